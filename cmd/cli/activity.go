@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// newActivityCmd creates the activity command
+func newActivityCmd() *cobra.Command {
+	activityCmd := &cobra.Command{
+		Use:   "activity",
+		Short: "Show the activity log",
+		Long:  "Show observed changes across tracked repositories, newest first",
+		Run: func(cmd *cobra.Command, args []string) {
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			filter := &models.ActivityFilter{}
+			filter.Repo, _ = cmd.Flags().GetString("repo")
+			filter.Type, _ = cmd.Flags().GetString("type")
+			filter.Page, _ = cmd.Flags().GetInt("page")
+			filter.PerPage, _ = cmd.Flags().GetInt("per-page")
+
+			since, _ := cmd.Flags().GetString("since")
+			if filter.Since, err = parseTimeFlag(since); err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid --since value: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+			until, _ := cmd.Flags().GetString("until")
+			if filter.Until, err = parseTimeFlag(until); err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid --until value: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			resp, err := client.ListActivity(filter)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error listing activity: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			// Print activity
+			fmt.Printf("%-20s %-28s %-40s %s\n", "TIME", "EVENT", "REPOSITORY", "DETAILS")
+			for _, event := range resp.Data {
+				details := event.Title
+				if event.Number > 0 {
+					details = fmt.Sprintf("#%d %s", event.Number, event.Title)
+				}
+				if event.Label != "" {
+					details = fmt.Sprintf("%s [%s]", details, event.Label)
+				}
+				if event.PreviousState != "" {
+					details = fmt.Sprintf("%s (%s -> %s)", details, event.PreviousState, event.State)
+				}
+				if event.Error != "" {
+					details = event.Error
+				}
+				fmt.Printf("%-20s %-28s %-40s %s\n", event.CreatedAt.Format("2006-01-02 15:04:05"), event.Type, event.Repository, details)
+			}
+
+			// Print pagination info
+			fmt.Printf("\nPage %d of %d (Total: %d)\n", resp.Pagination.Page, resp.Pagination.TotalPages, resp.Pagination.Total)
+		},
+	}
+	activityCmd.Flags().StringP("repo", "r", "", "Filter by repository (owner/name)")
+	activityCmd.Flags().String("type", "", "Filter by event type (e.g. pull_request.opened)")
+	activityCmd.Flags().String("since", "", "Only show events at or after this time (YYYY-MM-DD or RFC3339)")
+	activityCmd.Flags().String("until", "", "Only show events before this time (YYYY-MM-DD or RFC3339)")
+	activityCmd.Flags().IntP("page", "p", 1, "Page number")
+	activityCmd.Flags().IntP("per-page", "n", 20, "Items per page")
+
+	return activityCmd
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+// newAdminCmd creates the admin command group
+func newAdminCmd() *cobra.Command {
+	adminCmd := &cobra.Command{
+		Use:   "admin",
+		Short: "Inspect and maintain the database",
+		Long:  "Show storage statistics, compact and check the database and clear stored data, protected by the admin API key when one is configured",
+	}
+	adminCmd.PersistentFlags().String("api-key", os.Getenv("GHREPOS_ADMIN_API_KEY"), "Admin API key (default from GHREPOS_ADMIN_API_KEY)")
+
+	statsCmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Show storage statistics",
+		Long:  "Show entity counts per repository, the data file size and memory usage",
+		Run: func(cmd *cobra.Command, args []string) {
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			apiKey, _ := cmd.Flags().GetString("api-key")
+			stats, err := client.GetAdminStats(apiKey)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error getting stats: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			storage := stats.Storage
+			fmt.Printf("%-40s %-8s %-8s %-8s %-10s %-12s %s\n", "REPOSITORY", "PRS", "ISSUES", "LABELS", "SNAPSHOTS", "TOMBSTONED", "EST. BYTES")
+			for _, repo := range storage.PerRepository {
+				tombstoned := repo.TombstonedPullRequests + repo.TombstonedIssues
+				fmt.Printf("%-40s %-8d %-8d %-8d %-10d %-12d %d\n", repo.FullName, repo.PullRequests, repo.Issues, repo.Labels, repo.Snapshots, tombstoned, repo.EstimatedBytes)
+			}
+
+			fmt.Printf("\nRepositories: %d\n", storage.Repositories)
+			fmt.Printf("Pull Requests: %d\n", storage.PullRequests)
+			fmt.Printf("Issues: %d\n", storage.Issues)
+			fmt.Printf("Data File: %d bytes (schema version %d)\n", storage.FileBytes, storage.SchemaVersion)
+			fmt.Printf("Estimated Item Size: %d bytes\n", storage.EstimatedBytes)
+			fmt.Printf("Heap: %d bytes (System: %d bytes, Goroutines: %d)\n", stats.HeapBytes, stats.SysBytes, stats.Goroutines)
+		},
+	}
+
+	compactCmd := &cobra.Command{
+		Use:   "compact",
+		Short: "Compact the database",
+		Long: "Drop the closed items the retention policy no longer keeps and those no longer present upstream, remove labels, " +
+			"label links, snapshots and delivery logs no longer referenced, and rewrite the data file",
+		Run: func(cmd *cobra.Command, args []string) {
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			apiKey, _ := cmd.Flags().GetString("api-key")
+			result, err := client.CompactStorage(apiKey)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error compacting database: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			if result.ExpiredPullRequests > 0 || result.ExpiredIssues > 0 {
+				fmt.Printf("Dropped %d closed pull requests and %d closed issues past retention\n", result.ExpiredPullRequests, result.ExpiredIssues)
+			}
+			if result.RemovedTombstones > 0 {
+				fmt.Printf("Dropped %d pull requests and issues no longer present upstream\n", result.RemovedTombstones)
+			}
+			fmt.Printf("Removed %d orphaned entries (%d -> %d bytes, %d reclaimed)\n", result.RemovedEntries, result.BytesBefore, result.BytesAfter,
+				result.BytesBefore-result.BytesAfter)
+		},
+	}
+
+	clearCmd := &cobra.Command{
+		Use:   "clear [owner/name]",
+		Short: "Clear the stored data of a repository",
+		Long:  "Drop the stored pull requests, issues and snapshots of a repository; it stays tracked and is fetched again on the next refresh",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			owner, name, err := splitRepoName(args[0])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			apiKey, _ := cmd.Flags().GetString("api-key")
+			if err := client.ClearRepositoryData(apiKey, owner, name); err != nil {
+				fmt.Fprintf(os.Stderr, "Error clearing repository data: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			fmt.Printf("Cleared stored data of %s/%s\n", owner, name)
+		},
+	}
+
+	fsckCmd := &cobra.Command{
+		Use:   "fsck",
+		Short: "Check the integrity of the database",
+		Long: "Check that stored pull requests, issues, label links and diffs point at tracked repositories, existing items and " +
+			"defined labels, and list the orphans and inconsistencies found. With --repair, orphans are removed and the rest is fixed. " +
+			"Exits with status 1 when problems remain.",
+		Run: func(cmd *cobra.Command, args []string) {
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			apiKey, _ := cmd.Flags().GetString("api-key")
+			repair, _ := cmd.Flags().GetBool("repair")
+			report, err := client.CheckIntegrity(apiKey, repair)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error checking database: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			if len(report.Problems) == 0 {
+				fmt.Println("No problems found")
+				return
+			}
+			fmt.Printf("%-22s %-40s %-8s %s\n", "KIND", "REPOSITORY", "NUMBER", "DETAIL")
+			for _, problem := range report.Problems {
+				number := ""
+				if problem.Number > 0 {
+					number = strconv.Itoa(problem.Number)
+				}
+				fmt.Printf("%-22s %-40s %-8s %s\n", problem.Kind, problem.Repository, number, problem.Detail)
+			}
+			if repair {
+				fmt.Printf("\n%d problems repaired\n", report.Repaired)
+				return
+			}
+			fmt.Printf("\n%d problems found; run with --repair to fix them\n", len(report.Problems))
+			os.Exit(exitFailure)
+		},
+	}
+	fsckCmd.Flags().Bool("repair", false, "Remove orphans and fix the problems found")
+
+	warehouseCmd := &cobra.Command{
+		Use:   "warehouse",
+		Short: "Push the stored data to the warehouse",
+		Long: "Replace the repositories, pull requests and issues tables of the configured BigQuery or ClickHouse warehouse " +
+			"with the stored data, as 'ghrepos serve' does every warehouse.interval",
+		Run: func(cmd *cobra.Command, args []string) {
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			apiKey, _ := cmd.Flags().GetString("api-key")
+			push, err := client.PushWarehouse(apiKey)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error pushing to the warehouse: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+			fmt.Printf("Pushed %d repositories, %d pull requests and %d issues to %s\n", push.Repositories, push.PullRequests, push.Issues, push.Kind)
+		},
+	}
+
+	adminCmd.AddCommand(statsCmd, compactCmd, clearCmd, fsckCmd, warehouseCmd)
+	return adminCmd
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// newAlertsCmd creates the alerts command group
+func newAlertsCmd() *cobra.Command {
+	alertsCmd := &cobra.Command{
+		Use:   "alerts",
+		Short: "Show security alerts",
+		Long:  "Show the open Dependabot and code scanning alerts of the tracked repositories, synced when github.sync_alerts is enabled",
+	}
+
+	// alertFilter reads the filter flags shared by the alerts commands
+	alertFilter := func(cmd *cobra.Command) *models.SecurityAlertFilter {
+		filter := &models.SecurityAlertFilter{}
+		filter.Repo, _ = cmd.Flags().GetString("repo")
+		filter.RepoTag, _ = cmd.Flags().GetString("repo-tag")
+		filter.Kind, _ = cmd.Flags().GetString("kind")
+		filter.Severity, _ = cmd.Flags().GetString("severity")
+		return filter
+	}
+	addFilterFlags := func(cmd *cobra.Command) {
+		cmd.Flags().StringP("repo", "r", "", "Filter by repository (owner/name)")
+		cmd.Flags().String("repo-tag", "", "Filter by repository tag")
+		cmd.Flags().String("kind", "", "Filter by kind (dependabot, code_scanning)")
+		cmd.Flags().String("severity", "", "Only show alerts of at least this severity (low, medium, high, critical)")
+	}
+
+	// List alerts command
+	listAlertsCmd := &cobra.Command{
+		Use:         "list",
+		Short:       "List open security alerts, most severe first",
+		Annotations: servedAnnotations,
+		Run: func(cmd *cobra.Command, args []string) {
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			alerts, err := client.ListSecurityAlerts(alertFilter(cmd))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error listing alerts: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			fmt.Printf("%-40s %-14s %-6s %-9s %-30s %s\n", "REPOSITORY", "KIND", "NUMBER", "SEVERITY", "PACKAGE/RULE", "SUMMARY")
+			for _, alert := range alerts {
+				subject := alert.Package
+				if subject == "" {
+					subject = alert.Rule
+				}
+				fmt.Printf("%-40s %-14s %-6d %-9s %-30s %s\n", alert.RepositoryFullName, alert.Kind, alert.Number, alert.Severity, subject, alert.Summary)
+			}
+			fmt.Printf("\n%d open alerts\n", len(alerts))
+		},
+	}
+	addFilterFlags(listAlertsCmd)
+
+	// Summary command
+	summaryAlertsCmd := &cobra.Command{
+		Use:   "summary",
+		Short: "Count open security alerts per repository by severity",
+		Run: func(cmd *cobra.Command, args []string) {
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			summaries, err := client.SummarizeSecurityAlerts(alertFilter(cmd))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error summarizing alerts: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			fmt.Printf("%-40s %-6s %-9s %-5s %-7s %-5s %s\n", "REPOSITORY", "TOTAL", "CRITICAL", "HIGH", "MEDIUM", "LOW", "SYNCED")
+			for _, summary := range summaries {
+				synced := "never"
+				if !summary.SyncedAt.IsZero() {
+					synced = summary.SyncedAt.Format("2006-01-02 15:04:05")
+				}
+				fmt.Printf("%-40s %-6d %-9d %-5d %-7d %-5d %s\n", summary.Repository, summary.Total, summary.Critical, summary.High, summary.Medium, summary.Low, synced)
+			}
+		},
+	}
+	addFilterFlags(summaryAlertsCmd)
+
+	alertsCmd.AddCommand(listAlertsCmd, summaryAlertsCmd)
+	return alertsCmd
+}
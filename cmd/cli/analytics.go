@@ -0,0 +1,202 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/analytics"
+	"github.com/siddontang/github-repos-management/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// newAnalyticsCmd creates the analytics command
+func newAnalyticsCmd() *cobra.Command {
+	analyticsCmd := &cobra.Command{
+		Use:   "analytics",
+		Short: "Show lead-time and review-latency metrics",
+		Long:  "Show time to first review, time to merge and time to close for items created in a time range",
+		Run: func(cmd *cobra.Command, args []string) {
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			filter := &models.AnalyticsFilter{}
+			filter.Repo, _ = cmd.Flags().GetString("repo")
+			filter.RepoTag, _ = cmd.Flags().GetString("repo-tag")
+			filter.ExcludeBots, _ = cmd.Flags().GetBool("exclude-bots")
+			filter.Association, _ = cmd.Flags().GetString("association")
+
+			since, _ := cmd.Flags().GetString("since")
+			if filter.Since, err = parseTimeFlag(since); err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid --since value: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+			until, _ := cmd.Flags().GetString("until")
+			if filter.Until, err = parseTimeFlag(until); err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid --until value: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			by, _ := cmd.Flags().GetString("by")
+			if by != "repo" && by != "author" {
+				fmt.Fprintf(os.Stderr, "Invalid --by value %q, expected 'repo' or 'author'\n", by)
+				os.Exit(1)
+			}
+
+			report, err := client.GetAnalytics(filter)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error getting analytics: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			// Print analytics
+			groups := report.Repositories
+			header := "REPOSITORY"
+			if by == "author" {
+				groups = report.Authors
+				header = "AUTHOR"
+			}
+			fmt.Printf("%-40s %-6s %-7s %-15s %-15s %-7s %-15s %s\n", header, "PRS", "MERGED", "FIRST REVIEW", "MERGE", "ISSUES", "CLOSE", "COMMITS")
+			for _, stats := range append(groups, report.Overall) {
+				fmt.Printf("%-40s %-6d %-7d %-15s %-15s %-7d %-15s %d\n",
+					stats.Key, stats.PullRequestsOpened, stats.PullRequestsMerged,
+					formatMedian(stats.TimeToFirstReview), formatMedian(stats.TimeToMerge),
+					stats.IssuesOpened, formatMedian(stats.TimeToClose), stats.Commits)
+			}
+			fmt.Println("\nDurations are medians.")
+		},
+	}
+	analyticsCmd.Flags().StringP("repo", "r", "", "Filter by repository (owner/name)")
+	analyticsCmd.Flags().String("repo-tag", "", "Filter by repository tag")
+	analyticsCmd.Flags().Bool("exclude-bots", false, "Leave out items opened by bots")
+	analyticsCmd.Flags().String("association", "", "Only include authors with these associations, comma separated")
+	analyticsCmd.Flags().String("since", "", "Only include items created at or after this time (YYYY-MM-DD or RFC3339)")
+	analyticsCmd.Flags().String("until", "", "Only include items created before this time (YYYY-MM-DD or RFC3339)")
+	analyticsCmd.Flags().String("by", "repo", "Group by (repo, author)")
+
+	// Topics command
+	topicsCmd := &cobra.Command{
+		Use:         "topics",
+		Short:       "Show the themes of open issues across repositories",
+		Long:        "Group the open issues of the tracked repositories by label and title keyword, most issues first, to spot problems shared by many repositories",
+		Annotations: servedAnnotations,
+		Run: func(cmd *cobra.Command, args []string) {
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			filter := &models.TopicFilter{}
+			filter.Repo, _ = cmd.Flags().GetString("repo")
+			filter.RepoTag, _ = cmd.Flags().GetString("repo-tag")
+			filter.ExcludeBots, _ = cmd.Flags().GetBool("exclude-bots")
+			filter.Limit, _ = cmd.Flags().GetInt("limit")
+
+			topics, err := client.GetTopics(filter)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error getting topics: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			fmt.Printf("%-8s %-25s %-7s %-6s %s\n", "KIND", "TOPIC", "ISSUES", "REPOS", "EXAMPLES")
+			for _, topic := range topics {
+				fmt.Printf("%-8s %-25s %-7d %-6d %s\n", topic.Kind, truncate(topic.Name, 25), topic.Issues, len(topic.Repositories), strings.Join(topic.Examples, ", "))
+			}
+		},
+	}
+	topicsCmd.Flags().StringP("repo", "r", "", "Filter by repository (owner/name)")
+	topicsCmd.Flags().String("repo-tag", "", "Filter by repository tag")
+	topicsCmd.Flags().Bool("exclude-bots", false, "Leave out issues opened by bots")
+	topicsCmd.Flags().IntP("limit", "n", 20, "Most topics to show, 0 for all")
+	analyticsCmd.AddCommand(topicsCmd)
+
+	return analyticsCmd
+}
+
+// newLeaderboardCmd creates the leaderboard command
+func newLeaderboardCmd() *cobra.Command {
+	leaderboardCmd := &cobra.Command{
+		Use:   "leaderboard",
+		Short: "Show contributions per user",
+		Long:  "Summarize pull requests opened and merged, issues opened and closed, reviews given and commits made per user in a time range",
+		Run: func(cmd *cobra.Command, args []string) {
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			filter := &models.LeaderboardFilter{}
+			filter.Repo, _ = cmd.Flags().GetString("repo")
+			filter.RepoTag, _ = cmd.Flags().GetString("repo-tag")
+			filter.ExcludeBots, _ = cmd.Flags().GetBool("exclude-bots")
+			filter.Association, _ = cmd.Flags().GetString("association")
+			filter.Page, _ = cmd.Flags().GetInt("page")
+			filter.PerPage, _ = cmd.Flags().GetInt("per-page")
+
+			since, _ := cmd.Flags().GetString("since")
+			if filter.Since, err = parseTimeFlag(since); err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid --since value: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+			until, _ := cmd.Flags().GetString("until")
+			if filter.Until, err = parseTimeFlag(until); err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid --until value: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			format, _ := cmd.Flags().GetString("format")
+			if format != "table" && format != "csv" {
+				fmt.Fprintf(os.Stderr, "Invalid --format value %q, expected 'table' or 'csv'\n", format)
+				os.Exit(1)
+			}
+
+			resp, err := client.GetLeaderboard(filter)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error getting leaderboard: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			if format == "csv" {
+				if err := analytics.WriteLeaderboardCSV(os.Stdout, resp.Data); err != nil {
+					fmt.Fprintf(os.Stderr, "Error writing CSV: %v\n", err)
+					os.Exit(exitCode(err))
+				}
+				return
+			}
+
+			// Print leaderboard
+			fmt.Printf("%-30s %-10s %-10s %-13s %-13s %-10s %s\n", "USER", "PRS", "MERGED", "ISSUES", "CLOSED", "REVIEWS", "COMMITS")
+			for _, c := range resp.Data {
+				fmt.Printf("%-30s %-10d %-10d %-13d %-13d %-10d %d\n", c.User, c.PullRequestsOpened, c.PullRequestsMerged, c.IssuesOpened, c.IssuesClosed, c.ReviewsGiven, c.Commits)
+			}
+
+			// Print pagination info
+			fmt.Printf("\nPage %d of %d (Total: %d)\n", resp.Pagination.Page, resp.Pagination.TotalPages, resp.Pagination.Total)
+		},
+	}
+	leaderboardCmd.Flags().StringP("repo", "r", "", "Filter by repository (owner/name)")
+	leaderboardCmd.Flags().String("repo-tag", "", "Filter by repository tag")
+	leaderboardCmd.Flags().Bool("exclude-bots", false, "Leave out items opened by bots")
+	leaderboardCmd.Flags().String("association", "", "Only include authors with these associations, comma separated")
+	leaderboardCmd.Flags().String("since", "", "Only count contributions at or after this time (YYYY-MM-DD or RFC3339)")
+	leaderboardCmd.Flags().String("until", "", "Only count contributions before this time (YYYY-MM-DD or RFC3339)")
+	leaderboardCmd.Flags().String("format", "table", "Output format (table, csv)")
+	leaderboardCmd.Flags().IntP("page", "p", 1, "Page number")
+	leaderboardCmd.Flags().IntP("per-page", "n", 20, "Items per page")
+
+	return leaderboardCmd
+}
+
+// formatMedian formats the median of duration statistics, or "-" when there is no data
+func formatMedian(stats analytics.DurationStats) string {
+	if stats.Count == 0 {
+		return "-"
+	}
+	return stats.Median.Round(time.Minute).String()
+}
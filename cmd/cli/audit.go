@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// newAuditCmd creates the audit command
+func newAuditCmd() *cobra.Command {
+	auditCmd := &cobra.Command{
+		Use:         "audit",
+		Short:       "Show the audit log",
+		Annotations: servedAnnotations,
+		Long:        "Show who added, removed, refreshed, tagged or reconfigured repositories and when, newest first",
+		Run: func(cmd *cobra.Command, args []string) {
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			filter := &models.AuditFilter{}
+			filter.Actor, _ = cmd.Flags().GetString("actor")
+			filter.Action, _ = cmd.Flags().GetString("action")
+			filter.Target, _ = cmd.Flags().GetString("target")
+			filter.Page, _ = cmd.Flags().GetInt("page")
+			filter.PerPage, _ = cmd.Flags().GetInt("per-page")
+
+			since, _ := cmd.Flags().GetString("since")
+			if filter.Since, err = parseTimeFlag(since); err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid --since value: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+			until, _ := cmd.Flags().GetString("until")
+			if filter.Until, err = parseTimeFlag(until); err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid --until value: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			resp, err := client.ListAudit(filter)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error listing audit log: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			// Print audit entries
+			fmt.Printf("%-20s %-20s %-22s %-40s %s\n", "TIME", "ACTOR", "ACTION", "TARGET", "DETAILS")
+			for _, entry := range resp.Data {
+				actor := entry.Actor
+				if entry.Credential != "" {
+					actor = fmt.Sprintf("%s (key %s)", actor, entry.Credential)
+				}
+				fmt.Printf("%-20s %-20s %-22s %-40s %s\n", entry.CreatedAt.Format("2006-01-02 15:04:05"), actor, entry.Action, entry.Target, entry.Detail)
+			}
+
+			// Print pagination info
+			fmt.Printf("\nPage %d of %d (Total: %d)\n", resp.Pagination.Page, resp.Pagination.TotalPages, resp.Pagination.Total)
+		},
+	}
+	auditCmd.Flags().String("actor", "", "Filter by the user who made the change")
+	auditCmd.Flags().String("action", "", "Filter by action (e.g. repository.add, or repository for all repository actions)")
+	auditCmd.Flags().String("target", "", "Filter by target (e.g. owner/repo)")
+	auditCmd.Flags().String("since", "", "Only show entries at or after this time (YYYY-MM-DD or RFC3339)")
+	auditCmd.Flags().String("until", "", "Only show entries before this time (YYYY-MM-DD or RFC3339)")
+	auditCmd.Flags().IntP("page", "p", 1, "Page number")
+	auditCmd.Flags().IntP("per-page", "n", 20, "Items per page")
+
+	return auditCmd
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/siddontang/github-repos-management/internal/github"
+	"github.com/spf13/cobra"
+)
+
+// newAuthCmd creates the auth command group
+func newAuthCmd() *cobra.Command {
+	authCmd := &cobra.Command{
+		Use:   "auth",
+		Short: "Manage GitHub authentication",
+		Long:  "Log in to GitHub, show the active credentials and diagnose authentication problems",
+	}
+
+	loginCmd := &cobra.Command{
+		Use:   "login",
+		Short: "Log in to GitHub",
+		Long:  "Log in to GitHub through the gh CLI, interactively or with a token read from standard input",
+		Run: func(cmd *cobra.Command, args []string) {
+			requireOnline()
+			withToken, _ := cmd.Flags().GetBool("with-token")
+			if withToken {
+				token, err := bufio.NewReader(os.Stdin).ReadString('\n')
+				token = strings.TrimSpace(token)
+				if token == "" {
+					fmt.Fprintf(os.Stderr, "Error reading token from standard input: %v\n", err)
+					os.Exit(exitCode(err))
+				}
+				if err := github.LoginWithToken(token); err != nil {
+					fmt.Fprintf(os.Stderr, "Error logging in: %v\n", err)
+					os.Exit(exitCode(err))
+				}
+			} else if err := github.Login(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error logging in: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			if os.Getenv("GH_TOKEN") != "" || os.Getenv("GITHUB_TOKEN") != "" {
+				fmt.Println("Note: GH_TOKEN or GITHUB_TOKEN is set and takes precedence over the stored credentials")
+			}
+			fmt.Println("Logged in. Run 'ghrepos auth doctor' to verify the token scopes.")
+		},
+	}
+	loginCmd.Flags().Bool("with-token", false, "Read a personal access token from standard input")
+
+	statusCmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show the active GitHub credentials",
+		Long:  "Show which credentials are used (gh CLI or a token from the environment), the user and the token scopes",
+		Run: func(cmd *cobra.Command, args []string) {
+			requireOnline()
+			status, err := github.GetAuthStatus()
+
+			switch status.Mode {
+			case github.AuthModeToken:
+				fmt.Printf("Auth mode: token (from %s)\n", status.TokenSource)
+			default:
+				fmt.Println("Auth mode: gh CLI credentials")
+			}
+			if status.GHVersion != "" {
+				fmt.Printf("gh: %s\n", status.GHVersion)
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error checking authentication: %v\n", err)
+				fmt.Fprintln(os.Stderr, "Run 'ghrepos auth doctor' for suggested fixes.")
+				os.Exit(1)
+			}
+
+			fmt.Printf("User: %s\n", status.User)
+			if status.ScopesKnown {
+				fmt.Printf("Scopes: %s\n", strings.Join(status.Scopes, ", "))
+				if missing := status.MissingScopes(); len(missing) > 0 {
+					fmt.Printf("Missing scopes: %s\n", strings.Join(missing, ", "))
+				}
+			} else {
+				fmt.Println("Scopes: not reported (fine-grained or app token)")
+			}
+		},
+	}
+
+	doctorCmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Diagnose GitHub authentication problems",
+		Long:  "Check the gh installation, credentials, token scopes and rate limit, and print a fix for each problem",
+		Run: func(cmd *cobra.Command, args []string) {
+			requireOnline()
+			failed := false
+			for _, check := range github.Diagnose() {
+				mark := "ok"
+				switch {
+				case !check.OK:
+					mark = "FAIL"
+					failed = true
+				case check.Warn:
+					mark = "warn"
+				}
+				fmt.Printf("[%-4s] %-15s %s\n", mark, check.Name, check.Detail)
+				if check.Fix != "" && (!check.OK || check.Warn) {
+					fmt.Printf("       %-15s fix: %s\n", "", check.Fix)
+				}
+			}
+			if failed {
+				os.Exit(1)
+			}
+		},
+	}
+
+	authCmd.AddCommand(loginCmd, statusCmd, doctorCmd)
+	return authCmd
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/siddontang/github-repos-management/internal/bench"
+)
+
+// newBenchCmd creates the bench command
+func newBenchCmd() *cobra.Command {
+	benchCmd := &cobra.Command{
+		Use:   "bench",
+		Short: "Measure the latency of the list endpoints on synthetic data",
+		Long: "Populate each storage backend with synthetic repositories, pull requests and issues, then send requests to the " +
+			"list endpoints of the API in process and report their latency. The data is stored in a temporary directory, never in " +
+			"the configured database, and GitHub is never contacted. With --max-p95 the command fails when an endpoint is slower, " +
+			"so it can catch performance regressions in CI.",
+		Run: func(cmd *cobra.Command, args []string) {
+			var opts bench.Options
+			opts.Backends, _ = cmd.Flags().GetStringSlice("backend")
+			opts.Repositories, _ = cmd.Flags().GetInt("repos")
+			opts.PullRequests, _ = cmd.Flags().GetInt("prs")
+			opts.Issues, _ = cmd.Flags().GetInt("issues")
+			opts.Requests, _ = cmd.Flags().GetInt("requests")
+			maxP95, _ := cmd.Flags().GetDuration("max-p95")
+
+			format, _ := cmd.Flags().GetString("format")
+			if format != "table" && format != "csv" {
+				fmt.Fprintf(os.Stderr, "Invalid --format value %q, expected 'table' or 'csv'\n", format)
+				os.Exit(1)
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+
+			if format == "table" {
+				fmt.Fprintf(os.Stderr, "Populating %d repositories of %d pull requests and %d issues per backend...\n",
+					opts.Repositories, opts.PullRequests, opts.Issues)
+			}
+			results, err := bench.Run(ctx, opts)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error running benchmark: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			slow := 0
+			if format == "csv" {
+				w := csv.NewWriter(os.Stdout)
+				w.Write([]string{"backend", "endpoint", "requests", "mean_us", "p50_us", "p95_us", "p99_us", "max_us"})
+				for _, result := range results {
+					for _, e := range result.Endpoints {
+						w.Write([]string{
+							result.Backend,
+							e.Endpoint,
+							strconv.Itoa(e.Requests),
+							strconv.FormatInt(e.Mean.Microseconds(), 10),
+							strconv.FormatInt(e.P50.Microseconds(), 10),
+							strconv.FormatInt(e.P95.Microseconds(), 10),
+							strconv.FormatInt(e.P99.Microseconds(), 10),
+							strconv.FormatInt(e.Max.Microseconds(), 10),
+						})
+						if maxP95 > 0 && e.P95 > maxP95 {
+							slow++
+						}
+					}
+				}
+				w.Flush()
+				if err := w.Error(); err != nil {
+					fmt.Fprintf(os.Stderr, "Error writing CSV: %v\n", err)
+					os.Exit(exitCode(err))
+				}
+			} else {
+				fmt.Printf("%-8s %-50s %-9s %-10s %-10s %-10s %-10s %s\n", "BACKEND", "ENDPOINT", "REQUESTS", "MEAN", "P50", "P95", "P99", "MAX")
+				for _, result := range results {
+					for _, e := range result.Endpoints {
+						marker := ""
+						if maxP95 > 0 && e.P95 > maxP95 {
+							marker = " (slow)"
+							slow++
+						}
+						fmt.Printf("%-8s %-50s %-9d %-10s %-10s %-10s %-10s %s%s\n", result.Backend, e.Endpoint, e.Requests,
+							formatLatency(e.Mean), formatLatency(e.P50), formatLatency(e.P95), formatLatency(e.P99), formatLatency(e.Max), marker)
+					}
+				}
+				fmt.Println()
+				for _, result := range results {
+					fmt.Printf("Populating %s took %s\n", result.Backend, result.Populate.Round(time.Millisecond))
+				}
+			}
+
+			if slow > 0 {
+				fmt.Fprintf(os.Stderr, "Error: %d endpoints have a p95 latency above %s\n", slow, maxP95)
+				os.Exit(1)
+			}
+		},
+	}
+	benchCmd.Flags().StringSlice("backend", nil, "Storage backends to measure, comma separated (default every available one)")
+	benchCmd.Flags().Int("repos", bench.DefaultRepositories, "Synthetic repositories to populate")
+	benchCmd.Flags().Int("prs", bench.DefaultPullRequests, "Pull requests per repository")
+	benchCmd.Flags().Int("issues", bench.DefaultIssues, "Issues per repository")
+	benchCmd.Flags().Int("requests", bench.DefaultRequests, "Requests per endpoint")
+	benchCmd.Flags().Duration("max-p95", 0, "Fail when an endpoint's p95 latency is above this (e.g. 50ms)")
+	benchCmd.Flags().String("format", "table", "Output format (table, csv)")
+
+	return benchCmd
+}
+
+// formatLatency rounds a latency for display
+func formatLatency(d time.Duration) string {
+	if d >= time.Millisecond {
+		return d.Round(10 * time.Microsecond).String()
+	}
+	return d.Round(time.Microsecond).String()
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// newBulkCmd creates the bulk command of pull requests or issues, by item type
+func newBulkCmd(itemType string) *cobra.Command {
+	items := "issues"
+	if itemType == models.ItemTypePullRequest {
+		items = "pull requests"
+	}
+	bulkCmd := &cobra.Command{
+		Use:   "bulk [close | label <label> | comment <body>]",
+		Short: fmt.Sprintf("Close, label or comment on all %s matching a filter", items),
+		Long: fmt.Sprintf("Close, label or comment on all stored %s matching the filter flags, open ones unless --state is given. ", items) +
+			"Use --dry-run to see the matching items first; items already closed or labeled are skipped, and a failure doesn't stop the others.",
+		Args: cobra.RangeArgs(1, 2),
+		Run: func(cmd *cobra.Command, args []string) {
+			req := &models.BulkRequest{Action: args[0], Filter: models.BulkFilter{Type: itemType}}
+			switch {
+			case req.Action == models.BulkActionClose && len(args) == 1:
+			case req.Action == models.BulkActionLabel && len(args) == 2:
+				req.Label = args[1]
+			case req.Action == models.BulkActionComment && len(args) == 2:
+				req.Comment = args[1]
+			default:
+				fmt.Fprintf(os.Stderr, "Error: expected close, label <label> or comment <body>\n")
+				os.Exit(1)
+			}
+			req.Filter.Repo, _ = cmd.Flags().GetString("repo")
+			req.Filter.RepoTag, _ = cmd.Flags().GetString("repo-tag")
+			req.Filter.Label, _ = cmd.Flags().GetString("label")
+			req.Filter.Author, _ = cmd.Flags().GetString("author")
+			req.Filter.State, _ = cmd.Flags().GetString("state")
+			req.DryRun, _ = cmd.Flags().GetBool("dry-run")
+			req.Concurrency, _ = cmd.Flags().GetInt("concurrency")
+			req.Filter.AllRepos, _ = cmd.Flags().GetBool("all-repos")
+			since, _ := cmd.Flags().GetString("since")
+			var err error
+			if req.Filter.Since, err = parseTimeFlag(since); err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid --since value: %v\n", err)
+				os.Exit(1)
+			}
+			if !req.DryRun {
+				requireOnline()
+			}
+
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+			report, err := client.ApplyBulkAction(req)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error applying bulk action: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			fmt.Printf("%-45s %-8s %-50s %s\n", "ITEM", "STATUS", "TITLE", "REASON")
+			for _, result := range report.Results {
+				fmt.Printf("%-45s %-8s %-50s %s\n", fmt.Sprintf("%s#%d", result.RepositoryFullName, result.Number), result.Status,
+					truncate(result.Title, 50), result.Reason)
+			}
+			planned := report.Matched - report.Done - report.Skipped - report.Failed
+			fmt.Printf("\n%d matched: %d planned, %d done, %d skipped, %d failed\n", report.Matched, planned, report.Done, report.Skipped, report.Failed)
+			if report.Failed > 0 {
+				os.Exit(1)
+			}
+		},
+	}
+	bulkCmd.Flags().StringP("repo", "r", "", "Filter by repository (owner/name)")
+	bulkCmd.Flags().String("repo-tag", "", "Filter by repository tag")
+	bulkCmd.Flags().Bool("all-repos", false, "Apply to every tracked repository")
+	bulkCmd.Flags().StringP("label", "l", "", "Filter by label")
+	bulkCmd.Flags().StringP("author", "a", "", "Filter by author")
+	bulkCmd.Flags().StringP("state", "s", "", "Filter by state: open (default), closed or all")
+	bulkCmd.Flags().String("since", "", "Only apply to items updated since this date (YYYY-MM-DD or RFC3339)")
+	bulkCmd.Flags().Bool("dry-run", false, "List the matching items without changing them")
+	bulkCmd.Flags().Int("concurrency", 0, "Items changed at once (default 4, at most 16)")
+	return bulkCmd
+}
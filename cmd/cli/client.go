@@ -2,23 +2,60 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"os"
+	"os/user"
 	"strconv"
 	"time"
 
+	"github.com/siddontang/github-repos-management/internal/analytics"
 	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/db"
+	"github.com/siddontang/github-repos-management/internal/github"
 	"github.com/siddontang/github-repos-management/internal/models"
 	"github.com/siddontang/github-repos-management/internal/service"
 )
 
-// Client represents a service client wrapper
+// Client represents a service client wrapper. It runs the embedded service, or sends the
+// commands the JSON API serves to a running server when remote is set.
 type Client struct {
 	service *service.Service
+	remote  *remoteClient
+	config  *config.Config
 	ctx     context.Context
 }
 
-// NewClient creates a new service client wrapper
+// errNotServed is returned for operations the JSON API doesn't serve when using a server
+var errNotServed = errors.New("not served by the HTTP API; run with --local")
+
+// NewClient creates a new service client wrapper acting as the logged in user, if any
 func NewClient() (*Client, error) {
+	c, err := newClient()
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := loadSessionToken()
+	if err != nil {
+		return nil, err
+	}
+	if c.remote != nil {
+		// The server authenticates every request itself
+		c.remote.token = token
+		return c, nil
+	}
+	ctx, _, err := c.service.Authenticate(c.ctx, token)
+	if err != nil {
+		return nil, fmt.Errorf("%w; run 'ghrepos sso login'", err)
+	}
+	c.ctx = ctx
+	return c, nil
+}
+
+// newClient creates a new service client wrapper without a login session
+func newClient() (*Client, error) {
 	// Load default configuration
 	cfg := &config.Config{
 		Database: config.DatabaseConfig{
@@ -27,24 +64,106 @@ func NewClient() (*Client, error) {
 		},
 	}
 
+	// Load configuration file if specified
+	if configPath != "" {
+		var err error
+		cfg, err = config.Load(configPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load configuration: %w", err)
+		}
+	}
+
+	server, err := resolveServer(cfg)
+	if err != nil {
+		return nil, err
+	}
+	logMode(server)
+	if server != "" {
+		remote := newRemoteClient(server)
+		remote.workspace = currentWorkspace()
+		return &Client{
+			remote: remote,
+			config: cfg,
+			ctx:    context.Background(),
+		}, nil
+	}
+
 	// Create service
+	if readOnly {
+		cfg.Database.ReadOnly = true
+	}
+	if jobWorkers > 0 {
+		cfg.Jobs.Workers = jobWorkers
+	}
+	if offlineMode {
+		cfg.GitHub.Offline = true
+	}
+	if replayDir != "" {
+		cfg.GitHub.Replay = replayDir
+	}
 	svc, err := service.NewService(cfg)
+	if errors.Is(err, db.ErrDatabaseInUse) {
+		return nil, fmt.Errorf("%w; use the running server with --server, or read the data with --read-only", err)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to create service: %w", err)
 	}
 
+	localServices = append(localServices, svc)
+
+	// The CLI can read the database file, so it may use the admin operations without a key
+	ctx := service.WithLocalAccess(service.WithActor(context.Background(), currentActor()))
+	if id := currentWorkspace(); id != "" {
+		ctx = service.WithWorkspace(ctx, id)
+	}
 	return &Client{
 		service: svc,
-		ctx:     context.Background(),
+		config:  cfg,
+		ctx:     ctx,
 	}, nil
 }
 
+// localServices are the services created by the running command
+var localServices []*service.Service
+
+// stopLocalServices finishes the webhook deliveries and exports the spans the command's services
+// still have queued
+func stopLocalServices() {
+	for _, svc := range localServices {
+		svc.StopDeliveries()
+		svc.StopTracing()
+	}
+}
+
+// currentWorkspace returns the workspace commands are restricted to: --workspace when set,
+// otherwise GHREPOS_WORKSPACE
+func currentWorkspace() string {
+	if workspace != "" {
+		return workspace
+	}
+	return os.Getenv("GHREPOS_WORKSPACE")
+}
+
+// currentActor names the user changes are attributed to in the audit log:
+// GHREPOS_ACTOR when set, otherwise the operating system user
+func currentActor() string {
+	if actor := os.Getenv("GHREPOS_ACTOR"); actor != "" {
+		return actor
+	}
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return ""
+}
+
 // Pagination represents pagination information
 type Pagination struct {
-	Page       int `json:"page"`
-	PerPage    int `json:"per_page"`
-	Total      int `json:"total"`
-	TotalPages int `json:"total_pages"`
+	Page       int    `json:"page"`
+	PerPage    int    `json:"per_page"`
+	Total      int    `json:"total"`
+	TotalPages int    `json:"total_pages"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
 }
 
 // ListRepositoriesResponse represents a response for listing repositories
@@ -55,26 +174,52 @@ type ListRepositoriesResponse struct {
 
 // ListPullRequestsResponse represents a response for listing pull requests
 type ListPullRequestsResponse struct {
-	Data       []*models.PullRequest `json:"data"`
-	Pagination *Pagination           `json:"pagination"`
+	Data         []*models.PullRequest `json:"data"`
+	Pagination   *Pagination           `json:"pagination"`
+	ETag         string                `json:"etag"`
+	LastModified time.Time             `json:"last_modified"`
 }
 
 // ListIssuesResponse represents a response for listing issues
 type ListIssuesResponse struct {
-	Data       []*models.Issue `json:"data"`
-	Pagination *Pagination     `json:"pagination"`
+	Data         []*models.Issue `json:"data"`
+	Pagination   *Pagination     `json:"pagination"`
+	ETag         string          `json:"etag"`
+	LastModified time.Time       `json:"last_modified"`
 }
 
 // ListRepositories lists repositories that have been added
-func (c *Client) ListRepositories(page, perPage int) (*ListRepositoriesResponse, error) {
-	// Get repositories from service
-	repos, total, err := c.service.ListRepositories(c.ctx, page, perPage)
+func (c *Client) ListRepositories(tag, cursor string, page, perPage int) (*ListRepositoriesResponse, error) {
+	// Create filter
+	filter := &models.RepositoryFilter{
+		Tag:     tag,
+		Cursor:  cursor,
+		Page:    page,
+		PerPage: perPage,
+	}
+
+	// Get repositories from the server or the service
+	var repos []*models.Repository
+	var pagination *models.Pagination
+	var err error
+	if c.remote != nil {
+		var list remoteList[*models.Repository]
+		err = c.remote.get(c.ctx, "/api/v1/repositories", queryValues(map[string]string{
+			"repo_tag": tag,
+			"cursor":   cursor,
+			"page":     pageValue(page),
+			"per_page": pageValue(perPage),
+		}), &list)
+		repos, pagination = list.Data, list.Pagination
+	} else {
+		repos, pagination, err = c.service.ListRepositories(c.ctx, filter)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to list repositories: %w", err)
 	}
 
-	// Create pagination
-	totalPages := (total + perPage - 1) / perPage
+	// Always report at least one page
+	totalPages := pagination.TotalPages
 	if totalPages < 1 {
 		totalPages = 1
 	}
@@ -82,10 +227,12 @@ func (c *Client) ListRepositories(page, perPage int) (*ListRepositoriesResponse,
 	return &ListRepositoriesResponse{
 		Data: repos,
 		Pagination: &Pagination{
-			Page:       page,
-			PerPage:    perPage,
-			Total:      total,
+			Page:       pagination.Page,
+			PerPage:    pagination.PerPage,
+			Total:      pagination.Total,
 			TotalPages: totalPages,
+			NextCursor: pagination.NextCursor,
+			PrevCursor: pagination.PrevCursor,
 		},
 	}, nil
 }
@@ -101,6 +248,57 @@ func (c *Client) AddRepository(fullName string) (*models.Repository, error) {
 	return repo, nil
 }
 
+// AddRepositories adds several repositories, returning the outcome of each
+func (c *Client) AddRepositories(fullNames []string) []*models.RepositoryAddResult {
+	return c.service.AddRepositories(c.ctx, fullNames)
+}
+
+// AddOrganizationRepositories adds every non-archived repository of an organization
+func (c *Client) AddOrganizationRepositories(org string) ([]*models.RepositoryAddResult, error) {
+	results, err := c.service.AddOrganizationRepositories(c.ctx, org)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add organization repositories: %w", err)
+	}
+
+	return results, nil
+}
+
+// DiscoverRepositories lists untracked repositories the GitHub user owns, stars or contributes to
+func (c *Client) DiscoverRepositories(relations []string) ([]*models.DiscoveredRepository, error) {
+	repos, err := c.service.DiscoverRepositories(c.ctx, relations)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover repositories: %w", err)
+	}
+	return repos, nil
+}
+
+// UntrackedRepositories returns the repositories among fullNames that are not tracked yet
+func (c *Client) UntrackedRepositories(fullNames []string) ([]string, error) {
+	untracked, err := c.service.UntrackedRepositories(c.ctx, fullNames)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tracked repositories: %w", err)
+	}
+	return untracked, nil
+}
+
+// SeedRepository stores exported pull requests and issues of a repository without calling GitHub
+func (c *Client) SeedRepository(fullName string, prs []*github.PullRequest, issues []*github.Issue) (*models.SeedResult, error) {
+	result, err := c.service.SeedRepository(c.ctx, fullName, nil, prs, issues)
+	if err != nil {
+		return nil, fmt.Errorf("failed to seed repository: %w", err)
+	}
+	return result, nil
+}
+
+// SeedArchive stores the repositories, pull requests and issues of a GitHub migration archive
+func (c *Client) SeedArchive(archive *github.Archive) ([]*models.SeedResult, error) {
+	results, err := c.service.SeedArchive(c.ctx, archive)
+	if err != nil {
+		return results, fmt.Errorf("failed to seed from archive: %w", err)
+	}
+	return results, nil
+}
+
 // GetRepository gets a repository by owner and name
 func (c *Client) GetRepository(owner, name string) (*models.Repository, error) {
 	// Get repository using service
@@ -112,6 +310,220 @@ func (c *Client) GetRepository(owner, name string) (*models.Repository, error) {
 	return repo, nil
 }
 
+// AddRepositoryTag attaches a tag to a tracked repository
+func (c *Client) AddRepositoryTag(owner, name, tag string) (*models.Repository, error) {
+	repo, err := c.service.AddRepositoryTag(c.ctx, owner, name, tag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add tag: %w", err)
+	}
+
+	return repo, nil
+}
+
+// RemoveRepositoryTag detaches a tag from a tracked repository
+func (c *Client) RemoveRepositoryTag(owner, name, tag string) (*models.Repository, error) {
+	repo, err := c.service.RemoveRepositoryTag(c.ctx, owner, name, tag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to remove tag: %w", err)
+	}
+
+	return repo, nil
+}
+
+// UpdateRepositorySyncConfig updates a repository's sync configuration
+func (c *Client) UpdateRepositorySyncConfig(owner, name string, update *models.RepositorySyncConfigUpdate) (*models.Repository, error) {
+	repo, err := c.service.UpdateRepositorySyncConfig(c.ctx, owner, name, update)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update sync configuration: %w", err)
+	}
+
+	return repo, nil
+}
+
+// GetPullRequestDiff gets the changes of a pull request as a diff or patches, caching them with cache
+func (c *Client) GetPullRequestDiff(owner, name string, number int, format string, cache bool) (*models.PullRequestDiff, error) {
+	var diff *models.PullRequestDiff
+	var err error
+	if c.remote != nil {
+		diff = &models.PullRequestDiff{}
+		err = c.remote.get(c.ctx, fmt.Sprintf("/api/v1/repositories/%s/%s/pulls/%d/diff", owner, name, number), queryValues(map[string]string{
+			"format": format,
+			"cache":  strconv.FormatBool(cache),
+		}), diff)
+	} else {
+		diff, err = c.service.GetPullRequestDiff(c.ctx, owner, name, number, format, cache)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pull request diff: %w", err)
+	}
+
+	return diff, nil
+}
+
+// SetLocalClone associates a repository with its local clone, or removes the association for an empty path
+func (c *Client) SetLocalClone(owner, name, path string) (*models.Repository, error) {
+	repo, err := c.service.SetLocalClone(c.ctx, owner, name, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set local clone: %w", err)
+	}
+
+	return repo, nil
+}
+
+// CreatePullRequest opens a pull request in a tracked repository and stores it
+func (c *Client) CreatePullRequest(owner, name string, create *models.PullRequestCreate) (*models.PullRequest, error) {
+	pr, err := c.service.CreatePullRequest(c.ctx, owner, name, create)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pull request: %w", err)
+	}
+
+	return pr, nil
+}
+
+// UpdatePullRequest changes the assignees or milestone of a pull request
+func (c *Client) UpdatePullRequest(owner, name string, number int, update *models.ItemUpdate) (*models.PullRequest, error) {
+	pr, err := c.service.UpdatePullRequest(c.ctx, owner, name, number, update)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update pull request: %w", err)
+	}
+
+	return pr, nil
+}
+
+// UpdateIssue changes the assignees or milestone of an issue
+func (c *Client) UpdateIssue(owner, name string, number int, update *models.ItemUpdate) (*models.Issue, error) {
+	issue, err := c.service.UpdateIssue(c.ctx, owner, name, number, update)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update issue: %w", err)
+	}
+
+	return issue, nil
+}
+
+// ApplyBulkAction closes, labels or comments on the pull requests and issues matching a filter
+func (c *Client) ApplyBulkAction(req *models.BulkRequest) (*models.BulkReport, error) {
+	report, err := c.service.ApplyBulkAction(c.ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply bulk action: %w", err)
+	}
+
+	return report, nil
+}
+
+// PauseRepository excludes a repository from scheduled refreshes
+func (c *Client) PauseRepository(owner, name string) (*models.Repository, error) {
+	repo, err := c.service.PauseRepository(c.ctx, owner, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pause repository: %w", err)
+	}
+
+	return repo, nil
+}
+
+// ResumeRepository re-includes a paused repository in scheduled refreshes
+func (c *Client) ResumeRepository(owner, name string) (*models.Repository, error) {
+	repo, err := c.service.ResumeRepository(c.ctx, owner, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resume repository: %w", err)
+	}
+
+	return repo, nil
+}
+
+// GetRepositoryTrends gets the metric snapshots of a repository within a time window
+func (c *Client) GetRepositoryTrends(owner, name string, window time.Duration) ([]*models.RepositorySnapshot, error) {
+	snapshots, err := c.service.GetRepositoryTrends(c.ctx, owner, name, window)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository trends: %w", err)
+	}
+
+	return snapshots, nil
+}
+
+// ListDuplicates lists the probable duplicate issues of a repository, best match first
+func (c *Client) ListDuplicates(owner, name string) ([]*models.DuplicateCandidate, error) {
+	var candidates []*models.DuplicateCandidate
+	var err error
+	if c.remote != nil {
+		err = c.remote.get(c.ctx, "/api/v1/repositories/"+owner+"/"+name+"/duplicates", nil, &candidates)
+	} else {
+		candidates, err = c.service.ListDuplicates(c.ctx, owner, name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list duplicate issues: %w", err)
+	}
+	return candidates, nil
+}
+
+// GetIssueTree gets an issue with the issues of its task list, recursively
+func (c *Client) GetIssueTree(owner, name string, number int) (*models.IssueTree, error) {
+	var tree *models.IssueTree
+	var err error
+	if c.remote != nil {
+		err = c.remote.get(c.ctx, fmt.Sprintf("/api/v1/repositories/%s/%s/issues/%d/tree", owner, name, number), nil, &tree)
+	} else {
+		tree, err = c.service.GetIssueTree(c.ctx, owner, name, number)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get issue tree: %w", err)
+	}
+	return tree, nil
+}
+
+// ReleaseNotes drafts the release notes of a repository since a release tag or date
+func (c *Client) ReleaseNotes(owner, name, since string) (*models.ReleaseNotes, error) {
+	var notes *models.ReleaseNotes
+	var err error
+	if c.remote != nil {
+		err = c.remote.get(c.ctx, "/api/v1/repositories/"+owner+"/"+name+"/release-notes", queryValues(map[string]string{"since": since}), &notes)
+	} else {
+		notes, err = c.service.ReleaseNotes(c.ctx, owner, name, since)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to draft release notes: %w", err)
+	}
+	return notes, nil
+}
+
+// ListCommitsResponse represents the response from listing commits
+type ListCommitsResponse struct {
+	Data       []*models.Commit `json:"data"`
+	Pagination *Pagination      `json:"pagination"`
+}
+
+// ListCommits lists the synced default branch commits of a repository, newest first
+func (c *Client) ListCommits(owner, name string, filter *models.CommitFilter) (*ListCommitsResponse, error) {
+	var commits []*models.Commit
+	var pagination *models.Pagination
+	var err error
+	if c.remote != nil {
+		var list remoteList[*models.Commit]
+		err = c.remote.get(c.ctx, "/api/v1/repositories/"+owner+"/"+name+"/commits", queryValues(map[string]string{
+			"author":   filter.Author,
+			"since":    timeValue(filter.Since),
+			"until":    timeValue(filter.Until),
+			"page":     pageValue(filter.Page),
+			"per_page": pageValue(filter.PerPage),
+		}), &list)
+		commits, pagination = list.Data, list.Pagination
+	} else {
+		commits, pagination, err = c.service.ListCommits(c.ctx, owner, name, filter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list commits: %w", err)
+	}
+
+	return &ListCommitsResponse{
+		Data: commits,
+		Pagination: &Pagination{
+			Page:       pagination.Page,
+			PerPage:    pagination.PerPage,
+			Total:      pagination.Total,
+			TotalPages: pagination.TotalPages,
+		},
+	}, nil
+}
+
 // RemoveRepository removes a repository from tracking
 func (c *Client) RemoveRepository(owner, name string) error {
 	// Remove repository using service
@@ -123,15 +535,32 @@ func (c *Client) RemoveRepository(owner, name string) error {
 	return nil
 }
 
-// RefreshRepository forces a refresh of repository data
-func (c *Client) RefreshRepository(owner, name string) error {
+// RefreshRepository forces a refresh of repository data and waits up to timeout for it
+// to finish; a zero timeout waits until it is done. The returned job reports the outcome.
+func (c *Client) RefreshRepository(owner, name string, timeout time.Duration) (*models.Job, error) {
+	if c.remote != nil {
+		var job *models.Job
+		if err := c.remote.do(c.ctx, http.MethodPost, "/api/v1/repositories/"+owner+"/"+name+"/refresh", nil, &job); err != nil {
+			return nil, fmt.Errorf("failed to refresh repository: %w", err)
+		}
+		job, err := c.remote.waitJob(c.ctx, job, timeout)
+		if err != nil {
+			return nil, fmt.Errorf("failed to wait for refresh: %w", err)
+		}
+		return job, nil
+	}
+
 	// Refresh repository using service
-	err := c.service.RefreshRepository(c.ctx, owner, name)
+	job, err := c.service.StartRefresh(c.ctx, owner, name)
 	if err != nil {
-		return fmt.Errorf("failed to refresh repository: %w", err)
+		return nil, fmt.Errorf("failed to refresh repository: %w", err)
 	}
 
-	return nil
+	job, err = c.service.WaitJob(c.ctx, job.ID, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to wait for refresh: %w", err)
+	}
+	return job, nil
 }
 
 // ListPullRequests lists pull requests with filtering and pagination
@@ -141,9 +570,23 @@ func (c *Client) ListPullRequests(params map[string]string) (*ListPullRequestsRe
 		State:     params["state"],
 		Author:    params["author"],
 		Repo:      params["repo"],
+		RepoTag:   params["repo_tag"],
 		Label:     params["label"],
 		SortBy:    params["sort"],
 		Direction: params["direction"],
+		Cursor:    params["cursor"],
+
+		ExcludeBots:       params["exclude_bots"] == "true",
+		Association:       params["association"],
+		Team:              params["team"],
+		Jira:              params["jira"],
+		OwnedByMe:         params["owned_by_me"] == "true",
+		OwnedByTeam:       params["owned_by_team"],
+		Path:              params["path"],
+		Size:              params["size"],
+		Project:           params["project"],
+		ProjectStatus:     params["project_status"],
+		IncludeTombstoned: params["include_tombstoned"] == "true",
 	}
 
 	// Parse pagination
@@ -166,27 +609,76 @@ func (c *Client) ListPullRequests(params map[string]string) (*ListPullRequestsRe
 	filter.PerPage = perPage
 
 	// Parse since date
-	if sinceStr, ok := params["since"]; ok && sinceStr != "" {
-		if since, err := time.Parse(time.RFC3339, sinceStr); err == nil {
-			filter.Since = since
-		}
+	since, err := parseTimeFlag(params["since"])
+	if err != nil {
+		return nil, fmt.Errorf("invalid since date: %w", err)
 	}
+	filter.Since = since
 
-	// Get pull requests from service
-	prs, pagination, err := c.service.ListPullRequests(c.ctx, filter)
+	// Get pull requests from the server or the service
+	var prs []*models.PullRequest
+	var pagination *models.Pagination
+	if c.remote != nil {
+		var list remoteList[*models.PullRequest]
+		err = c.remote.get(c.ctx, "/api/v1/pulls", remoteItemQuery(params, since), &list)
+		prs, pagination = list.Data, list.Pagination
+	} else {
+		prs, pagination, err = c.service.ListPullRequests(c.ctx, filter)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to list pull requests: %w", err)
 	}
 
-	return &ListPullRequestsResponse{
+	// Bodies can be large, so they are only returned when asked for
+	if params["include_body"] != "true" {
+		sparse := make([]*models.PullRequest, 0, len(prs))
+		for _, pr := range prs {
+			copied := *pr
+			copied.Body = ""
+			sparse = append(sparse, &copied)
+		}
+		prs = sparse
+	}
+
+	resp := &ListPullRequestsResponse{
 		Data: prs,
 		Pagination: &Pagination{
 			Page:       pagination.Page,
 			PerPage:    pagination.PerPage,
 			Total:      pagination.Total,
 			TotalPages: pagination.TotalPages,
+			NextCursor: pagination.NextCursor,
+			PrevCursor: pagination.PrevCursor,
 		},
-	}, nil
+	}
+	resp.ETag = listETag(resp.Data, resp.Pagination)
+	for _, pr := range prs {
+		if pr.UpdatedAt.After(resp.LastModified) {
+			resp.LastModified = pr.UpdatedAt
+		}
+	}
+
+	return resp, nil
+}
+
+// GetPullRequest gets a pull request by repository and number
+func (c *Client) GetPullRequest(owner, name string, number int) (*models.PullRequest, error) {
+	pr, err := c.service.GetPullRequest(c.ctx, owner, name, number)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pull request: %w", err)
+	}
+
+	return pr, nil
+}
+
+// GetIssue gets an issue by repository and number
+func (c *Client) GetIssue(owner, name string, number int) (*models.Issue, error) {
+	issue, err := c.service.GetIssue(c.ctx, owner, name, number)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get issue: %w", err)
+	}
+
+	return issue, nil
 }
 
 // ListIssues lists issues with filtering and pagination
@@ -196,9 +688,19 @@ func (c *Client) ListIssues(params map[string]string) (*ListIssuesResponse, erro
 		State:     params["state"],
 		Author:    params["author"],
 		Repo:      params["repo"],
+		RepoTag:   params["repo_tag"],
 		Label:     params["label"],
 		SortBy:    params["sort"],
 		Direction: params["direction"],
+		Cursor:    params["cursor"],
+
+		ExcludeBots:       params["exclude_bots"] == "true",
+		Association:       params["association"],
+		Team:              params["team"],
+		Jira:              params["jira"],
+		Project:           params["project"],
+		ProjectStatus:     params["project_status"],
+		IncludeTombstoned: params["include_tombstoned"] == "true",
 	}
 
 	// Parse pagination
@@ -221,20 +723,147 @@ func (c *Client) ListIssues(params map[string]string) (*ListIssuesResponse, erro
 	filter.PerPage = perPage
 
 	// Parse since date
-	if sinceStr, ok := params["since"]; ok && sinceStr != "" {
-		if since, err := time.Parse(time.RFC3339, sinceStr); err == nil {
-			filter.Since = since
-		}
+	since, err := parseTimeFlag(params["since"])
+	if err != nil {
+		return nil, fmt.Errorf("invalid since date: %w", err)
 	}
+	filter.Since = since
 
-	// Get issues from service
-	issues, pagination, err := c.service.ListIssues(c.ctx, filter)
+	// Get issues from the server or the service
+	var issues []*models.Issue
+	var pagination *models.Pagination
+	if c.remote != nil {
+		var list remoteList[*models.Issue]
+		err = c.remote.get(c.ctx, "/api/v1/issues", remoteItemQuery(params, since), &list)
+		issues, pagination = list.Data, list.Pagination
+	} else {
+		issues, pagination, err = c.service.ListIssues(c.ctx, filter)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to list issues: %w", err)
 	}
 
-	return &ListIssuesResponse{
+	// Bodies can be large, so they are only returned when asked for
+	if params["include_body"] != "true" {
+		sparse := make([]*models.Issue, 0, len(issues))
+		for _, issue := range issues {
+			copied := *issue
+			copied.Body = ""
+			sparse = append(sparse, &copied)
+		}
+		issues = sparse
+	}
+
+	resp := &ListIssuesResponse{
 		Data: issues,
+		Pagination: &Pagination{
+			Page:       pagination.Page,
+			PerPage:    pagination.PerPage,
+			Total:      pagination.Total,
+			TotalPages: pagination.TotalPages,
+			NextCursor: pagination.NextCursor,
+			PrevCursor: pagination.PrevCursor,
+		},
+	}
+	resp.ETag = listETag(resp.Data, resp.Pagination)
+	for _, issue := range issues {
+		if issue.UpdatedAt.After(resp.LastModified) {
+			resp.LastModified = issue.UpdatedAt
+		}
+	}
+
+	return resp, nil
+}
+
+// RefreshAll refreshes all repositories, calling progress as their sync jobs change,
+// and returns the final state of the jobs
+func (c *Client) RefreshAll(progress func(*models.Job)) ([]*models.Job, error) {
+	if c.remote != nil {
+		return nil, fmt.Errorf("refreshing all repositories is %w", errNotServed)
+	}
+
+	jobs, err := c.service.RefreshAllWithProgress(c.ctx, progress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh all repositories: %w", err)
+	}
+	return jobs, nil
+}
+
+// RefreshDue refreshes repositories whose sync interval has elapsed
+func (c *Client) RefreshDue() (int, error) {
+	if c.remote != nil {
+		return 0, fmt.Errorf("refreshing due repositories is %w", errNotServed)
+	}
+
+	refreshed, err := c.service.RefreshDue(c.ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to refresh due repositories: %w", err)
+	}
+	return refreshed, nil
+}
+
+// PlanRefresh reports what a refresh would fetch without running it.
+// An empty owner plans a refresh of all repositories, or of the due ones when dueOnly is set.
+func (c *Client) PlanRefresh(owner, name string, dueOnly bool) (*models.RefreshPlan, error) {
+	if c.remote != nil {
+		if owner == "" {
+			return nil, fmt.Errorf("planning refreshes of several repositories is %w", errNotServed)
+		}
+		var plan *models.RefreshPlan
+		if err := c.remote.do(c.ctx, http.MethodPost, "/api/v1/repositories/"+owner+"/"+name+"/refresh", queryValues(map[string]string{"dry_run": "true"}), &plan); err != nil {
+			return nil, fmt.Errorf("failed to plan refresh: %w", err)
+		}
+		return plan, nil
+	}
+
+	plan, err := c.service.PlanRefresh(c.ctx, owner, name, dueOnly)
+	if err != nil {
+		return nil, fmt.Errorf("failed to plan refresh: %w", err)
+	}
+	return plan, nil
+}
+
+// ListWebhookDeliveriesResponse represents a response for listing webhook deliveries
+type ListWebhookDeliveriesResponse struct {
+	Data       []*models.WebhookDelivery `json:"data"`
+	Pagination *Pagination               `json:"pagination"`
+}
+
+// AddWebhook registers a webhook
+func (c *Client) AddWebhook(url, secret string, events []string) (*models.Webhook, error) {
+	hook, err := c.service.AddWebhook(c.ctx, url, secret, events)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add webhook: %w", err)
+	}
+	return hook, nil
+}
+
+// ListWebhooks lists registered webhooks
+func (c *Client) ListWebhooks() ([]*models.Webhook, error) {
+	hooks, err := c.service.ListWebhooks(c.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	return hooks, nil
+}
+
+// RemoveWebhook removes a webhook
+func (c *Client) RemoveWebhook(id int64) error {
+	if err := c.service.DeleteWebhook(c.ctx, id); err != nil {
+		return fmt.Errorf("failed to remove webhook: %w", err)
+	}
+	return nil
+}
+
+// ListWebhookDeliveries lists the delivery logs of a webhook
+func (c *Client) ListWebhookDeliveries(id int64, page, perPage int) (*ListWebhookDeliveriesResponse, error) {
+	deliveries, pagination, err := c.service.ListWebhookDeliveries(c.ctx, id, page, perPage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+
+	return &ListWebhookDeliveriesResponse{
+		Data: deliveries,
 		Pagination: &Pagination{
 			Page:       pagination.Page,
 			PerPage:    pagination.PerPage,
@@ -244,23 +873,682 @@ func (c *Client) ListIssues(params map[string]string) (*ListIssuesResponse, erro
 	}, nil
 }
 
-// RefreshAll forces a refresh of all repository data
-func (c *Client) RefreshAll() error {
-	// Get all repositories
-	err := c.service.RefreshAll(c.ctx)
+// AddSubscription subscribes a channel to a label
+func (c *Client) AddSubscription(sub *models.Subscription) (*models.Subscription, error) {
+	sub, err := c.service.AddSubscription(c.ctx, sub)
 	if err != nil {
-		return fmt.Errorf("failed to refresh all repositories: %w", err)
+		return nil, fmt.Errorf("failed to add subscription: %w", err)
+	}
+	return sub, nil
+}
+
+// ListSubscriptions lists label subscriptions
+func (c *Client) ListSubscriptions() ([]*models.Subscription, error) {
+	subs, err := c.service.ListSubscriptions(c.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subscriptions: %w", err)
+	}
+	return subs, nil
+}
+
+// RemoveSubscription removes a label subscription
+func (c *Client) RemoveSubscription(id int64) error {
+	if err := c.service.DeleteSubscription(c.ctx, id); err != nil {
+		return fmt.Errorf("failed to remove subscription: %w", err)
+	}
+	return nil
+}
+
+// AddTriageRule adds a triage rule
+func (c *Client) AddTriageRule(rule *models.TriageRule) (*models.TriageRule, error) {
+	rule, err := c.service.AddTriageRule(c.ctx, rule)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add triage rule: %w", err)
+	}
+	return rule, nil
+}
+
+// ListTriageRules lists triage rules
+func (c *Client) ListTriageRules() ([]*models.TriageRule, error) {
+	rules, err := c.service.ListTriageRules(c.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list triage rules: %w", err)
+	}
+	return rules, nil
+}
+
+// RemoveTriageRule removes a triage rule and its execution log
+func (c *Client) RemoveTriageRule(id int64) error {
+	if err := c.service.DeleteTriageRule(c.ctx, id); err != nil {
+		return fmt.Errorf("failed to remove triage rule: %w", err)
+	}
+	return nil
+}
+
+// ListTriageExecutions lists the items a triage rule acted on
+func (c *Client) ListTriageExecutions(id int64) ([]*models.TriageExecution, error) {
+	executions, err := c.service.ListTriageExecutions(c.ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list triage executions: %w", err)
+	}
+	return executions, nil
+}
+
+// ListJobsResponse represents a response for listing background jobs
+type ListJobsResponse struct {
+	Data       []*models.Job `json:"data"`
+	Pagination *Pagination   `json:"pagination"`
+}
+
+// ListJobs lists background jobs matching the filter
+func (c *Client) ListJobs(filter *models.JobFilter) (*ListJobsResponse, error) {
+	jobs, pagination, err := c.service.ListJobs(c.ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+
+	return &ListJobsResponse{
+		Data: jobs,
+		Pagination: &Pagination{
+			Page:       pagination.Page,
+			PerPage:    pagination.PerPage,
+			Total:      pagination.Total,
+			TotalPages: pagination.TotalPages,
+		},
+	}, nil
+}
+
+// GetJob gets a background job
+func (c *Client) GetJob(id int64) (*models.Job, error) {
+	var job *models.Job
+	var err error
+	if c.remote != nil {
+		err = c.remote.get(c.ctx, "/api/v1/jobs/"+strconv.FormatInt(id, 10), nil, &job)
+	} else {
+		job, err = c.service.GetJob(c.ctx, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+	return job, nil
+}
+
+// CancelJob cancels a queued or running background job
+func (c *Client) CancelJob(id int64) (*models.Job, error) {
+	job, err := c.service.CancelJob(c.ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel job: %w", err)
+	}
+	return job, nil
+}
+
+// ListActivityResponse represents a response for listing activity events
+type ListActivityResponse struct {
+	Data       []*models.ActivityEvent `json:"data"`
+	Pagination *Pagination             `json:"pagination"`
+}
+
+// ListActivity lists activity events matching the filter
+func (c *Client) ListActivity(filter *models.ActivityFilter) (*ListActivityResponse, error) {
+	events, pagination, err := c.service.ListActivity(c.ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list activity: %w", err)
+	}
+
+	return &ListActivityResponse{
+		Data: events,
+		Pagination: &Pagination{
+			Page:       pagination.Page,
+			PerPage:    pagination.PerPage,
+			Total:      pagination.Total,
+			TotalPages: pagination.TotalPages,
+		},
+	}, nil
+}
+
+// ListChanges returns the changes recorded after a sequence number, oldest first, and the sequence
+// number to read the next changes from
+func (c *Client) ListChanges(since int64, limit int) ([]*models.ChangeRecord, int64, error) {
+	records, next, err := c.service.ListChanges(c.ctx, since, limit)
+	if err != nil {
+		return nil, since, fmt.Errorf("failed to list changes: %w", err)
+	}
+	return records, next, nil
+}
+
+// ListAuditResponse represents a response for listing audit log entries
+type ListAuditResponse struct {
+	Data       []*models.AuditEntry `json:"data"`
+	Pagination *Pagination          `json:"pagination"`
+}
+
+// ListAudit lists audit log entries matching the filter
+func (c *Client) ListAudit(filter *models.AuditFilter) (*ListAuditResponse, error) {
+	var entries []*models.AuditEntry
+	var pagination *models.Pagination
+	var err error
+	if c.remote != nil {
+		var list remoteList[*models.AuditEntry]
+		err = c.remote.get(c.ctx, "/api/v1/audit", queryValues(map[string]string{
+			"actor":    filter.Actor,
+			"action":   filter.Action,
+			"target":   filter.Target,
+			"since":    timeValue(filter.Since),
+			"until":    timeValue(filter.Until),
+			"page":     pageValue(filter.Page),
+			"per_page": pageValue(filter.PerPage),
+		}), &list)
+		entries, pagination = list.Data, list.Pagination
+	} else {
+		entries, pagination, err = c.service.ListAudit(c.ctx, filter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit log: %w", err)
+	}
+
+	return &ListAuditResponse{
+		Data: entries,
+		Pagination: &Pagination{
+			Page:       pagination.Page,
+			PerPage:    pagination.PerPage,
+			Total:      pagination.Total,
+			TotalPages: pagination.TotalPages,
+		},
+	}, nil
+}
+
+// ListItemsResponse represents a response for the unified listing of pull requests and issues
+type ListItemsResponse struct {
+	Data       []*models.Item `json:"data"`
+	Pagination *Pagination    `json:"pagination"`
+}
+
+// ListItems lists pull requests and issues together
+func (c *Client) ListItems(filter *models.ItemFilter) (*ListItemsResponse, error) {
+	var items []*models.Item
+	var pagination *models.Pagination
+	var err error
+	if c.remote != nil {
+		var list remoteList[*models.Item]
+		err = c.remote.get(c.ctx, "/api/v1/items", queryValues(map[string]string{
+			"type":         filter.Type,
+			"state":        filter.State,
+			"author":       filter.Author,
+			"repo":         filter.Repo,
+			"repo_tag":     filter.RepoTag,
+			"label":        filter.Label,
+			"exclude_bots": boolValue(filter.ExcludeBots),
+			"association":  filter.Association,
+			"team":         filter.Team,
+			"jira":         filter.Jira,
+			"direction":    filter.Direction,
+			"since":        timeValue(filter.Since),
+			"cursor":       filter.Cursor,
+			"page":         pageValue(filter.Page),
+			"per_page":     pageValue(filter.PerPage),
+		}), &list)
+		items, pagination = list.Data, list.Pagination
+	} else {
+		items, pagination, err = c.service.ListItems(c.ctx, filter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list items: %w", err)
+	}
+
+	return &ListItemsResponse{
+		Data: items,
+		Pagination: &Pagination{
+			Page:       pagination.Page,
+			PerPage:    pagination.PerPage,
+			Total:      pagination.Total,
+			TotalPages: pagination.TotalPages,
+			NextCursor: pagination.NextCursor,
+			PrevCursor: pagination.PrevCursor,
+		},
+	}, nil
+}
+
+// GetAdminStats returns storage statistics and memory usage
+func (c *Client) GetAdminStats(apiKey string) (*service.AdminStats, error) {
+	stats, err := c.service.GetAdminStats(c.ctx, apiKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get admin stats: %w", err)
+	}
+	return stats, nil
+}
+
+// CompactStorage removes orphaned data from the database
+func (c *Client) CompactStorage(apiKey string) (*models.CompactionResult, error) {
+	result, err := c.service.CompactStorage(c.ctx, apiKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compact storage: %w", err)
+	}
+	return result, nil
+}
+
+// CheckIntegrity checks the references between the stored data, repairing problems with repair
+func (c *Client) CheckIntegrity(apiKey string, repair bool) (*models.IntegrityReport, error) {
+	report, err := c.service.CheckIntegrity(c.ctx, apiKey, repair)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check database integrity: %w", err)
+	}
+	return report, nil
+}
+
+// PushWarehouse pushes the stored data to the configured warehouse
+func (c *Client) PushWarehouse(apiKey string) (*models.WarehousePush, error) {
+	push, err := c.service.PushWarehouse(c.ctx, apiKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to push to the warehouse: %w", err)
+	}
+	return push, nil
+}
+
+// ClearRepositoryData drops the stored items of a repository
+func (c *Client) ClearRepositoryData(apiKey, owner, name string) error {
+	if err := c.service.ClearRepositoryData(c.ctx, apiKey, owner, name); err != nil {
+		return fmt.Errorf("failed to clear repository data: %w", err)
 	}
 	return nil
 }
 
+// GetAnalytics computes lead-time metrics for the filtered items
+func (c *Client) GetAnalytics(filter *models.AnalyticsFilter) (*analytics.Report, error) {
+	report, err := c.service.GetAnalytics(c.ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get analytics: %w", err)
+	}
+	return report, nil
+}
+
+// GetTopics groups open issues into themes by label and title keyword
+func (c *Client) GetTopics(filter *models.TopicFilter) ([]*analytics.Topic, error) {
+	var topics []*analytics.Topic
+	var err error
+	if c.remote != nil {
+		err = c.remote.get(c.ctx, "/api/v1/analytics/topics", queryValues(map[string]string{
+			"repo":         filter.Repo,
+			"repo_tag":     filter.RepoTag,
+			"exclude_bots": boolValue(filter.ExcludeBots),
+			"limit":        strconv.Itoa(filter.Limit),
+		}), &topics)
+	} else {
+		topics, err = c.service.GetTopics(c.ctx, filter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get topics: %w", err)
+	}
+	return topics, nil
+}
+
+// LinkGraph returns the graph of references between the items of tracked repositories
+func (c *Client) LinkGraph(filter *models.LinkFilter) (*models.LinkGraph, error) {
+	var graph *models.LinkGraph
+	var err error
+	if c.remote != nil {
+		err = c.remote.get(c.ctx, "/api/v1/links/repos", queryValues(map[string]string{
+			"repo":     filter.Repo,
+			"repo_tag": filter.RepoTag,
+		}), &graph)
+	} else {
+		graph, err = c.service.LinkGraph(c.ctx, filter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get link graph: %w", err)
+	}
+	return graph, nil
+}
+
+// LeaderboardResponse represents a response for the contributor leaderboard
+type LeaderboardResponse struct {
+	Data       []*analytics.Contribution `json:"data"`
+	Pagination *Pagination               `json:"pagination"`
+}
+
+// GetLeaderboard summarizes contributions per user
+func (c *Client) GetLeaderboard(filter *models.LeaderboardFilter) (*LeaderboardResponse, error) {
+	contributions, pagination, err := c.service.GetLeaderboard(c.ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get leaderboard: %w", err)
+	}
+
+	return &LeaderboardResponse{
+		Data: contributions,
+		Pagination: &Pagination{
+			Page:       pagination.Page,
+			PerPage:    pagination.PerPage,
+			Total:      pagination.Total,
+			TotalPages: pagination.TotalPages,
+		},
+	}, nil
+}
+
 // GetStatus returns the current status of the client
 func (c *Client) GetStatus() (map[string]interface{}, error) {
-	// Get status from service
-	status, err := c.service.GetStatus(c.ctx)
+	// Get status from the server or the service
+	var status map[string]interface{}
+	var err error
+	if c.remote != nil {
+		err = c.remote.get(c.ctx, "/api/v1/status", nil, &status)
+	} else {
+		status, err = c.service.GetStatus(c.ctx)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get status: %w", err)
 	}
 
 	return status, nil
 }
+
+// GetAPIUsage returns the GitHub API requests spent on each repository, most expensive first
+func (c *Client) GetAPIUsage() ([]*models.RepositoryAPIUsage, error) {
+	if c.remote != nil {
+		return nil, fmt.Errorf("API usage per repository is %w", errNotServed)
+	}
+
+	usage, err := c.service.GetAPIUsage(c.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API usage: %w", err)
+	}
+
+	return usage, nil
+}
+
+// CreateWorkspace creates a workspace
+func (c *Client) CreateWorkspace(id, name string, syncInterval time.Duration) (*models.Workspace, error) {
+	workspace, err := c.service.CreateWorkspace(c.ctx, id, name, syncInterval)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create workspace: %w", err)
+	}
+	return workspace, nil
+}
+
+// ListWorkspaces lists workspaces
+func (c *Client) ListWorkspaces() ([]*models.Workspace, error) {
+	var workspaces []*models.Workspace
+	var err error
+	if c.remote != nil {
+		err = c.remote.get(c.ctx, "/api/v1/workspaces", nil, &workspaces)
+	} else {
+		workspaces, err = c.service.ListWorkspaces(c.ctx)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workspaces: %w", err)
+	}
+	return workspaces, nil
+}
+
+// GetWorkspace gets a workspace
+func (c *Client) GetWorkspace(id string) (*models.Workspace, error) {
+	workspace, err := c.service.GetWorkspace(c.ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workspace: %w", err)
+	}
+	return workspace, nil
+}
+
+// UpdateWorkspace updates the name or sync interval of a workspace
+func (c *Client) UpdateWorkspace(id string, update *models.WorkspaceUpdate) (*models.Workspace, error) {
+	workspace, err := c.service.UpdateWorkspace(c.ctx, id, update)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update workspace: %w", err)
+	}
+	return workspace, nil
+}
+
+// DeleteWorkspace deletes a workspace
+func (c *Client) DeleteWorkspace(id string) error {
+	if err := c.service.DeleteWorkspace(c.ctx, id); err != nil {
+		return fmt.Errorf("failed to delete workspace: %w", err)
+	}
+	return nil
+}
+
+// CreateWorkspaceToken issues an API token restricted to a workspace
+func (c *Client) CreateWorkspaceToken(id, name string) (string, *models.WorkspaceToken, error) {
+	raw, token, err := c.service.CreateWorkspaceToken(c.ctx, id, name)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create workspace token: %w", err)
+	}
+	return raw, token, nil
+}
+
+// RevokeWorkspaceToken revokes an API token of a workspace
+func (c *Client) RevokeWorkspaceToken(id string, tokenID int64) error {
+	if err := c.service.RevokeWorkspaceToken(c.ctx, id, tokenID); err != nil {
+		return fmt.Errorf("failed to revoke workspace token: %w", err)
+	}
+	return nil
+}
+
+// ListSecurityAlerts lists the open security alerts of the tracked repositories, most severe first
+func (c *Client) ListSecurityAlerts(filter *models.SecurityAlertFilter) ([]*models.SecurityAlert, error) {
+	var alerts []*models.SecurityAlert
+	var err error
+	if c.remote != nil {
+		query := queryValues(map[string]string{
+			"repo":     filter.Repo,
+			"repo_tag": filter.RepoTag,
+			"kind":     filter.Kind,
+			"severity": filter.Severity,
+		})
+		err = c.remote.get(c.ctx, "/api/v1/alerts", query, &alerts)
+	} else {
+		alerts, err = c.service.ListSecurityAlerts(c.ctx, filter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list security alerts: %w", err)
+	}
+	return alerts, nil
+}
+
+// SummarizeSecurityAlerts counts the open security alerts of each tracked repository by severity
+func (c *Client) SummarizeSecurityAlerts(filter *models.SecurityAlertFilter) ([]*models.SecurityAlertSummary, error) {
+	summaries, err := c.service.SummarizeSecurityAlerts(c.ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize security alerts: %w", err)
+	}
+	return summaries, nil
+}
+
+// ListDiscussionsResponse represents the response from listing discussions
+type ListDiscussionsResponse struct {
+	Data       []*models.Discussion `json:"data"`
+	Pagination *Pagination          `json:"pagination"`
+}
+
+// ListDiscussions lists the synced discussions of the tracked repositories, most recently updated first
+func (c *Client) ListDiscussions(filter *models.DiscussionFilter) (*ListDiscussionsResponse, error) {
+	var discussions []*models.Discussion
+	var pagination *models.Pagination
+	var err error
+	if c.remote != nil {
+		var list remoteList[*models.Discussion]
+		query := queryValues(map[string]string{
+			"state":    filter.State,
+			"author":   filter.Author,
+			"repo":     filter.Repo,
+			"repo_tag": filter.RepoTag,
+			"category": filter.Category,
+			"since":    timeValue(filter.Since),
+			"page":     pageValue(filter.Page),
+			"per_page": pageValue(filter.PerPage),
+		})
+		if filter.Unanswered {
+			query.Set("unanswered", "true")
+		}
+		err = c.remote.get(c.ctx, "/api/v1/discussions", query, &list)
+		discussions, pagination = list.Data, list.Pagination
+	} else {
+		discussions, pagination, err = c.service.ListDiscussions(c.ctx, filter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list discussions: %w", err)
+	}
+
+	return &ListDiscussionsResponse{
+		Data: discussions,
+		Pagination: &Pagination{
+			Page:       pagination.Page,
+			PerPage:    pagination.PerPage,
+			Total:      pagination.Total,
+			TotalPages: pagination.TotalPages,
+		},
+	}, nil
+}
+
+// ReviewQueue lists the open pull requests waiting for review from a reviewer, oldest first
+func (c *Client) ReviewQueue(filter *models.ReviewQueueFilter) ([]*models.ReviewQueueItem, error) {
+	var items []*models.ReviewQueueItem
+	var err error
+	if c.remote != nil {
+		err = c.remote.get(c.ctx, "/api/v1/review-queue", queryValues(map[string]string{
+			"reviewer": filter.Reviewer,
+			"repo":     filter.Repo,
+			"repo_tag": filter.RepoTag,
+		}), &items)
+	} else {
+		items, err = c.service.ReviewQueue(c.ctx, filter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list review queue: %w", err)
+	}
+	return items, nil
+}
+
+// SLAReport lists the open pull requests and issues breaching the configured SLA policies
+func (c *Client) SLAReport(filter *models.SLAFilter) (*models.SLAReport, error) {
+	var report *models.SLAReport
+	var err error
+	if c.remote != nil {
+		err = c.remote.get(c.ctx, "/api/v1/sla", queryValues(map[string]string{
+			"repo":     filter.Repo,
+			"repo_tag": filter.RepoTag,
+			"team":     filter.Team,
+			"policy":   filter.Policy,
+		}), &report)
+	} else {
+		report, err = c.service.SLAReport(c.ctx, filter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to report SLA breaches: %w", err)
+	}
+	return report, nil
+}
+
+// Diff summarizes what changed in the tracked repositories between two times
+func (c *Client) Diff(filter *models.DiffFilter) (*models.FleetDiff, error) {
+	var diff *models.FleetDiff
+	var err error
+	if c.remote != nil {
+		err = c.remote.get(c.ctx, "/api/v1/diff", queryValues(map[string]string{
+			"from":     filter.From.Format(time.RFC3339),
+			"to":       filter.To.Format(time.RFC3339),
+			"repo":     filter.Repo,
+			"repo_tag": filter.RepoTag,
+		}), &diff)
+	} else {
+		diff, err = c.service.Diff(c.ctx, filter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute diff: %w", err)
+	}
+	return diff, nil
+}
+
+// LabelRegistry lists the labels of the tracked repositories grouped by name, with their inconsistencies
+func (c *Client) LabelRegistry(filter *models.LabelFilter) ([]*models.LabelSummary, error) {
+	var labels []*models.LabelSummary
+	var err error
+	if c.remote != nil {
+		query := queryValues(map[string]string{
+			"repo":     filter.Repo,
+			"repo_tag": filter.RepoTag,
+			"name":     filter.Name,
+		})
+		if filter.InconsistentOnly {
+			query.Set("inconsistent", "true")
+		}
+		err = c.remote.get(c.ctx, "/api/v1/labels", query, &labels)
+	} else {
+		labels, err = c.service.LabelRegistry(c.ctx, filter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list labels: %w", err)
+	}
+	return labels, nil
+}
+
+// RenameLabel renames or recolors a label across the tracked repositories, or only plans it
+func (c *Client) RenameLabel(rename *models.LabelRename) ([]*models.LabelChange, error) {
+	changes, err := c.service.RenameLabel(c.ctx, rename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rename label: %w", err)
+	}
+	return changes, nil
+}
+
+// ListProjects lists the projects linked to the tracked repositories
+func (c *Client) ListProjects(filter *models.ProjectFilter) ([]*models.Project, error) {
+	var projects []*models.Project
+	var err error
+	if c.remote != nil {
+		query := queryValues(map[string]string{
+			"repo":     filter.Repo,
+			"repo_tag": filter.RepoTag,
+		})
+		if filter.IncludeClosed {
+			query.Set("include_closed", "true")
+		}
+		err = c.remote.get(c.ctx, "/api/v1/projects", query, &projects)
+	} else {
+		projects, err = c.service.ListProjects(c.ctx, filter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list projects: %w", err)
+	}
+	return projects, nil
+}
+
+// GetProject returns a project and its items of the tracked repositories, only those in a column when status isn't empty
+func (c *Client) GetProject(owner string, number int, status string) (*models.Project, []*models.ProjectItem, error) {
+	var project *models.Project
+	var items []*models.ProjectItem
+	var err error
+	if c.remote != nil {
+		var resp struct {
+			Project *models.Project       `json:"project"`
+			Data    []*models.ProjectItem `json:"data"`
+		}
+		err = c.remote.get(c.ctx, fmt.Sprintf("/api/v1/projects/%s/%d", owner, number), queryValues(map[string]string{"status": status}), &resp)
+		project, items = resp.Project, resp.Data
+	} else {
+		project, items, err = c.service.GetProject(c.ctx, owner, number, status)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get project: %w", err)
+	}
+	return project, items, nil
+}
+
+// ComplianceReport checks the settings of the tracked repositories against the compliance policy
+func (c *Client) ComplianceReport(filter *models.ComplianceFilter) ([]*models.ComplianceResult, error) {
+	var results []*models.ComplianceResult
+	var err error
+	if c.remote != nil {
+		query := queryValues(map[string]string{
+			"repo":     filter.Repo,
+			"repo_tag": filter.RepoTag,
+		})
+		if filter.ViolationsOnly {
+			query.Set("violations_only", "true")
+		}
+		err = c.remote.get(c.ctx, "/api/v1/compliance", query, &results)
+	} else {
+		results, err = c.service.ComplianceReport(c.ctx, filter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get compliance report: %w", err)
+	}
+	return results, nil
+}
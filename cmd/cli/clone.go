@@ -0,0 +1,118 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/siddontang/github-repos-management/internal/localgit"
+	"github.com/spf13/cobra"
+)
+
+// newLinkRepoCmd creates the repo link command
+func newLinkRepoCmd() *cobra.Command {
+	linkCmd := &cobra.Command{
+		Use:   "link [owner/name] [path]",
+		Short: "Associate a repository with its local clone",
+		Long: "Associate a tracked repository with its local clone (the current directory by default), in which 'ghrepos pr checkout' " +
+			"checks out its pull requests. The clone must have a remote pointing to the repository.",
+		Args: cobra.RangeArgs(1, 2),
+		Run: func(cmd *cobra.Command, args []string) {
+			owner, name, err := splitRepoName(args[0])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				os.Exit(exitCode(err))
+			}
+			unset, _ := cmd.Flags().GetBool("unset")
+			path := "."
+			switch {
+			case unset && len(args) == 2:
+				fmt.Fprintf(os.Stderr, "Error: --unset takes no path\n")
+				os.Exit(1)
+			case unset:
+				path = ""
+			case len(args) == 2:
+				path = args[1]
+			}
+
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+			repo, err := client.SetLocalClone(owner, name, path)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error linking repository: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			if repo.LocalPath == "" {
+				fmt.Printf("Repository %s is no longer linked to a local clone\n", repo.FullName)
+				return
+			}
+			fmt.Printf("Repository %s linked to %s\n", repo.FullName, repo.LocalPath)
+		},
+	}
+	linkCmd.Flags().Bool("unset", false, "Remove the association")
+	return linkCmd
+}
+
+// newPRCheckoutCmd creates the pr checkout command
+func newPRCheckoutCmd() *cobra.Command {
+	checkoutCmd := &cobra.Command{
+		Use:   "checkout [owner/name#number]",
+		Short: "Check out a pull request in the local clone",
+		Long: "Fetch a pull request and check out its branch with gh in the clone linked by 'ghrepos repo link', " +
+			"or in the current directory when it is a clone of the repository.",
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			owner, name, number, err := splitItemRef(args[0])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				os.Exit(1)
+			}
+			requireOnline()
+
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+			repo, err := client.GetRepository(owner, name)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error getting repository: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			path, linked := repo.LocalPath, repo.LocalPath != ""
+			if !linked {
+				path = "."
+			}
+			remote, err := localgit.FindRemote(path, repo.FullName, localgit.Hosts())
+			if err != nil {
+				if !linked && (errors.Is(err, localgit.ErrNoRemote) || errors.Is(err, os.ErrNotExist)) {
+					fmt.Fprintf(os.Stderr, "Error: no local clone is linked to %s; run 'ghrepos repo link %s <path>'\n", repo.FullName, repo.FullName)
+				} else {
+					fmt.Fprintf(os.Stderr, "Error reading the clone at %s: %v\n", path, err)
+				}
+				os.Exit(1)
+			}
+
+			opts := localgit.CheckoutOptions{}
+			opts.Branch, _ = cmd.Flags().GetString("branch")
+			opts.Detach, _ = cmd.Flags().GetBool("detach")
+			opts.Force, _ = cmd.Flags().GetBool("force")
+			if linked {
+				fmt.Fprintf(os.Stderr, "Checking out %s#%d in %s\n", repo.FullName, number, path)
+			}
+			if err := localgit.CheckoutPullRequest(path, remote, number, opts, os.Stdout, os.Stderr); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+		},
+	}
+	checkoutCmd.Flags().StringP("branch", "b", "", "Local branch name (default: the head branch of the pull request)")
+	checkoutCmd.Flags().Bool("detach", false, "Check out the head commit without creating a branch")
+	checkoutCmd.Flags().BoolP("force", "f", false, "Reset an existing local branch to the pull request's head")
+	return checkoutCmd
+}
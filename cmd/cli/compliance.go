@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// newComplianceCmd creates the compliance command
+func newComplianceCmd() *cobra.Command {
+	complianceCmd := &cobra.Command{
+		Use:         "compliance",
+		Short:       "Report repositories deviating from the compliance policy",
+		Long:        "Check the captured settings of the tracked repositories (default branch, branch protection, merge methods) against compliance.policy",
+		Annotations: servedAnnotations,
+		Run: func(cmd *cobra.Command, args []string) {
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			filter := &models.ComplianceFilter{}
+			filter.Repo, _ = cmd.Flags().GetString("repo")
+			filter.RepoTag, _ = cmd.Flags().GetString("repo-tag")
+			filter.ViolationsOnly, _ = cmd.Flags().GetBool("violations")
+
+			results, err := client.ComplianceReport(filter)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error checking compliance: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			violating := 0
+			fmt.Printf("%-40s %-10s %-19s %s\n", "REPOSITORY", "COMPLIANT", "CAPTURED", "VIOLATIONS")
+			for _, result := range results {
+				compliant, captured := "yes", "never"
+				if !result.Compliant {
+					compliant = "no"
+					violating++
+				}
+				if result.Settings != nil {
+					captured = result.Settings.CapturedAt.Format("2006-01-02 15:04:05")
+				}
+				fmt.Printf("%-40s %-10s %-19s %s\n", result.Repository, compliant, captured, strings.Join(result.Violations, "; "))
+			}
+			fmt.Printf("\n%d repositories deviate from the policy\n", violating)
+		},
+	}
+	complianceCmd.Flags().StringP("repo", "r", "", "Filter by repository (owner/name)")
+	complianceCmd.Flags().String("repo-tag", "", "Filter by repository tag")
+	complianceCmd.Flags().Bool("violations", false, "Only show repositories deviating from the policy")
+
+	return complianceCmd
+}
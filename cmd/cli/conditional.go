@@ -0,0 +1,46 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// listETag returns an entity tag computed from the content of a list response
+func listETag(data interface{}, pagination *Pagination) string {
+	body, err := json.Marshal(struct {
+		Data       interface{} `json:"data"`
+		Pagination *Pagination `json:"pagination"`
+	}{data, pagination})
+	if err != nil {
+		return ""
+	}
+
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// addConditionalFlags adds the flags used to skip output when a list is unchanged
+func addConditionalFlags(cmd *cobra.Command) {
+	cmd.Flags().String("if-none-match", "", "Print nothing if the list still has this ETag")
+	cmd.Flags().String("if-modified-since", "", "Print nothing if no listed item was updated after this date (YYYY-MM-DD or RFC3339)")
+}
+
+// notModified reports whether the conditional flags match an unchanged list.
+// As with HTTP, --if-none-match takes precedence over --if-modified-since.
+func notModified(cmd *cobra.Command, etag string, lastModified time.Time) (bool, error) {
+	if ifNoneMatch, _ := cmd.Flags().GetString("if-none-match"); ifNoneMatch != "" {
+		return ifNoneMatch == etag, nil
+	}
+
+	value, _ := cmd.Flags().GetString("if-modified-since")
+	since, err := parseTimeFlag(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid if-modified-since date: %w", err)
+	}
+	return !since.IsZero() && !lastModified.After(since), nil
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// newDiffCmd creates the diff command
+func newDiffCmd() *cobra.Command {
+	diffCmd := &cobra.Command{
+		Use:         "diff",
+		Short:       "Summarize what changed in the tracked repositories between two dates",
+		Long:        "Summarize what changed in the tracked repositories between two dates: repositories added and removed, pull requests merged, issues closed, labels added and open item counts, from the activity log and the metric snapshots",
+		Annotations: servedAnnotations,
+		Run: func(cmd *cobra.Command, args []string) {
+			filter := &models.DiffFilter{}
+			fromStr, _ := cmd.Flags().GetString("from")
+			toStr, _ := cmd.Flags().GetString("to")
+			var err error
+			if filter.From, err = parseTimeFlag(fromStr); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: --from must be YYYY-MM-DD or RFC3339: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+			if filter.To, err = parseTimeFlag(toStr); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: --to must be YYYY-MM-DD or RFC3339: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+			filter.Repo, _ = cmd.Flags().GetString("repo")
+			filter.RepoTag, _ = cmd.Flags().GetString("repo-tag")
+
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			diff, err := client.Diff(filter)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error computing diff: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			fmt.Printf("Changes from %s to %s\n", diff.From.Format("2006-01-02 15:04"), diff.To.Format("2006-01-02 15:04"))
+			fmt.Printf("Repositories added: %d, removed: %d\n", len(diff.RepositoriesAdded), len(diff.RepositoriesRemoved))
+			for _, name := range diff.RepositoriesAdded {
+				fmt.Printf("  + %s\n", name)
+			}
+			for _, name := range diff.RepositoriesRemoved {
+				fmt.Printf("  - %s\n", name)
+			}
+			fmt.Printf("Pull requests opened: %d, merged: %d\n", diff.PullRequestsOpened, len(diff.PullRequestsMerged))
+			printDiffItems(diff.PullRequestsMerged)
+			fmt.Printf("Issues opened: %d, closed: %d\n", diff.IssuesOpened, len(diff.IssuesClosed))
+			printDiffItems(diff.IssuesClosed)
+			fmt.Printf("Labels added: %d\n", len(diff.LabelsAdded))
+			printDiffItems(diff.LabelsAdded)
+
+			if len(diff.Repositories) > 0 {
+				fmt.Printf("\n%-40s %-12s %s\n", "REPOSITORY", "OPEN PRS", "OPEN ISSUES")
+				for _, repo := range diff.Repositories {
+					fmt.Printf("%-40s %-12s %s\n", repo.Repository,
+						fmt.Sprintf("%d -> %d", repo.OpenPullRequestsFrom, repo.OpenPullRequestsTo),
+						fmt.Sprintf("%d -> %d", repo.OpenIssuesFrom, repo.OpenIssuesTo))
+				}
+			}
+		},
+	}
+	diffCmd.Flags().String("from", "", "Start of the window (YYYY-MM-DD or RFC3339)")
+	diffCmd.Flags().String("to", "", "End of the window, exclusive (YYYY-MM-DD or RFC3339)")
+	diffCmd.Flags().StringP("repo", "r", "", "Filter by repository (owner/name)")
+	diffCmd.Flags().String("repo-tag", "", "Filter by repository tag")
+	diffCmd.MarkFlagRequired("from")
+	diffCmd.MarkFlagRequired("to")
+	return diffCmd
+}
+
+// printDiffItems prints the pull requests and issues of a diff, one per line
+func printDiffItems(items []*models.DiffItem) {
+	for _, item := range items {
+		line := fmt.Sprintf("  %s %s#%d %s", item.At.Format("2006-01-02"), item.Repository, item.Number, item.Title)
+		if item.Label != "" {
+			line += fmt.Sprintf(" [%s]", item.Label)
+		}
+		fmt.Println(line)
+	}
+}
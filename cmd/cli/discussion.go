@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// newDiscussionCmd creates the discussion command group
+func newDiscussionCmd() *cobra.Command {
+	discussionCmd := &cobra.Command{
+		Use:   "discussion",
+		Short: "Show GitHub discussions",
+		Long:  "Show the discussions of tracked repositories syncing them (ghrepos repo config owner/name --sync-discussions)",
+	}
+
+	// List discussions command
+	listDiscussionCmd := &cobra.Command{
+		Use:         "list",
+		Short:       "List discussions",
+		Annotations: servedAnnotations,
+		Run: func(cmd *cobra.Command, args []string) {
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			filter := &models.DiscussionFilter{}
+			filter.State, _ = cmd.Flags().GetString("state")
+			filter.Author, _ = cmd.Flags().GetString("author")
+			filter.Repo, _ = cmd.Flags().GetString("repo")
+			filter.RepoTag, _ = cmd.Flags().GetString("repo-tag")
+			filter.Category, _ = cmd.Flags().GetString("category")
+			filter.Unanswered, _ = cmd.Flags().GetBool("unanswered")
+			filter.Page, _ = cmd.Flags().GetInt("page")
+			filter.PerPage, _ = cmd.Flags().GetInt("per-page")
+			since, _ := cmd.Flags().GetString("since")
+			if filter.Since, err = parseTimeFlag(since); err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid --since value: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			resp, err := client.ListDiscussions(filter)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error listing discussions: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			fmt.Printf("%-30s %-7s %-15s %-20s %-10s %-9s %s\n", "REPOSITORY", "NUMBER", "CATEGORY", "AUTHOR", "ANSWERED", "COMMENTS", "TITLE")
+			for _, d := range resp.Data {
+				answered := "-"
+				if d.Answerable {
+					answered = "no"
+					if d.Answered {
+						answered = "yes"
+					}
+				}
+				fmt.Printf("%-30s %-7d %-15s %-20s %-10s %-9d %s\n", d.RepositoryFullName, d.Number, d.Category, d.Author, answered, d.Comments, d.Title)
+			}
+
+			fmt.Printf("\nPage %d of %d (Total: %d)\n", resp.Pagination.Page, resp.Pagination.TotalPages, resp.Pagination.Total)
+		},
+	}
+	listDiscussionCmd.Flags().StringP("state", "s", "open", "Filter by state (open, closed, all)")
+	listDiscussionCmd.Flags().StringP("author", "a", "", "Filter by author")
+	listDiscussionCmd.Flags().StringP("repo", "r", "", "Filter by repository (owner/name)")
+	listDiscussionCmd.Flags().String("repo-tag", "", "Filter by repository tag")
+	listDiscussionCmd.Flags().StringP("category", "c", "", "Filter by category (e.g. Q&A)")
+	listDiscussionCmd.Flags().Bool("unanswered", false, "Only show questions without an accepted answer")
+	listDiscussionCmd.Flags().String("since", "", "Only show discussions updated since this date (YYYY-MM-DD or RFC3339)")
+	listDiscussionCmd.Flags().IntP("page", "p", 1, "Page number")
+	listDiscussionCmd.Flags().IntP("per-page", "n", 30, "Items per page")
+
+	discussionCmd.AddCommand(listDiscussionCmd)
+	return discussionCmd
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// newIssueDuplicatesCmd creates the issue duplicates command
+func newIssueDuplicatesCmd() *cobra.Command {
+	duplicatesCmd := &cobra.Command{
+		Use:         "duplicates [owner/name]",
+		Short:       "Report probable duplicate issues",
+		Long:        "List the open issues resembling an earlier open issue of the repository, found after each sync when duplicates.enabled is set, best match first",
+		Args:        cobra.ExactArgs(1),
+		Annotations: servedAnnotations,
+		Run: func(cmd *cobra.Command, args []string) {
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			owner, name, err := splitRepoName(args[0])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			candidates, err := client.ListDuplicates(owner, name)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error listing duplicate issues: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			fmt.Printf("%-6s %-8s %-45s %-8s %s\n", "SCORE", "ISSUE", "TITLE", "OF", "TITLE")
+			for _, c := range candidates {
+				fmt.Printf("%-6.2f %-8s %-45s %-8s %s\n", c.Score, fmt.Sprintf("#%d", c.Number), truncate(c.Title, 45),
+					fmt.Sprintf("#%d", c.DuplicateOf), c.DuplicateOfTitle)
+			}
+			fmt.Printf("\n%d probable duplicates\n", len(candidates))
+		},
+	}
+	return duplicatesCmd
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// newPREditCmd creates the pr edit command
+func newPREditCmd() *cobra.Command {
+	editCmd := &cobra.Command{
+		Use:   "edit [owner/name#number]",
+		Short: "Assign or unassign a pull request or set its milestone",
+		Long: "Add or remove assignees of a pull request and set or remove its milestone, found by title. " +
+			"The stored pull request is updated at once and restored if GitHub rejects the change.",
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			owner, name, number, update := parseItemUpdate(cmd, args[0])
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+			pr, err := client.UpdatePullRequest(owner, name, number, update)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error updating pull request: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+			printItemAssignment(fmt.Sprintf("%s#%d", pr.RepositoryFullName, pr.Number), pr.Assignees, pr.Milestone)
+		},
+	}
+	addItemUpdateFlags(editCmd)
+	return editCmd
+}
+
+// newIssueEditCmd creates the issue edit command
+func newIssueEditCmd() *cobra.Command {
+	editCmd := &cobra.Command{
+		Use:   "edit [owner/name#number]",
+		Short: "Assign or unassign an issue or set its milestone",
+		Long: "Add or remove assignees of an issue and set or remove its milestone, found by title. " +
+			"The stored issue is updated at once and restored if GitHub rejects the change.",
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			owner, name, number, update := parseItemUpdate(cmd, args[0])
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+			issue, err := client.UpdateIssue(owner, name, number, update)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error updating issue: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+			printItemAssignment(fmt.Sprintf("%s#%d", issue.RepositoryFullName, issue.Number), issue.Assignees, issue.Milestone)
+		},
+	}
+	addItemUpdateFlags(editCmd)
+	return editCmd
+}
+
+// addItemUpdateFlags adds the flags of the edit commands
+func addItemUpdateFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSlice("add-assignee", nil, "Assign users, by login")
+	cmd.Flags().StringSlice("remove-assignee", nil, "Unassign users, by login")
+	cmd.Flags().StringP("milestone", "m", "", "Set the milestone, by title")
+	cmd.Flags().Bool("remove-milestone", false, "Remove the milestone")
+}
+
+// parseItemUpdate reads the item reference and the update of an edit command, exiting on invalid input
+func parseItemUpdate(cmd *cobra.Command, ref string) (string, string, int, *models.ItemUpdate) {
+	owner, name, number, err := splitItemRef(ref)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	update := &models.ItemUpdate{}
+	update.AddAssignees, _ = cmd.Flags().GetStringSlice("add-assignee")
+	update.RemoveAssignees, _ = cmd.Flags().GetStringSlice("remove-assignee")
+	removeMilestone, _ := cmd.Flags().GetBool("remove-milestone")
+	if cmd.Flags().Changed("milestone") {
+		if removeMilestone {
+			fmt.Fprintf(os.Stderr, "Error: --milestone and --remove-milestone can't be combined\n")
+			os.Exit(1)
+		}
+		milestone, _ := cmd.Flags().GetString("milestone")
+		update.Milestone = &milestone
+	} else if removeMilestone {
+		none := ""
+		update.Milestone = &none
+	}
+	if len(update.AddAssignees) == 0 && len(update.RemoveAssignees) == 0 && update.Milestone == nil {
+		fmt.Fprintf(os.Stderr, "Error: specify --add-assignee, --remove-assignee, --milestone or --remove-milestone\n")
+		os.Exit(1)
+	}
+	requireOnline()
+	return owner, name, number, update
+}
+
+// printItemAssignment prints the assignees and milestone of an item after an update
+func printItemAssignment(ref string, assignees []string, milestone string) {
+	if len(assignees) == 0 {
+		assignees = []string{"none"}
+	}
+	if milestone == "" {
+		milestone = "none"
+	}
+	fmt.Printf("Updated %s\n", ref)
+	fmt.Printf("  Assignees: %s\n", strings.Join(assignees, ", "))
+	fmt.Printf("  Milestone: %s\n", milestone)
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// newIssueTreeCmd creates the command showing an epic with the issues of its task list
+func newIssueTreeCmd() *cobra.Command {
+	treeCmd := &cobra.Command{
+		Use:   "tree [owner/name] [number]",
+		Short: "Show an issue with the issues of its task list",
+		Long: "Show an issue with the issues listed in the task list of its body, recursively, and how many children of " +
+			"each epic are closed. The state of children that are not synced comes from their check mark.",
+		Args:        cobra.ExactArgs(2),
+		Annotations: servedAnnotations,
+		Run: func(cmd *cobra.Command, args []string) {
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			owner, name, err := splitRepoName(args[0])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				os.Exit(exitCode(err))
+			}
+			number, err := strconv.Atoi(args[1])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid issue number: %s\n", args[1])
+				os.Exit(1)
+			}
+
+			tree, err := client.GetIssueTree(owner, name, number)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error getting issue tree: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			fmt.Println(issueTreeLine(tree, false))
+			printIssueTree(tree.Children, "")
+			if len(tree.TrackedBy) > 0 {
+				fmt.Printf("\nTracked by: %s\n", strings.Join(tree.TrackedBy, ", "))
+			}
+		},
+	}
+	return treeCmd
+}
+
+// printIssueTree prints the children of an issue, indented below prefix
+func printIssueTree(children []*models.IssueTree, prefix string) {
+	for i, child := range children {
+		branch, indent := "├── ", "│   "
+		if i == len(children)-1 {
+			branch, indent = "└── ", "    "
+		}
+		fmt.Println(prefix + branch + issueTreeLine(child, true))
+		printIssueTree(child.Children, prefix+indent)
+	}
+}
+
+// issueTreeLine describes an issue of a tree, with its progress if it is an epic
+func issueTreeLine(node *models.IssueTree, mark bool) string {
+	line := node.Reference
+	if node.Title != "" {
+		line += " " + node.Title
+	}
+	if node.Progress != nil {
+		line += fmt.Sprintf(" [%d/%d closed]", node.Progress.Closed, node.Progress.Total)
+	}
+	if !mark {
+		return line
+	}
+	if node.Closed {
+		return "✓ " + line
+	}
+	return "○ " + line
+}
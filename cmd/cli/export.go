@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// newExportCmd creates the export command group
+func newExportCmd() *cobra.Command {
+	exportCmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export stored data for other systems",
+	}
+
+	changesCmd := &cobra.Command{
+		Use:   "changes",
+		Short: "Export the changes recorded since a sequence number as NDJSON",
+		Long: "Write the changes of the activity log recorded after the --since sequence number as NDJSON, one change per line, " +
+			"oldest first. Changes to pull requests and issues carry the item as stored now. The sequence number to pass next " +
+			"is printed to stderr; with --cursor-file it is read from and saved to a file instead, so that repeated runs each " +
+			"export only what changed since the previous one.",
+		Run: func(cmd *cobra.Command, args []string) {
+			since, _ := cmd.Flags().GetInt64("since")
+			limit, _ := cmd.Flags().GetInt("limit")
+			cursorFile, _ := cmd.Flags().GetString("cursor-file")
+			output, _ := cmd.Flags().GetString("output")
+			if cursorFile != "" {
+				if cmd.Flags().Changed("since") {
+					fmt.Fprintf(os.Stderr, "Error: --since can't be combined with --cursor-file\n")
+					os.Exit(1)
+				}
+				data, err := os.ReadFile(cursorFile)
+				if err != nil && !os.IsNotExist(err) {
+					fmt.Fprintf(os.Stderr, "Error reading cursor file: %v\n", err)
+					os.Exit(1)
+				}
+				if value := strings.TrimSpace(string(data)); value != "" {
+					if since, err = strconv.ParseInt(value, 10, 64); err != nil {
+						fmt.Fprintf(os.Stderr, "Error: cursor file %s doesn't hold a sequence number\n", cursorFile)
+						os.Exit(1)
+					}
+				}
+			}
+
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+			records, next, err := client.ListChanges(since, limit)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			var w io.Writer = os.Stdout
+			if output != "" {
+				f, err := os.OpenFile(output, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error opening output: %v\n", err)
+					os.Exit(1)
+				}
+				defer f.Close()
+				w = f
+			}
+			encoder := json.NewEncoder(w)
+			for _, record := range records {
+				if err := encoder.Encode(record); err != nil {
+					fmt.Fprintf(os.Stderr, "Error writing changes: %v\n", err)
+					os.Exit(1)
+				}
+			}
+
+			if cursorFile != "" {
+				if err := os.WriteFile(cursorFile, []byte(strconv.FormatInt(next, 10)+"\n"), 0644); err != nil {
+					fmt.Fprintf(os.Stderr, "Error saving cursor file: %v\n", err)
+					os.Exit(1)
+				}
+				return
+			}
+			fmt.Fprintf(os.Stderr, "%d changes, next sequence: %d\n", len(records), next)
+		},
+	}
+	changesCmd.Flags().Int64("since", 0, "Export the changes recorded after this sequence number")
+	changesCmd.Flags().Int("limit", 1000, "Maximum number of changes to export")
+	changesCmd.Flags().String("cursor-file", "", "File holding the sequence number to export from, updated after exporting")
+	changesCmd.Flags().StringP("output", "o", "", "Append the changes to a file instead of writing them to stdout")
+
+	exportCmd.AddCommand(changesCmd)
+	return exportCmd
+}
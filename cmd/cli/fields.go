@@ -0,0 +1,242 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// defaultFields are the columns printed when --columns is not given
+const defaultFields = "repository,number,author,state,title"
+
+// itemRow holds the printable fields shared by pull requests and issues
+type itemRow struct {
+	repository  string
+	number      int
+	title       string
+	body        string
+	state       string
+	author      string
+	authorBot   bool
+	association string
+	url         string
+	createdAt   time.Time
+	updatedAt   time.Time
+	closedAt    *time.Time
+	mergedAt    *time.Time
+	size        string // Pull requests only
+}
+
+// pullRequestRow returns the printable fields of a pull request
+func pullRequestRow(pr *models.PullRequest) *itemRow {
+	return &itemRow{
+		repository:  pr.RepositoryFullName,
+		number:      pr.Number,
+		title:       pr.Title,
+		body:        pr.Body,
+		state:       pr.State,
+		author:      pr.UserLogin,
+		authorBot:   pr.UserIsBot,
+		association: pr.AuthorAssociation,
+		url:         pr.HTMLURL,
+		createdAt:   pr.CreatedAt,
+		updatedAt:   pr.UpdatedAt,
+		closedAt:    pr.ClosedAt,
+		mergedAt:    pr.MergedAt,
+		size:        pr.Size(),
+	}
+}
+
+// issueRow returns the printable fields of an issue
+func issueRow(issue *models.Issue) *itemRow {
+	return &itemRow{
+		repository:  issue.RepositoryFullName,
+		number:      issue.Number,
+		title:       issue.Title,
+		body:        issue.Body,
+		state:       issue.State,
+		author:      issue.UserLogin,
+		authorBot:   issue.UserIsBot,
+		association: issue.AuthorAssociation,
+		url:         issue.HTMLURL,
+		createdAt:   issue.CreatedAt,
+		updatedAt:   issue.UpdatedAt,
+		closedAt:    issue.ClosedAt,
+	}
+}
+
+// column describes how a field is printed
+type column struct {
+	name   string
+	header string
+	width  int
+	value  func(row *itemRow) string
+}
+
+// formatOptionalTime formats a time that may be unset
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Format("2006-01-02")
+}
+
+// itemColumns lists the selectable fields in their documented order
+var itemColumns = []column{
+	{"repository", "REPOSITORY", 40, func(r *itemRow) string { return r.repository }},
+	{"number", "NUM", 5, func(r *itemRow) string { return strconv.Itoa(r.number) }},
+	{"author", "AUTHOR", 20, func(r *itemRow) string { return r.author }},
+	{"association", "ASSOCIATION", 22, func(r *itemRow) string { return r.association }},
+	{"bot", "BOT", 5, func(r *itemRow) string { return strconv.FormatBool(r.authorBot) }},
+	{"state", "STATE", 12, func(r *itemRow) string { return r.state }},
+	{"title", "TITLE", 60, func(r *itemRow) string { return r.title }},
+	{"url", "URL", 60, func(r *itemRow) string { return r.url }},
+	{"created_at", "CREATED", 10, func(r *itemRow) string { return r.createdAt.Format("2006-01-02") }},
+	{"updated_at", "UPDATED", 10, func(r *itemRow) string { return r.updatedAt.Format("2006-01-02") }},
+	{"closed_at", "CLOSED", 10, func(r *itemRow) string { return formatOptionalTime(r.closedAt) }},
+	{"merged_at", "MERGED", 10, func(r *itemRow) string { return formatOptionalTime(r.mergedAt) }},
+	{"size", "SIZE", 4, func(r *itemRow) string { return r.size }},
+	{"body", "BODY", 0, func(r *itemRow) string { return strings.Join(strings.Fields(r.body), " ") }},
+}
+
+// columnsFlag returns the --columns flag, or the deprecated --fields flag when given instead
+func columnsFlag(cmd *cobra.Command) string {
+	if cmd.Flags().Changed("fields") && !cmd.Flags().Changed("columns") {
+		fields, _ := cmd.Flags().GetString("fields")
+		return fields
+	}
+	columns, _ := cmd.Flags().GetString("columns")
+	return columns
+}
+
+// parseFields resolves a comma-separated field list into columns
+func parseFields(fields string) ([]column, error) {
+	if fields == "" {
+		fields = defaultFields
+	}
+
+	var columns []column
+	for _, name := range strings.Split(fields, ",") {
+		name = strings.TrimSpace(strings.ToLower(name))
+		found := false
+		for _, col := range itemColumns {
+			if col.name == name {
+				columns = append(columns, col)
+				found = true
+				break
+			}
+		}
+		if !found {
+			names := make([]string, 0, len(itemColumns))
+			for _, col := range itemColumns {
+				names = append(names, col.name)
+			}
+			return nil, fmt.Errorf("unknown field %q (available: %s)", name, strings.Join(names, ", "))
+		}
+	}
+	return columns, nil
+}
+
+// hasField reports whether the columns include a field
+func hasField(columns []column, name string) bool {
+	for _, col := range columns {
+		if col.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// stateColor returns the color of an item's state: open green, merged purple and closed red
+func stateColor(row *itemRow) string {
+	switch {
+	case row.mergedAt != nil:
+		return colorPurple
+	case row.state == "open":
+		return colorGreen
+	case row.state == "closed":
+		return colorRed
+	}
+	return ""
+}
+
+// truncate cuts a value to a number of characters, marking the cut with an ellipsis
+func truncate(value string, width int) string {
+	runes := []rune(value)
+	if len(runes) <= width {
+		return value
+	}
+	if width < 1 {
+		return ""
+	}
+	return string(runes[:width-1]) + "…"
+}
+
+// printRows prints a header and one line per row for the selected columns. Columns are as wide
+// as their longest value, so long values never shift the next ones; on a terminal they are capped
+// at their default widths and the last column is cut at the terminal width.
+func printRows(columns []column, rows []*itemRow) {
+	lines := make([][]string, 0, len(rows)+1)
+	headers := make([]string, len(columns))
+	for i, col := range columns {
+		headers[i] = col.header
+	}
+	lines = append(lines, headers)
+	for _, row := range rows {
+		values := make([]string, len(columns))
+		for i, col := range columns {
+			values[i] = col.value(row)
+		}
+		lines = append(lines, values)
+	}
+
+	width := outputWidth()
+	widths := make([]int, len(columns))
+	for _, values := range lines {
+		for i, value := range values {
+			widths[i] = max(widths[i], utf8.RuneCountInString(value))
+		}
+	}
+	last := len(columns) - 1
+	lastWidth := 0 // Unlimited
+	if width > 0 {
+		used := 0
+		for i, col := range columns[:last] {
+			if col.width > 0 {
+				widths[i] = min(widths[i], col.width)
+			}
+			used += widths[i] + 1
+		}
+		lastWidth = max(width-used, len(columns[last].header))
+	}
+
+	color := colorEnabled()
+	for n, values := range lines {
+		var b strings.Builder
+		for i, value := range values {
+			if i == last {
+				if lastWidth > 0 {
+					value = truncate(value, lastWidth)
+				}
+			} else {
+				value = truncate(value, widths[i])
+			}
+			padding := ""
+			if i < last {
+				padding = strings.Repeat(" ", widths[i]-utf8.RuneCountInString(value)+1)
+			}
+			if color && n > 0 && columns[i].name == "state" {
+				if code := stateColor(rows[n-1]); code != "" {
+					value = code + value + colorReset
+				}
+			}
+			b.WriteString(value + padding)
+		}
+		fmt.Println(b.String())
+	}
+}
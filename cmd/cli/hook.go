@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// newHookCmd creates the webhook command group
+func newHookCmd() *cobra.Command {
+	// Hook command
+	hookCmd := &cobra.Command{
+		Use:   "hook",
+		Short: "Manage outgoing webhooks",
+		Long:  "Register URLs that receive signed JSON events emitted by the service",
+	}
+
+	// Add hook command
+	addHookCmd := &cobra.Command{
+		Use:   "add [url]",
+		Short: "Register a webhook",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			secret, _ := cmd.Flags().GetString("secret")
+			events, _ := cmd.Flags().GetStringSlice("event")
+
+			hook, err := client.AddWebhook(args[0], secret, events)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error adding webhook: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			fmt.Printf("Webhook %d added successfully\n", hook.ID)
+		},
+	}
+	addHookCmd.Flags().String("secret", "", "Secret used to sign payloads (HMAC-SHA256)")
+	addHookCmd.Flags().StringSlice("event", nil, "Event to deliver, may be repeated (default all events)")
+
+	// List hooks command
+	listHookCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List registered webhooks",
+		Run: func(cmd *cobra.Command, args []string) {
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			hooks, err := client.ListWebhooks()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error listing webhooks: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			// Print webhooks
+			fmt.Printf("%-5s %-8s %-40s %s\n", "ID", "SIGNED", "EVENTS", "URL")
+			for _, hook := range hooks {
+				signed := "No"
+				if hook.Secret != "" {
+					signed = "Yes"
+				}
+				events := "all"
+				if len(hook.Events) > 0 {
+					events = strings.Join(hook.Events, ",")
+				}
+				fmt.Printf("%-5d %-8s %-40s %s\n", hook.ID, signed, events, hook.URL)
+			}
+		},
+	}
+
+	// Remove hook command
+	removeHookCmd := &cobra.Command{
+		Use:   "remove [id]",
+		Short: "Remove a webhook",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			id, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid webhook ID: %s\n", args[0])
+				os.Exit(1)
+			}
+
+			if err := client.RemoveWebhook(id); err != nil {
+				fmt.Fprintf(os.Stderr, "Error removing webhook: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			fmt.Printf("Webhook %d removed successfully\n", id)
+		},
+	}
+
+	// List deliveries command
+	deliveriesHookCmd := &cobra.Command{
+		Use:   "deliveries [id]",
+		Short: "Show delivery logs of a webhook",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			id, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid webhook ID: %s\n", args[0])
+				os.Exit(1)
+			}
+
+			page, _ := cmd.Flags().GetInt("page")
+			perPage, _ := cmd.Flags().GetInt("per-page")
+
+			resp, err := client.ListWebhookDeliveries(id, page, perPage)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error listing webhook deliveries: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			// Print deliveries
+			fmt.Printf("%-20s %-22s %-40s %-7s %-9s %s\n", "DELIVERED AT", "EVENT", "REPOSITORY", "STATUS", "ATTEMPTS", "ERROR")
+			for _, delivery := range resp.Data {
+				deliveredAt := delivery.DeliveredAt.Format("2006-01-02 15:04:05")
+				fmt.Printf("%-20s %-22s %-40s %-7d %-9d %s\n", deliveredAt, delivery.Event, delivery.Repository, delivery.StatusCode, delivery.Attempts, delivery.Error)
+			}
+
+			// Print pagination info
+			fmt.Printf("\nPage %d of %d (Total: %d)\n", resp.Pagination.Page, resp.Pagination.TotalPages, resp.Pagination.Total)
+		},
+	}
+	deliveriesHookCmd.Flags().IntP("page", "p", 1, "Page number")
+	deliveriesHookCmd.Flags().IntP("per-page", "n", 10, "Items per page")
+
+	hookCmd.AddCommand(addHookCmd, listHookCmd, removeHookCmd, deliveriesHookCmd)
+	return hookCmd
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/siddontang/github-repos-management/internal/localgit"
+	"github.com/spf13/cobra"
+)
+
+// newImportRepoCmd creates the repo import command
+func newImportRepoCmd() *cobra.Command {
+	importCmd := &cobra.Command{
+		Use:   "import [dir...]",
+		Short: "Suggest untracked repositories cloned locally",
+		Long: "Scan directories (the current one by default) for git clones and list the untracked GitHub repositories their remotes point to, " +
+			"then track them all with --add-all. Remotes of github.com and of the hosts gh is logged in to are recognized.",
+		Run: func(cmd *cobra.Command, args []string) {
+			depth, _ := cmd.Flags().GetInt("depth")
+			if depth < 1 {
+				fmt.Fprintf(os.Stderr, "Error: --depth must be at least 1\n")
+				os.Exit(1)
+			}
+			remoteNames, _ := cmd.Flags().GetStringSlice("remote")
+			if len(args) == 0 {
+				args = []string{"."}
+			}
+
+			// Repositories found, in order, with the clones pointing to each
+			hosts := localgit.Hosts()
+			var fullNames []string
+			paths := make(map[string][]string)
+			for _, dir := range args {
+				clones, err := localgit.Scan(dir, depth, hosts)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error scanning %s: %v\n", dir, err)
+					os.Exit(exitCode(err))
+				}
+				for _, clone := range clones {
+					for _, remote := range clone.Remotes {
+						if len(remoteNames) > 0 && !slices.Contains(remoteNames, remote.Name) {
+							continue
+						}
+						key := strings.ToLower(remote.Repository)
+						if _, ok := paths[key]; !ok {
+							fullNames = append(fullNames, remote.Repository)
+						}
+						if !slices.Contains(paths[key], clone.Path) {
+							paths[key] = append(paths[key], clone.Path)
+						}
+					}
+				}
+			}
+
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+			untracked, err := client.UntrackedRepositories(fullNames)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error checking tracked repositories: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+			if len(untracked) == 0 {
+				fmt.Printf("No untracked repositories found in %d cloned repositories\n", len(fullNames))
+				return
+			}
+
+			if addAll, _ := cmd.Flags().GetBool("add-all"); addAll {
+				results := client.AddRepositories(untracked)
+				failed := printAddResults(results)
+
+				// Link the repositories cloned once to their clone for 'ghrepos pr checkout'
+				linked := 0
+				for _, result := range results {
+					clones := paths[strings.ToLower(result.FullName)]
+					if result.Error != "" || len(clones) != 1 {
+						continue
+					}
+					owner, name, _ := splitRepoName(result.FullName)
+					if _, err := client.SetLocalClone(owner, name, clones[0]); err != nil {
+						fmt.Fprintf(os.Stderr, "Error linking %s to %s: %v\n", result.FullName, clones[0], err)
+						continue
+					}
+					linked++
+				}
+				if linked > 0 {
+					fmt.Printf("%d repositories linked to their local clone\n", linked)
+				}
+				if failed > 0 {
+					os.Exit(1)
+				}
+				return
+			}
+
+			fmt.Printf("%-40s %s\n", "REPOSITORY", "CLONES")
+			for _, fullName := range untracked {
+				fmt.Printf("%-40s %s\n", fullName, strings.Join(paths[strings.ToLower(fullName)], ", "))
+			}
+			fmt.Printf("\n%d untracked repositories; track them with 'ghrepos repo import --add-all' or 'ghrepos repo add'\n", len(untracked))
+		},
+	}
+	importCmd.Flags().Int("depth", 3, "How many directories deep to look for clones")
+	importCmd.Flags().StringSlice("remote", nil, "Only use remotes with these names (e.g. origin,upstream); all by default")
+	importCmd.Flags().Bool("add-all", false, "Track all the untracked repositories found")
+	return importCmd
+}
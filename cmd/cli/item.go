@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// newItemCmd creates the item command, listing pull requests and issues together
+func newItemCmd() *cobra.Command {
+	itemCmd := &cobra.Command{
+		Use:   "item",
+		Short: "Manage pull requests and issues together",
+		Long:  "List pull requests and issues of tracked repositories in a single listing",
+	}
+
+	listItemCmd := &cobra.Command{
+		Use:         "list",
+		Short:       "List pull requests and issues",
+		Annotations: servedAnnotations,
+		Long:        "List pull requests and issues across tracked repositories, each tagged with its type",
+		Run: func(cmd *cobra.Command, args []string) {
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			filter := &models.ItemFilter{}
+			filter.Type, _ = cmd.Flags().GetString("type")
+			filter.State, _ = cmd.Flags().GetString("state")
+			filter.Author, _ = cmd.Flags().GetString("author")
+			filter.Repo, _ = cmd.Flags().GetString("repo")
+			filter.RepoTag, _ = cmd.Flags().GetString("repo-tag")
+			filter.Label, _ = cmd.Flags().GetString("label")
+			filter.ExcludeBots, _ = cmd.Flags().GetBool("exclude-bots")
+			filter.Association, _ = cmd.Flags().GetString("association")
+			filter.Team, _ = cmd.Flags().GetString("team")
+			filter.Jira, _ = cmd.Flags().GetString("jira")
+			filter.Cursor, _ = cmd.Flags().GetString("cursor")
+			filter.Page, _ = cmd.Flags().GetInt("page")
+			filter.PerPage, _ = cmd.Flags().GetInt("per-page")
+			if filter.Type == "pr" {
+				filter.Type = models.ItemTypePullRequest
+			}
+
+			since, _ := cmd.Flags().GetString("since")
+			if filter.Since, err = parseTimeFlag(since); err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid --since value: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			resp, err := client.ListItems(filter)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error listing items: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			if quiet(cmd) {
+				for _, item := range resp.Data {
+					fmt.Printf("%s#%d\n", item.RepositoryFullName, item.Number)
+				}
+				return
+			}
+
+			// Print items
+			fmt.Printf("%-13s %-40s %-6s %-20s %-10s %s\n", "TYPE", "REPOSITORY", "NUM", "AUTHOR", "STATE", "TITLE")
+			for _, item := range resp.Data {
+				fmt.Printf("%-13s %-40s %-6d %-20s %-10s %s\n", item.Type, item.RepositoryFullName, item.Number, item.UserLogin, item.State, item.Title)
+			}
+
+			// Print pagination info
+			fmt.Printf("\nPage %d of %d (Total: %d)\n", resp.Pagination.Page, resp.Pagination.TotalPages, resp.Pagination.Total)
+			printCursors(resp.Pagination)
+		},
+	}
+	listItemCmd.Flags().StringP("type", "t", "", "Filter by type (pull_request, pr or issue)")
+	listItemCmd.Flags().StringP("state", "s", "", "Filter by state (open, closed, merged, all)")
+	listItemCmd.Flags().StringP("author", "a", "", "Filter by author")
+	listItemCmd.Flags().StringP("repo", "r", "", "Filter by repository (owner/name)")
+	listItemCmd.Flags().String("repo-tag", "", "Filter by repository tag")
+	listItemCmd.Flags().StringP("label", "l", "", "Filter by label")
+	listItemCmd.Flags().Bool("exclude-bots", false, "Hide items opened by bots such as dependabot")
+	listItemCmd.Flags().String("team", "", "Only show items assigned to, requesting review from or mentioning a team")
+	listItemCmd.Flags().String("jira", "", "Only show items referencing a Jira issue key (e.g. PROJ-123)")
+	listItemCmd.Flags().String("association", "", "Filter by author association, comma separated (e.g. MEMBER,CONTRIBUTOR,FIRST_TIMER)")
+	listItemCmd.Flags().String("since", "", "Only show items updated at or after this time (YYYY-MM-DD or RFC3339)")
+	listItemCmd.Flags().String("cursor", "", "Continue from a cursor printed by a previous list")
+	listItemCmd.Flags().IntP("page", "p", 1, "Page number")
+	listItemCmd.Flags().IntP("per-page", "n", 20, "Items per page")
+	addQuietFlag(listItemCmd)
+
+	itemCmd.AddCommand(listItemCmd)
+	return itemCmd
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// newJobCmd creates the job command group
+func newJobCmd() *cobra.Command {
+	jobCmd := &cobra.Command{
+		Use:   "job",
+		Short: "Inspect background jobs",
+		Long:  "List, inspect and cancel background jobs such as repository syncs",
+	}
+
+	// List jobs command
+	listJobCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List jobs, newest first",
+		Run: func(cmd *cobra.Command, args []string) {
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			filter := &models.JobFilter{}
+			filter.Type, _ = cmd.Flags().GetString("type")
+			filter.State, _ = cmd.Flags().GetString("state")
+			filter.Target, _ = cmd.Flags().GetString("target")
+			filter.Page, _ = cmd.Flags().GetInt("page")
+			filter.PerPage, _ = cmd.Flags().GetInt("per-page")
+
+			resp, err := client.ListJobs(filter)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error listing jobs: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			// Print jobs
+			fmt.Printf("%-6s %-18s %-40s %-10s %-9s %-20s %s\n", "ID", "TYPE", "TARGET", "STATE", "ATTEMPTS", "CREATED", "ERROR")
+			for _, job := range resp.Data {
+				fmt.Printf("%-6d %-18s %-40s %-10s %-9s %-20s %s\n", job.ID, job.Type, job.Target, job.State,
+					fmt.Sprintf("%d/%d", job.Attempts, job.MaxAttempts), job.CreatedAt.Format("2006-01-02 15:04:05"), job.Error)
+			}
+
+			// Print pagination info
+			fmt.Printf("\nPage %d of %d (Total: %d)\n", resp.Pagination.Page, resp.Pagination.TotalPages, resp.Pagination.Total)
+		},
+	}
+	listJobCmd.Flags().String("type", "", "Filter by job type (e.g. sync_repository)")
+	listJobCmd.Flags().String("state", "", "Filter by state (queued, running, succeeded, failed, canceled)")
+	listJobCmd.Flags().String("target", "", "Filter by target (e.g. owner/name)")
+	listJobCmd.Flags().IntP("page", "p", 1, "Page number")
+	listJobCmd.Flags().IntP("per-page", "n", 20, "Items per page")
+
+	// Show job command
+	showJobCmd := &cobra.Command{
+		Use:         "show [id]",
+		Short:       "Show a job",
+		Annotations: servedAnnotations,
+		Args:        cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			id, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid job ID: %s\n", args[0])
+				os.Exit(1)
+			}
+
+			job, err := client.GetJob(id)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error getting job: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			fmt.Printf("Job %d: %s %s\n", job.ID, job.Type, job.Target)
+			fmt.Printf("  State: %s\n", job.State)
+			fmt.Printf("  Attempts: %d of %d\n", job.Attempts, job.MaxAttempts)
+			fmt.Printf("  Created: %s\n", job.CreatedAt.Format("2006-01-02 15:04:05"))
+			if !job.StartedAt.IsZero() {
+				fmt.Printf("  Started: %s\n", job.StartedAt.Format("2006-01-02 15:04:05"))
+			}
+			if !job.FinishedAt.IsZero() {
+				fmt.Printf("  Finished: %s\n", job.FinishedAt.Format("2006-01-02 15:04:05"))
+			}
+			if job.Error != "" {
+				fmt.Printf("  Error: %s\n", job.Error)
+			}
+		},
+	}
+
+	// Cancel job command
+	cancelJobCmd := &cobra.Command{
+		Use:   "cancel [id]",
+		Short: "Cancel a queued or running job",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			id, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid job ID: %s\n", args[0])
+				os.Exit(1)
+			}
+
+			if _, err := client.CancelJob(id); err != nil {
+				fmt.Fprintf(os.Stderr, "Error canceling job: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			fmt.Printf("Job %d canceled successfully\n", id)
+		},
+	}
+
+	jobCmd.AddCommand(listJobCmd, showJobCmd, cancelJobCmd)
+	return jobCmd
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// newLabelCmd creates the label command group
+func newLabelCmd() *cobra.Command {
+	labelCmd := &cobra.Command{
+		Use:   "label",
+		Short: "Manage labels across tracked repositories",
+		Long:  "Compare the label sets of the tracked repositories, synced with their issue templates when github.sync_labels is enabled, and rename or recolor labels across them",
+	}
+
+	// List labels command
+	listLabelCmd := &cobra.Command{
+		Use:         "list",
+		Short:       "List labels grouped by name with their inconsistencies",
+		Long:        "List the labels of the tracked repositories grouped by name ignoring case, reporting differing colors or spellings and issue templates applying missing labels",
+		Annotations: servedAnnotations,
+		Run: func(cmd *cobra.Command, args []string) {
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			filter := &models.LabelFilter{}
+			filter.Repo, _ = cmd.Flags().GetString("repo")
+			filter.RepoTag, _ = cmd.Flags().GetString("repo-tag")
+			filter.Name, _ = cmd.Flags().GetString("name")
+			filter.InconsistentOnly, _ = cmd.Flags().GetBool("inconsistent")
+
+			labels, err := client.LabelRegistry(filter)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error listing labels: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			inconsistent := 0
+			fmt.Printf("%-30s %-13s %-9s %s\n", "NAME", "REPOSITORIES", "COLORS", "INCONSISTENCIES")
+			for _, label := range labels {
+				colors := make(map[string]bool)
+				for _, variant := range label.Variants {
+					colors[variant.Color] = true
+				}
+				if len(label.Inconsistencies) > 0 {
+					inconsistent++
+				}
+				fmt.Printf("%-30s %-13d %-9d %s\n", label.Name, label.Repositories, len(colors), strings.Join(label.Inconsistencies, "; "))
+			}
+			fmt.Printf("\n%d labels, %d inconsistent\n", len(labels), inconsistent)
+		},
+	}
+	listLabelCmd.Flags().StringP("repo", "r", "", "Filter by repository (owner/name)")
+	listLabelCmd.Flags().String("repo-tag", "", "Filter by repository tag")
+	listLabelCmd.Flags().String("name", "", "Only show the label with this name, ignoring case")
+	listLabelCmd.Flags().Bool("inconsistent", false, "Only show labels with inconsistencies")
+
+	// Rename label command
+	renameLabelCmd := &cobra.Command{
+		Use:   "rename [label] [new-name]",
+		Short: "Rename or recolor a label across repositories",
+		Long: "Rename a label, recolor it with --color, or both, in every selected repository having it. " +
+			"Use --dry-run to see the plan first; repositories lacking the label or already having the new name are skipped.",
+		Args: cobra.RangeArgs(1, 2),
+		Run: func(cmd *cobra.Command, args []string) {
+			rename := &models.LabelRename{Label: args[0]}
+			if len(args) == 2 {
+				rename.NewName = args[1]
+			}
+			rename.Color, _ = cmd.Flags().GetString("color")
+			rename.Repo, _ = cmd.Flags().GetString("repo")
+			rename.RepoTag, _ = cmd.Flags().GetString("repo-tag")
+			rename.DryRun, _ = cmd.Flags().GetBool("dry-run")
+			allRepos, _ := cmd.Flags().GetBool("all-repos")
+			if rename.NewName == "" && rename.Color == "" {
+				fmt.Fprintf(os.Stderr, "Error: specify a new name or --color\n")
+				os.Exit(1)
+			}
+			if !allRepos && rename.Repo == "" && rename.RepoTag == "" {
+				fmt.Fprintf(os.Stderr, "Error: specify --repo, --repo-tag or --all-repos\n")
+				os.Exit(1)
+			}
+
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			changes, err := client.RenameLabel(rename)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error renaming label: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			counts := make(map[string]int)
+			fmt.Printf("%-40s %-8s %-25s %-25s %s\n", "REPOSITORY", "STATUS", "LABEL", "CHANGE", "REASON")
+			for _, change := range changes {
+				counts[change.Status]++
+				fmt.Printf("%-40s %-8s %-25s %-25s %s\n", change.Repository, change.Status, change.Label, labelChangeSummary(change), change.Reason)
+			}
+			fmt.Printf("\n%d planned, %d updated, %d skipped, %d failed\n", counts[models.LabelChangePlanned],
+				counts[models.LabelChangeUpdated], counts[models.LabelChangeSkipped], counts[models.LabelChangeFailed])
+			if counts[models.LabelChangeFailed] > 0 {
+				os.Exit(1)
+			}
+		},
+	}
+	renameLabelCmd.Flags().String("color", "", "New hex color (e.g. d73a4a)")
+	renameLabelCmd.Flags().StringP("repo", "r", "", "Only change the label in a repository (owner/name)")
+	renameLabelCmd.Flags().String("repo-tag", "", "Only change the label in repositories with a tag")
+	renameLabelCmd.Flags().Bool("all-repos", false, "Change the label in every tracked repository")
+	renameLabelCmd.Flags().Bool("dry-run", false, "Show the planned changes without making them")
+
+	labelCmd.AddCommand(listLabelCmd, renameLabelCmd)
+	return labelCmd
+}
+
+// labelChangeSummary describes the new name and color of a label change
+func labelChangeSummary(change *models.LabelChange) string {
+	var parts []string
+	if change.NewName != "" {
+		parts = append(parts, "-> "+change.NewName)
+	}
+	if change.NewColor != "" {
+		parts = append(parts, "#"+change.NewColor)
+	}
+	return strings.Join(parts, " ")
+}
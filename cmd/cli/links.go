@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// newDepsRepoCmd creates the command showing the references between tracked repositories
+func newDepsRepoCmd() *cobra.Command {
+	depsCmd := &cobra.Command{
+		Use:   "deps",
+		Short: "Show the references between tracked repositories",
+		Long: "Show how many pull requests and issues of each tracked repository reference items of another, such as " +
+			"org/api#12 or its GitHub URL in their title or body, most references first",
+		Annotations: servedAnnotations,
+		Run: func(cmd *cobra.Command, args []string) {
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			filter := &models.LinkFilter{}
+			filter.Repo, _ = cmd.Flags().GetString("repo")
+			filter.RepoTag, _ = cmd.Flags().GetString("repo-tag")
+			items, _ := cmd.Flags().GetBool("items")
+
+			graph, err := client.LinkGraph(filter)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error getting the link graph: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			if items {
+				fmt.Printf("%-40s %-40s %s\n", "FROM", "TO", "TITLE")
+				for _, link := range graph.Items {
+					fmt.Printf("%-40s %-40s %s\n", link.From, link.To, truncate(link.FromTitle, 60))
+				}
+				fmt.Printf("\n%d references\n", len(graph.Items))
+				return
+			}
+			fmt.Printf("%-40s %-40s %s\n", "FROM", "TO", "REFERENCES")
+			for _, link := range graph.Repositories {
+				fmt.Printf("%-40s %-40s %d\n", link.From, link.To, link.References)
+			}
+			fmt.Printf("\n%d repository links\n", len(graph.Repositories))
+		},
+	}
+	depsCmd.Flags().StringP("repo", "r", "", "Only show links from or to a repository (owner/name)")
+	depsCmd.Flags().String("repo-tag", "", "Only show links from or to repositories with a tag")
+	depsCmd.Flags().Bool("items", false, "List the referencing items instead of counting them per repository")
+	return depsCmd
+}
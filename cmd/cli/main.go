@@ -2,14 +2,21 @@ package main
 
 import (
 	"fmt"
+	"io"
+	"log"
 	"os"
+	"strconv"
 	"strings"
 
+	"github.com/siddontang/github-repos-management/internal/models"
 	"github.com/spf13/cobra"
 )
 
 var (
-	verbose bool
+	verbose    bool
+	configPath string
+	readOnly   bool
+	workspace  string
 )
 
 func main() {
@@ -21,11 +28,24 @@ func main() {
 		PersistentPreRun: func(cmd *cobra.Command, args []string) {
 			// No need to initialize client here as each command creates its own client
 			cmd.SetContext(cmd.Context())
+			servedCommand = cmd.Annotations[servedAnnotation] == "true"
+			if err := validateColorMode(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+		},
+		PersistentPostRun: func(cmd *cobra.Command, args []string) {
+			stopLocalServices()
 		},
 	}
 
 	// Add global flags
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output")
+	rootCmd.PersistentFlags().StringVarP(&configPath, "config", "c", "", "Path to the configuration file")
+	rootCmd.PersistentFlags().BoolVar(&readOnly, "read-only", false, "Open the local database read-only, e.g. while a server has it open")
+	rootCmd.PersistentFlags().StringVar(&workspace, "workspace", "", "Restrict commands to the repositories of a workspace (also GHREPOS_WORKSPACE)")
+	addModeFlags(rootCmd)
+	addColorFlag(rootCmd)
 
 	// Repository command
 	repoCmd := &cobra.Command{
@@ -36,63 +56,152 @@ func main() {
 
 	// Add repository command
 	addRepoCmd := &cobra.Command{
-		Use:   "add [owner/name]",
-		Short: "Add a repository to track",
-		Args:  cobra.ExactArgs(1),
+		Use:   "add [owner/name...]",
+		Short: "Add repositories to track",
+		Long:  "Add one or more repositories, or every repository of an organization with --org",
 		Run: func(cmd *cobra.Command, args []string) {
+			org, _ := cmd.Flags().GetString("org")
+			if (org == "") == (len(args) == 0) {
+				fmt.Fprintf(os.Stderr, "Error: specify either repositories or --org\n")
+				os.Exit(1)
+			}
+
 			client, err := NewClient()
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
-				os.Exit(1)
+				os.Exit(exitCode(err))
 			}
 
-			repo, err := client.AddRepository(args[0])
+			if len(args) == 1 {
+				repo, err := client.AddRepository(args[0])
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error adding repository: %v\n", err)
+					os.Exit(exitCode(err))
+				}
+
+				fmt.Printf("Repository %s added successfully\n", repo.FullName)
+				return
+			}
+
+			var results []*models.RepositoryAddResult
+			if org != "" {
+				results, err = client.AddOrganizationRepositories(org)
+			} else {
+				results = client.AddRepositories(args)
+			}
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error adding repository: %v\n", err)
+				fmt.Fprintf(os.Stderr, "Error adding repositories: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			if failed := printAddResults(results); failed > 0 {
 				os.Exit(1)
 			}
+		},
+	}
+	addRepoCmd.Flags().String("org", "", "Add every non-archived repository of this organization")
 
-			fmt.Printf("Repository %s added successfully\n", repo.FullName)
+	// Discover repositories command
+	discoverRepoCmd := &cobra.Command{
+		Use:   "discover",
+		Short: "Suggest untracked repositories you own, star or contribute to",
+		Long:  "List the non-archived repositories the authenticated GitHub user owns, stars or has contributed to that are not tracked yet, and track them all with --add-all",
+		Run: func(cmd *cobra.Command, args []string) {
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			relations, _ := cmd.Flags().GetStringSlice("relation")
+			repos, err := client.DiscoverRepositories(relations)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error discovering repositories: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+			if len(repos) == 0 {
+				fmt.Println("No untracked repositories found")
+				return
+			}
+
+			if addAll, _ := cmd.Flags().GetBool("add-all"); addAll {
+				fullNames := make([]string, 0, len(repos))
+				for _, repo := range repos {
+					fullNames = append(fullNames, repo.FullName)
+				}
+				if failed := printAddResults(client.AddRepositories(fullNames)); failed > 0 {
+					os.Exit(1)
+				}
+				return
+			}
+
+			fmt.Printf("%-40s %-8s %-28s %s\n", "REPOSITORY", "PRIVATE", "RELATIONS", "DESCRIPTION")
+			for _, repo := range repos {
+				fmt.Printf("%-40s %-8t %-28s %s\n", repo.FullName, repo.Private, strings.Join(repo.Relations, ","), repo.Description)
+			}
+			fmt.Printf("\n%d untracked repositories; track them with 'ghrepos repo discover --add-all' or 'ghrepos repo add'\n", len(repos))
 		},
 	}
+	discoverRepoCmd.Flags().StringSlice("relation", nil, "Only look at repositories with this relation: owner, starred or contributor (default all)")
+	discoverRepoCmd.Flags().Bool("add-all", false, "Track every discovered repository")
 
 	// List repositories command
 	listRepoCmd := &cobra.Command{
-		Use:   "list",
-		Short: "List tracked repositories",
+		Use:         "list",
+		Short:       "List tracked repositories",
+		Annotations: servedAnnotations,
 		Run: func(cmd *cobra.Command, args []string) {
 			client, err := NewClient()
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
-				os.Exit(1)
+				os.Exit(exitCode(err))
 			}
 
+			tag, _ := cmd.Flags().GetString("tag")
+			cursor, _ := cmd.Flags().GetString("cursor")
 			page, _ := cmd.Flags().GetInt("page")
 			perPage, _ := cmd.Flags().GetInt("per-page")
 
-			resp, err := client.ListRepositories(page, perPage)
+			tmpl := mustParseTemplate(cmd)
+
+			resp, err := client.ListRepositories(tag, cursor, page, perPage)
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Error listing repositories: %v\n", err)
-				os.Exit(1)
+				os.Exit(exitCode(err))
+			}
+			if tmpl != nil || quiet(cmd) {
+				for _, repo := range resp.Data {
+					if tmpl != nil {
+						printTemplate(tmpl, repo)
+					} else {
+						fmt.Println(repo.FullName)
+					}
+				}
+				return
 			}
 
 			// Print repositories
-			fmt.Printf("%-40s %-20s %-20s %s\n", "REPOSITORY", "PRIVATE", "LAST SYNCED", "URL")
+			fmt.Printf("%-40s %-20s %-20s %-20s %s\n", "REPOSITORY", "PRIVATE", "LAST SYNCED", "TAGS", "URL")
 			for _, repo := range resp.Data {
 				lastSynced := repo.LastSyncedAt.Format("2006-01-02 15:04:05")
 				isPrivate := "No"
 				if repo.IsPrivate {
 					isPrivate = "Yes"
 				}
-				fmt.Printf("%-40s %-20s %-20s %s\n", repo.FullName, isPrivate, lastSynced, repo.HTMLURL)
+				fmt.Printf("%-40s %-20s %-20s %-20s %s\n", repo.FullName, isPrivate, lastSynced, strings.Join(repo.Tags, ","), repo.HTMLURL)
 			}
 
 			// Print pagination info
 			fmt.Printf("\nPage %d of %d (Total: %d)\n", resp.Pagination.Page, resp.Pagination.TotalPages, resp.Pagination.Total)
+			printCursors(resp.Pagination)
 		},
 	}
+	listRepoCmd.Flags().StringP("tag", "t", "", "Filter by repository tag")
 	listRepoCmd.Flags().IntP("page", "p", 1, "Page number")
 	listRepoCmd.Flags().IntP("per-page", "n", 10, "Items per page")
+	listRepoCmd.Flags().String("cursor", "", "Continue from a cursor printed by a previous list instead of using --page")
+	addTemplateFlag(listRepoCmd, "{{.FullName}} {{.HTMLURL}}")
+	addQuietFlag(listRepoCmd)
 
 	// Remove repository command
 	removeRepoCmd := &cobra.Command{
@@ -103,7 +212,7 @@ func main() {
 			client, err := NewClient()
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
-				os.Exit(1)
+				os.Exit(exitCode(err))
 			}
 
 			parts := strings.Split(args[0], "/")
@@ -116,7 +225,7 @@ func main() {
 			err = client.RemoveRepository(owner, name)
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Error removing repository: %v\n", err)
-				os.Exit(1)
+				os.Exit(exitCode(err))
 			}
 
 			fmt.Printf("Repository %s removed successfully\n", args[0])
@@ -125,24 +234,78 @@ func main() {
 
 	// Refresh repository command
 	refreshRepoCmd := &cobra.Command{
-		Use:   "refresh [owner/name]",
-		Short: "Refresh repository data",
-		Args:  cobra.MaximumNArgs(1),
+		Use:         "refresh [owner/name]",
+		Short:       "Refresh repository data",
+		Annotations: servedAnnotations,
+		Args:        cobra.MaximumNArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
 			client, err := NewClient()
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			due, _ := cmd.Flags().GetBool("due")
+			if due && len(args) != 0 {
+				fmt.Fprintf(os.Stderr, "--due cannot be combined with a repository argument\n")
 				os.Exit(1)
 			}
 
+			if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+				var owner, name string
+				if len(args) == 1 {
+					owner, name, err = splitRepoName(args[0])
+					if err != nil {
+						fmt.Fprintf(os.Stderr, "%v\n", err)
+						os.Exit(exitCode(err))
+					}
+				}
+
+				plan, err := client.PlanRefresh(owner, name, due)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error planning refresh: %v\n", err)
+					os.Exit(exitCode(err))
+				}
+				verbose, _ := cmd.Flags().GetBool("verbose")
+				printRefreshPlan(plan, verbose)
+				return
+			}
+
+			if due {
+
+				// Refresh repositories whose sync interval has elapsed
+				refreshed, err := client.RefreshDue()
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error refreshing repositories: %v\n", err)
+					os.Exit(exitCode(err))
+				}
+				fmt.Printf("%d repositories refreshed successfully\n", refreshed)
+				return
+			}
+
 			if len(args) == 0 {
-				// Refresh all repositories
-				err = client.RefreshAll()
+				// Refresh all repositories, showing their progress on stderr
+				progress := newRefreshProgress(os.Stderr)
+				stdout := os.Stdout
+				if progress.live {
+					// Failed syncs are listed by the view; the service log and the gh client's
+					// command output would tear it
+					log.SetOutput(io.Discard)
+					if devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0); err == nil {
+						os.Stdout = devNull
+					}
+				}
+				jobs, err := client.RefreshAll(progress.update)
+				progress.finish()
+				os.Stdout = stdout
 				if err != nil {
 					fmt.Fprintf(os.Stderr, "Error refreshing repositories: %v\n", err)
+					os.Exit(exitCode(err))
+				}
+				if !printRefreshFailures(jobs) {
 					os.Exit(1)
 				}
-				fmt.Println("All repositories refreshed successfully")
+				fmt.Printf("All %d repositories refreshed successfully\n", len(jobs))
 			} else {
 				// Refresh specific repository
 				parts := strings.Split(args[0], "/")
@@ -152,16 +315,340 @@ func main() {
 				}
 				owner, name := parts[0], parts[1]
 
-				err = client.RefreshRepository(owner, name)
+				timeout, _ := cmd.Flags().GetDuration("timeout")
+				job, err := client.RefreshRepository(owner, name, timeout)
 				if err != nil {
 					fmt.Fprintf(os.Stderr, "Error refreshing repository: %v\n", err)
+					os.Exit(exitCode(err))
+				}
+				switch job.State {
+				case models.JobStateSucceeded:
+					fmt.Printf("Repository %s refreshed successfully\n", args[0])
+				case models.JobStateFailed:
+					fmt.Fprintf(os.Stderr, "Error refreshing repository: %s\n", job.Error)
+					os.Exit(1)
+				default:
+					fmt.Fprintf(os.Stderr, "Refresh of %s (job %d) did not finish within %s\n", args[0], job.ID, timeout)
 					os.Exit(1)
 				}
-				fmt.Printf("Repository %s refreshed successfully\n", args[0])
 			}
 		},
 	}
 
+	refreshRepoCmd.Flags().Bool("due", false, "Only refresh repositories whose sync interval has elapsed")
+	refreshRepoCmd.Flags().Duration("timeout", 0, "Give up waiting for a single repository refresh after this long (0 waits until it finishes)")
+	refreshRepoCmd.Flags().IntVar(&jobWorkers, "workers", 0, "Repositories synced at once when refreshing all (0 uses jobs.workers)")
+	refreshRepoCmd.Flags().Bool("dry-run", false, "Show what the refresh would fetch without running it")
+	refreshRepoCmd.Flags().BoolP("verbose", "v", false, "With --dry-run, also list repositories that would be skipped")
+
+	// Repository sync configuration command
+	configRepoCmd := &cobra.Command{
+		Use:   "config [owner/name]",
+		Short: "Show or update repository sync configuration",
+		Long:  "Show the sync configuration of a repository, or override the global sync settings for it when flags are given",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			owner, name, err := splitRepoName(args[0])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			// Only apply the flags that were explicitly set
+			update := &models.RepositorySyncConfigUpdate{}
+			if cmd.Flags().Changed("sync-interval") {
+				interval, _ := cmd.Flags().GetDuration("sync-interval")
+				update.SyncInterval = &interval
+			}
+			if cmd.Flags().Changed("sync-prs") {
+				enabled, _ := cmd.Flags().GetBool("sync-prs")
+				update.SyncPullRequests = &enabled
+			}
+			if cmd.Flags().Changed("sync-issues") {
+				enabled, _ := cmd.Flags().GetBool("sync-issues")
+				update.SyncIssues = &enabled
+			}
+			if cmd.Flags().Changed("sync-reviews") {
+				enabled, _ := cmd.Flags().GetBool("sync-reviews")
+				update.SyncReviews = &enabled
+			}
+			if cmd.Flags().Changed("sync-discussions") {
+				enabled, _ := cmd.Flags().GetBool("sync-discussions")
+				update.SyncDiscussions = &enabled
+			}
+			if cmd.Flags().Changed("item-limit") {
+				limit, _ := cmd.Flags().GetInt("item-limit")
+				update.ItemLimit = &limit
+			}
+			if cmd.Flags().Changed("priority") {
+				priority, _ := cmd.Flags().GetString("priority")
+				update.Priority = &priority
+			}
+
+			var repo *models.Repository
+			if *update == (models.RepositorySyncConfigUpdate{}) {
+				repo, err = client.GetRepository(owner, name)
+			} else {
+				repo, err = client.UpdateRepositorySyncConfig(owner, name, update)
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error configuring repository: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			// Print sync configuration
+			syncConfig := repo.SyncConfig
+			interval := "default"
+			if syncConfig.SyncInterval > 0 {
+				interval = syncConfig.SyncInterval.String()
+			}
+			limit := "default"
+			if syncConfig.ItemLimit > 0 {
+				limit = fmt.Sprintf("%d", syncConfig.ItemLimit)
+			}
+			fmt.Printf("Sync configuration for %s:\n", repo.FullName)
+			fmt.Printf("  Paused: %t\n", repo.Paused)
+			fmt.Printf("  Sync Interval: %s\n", interval)
+			fmt.Printf("  Pull Requests: %t\n", syncConfig.ShouldSyncPullRequests())
+			fmt.Printf("  Issues: %t\n", syncConfig.ShouldSyncIssues())
+			fmt.Printf("  Reviews: %t\n", syncConfig.ShouldSyncReviews())
+			fmt.Printf("  Discussions: %t\n", syncConfig.ShouldSyncDiscussions())
+			fmt.Printf("  Item Limit: %s\n", limit)
+			fmt.Printf("  Priority: %s\n", syncConfig.PriorityClass())
+		},
+	}
+	configRepoCmd.Flags().Duration("sync-interval", 0, "Sync interval for this repository (0 uses the global interval)")
+	configRepoCmd.Flags().Bool("sync-prs", true, "Sync pull requests")
+	configRepoCmd.Flags().Bool("sync-issues", true, "Sync issues")
+	configRepoCmd.Flags().Bool("sync-reviews", true, "Sync reviews")
+	configRepoCmd.Flags().Bool("sync-discussions", false, "Sync discussions, for repositories using them instead of issues")
+	configRepoCmd.Flags().Int("item-limit", 0, "Maximum items fetched per sync (0 uses the default)")
+	configRepoCmd.Flags().String("priority", "", "Sync priority class: high, normal or low; higher classes are refreshed first")
+
+	// Pause repository command
+	pauseRepoCmd := &cobra.Command{
+		Use:   "pause [owner/name]",
+		Short: "Exclude a repository from scheduled refreshes",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			owner, name, err := splitRepoName(args[0])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			repo, err := client.PauseRepository(owner, name)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error pausing repository: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			fmt.Printf("Repository %s paused\n", repo.FullName)
+		},
+	}
+
+	// Resume repository command
+	resumeRepoCmd := &cobra.Command{
+		Use:   "resume [owner/name]",
+		Short: "Include a paused repository in scheduled refreshes again",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			owner, name, err := splitRepoName(args[0])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			repo, err := client.ResumeRepository(owner, name)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error resuming repository: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			fmt.Printf("Repository %s resumed\n", repo.FullName)
+		},
+	}
+
+	// Repository trends command
+	trendsRepoCmd := &cobra.Command{
+		Use:   "trends [owner/name]",
+		Short: "Show open and stale item counts over time",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			owner, name, err := splitRepoName(args[0])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			windowStr, _ := cmd.Flags().GetString("window")
+			window, err := parseWindow(windowStr)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid --window value: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			snapshots, err := client.GetRepositoryTrends(owner, name, window)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error getting repository trends: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			// Print snapshots
+			fmt.Printf("%-20s %-10s %-10s %-12s %s\n", "TIME", "OPEN PRS", "STALE PRS", "OPEN ISSUES", "STALE ISSUES")
+			for _, snapshot := range snapshots {
+				fmt.Printf("%-20s %-10d %-10d %-12d %d\n", snapshot.CreatedAt.Format("2006-01-02 15:04:05"), snapshot.OpenPullRequests, snapshot.StalePullRequests, snapshot.OpenIssues, snapshot.StaleIssues)
+			}
+		},
+	}
+	trendsRepoCmd.Flags().StringP("window", "w", "90d", "Time window to show (e.g. 90d, 12h)")
+
+	// Commits command
+	commitsRepoCmd := &cobra.Command{
+		Use:         "commits [owner/name]",
+		Short:       "List synced default branch commits",
+		Long:        "List the default branch commits synced when github.sync_commits is enabled, newest first",
+		Args:        cobra.ExactArgs(1),
+		Annotations: servedAnnotations,
+		Run: func(cmd *cobra.Command, args []string) {
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			owner, name, err := splitRepoName(args[0])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			filter := &models.CommitFilter{}
+			filter.Author, _ = cmd.Flags().GetString("author")
+			filter.Page, _ = cmd.Flags().GetInt("page")
+			filter.PerPage, _ = cmd.Flags().GetInt("per-page")
+			since, _ := cmd.Flags().GetString("since")
+			if filter.Since, err = parseTimeFlag(since); err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid --since value: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+			until, _ := cmd.Flags().GetString("until")
+			if filter.Until, err = parseTimeFlag(until); err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid --until value: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			resp, err := client.ListCommits(owner, name, filter)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error listing commits: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			fmt.Printf("%-9s %-20s %-20s %s\n", "SHA", "DATE", "AUTHOR", "SUBJECT")
+			for _, commit := range resp.Data {
+				sha := commit.SHA
+				if len(sha) > 7 {
+					sha = sha[:7]
+				}
+				fmt.Printf("%-9s %-20s %-20s %s\n", sha, commit.CommittedAt.Format("2006-01-02 15:04:05"), commit.Author, commit.Subject)
+			}
+
+			fmt.Printf("\nPage %d of %d (Total: %d)\n", resp.Pagination.Page, resp.Pagination.TotalPages, resp.Pagination.Total)
+		},
+	}
+	commitsRepoCmd.Flags().String("author", "", "Filter by author login")
+	commitsRepoCmd.Flags().String("since", "", "Only list commits made at or after this time (YYYY-MM-DD or RFC3339)")
+	commitsRepoCmd.Flags().String("until", "", "Only list commits made before this time (YYYY-MM-DD or RFC3339)")
+	commitsRepoCmd.Flags().IntP("page", "p", 1, "Page number")
+	commitsRepoCmd.Flags().IntP("per-page", "n", 30, "Items per page")
+
+	// Tag command
+	tagRepoCmd := &cobra.Command{
+		Use:   "tag",
+		Short: "Manage repository tags",
+		Long:  "Attach and detach tags used to group tracked repositories",
+	}
+
+	// Add tag command
+	addTagCmd := &cobra.Command{
+		Use:   "add [owner/name] [tag]",
+		Short: "Add a tag to a repository",
+		Args:  cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			owner, name, err := splitRepoName(args[0])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			repo, err := client.AddRepositoryTag(owner, name, args[1])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error adding tag: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			fmt.Printf("Repository %s tags: %s\n", repo.FullName, strings.Join(repo.Tags, ", "))
+		},
+	}
+
+	// Remove tag command
+	removeTagCmd := &cobra.Command{
+		Use:   "remove [owner/name] [tag]",
+		Short: "Remove a tag from a repository",
+		Args:  cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			owner, name, err := splitRepoName(args[0])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			repo, err := client.RemoveRepositoryTag(owner, name, args[1])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error removing tag: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			fmt.Printf("Repository %s tags: %s\n", repo.FullName, strings.Join(repo.Tags, ", "))
+		},
+	}
+
 	// Pull request command
 	prCmd := &cobra.Command{
 		Use:   "pr",
@@ -171,13 +658,14 @@ func main() {
 
 	// List pull requests command
 	listPRCmd := &cobra.Command{
-		Use:   "list",
-		Short: "List pull requests",
+		Use:         "list",
+		Short:       "List pull requests",
+		Annotations: servedAnnotations,
 		Run: func(cmd *cobra.Command, args []string) {
 			client, err := NewClient()
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
-				os.Exit(1)
+				os.Exit(exitCode(err))
 			}
 
 			// Get filter parameters
@@ -185,36 +673,184 @@ func main() {
 			params["state"], _ = cmd.Flags().GetString("state")
 			params["author"], _ = cmd.Flags().GetString("author")
 			params["repo"], _ = cmd.Flags().GetString("repo")
+			params["repo_tag"], _ = cmd.Flags().GetString("repo-tag")
+			params["label"], _ = cmd.Flags().GetString("label")
+			params["since"], _ = cmd.Flags().GetString("since")
 			params["sort"], _ = cmd.Flags().GetString("sort")
 			params["direction"], _ = cmd.Flags().GetString("direction")
+			params["cursor"], _ = cmd.Flags().GetString("cursor")
+			if includeTombstoned, _ := cmd.Flags().GetBool("include-tombstoned"); includeTombstoned {
+				params["include_tombstoned"] = "true"
+			}
+			if excludeBots, _ := cmd.Flags().GetBool("exclude-bots"); excludeBots {
+				params["exclude_bots"] = "true"
+			}
+			params["association"], _ = cmd.Flags().GetString("association")
+			params["team"], _ = cmd.Flags().GetString("team")
+			params["jira"], _ = cmd.Flags().GetString("jira")
+			if ownedByMe, _ := cmd.Flags().GetBool("owned-by-me"); ownedByMe {
+				params["owned_by_me"] = "true"
+			}
+			params["owned_by_team"], _ = cmd.Flags().GetString("owned-by-team")
+			params["path"], _ = cmd.Flags().GetString("path")
+			params["size"], _ = cmd.Flags().GetString("size")
+			params["project"], _ = cmd.Flags().GetString("project")
+			params["project_status"], _ = cmd.Flags().GetString("project-status")
 			page, _ := cmd.Flags().GetInt("page")
 			perPage, _ := cmd.Flags().GetInt("per-page")
 			params["page"] = fmt.Sprintf("%d", page)
 			params["per_page"] = fmt.Sprintf("%d", perPage)
 
+			columns, err := parseFields(columnsFlag(cmd))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+			tmpl := mustParseTemplate(cmd)
+			if hasField(columns, "body") || (tmpl != nil && strings.Contains(tmpl.Root.String(), ".Body")) {
+				params["include_body"] = "true"
+			}
+
 			resp, err := client.ListPullRequests(params)
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Error listing pull requests: %v\n", err)
-				os.Exit(1)
+				os.Exit(exitCode(err))
+			}
+
+			// Skip output when the caller already has this list
+			unchanged, err := notModified(cmd, resp.ETag, resp.LastModified)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+			if unchanged {
+				fmt.Println("Not modified")
+				return
+			}
+
+			if tmpl != nil || quiet(cmd) {
+				for _, pr := range resp.Data {
+					if tmpl != nil {
+						printTemplate(tmpl, pr)
+					} else {
+						fmt.Printf("%s#%d\n", pr.RepositoryFullName, pr.Number)
+					}
+				}
+				return
 			}
 
 			// Print pull requests
-			fmt.Printf("%-40s %-5s %-20s %-12s %s\n", "REPOSITORY", "NUM", "AUTHOR", "STATE", "TITLE")
+			rows := make([]*itemRow, 0, len(resp.Data))
 			for _, pr := range resp.Data {
-				fmt.Printf("%-40s %-5d %-20s %-12s %s\n", pr.RepositoryFullName, pr.Number, pr.UserLogin, pr.State, pr.Title)
+				rows = append(rows, pullRequestRow(pr))
 			}
+			printRows(columns, rows)
 
 			// Print pagination info
 			fmt.Printf("\nPage %d of %d (Total: %d)\n", resp.Pagination.Page, resp.Pagination.TotalPages, resp.Pagination.Total)
+			fmt.Printf("ETag: %s\n", resp.ETag)
+			printCursors(resp.Pagination)
 		},
 	}
 	listPRCmd.Flags().StringP("state", "s", "open", "Filter by state (open, closed, all)")
 	listPRCmd.Flags().StringP("author", "a", "", "Filter by author")
 	listPRCmd.Flags().StringP("repo", "r", "", "Filter by repository (owner/name)")
+	listPRCmd.Flags().String("repo-tag", "", "Filter by repository tag")
+	listPRCmd.Flags().StringP("label", "l", "", "Filter by label")
+	listPRCmd.Flags().String("since", "", "Only show items updated since this date (YYYY-MM-DD or RFC3339)")
 	listPRCmd.Flags().String("sort", "created", "Sort by (created, updated)")
 	listPRCmd.Flags().String("direction", "desc", "Sort direction (asc, desc)")
 	listPRCmd.Flags().IntP("page", "p", 1, "Page number")
 	listPRCmd.Flags().IntP("per-page", "n", 10, "Items per page")
+	listPRCmd.Flags().String("cursor", "", "Continue from a cursor printed by a previous list instead of using --page")
+	listPRCmd.Flags().String("columns", defaultFields, "Comma-separated columns to print (repository, number, author, association, bot, state, title, url, created_at, updated_at, closed_at, merged_at, size, body)")
+	listPRCmd.Flags().String("fields", "", "")
+	listPRCmd.Flags().MarkDeprecated("fields", "use --columns instead")
+	listPRCmd.Flags().Bool("exclude-bots", false, "Hide pull requests opened by bots such as dependabot")
+	listPRCmd.Flags().String("team", "", "Only show pull requests assigned to, requesting review from or mentioning a team")
+	listPRCmd.Flags().String("jira", "", "Only show pull requests referencing a Jira issue key (e.g. PROJ-123)")
+	listPRCmd.Flags().Bool("owned-by-me", false, "Only show pull requests changing paths code_owners.user owns")
+	listPRCmd.Flags().String("owned-by-team", "", "Only show pull requests changing paths a team owns")
+	listPRCmd.Flags().String("path", "", "Only show pull requests changing a path matching a pattern (e.g. docs/**, *.go)")
+	listPRCmd.Flags().String("size", "", "Only show pull requests of a size by lines changed (XS, S, M, L, XL, XXL)")
+	listPRCmd.Flags().String("project", "", "Only show pull requests on a project, by owner/number or title")
+	listPRCmd.Flags().String("project-status", "", "Only show pull requests in a project column (e.g. \"In Review\")")
+	listPRCmd.Flags().String("association", "", "Filter by author association, comma separated (e.g. MEMBER,CONTRIBUTOR,FIRST_TIMER)")
+	listPRCmd.Flags().Bool("include-tombstoned", false, "Include pull requests deleted or transferred upstream")
+	addTemplateFlag(listPRCmd, "{{.RepositoryFullName}}#{{.Number}} {{.Title}}")
+	addQuietFlag(listPRCmd)
+	addConditionalFlags(listPRCmd)
+
+	// View pull request command
+	viewPRCmd := &cobra.Command{
+		Use:   "view [owner/name] [number]",
+		Short: "Show a pull request, its body and its state history",
+		Args:  cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			owner, name, err := splitRepoName(args[0])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				os.Exit(exitCode(err))
+			}
+			number, err := strconv.Atoi(args[1])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid pull request number: %s\n", args[1])
+				os.Exit(1)
+			}
+
+			tmpl := mustParseTemplate(cmd)
+
+			item, err := client.GetPullRequest(owner, name, number)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error getting pull request: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+			if tmpl != nil {
+				printTemplate(tmpl, item)
+				return
+			}
+
+			fmt.Printf("Pull request %s#%d: %s\n", item.RepositoryFullName, item.Number, item.Title)
+			fmt.Printf("  State: %s\n", item.State)
+			if item.Draft {
+				fmt.Println("  Draft: yes")
+			}
+			fmt.Printf("  Author: %s\n", item.UserLogin)
+			fmt.Printf("  URL: %s\n", item.HTMLURL)
+			printLogins("Assignees", item.Assignees)
+			if item.Milestone != "" {
+				fmt.Printf("  Milestone: %s\n", item.Milestone)
+			}
+			printLogins("Review requested", append(item.RequestedReviewers, item.RequestedTeams...))
+			printLogins("Mentions", item.Mentions)
+			printLogins("References", item.References)
+			printLogins("Referenced by", item.ReferencedBy)
+			fmt.Printf("  Changes: +%d -%d in %d files (%s)\n", item.Additions, item.Deletions, item.ChangedFiles, item.Size())
+			fmt.Printf("  Created: %s\n", item.CreatedAt.Format("2006-01-02 15:04:05"))
+			fmt.Printf("  Updated: %s\n", item.UpdatedAt.Format("2006-01-02 15:04:05"))
+			fmt.Printf("  Reopened: %d\n", item.StateHistory.Reopened())
+			if item.Tombstoned {
+				fmt.Printf("  Tombstoned: %s (no longer present upstream)\n", item.TombstonedAt.Format("2006-01-02 15:04:05"))
+			}
+			printBody(cmd, item.Body)
+			printStateHistory(item.StateHistory)
+			if len(item.Files) > 0 {
+				fmt.Println("  Files:")
+				for _, file := range item.Files {
+					fmt.Printf("    %s\n", file)
+				}
+			}
+		},
+	}
+
+	addTemplateFlag(viewPRCmd, "{{.State}} {{join \",\" .RequestedReviewers}}")
+	addRawFlag(viewPRCmd)
 
 	// Issue command
 	issueCmd := &cobra.Command{
@@ -225,13 +861,14 @@ func main() {
 
 	// List issues command
 	listIssueCmd := &cobra.Command{
-		Use:   "list",
-		Short: "List issues",
+		Use:         "list",
+		Short:       "List issues",
+		Annotations: servedAnnotations,
 		Run: func(cmd *cobra.Command, args []string) {
 			client, err := NewClient()
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
-				os.Exit(1)
+				os.Exit(exitCode(err))
 			}
 
 			// Get filter parameters
@@ -239,52 +876,180 @@ func main() {
 			params["state"], _ = cmd.Flags().GetString("state")
 			params["author"], _ = cmd.Flags().GetString("author")
 			params["repo"], _ = cmd.Flags().GetString("repo")
+			params["repo_tag"], _ = cmd.Flags().GetString("repo-tag")
+			params["label"], _ = cmd.Flags().GetString("label")
+			params["since"], _ = cmd.Flags().GetString("since")
 			params["sort"], _ = cmd.Flags().GetString("sort")
 			params["direction"], _ = cmd.Flags().GetString("direction")
+			params["cursor"], _ = cmd.Flags().GetString("cursor")
+			if includeTombstoned, _ := cmd.Flags().GetBool("include-tombstoned"); includeTombstoned {
+				params["include_tombstoned"] = "true"
+			}
+			if excludeBots, _ := cmd.Flags().GetBool("exclude-bots"); excludeBots {
+				params["exclude_bots"] = "true"
+			}
+			params["association"], _ = cmd.Flags().GetString("association")
+			params["team"], _ = cmd.Flags().GetString("team")
+			params["jira"], _ = cmd.Flags().GetString("jira")
+			params["project"], _ = cmd.Flags().GetString("project")
+			params["project_status"], _ = cmd.Flags().GetString("project-status")
 			page, _ := cmd.Flags().GetInt("page")
 			perPage, _ := cmd.Flags().GetInt("per-page")
 			params["page"] = fmt.Sprintf("%d", page)
 			params["per_page"] = fmt.Sprintf("%d", perPage)
 
+			columns, err := parseFields(columnsFlag(cmd))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+			tmpl := mustParseTemplate(cmd)
+			if hasField(columns, "body") || (tmpl != nil && strings.Contains(tmpl.Root.String(), ".Body")) {
+				params["include_body"] = "true"
+			}
+
 			resp, err := client.ListIssues(params)
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Error listing issues: %v\n", err)
-				os.Exit(1)
+				os.Exit(exitCode(err))
+			}
+
+			// Skip output when the caller already has this list
+			unchanged, err := notModified(cmd, resp.ETag, resp.LastModified)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+			if unchanged {
+				fmt.Println("Not modified")
+				return
+			}
+
+			if tmpl != nil || quiet(cmd) {
+				for _, issue := range resp.Data {
+					if tmpl != nil {
+						printTemplate(tmpl, issue)
+					} else {
+						fmt.Printf("%s#%d\n", issue.RepositoryFullName, issue.Number)
+					}
+				}
+				return
 			}
 
 			// Print issues
-			fmt.Printf("%-40s %-5s %-20s %-12s %s\n", "REPOSITORY", "NUM", "AUTHOR", "STATE", "TITLE")
+			rows := make([]*itemRow, 0, len(resp.Data))
 			for _, issue := range resp.Data {
-				fmt.Printf("%-40s %-5d %-20s %-12s %s\n", issue.RepositoryFullName, issue.Number, issue.UserLogin, issue.State, issue.Title)
+				rows = append(rows, issueRow(issue))
 			}
+			printRows(columns, rows)
 
 			// Print pagination info
 			fmt.Printf("\nPage %d of %d (Total: %d)\n", resp.Pagination.Page, resp.Pagination.TotalPages, resp.Pagination.Total)
+			fmt.Printf("ETag: %s\n", resp.ETag)
+			printCursors(resp.Pagination)
 		},
 	}
 	listIssueCmd.Flags().StringP("state", "s", "open", "Filter by state (open, closed, all)")
 	listIssueCmd.Flags().StringP("author", "a", "", "Filter by author")
 	listIssueCmd.Flags().StringP("repo", "r", "", "Filter by repository (owner/name)")
+	listIssueCmd.Flags().String("repo-tag", "", "Filter by repository tag")
+	listIssueCmd.Flags().StringP("label", "l", "", "Filter by label")
+	listIssueCmd.Flags().String("since", "", "Only show items updated since this date (YYYY-MM-DD or RFC3339)")
 	listIssueCmd.Flags().String("sort", "created", "Sort by (created, updated)")
 	listIssueCmd.Flags().String("direction", "desc", "Sort direction (asc, desc)")
 	listIssueCmd.Flags().IntP("page", "p", 1, "Page number")
 	listIssueCmd.Flags().IntP("per-page", "n", 10, "Items per page")
+	listIssueCmd.Flags().String("cursor", "", "Continue from a cursor printed by a previous list instead of using --page")
+	listIssueCmd.Flags().String("columns", defaultFields, "Comma-separated columns to print (repository, number, author, association, bot, state, title, url, created_at, updated_at, closed_at, merged_at, body)")
+	listIssueCmd.Flags().String("fields", "", "")
+	listIssueCmd.Flags().MarkDeprecated("fields", "use --columns instead")
+	listIssueCmd.Flags().Bool("exclude-bots", false, "Hide issues opened by bots such as dependabot")
+	listIssueCmd.Flags().String("team", "", "Only show issues assigned to or mentioning a team")
+	listIssueCmd.Flags().String("jira", "", "Only show issues referencing a Jira issue key (e.g. PROJ-123)")
+	listIssueCmd.Flags().String("project", "", "Only show issues on a project, by owner/number or title")
+	listIssueCmd.Flags().String("project-status", "", "Only show issues in a project column (e.g. \"In Review\")")
+	listIssueCmd.Flags().String("association", "", "Filter by author association, comma separated (e.g. MEMBER,CONTRIBUTOR,FIRST_TIMER)")
+	listIssueCmd.Flags().Bool("include-tombstoned", false, "Include issues deleted or transferred upstream")
+	addTemplateFlag(listIssueCmd, "{{.RepositoryFullName}}#{{.Number}} {{.Title}}")
+	addQuietFlag(listIssueCmd)
+	addConditionalFlags(listIssueCmd)
+
+	// View issue command
+	viewIssueCmd := &cobra.Command{
+		Use:   "view [owner/name] [number]",
+		Short: "Show an issue, its body and its state history",
+		Args:  cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			owner, name, err := splitRepoName(args[0])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				os.Exit(exitCode(err))
+			}
+			number, err := strconv.Atoi(args[1])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid issue number: %s\n", args[1])
+				os.Exit(1)
+			}
+
+			tmpl := mustParseTemplate(cmd)
+
+			item, err := client.GetIssue(owner, name, number)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error getting issue: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+			if tmpl != nil {
+				printTemplate(tmpl, item)
+				return
+			}
+
+			fmt.Printf("Issue %s#%d: %s\n", item.RepositoryFullName, item.Number, item.Title)
+			fmt.Printf("  State: %s\n", item.State)
+			fmt.Printf("  Author: %s\n", item.UserLogin)
+			fmt.Printf("  URL: %s\n", item.HTMLURL)
+			printLogins("Assignees", item.Assignees)
+			if item.Milestone != "" {
+				fmt.Printf("  Milestone: %s\n", item.Milestone)
+			}
+			printLogins("Mentions", item.Mentions)
+			printLogins("References", item.References)
+			printLogins("Referenced by", item.ReferencedBy)
+			fmt.Printf("  Created: %s\n", item.CreatedAt.Format("2006-01-02 15:04:05"))
+			fmt.Printf("  Updated: %s\n", item.UpdatedAt.Format("2006-01-02 15:04:05"))
+			fmt.Printf("  Reopened: %d\n", item.StateHistory.Reopened())
+			if item.Tombstoned {
+				fmt.Printf("  Tombstoned: %s (no longer present upstream)\n", item.TombstonedAt.Format("2006-01-02 15:04:05"))
+			}
+			printBody(cmd, item.Body)
+			printStateHistory(item.StateHistory)
+		},
+	}
+
+	addTemplateFlag(viewIssueCmd, "{{.State}} {{join \",\" .Assignees}}")
+	addRawFlag(viewIssueCmd)
 
 	// Status command
 	statusCmd := &cobra.Command{
-		Use:   "status",
-		Short: "Show service status",
+		Use:         "status",
+		Short:       "Show service status",
+		Annotations: servedAnnotations,
 		Run: func(cmd *cobra.Command, args []string) {
 			client, err := NewClient()
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
-				os.Exit(1)
+				os.Exit(exitCode(err))
 			}
 
 			status, err := client.GetStatus()
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Error getting status: %v\n", err)
-				os.Exit(1)
+				os.Exit(exitCode(err))
 			}
 
 			// Print status
@@ -297,6 +1062,7 @@ func main() {
 				fmt.Println("\nRepositories:")
 				fmt.Printf("  Total: %v\n", repoStats["total"])
 				fmt.Printf("  Syncing: %v\n", repoStats["syncing"])
+				fmt.Printf("  Paused: %v\n", repoStats["paused"])
 				fmt.Printf("  Error: %v\n", repoStats["error"])
 			}
 
@@ -309,24 +1075,84 @@ func main() {
 					fmt.Printf("  Reset At: %s\n", resetAt)
 				}
 			}
+
+			// Print the rotated tokens
+			if tokens, ok := status["github_tokens"].([]map[string]interface{}); ok && len(tokens) > 0 {
+				fmt.Println("\nGitHub Tokens:")
+				for _, token := range tokens {
+					active := ""
+					if token["active"] == true {
+						active = " (active)"
+					}
+					fmt.Printf("  %v: %v of %v remaining, %v calls%s\n", token["name"], token["remaining"], token["limit"], token["calls"], active)
+				}
+			}
+
+			// Print gh process pool stats
+			if pool, ok := status["github_pool"].(map[string]interface{}); ok {
+				fmt.Println("\nGitHub CLI Processes:")
+				fmt.Printf("  Running: %v of %v (Peak: %v)\n", pool["running"], pool["max_concurrent"], pool["peak_running"])
+				fmt.Printf("  Waiting: %v\n", pool["waiting"])
+				fmt.Printf("  Calls: %v (Failures: %v)\n", pool["calls"], pool["failures"])
+				fmt.Printf("  Total Wait: %v\n", pool["total_wait"])
+			}
+
+			// Print storage stats
+			if storage, ok := status["storage"].(map[string]interface{}); ok {
+				fmt.Println("\nStorage:")
+				fmt.Printf("  Pull Requests: %v\n", storage["pull_requests"])
+				fmt.Printf("  Issues: %v\n", storage["issues"])
+				fmt.Printf("  Estimated Size: %v bytes\n", storage["estimated_bytes"])
+				fmt.Printf("  Evicted Pull Requests: %v\n", storage["evicted_pull_requests"])
+				fmt.Printf("  Evicted Issues: %v\n", storage["evicted_issues"])
+				fmt.Printf("  Tombstoned Pull Requests: %v\n", storage["tombstoned_pull_requests"])
+				fmt.Printf("  Tombstoned Issues: %v\n", storage["tombstoned_issues"])
+			}
+
+			// Print API usage
+			if apiUsage, ok := status["api_usage"].(map[string]interface{}); ok {
+				fmt.Println("\nGitHub API Usage:")
+				fmt.Printf("  Total Requests: %v\n", apiUsage["total_requests"])
+			}
+
+			detailed, _ := cmd.Flags().GetBool("detailed")
+			if !detailed {
+				return
+			}
+
+			usage, err := client.GetAPIUsage()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error getting API usage: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			fmt.Println("\nAPI Requests by Repository:")
+			fmt.Printf("  %-40s %-10s %-10s %-10s %s\n", "REPOSITORY", "LAST SYNC", "AVG/SYNC", "TOTAL", "SHARE")
+			for _, repo := range usage {
+				fmt.Printf("  %-40s %-10d %-10.1f %-10d %.1f%%\n", repo.FullName, repo.LastSyncRequests, repo.AverageSyncRequests(), repo.TotalRequests, repo.Share*100)
+			}
 		},
 	}
+	statusCmd.Flags().Bool("detailed", false, "Show the GitHub API requests spent on each repository")
+
+	// Add commands to tag command
+	tagRepoCmd.AddCommand(addTagCmd, removeTagCmd)
 
 	// Add commands to repo command
-	repoCmd.AddCommand(addRepoCmd, listRepoCmd, removeRepoCmd, refreshRepoCmd)
+	repoCmd.AddCommand(addRepoCmd, discoverRepoCmd, newImportRepoCmd(), newLinkRepoCmd(), newSeedRepoCmd(), listRepoCmd, removeRepoCmd, refreshRepoCmd, configRepoCmd, pauseRepoCmd, resumeRepoCmd, trendsRepoCmd, commitsRepoCmd, tagRepoCmd, newDepsRepoCmd())
 
 	// Add commands to pr command
-	prCmd.AddCommand(listPRCmd)
+	prCmd.AddCommand(listPRCmd, viewPRCmd, newPRCreateCmd(), newPREditCmd(), newBulkCmd(models.ItemTypePullRequest), newPRCheckoutCmd(), newPRDiffCmd(), newPRQueueCmd())
 
 	// Add commands to issue command
-	issueCmd.AddCommand(listIssueCmd)
+	issueCmd.AddCommand(listIssueCmd, viewIssueCmd, newIssueEditCmd(), newBulkCmd(models.ItemTypeIssue), newIssueDuplicatesCmd(), newIssueTreeCmd())
 
 	// Add commands to root command
-	rootCmd.AddCommand(repoCmd, prCmd, issueCmd, statusCmd)
+	rootCmd.AddCommand(repoCmd, prCmd, issueCmd, statusCmd, newAuthCmd(), newAdminCmd(), newItemCmd(), newHookCmd(), newActivityCmd(), newExportCmd(), newAnalyticsCmd(), newLeaderboardCmd(), newJobCmd(), newAuditCmd(), newSSOCmd(), newWorkspaceCmd(), newAlertsCmd(), newComplianceCmd(), newProjectCmd(), newDiscussionCmd(), newLabelCmd(), newSubscriptionCmd(), newTriageCmd(), newReleaseCmd(), newSLACmd(), newDiffCmd(), newServeCmd(), newBenchCmd(), newRecordCmd())
 
 	// Execute
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 }
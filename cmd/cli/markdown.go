@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/glamour/styles"
+	"github.com/spf13/cobra"
+)
+
+// defaultMarkdownWidth is the width Markdown is wrapped at when the terminal width is unknown
+const defaultMarkdownWidth = 80
+
+// addRawFlag adds the --raw flag, printing bodies as Markdown source
+func addRawFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool("raw", false, "Print the body as Markdown source instead of rendering it")
+}
+
+// printBody prints the body of a pull request or issue, rendered for the terminal unless --raw
+// was given or standard output isn't a terminal
+func printBody(cmd *cobra.Command, body string) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return
+	}
+	raw, _ := cmd.Flags().GetBool("raw")
+	fmt.Println()
+	fmt.Println(strings.TrimRight(renderMarkdown(body, raw), "\n"))
+}
+
+// renderMarkdown renders Markdown for the terminal at the output width, colored when colors are
+// enabled. The source is returned as is with raw, when output is piped without --color always,
+// or when it can't be rendered.
+func renderMarkdown(source string, raw bool) string {
+	if raw || (!isTerminal(os.Stdout) && colorMode != "always") {
+		return source
+	}
+
+	style := styles.NoTTYStyle
+	if colorEnabled() {
+		style = styles.AutoStyle
+		if !isTerminal(os.Stdout) {
+			style = styles.DarkStyle
+		}
+	}
+	width := outputWidth()
+	if width <= 0 {
+		width = defaultMarkdownWidth
+	}
+	renderer, err := glamour.NewTermRenderer(glamour.WithStandardStyle(style), glamour.WithWordWrap(width), glamour.WithEmoji())
+	if err != nil {
+		return source
+	}
+	rendered, err := renderer.Render(source)
+	if err != nil {
+		return source
+	}
+	return rendered
+}
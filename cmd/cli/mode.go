@@ -0,0 +1,163 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/siddontang/github-repos-management/internal/api"
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/github"
+)
+
+// Execution mode flags: --local runs the embedded service on the local database,
+// --server sends commands to the JSON API of a running 'ghrepos serve' and --offline runs the
+// embedded service without ever contacting GitHub; --replay runs it on recorded GitHub fixtures
+var (
+	localMode   bool
+	serverURL   string
+	offlineMode bool
+	replayDir   string
+
+	// servedCommand is set for commands the JSON API serves, which may run against a server
+	servedCommand bool
+)
+
+// servedAnnotation is the annotation marking commands the JSON API serves
+const servedAnnotation = "ghrepos/served"
+
+// servedAnnotations are the annotations of commands the JSON API serves
+var servedAnnotations = map[string]string{servedAnnotation: "true"}
+
+// probeTimeout bounds the check for a server running at the configured address
+const probeTimeout = 300 * time.Millisecond
+
+// addModeFlags adds the execution mode flags to the root command
+func addModeFlags(rootCmd *cobra.Command) {
+	rootCmd.PersistentFlags().BoolVar(&localMode, "local", false, "Run the embedded service on the local database, even if a server is running")
+	rootCmd.PersistentFlags().StringVar(&serverURL, "server", "", "Send commands to the ghrepos server at this URL (also GHREPOS_SERVER)")
+	offlineDefault, _ := strconv.ParseBool(os.Getenv("GHREPOS_OFFLINE"))
+	rootCmd.PersistentFlags().BoolVar(&offlineMode, "offline", offlineDefault, "Never contact GitHub: serve commands from the local database only (also GHREPOS_OFFLINE)")
+	rootCmd.PersistentFlags().StringVar(&replayDir, "replay", os.Getenv("GHREPOS_REPLAY"), "Serve GitHub from the fixtures 'ghrepos record' saved in this directory (also GHREPOS_REPLAY)")
+}
+
+// replaying reports whether GitHub is served from recorded fixtures, by --replay, GHREPOS_REPLAY or github.replay
+func replaying(cfg *config.Config) bool {
+	return replayDir != "" || cfg.GitHub.Replay != ""
+}
+
+// offline reports whether offline mode is on, by --offline, GHREPOS_OFFLINE or github.offline
+func offline(cfg *config.Config) bool {
+	return offlineMode || cfg.GitHub.Offline
+}
+
+// requireOnline exits with an error in offline mode, for commands that only talk to GitHub
+func requireOnline() {
+	cfg := &config.Config{}
+	if configPath != "" {
+		var err error
+		if cfg, err = config.Load(configPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading configuration: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if offline(cfg) {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", github.ErrOffline)
+		os.Exit(exitCode(github.ErrOffline))
+	}
+}
+
+// resolveServer returns the URL of the server to send the command to, or "" to run the
+// embedded service. In order: --local, --server, the GHREPOS_SERVER environment variable
+// ("local" forces the embedded service) and finally a server answering at the configured
+// server address. Commands the API doesn't serve run locally unless --server asks otherwise.
+// Offline and replay modes always run the embedded service, as a server may contact GitHub.
+func resolveServer(cfg *config.Config) (string, error) {
+	if localMode && serverURL != "" {
+		return "", fmt.Errorf("--local and --server cannot be combined")
+	}
+	if offline(cfg) && serverURL != "" {
+		return "", fmt.Errorf("offline mode and --server cannot be combined")
+	}
+	if replaying(cfg) && serverURL != "" {
+		return "", fmt.Errorf("replay mode and --server cannot be combined")
+	}
+	if localMode || offline(cfg) || replaying(cfg) {
+		return "", nil
+	}
+	if serverURL != "" {
+		if !servedCommand {
+			return "", fmt.Errorf("this command is not served by the HTTP API; run it with --local")
+		}
+		return serverURL, nil
+	}
+	if !servedCommand {
+		return "", nil
+	}
+
+	if env := os.Getenv("GHREPOS_SERVER"); env != "" {
+		if strings.EqualFold(env, "local") {
+			return "", nil
+		}
+		return env, nil
+	}
+
+	// Use a server already running on the same data rather than opening it a second time
+	addr := cfg.Server.Addr
+	if addr == "" {
+		addr = api.DefaultAddr
+	}
+	if candidate := probeURL(addr); candidate != "" && probeServer(candidate) {
+		return candidate, nil
+	}
+	return "", nil
+}
+
+// probeURL returns the URL to reach a listen address at, or "" if it is malformed
+func probeURL(addr string) string {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return ""
+	}
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "127.0.0.1"
+	}
+	return "http://" + net.JoinHostPort(host, port)
+}
+
+// probeServer reports whether a ghrepos server answers at baseURL
+func probeServer(baseURL string) bool {
+	client := &http.Client{Timeout: probeTimeout}
+	resp, err := client.Get(baseURL + "/api/v1/health")
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+
+	var health struct {
+		Service string `json:"service"`
+	}
+	if resp.StatusCode != http.StatusOK || json.NewDecoder(resp.Body).Decode(&health) != nil {
+		return false
+	}
+	return health.Service == api.HealthService
+}
+
+// logMode reports the execution mode with --verbose
+func logMode(server string) {
+	if !verbose {
+		return
+	}
+	if server != "" {
+		fmt.Fprintf(os.Stderr, "Using the ghrepos server at %s\n", server)
+		return
+	}
+	fmt.Fprintln(os.Stderr, "Using the local database")
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// newPRCreateCmd creates the pr create command
+func newPRCreateCmd() *cobra.Command {
+	createCmd := &cobra.Command{
+		Use:   "create [owner/name]",
+		Short: "Open a pull request in a tracked repository",
+		Long: "Open a pull request from --head into --base, the default branch unless given, and store it right away " +
+			"so it is listed without waiting for the next refresh. Branches of forks are given as owner:branch.",
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			owner, name, err := splitRepoName(args[0])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				os.Exit(1)
+			}
+			create := &models.PullRequestCreate{}
+			create.Title, _ = cmd.Flags().GetString("title")
+			create.Body, _ = cmd.Flags().GetString("body")
+			create.Base, _ = cmd.Flags().GetString("base")
+			create.Head, _ = cmd.Flags().GetString("head")
+			if bodyFile, _ := cmd.Flags().GetString("body-file"); bodyFile != "" {
+				var data []byte
+				if bodyFile == "-" {
+					data, err = io.ReadAll(os.Stdin)
+				} else {
+					data, err = os.ReadFile(bodyFile)
+				}
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error reading body: %v\n", err)
+					os.Exit(1)
+				}
+				create.Body = string(data)
+			}
+			requireOnline()
+
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+			pr, err := client.CreatePullRequest(owner, name, create)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error creating pull request: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+			fmt.Printf("Created pull request %s#%d: %s\n", pr.RepositoryFullName, pr.Number, pr.HTMLURL)
+		},
+	}
+	createCmd.Flags().StringP("title", "t", "", "Title of the pull request")
+	createCmd.Flags().StringP("body", "b", "", "Body of the pull request, in Markdown")
+	createCmd.Flags().StringP("body-file", "F", "", "Read the body from a file, or standard input with -")
+	createCmd.Flags().StringP("base", "B", "", "Branch to merge into (default: the default branch)")
+	createCmd.Flags().StringP("head", "H", "", "Branch holding the changes, owner:branch for a fork")
+	createCmd.MarkFlagRequired("title")
+	createCmd.MarkFlagRequired("head")
+	return createCmd
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// newPRDiffCmd creates the pr diff command
+func newPRDiffCmd() *cobra.Command {
+	diffCmd := &cobra.Command{
+		Use:   "diff [owner/name#number]",
+		Short: "Show the changes of a pull request",
+		Long: "Fetch the unified diff of a pull request, or its patches with --patch, and print or save it. With --cache it is " +
+			"stored in the local database, and served from there when GitHub can't be reached, such as with --offline.",
+		Args:        cobra.ExactArgs(1),
+		Annotations: servedAnnotations,
+		Run: func(cmd *cobra.Command, args []string) {
+			owner, name, number, err := splitItemRef(args[0])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				os.Exit(1)
+			}
+			format := models.DiffFormatDiff
+			if patch, _ := cmd.Flags().GetBool("patch"); patch {
+				format = models.DiffFormatPatch
+			}
+			cache, _ := cmd.Flags().GetBool("cache")
+			output, _ := cmd.Flags().GetString("output")
+
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+			diff, err := client.GetPullRequestDiff(owner, name, number, format, cache)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error getting pull request changes: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+			if diff.Cached {
+				fmt.Fprintf(os.Stderr, "Showing the %s cached at %s\n", diff.Format, diff.FetchedAt.Local().Format("2006-01-02 15:04"))
+			}
+
+			if output != "" {
+				if err := os.WriteFile(output, []byte(diff.Content), 0644); err != nil {
+					fmt.Fprintf(os.Stderr, "Error saving %s: %v\n", output, err)
+					os.Exit(1)
+				}
+				fmt.Fprintf(os.Stderr, "Saved the %s of %s#%d to %s\n", diff.Format, diff.RepositoryFullName, diff.Number, output)
+				return
+			}
+			printDiff(os.Stdout, diff.Content, colorEnabled())
+		},
+	}
+	diffCmd.Flags().Bool("patch", false, "Fetch the patches of the commits (git format-patch) instead of a unified diff")
+	diffCmd.Flags().StringP("output", "o", "", "Save to a file instead of printing")
+	diffCmd.Flags().Bool("cache", false, "Store the changes in the local database for offline viewing")
+	return diffCmd
+}
+
+// printDiff prints a diff or patch, coloring added and removed lines and hunk headers when color is set
+func printDiff(w io.Writer, content string, color bool) {
+	if !color {
+		fmt.Fprint(w, content)
+		return
+	}
+	out := bufio.NewWriter(w)
+	defer out.Flush()
+	for _, line := range strings.SplitAfter(content, "\n") {
+		code := ""
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+		case strings.HasPrefix(line, "+"):
+			code = colorGreen
+		case strings.HasPrefix(line, "-"):
+			code = colorRed
+		case strings.HasPrefix(line, "@@"):
+			code = colorCyan
+		}
+		if code == "" {
+			out.WriteString(line)
+			continue
+		}
+		text, newline := strings.CutSuffix(line, "\n")
+		out.WriteString(code + text + colorReset)
+		if newline {
+			out.WriteString("\n")
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// jobWorkers overrides jobs.workers, the number of syncs running at once, when positive
+var jobWorkers int
+
+// progressBarWidth is the number of cells of the overall progress bar
+const progressBarWidth = 30
+
+// refreshProgress shows the progress of a refresh of several repositories. Finished repositories
+// are printed as they finish; on a terminal, the running repositories and an overall bar are
+// redrawn in place below them.
+type refreshProgress struct {
+	out   io.Writer
+	live  bool
+	color bool
+
+	jobs   []*models.Job // Latest state of each job, in queue order
+	index  map[int64]int
+	done   int
+	failed int
+	drawn  int // Lines of the live region on screen
+}
+
+// newRefreshProgress creates a progress view writing to f, redrawn in place when f is a terminal
+func newRefreshProgress(f *os.File) *refreshProgress {
+	live := isTerminal(f)
+	return &refreshProgress{
+		out:   f,
+		live:  live,
+		color: live && colorEnabled(),
+		index: make(map[int64]int),
+	}
+}
+
+// update records the new state of a job and redraws the view
+func (p *refreshProgress) update(job *models.Job) {
+	i, ok := p.index[job.ID]
+	if !ok {
+		i = len(p.jobs)
+		p.index[job.ID] = i
+		p.jobs = append(p.jobs, job)
+	}
+	p.jobs[i] = job
+
+	p.clear()
+	if job.Done() {
+		p.done++
+		switch job.State {
+		case models.JobStateSucceeded:
+			fmt.Fprintf(p.out, "%s %s%s\n", p.paint(colorGreen, "✓"), job.Target, p.elapsed(job))
+		default:
+			// The errors are listed once the refresh is over
+			p.failed++
+			fmt.Fprintf(p.out, "%s %s %s%s\n", p.paint(colorRed, "✗"), job.Target, job.State, p.elapsed(job))
+		}
+	}
+	p.draw()
+}
+
+// finish removes the live region, leaving the finished repositories on screen
+func (p *refreshProgress) finish() {
+	p.clear()
+}
+
+// clear erases the live region
+func (p *refreshProgress) clear() {
+	if p.drawn > 0 {
+		fmt.Fprintf(p.out, "\x1b[%dA\x1b[J", p.drawn)
+		p.drawn = 0
+	}
+}
+
+// draw prints the live region: one line per running repository and the overall bar
+func (p *refreshProgress) draw() {
+	if !p.live {
+		return
+	}
+	for _, job := range p.jobs {
+		if job.State != models.JobStateRunning {
+			continue
+		}
+		line := "  syncing " + job.Target + p.elapsed(job)
+		if job.Attempts > 1 {
+			line += fmt.Sprintf(", attempt %d/%d", job.Attempts, job.MaxAttempts)
+		}
+		fmt.Fprintln(p.out, line)
+		p.drawn++
+	}
+
+	total := len(p.jobs)
+	filled := 0
+	if total > 0 {
+		filled = p.done * progressBarWidth / total
+	}
+	bar := strings.Repeat("#", filled) + strings.Repeat("-", progressBarWidth-filled)
+	line := fmt.Sprintf("[%s] %d/%d repositories", bar, p.done, total)
+	if p.failed > 0 {
+		line += ", " + p.paint(colorRed, fmt.Sprintf("%d failed", p.failed))
+	}
+	fmt.Fprintln(p.out, line)
+	p.drawn++
+}
+
+// elapsed describes how long the latest attempt of a job has been running, or ran
+func (p *refreshProgress) elapsed(job *models.Job) string {
+	if job.StartedAt.IsZero() {
+		return ""
+	}
+	end := time.Now()
+	if job.Done() && !job.FinishedAt.IsZero() {
+		end = job.FinishedAt
+	}
+	return fmt.Sprintf(" (%s)", end.Sub(job.StartedAt).Round(100*time.Millisecond))
+}
+
+// paint colors text when colors are enabled
+func (p *refreshProgress) paint(code, text string) string {
+	if !p.color {
+		return text
+	}
+	return code + text + colorReset
+}
+
+// printRefreshFailures lists the repositories whose sync failed, reporting whether all succeeded
+func printRefreshFailures(jobs []*models.Job) bool {
+	var failed []*models.Job
+	for _, job := range jobs {
+		if job.State != models.JobStateSucceeded {
+			failed = append(failed, job)
+		}
+	}
+	if len(failed) == 0 {
+		return true
+	}
+
+	fmt.Fprintf(os.Stderr, "\n%d of %d repositories failed to refresh:\n", len(failed), len(jobs))
+	for _, job := range failed {
+		reason := job.Error
+		if reason == "" {
+			reason = job.State
+		}
+		fmt.Fprintf(os.Stderr, "  %s: %s\n", job.Target, reason)
+	}
+	return false
+}
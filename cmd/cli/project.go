@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// newProjectCmd creates the project command group
+func newProjectCmd() *cobra.Command {
+	projectCmd := &cobra.Command{
+		Use:   "project",
+		Short: "Show GitHub projects linked to tracked repositories",
+		Long:  "Show the GitHub projects (Projects v2) linked to the tracked repositories and their items by Status column. Requires github.sync_projects.",
+	}
+
+	// List projects command
+	listProjectCmd := &cobra.Command{
+		Use:         "list",
+		Short:       "List projects with their items by column",
+		Annotations: servedAnnotations,
+		Run: func(cmd *cobra.Command, args []string) {
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			filter := &models.ProjectFilter{}
+			filter.Repo, _ = cmd.Flags().GetString("repo")
+			filter.RepoTag, _ = cmd.Flags().GetString("repo-tag")
+			filter.IncludeClosed, _ = cmd.Flags().GetBool("closed")
+
+			projects, err := client.ListProjects(filter)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error listing projects: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			fmt.Printf("%-30s %-40s %-13s %s\n", "PROJECT", "TITLE", "REPOSITORIES", "ITEMS")
+			for _, project := range projects {
+				title := project.Title
+				if project.Closed {
+					title += " (closed)"
+				}
+				fmt.Printf("%-30s %-40s %-13d %s\n", fmt.Sprintf("%s/%d", project.Owner, project.Number), title,
+					len(project.Repositories), projectColumns(project))
+			}
+		},
+	}
+	listProjectCmd.Flags().StringP("repo", "r", "", "Filter by repository (owner/name)")
+	listProjectCmd.Flags().String("repo-tag", "", "Filter by repository tag")
+	listProjectCmd.Flags().Bool("closed", false, "Include closed projects")
+
+	// Show project command
+	showProjectCmd := &cobra.Command{
+		Use:         "show [owner/number]",
+		Short:       "Show a project and its items",
+		Args:        cobra.ExactArgs(1),
+		Annotations: servedAnnotations,
+		Run: func(cmd *cobra.Command, args []string) {
+			owner, numberStr, ok := strings.Cut(args[0], "/")
+			number, err := strconv.Atoi(numberStr)
+			if !ok || owner == "" || err != nil {
+				fmt.Fprintf(os.Stderr, "Error: project must be owner/number, got %q\n", args[0])
+				os.Exit(1)
+			}
+
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			status, _ := cmd.Flags().GetString("status")
+			project, items, err := client.GetProject(owner, number, status)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error getting project: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			fmt.Printf("Project: %s/%d %s\n", project.Owner, project.Number, project.Title)
+			fmt.Printf("URL: %s\n", project.URL)
+			fmt.Printf("Repositories: %s\n", strings.Join(project.Repositories, ", "))
+			fmt.Printf("Columns: %s\n", projectColumns(project))
+			fmt.Printf("Items (%d):\n", len(items))
+			for _, item := range items {
+				column := item.Status
+				if column == "" {
+					column = models.ProjectNoStatus
+				}
+				fmt.Printf("  %-15s %s#%d %s (%s)\n", column, item.RepositoryFullName, item.Number, item.Title, item.Type)
+			}
+		},
+	}
+	showProjectCmd.Flags().String("status", "", "Only show items in a column (e.g. \"In Review\")")
+
+	projectCmd.AddCommand(listProjectCmd, showProjectCmd)
+	return projectCmd
+}
+
+// projectColumns describes the item counts of a project's columns in board order, followed by the
+// items without a status
+func projectColumns(project *models.Project) string {
+	columns := make([]string, 0, len(project.Columns)+1)
+	for _, column := range project.Columns {
+		columns = append(columns, fmt.Sprintf("%s: %d", column, project.Items[column]))
+	}
+	if n := project.Items[models.ProjectNoStatus]; n > 0 {
+		columns = append(columns, fmt.Sprintf("%s: %d", models.ProjectNoStatus, n))
+	}
+	return strings.Join(columns, ", ")
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/github"
+	"github.com/siddontang/github-repos-management/internal/service"
+)
+
+// newRecordCmd creates the record command
+func newRecordCmd() *cobra.Command {
+	recordCmd := &cobra.Command{
+		Use:   "record [owner/repo...]",
+		Short: "Record the GitHub data of repositories for replay",
+		Long: "Sync repositories from GitHub once as 'repo add' does, saving every response to a fixture per repository in " +
+			"--dir instead of the database. Commands run with --replay on that directory (or GHREPOS_REPLAY, or github.replay) " +
+			"then serve GitHub from the fixtures, deterministically and without gh, for demos, tests and offline development. " +
+			"The sync options of the configuration, such as github.sync_labels, decide what is recorded.",
+		Args: cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			dir, _ := cmd.Flags().GetString("dir")
+
+			cfg := &config.Config{}
+			if configPath != "" {
+				var err error
+				if cfg, err = config.Load(configPath); err != nil {
+					fmt.Fprintf(os.Stderr, "Error loading configuration: %v\n", err)
+					os.Exit(1)
+				}
+			}
+			if offline(cfg) {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", github.ErrOffline)
+				os.Exit(exitCode(github.ErrOffline))
+			}
+
+			// Record from gh into a throwaway database, whatever the configuration replays or stores in
+			cfg.GitHub.Replay = ""
+			cfg.Database = config.DatabaseConfig{Type: config.DBTypeMemory}
+			recorder := github.NewRecorder(github.NewClientWithOptions(github.ClientOptions{
+				MaxConcurrentCalls: cfg.GitHub.MaxConcurrentCalls,
+				Tokens:             cfg.GitHub.Tokens,
+				TokenMinRemaining:  cfg.GitHub.TokenMinRemaining,
+			}))
+			svc, err := service.NewServiceWithOptions(cfg, service.Options{GitHubClient: recorder})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error creating service: %v\n", err)
+				os.Exit(1)
+			}
+			defer svc.Close()
+
+			ctx := context.Background()
+			for _, fullName := range args {
+				if _, err := svc.AddRepository(ctx, fullName); err != nil {
+					fmt.Fprintf(os.Stderr, "Error recording %s: %v\n", fullName, err)
+					os.Exit(exitCode(err))
+				}
+			}
+
+			paths, err := recorder.Save(dir)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error saving fixtures: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+			for _, path := range paths {
+				fmt.Println(path)
+			}
+			fmt.Fprintf(os.Stderr, "Recorded %d repositories; replay them with --replay %s\n", len(paths), dir)
+		},
+	}
+	recordCmd.Flags().String("dir", "fixtures", "Directory to save the fixtures to")
+	return recordCmd
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// newReleaseCmd creates the release command group
+func newReleaseCmd() *cobra.Command {
+	releaseCmd := &cobra.Command{
+		Use:   "release",
+		Short: "Prepare releases",
+	}
+
+	// Release notes command
+	notesCmd := &cobra.Command{
+		Use:   "notes [owner/name]",
+		Short: "Draft release notes from merged pull requests",
+		Long: "Collect the pull requests merged since a release tag or date, group them by label into the " +
+			"release_notes sections and print a Markdown changelog draft. Without --since, the notes start " +
+			"from the latest release that is not a prerelease.",
+		Args:        cobra.ExactArgs(1),
+		Annotations: servedAnnotations,
+		Run: func(cmd *cobra.Command, args []string) {
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			owner, name, err := splitRepoName(args[0])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			since, _ := cmd.Flags().GetString("since")
+			notes, err := client.ReleaseNotes(owner, name, since)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error drafting release notes: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			output, _ := cmd.Flags().GetString("output")
+			if output == "" {
+				fmt.Print(notes.Markdown)
+				return
+			}
+			if err := os.WriteFile(output, []byte(notes.Markdown), 0644); err != nil {
+				fmt.Fprintf(os.Stderr, "Error writing %s: %v\n", output, err)
+				os.Exit(1)
+			}
+			fmt.Printf("Release notes written to %s\n", output)
+		},
+	}
+	notesCmd.Flags().String("since", "", "Release tag or date (YYYY-MM-DD or RFC3339) to start from, default the latest release")
+	notesCmd.Flags().StringP("output", "o", "", "Write the notes to a file instead of standard output")
+
+	releaseCmd.AddCommand(notesCmd)
+	return releaseCmd
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// remoteClient sends commands to the JSON API of a running 'ghrepos serve'
+type remoteClient struct {
+	baseURL    string
+	token      string // Session token sent as a bearer token, if any
+	workspace  string // Workspace whose routes requests are sent to, if any
+	httpClient *http.Client
+}
+
+// newRemoteClient creates a client of the server at baseURL
+func newRemoteClient(baseURL string) *remoteClient {
+	return &remoteClient{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// remoteList is the body of the API's list endpoints
+type remoteList[T any] struct {
+	Data       []T                `json:"data"`
+	Pagination *models.Pagination `json:"pagination"`
+}
+
+// do sends a request to the API and decodes the JSON response into v.
+// Failed requests return the error reported by the server.
+func (r *remoteClient) do(ctx context.Context, method, path string, query url.Values, v interface{}) error {
+	if r.workspace != "" {
+		if rest, ok := strings.CutPrefix(path, "/api/v1/"); ok {
+			path = "/api/v1/workspaces/" + url.PathEscape(r.workspace) + "/" + rest
+		}
+	}
+	endpoint := r.baseURL + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if r.token != "" {
+		req.Header.Set("Authorization", "Bearer "+r.token)
+	}
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("server %s: %w", r.baseURL, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("server %s: %w", r.baseURL, err)
+	}
+	enveloped := resp.Header.Get("X-Envelope") == "1"
+	if resp.StatusCode >= http.StatusBadRequest {
+		if failure := decodeFailure(body, enveloped); failure != nil {
+			failure.status = resp.StatusCode
+			return failure
+		}
+		return &remoteError{status: resp.StatusCode, message: fmt.Sprintf("server %s responded with status %d", r.baseURL, resp.StatusCode)}
+	}
+	if v == nil {
+		return nil
+	}
+	if enveloped {
+		// Lists keep their items and pagination side by side; other responses are in data
+		var e struct {
+			Data       json.RawMessage `json:"data"`
+			Pagination json.RawMessage `json:"pagination"`
+		}
+		if err := json.Unmarshal(body, &e); err != nil {
+			return err
+		}
+		if e.Pagination == nil {
+			body = e.Data
+		}
+	}
+	return json.Unmarshal(body, v)
+}
+
+// decodeFailure decodes the error of a failed response, plain or enveloped, or returns nil
+func decodeFailure(body []byte, enveloped bool) *remoteError {
+	if enveloped {
+		var failure struct {
+			Error *struct {
+				Code    string `json:"code"`
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if json.Unmarshal(body, &failure) != nil || failure.Error == nil || failure.Error.Message == "" {
+			return nil
+		}
+		return &remoteError{code: failure.Error.Code, message: failure.Error.Message}
+	}
+
+	var failure struct {
+		Error string `json:"error"`
+		Code  string `json:"code"`
+	}
+	if json.Unmarshal(body, &failure) != nil || failure.Error == "" {
+		return nil
+	}
+	return &remoteError{code: failure.Code, message: failure.Error}
+}
+
+// remoteError is an error reported by the server, with the HTTP status of the response and the
+// code of the error, such as repo_not_tracked
+type remoteError struct {
+	status  int
+	code    string
+	message string
+}
+
+func (e *remoteError) Error() string {
+	return e.message
+}
+
+// get sends a GET request to the API
+func (r *remoteClient) get(ctx context.Context, path string, query url.Values, v interface{}) error {
+	return r.do(ctx, http.MethodGet, path, query, v)
+}
+
+// waitJob polls a job until it is done or timeout elapses; a zero timeout waits until it is done
+func (r *remoteClient) waitJob(ctx context.Context, job *models.Job, timeout time.Duration) (*models.Job, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+	for !job.Done() {
+		select {
+		case <-ctx.Done():
+			return job, nil
+		case <-ticker.C:
+		}
+		if err := r.get(ctx, "/api/v1/jobs/"+strconv.FormatInt(job.ID, 10), nil, &job); err != nil {
+			if ctx.Err() != nil {
+				return job, nil
+			}
+			return nil, err
+		}
+	}
+	return job, nil
+}
+
+// queryValues returns the query parameters of a list request, leaving out empty values
+func queryValues(params map[string]string) url.Values {
+	query := url.Values{}
+	for key, value := range params {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	return query
+}
+
+// timeValue formats a time query parameter, or returns "" for the zero time
+func timeValue(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// pageValue formats a page query parameter, or returns "" to use the server's default
+func pageValue(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
+// boolValue formats a flag query parameter, or returns "" when it is not set
+func boolValue(b bool) string {
+	if !b {
+		return ""
+	}
+	return "true"
+}
+
+// remoteItemQuery returns the query parameters of a pull request or issue list. The server
+// leaves out bodies unless include_body is sent.
+func remoteItemQuery(params map[string]string, since time.Time) url.Values {
+	query := url.Values{}
+	for key, value := range params {
+		if value != "" && key != "since" {
+			query.Set(key, value)
+		}
+	}
+	if !since.IsZero() {
+		query.Set("since", since.Format(time.RFC3339))
+	}
+	return query
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// newPRQueueCmd creates the command listing the pull requests waiting for a reviewer
+func newPRQueueCmd() *cobra.Command {
+	queueCmd := &cobra.Command{
+		Use:         "queue",
+		Short:       "List pull requests waiting for your review, oldest first",
+		Long:        "List the open pull requests requesting review from a reviewer, directly or through a configured team, oldest first. The reviewer is review_queue.user, or code_owners.user, unless --reviewer is given.",
+		Annotations: servedAnnotations,
+		Run: func(cmd *cobra.Command, args []string) {
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			filter := &models.ReviewQueueFilter{}
+			filter.Reviewer, _ = cmd.Flags().GetString("reviewer")
+			filter.Repo, _ = cmd.Flags().GetString("repo")
+			filter.RepoTag, _ = cmd.Flags().GetString("repo-tag")
+
+			items, err := client.ReviewQueue(filter)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error listing review queue: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			if quiet(cmd) {
+				for _, item := range items {
+					fmt.Printf("%s#%d\n", item.PullRequest.RepositoryFullName, item.PullRequest.Number)
+				}
+				return
+			}
+
+			fmt.Printf("%-9s %-8s %-40s %-15s %s\n", "STATUS", "WAITING", "PULL REQUEST", "AUTHOR", "TITLE")
+			for _, item := range items {
+				pr := item.PullRequest
+				fmt.Printf("%-9s %-8s %-40s %-15s %s\n", item.Status, formatWaiting(time.Since(item.WaitingSince)),
+					fmt.Sprintf("%s#%d", pr.RepositoryFullName, pr.Number), pr.UserLogin, pr.Title)
+			}
+		},
+	}
+	queueCmd.Flags().String("reviewer", "", "Reviewer login, instead of review_queue.user")
+	queueCmd.Flags().StringP("repo", "r", "", "Filter by repository (owner/name)")
+	queueCmd.Flags().String("repo-tag", "", "Filter by repository tag")
+	addQuietFlag(queueCmd)
+	return queueCmd
+}
+
+// formatWaiting formats how long a pull request has waited, such as 3d4h or 5h
+func formatWaiting(d time.Duration) string {
+	days, hours := int(d/(24*time.Hour)), int(d%(24*time.Hour)/time.Hour)
+	if days > 0 {
+		return fmt.Sprintf("%dd%dh", days, hours)
+	}
+	return fmt.Sprintf("%dh", hours)
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/spf13/cobra"
+
+	"github.com/siddontang/github-repos-management/internal/github"
+	"github.com/siddontang/github-repos-management/internal/service"
+	"github.com/siddontang/github-repos-management/internal/sso"
+)
+
+// Exit codes of failed commands, so that scripts can branch on the class of failure
+const (
+	exitFailure     = 1 // Any other failure
+	exitNotFound    = 2 // A repository, item or other resource doesn't exist
+	exitRateLimited = 3 // The GitHub rate limit is exhausted, or the server throttled the request
+	exitAuthFailure = 4 // GitHub, the server or a session rejected the credentials
+)
+
+// exitCode returns the exit code of a command failing with err. Errors are classified by service
+// sentinel, by the error code or HTTP status of server responses, or by the failure gh reported.
+func exitCode(err error) int {
+	var remoteErr *remoteError
+	switch {
+	case errors.As(err, &remoteErr) && remoteErr.code != "":
+		switch remoteErr.code {
+		case "rate_limited":
+			return exitRateLimited
+		case "repo_not_tracked", "not_found":
+			return exitNotFound
+		case "unauthorized", "forbidden":
+			return exitAuthFailure
+		}
+	case errors.As(err, &remoteErr):
+		// Servers of earlier releases send no error code
+		switch {
+		case remoteErr.status == http.StatusTooManyRequests || github.IsRateLimited(err):
+			return exitRateLimited
+		case remoteErr.status == http.StatusNotFound:
+			return exitNotFound
+		case remoteErr.status == http.StatusUnauthorized || remoteErr.status == http.StatusForbidden:
+			return exitAuthFailure
+		}
+	case errors.Is(err, service.ErrRepositoryNotFound), errors.Is(err, service.ErrPullRequestNotFound), errors.Is(err, service.ErrIssueNotFound),
+		errors.Is(err, service.ErrWebhookNotFound), errors.Is(err, service.ErrSubscriptionNotFound), errors.Is(err, service.ErrJobNotFound),
+		errors.Is(err, service.ErrWorkspaceNotFound), errors.Is(err, service.ErrWorkspaceTokenNotFound), errors.Is(err, service.ErrProjectNotFound),
+		errors.Is(err, service.ErrSLAPolicyNotFound), errors.Is(err, service.ErrMilestoneNotFound),
+		errors.Is(err, service.ErrTriageRuleNotFound):
+		return exitNotFound
+	case errors.Is(err, service.ErrSessionRequired), errors.Is(err, service.ErrAdminUnauthorized), errors.Is(err, service.ErrAdminDisabled),
+		errors.Is(err, service.ErrInvalidWorkspaceToken), errors.Is(err, sso.ErrInvalidSession), errors.Is(err, sso.ErrSessionExpired):
+		return exitAuthFailure
+	case github.IsRateLimited(err):
+		return exitRateLimited
+	case github.IsAuthFailure(err):
+		return exitAuthFailure
+	case github.IsNotFound(err):
+		return exitNotFound
+	}
+	return exitFailure
+}
+
+// addQuietFlag adds the --quiet flag, printing only the identifiers of the listed items
+func addQuietFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolP("quiet", "q", false, "Only print identifiers, one per line, without headers or pagination")
+}
+
+// quiet reports whether --quiet was given
+func quiet(cmd *cobra.Command) bool {
+	q, _ := cmd.Flags().GetBool("quiet")
+	return q
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/siddontang/github-repos-management/internal/github"
+	"github.com/siddontang/github-repos-management/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// newSeedRepoCmd creates the repo seed command
+func newSeedRepoCmd() *cobra.Command {
+	seedCmd := &cobra.Command{
+		Use:   "seed [owner/name]",
+		Short: "Store exported pull requests and issues without calling GitHub",
+		Long: "Seed the store from data exported earlier, so that onboarding large repositories doesn't spend API quota: " +
+			"either the output of 'gh pr list --json' and 'gh issue list --json' for a repository, run with --state all and a large " +
+			"enough --limit, or a GitHub migration archive (a .tar.gz of issues_*.json and pull_requests_*.json files) for every " +
+			"repository it holds. Untracked repositories start being tracked; stored items updated since the export are left as " +
+			"they are. Refresh afterwards to fetch what changed since the export.",
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			archivePath, _ := cmd.Flags().GetString("archive")
+			prsPath, _ := cmd.Flags().GetString("prs")
+			issuesPath, _ := cmd.Flags().GetString("issues")
+			if archivePath != "" && (len(args) > 0 || prsPath != "" || issuesPath != "") {
+				fmt.Fprintf(os.Stderr, "Error: --archive can't be combined with a repository, --prs or --issues\n")
+				os.Exit(1)
+			}
+			if archivePath == "" && (len(args) == 0 || prsPath == "" && issuesPath == "") {
+				fmt.Fprintf(os.Stderr, "Error: give a repository with --prs and/or --issues, or --archive\n")
+				os.Exit(1)
+			}
+
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			if archivePath != "" {
+				f, err := os.Open(archivePath)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error opening archive: %v\n", err)
+					os.Exit(1)
+				}
+				archive, err := github.ReadArchive(f)
+				f.Close()
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error reading archive: %v\n", err)
+					os.Exit(1)
+				}
+				results, err := client.SeedArchive(archive)
+				printSeedResults(results)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					os.Exit(exitCode(err))
+				}
+				return
+			}
+
+			var prs []*github.PullRequest
+			var issues []*github.Issue
+			if prsPath != "" {
+				data, err := os.ReadFile(prsPath)
+				if err == nil {
+					prs, err = github.ParsePullRequests(data)
+				}
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", prsPath, err)
+					os.Exit(1)
+				}
+			}
+			if issuesPath != "" {
+				data, err := os.ReadFile(issuesPath)
+				if err == nil {
+					issues, err = github.ParseIssues(data)
+				}
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", issuesPath, err)
+					os.Exit(1)
+				}
+			}
+			result, err := client.SeedRepository(args[0], prs, issues)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+			printSeedResults([]*models.SeedResult{result})
+		},
+	}
+	seedCmd.Flags().String("prs", "", "File holding the output of 'gh pr list --json' for the repository")
+	seedCmd.Flags().String("issues", "", "File holding the output of 'gh issue list --json' for the repository")
+	seedCmd.Flags().String("archive", "", "GitHub migration archive (.tar.gz) to seed every repository it holds from")
+	return seedCmd
+}
+
+// printSeedResults prints what seeding stored per repository
+func printSeedResults(results []*models.SeedResult) {
+	for _, result := range results {
+		tracked := ""
+		if result.Tracked {
+			tracked = " (now tracked)"
+		}
+		fmt.Printf("%s%s: %d pull requests, %d issues stored, %d skipped\n",
+			result.Repository, tracked, result.PullRequests, result.Issues, result.Skipped)
+	}
+}
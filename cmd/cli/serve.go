@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/siddontang/github-repos-management/internal/api"
+)
+
+// newServeCmd creates the command serving the web dashboard and the JSON API
+func newServeCmd() *cobra.Command {
+	serveCmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve the web dashboard and the JSON API",
+		Long: `Serve the web dashboard at / and the JSON API under /api/v1.
+Requests are attributed to the session token sent as a bearer token.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			// Every request authenticates itself, so the server runs without the CLI session
+			client, err := newClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+			defer client.service.Close()
+
+			addr, _ := cmd.Flags().GetString("addr")
+			if addr == "" {
+				addr = client.config.Server.Addr
+			}
+			if addr == "" {
+				addr = api.DefaultAddr
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			// Closed items past the retention policy are dropped in the background
+			go client.service.RunRetention(ctx)
+			// The configured warehouse is pushed to periodically
+			go client.service.RunWarehouse(ctx)
+
+			server := api.New(client.service, client.config.Server, nil)
+			// Requests are logged to stderr as configured by logging
+			if err := server.SetAccessLog(client.config.Logging, os.Stderr); err != nil {
+				fmt.Fprintf(os.Stderr, "Error configuring the access log: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			fmt.Printf("Serving on http://%s\n", addr)
+			if err := server.ListenAndServe(ctx, addr); err != nil {
+				fmt.Fprintf(os.Stderr, "Error serving: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+		},
+	}
+	serveCmd.Flags().String("addr", "", "Listen address (default server.addr or "+api.DefaultAddr+")")
+
+	return serveCmd
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// newSLACmd creates the SLA command group
+func newSLACmd() *cobra.Command {
+	slaCmd := &cobra.Command{
+		Use:   "sla",
+		Short: "Check pull requests and issues against SLA policies",
+		Long:  "Check the open pull requests and issues of the tracked repositories against the policies configured under sla.policies",
+	}
+
+	// Report command
+	reportSLACmd := &cobra.Command{
+		Use:         "report",
+		Short:       "List current SLA breaches per repository and team",
+		Annotations: servedAnnotations,
+		Run: func(cmd *cobra.Command, args []string) {
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			filter := &models.SLAFilter{}
+			filter.Repo, _ = cmd.Flags().GetString("repo")
+			filter.RepoTag, _ = cmd.Flags().GetString("repo-tag")
+			filter.Team, _ = cmd.Flags().GetString("team")
+			filter.Policy, _ = cmd.Flags().GetString("policy")
+
+			report, err := client.SLAReport(filter)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error reporting SLA breaches: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			fmt.Printf("%-20s %-8s %-40s %-20s %s\n", "POLICY", "OVERDUE", "ITEM", "TEAMS", "TITLE")
+			for _, breach := range report.Breaches {
+				teams := strings.Join(breach.Teams, ",")
+				if teams == "" {
+					teams = "-"
+				}
+				fmt.Printf("%-20s %-8s %-40s %-20s %s\n", breach.Policy, formatWaiting(time.Since(breach.Deadline)),
+					fmt.Sprintf("%s#%d", breach.RepositoryFullName, breach.Number), teams, breach.Title)
+			}
+
+			fmt.Printf("\nBreaches: %d\n", len(report.Breaches))
+			printBreachCounts("By repository", report.ByRepository)
+			printBreachCounts("By team", report.ByTeam)
+		},
+	}
+	reportSLACmd.Flags().StringP("repo", "r", "", "Filter by repository (owner/name)")
+	reportSLACmd.Flags().String("repo-tag", "", "Filter by repository tag")
+	reportSLACmd.Flags().String("team", "", "Only show breaches involving a configured team")
+	reportSLACmd.Flags().String("policy", "", "Only check a policy, by name")
+
+	slaCmd.AddCommand(reportSLACmd)
+	return slaCmd
+}
+
+// printBreachCounts prints breach counts by name, most breaches first
+func printBreachCounts(title string, counts map[string]int) {
+	if len(counts) == 0 {
+		return
+	}
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if counts[names[i]] != counts[names[j]] {
+			return counts[names[i]] > counts[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	fmt.Printf("%s:\n", title)
+	for _, name := range names {
+		fmt.Printf("  %-40s %d\n", name, counts[name])
+	}
+}
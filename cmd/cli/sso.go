@@ -0,0 +1,146 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// sessionFile returns where the session token of the logged in user is kept
+func sessionFile() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate the config directory: %w", err)
+	}
+	return filepath.Join(dir, "ghrepos", "session"), nil
+}
+
+// loadSessionToken returns the session token from GHREPOS_SESSION or the session file, if any
+func loadSessionToken() (string, error) {
+	if token := os.Getenv("GHREPOS_SESSION"); token != "" {
+		return token, nil
+	}
+	path, err := sessionFile()
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read session: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// newSSOCmd creates the sso command group
+func newSSOCmd() *cobra.Command {
+	ssoCmd := &cobra.Command{
+		Use:   "sso",
+		Short: "Log in with your organization's identity provider",
+		Long:  "Log in through the configured OIDC provider or GitHub OAuth, so your changes are attributed to you",
+	}
+
+	loginCmd := &cobra.Command{
+		Use:   "login",
+		Short: "Log in and store a session",
+		Run: func(cmd *cobra.Command, args []string) {
+			client, err := newClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			code, err := client.service.StartLogin(client.ctx)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error starting login: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+			if code.VerificationURIComplete != "" {
+				fmt.Printf("Open %s to log in\n", code.VerificationURIComplete)
+			} else {
+				fmt.Printf("Open %s and enter the code %s\n", code.VerificationURI, code.UserCode)
+			}
+
+			token, session, err := client.service.CompleteLogin(client.ctx, code)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error logging in: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			printToken, _ := cmd.Flags().GetBool("print-token")
+			if printToken {
+				fmt.Println(token)
+				return
+			}
+			path, err := sessionFile()
+			if err == nil {
+				if err = os.MkdirAll(filepath.Dir(path), 0700); err == nil {
+					err = os.WriteFile(path, []byte(token+"\n"), 0600)
+				}
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error saving session: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+			fmt.Printf("Logged in as %s until %s\n", session.User(), session.ExpiresAt.Format("2006-01-02 15:04:05"))
+		},
+	}
+	loginCmd.Flags().Bool("print-token", false, "Print the session token instead of storing it, for use in GHREPOS_SESSION")
+
+	logoutCmd := &cobra.Command{
+		Use:   "logout",
+		Short: "Remove the stored session",
+		Run: func(cmd *cobra.Command, args []string) {
+			path, err := sessionFile()
+			if err == nil {
+				err = os.Remove(path)
+			}
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				fmt.Fprintf(os.Stderr, "Error removing session: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+			fmt.Println("Logged out")
+		},
+	}
+
+	whoamiCmd := &cobra.Command{
+		Use:   "whoami",
+		Short: "Show the logged in user",
+		Run: func(cmd *cobra.Command, args []string) {
+			client, err := newClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+			token, err := loadSessionToken()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+			if token == "" {
+				fmt.Printf("Not logged in; changes are attributed to %s\n", currentActor())
+				return
+			}
+
+			_, session, err := client.service.Authenticate(client.ctx, token)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error verifying session: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+			if session == nil {
+				fmt.Println("Authenticated with a workspace token")
+				return
+			}
+			fmt.Printf("Logged in as %s (%s, subject %s) until %s\n", session.User(), session.Provider, session.Subject, session.ExpiresAt.Format("2006-01-02 15:04:05"))
+		},
+	}
+
+	ssoCmd.AddCommand(loginCmd, logoutCmd, whoamiCmd)
+	return ssoCmd
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// newSubscriptionCmd creates the subscription command group
+func newSubscriptionCmd() *cobra.Command {
+	subscriptionCmd := &cobra.Command{
+		Use:   "subscription",
+		Short: "Manage label subscriptions",
+		Long:  "Get notified on Slack or a webhook when a label is added to a pull request or issue of a tracked repository",
+	}
+
+	// Add subscription command
+	addSubscriptionCmd := &cobra.Command{
+		Use:   "add [label] [url]",
+		Short: "Subscribe a Slack incoming webhook or a webhook to a label",
+		Args:  cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			sub := &models.Subscription{Label: args[0], URL: args[1]}
+			sub.Repository, _ = cmd.Flags().GetString("repo")
+			sub.Channel, _ = cmd.Flags().GetString("channel")
+			sub.Secret, _ = cmd.Flags().GetString("secret")
+
+			sub, err = client.AddSubscription(sub)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error adding subscription: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			fmt.Printf("Subscription %d added successfully\n", sub.ID)
+		},
+	}
+	addSubscriptionCmd.Flags().StringP("repo", "r", "", "Only subscribe to a repository (owner/name), default every tracked repository")
+	addSubscriptionCmd.Flags().String("channel", models.SubscriptionChannelWebhook, "Delivery channel: slack or webhook")
+	addSubscriptionCmd.Flags().String("secret", "", "Secret used to sign webhook payloads (HMAC-SHA256)")
+
+	// List subscriptions command
+	listSubscriptionCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List label subscriptions",
+		Run: func(cmd *cobra.Command, args []string) {
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			subs, err := client.ListSubscriptions()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error listing subscriptions: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			fmt.Printf("%-5s %-20s %-30s %-8s %s\n", "ID", "LABEL", "REPOSITORY", "CHANNEL", "URL")
+			for _, sub := range subs {
+				repo := sub.Repository
+				if repo == "" {
+					repo = "all"
+				}
+				fmt.Printf("%-5d %-20s %-30s %-8s %s\n", sub.ID, sub.Label, repo, sub.Channel, sub.URL)
+			}
+		},
+	}
+
+	// Remove subscription command
+	removeSubscriptionCmd := &cobra.Command{
+		Use:   "remove [id]",
+		Short: "Remove a label subscription",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			id, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid subscription ID: %s\n", args[0])
+				os.Exit(1)
+			}
+
+			if err := client.RemoveSubscription(id); err != nil {
+				fmt.Fprintf(os.Stderr, "Error removing subscription: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			fmt.Printf("Subscription %d removed successfully\n", id)
+		},
+	}
+
+	subscriptionCmd.AddCommand(addSubscriptionCmd, listSubscriptionCmd, removeSubscriptionCmd)
+	return subscriptionCmd
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// templateFuncs are the functions available to --template besides the text/template builtins
+var templateFuncs = template.FuncMap{
+	"join":  func(sep string, list []string) string { return strings.Join(list, sep) },
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"date":  func(t time.Time) string { return t.Format("2006-01-02") },
+	"truncate": func(n int, s string) string {
+		if runes := []rune(s); len(runes) > n {
+			return string(runes[:n])
+		}
+		return s
+	},
+}
+
+// addTemplateFlag adds the --template flag, printing each item with a Go template instead of the
+// default output
+func addTemplateFlag(cmd *cobra.Command, example string) {
+	cmd.Flags().String("template", "", fmt.Sprintf("Print each item with a Go template instead, e.g. '%s' (functions: join, upper, lower, date, truncate)", example))
+}
+
+// parseTemplate parses the --template flag, returning nil when it isn't set
+func parseTemplate(cmd *cobra.Command) (*template.Template, error) {
+	text, _ := cmd.Flags().GetString("template")
+	if text == "" {
+		return nil, nil
+	}
+	tmpl, err := template.New("template").Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// mustParseTemplate parses the --template flag, exiting on an invalid template
+func mustParseTemplate(cmd *cobra.Command) *template.Template {
+	tmpl, err := parseTemplate(cmd)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	return tmpl
+}
+
+// printTemplate prints an item with a template, ending it with a newline unless the template does
+func printTemplate(tmpl *template.Template, item any) {
+	var b strings.Builder
+	if err := tmpl.Execute(&b, item); err != nil {
+		fmt.Fprintf(os.Stderr, "Error executing template: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	out := b.String()
+	if !strings.HasSuffix(out, "\n") {
+		out += "\n"
+	}
+	fmt.Print(out)
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+// colorMode is the --color flag: auto, always or never
+var colorMode string
+
+// ANSI colors of item states and diff lines
+const (
+	colorCyan   = "\x1b[36m"
+	colorGreen  = "\x1b[32m"
+	colorPurple = "\x1b[35m"
+	colorRed    = "\x1b[31m"
+	colorReset  = "\x1b[0m"
+)
+
+// addColorFlag adds the --color flag to the root command
+func addColorFlag(rootCmd *cobra.Command) {
+	rootCmd.PersistentFlags().StringVar(&colorMode, "color", "auto", "Color item states in tables and diffs: auto (on a terminal, unless NO_COLOR is set), always or never")
+}
+
+// isTerminal reports whether a file is a terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// validateColorMode checks the --color flag
+func validateColorMode() error {
+	switch colorMode {
+	case "auto", "always", "never":
+		return nil
+	}
+	return fmt.Errorf("invalid --color %q, expected auto, always or never", colorMode)
+}
+
+// colorEnabled reports whether tables are colored, following --color and the NO_COLOR convention
+// (https://no-color.org)
+func colorEnabled() bool {
+	switch colorMode {
+	case "always":
+		return true
+	case "never":
+		return false
+	}
+	return os.Getenv("NO_COLOR") == "" && isTerminal(os.Stdout)
+}
+
+// outputWidth returns the width tables are cut at: $COLUMNS, else the width of the terminal, or 0
+// when standard output isn't a terminal so that piped output is never truncated
+func outputWidth() int {
+	if !isTerminal(os.Stdout) {
+		return 0
+	}
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
+	return terminalWidth(os.Stdout)
+}
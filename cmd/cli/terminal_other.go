@@ -0,0 +1,11 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package main
+
+import "os"
+
+// terminalWidth returns 0 on platforms where the terminal size isn't queried; set $COLUMNS there
+// to truncate tables
+func terminalWidth(f *os.File) int {
+	return 0
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+	"github.com/siddontang/github-repos-management/internal/service"
+	"github.com/spf13/cobra"
+)
+
+// newTriageCmd creates the triage command group
+func newTriageCmd() *cobra.Command {
+	triageCmd := &cobra.Command{
+		Use:   "triage",
+		Short: "Manage auto-triage rules",
+		Long:  "Label, comment on or notify about open pull requests and issues matching a rule after each sync",
+	}
+
+	// Add triage rule command
+	addTriageCmd := &cobra.Command{
+		Use:   "add [name]",
+		Short: "Add a triage rule from flags, or every rule of a YAML file",
+		Args:  cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			path, _ := cmd.Flags().GetString("file")
+			var rules []*models.TriageRule
+			if path != "" {
+				data, err := os.ReadFile(path)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", path, err)
+					os.Exit(1)
+				}
+				if rules, err = service.ParseTriageRules(data); err != nil {
+					fmt.Fprintf(os.Stderr, "Error parsing %s: %v\n", path, err)
+					os.Exit(exitCode(err))
+				}
+			} else {
+				if len(args) == 0 {
+					fmt.Fprintln(os.Stderr, "Error: a rule name or --file is required")
+					os.Exit(1)
+				}
+				rule := &models.TriageRule{Name: args[0]}
+				rule.Type, _ = cmd.Flags().GetString("type")
+				rule.Repository, _ = cmd.Flags().GetString("repo")
+				rule.If.TitleMatches, _ = cmd.Flags().GetString("title-matches")
+				rule.If.Author, _ = cmd.Flags().GetString("author")
+				rule.If.NoLabelDays, _ = cmd.Flags().GetInt("no-label-days")
+				rule.Then.AddLabel, _ = cmd.Flags().GetString("label")
+				rule.Then.Comment, _ = cmd.Flags().GetString("comment")
+				rule.Then.Notify, _ = cmd.Flags().GetBool("notify")
+				rules = append(rules, rule)
+			}
+
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			for _, rule := range rules {
+				rule, err = client.AddTriageRule(rule)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error adding triage rule: %v\n", err)
+					os.Exit(exitCode(err))
+				}
+				fmt.Printf("Triage rule %d (%s) added successfully\n", rule.ID, rule.Name)
+			}
+		},
+	}
+	addTriageCmd.Flags().StringP("file", "f", "", "YAML file of rules under a top-level rules key")
+	addTriageCmd.Flags().String("type", "", "Only triage pull_request or issue items, default both")
+	addTriageCmd.Flags().StringP("repo", "r", "", "Only triage a repository (owner/name), default every tracked repository")
+	addTriageCmd.Flags().String("title-matches", "", "Match items whose title matches a regular expression")
+	addTriageCmd.Flags().String("author", "", "Match items opened by a user")
+	addTriageCmd.Flags().Int("no-label-days", 0, "Match items still unlabeled this many days after they were opened")
+	addTriageCmd.Flags().String("label", "", "Add a label to matching items")
+	addTriageCmd.Flags().String("comment", "", "Comment on matching items, a Go template such as \"Thanks @{{.Author}}\"")
+	addTriageCmd.Flags().Bool("notify", false, "Send a triage.matched notification for matching items")
+
+	// List triage rules command
+	listTriageCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List triage rules",
+		Run: func(cmd *cobra.Command, args []string) {
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			rules, err := client.ListTriageRules()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error listing triage rules: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			fmt.Printf("%-5s %-20s %-30s %-40s %s\n", "ID", "NAME", "REPOSITORY", "IF", "THEN")
+			for _, rule := range rules {
+				repo := rule.Repository
+				if repo == "" {
+					repo = "all"
+				}
+				fmt.Printf("%-5d %-20s %-30s %-40s %s\n", rule.ID, rule.Name, repo, triageConditionText(rule), triageActionsText(rule))
+			}
+		},
+	}
+
+	// Remove triage rule command
+	removeTriageCmd := &cobra.Command{
+		Use:   "remove [id]",
+		Short: "Remove a triage rule and its execution log",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			id, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid triage rule ID: %s\n", args[0])
+				os.Exit(1)
+			}
+
+			if err := client.RemoveTriageRule(id); err != nil {
+				fmt.Fprintf(os.Stderr, "Error removing triage rule: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			fmt.Printf("Triage rule %d removed successfully\n", id)
+		},
+	}
+
+	// Triage rule execution log command
+	logTriageCmd := &cobra.Command{
+		Use:   "log [id]",
+		Short: "Show the items a triage rule acted on",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			id, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid triage rule ID: %s\n", args[0])
+				os.Exit(1)
+			}
+
+			executions, err := client.ListTriageExecutions(id)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error listing triage executions: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			fmt.Printf("%-20s %-40s %-30s %s\n", "EXECUTED", "ITEM", "ACTIONS", "ERROR")
+			for _, execution := range executions {
+				item := fmt.Sprintf("%s#%d", execution.RepositoryFullName, execution.Number)
+				fmt.Printf("%-20s %-40s %-30s %s\n", execution.ExecutedAt.Format("2006-01-02 15:04:05"), item,
+					strings.Join(execution.Actions, ", "), execution.Error)
+			}
+		},
+	}
+
+	triageCmd.AddCommand(addTriageCmd, listTriageCmd, removeTriageCmd, logTriageCmd)
+	return triageCmd
+}
+
+// triageConditionText describes the conditions of a triage rule
+func triageConditionText(rule *models.TriageRule) string {
+	var parts []string
+	if rule.Type != "" {
+		parts = append(parts, "type="+rule.Type)
+	}
+	if rule.If.TitleMatches != "" {
+		parts = append(parts, fmt.Sprintf("title~%q", rule.If.TitleMatches))
+	}
+	if rule.If.Author != "" {
+		parts = append(parts, "author="+rule.If.Author)
+	}
+	if rule.If.NoLabelDays > 0 {
+		parts = append(parts, fmt.Sprintf("unlabeled %dd", rule.If.NoLabelDays))
+	}
+	return strings.Join(parts, " ")
+}
+
+// triageActionsText describes the actions of a triage rule
+func triageActionsText(rule *models.TriageRule) string {
+	var parts []string
+	if rule.Then.AddLabel != "" {
+		parts = append(parts, "label "+rule.Then.AddLabel)
+	}
+	if rule.Then.Comment != "" {
+		parts = append(parts, "comment")
+	}
+	if rule.Then.Notify {
+		parts = append(parts, "notify")
+	}
+	return strings.Join(parts, ", ")
+}
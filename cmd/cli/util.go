@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// splitRepoName splits an "owner/name" argument into its parts
+func splitRepoName(fullName string) (string, string, error) {
+	parts := strings.Split(fullName, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("Invalid repository name format, expected 'owner/repo'")
+	}
+	return parts[0], parts[1], nil
+}
+
+// splitItemRef splits an "owner/name#number" argument into its parts
+func splitItemRef(ref string) (string, string, int, error) {
+	fullName, numberStr, found := strings.Cut(ref, "#")
+	number, err := strconv.Atoi(numberStr)
+	if !found || err != nil || number <= 0 {
+		return "", "", 0, fmt.Errorf("Invalid reference %q, expected 'owner/repo#number'", ref)
+	}
+	owner, name, err := splitRepoName(fullName)
+	if err != nil {
+		return "", "", 0, err
+	}
+	return owner, name, number, nil
+}
+
+// parseTimeFlag parses a date (YYYY-MM-DD) or RFC3339 timestamp; an empty value yields the zero time
+func parseTimeFlag(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02", value, time.Local)
+}
+
+// parseWindow parses a duration that may also use a day suffix, such as "90d"
+func parseWindow(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid window %q", value)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(value)
+}
+
+// printCursors prints the cursors of the neighbouring pages, if any
+func printCursors(pagination *Pagination) {
+	if pagination.NextCursor != "" {
+		fmt.Printf("Next cursor: %s\n", pagination.NextCursor)
+	}
+	if pagination.PrevCursor != "" {
+		fmt.Printf("Previous cursor: %s\n", pagination.PrevCursor)
+	}
+}
+
+// printLogins prints a labeled list of users or teams, if any
+func printLogins(label string, logins []string) {
+	if len(logins) > 0 {
+		fmt.Printf("  %s: %s\n", label, strings.Join(logins, ", "))
+	}
+}
+
+// printStateHistory prints the state transitions of a pull request or issue
+func printStateHistory(history models.StateHistory) {
+	if len(history) == 0 {
+		return
+	}
+
+	fmt.Println("\nState history:")
+	for _, t := range history {
+		fmt.Printf("  %s  %s -> %s\n", t.At.Format("2006-01-02 15:04:05"), t.From, t.To)
+	}
+}
+
+// printRefreshPlan prints what a refresh would fetch; skipped repositories are only listed when verbose
+func printRefreshPlan(plan *models.RefreshPlan, verbose bool) {
+	fmt.Printf("%-40s %-8s %-8s %-8s %-8s %-8s %-10s %-10s %s\n", "REPOSITORY", "PRIORITY", "CHANGES", "PRS", "ISSUES", "REVIEWS", "REQUESTS", "ITEMS", "LAST SYNCED")
+	yesNo := func(b bool) string {
+		if b {
+			return "yes"
+		}
+		return "no"
+	}
+	skipped := 0
+	for _, repo := range plan.Repositories {
+		if repo.Skip != "" {
+			skipped++
+			if verbose {
+				fmt.Printf("%-40s skipped (%s)\n", repo.FullName, repo.Skip)
+			}
+			continue
+		}
+		fmt.Printf("%-40s %-8s %-8d %-8s %-8s %-8s %-10d %-10d %s\n", repo.FullName, repo.Priority, repo.RecentChanges, yesNo(repo.PullRequests), yesNo(repo.Issues), yesNo(repo.Reviews),
+			repo.EstimatedRequests, repo.EstimatedItems, repo.LastSyncedAt.Format("2006-01-02 15:04:05"))
+	}
+
+	fmt.Printf("\n%d repositories would be refreshed, %d skipped\n", len(plan.Repositories)-skipped, skipped)
+	fmt.Printf("Estimated requests: %d, items: up to %d\n", plan.EstimatedRequests, plan.EstimatedItems)
+	if plan.RateLimit == nil {
+		fmt.Println("Rate limit: unknown")
+		return
+	}
+	fmt.Printf("Rate limit: %d of %d remaining, resets at %s\n", plan.RateLimit.Remaining, plan.RateLimit.Limit, plan.RateLimit.ResetAt.Format("2006-01-02 15:04:05"))
+	if plan.ExceedsRateLimit() {
+		fmt.Println("Warning: the refresh needs more requests than the rate limit has left")
+	}
+}
+
+// printAddResults prints the outcome of adding each repository, returning how many failed
+func printAddResults(results []*models.RepositoryAddResult) int {
+	failed := 0
+	fmt.Printf("%-40s %s\n", "REPOSITORY", "RESULT")
+	for _, result := range results {
+		switch {
+		case result.Error != "":
+			failed++
+			fmt.Printf("%-40s failed: %s\n", result.FullName, result.Error)
+		case result.Existing:
+			fmt.Printf("%-40s already tracked\n", result.FullName)
+		default:
+			fmt.Printf("%-40s added\n", result.FullName)
+		}
+	}
+
+	fmt.Printf("\n%d repositories processed, %d failed\n", len(results), failed)
+	return failed
+}
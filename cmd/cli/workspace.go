@@ -0,0 +1,242 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// newWorkspaceCmd creates the workspace command group
+func newWorkspaceCmd() *cobra.Command {
+	workspaceCmd := &cobra.Command{
+		Use:   "workspace",
+		Short: "Manage workspaces",
+		Long: "Group repositories into workspaces with their own API tokens and sync schedule. " +
+			"Run other commands with --workspace to restrict them to a workspace; repositories added that way join it.",
+	}
+
+	// Create workspace command
+	createWorkspaceCmd := &cobra.Command{
+		Use:   "create [id]",
+		Short: "Create a workspace",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			name, _ := cmd.Flags().GetString("name")
+			interval, _ := cmd.Flags().GetDuration("sync-interval")
+
+			ws, err := client.CreateWorkspace(args[0], name, interval)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error creating workspace: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			fmt.Printf("Workspace %s created successfully\n", ws.ID)
+		},
+	}
+	createWorkspaceCmd.Flags().String("name", "", "Display name (default the ID)")
+	createWorkspaceCmd.Flags().Duration("sync-interval", 0, "Sync interval of the workspace's repositories (0 uses the global interval)")
+
+	// List workspaces command
+	listWorkspaceCmd := &cobra.Command{
+		Use:         "list",
+		Short:       "List workspaces",
+		Annotations: servedAnnotations,
+		Run: func(cmd *cobra.Command, args []string) {
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			workspaces, err := client.ListWorkspaces()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error listing workspaces: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			fmt.Printf("%-20s %-30s %-13s %-7s %s\n", "ID", "NAME", "REPOSITORIES", "TOKENS", "SYNC INTERVAL")
+			for _, ws := range workspaces {
+				fmt.Printf("%-20s %-30s %-13d %-7d %s\n", ws.ID, ws.Name, len(ws.Repositories), len(ws.Tokens), workspaceInterval(ws))
+			}
+		},
+	}
+
+	// Show workspace command
+	showWorkspaceCmd := &cobra.Command{
+		Use:   "show [id]",
+		Short: "Show a workspace with its repositories and tokens",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			ws, err := client.GetWorkspace(args[0])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error getting workspace: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			fmt.Printf("Workspace: %s\n", ws.ID)
+			fmt.Printf("Name: %s\n", ws.Name)
+			fmt.Printf("Sync interval: %s\n", workspaceInterval(ws))
+			fmt.Printf("Created: %s\n", ws.CreatedAt.Format("2006-01-02 15:04:05"))
+			fmt.Printf("Repositories (%d):\n", len(ws.Repositories))
+			for _, fullName := range ws.Repositories {
+				fmt.Printf("  %s\n", fullName)
+			}
+			fmt.Printf("Tokens (%d):\n", len(ws.Tokens))
+			for _, token := range ws.Tokens {
+				fmt.Printf("  %d  %-20s created %s, %s\n", token.ID, token.Name, token.CreatedAt.Format("2006-01-02"), tokenLastUsed(token))
+			}
+		},
+	}
+
+	// Update workspace command
+	updateWorkspaceCmd := &cobra.Command{
+		Use:   "update [id]",
+		Short: "Rename a workspace or change its sync interval",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			update := &models.WorkspaceUpdate{}
+			if cmd.Flags().Changed("name") {
+				name, _ := cmd.Flags().GetString("name")
+				update.Name = &name
+			}
+			if cmd.Flags().Changed("sync-interval") {
+				interval, _ := cmd.Flags().GetDuration("sync-interval")
+				update.SyncInterval = &interval
+			}
+			if update.Name == nil && update.SyncInterval == nil {
+				fmt.Fprintf(os.Stderr, "Error: specify --name or --sync-interval\n")
+				os.Exit(1)
+			}
+
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			ws, err := client.UpdateWorkspace(args[0], update)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error updating workspace: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			fmt.Printf("Workspace %s updated: name %s, sync interval %s\n", ws.ID, ws.Name, workspaceInterval(ws))
+		},
+	}
+	updateWorkspaceCmd.Flags().String("name", "", "Display name")
+	updateWorkspaceCmd.Flags().Duration("sync-interval", 0, "Sync interval of the workspace's repositories (0 uses the global interval)")
+
+	// Delete workspace command
+	deleteWorkspaceCmd := &cobra.Command{
+		Use:   "delete [id]",
+		Short: "Delete a workspace and its tokens; its repositories stay tracked",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			if err := client.DeleteWorkspace(args[0]); err != nil {
+				fmt.Fprintf(os.Stderr, "Error deleting workspace: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			fmt.Printf("Workspace %s deleted successfully\n", args[0])
+		},
+	}
+
+	// Token command
+	tokenCmd := &cobra.Command{
+		Use:   "token",
+		Short: "Manage the API tokens of a workspace",
+		Long:  "Issue bearer tokens that only grant access to the repositories of one workspace",
+	}
+
+	// Create token command
+	createTokenCmd := &cobra.Command{
+		Use:   "create [workspace]",
+		Short: "Issue an API token for a workspace",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			name, _ := cmd.Flags().GetString("name")
+			raw, token, err := client.CreateWorkspaceToken(args[0], name)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error creating token: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			fmt.Printf("Token %d (%s) created for workspace %s. It is not shown again:\n%s\n", token.ID, token.Name, args[0], raw)
+		},
+	}
+	createTokenCmd.Flags().String("name", "", "Name describing the token's use")
+
+	// Revoke token command
+	revokeTokenCmd := &cobra.Command{
+		Use:   "revoke [workspace] [token-id]",
+		Short: "Revoke an API token of a workspace",
+		Args:  cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			id, err := strconv.ParseInt(args[1], 10, 64)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid token ID: %s\n", args[1])
+				os.Exit(1)
+			}
+
+			client, err := NewClient()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing client: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			if err := client.RevokeWorkspaceToken(args[0], id); err != nil {
+				fmt.Fprintf(os.Stderr, "Error revoking token: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+
+			fmt.Printf("Token %d of workspace %s revoked successfully\n", id, args[0])
+		},
+	}
+
+	tokenCmd.AddCommand(createTokenCmd, revokeTokenCmd)
+	workspaceCmd.AddCommand(createWorkspaceCmd, listWorkspaceCmd, showWorkspaceCmd, updateWorkspaceCmd, deleteWorkspaceCmd, tokenCmd)
+	return workspaceCmd
+}
+
+// workspaceInterval describes the sync interval of a workspace
+func workspaceInterval(ws *models.Workspace) string {
+	if ws.SyncInterval <= 0 {
+		return "global"
+	}
+	return ws.SyncInterval.String()
+}
+
+// tokenLastUsed describes when a workspace token was last used
+func tokenLastUsed(token *models.WorkspaceToken) string {
+	if token.LastUsedAt.IsZero() {
+		return "never used"
+	}
+	return "last used " + token.LastUsedAt.Format("2006-01-02 15:04")
+}
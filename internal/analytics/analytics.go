@@ -0,0 +1,180 @@
+package analytics
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+	"github.com/siddontang/github-repos-management/internal/workhours"
+)
+
+// DurationStats summarizes a set of durations
+type DurationStats struct {
+	Count  int           `json:"count"`
+	Mean   time.Duration `json:"mean"`
+	Median time.Duration `json:"median"`
+	P90    time.Duration `json:"p90"`
+}
+
+// Summarize computes the statistics of the given durations
+func Summarize(durations []time.Duration) DurationStats {
+	if len(durations) == 0 {
+		return DurationStats{}
+	}
+
+	sorted := make([]time.Duration, len(durations))
+	copy(sorted, durations)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i] < sorted[j]
+	})
+
+	var sum time.Duration
+	for _, d := range sorted {
+		sum += d
+	}
+
+	return DurationStats{
+		Count:  len(sorted),
+		Mean:   sum / time.Duration(len(sorted)),
+		Median: percentile(sorted, 50),
+		P90:    percentile(sorted, 90),
+	}
+}
+
+// percentile returns the nearest-rank percentile of sorted durations
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// Stats holds lead-time metrics for a group of pull requests and issues
+type Stats struct {
+	Key                string        `json:"key"`
+	PullRequestsOpened int           `json:"pull_requests_opened"`
+	PullRequestsMerged int           `json:"pull_requests_merged"`
+	IssuesOpened       int           `json:"issues_opened"`
+	IssuesClosed       int           `json:"issues_closed"`
+	Commits            int           `json:"commits"` // Default branch commits, when commits are synced
+	TimeToFirstReview  DurationStats `json:"time_to_first_review"`
+	TimeToMerge        DurationStats `json:"time_to_merge"`
+	TimeToClose        DurationStats `json:"time_to_close"`
+}
+
+// Report holds lead-time metrics overall, per repository and per author
+type Report struct {
+	Overall      *Stats   `json:"overall"`
+	Repositories []*Stats `json:"repositories"`
+	Authors      []*Stats `json:"authors"`
+}
+
+// accumulator collects the raw durations of a group before summarizing
+type accumulator struct {
+	stats       Stats
+	hours       *workhours.Calendar // Measures the durations, in wall-clock time when nil
+	firstReview []time.Duration
+	merge       []time.Duration
+	close       []time.Duration
+}
+
+func (a *accumulator) addPullRequest(pr *models.PullRequest) {
+	a.stats.PullRequestsOpened++
+	if pr.FirstReviewAt != nil {
+		a.firstReview = append(a.firstReview, a.hours.Between(pr.CreatedAt, *pr.FirstReviewAt))
+	}
+	if pr.MergedAt != nil {
+		a.stats.PullRequestsMerged++
+		a.merge = append(a.merge, a.hours.Between(pr.CreatedAt, *pr.MergedAt))
+	}
+}
+
+func (a *accumulator) addIssue(issue *models.Issue) {
+	a.stats.IssuesOpened++
+	if issue.ClosedAt != nil {
+		a.stats.IssuesClosed++
+		a.close = append(a.close, a.hours.Between(issue.CreatedAt, *issue.ClosedAt))
+	}
+}
+
+func (a *accumulator) addCommit() {
+	a.stats.Commits++
+}
+
+func (a *accumulator) summarize() *Stats {
+	stats := a.stats
+	stats.TimeToFirstReview = Summarize(a.firstReview)
+	stats.TimeToMerge = Summarize(a.merge)
+	stats.TimeToClose = Summarize(a.close)
+	return &stats
+}
+
+// group returns the accumulator for key, creating it when needed
+func group(groups map[string]*accumulator, key string, hours *workhours.Calendar) *accumulator {
+	acc, ok := groups[key]
+	if !ok {
+		acc = &accumulator{stats: Stats{Key: key}, hours: hours}
+		groups[key] = acc
+	}
+	return acc
+}
+
+// Compute builds a lead-time report from pull requests and issues, counting commits per group.
+// Lead times are measured in the working time of hours, or wall-clock time when it is nil.
+func Compute(prs []*models.PullRequest, issues []*models.Issue, commits []*models.Commit, hours *workhours.Calendar) *Report {
+	overall := &accumulator{stats: Stats{Key: "all"}, hours: hours}
+	repos := make(map[string]*accumulator)
+	authors := make(map[string]*accumulator)
+
+	for _, pr := range prs {
+		overall.addPullRequest(pr)
+		group(repos, pr.RepositoryFullName, hours).addPullRequest(pr)
+		group(authors, strings.ToLower(pr.UserLogin), hours).addPullRequest(pr)
+	}
+	for _, issue := range issues {
+		overall.addIssue(issue)
+		group(repos, issue.RepositoryFullName, hours).addIssue(issue)
+		group(authors, strings.ToLower(issue.UserLogin), hours).addIssue(issue)
+	}
+	for _, commit := range commits {
+		overall.addCommit()
+		group(repos, commit.RepositoryFullName, hours).addCommit()
+		group(authors, strings.ToLower(commit.Author), hours).addCommit()
+	}
+
+	return &Report{
+		Overall:      overall.summarize(),
+		Repositories: summarizeGroups(repos),
+		Authors:      summarizeGroups(authors),
+	}
+}
+
+// summarizeGroups summarizes every group, most active first
+func summarizeGroups(groups map[string]*accumulator) []*Stats {
+	stats := make([]*Stats, 0, len(groups))
+	for _, acc := range groups {
+		stats = append(stats, acc.summarize())
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		ai := stats[i].PullRequestsOpened + stats[i].IssuesOpened
+		aj := stats[j].PullRequestsOpened + stats[j].IssuesOpened
+		if ai != aj {
+			return ai > aj
+		}
+		return stats[i].Key < stats[j].Key
+	})
+	return stats
+}
+
+// InRange reports whether t falls in [since, until); zero bounds are open
+func InRange(t, since, until time.Time) bool {
+	if !since.IsZero() && t.Before(since) {
+		return false
+	}
+	if !until.IsZero() && !t.Before(until) {
+		return false
+	}
+	return true
+}
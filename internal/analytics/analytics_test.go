@@ -0,0 +1,144 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/models"
+	"github.com/siddontang/github-repos-management/internal/workhours"
+)
+
+// TestSummarize tests the Summarize function
+func TestSummarize(t *testing.T) {
+	if got := Summarize(nil); got.Count != 0 {
+		t.Errorf("Summarize(nil).Count = %v, want 0", got.Count)
+	}
+
+	durations := []time.Duration{}
+	for i := 10; i >= 1; i-- {
+		durations = append(durations, time.Duration(i)*time.Hour)
+	}
+
+	got := Summarize(durations)
+	if got.Count != 10 {
+		t.Errorf("Count = %v, want %v", got.Count, 10)
+	}
+	if want := 330 * time.Minute; got.Mean != want {
+		t.Errorf("Mean = %v, want %v", got.Mean, want)
+	}
+	if want := 5 * time.Hour; got.Median != want {
+		t.Errorf("Median = %v, want %v", got.Median, want)
+	}
+	if want := 9 * time.Hour; got.P90 != want {
+		t.Errorf("P90 = %v, want %v", got.P90, want)
+	}
+}
+
+// TestCompute tests the Compute function
+func TestCompute(t *testing.T) {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	reviewed := created.Add(2 * time.Hour)
+	merged := created.Add(24 * time.Hour)
+	closed := created.Add(48 * time.Hour)
+
+	prs := []*models.PullRequest{
+		{RepositoryFullName: "pingcap/tidb", UserLogin: "alice", CreatedAt: created, FirstReviewAt: &reviewed, MergedAt: &merged},
+		{RepositoryFullName: "pingcap/tikv", UserLogin: "Alice", CreatedAt: created},
+	}
+	issues := []*models.Issue{
+		{RepositoryFullName: "pingcap/tidb", UserLogin: "bob", CreatedAt: created, ClosedAt: &closed},
+	}
+
+	report := Compute(prs, issues, nil, nil)
+	if report.Overall.PullRequestsOpened != 2 || report.Overall.PullRequestsMerged != 1 {
+		t.Errorf("Overall pull requests = %d opened / %d merged, want 2 / 1", report.Overall.PullRequestsOpened, report.Overall.PullRequestsMerged)
+	}
+	if report.Overall.TimeToFirstReview.Mean != 2*time.Hour {
+		t.Errorf("Overall time to first review = %v, want %v", report.Overall.TimeToFirstReview.Mean, 2*time.Hour)
+	}
+	if report.Overall.TimeToClose.Mean != 48*time.Hour {
+		t.Errorf("Overall time to close = %v, want %v", report.Overall.TimeToClose.Mean, 48*time.Hour)
+	}
+	if len(report.Repositories) != 2 || report.Repositories[0].Key != "pingcap/tidb" {
+		t.Errorf("Repositories = %+v, want pingcap/tidb first", report.Repositories)
+	}
+	if len(report.Authors) != 2 || report.Authors[0].Key != "alice" || report.Authors[0].PullRequestsOpened != 2 {
+		t.Errorf("Authors = %+v, want alice first with 2 pull requests", report.Authors)
+	}
+
+	// In business hours, the issue closed on Wednesday midnight was open for two 8 hour days
+	hours, err := workhours.New(&config.BusinessHoursConfig{Enabled: true})
+	if err != nil {
+		t.Fatalf("workhours.New() error = %v", err)
+	}
+	report = Compute(prs, issues, nil, hours)
+	if report.Overall.TimeToClose.Mean != 16*time.Hour {
+		t.Errorf("Overall time to close in business hours = %v, want %v", report.Overall.TimeToClose.Mean, 16*time.Hour)
+	}
+}
+
+// TestLeaderboard tests the Leaderboard function
+func TestLeaderboard(t *testing.T) {
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	inside := since.Add(time.Hour)
+	before := since.Add(-time.Hour)
+
+	prs := []*models.PullRequest{
+		{
+			UserLogin: "alice",
+			CreatedAt: inside,
+			MergedAt:  &inside,
+			Reviews: []models.PullRequestReview{
+				{UserLogin: "bob", SubmittedAt: inside},
+				{UserLogin: "alice", SubmittedAt: inside},
+				{UserLogin: "carol", SubmittedAt: before},
+			},
+		},
+	}
+	issues := []*models.Issue{
+		{UserLogin: "bob", CreatedAt: before, ClosedAt: &inside},
+	}
+
+	got := Leaderboard(prs, issues, nil, since, time.Time{})
+	if len(got) != 2 {
+		t.Fatalf("Leaderboard() returned %d users, want 2", len(got))
+	}
+	if got[0].User != "alice" || got[0].PullRequestsOpened != 1 || got[0].PullRequestsMerged != 1 || got[0].ReviewsGiven != 0 {
+		t.Errorf("Leaderboard()[0] = %+v, want alice with 1 opened, 1 merged, 0 reviews", got[0])
+	}
+	if got[1].User != "bob" || got[1].ReviewsGiven != 1 || got[1].IssuesOpened != 0 || got[1].IssuesClosed != 1 {
+		t.Errorf("Leaderboard()[1] = %+v, want bob with 1 review, 0 opened, 1 closed", got[1])
+	}
+}
+
+// TestTopics tests the Topics function
+func TestTopics(t *testing.T) {
+	issues := []*TopicIssue{
+		{Repository: "org/web", Number: 7, Title: "Timeout when uploading files", Labels: []string{"Network"}},
+		{Repository: "org/api", Number: 1, Title: "Request timeout on large uploads", Labels: []string{"network"}},
+		{Repository: "org/api", Number: 2, Title: "Upload timeout behind a proxy"},
+		{Repository: "org/api", Number: 3, Title: "Dark mode for the dashboard"},
+		{Repository: "org/cli", Number: 4, Title: "Dashboard crashes on login"},
+		{Repository: "org/cli", Number: 5, Title: "Typo in README", Labels: []string{"docs"}},
+	}
+
+	topics := Topics(issues, 0)
+	if len(topics) != 3 {
+		t.Fatalf("Topics() = %d topics, want timeout, network and dashboard", len(topics))
+	}
+	if topic := topics[0]; topic.Kind != TopicKindKeyword || topic.Name != "timeout" || topic.Issues != 3 || len(topic.Repositories) != 2 {
+		t.Errorf("topics[0] = %+v, want the timeout keyword in 3 issues of 2 repositories", topic)
+	}
+	if topic := topics[1]; topic.Kind != TopicKindLabel || topic.Name != "network" || topic.Issues != 2 ||
+		len(topic.Examples) != 2 || topic.Examples[0] != "org/api#1" {
+		t.Errorf("topics[1] = %+v, want the network label in org/api#1 and org/web#7", topic)
+	}
+	if topic := topics[2]; topic.Name != "dashboard" || topic.Issues != 2 {
+		t.Errorf("topics[2] = %+v, want the dashboard keyword in 2 issues", topic)
+	}
+
+	if topics := Topics(issues, 1); len(topics) != 1 {
+		t.Errorf("Topics(limit 1) = %d topics, want 1", len(topics))
+	}
+}
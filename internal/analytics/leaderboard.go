@@ -0,0 +1,105 @@
+package analytics
+
+import (
+	"encoding/csv"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// Contribution counts the activity of a single user
+type Contribution struct {
+	User               string `json:"user"`
+	PullRequestsOpened int    `json:"pull_requests_opened"`
+	PullRequestsMerged int    `json:"pull_requests_merged"`
+	IssuesOpened       int    `json:"issues_opened"`
+	IssuesClosed       int    `json:"issues_closed"`
+	ReviewsGiven       int    `json:"reviews_given"`
+	Commits            int    `json:"commits"`
+}
+
+// Total returns the number of contributions of all kinds
+func (c *Contribution) Total() int {
+	return c.PullRequestsOpened + c.PullRequestsMerged + c.IssuesOpened + c.IssuesClosed + c.ReviewsGiven + c.Commits
+}
+
+// WriteLeaderboardCSV writes contributions as CSV, with a header row of their JSON field names
+func WriteLeaderboardCSV(w io.Writer, contributions []*Contribution) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"user", "pull_requests_opened", "pull_requests_merged", "issues_opened", "issues_closed", "reviews_given", "commits"})
+	for _, c := range contributions {
+		writer.Write([]string{
+			c.User,
+			strconv.Itoa(c.PullRequestsOpened),
+			strconv.Itoa(c.PullRequestsMerged),
+			strconv.Itoa(c.IssuesOpened),
+			strconv.Itoa(c.IssuesClosed),
+			strconv.Itoa(c.ReviewsGiven),
+			strconv.Itoa(c.Commits),
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// Leaderboard counts contributions per user whose time falls in [since, until), most active first.
+// Openings count at creation, merges and closes when they happened, and reviews when submitted.
+// Issue closes are credited to the issue author, since the closer is not synced. Commits count
+// when authored.
+func Leaderboard(prs []*models.PullRequest, issues []*models.Issue, commits []*models.Commit, since, until time.Time) []*Contribution {
+	users := make(map[string]*Contribution)
+	user := func(login string) *Contribution {
+		key := strings.ToLower(login)
+		c, ok := users[key]
+		if !ok {
+			c = &Contribution{User: key}
+			users[key] = c
+		}
+		return c
+	}
+
+	for _, pr := range prs {
+		if InRange(pr.CreatedAt, since, until) {
+			user(pr.UserLogin).PullRequestsOpened++
+		}
+		if pr.MergedAt != nil && InRange(*pr.MergedAt, since, until) {
+			user(pr.UserLogin).PullRequestsMerged++
+		}
+		for _, review := range pr.Reviews {
+			if strings.EqualFold(review.UserLogin, pr.UserLogin) || !InRange(review.SubmittedAt, since, until) {
+				continue
+			}
+			user(review.UserLogin).ReviewsGiven++
+		}
+	}
+	for _, issue := range issues {
+		if InRange(issue.CreatedAt, since, until) {
+			user(issue.UserLogin).IssuesOpened++
+		}
+		if issue.ClosedAt != nil && InRange(*issue.ClosedAt, since, until) {
+			user(issue.UserLogin).IssuesClosed++
+		}
+	}
+	for _, commit := range commits {
+		if InRange(commit.CommittedAt, since, until) {
+			user(commit.Author).Commits++
+		}
+	}
+
+	contributions := make([]*Contribution, 0, len(users))
+	for _, c := range users {
+		contributions = append(contributions, c)
+	}
+	sort.Slice(contributions, func(i, j int) bool {
+		ti, tj := contributions[i].Total(), contributions[j].Total()
+		if ti != tj {
+			return ti > tj
+		}
+		return contributions[i].User < contributions[j].User
+	})
+	return contributions
+}
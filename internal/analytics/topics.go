@@ -0,0 +1,172 @@
+package analytics
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/siddontang/github-repos-management/internal/similarity"
+)
+
+// Topic kinds
+const (
+	TopicKindLabel   = "label"
+	TopicKindKeyword = "keyword"
+)
+
+// maxTopicExamples bounds how many issues a topic lists as examples
+const maxTopicExamples = 5
+
+// TopicIssue is an open issue to find topics in
+type TopicIssue struct {
+	Repository string
+	Number     int
+	Title      string
+	Labels     []string
+}
+
+// Topic is a theme shared by open issues, a label or a title keyword
+type Topic struct {
+	Kind         string   `json:"kind"` // TopicKindLabel or TopicKindKeyword
+	Name         string   `json:"name"`
+	Issues       int      `json:"issues"`
+	Repositories []string `json:"repositories"` // Repositories with issues of the topic, by name
+	Examples     []string `json:"examples"`     // The first issues of the topic, such as "org/api#12"
+}
+
+// Topics groups open issues into themes shared by at least two of them, most issues first, and
+// returns at most limit of them. Each label is a theme. Title keywords are clustered greedily: the
+// keyword shared by most issues not yet in a keyword theme becomes the next one, so an issue counts
+// towards a single keyword.
+func Topics(issues []*TopicIssue, limit int) []*Topic {
+	sorted := make([]*TopicIssue, len(issues))
+	copy(sorted, issues)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Repository != sorted[j].Repository {
+			return sorted[i].Repository < sorted[j].Repository
+		}
+		return sorted[i].Number < sorted[j].Number
+	})
+
+	var topics []*Topic
+	labels := make(map[string][]*TopicIssue)
+	for _, issue := range sorted {
+		seen := make(map[string]bool)
+		for _, label := range issue.Labels {
+			key := strings.ToLower(label)
+			if !seen[key] {
+				seen[key] = true
+				labels[key] = append(labels[key], issue)
+			}
+		}
+	}
+	for label, members := range labels {
+		if len(members) >= 2 {
+			topics = append(topics, newTopic(TopicKindLabel, label, members))
+		}
+	}
+
+	keywords := make([]map[string]bool, len(sorted))
+	for i, issue := range sorted {
+		keywords[i] = titleKeywords(issue.Title)
+	}
+	clustered := make([]bool, len(sorted))
+	for {
+		counts := make(map[string]int)
+		for i, words := range keywords {
+			if clustered[i] {
+				continue
+			}
+			for word := range words {
+				counts[word]++
+			}
+		}
+		best := ""
+		for word, count := range counts {
+			if count > counts[best] || (count == counts[best] && word < best) {
+				best = word
+			}
+		}
+		if counts[best] < 2 {
+			break
+		}
+		var members []*TopicIssue
+		for i, words := range keywords {
+			if !clustered[i] && words[best] {
+				clustered[i] = true
+				members = append(members, sorted[i])
+			}
+		}
+		topics = append(topics, newTopic(TopicKindKeyword, best, members))
+	}
+
+	sort.Slice(topics, func(i, j int) bool {
+		a, b := topics[i], topics[j]
+		if a.Issues != b.Issues {
+			return a.Issues > b.Issues
+		}
+		if len(a.Repositories) != len(b.Repositories) {
+			return len(a.Repositories) > len(b.Repositories)
+		}
+		if a.Kind != b.Kind {
+			return a.Kind == TopicKindLabel
+		}
+		return a.Name < b.Name
+	})
+	if limit > 0 && len(topics) > limit {
+		topics = topics[:limit]
+	}
+	return topics
+}
+
+// newTopic summarizes the issues of a topic
+func newTopic(kind, name string, members []*TopicIssue) *Topic {
+	topic := &Topic{Kind: kind, Name: name, Issues: len(members), Repositories: []string{}, Examples: []string{}}
+	repos := make(map[string]bool)
+	for _, issue := range members {
+		if !repos[issue.Repository] {
+			repos[issue.Repository] = true
+			topic.Repositories = append(topic.Repositories, issue.Repository)
+		}
+		if len(topic.Examples) < maxTopicExamples {
+			topic.Examples = append(topic.Examples, fmt.Sprintf("%s#%d", issue.Repository, issue.Number))
+		}
+	}
+	return topic
+}
+
+// titleKeywords returns the words of a title worth grouping issues by, leaving out common words,
+// numbers and words shorter than three letters
+func titleKeywords(title string) map[string]bool {
+	words := make(map[string]bool)
+	for _, word := range similarity.Words(title) {
+		if utf8.RuneCountInString(word) < 3 || stopWords[word] || isNumber(word) {
+			continue
+		}
+		words[word] = true
+	}
+	return words
+}
+
+// isNumber reports whether a word is made of digits only
+func isNumber(word string) bool {
+	for _, r := range word {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// stopWords are English words too common in issue titles to make a theme
+var stopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "when": true, "not": true, "from": true,
+	"into": true, "are": true, "was": true, "can": true, "cannot": true, "does": true, "doesn": true,
+	"don": true, "should": true, "after": true, "before": true, "while": true, "this": true,
+	"that": true, "its": true, "has": true, "have": true, "all": true, "any": true, "use": true,
+	"using": true, "add": true, "support": true, "issue": true, "bug": true, "error": true,
+	"fix": true, "feature": true, "request": true, "how": true, "why": true, "what": true,
+	"able": true, "unable": true, "get": true, "set": true, "new": true, "make": true, "via": true,
+	"out": true, "but": true, "more": true, "some": true, "than": true, "then": true, "there": true,
+}
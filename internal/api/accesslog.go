@@ -0,0 +1,121 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	mathrand "math/rand/v2"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/service"
+)
+
+// requestIDHeader carries the ID of a request, kept from the caller when it sends one
+const requestIDHeader = "X-Request-Id"
+
+// maxRequestIDLength is the length beyond which a caller's request ID is replaced
+const maxRequestIDLength = 128
+
+// SetAccessLog writes a line per request to w, with the level and format of the logging
+// configuration, sampling the successful requests of noisy routes
+func (s *Server) SetAccessLog(cfg config.LoggingConfig, w io.Writer) error {
+	if cfg.Access.Disabled {
+		s.access = nil
+		return nil
+	}
+
+	var level slog.Level
+	switch strings.ToLower(cfg.Level) {
+	case "", "info":
+		level = slog.LevelInfo
+	case "debug":
+		level = slog.LevelDebug
+	case "warn", "warning":
+		level = slog.LevelWarn
+	case "error":
+		level = slog.LevelError
+	default:
+		return fmt.Errorf("unknown log level %q, expected debug, info, warn or error", cfg.Level)
+	}
+	for route, ratio := range cfg.Access.Sample {
+		if ratio < 0 || ratio > 1 {
+			return fmt.Errorf("access log sample of %q must be between 0 and 1, got %v", route, ratio)
+		}
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+	switch strings.ToLower(cfg.Format) {
+	case "", "text":
+		s.access = slog.New(slog.NewTextHandler(w, opts))
+	case "json":
+		s.access = slog.New(slog.NewJSONHandler(w, opts))
+	default:
+		return fmt.Errorf("unknown log format %q, expected text or json", cfg.Format)
+	}
+	s.accessSample = cfg.Access.Sample
+	return nil
+}
+
+// logRequest writes the access log line of a request served by route: at the error level for
+// server errors, the warn level for client errors and the info level otherwise
+func (s *Server) logRequest(ctx context.Context, r *http.Request, route, requestID string, status int, latency time.Duration) {
+	if s.access == nil {
+		return
+	}
+	level := slog.LevelInfo
+	switch {
+	case status >= http.StatusInternalServerError:
+		level = slog.LevelError
+	case status >= http.StatusBadRequest:
+		level = slog.LevelWarn
+	default:
+		if ratio, ok := s.accessSample[route]; ok && mathrand.Float64() >= ratio {
+			return
+		}
+	}
+	if !s.access.Enabled(ctx, level) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("method", r.Method),
+		slog.String("path", r.URL.Path),
+		slog.Int("status", status),
+		slog.Duration("latency", latency),
+		slog.String("request_id", requestID),
+	}
+	if key := requestCredential(r); key != "" {
+		attrs = append(attrs, slog.String("api_key", service.MaskSecret(key)))
+	}
+	s.access.LogAttrs(ctx, level, "request", attrs...)
+}
+
+// requestCredential returns the session token, feed token or admin API key a request was made with
+func requestCredential(r *http.Request) string {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok && token != "" {
+		return token
+	}
+	if key := r.Header.Get("X-Admin-Key"); key != "" {
+		return key
+	}
+	return r.URL.Query().Get("token")
+}
+
+// requestID returns the caller's request ID, or a new random one when it sent none or an
+// unusable one
+func requestID(r *http.Request) string {
+	if id := r.Header.Get(requestIDHeader); id != "" && len(id) <= maxRequestIDLength && !strings.ContainsFunc(id, func(c rune) bool {
+		return c <= ' ' || c > '~'
+	}) {
+		return id
+	}
+	var id [8]byte
+	rand.Read(id[:])
+	return hex.EncodeToString(id[:])
+}
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/db/file"
+	"github.com/siddontang/github-repos-management/internal/service"
+)
+
+func TestAccessLog(t *testing.T) {
+	db, err := file.NewDB("")
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	cfg := &config.Config{}
+	svc, err := service.NewServiceWithOptions(cfg, service.Options{DB: db, Logger: log.New(io.Discard, "", 0)})
+	if err != nil {
+		t.Fatalf("NewServiceWithOptions() error = %v", err)
+	}
+	handler := New(svc, cfg.Server, log.New(io.Discard, "", 0))
+
+	var out bytes.Buffer
+	err = handler.SetAccessLog(config.LoggingConfig{
+		Format: "json",
+		Access: config.AccessLogConfig{Sample: map[string]float64{"GET /api/v1/repositories": 0}},
+	}, &out)
+	if err != nil {
+		t.Fatalf("SetAccessLog() error = %v", err)
+	}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	// Successful requests to a sampled route are dropped
+	if status, _ := get(t, server.URL+"/api/v1/repositories"); status != http.StatusOK {
+		t.Fatalf("GET /api/v1/repositories status = %d", status)
+	}
+	if out.Len() != 0 {
+		t.Errorf("access log = %s, want the sampled request dropped", out.String())
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/api/v1/repositories/org/missing?token=ignored", nil)
+	req.Header.Set("X-Request-Id", "req-42")
+	req.Header.Set("X-Admin-Key", "admin-s3cr3t")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.Header.Get("X-Request-Id") != "req-42" {
+		t.Errorf("X-Request-Id = %q, want the caller's", resp.Header.Get("X-Request-Id"))
+	}
+
+	var line map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &line); err != nil {
+		t.Fatalf("access log = %s: %v", out.String(), err)
+	}
+	if line["level"] != "WARN" || line["method"] != "GET" || line["path"] != "/api/v1/repositories/org/missing" ||
+		line["status"] != float64(http.StatusNotFound) || line["request_id"] != "req-42" || line["api_key"] != "…cr3t" {
+		t.Errorf("access log line = %v", line)
+	}
+	if _, ok := line["latency"]; !ok || strings.Contains(out.String(), "admin-s3cr3t") {
+		t.Errorf("access log line = %v, want a latency and no full key", line)
+	}
+
+	// Below the configured level nothing is logged
+	out.Reset()
+	if err := handler.SetAccessLog(config.LoggingConfig{Level: "error"}, &out); err != nil {
+		t.Fatalf("SetAccessLog() error = %v", err)
+	}
+	get(t, server.URL+"/api/v1/repositories/org/missing")
+	if out.Len() != 0 {
+		t.Errorf("access log = %s, want nothing below the error level", out.String())
+	}
+
+	if err := handler.SetAccessLog(config.LoggingConfig{Level: "verbose"}, &out); err == nil {
+		t.Error("SetAccessLog() with an unknown level should fail")
+	}
+}
@@ -0,0 +1,353 @@
+// Package api serves the service over HTTP: a JSON API under /api/v1, badges under
+// /badge, Atom feeds under /feeds, an iCalendar feed at /calendar.ics and the
+// embedded web dashboard at /.
+package api
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"log"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/models"
+	"github.com/siddontang/github-repos-management/internal/service"
+	"github.com/siddontang/github-repos-management/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DefaultAddr is the address the server listens on by default
+const DefaultAddr = "127.0.0.1:8080"
+
+// Default and maximum page sizes of list endpoints
+const (
+	defaultPerPage = 30
+	maxPerPage     = 100
+)
+
+// Default and maximum time a refresh waits for its job with wait=true
+const (
+	defaultRefreshWait = 30 * time.Second
+	maxRefreshWait     = 5 * time.Minute
+)
+
+//go:embed dashboard
+var dashboardFiles embed.FS
+
+// Server handles HTTP requests with a service
+type Server struct {
+	service *service.Service
+	config  config.ServerConfig
+	logger  *log.Logger
+	mux     *http.ServeMux
+
+	access       *slog.Logger       // Nil until SetAccessLog is called
+	accessSample map[string]float64 // Route -> fraction of successful requests logged
+}
+
+// New creates a server for a service
+func New(svc *service.Service, cfg config.ServerConfig, logger *log.Logger) *Server {
+	if logger == nil {
+		logger = log.Default()
+	}
+	s := &Server{service: svc, config: cfg, logger: logger, mux: http.NewServeMux()}
+	s.routes()
+	return s
+}
+
+// routes registers the handlers
+func (s *Server) routes() {
+	s.mux.HandleFunc("GET /api/v1/health", s.handleHealth)
+	s.mux.HandleFunc("GET /api/v1/status", s.authenticated(s.handleStatus))
+	s.mux.HandleFunc("GET /api/v1/repositories", s.authenticated(s.handleListRepositories))
+	s.mux.HandleFunc("POST /api/v1/repositories/batch", s.authenticated(s.handleAddRepositories))
+	s.mux.HandleFunc("GET /api/v1/repositories/{owner}/{name}", s.authenticated(s.handleGetRepository))
+	s.mux.HandleFunc("PATCH /api/v1/repositories/{owner}/{name}", s.authenticated(s.handleUpdateRepository))
+	s.mux.HandleFunc("POST /api/v1/repositories/{owner}/{name}/refresh", s.authenticated(s.handleRefreshRepository))
+	s.mux.HandleFunc("POST /api/v1/repositories/{owner}/{name}/pause", s.authenticated(s.handlePauseRepository))
+	s.mux.HandleFunc("POST /api/v1/repositories/{owner}/{name}/resume", s.authenticated(s.handleResumeRepository))
+	s.mux.HandleFunc("GET /api/v1/repositories/{owner}/{name}/trends", s.authenticated(s.handleRepositoryTrends))
+	s.mux.HandleFunc("GET /api/v1/repositories/{owner}/{name}/alerts", s.authenticated(s.handleRepositoryAlerts))
+	s.mux.HandleFunc("GET /api/v1/repositories/{owner}/{name}/commits", s.authenticated(s.handleListCommits))
+	s.mux.HandleFunc("GET /api/v1/repositories/{owner}/{name}/duplicates", s.authenticated(s.handleListDuplicates))
+	s.mux.HandleFunc("GET /api/v1/repositories/{owner}/{name}/release-notes", s.authenticated(s.handleReleaseNotes))
+	s.mux.HandleFunc("GET /api/v1/repositories/{owner}/{name}/pulls/{number}/diff", s.authenticated(s.handlePullRequestDiff))
+	s.mux.HandleFunc("GET /api/v1/repositories/{owner}/{name}/pulls/{number}", s.authenticated(s.handleGetPullRequest))
+	s.mux.HandleFunc("PATCH /api/v1/repositories/{owner}/{name}/pulls/{number}", s.authenticated(s.handleUpdatePullRequest))
+	s.mux.HandleFunc("GET /api/v1/repositories/{owner}/{name}/issues/{number}", s.authenticated(s.handleGetIssue))
+	s.mux.HandleFunc("PATCH /api/v1/repositories/{owner}/{name}/issues/{number}", s.authenticated(s.handleUpdateIssue))
+	s.mux.HandleFunc("GET /api/v1/repositories/{owner}/{name}/issues/{number}/tree", s.authenticated(s.handleIssueTree))
+	s.mux.HandleFunc("GET /api/v1/alerts", s.authenticated(s.handleListAlerts))
+	s.mux.HandleFunc("GET /api/v1/compliance", s.authenticated(s.handleCompliance))
+	s.mux.HandleFunc("GET /api/v1/labels", s.authenticated(s.handleListLabels))
+	s.mux.HandleFunc("GET /api/v1/hooks", s.authenticated(s.handleListWebhooks))
+	s.mux.HandleFunc("POST /api/v1/hooks", s.authenticated(s.handleAddWebhook))
+	s.mux.HandleFunc("DELETE /api/v1/hooks/{id}", s.authenticated(s.handleDeleteWebhook))
+	s.mux.HandleFunc("GET /api/v1/hooks/{id}/deliveries", s.authenticated(s.handleListWebhookDeliveries))
+	s.mux.HandleFunc("GET /api/v1/subscriptions", s.authenticated(s.handleListSubscriptions))
+	s.mux.HandleFunc("POST /api/v1/subscriptions", s.authenticated(s.handleAddSubscription))
+	s.mux.HandleFunc("DELETE /api/v1/subscriptions/{id}", s.authenticated(s.handleDeleteSubscription))
+	s.mux.HandleFunc("GET /api/v1/triage-rules", s.authenticated(s.handleListTriageRules))
+	s.mux.HandleFunc("POST /api/v1/triage-rules", s.authenticated(s.handleAddTriageRules))
+	s.mux.HandleFunc("DELETE /api/v1/triage-rules/{id}", s.authenticated(s.handleDeleteTriageRule))
+	s.mux.HandleFunc("GET /api/v1/triage-rules/{id}/executions", s.authenticated(s.handleListTriageExecutions))
+	s.mux.HandleFunc("GET /api/v1/projects", s.authenticated(s.handleListProjects))
+	s.mux.HandleFunc("GET /api/v1/projects/{owner}/{number}", s.authenticated(s.handleGetProject))
+	s.mux.HandleFunc("GET /api/v1/pulls", s.authenticated(s.handleListPullRequests))
+	s.mux.HandleFunc("GET /api/v1/issues", s.authenticated(s.handleListIssues))
+	s.mux.HandleFunc("GET /api/v1/activity", s.authenticated(s.handleListActivity))
+	s.mux.HandleFunc("GET /api/v1/analytics", s.authenticated(s.handleAnalytics))
+	s.mux.HandleFunc("GET /api/v1/analytics/topics", s.authenticated(s.handleTopics))
+	s.mux.HandleFunc("GET /api/v1/leaderboard", s.authenticated(s.handleLeaderboard))
+	s.mux.HandleFunc("GET /api/v1/review-queue", s.authenticated(s.handleReviewQueue))
+	s.mux.HandleFunc("GET /api/v1/sla", s.authenticated(s.handleSLAReport))
+	s.mux.HandleFunc("GET /api/v1/diff", s.authenticated(s.handleDiff))
+	s.mux.HandleFunc("GET /api/v1/discussions", s.authenticated(s.handleListDiscussions))
+	s.mux.HandleFunc("GET /api/v1/items", s.authenticated(s.handleListItems))
+	s.mux.HandleFunc("POST /api/v1/bulk", s.authenticated(s.handleBulk))
+	s.mux.HandleFunc("GET /api/v1/jobs", s.authenticated(s.handleListJobs))
+	s.mux.HandleFunc("GET /api/v1/jobs/{id}", s.authenticated(s.handleGetJob))
+	s.mux.HandleFunc("POST /api/v1/jobs/{id}/cancel", s.authenticated(s.handleCancelJob))
+	s.mux.HandleFunc("GET /api/v1/audit", s.authenticated(s.handleListAudit))
+	s.mux.HandleFunc("GET /api/v1/changes", s.authenticated(s.handleListChanges))
+	s.mux.HandleFunc("GET /api/v1/admin/stats", s.authenticated(s.handleAdminStats))
+	s.mux.HandleFunc("POST /api/v1/admin/compact", s.authenticated(s.handleAdminCompact))
+	s.mux.HandleFunc("DELETE /api/v1/admin/repositories/{owner}/{name}/data", s.authenticated(s.handleAdminClear))
+	s.mux.HandleFunc("GET /api/v1/links/jira", s.authenticated(s.handleListJiraLinks))
+	s.mux.HandleFunc("GET /api/v1/links/repos", s.authenticated(s.handleLinkGraph))
+	s.mux.HandleFunc("GET /api/v1/query", s.authenticated(s.handleQuery))
+	s.mux.HandleFunc("GET /api/v1/discover", s.authenticated(s.handleDiscover))
+	s.mux.HandleFunc("GET /api/v1/workspaces", s.authenticated(s.handleListWorkspaces))
+	s.mux.HandleFunc("GET /api/v1/workspaces/{workspace}", s.authenticated(s.handleGetWorkspace))
+	for _, method := range []string{http.MethodGet, http.MethodPost, http.MethodPatch, http.MethodDelete} {
+		s.mux.HandleFunc(method+" /api/v1/workspaces/{workspace}/{rest...}", s.authenticated(s.handleWorkspace))
+	}
+	s.mux.HandleFunc("POST /api/v1/integrations/slack/command", s.handleSlackCommand)
+	s.mux.HandleFunc("GET /badge/{owner}/{name}/{kind}", s.handleBadge)
+	s.mux.HandleFunc("GET /feeds/all.atom", queryToken(s.authenticated(s.handleFeed)))
+	s.mux.HandleFunc("GET /feeds/{owner}/{file}", queryToken(s.authenticated(s.handleRepositoryFeed)))
+	s.mux.HandleFunc("GET /calendar.ics", queryToken(s.authenticated(s.handleCalendar)))
+
+	dashboard, _ := fs.Sub(dashboardFiles, "dashboard")
+	s.mux.Handle("GET /", http.FileServer(http.FS(dashboard)))
+}
+
+// ServeHTTP implements http.Handler. Each request gets an ID, returned in the X-Request-Id
+// header, and a line in the access log. With tracing configured, it is also a server span named
+// after its route, continuing the caller's trace when it sends a traceparent header.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	_, route := s.mux.Handler(r)
+	id := requestID(r)
+	w.Header().Set(requestIDHeader, id)
+
+	ctx := r.Context()
+	if tracer := s.service.Tracer(); tracer != nil {
+		spanName := route
+		if spanName == "" {
+			spanName = r.Method
+		}
+		var span trace.Span
+		ctx, span = tracer.Start(tracing.Extract(ctx, r.Header), spanName, trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(attribute.String("http.request.method", r.Method), attribute.String("url.path", r.URL.Path),
+				attribute.String("http.request.id", id)))
+		defer span.End()
+	}
+
+	recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	s.mux.ServeHTTP(recorder, r.WithContext(ctx))
+
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(attribute.Int("http.response.status_code", recorder.status))
+	if recorder.status >= http.StatusInternalServerError {
+		tracing.RecordError(span, errors.New(http.StatusText(recorder.status)))
+	}
+	s.logRequest(ctx, r, route, id, recorder.status, time.Since(start))
+}
+
+// statusRecorder remembers the status written to a response
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader records the status and writes it
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Unwrap returns the underlying writer, for http.ResponseController
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// ListenAndServe serves on addr until ctx is done, then shuts down gracefully
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	if addr == "" {
+		addr = DefaultAddr
+	}
+	server := &http.Server{Addr: addr, Handler: s, ReadHeaderTimeout: 10 * time.Second}
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- server.ListenAndServe() }()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return server.Shutdown(shutdownCtx)
+}
+
+// handleWorkspace serves the API restricted to a workspace: /api/v1/workspaces/{workspace}/pulls
+// is /api/v1/pulls over the repositories of the workspace, with any method the route takes. The
+// workspace is looked up once the request is authenticated, so that callers without a session
+// can't tell which workspaces exist.
+func (s *Server) handleWorkspace(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("workspace")
+	if _, err := s.service.GetWorkspace(r.Context(), id); err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	scoped := r.Clone(service.WithWorkspace(r.Context(), id))
+	scoped.URL.Path = "/api/v1/" + r.PathValue("rest")
+	scoped.URL.RawPath = ""
+	s.mux.ServeHTTP(w, scoped)
+}
+
+// authenticated attributes a request to the user of its session token, passed as a bearer token.
+// Requests without a token are let through unless the configuration requires sessions. Workspace
+// tokens restrict the request to their workspace.
+func (s *Server) authenticated(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		ctx, _, err := s.service.Authenticate(r.Context(), token)
+		if err != nil {
+			s.writeError(w, err)
+			return
+		}
+		next(w, r.WithContext(ctx))
+	}
+}
+
+// listResponse is the body of list endpoints
+type listResponse struct {
+	Data       interface{}        `json:"data"`
+	Pagination *models.Pagination `json:"pagination"`
+}
+
+// envelopeHeader marks responses wrapped in an envelope
+const envelopeHeader = "X-Envelope"
+
+// envelope is the body of every JSON response when server.envelope is set: the response of the
+// endpoint in data, or the error of failed requests. Lists have their items in data next to
+// their pagination, which only lists have.
+type envelope struct {
+	Data       interface{}    `json:"data"`
+	Pagination interface{}    `json:"pagination,omitempty"` // A *models.Pagination, possibly nil
+	Error      *envelopeError `json:"error"`
+}
+
+// envelopeError is the error of a failed request in an envelope
+type envelopeError struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// writeJSON writes a JSON response, wrapped in an envelope when server.envelope is set
+func (s *Server) writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	if s.config.Envelope {
+		w.Header().Set(envelopeHeader, "1")
+		switch b := body.(type) {
+		case listResponse:
+			body = envelope{Data: b.Data, Pagination: b.Pagination}
+		case errorResponse:
+			body = envelope{Error: &envelopeError{Code: b.Code, Message: b.Error, RequestID: b.RequestID}}
+		default:
+			body = envelope{Data: body}
+		}
+	}
+	s.encodeJSON(w, status, body)
+}
+
+// encodeJSON writes a JSON response as is
+func (s *Server) encodeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		s.logger.Printf("Error writing response: %v", err)
+	}
+}
+
+// errInvalidParameter is returned for malformed query parameters
+var errInvalidParameter = errors.New("invalid parameter")
+
+// pagination reads the page and per_page query parameters
+func pagination(r *http.Request) (page, perPage int, err error) {
+	page, perPage = 1, defaultPerPage
+	if value := r.URL.Query().Get("page"); value != "" {
+		if page, err = strconv.Atoi(value); err != nil || page < 1 {
+			return 0, 0, errors.Join(errInvalidParameter, errors.New("page must be a positive number"))
+		}
+	}
+	if value := r.URL.Query().Get("per_page"); value != "" {
+		if perPage, err = strconv.Atoi(value); err != nil || perPage < 1 {
+			return 0, 0, errors.Join(errInvalidParameter, errors.New("per_page must be a positive number"))
+		}
+	}
+	if perPage > maxPerPage {
+		perPage = maxPerPage
+	}
+	return page, perPage, nil
+}
+
+// parseWindow parses a time window given in days, such as 90d, or as a duration, such as 12h
+func parseWindow(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(value)
+}
+
+// repoTagParameter reads the repo_tag query parameter, or tag, which the repository list, feed and
+// calendar routes took before
+func repoTagParameter(r *http.Request) string {
+	if tag := r.URL.Query().Get("repo_tag"); tag != "" {
+		return tag
+	}
+	return r.URL.Query().Get("tag")
+}
+
+// timeParameter reads a YYYY-MM-DD or RFC3339 query parameter
+func timeParameter(r *http.Request, name string) (time.Time, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, errors.Join(errInvalidParameter, errors.New(name+" must be YYYY-MM-DD or RFC3339"))
+	}
+	return t, nil
+}
@@ -0,0 +1,763 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/db/file"
+	"github.com/siddontang/github-repos-management/internal/models"
+	"github.com/siddontang/github-repos-management/internal/service"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// newTestServer serves a service tracking org/repo, returning its database to add more data
+func newTestServer(t *testing.T, cfg *config.Config) (*httptest.Server, *file.DB) {
+	t.Helper()
+	db, err := file.NewDB("")
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	if err := db.AddRepository(context.Background(), &models.Repository{Owner: "org", Name: "repo", FullName: "org/repo"}); err != nil {
+		t.Fatalf("AddRepository() error = %v", err)
+	}
+	svc, err := service.NewServiceWithOptions(cfg, service.Options{DB: db, Logger: log.New(io.Discard, "", 0)})
+	if err != nil {
+		t.Fatalf("NewServiceWithOptions() error = %v", err)
+	}
+
+	server := httptest.NewServer(New(svc, cfg.Server, log.New(io.Discard, "", 0)))
+	t.Cleanup(server.Close)
+	return server, db
+}
+
+func get(t *testing.T, url string) (int, string) {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s error = %v", url, err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(body)
+}
+
+func TestRepositories(t *testing.T) {
+	server, _ := newTestServer(t, &config.Config{})
+
+	status, body := get(t, server.URL+"/api/v1/repositories")
+	if status != http.StatusOK {
+		t.Fatalf("list status = %d, body %s", status, body)
+	}
+	var list struct {
+		Data       []*models.Repository `json:"data"`
+		Pagination *models.Pagination   `json:"pagination"`
+	}
+	if err := json.Unmarshal([]byte(body), &list); err != nil {
+		t.Fatalf("list body %s: %v", body, err)
+	}
+	if len(list.Data) != 1 || list.Data[0].FullName != "org/repo" || list.Pagination.Total != 1 {
+		t.Errorf("list = %s, want org/repo", body)
+	}
+
+	if status, body := get(t, server.URL+"/api/v1/repositories/org/missing"); status != http.StatusNotFound {
+		t.Errorf("missing repository status = %d, body %s", status, body)
+	}
+	if status, body := get(t, server.URL+"/api/v1/pulls?per_page=0"); status != http.StatusBadRequest {
+		t.Errorf("invalid per_page status = %d, body %s", status, body)
+	}
+}
+
+func TestRepoTagParameter(t *testing.T) {
+	server, db := newTestServer(t, &config.Config{})
+	ctx := context.Background()
+	if err := db.AddRepository(ctx, &models.Repository{Owner: "org", Name: "tagged", FullName: "org/tagged", Tags: []string{"team-db"}}); err != nil {
+		t.Fatalf("AddRepository() error = %v", err)
+	}
+	for _, repo := range []string{"org/repo", "org/tagged"} {
+		if err := db.AddPullRequest(ctx, &models.PullRequest{RepositoryFullName: repo, Number: 1, Title: "Add tags", State: "open"}); err != nil {
+			t.Fatalf("AddPullRequest() error = %v", err)
+		}
+		if err := db.ReplaceMilestones(ctx, repo, []*models.Milestone{{Number: 1, Title: "v1.0", State: "open", DueOn: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)}}); err != nil {
+			t.Fatalf("ReplaceMilestones() error = %v", err)
+		}
+	}
+
+	// repo_tag, and tag as its older name, filter the repositories, feed and calendar alike
+	for _, name := range []string{"repo_tag", "tag"} {
+		var list struct {
+			Data []*models.Repository `json:"data"`
+		}
+		if _, body := get(t, server.URL+"/api/v1/repositories?"+name+"=team-db"); json.Unmarshal([]byte(body), &list) != nil || len(list.Data) != 1 || list.Data[0].FullName != "org/tagged" {
+			t.Errorf("repositories with %s = %s, want org/tagged", name, body)
+		}
+		var feed atomFeed
+		if _, body := get(t, server.URL+"/feeds/all.atom?"+name+"=team-db"); xml.Unmarshal([]byte(body), &feed) != nil || len(feed.Entries) != 1 || !strings.HasPrefix(feed.Entries[0].Title, "org/tagged ") {
+			t.Errorf("feed with %s = %s, want the org/tagged pull request", name, body)
+		}
+		if _, body := get(t, server.URL+"/calendar.ics?"+name+"=team-db"); !strings.Contains(body, "org/tagged milestone") || strings.Contains(body, "org/repo milestone") {
+			t.Errorf("calendar with %s = %s, want the org/tagged milestone", name, body)
+		}
+	}
+}
+
+func TestItems(t *testing.T) {
+	server, db := newTestServer(t, &config.Config{})
+	if err := db.AddPullRequest(context.Background(), &models.PullRequest{RepositoryFullName: "org/repo", Number: 1, State: "open"}); err != nil {
+		t.Fatalf("AddPullRequest() error = %v", err)
+	}
+	if err := db.AddIssue(context.Background(), &models.Issue{RepositoryFullName: "org/repo", Number: 2, State: "closed"}); err != nil {
+		t.Fatalf("AddIssue() error = %v", err)
+	}
+
+	status, body := get(t, server.URL+"/api/v1/items?state=all&repo=org/repo")
+	if status != http.StatusOK {
+		t.Fatalf("items status = %d, body %s", status, body)
+	}
+	var list struct {
+		Data []*models.Item `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(body), &list); err != nil {
+		t.Fatalf("items body %s: %v", body, err)
+	}
+	if len(list.Data) != 2 {
+		t.Errorf("items = %s, want pull request 1 and issue 2", body)
+	}
+
+	if status, body := get(t, server.URL+"/api/v1/items?type=discussion"); status != http.StatusBadRequest {
+		t.Errorf("invalid type status = %d, body %s", status, body)
+	}
+}
+
+func TestWriteItems(t *testing.T) {
+	server, db := newTestServer(t, &config.Config{GitHub: config.GitHubConfig{Offline: true}})
+	if err := db.AddPullRequest(context.Background(), &models.PullRequest{RepositoryFullName: "org/repo", Number: 1, State: "OPEN", Assignees: []string{"bob"}}); err != nil {
+		t.Fatalf("AddPullRequest() error = %v", err)
+	}
+
+	patch := func(path, body string) int {
+		req, _ := http.NewRequest(http.MethodPatch, server.URL+path, strings.NewReader(body))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("PATCH %s error = %v", path, err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	// GitHub can't be reached offline, so the stored assignees are restored
+	if status := patch("/api/v1/repositories/org/repo/pulls/1", `{"add_assignees":["alice"]}`); status != http.StatusServiceUnavailable {
+		t.Errorf("offline update status = %d, want 503", status)
+	}
+	if pr, _ := db.GetPullRequest(context.Background(), "org/repo", 1); len(pr.Assignees) != 1 || pr.Assignees[0] != "bob" {
+		t.Errorf("assignees after a failed update = %v, want [bob]", pr.Assignees)
+	}
+	if status := patch("/api/v1/repositories/org/repo/issues/1", `{"add_assignees":["alice"]}`); status != http.StatusNotFound {
+		t.Errorf("missing issue status = %d, want 404", status)
+	}
+	if status := patch("/api/v1/repositories/org/repo/pulls/1", `{}`); status != http.StatusBadRequest {
+		t.Errorf("empty update status = %d, want 400", status)
+	}
+
+	resp, err := http.Post(server.URL+"/api/v1/bulk", "application/json", strings.NewReader(`{"action":"close","filter":{"repo":"org/repo"},"dry_run":true}`))
+	if err != nil {
+		t.Fatalf("POST /api/v1/bulk error = %v", err)
+	}
+	var report models.BulkReport
+	json.NewDecoder(resp.Body).Decode(&report)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || report.Matched != 1 || report.Results[0].Status != models.BulkItemPlanned {
+		t.Errorf("bulk dry run = %d %+v, want pull request 1 planned", resp.StatusCode, report)
+	}
+	resp, err = http.Post(server.URL+"/api/v1/bulk", "application/json", strings.NewReader(`{"action":"reopen"}`))
+	if err != nil {
+		t.Fatalf("POST /api/v1/bulk error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("invalid bulk action status = %d, want 400", resp.StatusCode)
+	}
+	if status := send(t, http.MethodPost, server.URL+"/api/v1/bulk", `{"action":"close"}`, nil); status != http.StatusBadRequest {
+		t.Errorf("bulk action without a repository scope status = %d, want 400", status)
+	}
+	if status := send(t, http.MethodPost, server.URL+"/api/v1/bulk", `{"action":"comment","comment":"`+strings.Repeat("x", bulkMaxBody)+`"}`, nil); status != http.StatusBadRequest {
+		t.Errorf("oversized bulk request status = %d, want 400", status)
+	}
+}
+
+// adminRequest sends an admin request with the X-Admin-Key header, decoding the response into result
+func adminRequest(t *testing.T, method, url, key string, result any) int {
+	t.Helper()
+	req, _ := http.NewRequest(method, url, nil)
+	req.Header.Set("X-Admin-Key", key)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s error = %v", method, url, err)
+	}
+	defer resp.Body.Close()
+	if result != nil {
+		json.NewDecoder(resp.Body).Decode(result)
+	}
+	return resp.StatusCode
+}
+
+func TestAdminCompact(t *testing.T) {
+	server, db := newTestServer(t, &config.Config{Admin: config.AdminConfig{APIKey: "s3cr3t"}})
+	if err := db.AddIssue(context.Background(), &models.Issue{RepositoryFullName: "org/repo", Number: 1, State: "OPEN", Tombstoned: true}); err != nil {
+		t.Fatalf("AddIssue() error = %v", err)
+	}
+
+	if status := adminRequest(t, http.MethodPost, server.URL+"/api/v1/admin/compact", "wrong", nil); status != http.StatusForbidden {
+		t.Errorf("wrong key status = %d, want 403", status)
+	}
+	result := &models.CompactionResult{}
+	if status := adminRequest(t, http.MethodPost, server.URL+"/api/v1/admin/compact", "s3cr3t", result); status != http.StatusOK || result.RemovedTombstones != 1 {
+		t.Errorf("compact = %d %+v, want the tombstoned issue removed", status, result)
+	}
+}
+
+func TestAdminRoutes(t *testing.T) {
+	server, db := newTestServer(t, &config.Config{Admin: config.AdminConfig{APIKey: "s3cr3t"}})
+	ctx := context.Background()
+	if err := db.AddRepository(ctx, &models.Repository{Owner: "org", Name: "repo", FullName: "org/repo"}); err != nil {
+		t.Fatalf("AddRepository() error = %v", err)
+	}
+	if err := db.AddIssue(ctx, &models.Issue{RepositoryFullName: "org/repo", Number: 1, State: "OPEN"}); err != nil {
+		t.Fatalf("AddIssue() error = %v", err)
+	}
+
+	var stats service.AdminStats
+	if status := adminRequest(t, http.MethodGet, server.URL+"/api/v1/admin/stats", "s3cr3t", &stats); status != http.StatusOK || stats.Storage == nil || stats.Storage.Issues != 1 {
+		t.Errorf("stats = %d %+v, want one issue", status, stats.Storage)
+	}
+	clearURL := server.URL + "/api/v1/admin/repositories/org/repo/data"
+	if status := adminRequest(t, http.MethodDelete, clearURL, "wrong", nil); status != http.StatusForbidden {
+		t.Errorf("clear with a wrong key status = %d, want 403", status)
+	}
+	if status := adminRequest(t, http.MethodDelete, clearURL, "s3cr3t", nil); status != http.StatusNoContent {
+		t.Errorf("clear status = %d, want 204", status)
+	}
+	if issues, _ := db.ListAllIssues(ctx, "org/repo"); len(issues) != 0 {
+		t.Errorf("issues after clear = %d, want none", len(issues))
+	}
+}
+
+func TestAdminRoutesWithoutKey(t *testing.T) {
+	server, _ := newTestServer(t, &config.Config{})
+
+	// Without an admin key configured nobody may use the admin routes over HTTP
+	for _, route := range []struct{ method, path string }{
+		{http.MethodGet, "/api/v1/admin/stats"},
+		{http.MethodPost, "/api/v1/admin/compact"},
+		{http.MethodDelete, "/api/v1/admin/repositories/org/repo/data"},
+	} {
+		if status := adminRequest(t, route.method, server.URL+route.path, "", nil); status != http.StatusForbidden {
+			t.Errorf("%s %s without an admin key status = %d, want 403", route.method, route.path, status)
+		}
+	}
+}
+
+func TestListChanges(t *testing.T) {
+	server, db := newTestServer(t, &config.Config{})
+	for _, number := range []int{1, 2} {
+		if err := db.AppendActivity(context.Background(), &models.ActivityEvent{Type: "issue.opened", Repository: "org/repo", Number: number}); err != nil {
+			t.Fatalf("AppendActivity() error = %v", err)
+		}
+	}
+
+	resp, err := http.Get(server.URL + "/api/v1/changes?since=1")
+	if err != nil {
+		t.Fatalf("GET /api/v1/changes error = %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "application/x-ndjson" || resp.Header.Get("X-Next-Sequence") != "2" {
+		t.Fatalf("changes = %d %v, body %s", resp.StatusCode, resp.Header, body)
+	}
+	lines := strings.Split(strings.TrimSpace(string(body)), "\n")
+	var record models.ChangeRecord
+	if len(lines) != 1 || json.Unmarshal([]byte(lines[0]), &record) != nil || record.Sequence != 2 || record.Number != 2 {
+		t.Errorf("changes since 1 = %s, want the second event only", body)
+	}
+
+	if status, _ := get(t, server.URL+"/api/v1/changes?since=x"); status != http.StatusBadRequest {
+		t.Errorf("invalid since status = %d, want 400", status)
+	}
+}
+
+func TestRequiredSession(t *testing.T) {
+	server, _ := newTestServer(t, &config.Config{SSO: config.SSOConfig{SessionSecret: "secret", Required: true}})
+
+	if status, body := get(t, server.URL+"/api/v1/repositories"); status != http.StatusUnauthorized {
+		t.Errorf("status without session = %d, body %s", status, body)
+	}
+	// Health checks need no session
+	if status, body := get(t, server.URL+"/api/v1/health"); status != http.StatusOK || !strings.Contains(body, `"service":"ghrepos"`) {
+		t.Errorf("health status = %d, body %s", status, body)
+	}
+	// The dashboard itself is public; it asks for a session token
+	if status, _ := get(t, server.URL+"/"); status != http.StatusOK {
+		t.Errorf("dashboard status = %d", status)
+	}
+}
+
+func TestWorkspaceRoutes(t *testing.T) {
+	server, db := newTestServer(t, &config.Config{SSO: config.SSOConfig{SessionSecret: "secret", Required: true}})
+	ctx := context.Background()
+	if err := db.AddRepository(ctx, &models.Repository{Owner: "org", Name: "other", FullName: "org/other"}); err != nil {
+		t.Fatalf("AddRepository() error = %v", err)
+	}
+	hash := sha256.Sum256([]byte("ghw_team"))
+	for _, ws := range []*models.Workspace{
+		{ID: "team", Repositories: []string{"org/repo"}, Tokens: []*models.WorkspaceToken{{ID: 1, Name: "ci", Hash: hex.EncodeToString(hash[:])}}},
+		{ID: "other", Repositories: []string{"org/other"}},
+	} {
+		if err := db.SaveWorkspace(ctx, ws); err != nil {
+			t.Fatalf("SaveWorkspace() error = %v", err)
+		}
+	}
+
+	send := func(method, path, payload string) (int, string) {
+		t.Helper()
+		req, _ := http.NewRequest(method, server.URL+path, strings.NewReader(payload))
+		req.Header.Set("Authorization", "Bearer ghw_team")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s error = %v", method, path, err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+	do := func(method, path string) (int, string) {
+		t.Helper()
+		return send(method, path, "")
+	}
+	request := func(path string) (int, string) {
+		t.Helper()
+		return do(http.MethodGet, path)
+	}
+
+	// A workspace token only sees the repositories of its workspace, on either route
+	for _, path := range []string{"/api/v1/repositories", "/api/v1/workspaces/team/repositories"} {
+		status, body := request(path)
+		if status != http.StatusOK || !strings.Contains(body, "org/repo") || strings.Contains(body, "org/other") {
+			t.Errorf("%s status = %d, body %s, want only org/repo", path, status, body)
+		}
+	}
+	if status, body := request("/api/v1/repositories/org/other"); status != http.StatusNotFound {
+		t.Errorf("repository outside the workspace status = %d, body %s", status, body)
+	}
+	for _, path := range []string{"/api/v1/repositories/org/other/trends", "/api/v1/activity?repo=org/other"} {
+		if status, body := request(path); status != http.StatusNotFound {
+			t.Errorf("%s outside the workspace status = %d, body %s", path, status, body)
+		}
+	}
+	if status, body := do(http.MethodPost, "/api/v1/repositories/org/other/pause"); status != http.StatusNotFound {
+		t.Errorf("pause outside the workspace status = %d, body %s", status, body)
+	}
+	// Jobs and the activity of every repository span the workspaces
+	for _, path := range []string{"/api/v1/jobs", "/api/v1/activity"} {
+		if status, body := request(path); status != http.StatusUnauthorized {
+			t.Errorf("%s with a workspace token status = %d, body %s", path, status, body)
+		}
+	}
+	// Webhooks, triage rules and label subscriptions span every workspace
+	for _, route := range []struct{ method, path, payload string }{
+		{http.MethodGet, "/api/v1/hooks", ""},
+		{http.MethodPost, "/api/v1/hooks", `{"url":"https://example.com/hook"}`},
+		{http.MethodDelete, "/api/v1/hooks/1", ""},
+		{http.MethodGet, "/api/v1/hooks/1/deliveries", ""},
+		{http.MethodGet, "/api/v1/triage-rules", ""},
+		{http.MethodPost, "/api/v1/triage-rules", `{"name":"stale","if":{"no_label_days":7},"then":{"add_label":"stale"}}`},
+		{http.MethodDelete, "/api/v1/triage-rules/1", ""},
+		{http.MethodGet, "/api/v1/triage-rules/1/executions", ""},
+		{http.MethodGet, "/api/v1/subscriptions", ""},
+		{http.MethodPost, "/api/v1/subscriptions", `{"label":"security","url":"https://example.com/hook"}`},
+		{http.MethodDelete, "/api/v1/subscriptions/1", ""},
+	} {
+		if status, body := send(route.method, route.path, route.payload); status != http.StatusUnauthorized {
+			t.Errorf("%s %s with a workspace token status = %d, body %s", route.method, route.path, status, body)
+		}
+	}
+	if status, body := request("/api/v1/workspaces/other/repositories"); status != http.StatusNotFound {
+		t.Errorf("another workspace status = %d, body %s", status, body)
+	}
+	if status, body := request("/api/v1/workspaces/missing/repositories"); status != http.StatusNotFound {
+		t.Errorf("missing workspace status = %d, body %s", status, body)
+	}
+	if status, body := request("/api/v1/workspaces"); status != http.StatusOK || strings.Contains(body, `"other"`) || strings.Contains(body, "ghw_team") {
+		t.Errorf("workspaces status = %d, body %s, want only team without secrets", status, body)
+	}
+	if status, body := get(t, server.URL+"/api/v1/workspaces/team/repositories"); status != http.StatusUnauthorized {
+		t.Errorf("workspace route without a token status = %d, body %s", status, body)
+	}
+	if status, body := get(t, server.URL+"/api/v1/workspaces/missing/repositories"); status != http.StatusUnauthorized {
+		t.Errorf("missing workspace route without a token status = %d, body %s", status, body)
+	}
+
+	// Updates go through the workspace routes too
+	if status, body := send(http.MethodPatch, "/api/v1/workspaces/team/repositories/org/repo", `{"priority":"high"}`); status != http.StatusOK {
+		t.Errorf("PATCH through the workspace route status = %d, body %s", status, body)
+	}
+	if status, body := send(http.MethodPatch, "/api/v1/workspaces/team/repositories/org/other", `{"priority":"high"}`); status != http.StatusNotFound {
+		t.Errorf("PATCH outside the workspace status = %d, body %s", status, body)
+	}
+}
+
+func TestDashboard(t *testing.T) {
+	server, _ := newTestServer(t, &config.Config{})
+
+	for _, path := range []string{"/", "/app.js", "/style.css"} {
+		status, body := get(t, server.URL+path)
+		if status != http.StatusOK || body == "" {
+			t.Errorf("GET %s status = %d", path, status)
+		}
+	}
+	if _, body := get(t, server.URL+"/"); !strings.Contains(body, "app.js") {
+		t.Errorf("index does not load the dashboard script: %s", body)
+	}
+}
+
+func TestTracing(t *testing.T) {
+	var mu sync.Mutex
+	var spans []*tracepb.Span
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var req coltracepb.ExportTraceServiceRequest
+		if err := proto.Unmarshal(body, &req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		for _, resource := range req.ResourceSpans {
+			for _, scope := range resource.ScopeSpans {
+				spans = append(spans, scope.Spans...)
+			}
+		}
+	}))
+	defer collector.Close()
+
+	db, err := file.NewDB("")
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	cfg := &config.Config{Tracing: config.TracingConfig{Endpoint: collector.URL}}
+	svc, err := service.NewServiceWithOptions(cfg, service.Options{DB: db, Logger: log.New(io.Discard, "", 0)})
+	if err != nil {
+		t.Fatalf("NewServiceWithOptions() error = %v", err)
+	}
+	server := httptest.NewServer(New(svc, cfg.Server, log.New(io.Discard, "", 0)))
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/api/v1/repositories", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET error = %v", err)
+	}
+	resp.Body.Close()
+	svc.StopTracing()
+
+	var request *tracepb.Span
+	storage := 0
+	for _, span := range spans {
+		if hex.EncodeToString(span.TraceId) != "4bf92f3577b34da6a3ce929d0e0e4736" {
+			t.Errorf("span %s is not in the caller's trace", span.Name)
+		}
+		switch {
+		case span.Name == "GET /api/v1/repositories":
+			request = span
+		case strings.HasPrefix(span.Name, "db."):
+			storage++
+		}
+	}
+	if request == nil || hex.EncodeToString(request.ParentSpanId) != "00f067aa0ba902b7" || request.Kind != tracepb.Span_SPAN_KIND_SERVER {
+		t.Fatalf("request span = %v, want a server span continuing the caller's", request)
+	}
+	if storage == 0 {
+		t.Errorf("spans = %v, want storage operations within the request", spans)
+	}
+}
+
+func TestSubscriptionSecrets(t *testing.T) {
+	server, db := newTestServer(t, &config.Config{})
+
+	resp, err := http.Post(server.URL+"/api/v1/subscriptions", "application/json",
+		strings.NewReader(`{"label":"bug","channel":"webhook","url":"https://example.com/hook","secret":"s1gn"}`))
+	if err != nil {
+		t.Fatalf("POST /api/v1/subscriptions error = %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated || strings.Contains(string(body), "s1gn") {
+		t.Errorf("add subscription = %d %s, want it created without the secret", resp.StatusCode, body)
+	}
+	if subs, _ := db.ListSubscriptions(context.Background()); len(subs) != 1 || subs[0].Secret != "s1gn" {
+		t.Errorf("stored subscriptions = %+v, want the secret kept", subs)
+	}
+
+	// Secrets are write-only
+	if status, body := get(t, server.URL+"/api/v1/subscriptions"); status != http.StatusOK || strings.Contains(body, "s1gn") {
+		t.Errorf("list subscriptions = %d %s, want no secret", status, body)
+	}
+}
+
+func TestWebhookRoutes(t *testing.T) {
+	server, db := newTestServer(t, &config.Config{})
+
+	resp, err := http.Post(server.URL+"/api/v1/hooks", "application/json",
+		strings.NewReader(`{"url":"https://example.com/hook","secret":"h00k","events":["sync.completed"]}`))
+	if err != nil {
+		t.Fatalf("POST /api/v1/hooks error = %v", err)
+	}
+	var hook models.Webhook
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated || json.Unmarshal(body, &hook) != nil || hook.ID == 0 || strings.Contains(string(body), "h00k") {
+		t.Fatalf("add webhook = %d %s, want it created without the secret", resp.StatusCode, body)
+	}
+	resp, _ = http.Post(server.URL+"/api/v1/hooks", "application/json", strings.NewReader(`{"url":"ftp://example.com"}`))
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("invalid webhook URL status = %d, want 400", resp.StatusCode)
+	}
+
+	if status, body := get(t, server.URL+"/api/v1/hooks"); status != http.StatusOK || !strings.Contains(body, "example.com/hook") || strings.Contains(body, "h00k") {
+		t.Errorf("list webhooks = %d %s", status, body)
+	}
+	if err := db.AddWebhookDeliveries(context.Background(), []*models.WebhookDelivery{{WebhookID: hook.ID, Event: "sync.completed", StatusCode: 200}}); err != nil {
+		t.Fatalf("AddWebhookDeliveries() error = %v", err)
+	}
+	status, deliveries := get(t, server.URL+"/api/v1/hooks/"+strconv.FormatInt(hook.ID, 10)+"/deliveries")
+	var list struct {
+		Data []*models.WebhookDelivery `json:"data"`
+	}
+	if status != http.StatusOK || json.Unmarshal([]byte(deliveries), &list) != nil || len(list.Data) != 1 {
+		t.Errorf("deliveries = %d %s, want the recorded delivery", status, deliveries)
+	}
+	if status, _ := get(t, server.URL+"/api/v1/hooks/99/deliveries"); status != http.StatusNotFound {
+		t.Errorf("deliveries of a missing webhook status = %d, want 404", status)
+	}
+
+	if status := adminRequest(t, http.MethodDelete, server.URL+"/api/v1/hooks/"+strconv.FormatInt(hook.ID, 10), "", nil); status != http.StatusNoContent {
+		t.Errorf("delete webhook status = %d, want 204", status)
+	}
+}
+
+// send makes a request with a JSON body, decoding the response into result unless it is nil
+func send(t *testing.T, method, url, body string, result any) int {
+	t.Helper()
+	req, _ := http.NewRequest(method, url, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s error = %v", method, url, err)
+	}
+	defer resp.Body.Close()
+	if result != nil {
+		json.NewDecoder(resp.Body).Decode(result)
+	}
+	return resp.StatusCode
+}
+
+func TestRepositoryConfigRoutes(t *testing.T) {
+	server, _ := newTestServer(t, &config.Config{})
+	url := server.URL + "/api/v1/repositories/org/repo"
+
+	var repo models.Repository
+	if status := send(t, http.MethodPatch, url, `{"sync_interval":"30m","sync_issues":false,"item_limit":50,"priority":"high"}`, &repo); status != http.StatusOK {
+		t.Fatalf("PATCH status = %d", status)
+	}
+	if c := repo.SyncConfig; c.SyncInterval != 30*time.Minute || c.ShouldSyncIssues() || c.ItemLimit != 50 || c.Priority != "high" {
+		t.Errorf("sync config = %+v, want the update applied", c)
+	}
+	// Settings left out are unchanged
+	repo = models.Repository{}
+	if status := send(t, http.MethodPatch, url, `{"item_limit":10}`, &repo); status != http.StatusOK || repo.SyncConfig.SyncInterval != 30*time.Minute || repo.SyncConfig.ItemLimit != 10 {
+		t.Errorf("partial PATCH = %d %+v", status, repo.SyncConfig)
+	}
+	for _, body := range []string{`{"priority":"urgent"}`, `{"sync_interval":"soon"}`, `{"item_limit":-1}`, `[]`} {
+		if status := send(t, http.MethodPatch, url, body, nil); status != http.StatusBadRequest {
+			t.Errorf("PATCH %s status = %d, want 400", body, status)
+		}
+	}
+	if status := send(t, http.MethodPatch, server.URL+"/api/v1/repositories/org/missing", `{}`, nil); status != http.StatusNotFound {
+		t.Errorf("PATCH of a missing repository status = %d, want 404", status)
+	}
+
+	repo = models.Repository{}
+	if status := send(t, http.MethodPost, url+"/pause", "", &repo); status != http.StatusOK || !repo.Paused {
+		t.Errorf("pause = %d %+v, want the repository paused", status, repo)
+	}
+	repo = models.Repository{}
+	if status := send(t, http.MethodPost, url+"/resume", "", &repo); status != http.StatusOK || repo.Paused {
+		t.Errorf("resume = %d %+v, want the repository resumed", status, repo)
+	}
+}
+
+func TestItemDetail(t *testing.T) {
+	server, db := newTestServer(t, &config.Config{})
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+	history := models.StateHistory{{From: "open", To: "closed", At: now.Add(-2 * time.Hour)}, {From: "closed", To: "open", At: now.Add(-time.Hour)}}
+	if err := db.AddPullRequest(ctx, &models.PullRequest{RepositoryFullName: "org/repo", Number: 1, State: "open", StateHistory: history}); err != nil {
+		t.Fatalf("AddPullRequest() error = %v", err)
+	}
+	if err := db.AddIssue(ctx, &models.Issue{RepositoryFullName: "org/repo", Number: 2, State: "open", StateHistory: history}); err != nil {
+		t.Fatalf("AddIssue() error = %v", err)
+	}
+
+	// Items of other tracked repositories referencing them are listed with them
+	if err := db.AddRepository(ctx, &models.Repository{Owner: "org", Name: "other", FullName: "org/other"}); err != nil {
+		t.Fatalf("AddRepository() error = %v", err)
+	}
+	if err := db.AddIssue(ctx, &models.Issue{RepositoryFullName: "org/other", Number: 7, State: "open", References: []string{"org/repo#1", "org/repo#2"}}); err != nil {
+		t.Fatalf("AddIssue() error = %v", err)
+	}
+
+	for _, path := range []string{"/pulls/1", "/issues/2"} {
+		var item struct {
+			Number       int
+			StateHistory models.StateHistory
+			ReferencedBy []string
+		}
+		status := send(t, http.MethodGet, server.URL+"/api/v1/repositories/org/repo"+path, "", &item)
+		if status != http.StatusOK || item.StateHistory.Reopened() != 1 {
+			t.Errorf("GET %s = %d %+v, want its state history", path, status, item)
+		}
+		if len(item.ReferencedBy) != 1 || item.ReferencedBy[0] != "org/other#7" {
+			t.Errorf("GET %s referenced by %v, want org/other#7", path, item.ReferencedBy)
+		}
+	}
+	for path, want := range map[string]int{
+		"/pulls/2":    http.StatusNotFound,
+		"/issues/1":   http.StatusNotFound,
+		"/pulls/zero": http.StatusBadRequest,
+	} {
+		if status, body := get(t, server.URL+"/api/v1/repositories/org/repo"+path); status != want {
+			t.Errorf("GET %s status = %d, body %s, want %d", path, status, body, want)
+		}
+	}
+	if status, body := get(t, server.URL+"/api/v1/repositories/org/missing/pulls/1"); status != http.StatusNotFound {
+		t.Errorf("GET of an untracked repository's pull request status = %d, body %s", status, body)
+	}
+}
+
+func TestLeaderboard(t *testing.T) {
+	server, db := newTestServer(t, &config.Config{})
+	ctx := context.Background()
+	now := time.Now()
+	merged := now.Add(-time.Hour)
+	for _, pr := range []*models.PullRequest{
+		{RepositoryFullName: "org/repo", Number: 1, State: "merged", UserLogin: "alice", CreatedAt: now.Add(-5 * time.Hour), MergedAt: &merged},
+		{RepositoryFullName: "org/repo", Number: 2, State: "open", UserLogin: "bob", CreatedAt: now.Add(-2 * time.Hour)},
+		{RepositoryFullName: "org/repo", Number: 3, State: "open", UserLogin: "carol", CreatedAt: now.Add(-30 * 24 * time.Hour)},
+	} {
+		if err := db.AddPullRequest(ctx, pr); err != nil {
+			t.Fatalf("AddPullRequest() error = %v", err)
+		}
+	}
+	url := server.URL + "/api/v1/leaderboard?since=" + now.Add(-24*time.Hour).Format("2006-01-02")
+
+	var list struct {
+		Data []struct {
+			User               string `json:"user"`
+			PullRequestsMerged int    `json:"pull_requests_merged"`
+		} `json:"data"`
+		Pagination *models.Pagination `json:"pagination"`
+	}
+	if status := send(t, http.MethodGet, url+"&per_page=1", "", &list); status != http.StatusOK || len(list.Data) != 1 || list.Data[0].User != "alice" || list.Data[0].PullRequestsMerged != 1 {
+		t.Fatalf("leaderboard = %d %+v, want alice first", status, list.Data)
+	}
+	if list.Pagination.Total != 2 || list.Pagination.NextCursor == "" {
+		t.Fatalf("leaderboard pagination = %+v, want 2 users with a next cursor", list.Pagination)
+	}
+	if status := send(t, http.MethodGet, url+"&per_page=1&cursor="+list.Pagination.NextCursor, "", &list); status != http.StatusOK || len(list.Data) != 1 || list.Data[0].User != "bob" {
+		t.Errorf("leaderboard after the cursor = %d %+v, want bob", status, list.Data)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, url+"&format=csv", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET csv error = %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	lines := strings.Split(strings.TrimSpace(string(body)), "\n")
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/csv; charset=utf-8" || len(lines) != 3 || !strings.HasPrefix(lines[1], "alice,1,1,") {
+		t.Errorf("csv leaderboard = %d %q, want a header and a row per user", resp.StatusCode, body)
+	}
+	for _, query := range []string{"&format=xml", "&cursor=bad", "&until=tomorrow"} {
+		if status, body := get(t, url+query); status != http.StatusBadRequest {
+			t.Errorf("leaderboard%s status = %d, body %s", query, status, body)
+		}
+	}
+}
+
+func TestTrendsActivityAndAnalytics(t *testing.T) {
+	server, db := newTestServer(t, &config.Config{})
+	ctx := context.Background()
+	now := time.Now()
+	if err := db.AddRepositorySnapshot(ctx, &models.RepositorySnapshot{RepositoryFullName: "org/repo", OpenPullRequests: 3, CreatedAt: now.Add(-48 * time.Hour)}); err != nil {
+		t.Fatalf("AddRepositorySnapshot() error = %v", err)
+	}
+	if err := db.AddRepositorySnapshot(ctx, &models.RepositorySnapshot{RepositoryFullName: "org/repo", OpenPullRequests: 2, CreatedAt: now.Add(-time.Hour)}); err != nil {
+		t.Fatalf("AddRepositorySnapshot() error = %v", err)
+	}
+	if err := db.AppendActivity(ctx, &models.ActivityEvent{Type: "pull_request.opened", Repository: "org/repo", Number: 1, CreatedAt: now}); err != nil {
+		t.Fatalf("AppendActivity() error = %v", err)
+	}
+	merged := now.Add(-time.Hour)
+	if err := db.AddPullRequest(ctx, &models.PullRequest{RepositoryFullName: "org/repo", Number: 1, State: "merged", UserLogin: "alice", CreatedAt: now.Add(-5 * time.Hour), MergedAt: &merged}); err != nil {
+		t.Fatalf("AddPullRequest() error = %v", err)
+	}
+
+	var snapshots []*models.RepositorySnapshot
+	if status := send(t, http.MethodGet, server.URL+"/api/v1/repositories/org/repo/trends?window=1d", "", &snapshots); status != http.StatusOK || len(snapshots) != 1 || snapshots[0].OpenPullRequests != 2 {
+		t.Errorf("trends = %d %+v, want the snapshot of the last day", status, snapshots)
+	}
+	if status := send(t, http.MethodGet, server.URL+"/api/v1/repositories/org/repo/trends", "", &snapshots); status != http.StatusOK || len(snapshots) != 2 {
+		t.Errorf("default trends = %d %+v, want both snapshots", status, snapshots)
+	}
+	if status, body := get(t, server.URL+"/api/v1/repositories/org/repo/trends?window=ever"); status != http.StatusBadRequest {
+		t.Errorf("invalid window status = %d, body %s", status, body)
+	}
+
+	var activity struct {
+		Data       []*models.ActivityEvent `json:"data"`
+		Pagination *models.Pagination      `json:"pagination"`
+	}
+	if status := send(t, http.MethodGet, server.URL+"/api/v1/activity?repo=org/repo", "", &activity); status != http.StatusOK || len(activity.Data) != 1 || activity.Data[0].Type != "pull_request.opened" {
+		t.Errorf("activity = %d %+v", status, activity.Data)
+	}
+	if status := send(t, http.MethodGet, server.URL+"/api/v1/activity?repo=org/other", "", &activity); status != http.StatusOK || len(activity.Data) != 0 {
+		t.Errorf("activity of another repository = %d %+v, want none", status, activity.Data)
+	}
+
+	var report struct {
+		Overall struct {
+			PullRequestsMerged int `json:"pull_requests_merged"`
+		} `json:"overall"`
+	}
+	if status := send(t, http.MethodGet, server.URL+"/api/v1/analytics?repo=org/repo&since="+now.Add(-24*time.Hour).Format("2006-01-02"), "", &report); status != http.StatusOK || report.Overall.PullRequestsMerged != 1 {
+		t.Errorf("analytics = %d %+v, want the merged pull request", status, report)
+	}
+	if status, body := get(t, server.URL+"/api/v1/analytics?until=tomorrow"); status != http.StatusBadRequest {
+		t.Errorf("invalid until status = %d, body %s", status, body)
+	}
+}
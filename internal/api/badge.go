@@ -0,0 +1,111 @@
+package api
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// badgeCacheAge is how long clients and image proxies may cache a badge, in seconds
+const badgeCacheAge = 300
+
+// Badge colors
+const (
+	badgeLabelColor = "#555"
+	badgeZeroColor  = "#4c1"    // Nothing open
+	badgeCountColor = "#007ec6" // Some items open
+	badgeErrorColor = "#9f9f9f"
+)
+
+// badgeKinds maps the last path segment of a badge URL to its label and count
+var badgeKinds = map[string]struct {
+	label string
+	count func(counts *models.RepositorySnapshot) int
+}{
+	"open-prs":     {"open PRs", func(c *models.RepositorySnapshot) int { return c.OpenPullRequests }},
+	"open-issues":  {"open issues", func(c *models.RepositorySnapshot) int { return c.OpenIssues }},
+	"stale-prs":    {"stale PRs", func(c *models.RepositorySnapshot) int { return c.StalePullRequests }},
+	"stale-issues": {"stale issues", func(c *models.RepositorySnapshot) int { return c.StaleIssues }},
+}
+
+// handleBadge renders an SVG badge with an item count of a repository, such as
+// /badge/pingcap/tidb/open-prs, from the stored data. Badges are embedded in pages
+// that can't send a session, so those of public repositories are served to anyone; a
+// workspace token still only sees the repositories of its workspace.
+func (s *Server) handleBadge(w http.ResponseWriter, r *http.Request) {
+	kind, ok := badgeKinds[strings.TrimSuffix(r.PathValue("kind"), ".svg")]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	ctx := r.Context()
+	token, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	authenticated, _, authErr := s.service.Authenticate(ctx, token)
+	if authErr == nil {
+		ctx = authenticated
+	}
+	repo, err := s.service.GetStoredRepository(ctx, r.PathValue("owner"), r.PathValue("name"))
+	if err == nil && repo.IsPrivate {
+		err = authErr
+	}
+	var counts *models.RepositorySnapshot
+	if err == nil {
+		counts, err = s.service.GetRepositoryCounts(ctx, repo.Owner, repo.Name)
+	}
+
+	// Failed badges still render so the embedding page doesn't show a broken image
+	status, message, color := http.StatusOK, "", badgeCountColor
+	if err != nil {
+		// Unauthorized badges of private repositories look like unknown ones
+		status, message, color = http.StatusNotFound, "unknown", badgeErrorColor
+		if errorStatus(err) == http.StatusInternalServerError {
+			status = http.StatusInternalServerError
+			s.logger.Printf("Error rendering badge: %v", err)
+		}
+	} else {
+		count := kind.count(counts)
+		message = strconv.Itoa(count)
+		if count == 0 {
+			color = badgeZeroColor
+		}
+	}
+
+	w.Header().Set("Content-Type", "image/svg+xml")
+	w.Header().Set("Cache-Control", fmt.Sprintf("max-age=%d", badgeCacheAge))
+	w.WriteHeader(status)
+	fmt.Fprint(w, renderBadge(kind.label, message, color))
+}
+
+// badgeCharWidth approximates the width of a character in the 11px badge font
+const badgeCharWidth = 7
+
+// renderBadge draws a flat badge in the style of shields.io: the label on grey, the message on color
+func renderBadge(label, message, color string) string {
+	labelWidth := len(label)*badgeCharWidth + 10
+	messageWidth := len(message)*badgeCharWidth + 10
+	width := labelWidth + messageWidth
+	label, message = html.EscapeString(label), html.EscapeString(message)
+
+	return fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%[1]d" height="20" role="img" aria-label="%[4]s: %[5]s">
+<title>%[4]s: %[5]s</title>
+<linearGradient id="s" x2="0" y2="100%%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>
+<clipPath id="r"><rect width="%[1]d" height="20" rx="3" fill="#fff"/></clipPath>
+<g clip-path="url(#r)">
+<rect width="%[2]d" height="20" fill="%[6]s"/>
+<rect x="%[2]d" width="%[3]d" height="20" fill="%[7]s"/>
+<rect width="%[1]d" height="20" fill="url(#s)"/>
+</g>
+<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">
+<text x="%[8]d" y="15" fill="#010101" fill-opacity=".3">%[4]s</text>
+<text x="%[8]d" y="14">%[4]s</text>
+<text x="%[9]d" y="15" fill="#010101" fill-opacity=".3">%[5]s</text>
+<text x="%[9]d" y="14">%[5]s</text>
+</g>
+</svg>
+`, width, labelWidth, messageWidth, label, message, badgeLabelColor, color, labelWidth/2, labelWidth+messageWidth/2)
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+func TestBadge(t *testing.T) {
+	server, db := newTestServer(t, &config.Config{SSO: config.SSOConfig{SessionSecret: "secret", Required: true}})
+	ctx := context.Background()
+	for number, state := range map[int]string{1: "open", 2: "open", 3: "closed"} {
+		if err := db.AddPullRequest(ctx, &models.PullRequest{RepositoryFullName: "org/repo", Number: number, State: state}); err != nil {
+			t.Fatalf("AddPullRequest() error = %v", err)
+		}
+	}
+	if err := db.AddRepository(ctx, &models.Repository{Owner: "org", Name: "secret", FullName: "org/secret", IsPrivate: true}); err != nil {
+		t.Fatalf("AddRepository() error = %v", err)
+	}
+
+	tests := []struct {
+		path    string
+		status  int
+		message string
+	}{
+		{"/badge/org/repo/open-prs", http.StatusOK, ">2<"},
+		{"/badge/org/repo/open-issues.svg", http.StatusOK, ">0<"},
+		{"/badge/org/missing/open-prs", http.StatusNotFound, ">unknown<"},
+		// Private repositories need a session when sessions are required
+		{"/badge/org/secret/open-prs", http.StatusNotFound, ">unknown<"},
+	}
+	for _, tt := range tests {
+		status, body := get(t, server.URL+tt.path)
+		if status != tt.status || !strings.Contains(body, tt.message) || !strings.HasPrefix(body, "<svg") {
+			t.Errorf("GET %s = %d %s, want %d with %s", tt.path, status, body, tt.status, tt.message)
+		}
+	}
+
+	if status, _ := get(t, server.URL+"/badge/org/repo/stars"); status != http.StatusNotFound {
+		t.Errorf("unknown badge status = %d, want 404", status)
+	}
+
+	// A workspace token only sees the badges of private repositories of its workspace
+	for _, ws := range []*models.Workspace{
+		{ID: "team", Repositories: []string{"org/secret"}, Tokens: []*models.WorkspaceToken{{ID: 1, Hash: tokenHash("ghw_team")}}},
+		{ID: "other", Repositories: []string{"org/repo"}, Tokens: []*models.WorkspaceToken{{ID: 1, Hash: tokenHash("ghw_other")}}},
+	} {
+		if err := db.SaveWorkspace(ctx, ws); err != nil {
+			t.Fatalf("SaveWorkspace() error = %v", err)
+		}
+	}
+	for token, want := range map[string]int{"ghw_team": http.StatusOK, "ghw_other": http.StatusNotFound} {
+		req, _ := http.NewRequest(http.MethodGet, server.URL+"/badge/org/secret/open-prs", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("GET badge error = %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("private badge with %s status = %d, want %d", token, resp.StatusCode, want)
+		}
+	}
+}
+
+// tokenHash returns the stored hash of a workspace token
+func tokenHash(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
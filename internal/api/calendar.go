@@ -0,0 +1,106 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// icalLineLength is the maximum length of an iCalendar content line in octets (RFC 5545 3.1)
+const icalLineLength = 75
+
+// icalEscaper escapes TEXT values (RFC 5545 3.3.11)
+var icalEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// handleCalendar serves the milestone due dates and releases of the tracked repositories
+// as an iCalendar feed of all-day events at /calendar.ics, filtered by the repo, tag and
+// type query parameters
+func (s *Server) handleCalendar(w http.ResponseWriter, r *http.Request) {
+	since, err := timeParameter(r, "since")
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	until, err := timeParameter(r, "until")
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	query := r.URL.Query()
+	events, err := s.service.ListCalendarEvents(r.Context(), &models.CalendarFilter{
+		Repo:    query.Get("repo"),
+		RepoTag: repoTagParameter(r),
+		Type:    query.Get("type"),
+		Since:   since,
+		Until:   until,
+	})
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	var b strings.Builder
+	line := func(format string, args ...interface{}) {
+		writeICalLine(&b, fmt.Sprintf(format, args...))
+	}
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//ghrepos//calendar//EN")
+	line("CALSCALE:GREGORIAN")
+	line("X-WR-CALNAME:GitHub milestones and releases")
+	now := time.Now().UTC().Format("20060102T150405Z")
+	for _, event := range events {
+		stamp := now
+		if !event.UpdatedAt.IsZero() {
+			stamp = event.UpdatedAt.UTC().Format("20060102T150405Z")
+		}
+		day := event.Date.UTC()
+		line("BEGIN:VEVENT")
+		line("UID:%s-%s-%s@ghrepos", event.Type, strings.ReplaceAll(event.RepositoryFullName, "/", "-"), icalEscaper.Replace(event.ID))
+		line("DTSTAMP:%s", stamp)
+		line("DTSTART;VALUE=DATE:%s", day.Format("20060102"))
+		line("DTEND;VALUE=DATE:%s", day.AddDate(0, 0, 1).Format("20060102"))
+		line("SUMMARY:%s", icalEscaper.Replace(event.Title))
+		if event.Description != "" {
+			line("DESCRIPTION:%s", icalEscaper.Replace(event.Description))
+		}
+		if event.HTMLURL != "" {
+			line("URL:%s", event.HTMLURL)
+		}
+		line("CATEGORIES:%s", event.Type)
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, b.String())
+}
+
+// writeICalLine writes a content line ending with CRLF, folding it into continuation
+// lines starting with a space when it is too long. Lines are only split between UTF-8 characters.
+func writeICalLine(b *strings.Builder, line string) {
+	limit := icalLineLength
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8Start(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// The leading space of a continuation line counts towards its length
+		limit = icalLineLength - 1
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
+
+// utf8Start reports whether a byte starts a UTF-8 encoded character
+func utf8Start(c byte) bool {
+	return c&0xC0 != 0x80
+}
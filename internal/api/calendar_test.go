@@ -0,0 +1,62 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+func TestCalendar(t *testing.T) {
+	server, db := newTestServer(t, &config.Config{})
+	milestones := []*models.Milestone{{Number: 1, Title: "v1.0, final", State: "open", DueOn: time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)}}
+	if err := db.ReplaceMilestones(context.Background(), "org/repo", milestones); err != nil {
+		t.Fatalf("ReplaceMilestones() error = %v", err)
+	}
+
+	status, body := get(t, server.URL+"/calendar.ics?repo=org/repo")
+	if status != http.StatusOK {
+		t.Fatalf("calendar status = %d, body %s", status, body)
+	}
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"UID:milestone-org-repo-1@ghrepos\r\n",
+		"DTSTART;VALUE=DATE:20240301\r\n",
+		"DTEND;VALUE=DATE:20240302\r\n",
+		`SUMMARY:org/repo milestone v1.0\, final (open)` + "\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("calendar is missing %q:\n%s", want, body)
+		}
+	}
+
+	if status, _ := get(t, server.URL+"/calendar.ics?type=deadline"); status != http.StatusBadRequest {
+		t.Errorf("unknown event type status = %d, want 400", status)
+	}
+}
+
+func TestWriteICalLine(t *testing.T) {
+	var b strings.Builder
+	writeICalLine(&b, "DESCRIPTION:"+strings.Repeat("é", 80))
+
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\r\n"), "\r\n")
+	if len(lines) != 3 {
+		t.Fatalf("folded into %d lines, want 3: %q", len(lines), lines)
+	}
+	for i, line := range lines {
+		if len(line) > icalLineLength {
+			t.Errorf("line %d has %d octets, want at most %d", i, len(line), icalLineLength)
+		}
+		if i > 0 && !strings.HasPrefix(line, " ") {
+			t.Errorf("continuation line %d does not start with a space", i)
+		}
+	}
+	if unfolded := strings.ReplaceAll(b.String(), "\r\n ", ""); unfolded != "DESCRIPTION:"+strings.Repeat("é", 80)+"\r\n" {
+		t.Errorf("unfolded line = %q", unfolded)
+	}
+}
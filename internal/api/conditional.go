@@ -0,0 +1,57 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// writeList writes a list response with an ETag computed from its content and, when
+// lastModified is set, a Last-Modified date. Clients polling the list send them back in
+// If-None-Match or If-Modified-Since and get 304 Not Modified while it is unchanged.
+func (s *Server) writeList(w http.ResponseWriter, r *http.Request, list listResponse, lastModified time.Time) {
+	body, err := json.Marshal(list)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	w.Header().Set("ETag", etag)
+	if !lastModified.IsZero() {
+		w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+	if notModified(r, etag, lastModified) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, list)
+}
+
+// notModified reports whether the conditional headers of a request match an unchanged list.
+// As required by HTTP, If-None-Match takes precedence over If-Modified-Since.
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		for _, tag := range strings.Split(ifNoneMatch, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == etag || tag == "*" {
+				return true
+			}
+		}
+		return false
+	}
+
+	if lastModified.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	// HTTP dates have no fractions of a second
+	return !lastModified.Truncate(time.Second).After(since)
+}
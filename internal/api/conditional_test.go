@@ -0,0 +1,70 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+func TestConditionalList(t *testing.T) {
+	server, db := newTestServer(t, &config.Config{})
+	ctx := context.Background()
+	updated := time.Date(2024, 5, 1, 12, 0, 0, 500, time.UTC)
+	if err := db.AddPullRequest(ctx, &models.PullRequest{RepositoryFullName: "org/repo", Number: 1, State: "open", UpdatedAt: updated}); err != nil {
+		t.Fatalf("AddPullRequest() error = %v", err)
+	}
+	url := server.URL + "/api/v1/pulls?repo=org/repo"
+
+	request := func(header, value string) *http.Response {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodGet, url, nil)
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("GET %s error = %v", url, err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+
+	resp := request("", "")
+	etag := resp.Header.Get("ETag")
+	if resp.StatusCode != http.StatusOK || etag == "" {
+		t.Fatalf("list status = %d with ETag %q, want 200 with one", resp.StatusCode, etag)
+	}
+	if lastModified := resp.Header.Get("Last-Modified"); lastModified != updated.Format(http.TimeFormat) {
+		t.Errorf("Last-Modified = %q, want the latest update", lastModified)
+	}
+
+	tests := []struct {
+		header string
+		value  string
+		status int
+	}{
+		{"If-None-Match", etag, http.StatusNotModified},
+		{"If-None-Match", `"other", W/` + etag, http.StatusNotModified},
+		{"If-None-Match", `"other"`, http.StatusOK},
+		{"If-Modified-Since", updated.Format(http.TimeFormat), http.StatusNotModified},
+		{"If-Modified-Since", updated.Add(-time.Second).Format(http.TimeFormat), http.StatusOK},
+		{"If-Modified-Since", "yesterday", http.StatusOK},
+	}
+	for _, tt := range tests {
+		if resp := request(tt.header, tt.value); resp.StatusCode != tt.status {
+			t.Errorf("GET with %s: %s status = %d, want %d", tt.header, tt.value, resp.StatusCode, tt.status)
+		}
+	}
+
+	// A changed list has another ETag
+	if err := db.AddPullRequest(ctx, &models.PullRequest{RepositoryFullName: "org/repo", Number: 2, State: "open", UpdatedAt: updated}); err != nil {
+		t.Fatalf("AddPullRequest() error = %v", err)
+	}
+	if resp := request("If-None-Match", etag); resp.StatusCode != http.StatusOK || resp.Header.Get("ETag") == etag {
+		t.Errorf("changed list status = %d with ETag %s, want 200 with a new one", resp.StatusCode, resp.Header.Get("ETag"))
+	}
+}
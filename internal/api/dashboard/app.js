@@ -0,0 +1,154 @@
+// Dashboard of tracked repositories, pull requests and issues, built on the /api/v1 endpoints
+
+const sessionInput = document.getElementById("session");
+sessionInput.value = localStorage.getItem("ghrepos-session") || "";
+sessionInput.addEventListener("change", () => {
+  localStorage.setItem("ghrepos-session", sessionInput.value);
+  load(currentTab);
+});
+
+async function api(method, path) {
+  const headers = {};
+  if (sessionInput.value) {
+    headers["Authorization"] = "Bearer " + sessionInput.value;
+  }
+  const resp = await fetch(path, { method, headers });
+  const body = await resp.json();
+  // With server.envelope, errors are objects and responses other than lists are in data
+  const enveloped = resp.headers.get("X-Envelope") === "1";
+  if (!resp.ok) {
+    const error = enveloped ? body.error && body.error.message : body.error;
+    throw new Error(error || resp.statusText);
+  }
+  return enveloped && !("pagination" in body) ? body.data : body;
+}
+
+function showMessage(text) {
+  document.getElementById("message").textContent = text;
+}
+
+function el(tag, attrs, ...children) {
+  const node = document.createElement(tag);
+  Object.assign(node, attrs);
+  for (const child of children) {
+    node.append(child);
+  }
+  return node;
+}
+
+function link(text, href) {
+  return el("a", { href, target: "_blank", rel: "noopener" }, text);
+}
+
+function when(value) {
+  return value && !value.startsWith("0001-") ? new Date(value).toLocaleString() : "never";
+}
+
+function query(form) {
+  const params = new URLSearchParams({ per_page: "100" });
+  for (const [name, value] of new FormData(form)) {
+    if (value && name !== "awaiting_review") {
+      params.set(name, value);
+    }
+  }
+  return params;
+}
+
+function render(section, rows) {
+  const tbody = document.querySelector(`#${section} tbody`);
+  tbody.replaceChildren(...rows);
+}
+
+async function refresh(repo, button) {
+  button.disabled = true;
+  button.textContent = "Refreshing...";
+  try {
+    let job = await api("POST", `/api/v1/repositories/${repo}/refresh`);
+    while (job.State === "queued" || job.State === "running") {
+      await new Promise((resolve) => setTimeout(resolve, 2000));
+      job = await api("GET", `/api/v1/jobs/${job.ID}`);
+    }
+    if (job.State !== "succeeded") {
+      throw new Error(`refresh of ${repo} ${job.State}: ${job.Error}`);
+    }
+    await load("repositories");
+  } catch (err) {
+    showMessage(err.message);
+    button.disabled = false;
+    button.textContent = "Refresh";
+  }
+}
+
+const loaders = {
+  async repositories(form) {
+    const { data } = await api("GET", "/api/v1/repositories?" + query(form));
+    render("repositories", (data || []).map((repo) => {
+      const button = el("button", { textContent: "Refresh" });
+      button.addEventListener("click", () => refresh(repo.FullName, button));
+      return el("tr", {},
+        el("td", {}, link(repo.FullName, `https://github.com/${repo.FullName}`), repo.Paused ? " (paused)" : ""),
+        el("td", {}, ...(repo.Tags || []).map((tag) => el("span", { className: "tag" }, tag))),
+        el("td", {}, when(repo.LastSyncedAt)),
+        el("td", {}, button));
+    }));
+  },
+
+  async pulls(form) {
+    const params = query(form);
+    params.set("state", "open");
+    let { data } = await api("GET", "/api/v1/pulls?" + params);
+    data = data || [];
+    if (form.elements.awaiting_review.checked) {
+      data = data.filter((pr) => !pr.Draft && pr.ReviewDecision !== "APPROVED");
+    }
+    render("pulls", data.map((pr) => el("tr", {},
+      el("td", {}, pr.RepositoryFullName),
+      el("td", {}, link(`#${pr.Number} ${pr.Title}`, pr.HTMLURL)),
+      el("td", {}, pr.UserLogin),
+      el("td", {}, pr.Draft ? "draft" : (pr.ReviewDecision || "none").toLowerCase().replace("_", " ")),
+      el("td", {}, when(pr.UpdatedAt)))));
+  },
+
+  async issues(form) {
+    const { data } = await api("GET", "/api/v1/issues?" + query(form));
+    render("issues", (data || []).map((issue) => el("tr", {},
+      el("td", {}, issue.RepositoryFullName),
+      el("td", {}, link(`#${issue.Number} ${issue.Title}`, issue.HTMLURL)),
+      el("td", {}, issue.UserLogin),
+      el("td", {}, issue.State),
+      el("td", {}, when(issue.UpdatedAt)))));
+  },
+};
+
+let currentTab = "repositories";
+
+async function load(tab) {
+  showMessage("");
+  try {
+    await loaders[tab](document.querySelector(`#${tab} form`));
+  } catch (err) {
+    showMessage(err.message);
+  }
+}
+
+for (const button of document.querySelectorAll("nav button")) {
+  button.addEventListener("click", () => {
+    currentTab = button.dataset.tab;
+    for (const other of document.querySelectorAll("nav button")) {
+      other.classList.toggle("active", other === button);
+    }
+    for (const section of document.querySelectorAll("main section")) {
+      section.hidden = section.id !== currentTab;
+    }
+    load(currentTab);
+  });
+}
+
+for (const form of document.querySelectorAll(".filters")) {
+  form.addEventListener("submit", (event) => {
+    event.preventDefault();
+    load(currentTab);
+  });
+}
+
+load(currentTab);
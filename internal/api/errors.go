@@ -0,0 +1,115 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/siddontang/github-repos-management/internal/db"
+	"github.com/siddontang/github-repos-management/internal/github"
+	"github.com/siddontang/github-repos-management/internal/service"
+	"github.com/siddontang/github-repos-management/internal/sso"
+)
+
+// Error codes of failed requests, which clients can match on rather than on messages
+const (
+	codeRepoNotTracked      = "repo_not_tracked"
+	codeNotFound            = "not_found"
+	codeNotConfigured       = "not_configured"
+	codeInvalidRequest      = "invalid_request"
+	codeUnauthorized        = "unauthorized"
+	codeForbidden           = "forbidden"
+	codeConflict            = "conflict"
+	codeRateLimited         = "rate_limited"
+	codeUpstreamUnavailable = "upstream_unavailable"
+	codeInternal            = "internal"
+)
+
+// internalErrorMessage replaces the message of internal errors, which may reveal paths or the
+// output of gh; the error itself is logged with the request ID
+const internalErrorMessage = "internal server error"
+
+// errorResponse is the body of failed requests
+type errorResponse struct {
+	Error     string `json:"error"`
+	Code      string `json:"code,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// errorKind is the status and code of a class of errors
+type errorKind struct {
+	status int
+	code   string
+}
+
+// errorKinds maps errors to their kind, the first match winning. Errors matching none are internal.
+var errorKinds = []struct {
+	kind errorKind
+	errs []error
+}{
+	{errorKind{http.StatusNotFound, codeRepoNotTracked}, []error{service.ErrRepositoryNotFound}},
+	{errorKind{http.StatusNotFound, codeNotFound}, []error{
+		service.ErrPullRequestNotFound, service.ErrIssueNotFound, service.ErrJobNotFound, service.ErrWorkspaceNotFound,
+		service.ErrWorkspaceTokenNotFound, service.ErrProjectNotFound, service.ErrSubscriptionNotFound, service.ErrWebhookNotFound,
+		service.ErrTriageRuleNotFound, service.ErrSLAPolicyNotFound, service.ErrMilestoneNotFound, db.ErrNotFound,
+	}},
+	{errorKind{http.StatusNotFound, codeNotConfigured}, []error{
+		service.ErrQueryNotConfigured, service.ErrComplianceNotConfigured, service.ErrCodeOwnersNotConfigured,
+		service.ErrFilesNotConfigured, service.ErrProjectsNotConfigured, service.ErrLabelsNotConfigured,
+		service.ErrDuplicatesNotConfigured, service.ErrSLANotConfigured, service.ErrWarehouseNotConfigured,
+	}},
+	{errorKind{http.StatusBadRequest, codeInvalidRequest}, []error{
+		service.ErrInvalidRepositoryName, service.ErrInvalidTag, service.ErrInvalidSyncConfig, service.ErrInvalidCursor,
+		service.ErrInvalidItemType, service.ErrInvalidCalendarEventType, service.ErrInvalidQuery, service.ErrInvalidWorkspace,
+		service.ErrInvalidRelation, service.ErrInvalidSeverity, service.ErrInvalidAlertKind, service.ErrCodeOwnersUserNotSet,
+		service.ErrInvalidSize, service.ErrInvalidPathPattern, service.ErrInvalidSubscription, service.ErrReviewerNotSet,
+		service.ErrInvalidAggregate, service.ErrInvalidWindow, service.ErrInvalidDiffFormat, service.ErrInvalidItemUpdate,
+		service.ErrInvalidBulkAction, service.ErrInvalidBulkScope, service.ErrInvalidTriageRule, service.ErrInvalidReleaseNotesSince, service.ErrInvalidWebhookURL,
+		service.ErrInvalidOrganization, service.ErrInvalidLabelChange, service.ErrInvalidPullRequest, service.ErrInvalidSLAPolicy,
+		errInvalidParameter,
+	}},
+	{errorKind{http.StatusUnauthorized, codeUnauthorized}, []error{
+		service.ErrSessionRequired, service.ErrSSONotConfigured, sso.ErrInvalidSession, sso.ErrSessionExpired,
+		service.ErrInvalidWorkspaceToken,
+	}},
+	{errorKind{http.StatusForbidden, codeForbidden}, []error{service.ErrAdminUnauthorized, service.ErrAdminDisabled}},
+	{errorKind{http.StatusConflict, codeConflict}, []error{
+		service.ErrRepositoryExists, service.ErrWorkspaceExists, service.ErrJobFinished, db.ErrConflict,
+	}},
+	{errorKind{http.StatusBadGateway, codeUpstreamUnavailable}, []error{service.ErrQueryFailed}},
+	{errorKind{http.StatusServiceUnavailable, codeUpstreamUnavailable}, []error{github.ErrOffline, db.ErrUnavailable}},
+}
+
+// classifyError returns the kind of an error
+func classifyError(err error) errorKind {
+	for _, entry := range errorKinds {
+		for _, target := range entry.errs {
+			if errors.Is(err, target) {
+				return entry.kind
+			}
+		}
+	}
+	switch {
+	case github.IsRateLimited(err):
+		return errorKind{http.StatusTooManyRequests, codeRateLimited}
+	case github.IsAuthFailure(err):
+		return errorKind{http.StatusBadGateway, codeUpstreamUnavailable}
+	}
+	return errorKind{http.StatusInternalServerError, codeInternal}
+}
+
+// errorStatus returns the HTTP status of an error
+func errorStatus(err error) int {
+	return classifyError(err).status
+}
+
+// writeError writes an error response with the status and code matching the error
+func (s *Server) writeError(w http.ResponseWriter, err error) {
+	kind := classifyError(err)
+	response := errorResponse{Error: err.Error(), Code: kind.code}
+	if kind.status == http.StatusInternalServerError {
+		response.Error = internalErrorMessage
+		response.RequestID = w.Header().Get(requestIDHeader)
+		s.logger.Printf("Error handling request %s: %v", response.RequestID, err)
+	}
+	s.writeJSON(w, kind.status, response)
+}
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/db"
+	"github.com/siddontang/github-repos-management/internal/github"
+	"github.com/siddontang/github-repos-management/internal/service"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{fmt.Errorf("%w: tidb", service.ErrRepositoryNotFound), http.StatusNotFound, codeRepoNotTracked},
+		{service.ErrIssueNotFound, http.StatusNotFound, codeNotFound},
+		{service.ErrDuplicatesNotConfigured, http.StatusNotFound, codeNotConfigured},
+		{errors.Join(errInvalidParameter, errors.New("page must be a positive number")), http.StatusBadRequest, codeInvalidRequest},
+		{service.ErrAdminUnauthorized, http.StatusForbidden, codeForbidden},
+		{db.ErrVersionConflict, http.StatusConflict, codeConflict},
+		{fmt.Errorf("label bug %w in repository org/repo", db.ErrNotFound), http.StatusNotFound, codeNotFound},
+		{fmt.Errorf("%w: open /var/lib/ghrepos/data.db.tmp: no space left on device", db.ErrUnavailable), http.StatusServiceUnavailable, codeUpstreamUnavailable},
+		{db.ErrReadOnly, http.StatusServiceUnavailable, codeUpstreamUnavailable},
+		{errors.New("gh: API rate limit exceeded for user ID 1"), http.StatusTooManyRequests, codeRateLimited},
+		{github.ErrOffline, http.StatusServiceUnavailable, codeUpstreamUnavailable},
+		{errors.New("failed to write /var/lib/ghrepos/data.db: disk full"), http.StatusInternalServerError, codeInternal},
+	}
+	for _, tt := range tests {
+		if kind := classifyError(tt.err); kind.status != tt.status || kind.code != tt.code {
+			t.Errorf("classifyError(%v) = %d %s, want %d %s", tt.err, kind.status, kind.code, tt.status, tt.code)
+		}
+	}
+}
+
+func TestWriteInternalError(t *testing.T) {
+	var logged strings.Builder
+	s := &Server{logger: log.New(&logged, "", 0)}
+	w := httptest.NewRecorder()
+	w.Header().Set(requestIDHeader, "req-1")
+	s.writeError(w, errors.New("failed to write /var/lib/ghrepos/data.db: disk full"))
+
+	var body errorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if w.Code != http.StatusInternalServerError || body.Error != internalErrorMessage || body.Code != codeInternal || body.RequestID != "req-1" {
+		t.Errorf("response = %d %+v, want a generic internal error", w.Code, body)
+	}
+	if !strings.Contains(logged.String(), "req-1") || !strings.Contains(logged.String(), "disk full") {
+		t.Errorf("logged %q, want the error with the request ID", logged.String())
+	}
+}
+
+func TestEnvelope(t *testing.T) {
+	cfg := &config.Config{Server: config.ServerConfig{Envelope: true}}
+	server, _ := newTestServer(t, cfg)
+
+	get := func(path string) (*http.Response, map[string]json.RawMessage) {
+		t.Helper()
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s error = %v", path, err)
+		}
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+		var body map[string]json.RawMessage
+		if err := json.Unmarshal(data, &body); err != nil {
+			t.Fatalf("GET %s body = %s: %v", path, data, err)
+		}
+		return resp, body
+	}
+
+	resp, body := get("/api/v1/repositories")
+	if resp.Header.Get(envelopeHeader) != "1" || string(body["error"]) != "null" || !strings.Contains(string(body["data"]), `"org/repo"`) || body["pagination"] == nil {
+		t.Errorf("list = %v, want the items and pagination in the envelope", body)
+	}
+
+	resp, body = get("/api/v1/repositories/org/repo")
+	if _, ok := body["pagination"]; ok || !strings.Contains(string(body["data"]), `"FullName":"org/repo"`) {
+		t.Errorf("repository = %v, want it in data", body)
+	}
+
+	resp, body = get("/api/v1/repositories/org/missing")
+	var failure envelopeError
+	json.Unmarshal(body["error"], &failure)
+	if resp.StatusCode != http.StatusNotFound || string(body["data"]) != "null" || failure.Code != codeRepoNotTracked || failure.Message == "" {
+		t.Errorf("missing repository = %d %v, want a repo_not_tracked error", resp.StatusCode, body)
+	}
+}
@@ -0,0 +1,151 @@
+package api
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// feedEntries is the number of newest pull requests and issues a feed lists
+const feedEntries = 50
+
+// atomFeed is an Atom feed (RFC 4287)
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+// atomLink is a link of an Atom feed or entry
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+// atomEntry is an entry of an Atom feed
+type atomEntry struct {
+	ID        string     `xml:"id"`
+	Title     string     `xml:"title"`
+	Updated   string     `xml:"updated"`
+	Published string     `xml:"published"`
+	Author    atomAuthor `xml:"author"`
+	Links     []atomLink `xml:"link"`
+	Summary   string     `xml:"summary"`
+}
+
+// atomAuthor is the author of an Atom entry
+type atomAuthor struct {
+	Name string `xml:"name"`
+	URI  string `xml:"uri,omitempty"`
+}
+
+// handleRepositoryFeed serves the Atom feed of the newest pull requests and issues of a
+// repository at /feeds/{owner}/{name}.atom
+func (s *Server) handleRepositoryFeed(w http.ResponseWriter, r *http.Request) {
+	name, ok := strings.CutSuffix(r.PathValue("file"), ".atom")
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	repo := r.PathValue("owner") + "/" + name
+	s.writeFeed(w, r, repo+" activity", &models.ItemFilter{Repo: repo})
+}
+
+// handleFeed serves the Atom feed of the newest pull requests and issues across the
+// tracked repositories, or those with the repo_tag query parameter
+func (s *Server) handleFeed(w http.ResponseWriter, r *http.Request) {
+	title := "Tracked repositories activity"
+	tag := repoTagParameter(r)
+	if tag != "" {
+		title = fmt.Sprintf("Repositories tagged %s activity", tag)
+	}
+	s.writeFeed(w, r, title, &models.ItemFilter{RepoTag: tag})
+}
+
+// writeFeed lists the newest items matching filter from the stored data and writes them as an Atom feed
+func (s *Server) writeFeed(w http.ResponseWriter, r *http.Request, title string, filter *models.ItemFilter) {
+	filter.Type = r.URL.Query().Get("type")
+	filter.Direction = "desc"
+	filter.Cached = true
+	filter.Page = 1
+	filter.PerPage = feedEntries
+	items, _, err := s.service.ListItems(r.Context(), filter)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	self := requestURL(r)
+	feed := &atomFeed{
+		ID:    self,
+		Title: title,
+		Links: []atomLink{{Href: self, Rel: "self"}},
+	}
+	updated := time.Time{}
+	for _, item := range items {
+		kind := "Issue"
+		if item.Type == models.ItemTypePullRequest {
+			kind = "Pull request"
+		}
+		entry := atomEntry{
+			ID:        item.HTMLURL,
+			Title:     fmt.Sprintf("%s #%d: %s", item.RepositoryFullName, item.Number, item.Title),
+			Updated:   item.UpdatedAt.UTC().Format(time.RFC3339),
+			Published: item.CreatedAt.UTC().Format(time.RFC3339),
+			Author:    atomAuthor{Name: item.UserLogin, URI: "https://github.com/" + item.UserLogin},
+			Summary:   fmt.Sprintf("%s #%d opened by %s in %s, now %s", kind, item.Number, item.UserLogin, item.RepositoryFullName, strings.ToLower(item.State)),
+		}
+		if item.HTMLURL != "" {
+			entry.Links = []atomLink{{Href: item.HTMLURL, Rel: "alternate"}}
+		} else {
+			entry.ID = fmt.Sprintf("urn:ghrepos:%s:%s:%d", item.RepositoryFullName, item.Type, item.Number)
+		}
+		feed.Entries = append(feed.Entries, entry)
+		if item.UpdatedAt.After(updated) {
+			updated = item.UpdatedAt
+		}
+	}
+	if updated.IsZero() {
+		updated = time.Now()
+	}
+	feed.Updated = updated.UTC().Format(time.RFC3339)
+
+	w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, xml.Header)
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(feed); err != nil {
+		s.logger.Printf("Error writing feed: %v", err)
+	}
+}
+
+// requestURL returns the absolute URL of a request, honoring the scheme set by a TLS-terminating proxy
+func requestURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+	return scheme + "://" + r.Host + r.URL.RequestURI()
+}
+
+// queryToken lets feed readers and calendar apps, which can't send headers, pass the
+// session token as the token query parameter
+func queryToken(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if token := r.URL.Query().Get("token"); token != "" && r.Header.Get("Authorization") == "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		next(w, r)
+	}
+}
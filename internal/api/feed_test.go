@@ -0,0 +1,56 @@
+package api
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/models"
+	"github.com/siddontang/github-repos-management/internal/sso"
+)
+
+func TestFeed(t *testing.T) {
+	server, db := newTestServer(t, &config.Config{SSO: config.SSOConfig{SessionSecret: "secret", Required: true}})
+	ctx := context.Background()
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := db.AddPullRequest(ctx, &models.PullRequest{RepositoryFullName: "org/repo", Number: 1, Title: "Add feeds", State: "open", UserLogin: "alice", CreatedAt: created, UpdatedAt: created}); err != nil {
+		t.Fatalf("AddPullRequest() error = %v", err)
+	}
+	if err := db.AddIssue(ctx, &models.Issue{RepositoryFullName: "org/repo", Number: 2, Title: "Feeds are missing", State: "open", UserLogin: "bob", CreatedAt: created.Add(time.Hour), UpdatedAt: created.Add(time.Hour)}); err != nil {
+		t.Fatalf("AddIssue() error = %v", err)
+	}
+
+	if status, _ := get(t, server.URL+"/feeds/org/repo.atom"); status != http.StatusUnauthorized {
+		t.Fatalf("feed status without session = %d, want 401", status)
+	}
+	token, _, err := sso.IssueSession([]byte("secret"), &sso.Identity{Provider: "github", Subject: "1", Name: "alice"}, time.Hour)
+	if err != nil {
+		t.Fatalf("IssueSession() error = %v", err)
+	}
+
+	status, body := get(t, server.URL+"/feeds/org/repo.atom?token="+token)
+	if status != http.StatusOK {
+		t.Fatalf("feed status = %d, body %s", status, body)
+	}
+	var feed atomFeed
+	if err := xml.Unmarshal([]byte(body), &feed); err != nil {
+		t.Fatalf("feed %s: %v", body, err)
+	}
+	if len(feed.Entries) != 2 || feed.Entries[0].Title != "org/repo #2: Feeds are missing" || feed.Entries[1].Author.Name != "alice" {
+		t.Errorf("feed entries = %+v, want the issue then the pull request", feed.Entries)
+	}
+	if feed.Updated != "2024-01-01T01:00:00Z" {
+		t.Errorf("feed updated = %s, want the newest entry", feed.Updated)
+	}
+
+	var prFeed atomFeed
+	if _, body := get(t, server.URL+"/feeds/all.atom?type=pull_request&token="+token); xml.Unmarshal([]byte(body), &prFeed) != nil || len(prFeed.Entries) != 1 {
+		t.Errorf("pull request feed = %s, want one entry", body)
+	}
+	if status, _ := get(t, server.URL+"/feeds/org/missing.atom?token="+token); status != http.StatusNotFound {
+		t.Errorf("untracked repository feed status = %d, want 404", status)
+	}
+}
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// selectFields returns the items of a list with only the fields named in a comma-separated
+// fields parameter. Names match the JSON fields ignoring case and underscores, so user_login
+// selects UserLogin.
+func selectFields[T any](items []*T, fields string) ([]map[string]json.RawMessage, error) {
+	// The zero item has every field, even those the items leave out
+	zero, err := json.Marshal(new(T))
+	if err != nil {
+		return nil, err
+	}
+	var known map[string]json.RawMessage
+	if err := json.Unmarshal(zero, &known); err != nil {
+		return nil, err
+	}
+	names := make(map[string]string, len(known))
+	for name := range known {
+		names[fieldKey(name)] = name
+	}
+
+	var selected []string
+	for _, field := range strings.Split(fields, ",") {
+		name, ok := names[fieldKey(field)]
+		if !ok {
+			return nil, errors.Join(errInvalidParameter, errors.New("unknown field "+strings.TrimSpace(field)))
+		}
+		selected = append(selected, name)
+	}
+
+	sparse := make([]map[string]json.RawMessage, 0, len(items))
+	for _, item := range items {
+		data, err := json.Marshal(item)
+		if err != nil {
+			return nil, err
+		}
+		var all map[string]json.RawMessage
+		if err := json.Unmarshal(data, &all); err != nil {
+			return nil, err
+		}
+		values := make(map[string]json.RawMessage, len(selected))
+		for _, name := range selected {
+			values[name] = all[name]
+		}
+		sparse = append(sparse, values)
+	}
+	return sparse, nil
+}
+
+// fieldKey normalizes a field name for matching
+func fieldKey(name string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(name), "_", ""))
+}
+
+// withoutBodies returns copies of the items with their bodies cleared by clearBody. Bodies can
+// be large, so lists only return them when asked for.
+func withoutBodies[T any](items []*T, clearBody func(*T)) []*T {
+	copies := make([]*T, 0, len(items))
+	for _, item := range items {
+		copied := *item
+		clearBody(&copied)
+		copies = append(copies, &copied)
+	}
+	return copies
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+func TestListFields(t *testing.T) {
+	server, db := newTestServer(t, &config.Config{})
+	ctx := context.Background()
+	if err := db.AddPullRequest(ctx, &models.PullRequest{RepositoryFullName: "org/repo", Number: 1, Title: "Add retries", State: "open", Body: "A long description", UserLogin: "alice"}); err != nil {
+		t.Fatalf("AddPullRequest() error = %v", err)
+	}
+	if err := db.AddIssue(ctx, &models.Issue{RepositoryFullName: "org/repo", Number: 2, Title: "Requests time out", State: "open", Body: "Steps to reproduce"}); err != nil {
+		t.Fatalf("AddIssue() error = %v", err)
+	}
+
+	list := func(path string) map[string]interface{} {
+		t.Helper()
+		status, body := get(t, server.URL+path)
+		var list struct {
+			Data []map[string]interface{} `json:"data"`
+		}
+		if status != http.StatusOK || json.Unmarshal([]byte(body), &list) != nil || len(list.Data) != 1 {
+			t.Fatalf("GET %s = %d %s, want one item", path, status, body)
+		}
+		return list.Data[0]
+	}
+
+	// Bodies are left out unless asked for
+	if pr := list("/api/v1/pulls?repo=org/repo"); pr["Body"] != "" || pr["Title"] != "Add retries" {
+		t.Errorf("default pull request = %v, want every field but the body", pr)
+	}
+	if issue := list("/api/v1/issues?repo=org/repo"); issue["Body"] != "" {
+		t.Errorf("default issue body = %v, want it left out", issue["Body"])
+	}
+	if pr := list("/api/v1/pulls?repo=org/repo&include_body=true"); pr["Body"] != "A long description" {
+		t.Errorf("include_body pull request body = %v", pr["Body"])
+	}
+
+	// fields selects the fields returned, by any case and with underscores
+	pr := list("/api/v1/pulls?repo=org/repo&fields=number,user_login,BODY")
+	if len(pr) != 3 || pr["Number"] != float64(1) || pr["UserLogin"] != "alice" || pr["Body"] != "A long description" {
+		t.Errorf("pull request with fields = %v, want Number, UserLogin and Body only", pr)
+	}
+	if issue := list("/api/v1/issues?repo=org/repo&fields=title"); len(issue) != 1 || issue["Title"] != "Requests time out" {
+		t.Errorf("issue with fields = %v, want Title only", issue)
+	}
+
+	if status, body := get(t, server.URL+"/api/v1/pulls?repo=org/repo&fields=number,color"); status != http.StatusBadRequest {
+		t.Errorf("unknown field status = %d, body %s", status, body)
+	}
+}
@@ -0,0 +1,205 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/db/file"
+	"github.com/siddontang/github-repos-management/internal/github"
+	"github.com/siddontang/github-repos-management/internal/models"
+	"github.com/siddontang/github-repos-management/internal/service"
+)
+
+// TestRefreshFromFixtures tests refreshing a repository through the API with GitHub served from fixtures
+func TestRefreshFromFixtures(t *testing.T) {
+	ctx := context.Background()
+	gh := github.NewFixtureClient(&github.Fixture{
+		Repository:   &github.Repository{Owner: github.User{Login: "org"}, Name: "api", FullName: "org/api"},
+		PullRequests: []*github.PullRequest{{Number: 1, Title: "Add retries", State: "OPEN", User: github.User{Login: "alice"}}},
+	})
+	db, err := file.NewDB("")
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	svc, err := service.NewServiceWithOptions(&config.Config{}, service.Options{DB: db, GitHubClient: gh, Logger: log.New(io.Discard, "", 0)})
+	if err != nil {
+		t.Fatalf("NewServiceWithOptions() error = %v", err)
+	}
+	defer svc.Close()
+	if _, err := svc.AddRepository(ctx, "org/api"); err != nil {
+		t.Fatalf("AddRepository() error = %v", err)
+	}
+	server := httptest.NewServer(New(svc, config.ServerConfig{}, log.New(io.Discard, "", 0)))
+	defer server.Close()
+
+	listPullRequests := func() []*models.PullRequest {
+		t.Helper()
+		status, body := get(t, server.URL+"/api/v1/pulls?repo=org/api&state=all")
+		var list struct {
+			Data []*models.PullRequest `json:"data"`
+		}
+		if status != http.StatusOK || json.Unmarshal([]byte(body), &list) != nil {
+			t.Fatalf("list status = %d, body %s", status, body)
+		}
+		return list.Data
+	}
+	if prs := listPullRequests(); len(prs) != 1 || prs[0].UserLogin != "alice" {
+		t.Fatalf("pull requests = %+v, want the fixture's", prs)
+	}
+
+	gh.CreatePullRequest("org", "api", &github.PullRequestCreate{Title: "Document retries", Head: "docs"})
+	resp, err := http.Post(server.URL+"/api/v1/repositories/org/api/refresh", "application/json", nil)
+	if err != nil {
+		t.Fatalf("POST refresh error = %v", err)
+	}
+	var job models.Job
+	json.NewDecoder(resp.Body).Decode(&job)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("refresh status = %d", resp.StatusCode)
+	}
+	if done, err := svc.WaitJob(ctx, job.ID, 0); err != nil || done.State != models.JobStateSucceeded {
+		t.Fatalf("refresh job = %+v, %v", done, err)
+	}
+	if prs := listPullRequests(); len(prs) != 2 {
+		t.Errorf("pull requests after refresh = %d, want the new one too", len(prs))
+	}
+}
+
+// gatedClient serves GitHub from fixtures, holding pull request listings until its gate is closed
+type gatedClient struct {
+	*github.FixtureClient
+	gate chan struct{}
+}
+
+func (c *gatedClient) ListPullRequests(owner, name string, options *github.PullRequestOptions) ([]*github.PullRequest, error) {
+	<-c.gate
+	return c.FixtureClient.ListPullRequests(owner, name, options)
+}
+
+// TestRefreshWait tests planning a refresh through the API, and waiting for one until it finishes
+// or the timeout
+func TestRefreshWait(t *testing.T) {
+	ctx := context.Background()
+	gh := &gatedClient{
+		FixtureClient: github.NewFixtureClient(&github.Fixture{
+			Repository: &github.Repository{Owner: github.User{Login: "org"}, Name: "api", FullName: "org/api"},
+		}),
+		gate: make(chan struct{}),
+	}
+	db, err := file.NewDB("")
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	if err := db.AddRepository(ctx, &models.Repository{Owner: "org", Name: "api", FullName: "org/api"}); err != nil {
+		t.Fatalf("AddRepository() error = %v", err)
+	}
+	svc, err := service.NewServiceWithOptions(&config.Config{}, service.Options{DB: db, GitHubClient: gh, Logger: log.New(io.Discard, "", 0)})
+	if err != nil {
+		t.Fatalf("NewServiceWithOptions() error = %v", err)
+	}
+	defer svc.Close()
+	server := httptest.NewServer(New(svc, config.ServerConfig{}, log.New(io.Discard, "", 0)))
+	defer server.Close()
+	url := server.URL + "/api/v1/repositories/org/api/refresh"
+
+	// A dry run plans the refresh without starting it
+	var plan models.RefreshPlan
+	if status := send(t, http.MethodPost, url+"?dry_run=true", "", &plan); status != http.StatusOK || len(plan.Repositories) != 1 || plan.Repositories[0].FullName != "org/api" {
+		t.Errorf("dry run = %d %+v, want the plan of org/api", status, plan)
+	}
+	if jobs, _, err := svc.ListJobs(ctx, &models.JobFilter{Page: 1, PerPage: 10}); err != nil || len(jobs) != 0 {
+		t.Errorf("jobs after a dry run = %+v, %v, want none", jobs, err)
+	}
+	if status := send(t, http.MethodPost, url+"?dry_run=maybe", "", nil); status != http.StatusBadRequest {
+		t.Errorf("dry_run=maybe status = %d, want 400", status)
+	}
+
+	var job models.Job
+	if status := send(t, http.MethodPost, url+"?wait=true&timeout=50ms", "", &job); status != http.StatusAccepted || job.Done() {
+		t.Errorf("refresh past the timeout = %d %+v, want 202 with the running job", status, job)
+	}
+	close(gh.gate)
+	if status := send(t, http.MethodPost, url+"?wait=true&timeout=10s", "", &job); status != http.StatusOK || job.State != models.JobStateSucceeded {
+		t.Errorf("refresh = %d %+v, want 200 with the finished job", status, job)
+	}
+	for _, query := range []string{"?wait=maybe", "?wait=true&timeout=soon", "?wait=true&timeout=-1s"} {
+		if status := send(t, http.MethodPost, url+query, "", nil); status != http.StatusBadRequest {
+			t.Errorf("refresh%s status = %d, want 400", query, status)
+		}
+	}
+}
+
+// TestBatchAndJobs tests adding repositories in a batch, then listing and canceling their sync jobs
+func TestBatchAndJobs(t *testing.T) {
+	ctx := context.Background()
+	gh := github.NewFixtureClient(
+		&github.Fixture{Repository: &github.Repository{Owner: github.User{Login: "org"}, Name: "api", FullName: "org/api"}},
+		&github.Fixture{Repository: &github.Repository{Owner: github.User{Login: "org"}, Name: "web", FullName: "org/web"}},
+	)
+	db, err := file.NewDB("")
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	svc, err := service.NewServiceWithOptions(&config.Config{}, service.Options{DB: db, GitHubClient: gh, Logger: log.New(io.Discard, "", 0)})
+	if err != nil {
+		t.Fatalf("NewServiceWithOptions() error = %v", err)
+	}
+	defer svc.Close()
+	server := httptest.NewServer(New(svc, config.ServerConfig{}, log.New(io.Discard, "", 0)))
+	defer server.Close()
+
+	var results []*models.RepositoryAddResult
+	status := send(t, http.MethodPost, server.URL+"/api/v1/repositories/batch", `{"repositories":["org/api","org/web","org/missing","bad"]}`, &results)
+	if status != http.StatusOK || len(results) != 4 {
+		t.Fatalf("batch = %d %+v, want the outcome of each repository", status, results)
+	}
+	for i, result := range results[:2] {
+		if result.Error != "" || result.Repository == nil || result.Job == nil {
+			t.Errorf("result %d = %+v, want the repository added with its sync job", i, result)
+			continue
+		}
+		if job, err := svc.WaitJob(ctx, result.Job.ID, 0); err != nil || job.State != models.JobStateSucceeded {
+			t.Errorf("sync of %s = %+v, %v", result.FullName, job, err)
+		}
+	}
+	if results[2].Error == "" || results[3].Error == "" {
+		t.Errorf("failed results = %+v, %+v, want their errors", results[2], results[3])
+	}
+	// Adding again reports the repository as existing, without syncing it
+	results = nil
+	if status := send(t, http.MethodPost, server.URL+"/api/v1/repositories/batch", `{"repositories":["org/api"]}`, &results); status != http.StatusOK || len(results) != 1 || !results[0].Existing || results[0].Job != nil {
+		t.Errorf("second batch = %d %+v, want org/api existing", status, results)
+	}
+	for _, body := range []string{`{}`, `{"repositories":["org/api"],"organization":"org"}`, `{"organization":"org/api"}`} {
+		if status := send(t, http.MethodPost, server.URL+"/api/v1/repositories/batch", body, nil); status != http.StatusBadRequest {
+			t.Errorf("batch %s status = %d, want 400", body, status)
+		}
+	}
+
+	var jobs struct {
+		Data       []*models.Job      `json:"data"`
+		Pagination *models.Pagination `json:"pagination"`
+	}
+	if status := send(t, http.MethodGet, server.URL+"/api/v1/jobs?target=org/web", "", &jobs); status != http.StatusOK || len(jobs.Data) != 1 || jobs.Data[0].Target != "org/web" {
+		t.Fatalf("jobs = %d %+v, want the sync of org/web", status, jobs.Data)
+	}
+	if status := send(t, http.MethodGet, server.URL+"/api/v1/jobs?state=succeeded", "", &jobs); status != http.StatusOK || jobs.Pagination.Total != 2 {
+		t.Errorf("succeeded jobs = %d %+v, want both syncs", status, jobs.Pagination)
+	}
+	// Finished jobs can't be canceled
+	id := strconv.FormatInt(jobs.Data[0].ID, 10)
+	if status := send(t, http.MethodPost, server.URL+"/api/v1/jobs/"+id+"/cancel", "", nil); status != http.StatusConflict {
+		t.Errorf("cancel of a finished job status = %d, want 409", status)
+	}
+	if status := send(t, http.MethodPost, server.URL+"/api/v1/jobs/999/cancel", "", nil); status != http.StatusNotFound {
+		t.Errorf("cancel of a missing job status = %d, want 404", status)
+	}
+}
@@ -0,0 +1,1428 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/analytics"
+	"github.com/siddontang/github-repos-management/internal/models"
+	"github.com/siddontang/github-repos-management/internal/service"
+)
+
+// HealthService names the service in health responses, so clients can tell a ghrepos server
+// from anything else listening on its port
+const HealthService = "ghrepos"
+
+// handleHealth reports that the server is up. It needs no session and makes no GitHub
+// requests, so load balancers and the CLI can probe it cheaply.
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	s.writeJSON(w, http.StatusOK, map[string]string{"status": "ok", "service": HealthService})
+}
+
+// handleStatus reports the service status
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	status, err := s.service.GetStatus(r.Context())
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, status)
+}
+
+// handleListRepositories lists tracked repositories, optionally with a tag
+func (s *Server) handleListRepositories(w http.ResponseWriter, r *http.Request) {
+	page, perPage, err := pagination(r)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	filter := &models.RepositoryFilter{
+		Tag:     repoTagParameter(r),
+		Cursor:  r.URL.Query().Get("cursor"),
+		Page:    page,
+		PerPage: perPage,
+	}
+	repos, pagination, err := s.service.ListRepositories(r.Context(), filter)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	s.writeList(w, r, listResponse{Data: repos, Pagination: pagination}, time.Time{})
+}
+
+// handleGetRepository returns a tracked repository
+func (s *Server) handleGetRepository(w http.ResponseWriter, r *http.Request) {
+	repo, err := s.service.GetRepository(r.Context(), r.PathValue("owner"), r.PathValue("name"))
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, repo)
+}
+
+// handleRefreshRepository starts refreshing a repository and returns the job to poll. With
+// wait=true it waits for the job up to timeout (30s by default, at most maxRefreshWait),
+// returning 200 with the finished job or 202 with the job still running. With dry_run=true
+// it returns what the refresh would fetch instead, without running it.
+func (s *Server) handleRefreshRepository(w http.ResponseWriter, r *http.Request) {
+	if value := r.URL.Query().Get("dry_run"); value != "" {
+		dryRun, err := strconv.ParseBool(value)
+		if err != nil {
+			s.writeError(w, errors.Join(errInvalidParameter, errors.New("dry_run must be true or false")))
+			return
+		}
+		if dryRun {
+			plan, err := s.service.PlanRefresh(r.Context(), r.PathValue("owner"), r.PathValue("name"), false)
+			if err != nil {
+				s.writeError(w, err)
+				return
+			}
+			s.writeJSON(w, http.StatusOK, plan)
+			return
+		}
+	}
+
+	wait := false
+	timeout := defaultRefreshWait
+	if value := r.URL.Query().Get("wait"); value != "" {
+		var err error
+		if wait, err = strconv.ParseBool(value); err != nil {
+			s.writeError(w, errors.Join(errInvalidParameter, errors.New("wait must be true or false")))
+			return
+		}
+	}
+	if value := r.URL.Query().Get("timeout"); value != "" {
+		var err error
+		if timeout, err = time.ParseDuration(value); err != nil || timeout <= 0 {
+			s.writeError(w, errors.Join(errInvalidParameter, errors.New("timeout must be a positive duration such as 30s")))
+			return
+		}
+		timeout = min(timeout, maxRefreshWait)
+	}
+
+	job, err := s.service.StartRefresh(r.Context(), r.PathValue("owner"), r.PathValue("name"))
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	if !wait {
+		s.writeJSON(w, http.StatusAccepted, job)
+		return
+	}
+
+	if job, err = s.service.WaitJob(r.Context(), job.ID, timeout); err != nil {
+		s.writeError(w, err)
+		return
+	}
+	if !job.Done() {
+		s.writeJSON(w, http.StatusAccepted, job)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, job)
+}
+
+// batchRequest is the JSON body of /api/v1/repositories/batch: the full names of repositories,
+// or an organization whose repositories are all added
+type batchRequest struct {
+	Repositories []string `json:"repositories"`
+	Organization string   `json:"organization"`
+}
+
+// handleAddRepositories adds several repositories at once, returning the outcome of each with the
+// sync job queued for the newly added ones
+func (s *Server) handleAddRepositories(w http.ResponseWriter, r *http.Request) {
+	var req batchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, errors.Join(errInvalidParameter, errors.New("body must be a JSON object with repositories or organization")))
+		return
+	}
+	if (len(req.Repositories) == 0) == (req.Organization == "") {
+		s.writeError(w, errors.Join(errInvalidParameter, errors.New("exactly one of repositories and organization must be given")))
+		return
+	}
+
+	var results []*models.RepositoryAddResult
+	if req.Organization != "" {
+		var err error
+		if results, err = s.service.StartAddOrganizationRepositories(r.Context(), req.Organization); err != nil {
+			s.writeError(w, err)
+			return
+		}
+	} else {
+		results = s.service.StartAddRepositories(r.Context(), req.Repositories)
+	}
+	s.writeJSON(w, http.StatusOK, results)
+}
+
+// syncConfigRequest is the JSON body of PATCH /api/v1/repositories/{owner}/{name}. Settings left
+// out are unchanged.
+type syncConfigRequest struct {
+	SyncInterval     *string `json:"sync_interval"` // A duration such as 30m, 0 for the default
+	SyncPullRequests *bool   `json:"sync_pull_requests"`
+	SyncIssues       *bool   `json:"sync_issues"`
+	SyncReviews      *bool   `json:"sync_reviews"`
+	SyncDiscussions  *bool   `json:"sync_discussions"`
+	ItemLimit        *int    `json:"item_limit"`
+	Priority         *string `json:"priority"`
+}
+
+// handleUpdateRepository changes the sync configuration of a repository, returning the repository
+func (s *Server) handleUpdateRepository(w http.ResponseWriter, r *http.Request) {
+	var req syncConfigRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, errors.Join(errInvalidParameter, errors.New("body must be a JSON sync configuration")))
+		return
+	}
+	update := &models.RepositorySyncConfigUpdate{
+		SyncPullRequests: req.SyncPullRequests,
+		SyncIssues:       req.SyncIssues,
+		SyncReviews:      req.SyncReviews,
+		SyncDiscussions:  req.SyncDiscussions,
+		ItemLimit:        req.ItemLimit,
+		Priority:         req.Priority,
+	}
+	if req.SyncInterval != nil {
+		interval, err := time.ParseDuration(*req.SyncInterval)
+		if err != nil {
+			s.writeError(w, errors.Join(errInvalidParameter, errors.New("sync_interval must be a duration such as 30m")))
+			return
+		}
+		update.SyncInterval = &interval
+	}
+
+	repo, err := s.service.UpdateRepositorySyncConfig(r.Context(), r.PathValue("owner"), r.PathValue("name"), update)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, repo)
+}
+
+// handlePauseRepository excludes a repository from scheduled refreshes, returning the repository
+func (s *Server) handlePauseRepository(w http.ResponseWriter, r *http.Request) {
+	repo, err := s.service.PauseRepository(r.Context(), r.PathValue("owner"), r.PathValue("name"))
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, repo)
+}
+
+// handleResumeRepository re-includes a paused repository in scheduled refreshes, returning the repository
+func (s *Server) handleResumeRepository(w http.ResponseWriter, r *http.Request) {
+	repo, err := s.service.ResumeRepository(r.Context(), r.PathValue("owner"), r.PathValue("name"))
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, repo)
+}
+
+// handleRepositoryTrends returns the metric snapshots of a repository taken within the window
+// parameter (such as 90d or 12h, default 90d), oldest first
+func (s *Server) handleRepositoryTrends(w http.ResponseWriter, r *http.Request) {
+	window := 90 * 24 * time.Hour
+	if value := r.URL.Query().Get("window"); value != "" {
+		var err error
+		if window, err = parseWindow(value); err != nil {
+			s.writeError(w, errors.Join(errInvalidParameter, errors.New("window must be a number of days such as 90d or a duration such as 12h")))
+			return
+		}
+	}
+	snapshots, err := s.service.GetRepositoryTrends(r.Context(), r.PathValue("owner"), r.PathValue("name"), window)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, snapshots)
+}
+
+// repositoryAlertsResponse is the body of /api/v1/repositories/{owner}/{name}/alerts
+type repositoryAlertsResponse struct {
+	Summary *models.SecurityAlertSummary `json:"summary"`
+	Data    []*models.SecurityAlert      `json:"data"`
+}
+
+// handleRepositoryAlerts returns the severity breakdown and open security alerts of a repository
+func (s *Server) handleRepositoryAlerts(w http.ResponseWriter, r *http.Request) {
+	summary, alerts, err := s.service.GetRepositoryAlerts(r.Context(), r.PathValue("owner"), r.PathValue("name"))
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, repositoryAlertsResponse{Summary: summary, Data: alerts})
+}
+
+// handleListDuplicates lists the probable duplicate issues of a repository, best match first
+func (s *Server) handleListDuplicates(w http.ResponseWriter, r *http.Request) {
+	candidates, err := s.service.ListDuplicates(r.Context(), r.PathValue("owner"), r.PathValue("name"))
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, candidates)
+}
+
+// handleReleaseNotes drafts the release notes of a repository since the release tag or date of the
+// since parameter, as JSON or, with format=markdown, as the Markdown changelog alone
+func (s *Server) handleReleaseNotes(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	format := query.Get("format")
+	if format != "" && format != "json" && format != "markdown" {
+		s.writeError(w, errors.Join(errInvalidParameter, errors.New("format must be json or markdown")))
+		return
+	}
+	notes, err := s.service.ReleaseNotes(r.Context(), r.PathValue("owner"), r.PathValue("name"), query.Get("since"))
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	if format == "markdown" {
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		io.WriteString(w, notes.Markdown)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, notes)
+}
+
+// handleListCommits lists the synced default branch commits of a repository, newest first
+func (s *Server) handleListCommits(w http.ResponseWriter, r *http.Request) {
+	page, perPage, err := pagination(r)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	since, err := timeParameter(r, "since")
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	until, err := timeParameter(r, "until")
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	filter := &models.CommitFilter{
+		Author:  r.URL.Query().Get("author"),
+		Since:   since,
+		Until:   until,
+		Page:    page,
+		PerPage: perPage,
+	}
+	commits, pagination, err := s.service.ListCommits(r.Context(), r.PathValue("owner"), r.PathValue("name"), filter)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	s.writeList(w, r, listResponse{Data: commits, Pagination: pagination}, time.Time{})
+}
+
+// handlePullRequestDiff returns the changes of a pull request as a diff or, with format=patch, as
+// patches; cache=true stores them for offline viewing
+func (s *Server) handlePullRequestDiff(w http.ResponseWriter, r *http.Request) {
+	number, err := strconv.Atoi(r.PathValue("number"))
+	if err != nil || number < 1 {
+		s.writeError(w, errors.Join(errInvalidParameter, errors.New("number must be a positive number")))
+		return
+	}
+	cache := false
+	if value := r.URL.Query().Get("cache"); value != "" {
+		if cache, err = strconv.ParseBool(value); err != nil {
+			s.writeError(w, errors.Join(errInvalidParameter, errors.New("cache must be true or false")))
+			return
+		}
+	}
+
+	diff, err := s.service.GetPullRequestDiff(r.Context(), r.PathValue("owner"), r.PathValue("name"), number, r.URL.Query().Get("format"), cache)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, diff)
+}
+
+// handleGetPullRequest returns a pull request with its state history and the items of tracked
+// repositories referencing it
+func (s *Server) handleGetPullRequest(w http.ResponseWriter, r *http.Request) {
+	number, err := itemNumber(r)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	pr, err := s.service.GetPullRequest(r.Context(), r.PathValue("owner"), r.PathValue("name"), number)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, pr)
+}
+
+// handleGetIssue returns an issue with its state history and the items of tracked repositories
+// referencing it
+func (s *Server) handleGetIssue(w http.ResponseWriter, r *http.Request) {
+	number, err := itemNumber(r)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	issue, err := s.service.GetIssue(r.Context(), r.PathValue("owner"), r.PathValue("name"), number)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, issue)
+}
+
+// handleUpdatePullRequest changes the assignees or milestone of a pull request
+func (s *Server) handleUpdatePullRequest(w http.ResponseWriter, r *http.Request) {
+	number, update, err := itemUpdate(r)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	pr, err := s.service.UpdatePullRequest(r.Context(), r.PathValue("owner"), r.PathValue("name"), number, update)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, pr)
+}
+
+// handleUpdateIssue changes the assignees or milestone of an issue
+func (s *Server) handleUpdateIssue(w http.ResponseWriter, r *http.Request) {
+	number, update, err := itemUpdate(r)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	issue, err := s.service.UpdateIssue(r.Context(), r.PathValue("owner"), r.PathValue("name"), number, update)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, issue)
+}
+
+// handleIssueTree returns an issue with the issues of its task list and their progress
+func (s *Server) handleIssueTree(w http.ResponseWriter, r *http.Request) {
+	number, err := strconv.Atoi(r.PathValue("number"))
+	if err != nil || number < 1 {
+		s.writeError(w, errors.Join(errInvalidParameter, errors.New("number must be a positive number")))
+		return
+	}
+	tree, err := s.service.GetIssueTree(r.Context(), r.PathValue("owner"), r.PathValue("name"), number)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, tree)
+}
+
+// itemUpdate reads the number and the JSON update of a pull request or issue
+func itemUpdate(r *http.Request) (int, *models.ItemUpdate, error) {
+	number, err := itemNumber(r)
+	if err != nil {
+		return 0, nil, err
+	}
+	update := &models.ItemUpdate{}
+	if err := json.NewDecoder(r.Body).Decode(update); err != nil {
+		return 0, nil, errors.Join(errInvalidParameter, errors.New("body must be a JSON object with add_assignees, remove_assignees or milestone"))
+	}
+	return number, update, nil
+}
+
+// itemNumber reads the number of the pull request or issue of a request path
+func itemNumber(r *http.Request) (int, error) {
+	number, err := strconv.Atoi(r.PathValue("number"))
+	if err != nil || number < 1 {
+		return 0, errors.Join(errInvalidParameter, errors.New("number must be a positive number"))
+	}
+	return number, nil
+}
+
+// bulkMaxBody bounds the size of a bulk request
+const bulkMaxBody = 64 << 10
+
+// handleBulk closes, labels or comments on the pull requests and issues matching a filter, or only
+// plans it with dry_run, reporting the outcome of each
+func (s *Server) handleBulk(w http.ResponseWriter, r *http.Request) {
+	req := &models.BulkRequest{}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, bulkMaxBody)).Decode(req); err != nil {
+		s.writeError(w, errors.Join(errInvalidParameter, errors.New("body must be a JSON bulk request")))
+		return
+	}
+	report, err := s.service.ApplyBulkAction(r.Context(), req)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, report)
+}
+
+// handleListAlerts lists the open security alerts of the tracked repositories, most severe first
+func (s *Server) handleListAlerts(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	alerts, err := s.service.ListSecurityAlerts(r.Context(), &models.SecurityAlertFilter{
+		Repo:     query.Get("repo"),
+		RepoTag:  query.Get("repo_tag"),
+		Kind:     query.Get("kind"),
+		Severity: query.Get("severity"),
+	})
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, alerts)
+}
+
+// handleCompliance reports how the settings of the tracked repositories compare to the compliance policy
+func (s *Server) handleCompliance(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := &models.ComplianceFilter{Repo: query.Get("repo"), RepoTag: query.Get("repo_tag")}
+	if value := query.Get("violations_only"); value != "" {
+		violationsOnly, err := strconv.ParseBool(value)
+		if err != nil {
+			s.writeError(w, errors.Join(errInvalidParameter, errors.New("violations_only must be true or false")))
+			return
+		}
+		filter.ViolationsOnly = violationsOnly
+	}
+
+	results, err := s.service.ComplianceReport(r.Context(), filter)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, results)
+}
+
+// handleListLabels lists the labels of the tracked repositories grouped by name, with their inconsistencies
+func (s *Server) handleListLabels(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := &models.LabelFilter{Repo: query.Get("repo"), RepoTag: query.Get("repo_tag"), Name: query.Get("name")}
+	if value := query.Get("inconsistent"); value != "" {
+		inconsistent, err := strconv.ParseBool(value)
+		if err != nil {
+			s.writeError(w, errors.Join(errInvalidParameter, errors.New("inconsistent must be true or false")))
+			return
+		}
+		filter.InconsistentOnly = inconsistent
+	}
+
+	labels, err := s.service.LabelRegistry(r.Context(), filter)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, labels)
+}
+
+// handleTopics groups the open issues of the tracked repositories into themes by label and title
+// keyword, most issues first
+func (s *Server) handleTopics(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := &models.TopicFilter{Repo: query.Get("repo"), RepoTag: query.Get("repo_tag"), ExcludeBots: query.Get("exclude_bots") == "true", Limit: 20}
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 0 {
+			s.writeError(w, errInvalidParameter)
+			return
+		}
+		filter.Limit = limit
+	}
+	topics, err := s.service.GetTopics(r.Context(), filter)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, topics)
+}
+
+// handleAnalytics computes lead-time and review-latency metrics of the pull requests and issues
+// created between the since and until dates, overall, per repository and per author
+func (s *Server) handleAnalytics(w http.ResponseWriter, r *http.Request) {
+	since, err := timeParameter(r, "since")
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	until, err := timeParameter(r, "until")
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	query := r.URL.Query()
+	report, err := s.service.GetAnalytics(r.Context(), &models.AnalyticsFilter{
+		Repo:        query.Get("repo"),
+		RepoTag:     query.Get("repo_tag"),
+		ExcludeBots: query.Get("exclude_bots") == "true",
+		Association: query.Get("association"),
+		Since:       since,
+		Until:       until,
+	})
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, report)
+}
+
+// handleLeaderboard summarizes the contributions per user between the since and until dates, most
+// active first, as a JSON list or, with format=csv, as CSV rows of the page
+func (s *Server) handleLeaderboard(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	format := query.Get("format")
+	if format != "" && format != "json" && format != "csv" {
+		s.writeError(w, errors.Join(errInvalidParameter, errors.New("format must be json or csv")))
+		return
+	}
+	page, perPage, err := pagination(r)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	since, err := timeParameter(r, "since")
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	until, err := timeParameter(r, "until")
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	contributions, pagination, err := s.service.GetLeaderboard(r.Context(), &models.LeaderboardFilter{
+		Repo:        query.Get("repo"),
+		RepoTag:     query.Get("repo_tag"),
+		ExcludeBots: query.Get("exclude_bots") == "true",
+		Association: query.Get("association"),
+		Since:       since,
+		Until:       until,
+		Cursor:      query.Get("cursor"),
+		Page:        page,
+		PerPage:     perPage,
+	})
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	if format == "csv" {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		if err := analytics.WriteLeaderboardCSV(w, contributions); err != nil {
+			s.logger.Printf("Error writing response: %v", err)
+		}
+		return
+	}
+	s.writeList(w, r, listResponse{Data: contributions, Pagination: pagination}, time.Time{})
+}
+
+// handleListActivity lists the recorded activity events, newest first
+func (s *Server) handleListActivity(w http.ResponseWriter, r *http.Request) {
+	page, perPage, err := pagination(r)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	since, err := timeParameter(r, "since")
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	until, err := timeParameter(r, "until")
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	query := r.URL.Query()
+	filter := &models.ActivityFilter{
+		Repo:    query.Get("repo"),
+		Type:    query.Get("type"),
+		Since:   since,
+		Until:   until,
+		Page:    page,
+		PerPage: perPage,
+	}
+	events, pagination, err := s.service.ListActivity(r.Context(), filter)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	s.writeList(w, r, listResponse{Data: events, Pagination: pagination}, time.Time{})
+}
+
+// handleReviewQueue lists the open pull requests waiting for review from a reviewer, oldest first
+func (s *Server) handleReviewQueue(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := &models.ReviewQueueFilter{Reviewer: query.Get("reviewer"), Repo: query.Get("repo"), RepoTag: query.Get("repo_tag")}
+	items, err := s.service.ReviewQueue(r.Context(), filter)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, items)
+}
+
+// handleSLAReport lists the open pull requests and issues breaching the configured SLA policies
+func (s *Server) handleSLAReport(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := &models.SLAFilter{Repo: query.Get("repo"), RepoTag: query.Get("repo_tag"), Team: query.Get("team"), Policy: query.Get("policy")}
+	report, err := s.service.SLAReport(r.Context(), filter)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, report)
+}
+
+// handleDiff summarizes what changed in the tracked repositories between the from and to dates
+func (s *Server) handleDiff(w http.ResponseWriter, r *http.Request) {
+	from, err := timeParameter(r, "from")
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	to, err := timeParameter(r, "to")
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	query := r.URL.Query()
+	filter := &models.DiffFilter{From: from, To: to, Repo: query.Get("repo"), RepoTag: query.Get("repo_tag")}
+	diff, err := s.service.Diff(r.Context(), filter)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, diff)
+}
+
+// handleListWebhooks lists the registered webhooks, without their secrets
+func (s *Server) handleListWebhooks(w http.ResponseWriter, r *http.Request) {
+	hooks, err := s.service.ListWebhooks(r.Context())
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, hooks)
+}
+
+// handleAddWebhook registers a webhook from a JSON body with the url, secret and events fields
+func (s *Server) handleAddWebhook(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		URL    string   `json:"url"`
+		Secret string   `json:"secret"`
+		Events []string `json:"events"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, errors.Join(errInvalidParameter, errors.New("body must be a JSON webhook")))
+		return
+	}
+
+	hook, err := s.service.AddWebhook(r.Context(), req.URL, req.Secret, req.Events)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	s.writeJSON(w, http.StatusCreated, hook)
+}
+
+// handleDeleteWebhook removes a webhook and its delivery logs
+func (s *Server) handleDeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		s.writeError(w, errInvalidParameter)
+		return
+	}
+	if err := s.service.DeleteWebhook(r.Context(), id); err != nil {
+		s.writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleListWebhookDeliveries lists the delivery logs of a webhook, newest first
+func (s *Server) handleListWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		s.writeError(w, errInvalidParameter)
+		return
+	}
+	page, perPage, err := pagination(r)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	deliveries, pagination, err := s.service.ListWebhookDeliveries(r.Context(), id, page, perPage)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	s.writeList(w, r, listResponse{Data: deliveries, Pagination: pagination}, time.Time{})
+}
+
+// handleListSubscriptions lists the label subscriptions, without their secrets
+func (s *Server) handleListSubscriptions(w http.ResponseWriter, r *http.Request) {
+	subs, err := s.service.ListSubscriptions(r.Context())
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, subs)
+}
+
+// subscriptionRequest is the JSON body adding a subscription, whose secret is write-only
+type subscriptionRequest struct {
+	models.Subscription
+	Secret string `json:"secret"`
+}
+
+// handleAddSubscription adds a label subscription from a JSON body with the label, repository,
+// channel, url and secret fields
+func (s *Server) handleAddSubscription(w http.ResponseWriter, r *http.Request) {
+	var req subscriptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, errors.Join(errInvalidParameter, errors.New("body must be a JSON subscription")))
+		return
+	}
+	sub := &req.Subscription
+	sub.ID = 0
+	sub.Secret = req.Secret
+
+	sub, err := s.service.AddSubscription(r.Context(), sub)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	s.writeJSON(w, http.StatusCreated, sub)
+}
+
+// handleDeleteSubscription removes a label subscription
+func (s *Server) handleDeleteSubscription(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		s.writeError(w, errInvalidParameter)
+		return
+	}
+	if err := s.service.DeleteSubscription(r.Context(), id); err != nil {
+		s.writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleListTriageRules lists the triage rules
+func (s *Server) handleListTriageRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := s.service.ListTriageRules(r.Context())
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, rules)
+}
+
+// handleAddTriageRules adds a triage rule from a JSON body, or every rule of a YAML body sent with
+// a YAML content type, returning the list of rules added
+func (s *Server) handleAddTriageRules(w http.ResponseWriter, r *http.Request) {
+	var rules []*models.TriageRule
+	if strings.Contains(r.Header.Get("Content-Type"), "yaml") {
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			s.writeError(w, errors.Join(errInvalidParameter, err))
+			return
+		}
+		if rules, err = service.ParseTriageRules(data); err != nil {
+			s.writeError(w, err)
+			return
+		}
+	} else {
+		rule := &models.TriageRule{}
+		if err := json.NewDecoder(r.Body).Decode(rule); err != nil {
+			s.writeError(w, errors.Join(errInvalidParameter, errors.New("body must be a JSON triage rule")))
+			return
+		}
+		rules = append(rules, rule)
+	}
+
+	added := make([]*models.TriageRule, 0, len(rules))
+	for _, rule := range rules {
+		rule.ID = 0
+		rule, err := s.service.AddTriageRule(r.Context(), rule)
+		if err != nil {
+			s.writeError(w, err)
+			return
+		}
+		added = append(added, rule)
+	}
+	s.writeJSON(w, http.StatusCreated, added)
+}
+
+// handleDeleteTriageRule removes a triage rule and its execution log
+func (s *Server) handleDeleteTriageRule(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		s.writeError(w, errInvalidParameter)
+		return
+	}
+	if err := s.service.DeleteTriageRule(r.Context(), id); err != nil {
+		s.writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleListTriageExecutions lists the items a triage rule acted on, newest first
+func (s *Server) handleListTriageExecutions(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		s.writeError(w, errInvalidParameter)
+		return
+	}
+	executions, err := s.service.ListTriageExecutions(r.Context(), id)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, executions)
+}
+
+// handleListProjects lists the projects linked to the tracked repositories
+func (s *Server) handleListProjects(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := &models.ProjectFilter{Repo: query.Get("repo"), RepoTag: query.Get("repo_tag")}
+	if value := query.Get("include_closed"); value != "" {
+		includeClosed, err := strconv.ParseBool(value)
+		if err != nil {
+			s.writeError(w, errors.Join(errInvalidParameter, errors.New("include_closed must be true or false")))
+			return
+		}
+		filter.IncludeClosed = includeClosed
+	}
+
+	projects, err := s.service.ListProjects(r.Context(), filter)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, projects)
+}
+
+// projectResponse is the body of /api/v1/projects/{owner}/{number}
+type projectResponse struct {
+	Project *models.Project       `json:"project"`
+	Data    []*models.ProjectItem `json:"data"`
+}
+
+// handleGetProject returns a project and its items, optionally only those in a column (status=In Review)
+func (s *Server) handleGetProject(w http.ResponseWriter, r *http.Request) {
+	number, err := strconv.Atoi(r.PathValue("number"))
+	if err != nil {
+		s.writeError(w, errors.Join(errInvalidParameter, errors.New("project number must be a number")))
+		return
+	}
+	project, items, err := s.service.GetProject(r.Context(), r.PathValue("owner"), number, r.URL.Query().Get("status"))
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, projectResponse{Project: project, Data: items})
+}
+
+// handleGetJob returns the status of a background job
+func (s *Server) handleGetJob(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		s.writeError(w, errInvalidParameter)
+		return
+	}
+	job, err := s.service.GetJob(r.Context(), id)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, job)
+}
+
+// handleListJobs lists background jobs, newest first
+func (s *Server) handleListJobs(w http.ResponseWriter, r *http.Request) {
+	page, perPage, err := pagination(r)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	query := r.URL.Query()
+	filter := &models.JobFilter{
+		Type:    query.Get("type"),
+		State:   query.Get("state"),
+		Target:  query.Get("target"),
+		Page:    page,
+		PerPage: perPage,
+	}
+	jobs, pagination, err := s.service.ListJobs(r.Context(), filter)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	s.writeList(w, r, listResponse{Data: jobs, Pagination: pagination}, time.Time{})
+}
+
+// handleCancelJob stops a queued or running background job, returning its final state
+func (s *Server) handleCancelJob(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		s.writeError(w, errInvalidParameter)
+		return
+	}
+	job, err := s.service.CancelJob(r.Context(), id)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, job)
+}
+
+// aggregateResponse is the body of /api/v1/pulls and /api/v1/issues with the aggregate parameter
+type aggregateResponse struct {
+	Aggregate string           `json:"aggregate"`
+	Data      []*models.Bucket `json:"data"`
+}
+
+// handleListPullRequests lists pull requests with the filters of 'ghrepos pr list'
+func (s *Server) handleListPullRequests(w http.ResponseWriter, r *http.Request) {
+	page, perPage, err := pagination(r)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	since, err := timeParameter(r, "since")
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	query := r.URL.Query()
+	filter := &models.PullRequestFilter{
+		State:             query.Get("state"),
+		Author:            query.Get("author"),
+		Repo:              query.Get("repo"),
+		RepoTag:           query.Get("repo_tag"),
+		Label:             query.Get("label"),
+		SortBy:            query.Get("sort"),
+		Direction:         query.Get("direction"),
+		Since:             since,
+		ExcludeBots:       query.Get("exclude_bots") == "true",
+		Association:       query.Get("association"),
+		Team:              query.Get("team"),
+		Jira:              query.Get("jira"),
+		OwnedByMe:         query.Get("owned_by_me") == "true",
+		OwnedByTeam:       query.Get("owned_by_team"),
+		Path:              query.Get("path"),
+		Size:              query.Get("size"),
+		Project:           query.Get("project"),
+		ProjectStatus:     query.Get("project_status"),
+		IncludeTombstoned: query.Get("include_tombstoned") == "true",
+		Cursor:            query.Get("cursor"),
+		Page:              page,
+		PerPage:           perPage,
+	}
+	if aggregate := query.Get("aggregate"); aggregate != "" {
+		buckets, err := s.service.AggregatePullRequests(r.Context(), filter, aggregate)
+		if err != nil {
+			s.writeError(w, err)
+			return
+		}
+		s.writeJSON(w, http.StatusOK, aggregateResponse{Aggregate: aggregate, Data: buckets})
+		return
+	}
+	prs, pagination, err := s.service.ListPullRequests(r.Context(), filter)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	var lastModified time.Time
+	for _, pr := range prs {
+		if pr.UpdatedAt.After(lastModified) {
+			lastModified = pr.UpdatedAt
+		}
+	}
+	var data interface{} = prs
+	if fields := query.Get("fields"); fields != "" {
+		if data, err = selectFields(prs, fields); err != nil {
+			s.writeError(w, err)
+			return
+		}
+	} else if query.Get("include_body") != "true" {
+		data = withoutBodies(prs, func(pr *models.PullRequest) { pr.Body = "" })
+	}
+	s.writeList(w, r, listResponse{Data: data, Pagination: pagination}, lastModified)
+}
+
+// handleListIssues lists issues with the filters of 'ghrepos issue list'
+func (s *Server) handleListIssues(w http.ResponseWriter, r *http.Request) {
+	page, perPage, err := pagination(r)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	since, err := timeParameter(r, "since")
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	query := r.URL.Query()
+	filter := &models.IssueFilter{
+		State:             query.Get("state"),
+		Author:            query.Get("author"),
+		Repo:              query.Get("repo"),
+		RepoTag:           query.Get("repo_tag"),
+		Label:             query.Get("label"),
+		SortBy:            query.Get("sort"),
+		Direction:         query.Get("direction"),
+		Since:             since,
+		ExcludeBots:       query.Get("exclude_bots") == "true",
+		Association:       query.Get("association"),
+		Team:              query.Get("team"),
+		Jira:              query.Get("jira"),
+		Project:           query.Get("project"),
+		ProjectStatus:     query.Get("project_status"),
+		IncludeTombstoned: query.Get("include_tombstoned") == "true",
+		Cursor:            query.Get("cursor"),
+		Page:              page,
+		PerPage:           perPage,
+	}
+	if aggregate := query.Get("aggregate"); aggregate != "" {
+		buckets, err := s.service.AggregateIssues(r.Context(), filter, aggregate)
+		if err != nil {
+			s.writeError(w, err)
+			return
+		}
+		s.writeJSON(w, http.StatusOK, aggregateResponse{Aggregate: aggregate, Data: buckets})
+		return
+	}
+	issues, pagination, err := s.service.ListIssues(r.Context(), filter)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	var lastModified time.Time
+	for _, issue := range issues {
+		if issue.UpdatedAt.After(lastModified) {
+			lastModified = issue.UpdatedAt
+		}
+	}
+	var data interface{} = issues
+	if fields := query.Get("fields"); fields != "" {
+		if data, err = selectFields(issues, fields); err != nil {
+			s.writeError(w, err)
+			return
+		}
+	} else if query.Get("include_body") != "true" {
+		data = withoutBodies(issues, func(issue *models.Issue) { issue.Body = "" })
+	}
+	s.writeList(w, r, listResponse{Data: data, Pagination: pagination}, lastModified)
+}
+
+// handleListDiscussions lists synced discussions with the filters of 'ghrepos discussion list'
+func (s *Server) handleListDiscussions(w http.ResponseWriter, r *http.Request) {
+	page, perPage, err := pagination(r)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	since, err := timeParameter(r, "since")
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	query := r.URL.Query()
+	filter := &models.DiscussionFilter{
+		State:      query.Get("state"),
+		Author:     query.Get("author"),
+		Repo:       query.Get("repo"),
+		RepoTag:    query.Get("repo_tag"),
+		Category:   query.Get("category"),
+		Unanswered: query.Get("unanswered") == "true",
+		Since:      since,
+		Page:       page,
+		PerPage:    perPage,
+	}
+	discussions, pagination, err := s.service.ListDiscussions(r.Context(), filter)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	s.writeList(w, r, listResponse{Data: discussions, Pagination: pagination}, time.Time{})
+}
+
+// handleListItems lists pull requests and issues together with the filters of 'ghrepos item list'
+func (s *Server) handleListItems(w http.ResponseWriter, r *http.Request) {
+	page, perPage, err := pagination(r)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	since, err := timeParameter(r, "since")
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	query := r.URL.Query()
+	filter := &models.ItemFilter{
+		Type:        query.Get("type"),
+		State:       query.Get("state"),
+		Author:      query.Get("author"),
+		Repo:        query.Get("repo"),
+		RepoTag:     query.Get("repo_tag"),
+		Label:       query.Get("label"),
+		ExcludeBots: query.Get("exclude_bots") == "true",
+		Association: query.Get("association"),
+		Team:        query.Get("team"),
+		Jira:        query.Get("jira"),
+		Direction:   query.Get("direction"),
+		Since:       since,
+		Cursor:      query.Get("cursor"),
+		Page:        page,
+		PerPage:     perPage,
+	}
+	items, pagination, err := s.service.ListItems(r.Context(), filter)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	s.writeList(w, r, listResponse{Data: items, Pagination: pagination}, time.Time{})
+}
+
+// handleAdminStats returns storage statistics, authorized by the admin API key of the X-Admin-Key header
+func (s *Server) handleAdminStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := s.service.GetAdminStats(r.Context(), r.Header.Get("X-Admin-Key"))
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, stats)
+}
+
+// handleAdminCompact compacts the database, authorized by the admin API key of the X-Admin-Key header
+func (s *Server) handleAdminCompact(w http.ResponseWriter, r *http.Request) {
+	result, err := s.service.CompactStorage(r.Context(), r.Header.Get("X-Admin-Key"))
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, result)
+}
+
+// handleAdminClear drops the stored data of a repository, authorized by the admin API key of the X-Admin-Key header
+func (s *Server) handleAdminClear(w http.ResponseWriter, r *http.Request) {
+	if err := s.service.ClearRepositoryData(r.Context(), r.Header.Get("X-Admin-Key"), r.PathValue("owner"), r.PathValue("name")); err != nil {
+		s.writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleListAudit lists audit log entries
+func (s *Server) handleListAudit(w http.ResponseWriter, r *http.Request) {
+	page, perPage, err := pagination(r)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	since, err := timeParameter(r, "since")
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	until, err := timeParameter(r, "until")
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	query := r.URL.Query()
+	filter := &models.AuditFilter{
+		Actor:   query.Get("actor"),
+		Action:  query.Get("action"),
+		Target:  query.Get("target"),
+		Since:   since,
+		Until:   until,
+		Page:    page,
+		PerPage: perPage,
+	}
+	entries, pagination, err := s.service.ListAudit(r.Context(), filter)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	s.writeList(w, r, listResponse{Data: entries, Pagination: pagination}, time.Time{})
+}
+
+// handleListJiraLinks lists the Jira issues referenced by pull requests and issues
+func (s *Server) handleListJiraLinks(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	links, err := s.service.ListJiraLinks(r.Context(), &models.JiraLinkFilter{
+		Key:     query.Get("key"),
+		Project: query.Get("project"),
+		Repo:    query.Get("repo"),
+		RepoTag: query.Get("repo_tag"),
+	})
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, links)
+}
+
+// handleLinkGraph returns the graph of references between the items of tracked repositories
+func (s *Server) handleLinkGraph(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	graph, err := s.service.LinkGraph(r.Context(), &models.LinkFilter{
+		Repo:    query.Get("repo"),
+		RepoTag: query.Get("repo_tag"),
+	})
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, graph)
+}
+
+// handleListChanges streams the changes recorded after the since sequence number as NDJSON, one
+// change per line. X-Next-Sequence holds the since value to pass for the following changes.
+func (s *Server) handleListChanges(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	var since int64
+	if value := query.Get("since"); value != "" {
+		var err error
+		if since, err = strconv.ParseInt(value, 10, 64); err != nil || since < 0 {
+			s.writeError(w, errors.Join(errInvalidParameter, errors.New("since must be a sequence number")))
+			return
+		}
+	}
+	limit := 0
+	if value := query.Get("limit"); value != "" {
+		var err error
+		if limit, err = strconv.Atoi(value); err != nil || limit < 1 {
+			s.writeError(w, errors.Join(errInvalidParameter, errors.New("limit must be a positive number")))
+			return
+		}
+	}
+
+	records, next, err := s.service.ListChanges(r.Context(), since, limit)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("X-Next-Sequence", strconv.FormatInt(next, 10))
+	w.WriteHeader(http.StatusOK)
+	encoder := json.NewEncoder(w)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			s.logger.Printf("Error writing response: %v", err)
+			return
+		}
+	}
+}
+
+// queryResponse is the body of /api/v1/query: the items matching a question and the
+// filter it was translated into, so clients can show how the question was understood
+type queryResponse struct {
+	Data       interface{}        `json:"data"`
+	Pagination *models.Pagination `json:"pagination"`
+	Filter     queryFilter        `json:"filter"`
+}
+
+// queryFilter is the filter a question was translated into, named like the list parameters
+type queryFilter struct {
+	Type    string `json:"type,omitempty"`
+	State   string `json:"state,omitempty"`
+	Author  string `json:"author,omitempty"`
+	Repo    string `json:"repo,omitempty"`
+	RepoTag string `json:"repo_tag,omitempty"`
+	Label   string `json:"label,omitempty"`
+	Team    string `json:"team,omitempty"`
+	Jira    string `json:"jira,omitempty"`
+	Since   string `json:"since,omitempty"`
+}
+
+// handleQuery answers a natural-language question about pull requests and issues
+func (s *Server) handleQuery(w http.ResponseWriter, r *http.Request) {
+	page, perPage, err := pagination(r)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	filter, items, pagination, err := s.service.QueryItems(r.Context(), r.URL.Query().Get("q"), page, perPage)
+	if err != nil {
+		if errors.Is(err, service.ErrQueryFailed) {
+			s.logger.Printf("Error translating query: %v", err)
+		}
+		s.writeError(w, err)
+		return
+	}
+	applied := queryFilter{
+		Type:    filter.Type,
+		State:   filter.State,
+		Author:  filter.Author,
+		Repo:    filter.Repo,
+		RepoTag: filter.RepoTag,
+		Label:   filter.Label,
+		Team:    filter.Team,
+		Jira:    filter.Jira,
+	}
+	if !filter.Since.IsZero() {
+		applied.Since = filter.Since.Format("2006-01-02")
+	}
+	s.writeJSON(w, http.StatusOK, queryResponse{Data: items, Pagination: pagination, Filter: applied})
+}
+
+// handleDiscover lists untracked repositories the GitHub user of the server owns, stars or
+// contributes to, optionally only those of some relations (relation=owner,starred)
+func (s *Server) handleDiscover(w http.ResponseWriter, r *http.Request) {
+	var relations []string
+	if value := r.URL.Query().Get("relation"); value != "" {
+		relations = strings.Split(value, ",")
+	}
+	repos, err := s.service.DiscoverRepositories(r.Context(), relations)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, repos)
+}
+
+// handleListWorkspaces lists workspaces; a workspace token only sees its own
+func (s *Server) handleListWorkspaces(w http.ResponseWriter, r *http.Request) {
+	workspaces, err := s.service.ListWorkspaces(r.Context())
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, workspaces)
+}
+
+// handleGetWorkspace returns a workspace
+func (s *Server) handleGetWorkspace(w http.ResponseWriter, r *http.Request) {
+	workspace, err := s.service.GetWorkspace(r.Context(), r.PathValue("workspace"))
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, workspace)
+}
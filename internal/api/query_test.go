@@ -0,0 +1,60 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+func TestQuery(t *testing.T) {
+	// A chat completions API translating every question to open pull requests by alice in repo
+	llm := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"choices":[{"message":{"content":"{\"type\":\"pull_request\",\"state\":\"open\",\"author\":\"alice\",\"repo\":\"repo\"}"}}]}`))
+	}))
+	defer llm.Close()
+
+	server, db := newTestServer(t, &config.Config{Query: config.QueryConfig{Backend: "openai", Endpoint: llm.URL}})
+	for _, pr := range []*models.PullRequest{
+		{RepositoryFullName: "org/repo", Number: 1, State: "open", UserLogin: "alice"},
+		{RepositoryFullName: "org/repo", Number: 2, State: "open", UserLogin: "bob"},
+	} {
+		if err := db.AddPullRequest(context.Background(), pr); err != nil {
+			t.Fatalf("AddPullRequest() error = %v", err)
+		}
+	}
+
+	status, body := get(t, server.URL+"/api/v1/query?q="+url.QueryEscape("open PRs by alice in repo"))
+	if status != http.StatusOK {
+		t.Fatalf("query status = %d, body %s", status, body)
+	}
+	var response struct {
+		Data       []*models.Item     `json:"data"`
+		Pagination *models.Pagination `json:"pagination"`
+		Filter     queryFilter        `json:"filter"`
+	}
+	if err := json.Unmarshal([]byte(body), &response); err != nil {
+		t.Fatalf("decode query: %v", err)
+	}
+	if len(response.Data) != 1 || response.Data[0].Number != 1 || response.Pagination == nil {
+		t.Errorf("query data = %s, want pull request 1", body)
+	}
+	want := queryFilter{Type: models.ItemTypePullRequest, State: "open", Author: "alice", Repo: "org/repo"}
+	if response.Filter != want {
+		t.Errorf("query filter = %+v, want %+v", response.Filter, want)
+	}
+
+	if status, _ := get(t, server.URL+"/api/v1/query"); status != http.StatusBadRequest {
+		t.Errorf("empty query status = %d, want 400", status)
+	}
+
+	unconfigured, _ := newTestServer(t, &config.Config{})
+	if status, _ := get(t, unconfigured.URL+"/api/v1/query?q=open+issues"); status != http.StatusNotFound {
+		t.Errorf("unconfigured query status = %d, want 404", status)
+	}
+}
@@ -0,0 +1,234 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// slackMaxSkew is how old a Slack request may be before it is rejected as a replay
+const slackMaxSkew = 5 * time.Minute
+
+// slackMaxBody bounds the size of a slash command request
+const slackMaxBody = 64 << 10
+
+// slackItems is the number of pull requests or issues a slash command response lists
+const slackItems = 10
+
+// slackUsage explains the slash command syntax
+const slackUsage = "Usage: `/ghrepos prs|issues [repo:owner/name] [tag:t] [state:open|closed|all] " +
+	"[author:login] [label:l] [team:t] [jira:KEY-1] [since:YYYY-MM-DD] [page:n]`"
+
+// slackFilterKeys are the filters a slash command accepts
+var slackFilterKeys = map[string]bool{
+	"repo": true, "tag": true, "state": true, "author": true, "label": true,
+	"team": true, "jira": true, "since": true, "page": true,
+}
+
+// slackMessage is a slash command response (https://api.slack.com/interactivity/slash-commands)
+type slackMessage struct {
+	ResponseType string       `json:"response_type"`
+	Text         string       `json:"text"`
+	Blocks       []slackBlock `json:"blocks,omitempty"`
+}
+
+// slackBlock is a Block Kit section or context block
+type slackBlock struct {
+	Type     string      `json:"type"`
+	Text     *slackText  `json:"text,omitempty"`
+	Elements []slackText `json:"elements,omitempty"`
+}
+
+// slackText is a Block Kit text object
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// slackEscaper escapes the control characters of Slack message formatting
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// handleSlackCommand answers /ghrepos slash commands such as "prs repo:owner/name state:open"
+// with the matching pull requests or issues. Requests are authenticated by their Slack signature.
+func (s *Server) handleSlackCommand(w http.ResponseWriter, r *http.Request) {
+	if s.config.SlackSigningSecret == "" {
+		s.writeJSON(w, http.StatusNotFound, errorResponse{Error: "Slack commands are not configured", Code: codeNotConfigured})
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, slackMaxBody))
+	if err != nil {
+		s.writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid request body", Code: codeInvalidRequest})
+		return
+	}
+	if err := verifySlackSignature(s.config.SlackSigningSecret, r.Header, body, time.Now()); err != nil {
+		s.writeJSON(w, http.StatusUnauthorized, errorResponse{Error: err.Error(), Code: codeUnauthorized})
+		return
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		s.writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid form", Code: codeInvalidRequest})
+		return
+	}
+
+	// Errors are shown to the user; any other status makes Slack report a failed command
+	message, err := s.slackResponse(r, form.Get("text"))
+	if err != nil {
+		message = &slackMessage{ResponseType: "ephemeral", Text: fmt.Sprintf("%s\n%s", err, slackUsage)}
+		if errorStatus(err) == http.StatusInternalServerError {
+			s.logger.Printf("Error handling Slack command: %v", err)
+			message.Text = "Something went wrong, please try again later"
+		}
+	}
+	// Slack reads the message itself, never enveloped
+	s.encodeJSON(w, http.StatusOK, message)
+}
+
+// verifySlackSignature checks the X-Slack-Signature of a request body
+// (https://api.slack.com/authentication/verifying-requests-from-slack)
+func verifySlackSignature(secret string, header http.Header, body []byte, now time.Time) error {
+	timestamp := header.Get("X-Slack-Request-Timestamp")
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return errors.New("missing request timestamp")
+	}
+	if skew := now.Sub(time.Unix(seconds, 0)); skew > slackMaxSkew || skew < -slackMaxSkew {
+		return errors.New("request timestamp is too old")
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "v0:%s:", timestamp)
+	mac.Write(body)
+	want := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(header.Get("X-Slack-Signature")), []byte(want)) {
+		return errors.New("invalid request signature")
+	}
+	return nil
+}
+
+// parseSlackCommand splits slash command text into its subcommand and key:value filters
+func parseSlackCommand(text string) (string, map[string]string, error) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return "", nil, errors.Join(errInvalidParameter, errors.New("missing command"))
+	}
+
+	filters := make(map[string]string)
+	for _, field := range fields[1:] {
+		key, value, ok := strings.Cut(field, ":")
+		if !ok || value == "" || !slackFilterKeys[strings.ToLower(key)] {
+			return "", nil, errors.Join(errInvalidParameter, fmt.Errorf("unknown filter %q", field))
+		}
+		filters[strings.ToLower(key)] = value
+	}
+	return strings.ToLower(fields[0]), filters, nil
+}
+
+// slackResponse runs a slash command and formats its result
+func (s *Server) slackResponse(r *http.Request, text string) (*slackMessage, error) {
+	command, filters, err := parseSlackCommand(text)
+	if err != nil {
+		return nil, err
+	}
+
+	page := 1
+	if value, ok := filters["page"]; ok {
+		if page, err = strconv.Atoi(value); err != nil || page < 1 {
+			return nil, errors.Join(errInvalidParameter, errors.New("page must be a positive number"))
+		}
+	}
+	var since time.Time
+	if value, ok := filters["since"]; ok {
+		if since, err = time.ParseInLocation("2006-01-02", value, time.Local); err != nil {
+			return nil, errors.Join(errInvalidParameter, errors.New("since must be YYYY-MM-DD"))
+		}
+	}
+	state := filters["state"]
+	if state == "" {
+		state = "open"
+	}
+
+	var (
+		kind       string
+		lines      []string
+		pagination *models.Pagination
+	)
+	switch command {
+	case "prs", "pulls", "pr":
+		kind = "pull requests"
+		var prs []*models.PullRequest
+		prs, pagination, err = s.service.ListPullRequests(r.Context(), &models.PullRequestFilter{
+			State: state, Author: filters["author"], Repo: filters["repo"], RepoTag: filters["tag"], Label: filters["label"],
+			Team: filters["team"], Jira: filters["jira"], Since: since, Page: page, PerPage: slackItems,
+		})
+		for _, pr := range prs {
+			status := strings.ToLower(pr.State)
+			if pr.Draft {
+				status = "draft"
+			} else if pr.ReviewDecision != "" {
+				status = strings.ToLower(strings.ReplaceAll(pr.ReviewDecision, "_", " "))
+			}
+			lines = append(lines, slackItemLine(pr.RepositoryFullName, pr.Number, pr.Title, pr.HTMLURL, pr.UserLogin, status))
+		}
+	case "issues", "issue":
+		kind = "issues"
+		var issues []*models.Issue
+		issues, pagination, err = s.service.ListIssues(r.Context(), &models.IssueFilter{
+			State: state, Author: filters["author"], Repo: filters["repo"], RepoTag: filters["tag"], Label: filters["label"],
+			Team: filters["team"], Jira: filters["jira"], Since: since, Page: page, PerPage: slackItems,
+		})
+		for _, issue := range issues {
+			lines = append(lines, slackItemLine(issue.RepositoryFullName, issue.Number, issue.Title, issue.HTMLURL, issue.UserLogin, strings.ToLower(issue.State)))
+		}
+	case "help":
+		return &slackMessage{ResponseType: "ephemeral", Text: slackUsage}, nil
+	default:
+		return nil, errors.Join(errInvalidParameter, fmt.Errorf("unknown command %q", command))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	title := fmt.Sprintf("*%d %s %s*", pagination.Total, state, kind)
+	if repo := filters["repo"]; repo != "" {
+		title += " in " + slackEscaper.Replace(repo)
+	}
+	message := &slackMessage{
+		ResponseType: "ephemeral",
+		Text:         strings.Trim(title, "*"),
+		Blocks:       []slackBlock{{Type: "section", Text: &slackText{Type: "mrkdwn", Text: title}}},
+	}
+	for _, line := range lines {
+		message.Blocks = append(message.Blocks, slackBlock{Type: "section", Text: &slackText{Type: "mrkdwn", Text: line}})
+	}
+	if pagination.Page < pagination.TotalPages {
+		message.Blocks = append(message.Blocks, slackBlock{Type: "context", Elements: []slackText{{
+			Type: "mrkdwn",
+			Text: fmt.Sprintf("Page %d of %d; add page:%d for more", pagination.Page, pagination.TotalPages, pagination.Page+1),
+		}}})
+	}
+	return message, nil
+}
+
+// slackItemLine formats a pull request or issue as a line of a Slack message
+func slackItemLine(repo string, number int, title, htmlURL, author, status string) string {
+	var b strings.Builder
+	ref := fmt.Sprintf("%s#%d", repo, number)
+	if htmlURL != "" {
+		fmt.Fprintf(&b, "<%s|%s>", htmlURL, ref)
+	} else {
+		b.WriteString(ref)
+	}
+	fmt.Fprintf(&b, " %s\n_by %s · %s_", slackEscaper.Replace(title), slackEscaper.Replace(author), status)
+	return b.String()
+}
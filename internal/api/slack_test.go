@@ -0,0 +1,91 @@
+package api
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// postSlackCommand sends a slash command signed with secret
+func postSlackCommand(t *testing.T, serverURL, secret, text string) (int, *slackMessage) {
+	t.Helper()
+	body := url.Values{"command": {"/ghrepos"}, "text": {text}, "user_name": {"alice"}}.Encode()
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "v0:%s:%s", timestamp, body)
+
+	req, _ := http.NewRequest(http.MethodPost, serverURL+"/api/v1/integrations/slack/command", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Slack-Request-Timestamp", timestamp)
+	req.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST slack command error = %v", err)
+	}
+	defer resp.Body.Close()
+
+	data, _ := io.ReadAll(resp.Body)
+	var message slackMessage
+	if resp.StatusCode == http.StatusOK {
+		if err := json.Unmarshal(data, &message); err != nil {
+			t.Fatalf("slack response %s: %v", data, err)
+		}
+	}
+	return resp.StatusCode, &message
+}
+
+func TestSlackCommand(t *testing.T) {
+	server, db := newTestServer(t, &config.Config{Server: config.ServerConfig{SlackSigningSecret: "slack-secret"}})
+	for number, state := range map[int]string{1: "open", 2: "open", 3: "closed"} {
+		pr := &models.PullRequest{RepositoryFullName: "org/repo", Number: number, Title: fmt.Sprintf("Fix <bug> %d", number), State: state, UserLogin: "alice", HTMLURL: fmt.Sprintf("https://github.com/org/repo/pull/%d", number)}
+		if err := db.AddPullRequest(context.Background(), pr); err != nil {
+			t.Fatalf("AddPullRequest() error = %v", err)
+		}
+	}
+
+	status, message := postSlackCommand(t, server.URL, "slack-secret", "prs repo:org/repo")
+	if status != http.StatusOK {
+		t.Fatalf("command status = %d", status)
+	}
+	if len(message.Blocks) != 3 || message.Blocks[0].Text.Text != "*2 open pull requests* in org/repo" {
+		t.Fatalf("command blocks = %+v, want a title and 2 pull requests", message.Blocks)
+	}
+	if line := message.Blocks[1].Text.Text; !strings.HasPrefix(line, "<https://github.com/org/repo/pull/") || !strings.Contains(line, "Fix &lt;bug&gt;") {
+		t.Errorf("pull request line = %q, want a link and an escaped title", line)
+	}
+
+	if _, message := postSlackCommand(t, server.URL, "slack-secret", "prs colour:red"); !strings.Contains(message.Text, "unknown filter") {
+		t.Errorf("invalid command text = %q, want the error and usage", message.Text)
+	}
+	if status, _ := postSlackCommand(t, server.URL, "wrong-secret", "prs"); status != http.StatusUnauthorized {
+		t.Errorf("wrongly signed command status = %d, want 401", status)
+	}
+}
+
+func TestSlackCommandNotConfigured(t *testing.T) {
+	server, _ := newTestServer(t, &config.Config{})
+	if status, _ := postSlackCommand(t, server.URL, "", "prs"); status != http.StatusNotFound {
+		t.Errorf("command status = %d, want 404 without a signing secret", status)
+	}
+}
+
+func TestVerifySlackSignature(t *testing.T) {
+	header := http.Header{}
+	header.Set("X-Slack-Request-Timestamp", "1700000000")
+	if err := verifySlackSignature("secret", header, []byte("text=prs"), time.Unix(1700000000, 0).Add(10*time.Minute)); err == nil {
+		t.Error("verifySlackSignature() accepted a stale request")
+	}
+}
@@ -0,0 +1,290 @@
+// Package bench measures the latency of the list endpoints of the API on each storage backend,
+// over synthetic repositories holding as many pull requests and issues as asked for, so that
+// performance regressions show up before a release.
+package bench
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/api"
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/db"
+	"github.com/siddontang/github-repos-management/internal/models"
+	"github.com/siddontang/github-repos-management/internal/service"
+)
+
+// Defaults of the size of the synthetic data and of the measurement
+const (
+	DefaultRepositories = 10
+	DefaultPullRequests = 1000
+	DefaultIssues       = 1000
+	DefaultRequests     = 100
+)
+
+// Endpoints are the list endpoints measured
+var Endpoints = []string{
+	"/api/v1/repositories",
+	"/api/v1/pulls?state=all&per_page=100",
+	"/api/v1/issues?state=all&per_page=100",
+	"/api/v1/items?state=all&per_page=100",
+	"/api/v1/pulls?state=open&label=bug&per_page=100",
+}
+
+// Synthetic authors and labels the items are spread over
+var (
+	authors = []string{"alice", "bob", "carol", "dave", "erin", "frank", "grace", "heidi", "ivan", "judy", "dependabot[bot]"}
+	labels  = []string{"bug", "enhancement", "documentation", "good first issue", "help wanted"}
+)
+
+// Options are the size of the synthetic data and of the measurement
+type Options struct {
+	Backends     []string // Database types to measure, defaults to every registered one
+	Repositories int
+	PullRequests int // Per repository
+	Issues       int // Per repository
+	Requests     int // Per endpoint
+}
+
+// withDefaults returns the options with the backends, repositories and requests defaulted when
+// unset; repositories may have no pull requests or issues
+func (o Options) withDefaults() Options {
+	if len(o.Backends) == 0 {
+		o.Backends = db.Providers()
+	}
+	if o.Repositories <= 0 {
+		o.Repositories = DefaultRepositories
+	}
+	if o.Requests <= 0 {
+		o.Requests = DefaultRequests
+	}
+	return o
+}
+
+// Result is the measurement of a backend
+type Result struct {
+	Backend   string
+	Populate  time.Duration // Time taken to store the synthetic data
+	Endpoints []*EndpointResult
+}
+
+// EndpointResult is the latency of the requests to an endpoint
+type EndpointResult struct {
+	Endpoint string
+	Requests int
+	Mean     time.Duration
+	P50      time.Duration
+	P95      time.Duration
+	P99      time.Duration
+	Max      time.Duration
+}
+
+// Run populates each backend with the synthetic data and measures the latency of the list
+// endpoints on it. Backends storing files write them to a temporary directory, removed afterwards.
+func Run(ctx context.Context, opts Options) ([]*Result, error) {
+	opts = opts.withDefaults()
+
+	dir, err := os.MkdirTemp("", "ghrepos-bench")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	var results []*Result
+	for _, backend := range opts.Backends {
+		result, err := runBackend(ctx, backend, dir, opts)
+		if err != nil {
+			return results, fmt.Errorf("%s: %w", backend, err)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// runBackend measures a single backend
+func runBackend(ctx context.Context, backend, dir string, opts Options) (*Result, error) {
+	start := time.Now()
+	fixture, err := NewFixture(ctx, backend, dir, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer fixture.Close()
+	result := &Result{Backend: backend, Populate: time.Since(start)}
+
+	for _, endpoint := range Endpoints {
+		latencies := make([]time.Duration, 0, opts.Requests)
+		for i := 0; i < opts.Requests; i++ {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			start := time.Now()
+			if err := fixture.Get(endpoint); err != nil {
+				return nil, err
+			}
+			latencies = append(latencies, time.Since(start))
+		}
+		result.Endpoints = append(result.Endpoints, summarize(endpoint, latencies))
+	}
+	return result, nil
+}
+
+// summarize computes the statistics of the latencies of an endpoint
+func summarize(endpoint string, latencies []time.Duration) *EndpointResult {
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	var total time.Duration
+	for _, latency := range latencies {
+		total += latency
+	}
+	percentile := func(p int) time.Duration {
+		return latencies[(len(latencies)-1)*p/100]
+	}
+	return &EndpointResult{
+		Endpoint: endpoint,
+		Requests: len(latencies),
+		Mean:     total / time.Duration(len(latencies)),
+		P50:      percentile(50),
+		P95:      percentile(95),
+		P99:      percentile(99),
+		Max:      latencies[len(latencies)-1],
+	}
+}
+
+// Fixture is the API over a backend populated with synthetic data, served without a listener
+type Fixture struct {
+	handler http.Handler
+	service *service.Service
+}
+
+// NewFixture opens a database of the backend, with its file if any in dir, populates it and
+// serves the API over it. The service runs offline, so nothing reaches GitHub.
+func NewFixture(ctx context.Context, backend, dir string, opts Options) (*Fixture, error) {
+	opts = opts.withDefaults()
+	cfg := &config.Config{
+		Database: config.DatabaseConfig{Type: backend, Path: filepath.Join(dir, backend+".db")},
+		GitHub:   config.GitHubConfig{Offline: true},
+	}
+	store, err := db.Open(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := Populate(ctx, store, opts.Repositories, opts.PullRequests, opts.Issues); err != nil {
+		store.Close()
+		return nil, err
+	}
+
+	logger := log.New(io.Discard, "", 0)
+	svc, err := service.NewServiceWithOptions(cfg, service.Options{DB: store, Logger: logger})
+	if err != nil {
+		store.Close()
+		return nil, err
+	}
+	return &Fixture{handler: api.New(svc, cfg.Server, logger), service: svc}, nil
+}
+
+// Get serves a GET request to an endpoint, failing unless it succeeds
+func (f *Fixture) Get(endpoint string) error {
+	w := httptest.NewRecorder()
+	f.handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, endpoint, nil))
+	if w.Code != http.StatusOK {
+		return fmt.Errorf("GET %s: status %d: %s", endpoint, w.Code, w.Body.String())
+	}
+	return nil
+}
+
+// Close closes the service and its database
+func (f *Fixture) Close() error {
+	return f.service.Close()
+}
+
+// Populate stores the given number of synthetic repositories, each with the given number of pull
+// requests and issues spread over a few authors, states and labels. The data only depends on the
+// numbers, so runs are comparable.
+func Populate(ctx context.Context, store db.DB, repositories, pullRequests, issues int) error {
+	now := time.Now().UTC().Truncate(time.Second)
+	for _, name := range labels {
+		if err := store.AddLabel(ctx, &models.Label{Name: name}); err != nil {
+			return err
+		}
+	}
+
+	for r := 0; r < repositories; r++ {
+		repo := &models.Repository{
+			Owner:     "bench",
+			Name:      fmt.Sprintf("repo-%d", r),
+			FullName:  fmt.Sprintf("bench/repo-%d", r),
+			HTMLURL:   fmt.Sprintf("https://github.com/bench/repo-%d", r),
+			CreatedAt: now,
+			UpdatedAt: now,
+			// Synced now, so nothing is refreshed while measuring
+			LastSyncedAt:         now,
+			MetadataSyncedAt:     now,
+			PullRequestsSyncedAt: now,
+			IssuesSyncedAt:       now,
+		}
+		if err := store.AddRepository(ctx, repo); err != nil {
+			return err
+		}
+
+		err := store.Batch(ctx, func(tx db.Tx) error {
+			for n := 1; n <= pullRequests; n++ {
+				pr := &models.PullRequest{
+					RepositoryFullName: repo.FullName,
+					Number:             n,
+					Title:              fmt.Sprintf("Change %d of %s", n, repo.Name),
+					State:              itemState(n),
+					HTMLURL:            fmt.Sprintf("%s/pull/%d", repo.HTMLURL, n),
+					UserLogin:          authors[n%len(authors)],
+					UserIsBot:          n%len(authors) == len(authors)-1,
+					CreatedAt:          now.Add(-time.Duration(pullRequests-n+1) * time.Hour),
+					UpdatedAt:          now.Add(-time.Duration(pullRequests-n) * time.Minute),
+				}
+				if _, err := tx.UpsertPullRequest(ctx, pr); err != nil {
+					return err
+				}
+				if err := tx.AddPullRequestLabel(ctx, repo.FullName, n, labels[n%len(labels)]); err != nil {
+					return err
+				}
+			}
+			for n := 1; n <= issues; n++ {
+				number := pullRequests + n
+				issue := &models.Issue{
+					RepositoryFullName: repo.FullName,
+					Number:             number,
+					Title:              fmt.Sprintf("Problem %d in %s", n, repo.Name),
+					State:              itemState(n),
+					HTMLURL:            fmt.Sprintf("%s/issues/%d", repo.HTMLURL, number),
+					UserLogin:          authors[(n+1)%len(authors)],
+					CreatedAt:          now.Add(-time.Duration(issues-n+1) * time.Hour),
+					UpdatedAt:          now.Add(-time.Duration(issues-n) * time.Minute),
+				}
+				if _, err := tx.UpsertIssue(ctx, issue); err != nil {
+					return err
+				}
+				if err := tx.AddIssueLabel(ctx, repo.FullName, number, labels[n%len(labels)]); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to populate %s: %w", repo.FullName, err)
+		}
+	}
+	return nil
+}
+
+// itemState spreads the items over states, a third of them open
+func itemState(n int) string {
+	if n%3 == 0 {
+		return "open"
+	}
+	return "closed"
+}
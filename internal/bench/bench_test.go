@@ -0,0 +1,69 @@
+package bench
+
+import (
+	"context"
+	"testing"
+
+	"github.com/siddontang/github-repos-management/internal/db"
+)
+
+func TestRun(t *testing.T) {
+	results, err := Run(context.Background(), Options{Repositories: 2, PullRequests: 20, Issues: 10, Requests: 5})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(results) != len(db.Providers()) {
+		t.Fatalf("results = %d, want one per backend %v", len(results), db.Providers())
+	}
+	for _, result := range results {
+		if len(result.Endpoints) != len(Endpoints) {
+			t.Errorf("%s endpoints = %d, want %d", result.Backend, len(result.Endpoints), len(Endpoints))
+		}
+		for _, endpoint := range result.Endpoints {
+			if endpoint.Requests != 5 || endpoint.P50 > endpoint.P99 || endpoint.P99 > endpoint.Max {
+				t.Errorf("%s %s = %+v", result.Backend, endpoint.Endpoint, endpoint)
+			}
+		}
+	}
+
+	if _, err := Run(context.Background(), Options{Backends: []string{"cassandra"}}); err == nil {
+		t.Error("Run() with an unknown backend should fail")
+	}
+}
+
+// BenchmarkList measures each list endpoint on each backend, over 10 repositories of 1000 pull
+// requests and 1000 issues; compare runs with benchstat to catch regressions
+func BenchmarkList(b *testing.B) {
+	ctx := context.Background()
+	for _, backend := range db.Providers() {
+		fixture, err := NewFixture(ctx, backend, b.TempDir(), Options{PullRequests: DefaultPullRequests, Issues: DefaultIssues})
+		if err != nil {
+			b.Fatalf("NewFixture(%s) error = %v", backend, err)
+		}
+		for _, endpoint := range Endpoints {
+			b.Run(backend+endpoint, func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					if err := fixture.Get(endpoint); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+		fixture.Close()
+	}
+}
+
+func BenchmarkPopulate(b *testing.B) {
+	ctx := context.Background()
+	for _, backend := range db.Providers() {
+		b.Run(backend, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				fixture, err := NewFixture(ctx, backend, b.TempDir(), Options{Repositories: 1, PullRequests: DefaultPullRequests, Issues: DefaultIssues})
+				if err != nil {
+					b.Fatal(err)
+				}
+				fixture.Close()
+			}
+		})
+	}
+}
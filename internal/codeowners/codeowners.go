@@ -0,0 +1,108 @@
+// Package codeowners parses CODEOWNERS files and finds the owners of paths with the
+// gitignore-style patterns GitHub uses: the last matching rule decides the owners.
+package codeowners
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// Parse parses the rules of a CODEOWNERS file, skipping comments and blank lines. A rule
+// without owners is kept, since it takes ownership of its paths away from earlier rules.
+func Parse(content string) []models.CodeOwnersRule {
+	var rules []models.CodeOwnersRule
+	for _, line := range strings.Split(content, "\n") {
+		if i := strings.Index(line, "#"); i >= 0 && (i == 0 || line[i-1] != '\\') {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		rule := models.CodeOwnersRule{Pattern: strings.ReplaceAll(fields[0], `\#`, "#")}
+		for _, owner := range fields[1:] {
+			rule.Owners = append(rule.Owners, strings.TrimPrefix(owner, "@"))
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// Matcher finds the owners of paths
+type Matcher struct {
+	patterns []*regexp.Regexp
+	owners   [][]string
+}
+
+// Compile compiles rules into a matcher. Rules with invalid patterns never match.
+func Compile(rules []models.CodeOwnersRule) *Matcher {
+	m := &Matcher{}
+	for _, rule := range rules {
+		pattern, err := CompilePattern(rule.Pattern)
+		if err != nil {
+			continue
+		}
+		m.patterns = append(m.patterns, pattern)
+		m.owners = append(m.owners, rule.Owners)
+	}
+	return m
+}
+
+// Owners returns the owners of a path, as user logins, org/team slugs or emails. It is nil
+// when no rule matches the path or the matching rule has no owners.
+func (m *Matcher) Owners(path string) []string {
+	path = strings.TrimPrefix(path, "/")
+	for i := len(m.patterns) - 1; i >= 0; i-- {
+		if m.patterns[i].MatchString(path) {
+			return m.owners[i]
+		}
+	}
+	return nil
+}
+
+// CompilePattern compiles a CODEOWNERS pattern, such as docs/** or *.go, into a regular
+// expression matching the paths it covers, relative to the repository root
+func CompilePattern(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile(translate(pattern))
+}
+
+// translate converts a CODEOWNERS pattern into a regular expression matching file paths.
+// Patterns containing a slash other than a trailing one are relative to the repository
+// root, others match at any depth, and patterns matching a directory match all it contains.
+func translate(pattern string) string {
+	dirOnly := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	var b strings.Builder
+	if anchored {
+		b.WriteString("^")
+	} else {
+		b.WriteString("^(?:.*/)?")
+	}
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case pattern[i] == '*':
+			b.WriteString("[^/]*")
+		case pattern[i] == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	if dirOnly {
+		b.WriteString("/.*$")
+	} else {
+		b.WriteString("(?:/.*)?$")
+	}
+	return b.String()
+}
@@ -0,0 +1,37 @@
+package codeowners
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOwners(t *testing.T) {
+	m := Compile(Parse(`# Default owners
+*       @org/core
+
+*.go    @gopher  # Go files
+/docs/  @org/docs writer@example.com
+apps/   @app-team
+/build/logs
+**/testdata/** @qa
+`))
+
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"README.md", []string{"org/core"}},
+		{"cmd/main.go", []string{"gopher"}},
+		{"docs/guide/intro.md", []string{"org/docs", "writer@example.com"}},
+		{"src/docs/intro.md", []string{"org/core"}},
+		{"apps/web/index.js", []string{"app-team"}},
+		{"services/apps/api.js", []string{"app-team"}},
+		{"build/logs/out.txt", nil},
+		{"pkg/testdata/case.json", []string{"qa"}},
+	}
+	for _, tt := range tests {
+		if got := m.Owners(tt.path); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Owners(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
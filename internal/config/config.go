@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -12,39 +13,351 @@ import (
 // Database types
 const (
 	DBTypeFile   = "file"
+	DBTypeMemory = "memory"
 	DBTypeSQLite = "sqlite"
 	DBTypeMySQL  = "mysql"
 )
 
 // Config represents the application configuration
 type Config struct {
-	Database DatabaseConfig `yaml:"database"`
-	GitHub   GitHubConfig   `yaml:"github"`
-	Logging  LoggingConfig  `yaml:"logging"`
+	Database      DatabaseConfig      `yaml:"database"`
+	Cache         CacheConfig         `yaml:"cache"`
+	Retention     RetentionConfig     `yaml:"retention"`
+	GitHub        GitHubConfig        `yaml:"github"`
+	Logging       LoggingConfig       `yaml:"logging"`
+	Notifications NotificationsConfig `yaml:"notifications"`
+	Admin         AdminConfig         `yaml:"admin"`
+	SSO           SSOConfig           `yaml:"sso"`
+	Jobs          JobsConfig          `yaml:"jobs"`
+	Server        ServerConfig        `yaml:"server"`
+	Jira          JiraConfig          `yaml:"jira"`
+	Query         QueryConfig         `yaml:"query"`
+	Compliance    ComplianceConfig    `yaml:"compliance"`
+	CodeOwners    CodeOwnersConfig    `yaml:"code_owners"`
+	ReviewQueue   ReviewQueueConfig   `yaml:"review_queue"`
+	SLA           SLAConfig           `yaml:"sla"`
+	BusinessHours BusinessHoursConfig `yaml:"business_hours"`
+	Duplicates    DuplicatesConfig    `yaml:"duplicates"`
+	ReleaseNotes  ReleaseNotesConfig  `yaml:"release_notes"`
+	Warehouse     WarehouseConfig     `yaml:"warehouse"`
+	Tracing       TracingConfig       `yaml:"tracing"`
+	// Teams maps a team name to the logins of its members, used by the team filter
+	Teams map[string][]string `yaml:"teams"`
 }
 
 // DatabaseConfig represents the database configuration
 type DatabaseConfig struct {
-	Type string `yaml:"type"` // file, sqlite, or mysql
+	Type string `yaml:"type"` // A registered backend: file or memory (sqlite and mysql are planned)
 	Path string `yaml:"path"` // For file or SQLite
+	// ReadOnly opens the database without writing to it, so it can be read while a
+	// server has it open. Only the file backend supports it.
+	ReadOnly bool `yaml:"read_only,omitempty"`
+	// Indent writes the file backend as indented JSON, easier to read by hand but larger
+	Indent bool `yaml:"indent,omitempty"`
 	// MySQL configuration (for future use)
 	Host     string `yaml:"host,omitempty"`
 	Port     int    `yaml:"port,omitempty"`
 	Username string `yaml:"username,omitempty"`
 	Password string `yaml:"password,omitempty"`
-	Database string `yaml:"database,omitempty"`
+	// PasswordFile holds the password, replacing Password when set
+	PasswordFile string `yaml:"password_file,omitempty"`
+	Database     string `yaml:"database,omitempty"`
+}
+
+// CacheConfig represents the limits and expiry of the in-memory data kept by the database.
+// Zero values mean unlimited and never expiring.
+type CacheConfig struct {
+	MaxRepositories       int   `yaml:"max_repositories"`
+	MaxItemsPerRepository int   `yaml:"max_items_per_repository"` // Applies to pull requests and issues separately
+	MaxMemoryBytes        int64 `yaml:"max_memory_bytes"`         // Estimated size of pull requests and issues
+
+	// Data older than its TTL is re-fetched from GitHub when it is read
+	RepositoryTTL  time.Duration `yaml:"repository_ttl"`
+	PullRequestTTL time.Duration `yaml:"pull_request_ttl"`
+	IssueTTL       time.Duration `yaml:"issue_ttl"`
+}
+
+// RetentionConfig represents how long closed pull requests and issues are kept. Zero values keep
+// them forever. 'ghrepos serve' applies the policy every Interval, and 'ghrepos admin compact'
+// whenever it runs.
+type RetentionConfig struct {
+	// ClosedMaxAge drops the items closed longer ago, such as 4320h for 180 days
+	ClosedMaxAge time.Duration `yaml:"closed_max_age"`
+	// MaxClosedPerRepository keeps only the most recently closed pull requests and issues of each
+	// repository, each up to this many
+	MaxClosedPerRepository int           `yaml:"max_closed_per_repository"`
+	Interval               time.Duration `yaml:"interval"` // 0 uses the default of 24h
 }
 
 // GitHubConfig represents the GitHub configuration
 type GitHubConfig struct {
 	RefreshInterval time.Duration `yaml:"refresh_interval"`
 	ItemsPerFetch   int           `yaml:"items_per_fetch"`
+	// MaxConcurrentCalls bounds the gh processes running at once; further calls wait (0 uses the default)
+	MaxConcurrentCalls int `yaml:"max_concurrent_calls"`
+	// Tokens are rotated between, moving to the token with the most requests left when the
+	// current one has TokenMinRemaining or fewer (0 uses the default). When empty gh uses its own credentials.
+	Tokens            []string `yaml:"tokens"`
+	TokenMinRemaining int      `yaml:"token_min_remaining"`
+	// TokenFile lists more tokens, one per line
+	TokenFile string `yaml:"token_file"`
+	// TrackStarred tracks the repositories the authenticated user stars, and untracks those
+	// tracked this way once unstarred, on every scheduled sync
+	TrackStarred bool `yaml:"track_starred"`
+	// SyncAlerts syncs the open Dependabot and code scanning alerts of each repository, which
+	// needs a token with access to security alerts
+	SyncAlerts bool `yaml:"sync_alerts"`
+	// SyncCommits syncs the commits of each repository's default branch made within CommitLookback
+	// (0 uses the default of 30 days); older commits are dropped
+	SyncCommits    bool          `yaml:"sync_commits"`
+	CommitLookback time.Duration `yaml:"commit_lookback"`
+	// SyncPullRequestFiles syncs the paths changed by each pull request, at most 100, for the path
+	// filter. They are also synced when code owners are enabled.
+	SyncPullRequestFiles bool `yaml:"sync_pull_request_files"`
+	// SyncProjects syncs the GitHub projects linked to each repository and the Status column of
+	// its recently updated issues and pull requests; the token needs the read:project scope
+	SyncProjects bool `yaml:"sync_projects"`
+	// SyncLabels syncs the label set and issue templates of each repository for the label registry
+	SyncLabels bool `yaml:"sync_labels"`
+	// Offline never contacts GitHub: commands are served from the local store only, and those
+	// needing GitHub, such as refreshing or adding repositories, fail
+	Offline bool `yaml:"offline"`
+	// Replay serves GitHub from the fixtures 'ghrepos record' saved in this directory instead of
+	// running gh, so that syncs are deterministic; repositories not recorded are not found
+	Replay string `yaml:"replay"`
+}
+
+// NotificationsConfig represents the notification configuration
+type NotificationsConfig struct {
+	Slack []SlackConfig `yaml:"slack"`
+}
+
+// NotificationRoute selects the events delivered to a notifier.
+// Empty lists match everything.
+type NotificationRoute struct {
+	Events       []string `yaml:"events,omitempty"`       // e.g. pull_request.opened, sync.failed
+	Repositories []string `yaml:"repositories,omitempty"` // owner/name
+	Labels       []string `yaml:"labels,omitempty"`       // for issue.labeled events
+}
+
+// SlackConfig represents a Slack incoming webhook notifier
+type SlackConfig struct {
+	WebhookURL        string `yaml:"webhook_url"`
+	Channel           string `yaml:"channel,omitempty"`
+	NotificationRoute `yaml:",inline"`
+}
+
+// AdminConfig represents the configuration of the admin operations
+type AdminConfig struct {
+	// APIKey must be presented to use the admin operations. Without it they are only allowed to
+	// the CLI on the local database, never over the HTTP API.
+	APIKey string `yaml:"api_key"`
+}
+
+// SSOConfig represents the login of users through an identity provider. Logged in users
+// get a session token that attributes their changes in the audit log.
+type SSOConfig struct {
+	Provider     string   `yaml:"provider"` // oidc or github; empty disables logins
+	Issuer       string   `yaml:"issuer"`   // OIDC issuer URL, such as https://accounts.google.com
+	ClientID     string   `yaml:"client_id"`
+	ClientSecret string   `yaml:"client_secret"`
+	Scopes       []string `yaml:"scopes,omitempty"`
+	// SessionSecret signs session tokens; keep it out of the file with a secret reference
+	SessionSecret string        `yaml:"session_secret"`
+	SessionTTL    time.Duration `yaml:"session_ttl"`
+	// Required rejects commands run without a valid session
+	Required bool `yaml:"required"`
+	// When any is set, only matching users can log in: GitHub logins or OIDC emails, members of
+	// GitHub organizations, or OIDC users with a verified email in a domain
+	AllowedUsers   []string `yaml:"allowed_users,omitempty"`
+	AllowedOrgs    []string `yaml:"allowed_orgs,omitempty"`
+	AllowedDomains []string `yaml:"allowed_domains,omitempty"`
+}
+
+// JobsConfig represents the configuration of the background job queue.
+// Zero values use the defaults.
+type JobsConfig struct {
+	Workers     int           `yaml:"workers"`      // Jobs running at once
+	MaxAttempts int           `yaml:"max_attempts"` // Runs of a failing job before it is given up
+	RetryDelay  time.Duration `yaml:"retry_delay"`  // Wait before the first retry, growing with each attempt
+}
+
+// JiraConfig represents the detection of Jira issue keys, such as PROJ-123, in pull requests and issues
+type JiraConfig struct {
+	// BaseURL links keys to issues, such as https://example.atlassian.net
+	BaseURL string `yaml:"base_url"`
+	// Projects restricts detection to these project keys; empty detects any key
+	Projects []string `yaml:"projects,omitempty"`
+}
+
+// Warehouse kinds
+const (
+	WarehouseBigQuery   = "bigquery"
+	WarehouseClickHouse = "clickhouse"
+)
+
+// WarehouseConfig represents the data warehouse that 'ghrepos serve' pushes repositories, pull
+// requests and issues to every Interval, replacing the tables each time
+type WarehouseConfig struct {
+	Kind        string           `yaml:"kind"`         // bigquery or clickhouse; empty disables the sink
+	Interval    time.Duration    `yaml:"interval"`     // 0 uses the default of 6h
+	TablePrefix string           `yaml:"table_prefix"` // Prepended to the table names, such as ghrepos_
+	BigQuery    BigQueryConfig   `yaml:"bigquery"`
+	ClickHouse  ClickHouseConfig `yaml:"clickhouse"`
+}
+
+// BigQueryConfig represents a BigQuery dataset, loaded with the bq CLI, which must be installed
+// and authenticated
+type BigQueryConfig struct {
+	Dataset string `yaml:"dataset"` // project:dataset, or dataset of the default project
+	Command string `yaml:"command"` // Path of the bq CLI, bq by default
+}
+
+// ClickHouseConfig represents a ClickHouse database, loaded over its HTTP interface
+type ClickHouseConfig struct {
+	URL      string `yaml:"url"` // Such as http://localhost:8123
+	Database string `yaml:"database"`
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+}
+
+// TracingConfig represents the OpenTelemetry collector spans of HTTP requests, syncs, storage
+// operations and gh calls are exported to over OTLP/HTTP
+type TracingConfig struct {
+	Endpoint    string            `yaml:"endpoint"`     // Such as http://localhost:4318; empty disables tracing
+	ServiceName string            `yaml:"service_name"` // ghrepos by default
+	SampleRatio float64           `yaml:"sample_ratio"` // Fraction of traces recorded; 0 records all
+	Headers     map[string]string `yaml:"headers"`      // Sent with each export, such as an API key
+}
+
+// ComplianceConfig represents the capture of repository settings and the policy they are checked against
+type ComplianceConfig struct {
+	// Enabled captures the settings of each repository when it is synced, at most once per SnapshotInterval
+	Enabled          bool             `yaml:"enabled"`
+	SnapshotInterval time.Duration    `yaml:"snapshot_interval"` // 0 uses the default of 24h
+	Policy           CompliancePolicy `yaml:"policy"`
+}
+
+// CompliancePolicy lists the expected repository settings. Unset rules are not checked.
+type CompliancePolicy struct {
+	DefaultBranch        string   `yaml:"default_branch,omitempty"`
+	RequireProtection    *bool    `yaml:"require_protection,omitempty"` // Protection of the default branch
+	MinApprovals         int      `yaml:"min_approvals,omitempty"`
+	RequireStatusChecks  *bool    `yaml:"require_status_checks,omitempty"`
+	EnforceAdmins        *bool    `yaml:"enforce_admins,omitempty"`
+	AllowForcePushes     *bool    `yaml:"allow_force_pushes,omitempty"`
+	RequireLinearHistory *bool    `yaml:"require_linear_history,omitempty"`
+	DeleteBranchOnMerge  *bool    `yaml:"delete_branch_on_merge,omitempty"`
+	AllowedMergeMethods  []string `yaml:"allowed_merge_methods,omitempty"` // merge, squash, rebase; others must be disabled
+}
+
+// CodeOwnersConfig represents the sync of CODEOWNERS files and of the files changed by pull
+// requests, used to filter pull requests by the owners of the paths they touch
+type CodeOwnersConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// User is the GitHub login matched by the owned-by-me filter, directly or through the teams listing it
+	User string `yaml:"user"`
+}
+
+// ReviewQueueConfig represents the queue of pull requests waiting for a reviewer, shown by
+// 'ghrepos pr queue'. Zero thresholds use the defaults of 24h to be due soon and 48h to be overdue,
+// counted in business hours when they are enabled.
+type ReviewQueueConfig struct {
+	// User is the reviewer whose queue is shown when none is given, code_owners.user when unset
+	User    string        `yaml:"user"`
+	DueSoon time.Duration `yaml:"due_soon"`
+	Overdue time.Duration `yaml:"overdue"`
+}
+
+// DuplicatesConfig represents the detection of probable duplicate open issues within a repository
+// after each sync. A zero threshold uses the default of 0.5 and a zero shingle size the default of
+// 2 words.
+type DuplicatesConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Threshold is the lowest similarity, from 0 to 1, of the titles and bodies of two issues flagged as duplicates
+	Threshold   float64 `yaml:"threshold"`
+	ShingleSize int     `yaml:"shingle_size"`
+}
+
+// ReleaseNotesConfig represents how 'ghrepos release notes' groups merged pull requests by label.
+// Without sections, features, bug fixes and documentation are told apart by their usual labels.
+type ReleaseNotesConfig struct {
+	Sections []ReleaseNotesSection `yaml:"sections"`
+	// Exclude leaves out pull requests with any of these labels, such as "skip-changelog"
+	Exclude []string `yaml:"exclude"`
+}
+
+// ReleaseNotesSection lists the pull requests with any of its labels, matched ignoring case and
+// any scope prefix such as "type/" or "kind:". A pull request goes to its first matching section.
+type ReleaseNotesSection struct {
+	Title  string   `yaml:"title"`
+	Labels []string `yaml:"labels"`
+}
+
+// SLA policy targets
+const (
+	SLATargetFirstReview = "first_review" // Pull requests only
+	SLATargetClose       = "close"
+)
+
+// SLAConfig lists the policies the open pull requests and issues are checked against by 'ghrepos sla report'
+type SLAConfig struct {
+	Policies []SLAPolicy `yaml:"policies"`
+}
+
+// SLAPolicy requires the pull requests or issues it covers to reach a target within a time of being
+// opened; for first reviews, of being ready for review
+type SLAPolicy struct {
+	Name         string        `yaml:"name"`
+	Type         string        `yaml:"type"`                   // pull_request or issue
+	Target       string        `yaml:"target"`                 // SLATargetFirstReview or SLATargetClose
+	Within       time.Duration `yaml:"within"`                 // In business hours when they are enabled
+	Label        string        `yaml:"label,omitempty"`        // Only items with this label
+	Repositories []string      `yaml:"repositories,omitempty"` // Only these repositories
+}
+
+// BusinessHoursConfig represents the working time SLA deadlines, review queue waits and lead-time
+// analytics are measured in. When disabled, they are measured in wall-clock time.
+type BusinessHoursConfig struct {
+	Enabled   bool     `yaml:"enabled"`
+	Timezone  string   `yaml:"timezone"`   // IANA name, UTC by default
+	WorkHours string   `yaml:"work_hours"` // HH:MM-HH:MM, 09:00-17:00 by default
+	Weekends  []string `yaml:"weekends"`   // Days off each week, saturday and sunday by default
+	Holidays  []string `yaml:"holidays"`   // Dates off, YYYY-MM-DD
+}
+
+// QueryConfig represents the language model translating natural-language questions into
+// filters for /api/v1/query. Questions are rejected when no backend is set.
+type QueryConfig struct {
+	Backend  string        `yaml:"backend"`  // openai, for any OpenAI-compatible chat completions API
+	Endpoint string        `yaml:"endpoint"` // API base URL, https://api.openai.com/v1 by default
+	Model    string        `yaml:"model"`
+	APIKey   string        `yaml:"api_key"`
+	Timeout  time.Duration `yaml:"timeout"`
+}
+
+// ServerConfig represents the HTTP server of 'ghrepos serve'
+type ServerConfig struct {
+	Addr string `yaml:"addr"` // Listen address, 127.0.0.1:8080 by default
+	// SlackSigningSecret verifies Slack slash commands; the command endpoint is disabled without it
+	SlackSigningSecret string `yaml:"slack_signing_secret,omitempty"`
+	// Envelope wraps every JSON response in {"data", "pagination", "error"}, errors carrying a code
+	Envelope bool `yaml:"envelope,omitempty"`
 }
 
 // LoggingConfig represents the logging configuration
 type LoggingConfig struct {
-	Level  string `yaml:"level"`
-	Format string `yaml:"format"`
+	Level  string          `yaml:"level"`  // debug, info, warn or error
+	Format string          `yaml:"format"` // text or json
+	Access AccessLogConfig `yaml:"access"`
+}
+
+// AccessLogConfig represents the access log 'ghrepos serve' writes, one line per request at the
+// info level. Failed requests are always logged; successful requests to the routes of Sample,
+// such as "GET /api/v1/pulls", are logged at the given fraction, 0 dropping them all.
+type AccessLogConfig struct {
+	Disabled bool               `yaml:"disabled"`
+	Sample   map[string]float64 `yaml:"sample"`
 }
 
 // DefaultConfig returns the default configuration
@@ -65,26 +378,33 @@ func DefaultConfig() *Config {
 	}
 }
 
-// Load loads the configuration from the specified file
+// Load loads the configuration from the specified file. ${NAME} in the file is replaced
+// with the environment variable NAME, and credentials may refer to secrets kept elsewhere
+// (file:, vault: and awssm: references).
 func Load(configPath string) (*Config, error) {
 	config := DefaultConfig()
 
 	// If no config file is specified, use environment variables
 	if configPath == "" {
-		return loadFromEnv(config)
-	}
+		if _, err := loadFromEnv(config); err != nil {
+			return nil, err
+		}
+	} else {
+		// Read the config file
+		data, err := os.ReadFile(configPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file: %w", err)
+		}
 
-	// Read the config file
-	data, err := os.ReadFile(configPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
+		// Parse the config file
+		if err := yaml.Unmarshal(expandEnv(data), config); err != nil {
+			return nil, fmt.Errorf("failed to parse config file: %w", err)
+		}
 	}
 
-	// Parse the config file
-	if err := yaml.Unmarshal(data, config); err != nil {
-		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	if err := resolveSecrets(config); err != nil {
+		return nil, err
 	}
-
 	return config, nil
 }
 
@@ -110,6 +430,73 @@ func loadFromEnv(config *Config) (*Config, error) {
 		}
 	}
 
+	if maxCallsStr := os.Getenv("GHREPOS_MAX_CONCURRENT_CALLS"); maxCallsStr != "" {
+		if maxCalls, err := strconv.Atoi(maxCallsStr); err == nil && maxCalls > 0 {
+			config.GitHub.MaxConcurrentCalls = maxCalls
+		}
+	}
+
+	if tokenFile := os.Getenv("GHREPOS_GITHUB_TOKEN_FILE"); tokenFile != "" {
+		config.GitHub.TokenFile = tokenFile
+	}
+	if tokens := os.Getenv("GHREPOS_GITHUB_TOKENS"); tokens != "" {
+		config.GitHub.Tokens = nil
+		for _, token := range strings.Split(tokens, ",") {
+			if token = strings.TrimSpace(token); token != "" {
+				config.GitHub.Tokens = append(config.GitHub.Tokens, token)
+			}
+		}
+	}
+
+	if offlineStr := os.Getenv("GHREPOS_OFFLINE"); offlineStr != "" {
+		if offline, err := strconv.ParseBool(offlineStr); err == nil {
+			config.GitHub.Offline = offline
+		}
+	}
+	if replay := os.Getenv("GHREPOS_REPLAY"); replay != "" {
+		config.GitHub.Replay = replay
+	}
+
+	// Job queue configuration
+	if workersStr := os.Getenv("GHREPOS_JOB_WORKERS"); workersStr != "" {
+		if workers, err := strconv.Atoi(workersStr); err == nil && workers > 0 {
+			config.Jobs.Workers = workers
+		}
+	}
+	if attemptsStr := os.Getenv("GHREPOS_JOB_MAX_ATTEMPTS"); attemptsStr != "" {
+		if attempts, err := strconv.Atoi(attemptsStr); err == nil && attempts > 0 {
+			config.Jobs.MaxAttempts = attempts
+		}
+	}
+	if retryDelay := os.Getenv("GHREPOS_JOB_RETRY_DELAY"); retryDelay != "" {
+		if duration, err := time.ParseDuration(retryDelay); err == nil {
+			config.Jobs.RetryDelay = duration
+		}
+	}
+
+	// Admin configuration
+	if apiKey := os.Getenv("GHREPOS_ADMIN_API_KEY"); apiKey != "" {
+		config.Admin.APIKey = apiKey
+	}
+
+	// SSO configuration
+	if secret := os.Getenv("GHREPOS_SSO_SESSION_SECRET"); secret != "" {
+		config.SSO.SessionSecret = secret
+	}
+
+	// Server configuration
+	if addr := os.Getenv("GHREPOS_SERVER_ADDR"); addr != "" {
+		config.Server.Addr = addr
+	}
+	if secret := os.Getenv("GHREPOS_SLACK_SIGNING_SECRET"); secret != "" {
+		config.Server.SlackSigningSecret = secret
+	}
+
+	// Query configuration
+	if apiKey := os.Getenv("GHREPOS_QUERY_API_KEY"); apiKey != "" {
+		config.Query.APIKey = apiKey
+	}
+
 	// Logging configuration
 	if logLevel := os.Getenv("GHREPOS_LOG_LEVEL"); logLevel != "" {
 		config.Logging.Level = logLevel
@@ -0,0 +1,152 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// envReference matches ${NAME} in the configuration file. Bare $NAME is left alone
+// so values such as passwords may contain dollar signs.
+var envReference = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// expandEnv replaces ${NAME} with the value of the environment variable NAME
+func expandEnv(data []byte) []byte {
+	return envReference.ReplaceAllFunc(data, func(ref []byte) []byte {
+		return []byte(os.Getenv(string(envReference.FindSubmatch(ref)[1])))
+	})
+}
+
+// SecretResolver fetches the secret a reference points to
+type SecretResolver func(ref string) (string, error)
+
+// secretResolvers maps the scheme of a secret reference, such as vault in
+// vault:secret/ghrepos#token, to its resolver. Values without a known scheme are used as is.
+var secretResolvers = map[string]SecretResolver{
+	"file":  readFileSecret,
+	"vault": readVaultSecret,
+	"awssm": readAWSSecret,
+}
+
+// resolveSecret returns the secret a value refers to, or the value itself when it is not a reference
+func resolveSecret(value string) (string, error) {
+	scheme, ref, ok := strings.Cut(value, ":")
+	if !ok {
+		return value, nil
+	}
+	resolve, ok := secretResolvers[scheme]
+	if !ok {
+		return value, nil
+	}
+
+	secret, err := resolve(ref)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s secret %s: %w", scheme, ref, err)
+	}
+	return secret, nil
+}
+
+// resolveSecrets replaces the secret references in the credentials of a configuration
+// and adds the tokens and passwords kept in files
+func resolveSecrets(config *Config) error {
+	if config.GitHub.TokenFile != "" {
+		data, err := os.ReadFile(config.GitHub.TokenFile)
+		if err != nil {
+			return fmt.Errorf("failed to read token file: %w", err)
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			if token := strings.TrimSpace(line); token != "" && !strings.HasPrefix(token, "#") {
+				config.GitHub.Tokens = append(config.GitHub.Tokens, token)
+			}
+		}
+	}
+	if config.Database.PasswordFile != "" {
+		password, err := readFileSecret(config.Database.PasswordFile)
+		if err != nil {
+			return fmt.Errorf("failed to read database password file: %w", err)
+		}
+		config.Database.Password = password
+	}
+
+	values := []*string{&config.Database.Password, &config.Admin.APIKey, &config.SSO.ClientSecret, &config.SSO.SessionSecret,
+		&config.Server.SlackSigningSecret, &config.Query.APIKey, &config.Warehouse.ClickHouse.Password}
+	for i := range config.GitHub.Tokens {
+		values = append(values, &config.GitHub.Tokens[i])
+	}
+	for i := range config.Notifications.Slack {
+		values = append(values, &config.Notifications.Slack[i].WebhookURL)
+	}
+	for _, value := range values {
+		secret, err := resolveSecret(*value)
+		if err != nil {
+			return err
+		}
+		*value = secret
+	}
+	for name, value := range config.Tracing.Headers {
+		secret, err := resolveSecret(value)
+		if err != nil {
+			return err
+		}
+		config.Tracing.Headers[name] = secret
+	}
+	return nil
+}
+
+// readFileSecret reads a secret from a file, ignoring surrounding whitespace
+func readFileSecret(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// readVaultSecret reads a field of a HashiCorp Vault KV secret with the vault CLI,
+// which takes its address and token from VAULT_ADDR and VAULT_TOKEN. The reference
+// is path#field, the field defaulting to value.
+func readVaultSecret(ref string) (string, error) {
+	path, field, ok := strings.Cut(ref, "#")
+	if !ok {
+		field = "value"
+	}
+	return runSecretCommand("vault", "kv", "get", "-field="+field, path)
+}
+
+// readAWSSecret reads a secret from AWS Secrets Manager with the aws CLI, which uses
+// the standard AWS credentials. The reference is name#key, where key selects a field
+// of a JSON secret; without it the whole secret string is returned.
+func readAWSSecret(ref string) (string, error) {
+	name, key, hasKey := strings.Cut(ref, "#")
+	secret, err := runSecretCommand("aws", "secretsmanager", "get-secret-value", "--secret-id", name, "--query", "SecretString", "--output", "text")
+	if err != nil || !hasKey {
+		return secret, err
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(secret), &fields); err != nil {
+		return "", fmt.Errorf("secret is not a JSON object: %w", err)
+	}
+	value, ok := fields[key]
+	if !ok {
+		return "", fmt.Errorf("secret has no key %q", key)
+	}
+	return fmt.Sprint(value), nil
+}
+
+// runSecretCommand runs a secret store CLI and returns its trimmed output
+func runSecretCommand(name string, args ...string) (string, error) {
+	cmd := exec.Command(name, args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%s failed: %w, stderr: %s", name, err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
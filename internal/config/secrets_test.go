@@ -0,0 +1,62 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadSecrets(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+		return path
+	}
+
+	t.Setenv("GHREPOS_TEST_TOKEN", "env-token")
+	tokenFile := write("tokens", "# rotated tokens\nfile-token-1\n\nfile-token-2\n")
+	passwordFile := write("password", "db-pa$$word\n")
+	apiKeyFile := write("api-key", " admin-key ")
+	configPath := write("config.yaml", `
+database:
+  password_file: "`+passwordFile+`"
+github:
+  tokens: ["${GHREPOS_TEST_TOKEN}", "literal-token"]
+  token_file: "`+tokenFile+`"
+admin:
+  api_key: "file:`+apiKeyFile+`"
+`)
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	want := []string{"env-token", "literal-token", "file-token-1", "file-token-2"}
+	if len(cfg.GitHub.Tokens) != len(want) {
+		t.Fatalf("tokens = %v, want %v", cfg.GitHub.Tokens, want)
+	}
+	for i := range want {
+		if cfg.GitHub.Tokens[i] != want[i] {
+			t.Fatalf("tokens = %v, want %v", cfg.GitHub.Tokens, want)
+		}
+	}
+	if cfg.Database.Password != "db-pa$$word" {
+		t.Errorf("password = %q, want it read from the file unexpanded", cfg.Database.Password)
+	}
+	if cfg.Admin.APIKey != "admin-key" {
+		t.Errorf("api key = %q, want admin-key", cfg.Admin.APIKey)
+	}
+}
+
+func TestResolveSecretErrors(t *testing.T) {
+	if _, err := resolveSecret("file:/does/not/exist"); err == nil {
+		t.Error("resolveSecret() of a missing file should fail")
+	}
+	if got, err := resolveSecret("https://hooks.slack.com/services/x"); err != nil || got != "https://hooks.slack.com/services/x" {
+		t.Errorf("resolveSecret() = %q, %v, want the URL unchanged", got, err)
+	}
+}
@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"time"
 
 	"github.com/siddontang/github-repos-management/internal/config"
 	"github.com/siddontang/github-repos-management/internal/models"
@@ -13,22 +14,35 @@ type DB interface {
 	AddRepository(ctx context.Context, repo *models.Repository) error
 	GetRepository(ctx context.Context, owner, name string) (*models.Repository, error)
 	ListRepositories(ctx context.Context, page, perPage int) ([]*models.Repository, int, error)
+	ListAllRepositories(ctx context.Context) ([]*models.Repository, error)
 	UpdateRepository(ctx context.Context, repo *models.Repository) error
+	// UpsertRepository adds a repository or replaces the stored one like UpdateRepository,
+	// reporting whether it was added
+	UpsertRepository(ctx context.Context, repo *models.Repository) (created bool, err error)
 	DeleteRepository(ctx context.Context, owner, name string) error
 
 	// Pull request operations
 	AddPullRequest(ctx context.Context, pr *models.PullRequest) error
 	GetPullRequest(ctx context.Context, repoFullName string, number int) (*models.PullRequest, error)
 	ListPullRequests(ctx context.Context, repoFullName string, page, perPage int) ([]*models.PullRequest, int, error)
+	ListAllPullRequests(ctx context.Context, repoFullName string) ([]*models.PullRequest, error)
 	UpdatePullRequest(ctx context.Context, pr *models.PullRequest) error
+	// UpsertPullRequest adds a pull request or replaces the stored one like UpdatePullRequest,
+	// reporting whether it was added
+	UpsertPullRequest(ctx context.Context, pr *models.PullRequest) (created bool, err error)
 	DeletePullRequest(ctx context.Context, repoFullName string, number int) error
+	FindPullRequests(ctx context.Context, query *models.ItemQuery) ([]*models.PullRequest, error)
 
 	// Issue operations
 	AddIssue(ctx context.Context, issue *models.Issue) error
 	GetIssue(ctx context.Context, repoFullName string, number int) (*models.Issue, error)
 	ListIssues(ctx context.Context, repoFullName string, page, perPage int) ([]*models.Issue, int, error)
+	ListAllIssues(ctx context.Context, repoFullName string) ([]*models.Issue, error)
 	UpdateIssue(ctx context.Context, issue *models.Issue) error
+	// UpsertIssue adds an issue or replaces the stored one like UpdateIssue, reporting whether it was added
+	UpsertIssue(ctx context.Context, issue *models.Issue) (created bool, err error)
 	DeleteIssue(ctx context.Context, repoFullName string, number int) error
+	FindIssues(ctx context.Context, query *models.ItemQuery) ([]*models.Issue, error)
 
 	// Label operations
 	AddLabel(ctx context.Context, label *models.Label) error
@@ -47,13 +61,144 @@ type DB interface {
 	ListIssueLabels(ctx context.Context, repoFullName string, issueNumber int) ([]*models.Label, error)
 	RemoveIssueLabel(ctx context.Context, repoFullName string, issueNumber int, labelName string) error
 
+	// Webhook operations
+	AddWebhook(ctx context.Context, hook *models.Webhook) error
+	GetWebhook(ctx context.Context, id int64) (*models.Webhook, error)
+	ListWebhooks(ctx context.Context) ([]*models.Webhook, error)
+	DeleteWebhook(ctx context.Context, id int64) error
+	// AddWebhookDeliveries records deliveries with a single write, skipping those of deleted webhooks
+	AddWebhookDeliveries(ctx context.Context, deliveries []*models.WebhookDelivery) error
+	ListWebhookDeliveries(ctx context.Context, hookID int64, page, perPage int) ([]*models.WebhookDelivery, int, error)
+
+	// Subscription operations
+	AddSubscription(ctx context.Context, sub *models.Subscription) error
+	ListSubscriptions(ctx context.Context) ([]*models.Subscription, error)
+	DeleteSubscription(ctx context.Context, id int64) error
+
+	// Triage rule operations
+	AddTriageRule(ctx context.Context, rule *models.TriageRule) error
+	ListTriageRules(ctx context.Context) ([]*models.TriageRule, error)
+	DeleteTriageRule(ctx context.Context, id int64) error
+	AddTriageExecution(ctx context.Context, execution *models.TriageExecution) error
+	ListTriageExecutions(ctx context.Context, ruleID int64) ([]*models.TriageExecution, error)
+
+	// Workspace operations; SaveWorkspace creates or replaces a workspace
+	SaveWorkspace(ctx context.Context, workspace *models.Workspace) error
+	// UpdateWorkspace applies mutate to a stored workspace while no other write can happen,
+	// storing it when mutate returns true, and returns the workspace as stored
+	UpdateWorkspace(ctx context.Context, id string, mutate func(workspace *models.Workspace) bool) (*models.Workspace, error)
+	GetWorkspace(ctx context.Context, id string) (*models.Workspace, error)
+	ListWorkspaces(ctx context.Context) ([]*models.Workspace, error)
+	DeleteWorkspace(ctx context.Context, id string) error
+
+	// Activity operations
+	AppendActivity(ctx context.Context, event *models.ActivityEvent) error
+	ListActivity(ctx context.Context, filter *models.ActivityFilter) ([]*models.ActivityEvent, int, error)
+	// ListActivitySince lists the events recorded after the one with ID afterID, oldest first, at most
+	// limit of them when limit is positive
+	ListActivitySince(ctx context.Context, afterID int64, limit int) ([]*models.ActivityEvent, error)
+
+	// Audit operations
+	AppendAudit(ctx context.Context, entry *models.AuditEntry) error
+	ListAudit(ctx context.Context, filter *models.AuditFilter) ([]*models.AuditEntry, int, error)
+
+	// Job operations
+	AddJob(ctx context.Context, job *models.Job) error
+	UpdateJob(ctx context.Context, job *models.Job) error
+	GetJob(ctx context.Context, id int64) (*models.Job, error)
+	ListJobs(ctx context.Context, filter *models.JobFilter) ([]*models.Job, int, error)
+
+	// Snapshot operations
+	AddRepositorySnapshot(ctx context.Context, snapshot *models.RepositorySnapshot) error
+	ListRepositorySnapshots(ctx context.Context, repoFullName string, since time.Time) ([]*models.RepositorySnapshot, error)
+
+	// Milestone and release operations; an empty repository lists those of every repository
+	ReplaceMilestones(ctx context.Context, repoFullName string, milestones []*models.Milestone) error
+	ListMilestones(ctx context.Context, repoFullName string) ([]*models.Milestone, error)
+	ReplaceReleases(ctx context.Context, repoFullName string, releases []*models.Release) error
+	ListReleases(ctx context.Context, repoFullName string) ([]*models.Release, error)
+
+	// Security alert operations; an empty repository lists those of every repository
+	ReplaceSecurityAlerts(ctx context.Context, repoFullName string, alerts []*models.SecurityAlert) error
+	ListSecurityAlerts(ctx context.Context, repoFullName string) ([]*models.SecurityAlert, error)
+
+	// Commit operations; an empty repository lists those of every repository
+	ReplaceCommits(ctx context.Context, repoFullName string, commits []*models.Commit) error
+	ListCommits(ctx context.Context, repoFullName string) ([]*models.Commit, error)
+
+	// Duplicate issue operations; candidates are replaced as a whole on each detection
+	ReplaceDuplicates(ctx context.Context, repoFullName string, candidates []*models.DuplicateCandidate) error
+	ListDuplicates(ctx context.Context, repoFullName string) ([]*models.DuplicateCandidate, error)
+
+	// Discussion operations; an empty repository lists those of every repository
+	ReplaceDiscussions(ctx context.Context, repoFullName string, discussions []*models.Discussion) error
+	ListDiscussions(ctx context.Context, repoFullName string) ([]*models.Discussion, error)
+
+	// Project operations; an empty repository lists those of every repository
+	ReplaceProjects(ctx context.Context, repoFullName string, projects []*models.Project, items []*models.ProjectItem) error
+	ListProjects(ctx context.Context, repoFullName string) ([]*models.Project, error)
+	ListProjectItems(ctx context.Context, repoFullName string) ([]*models.ProjectItem, error)
+
+	// Pull request diff operations; SavePullRequestDiff replaces the cached diff of the same pull
+	// request and format
+	SavePullRequestDiff(ctx context.Context, diff *models.PullRequestDiff) error
+	GetPullRequestDiff(ctx context.Context, repoFullName string, number int, format string) (*models.PullRequestDiff, error)
+
 	// Maintenance operations
+	Stats(ctx context.Context) (*models.StorageStats, error)
+	Compact(ctx context.Context) (*models.CompactionResult, error)
+	ExpireClosedItems(ctx context.Context, policy *models.RetentionPolicy) (*models.RetentionResult, error)
+	CheckIntegrity(ctx context.Context, repair bool) (*models.IntegrityReport, error)
+	ClearRepositoryData(ctx context.Context, fullName string) error
 	Close() error
 	Ping(ctx context.Context) error
 
+	// Snapshot returns the repositories, pull requests, issues and labels as they are now. Later
+	// writes don't change it, and reading it doesn't block them.
+	Snapshot(ctx context.Context) (Reader, error)
+
+	// Batch runs fn, applying the writes it makes through tx together: readers see all of them
+	// or none, and they are undone when fn or saving them fails. fn must not use the database
+	// other than through tx.
+	Batch(ctx context.Context, fn func(tx Tx) error) error
+
 	// Sync operations
 	Sync() error
 }
 
+// Reader is the part of a database available from a snapshot, for queries reading many
+// repositories or items
+type Reader interface {
+	GetRepository(ctx context.Context, owner, name string) (*models.Repository, error)
+	ListAllRepositories(ctx context.Context) ([]*models.Repository, error)
+
+	GetPullRequest(ctx context.Context, repoFullName string, number int) (*models.PullRequest, error)
+	ListAllPullRequests(ctx context.Context, repoFullName string) ([]*models.PullRequest, error)
+	FindPullRequests(ctx context.Context, query *models.ItemQuery) ([]*models.PullRequest, error)
+	ListPullRequestLabels(ctx context.Context, repoFullName string, prNumber int) ([]*models.Label, error)
+
+	GetIssue(ctx context.Context, repoFullName string, number int) (*models.Issue, error)
+	ListAllIssues(ctx context.Context, repoFullName string) ([]*models.Issue, error)
+	FindIssues(ctx context.Context, query *models.ItemQuery) ([]*models.Issue, error)
+	ListIssueLabels(ctx context.Context, repoFullName string, issueNumber int) ([]*models.Label, error)
+}
+
+// Tx is the part of a database available to a batch, the reads and writes of a sync
+type Tx interface {
+	GetPullRequest(ctx context.Context, repoFullName string, number int) (*models.PullRequest, error)
+	UpsertPullRequest(ctx context.Context, pr *models.PullRequest) (created bool, err error)
+	ListPullRequestLabels(ctx context.Context, repoFullName string, prNumber int) ([]*models.Label, error)
+	AddPullRequestLabel(ctx context.Context, repoFullName string, prNumber int, labelName string) error
+
+	GetIssue(ctx context.Context, repoFullName string, number int) (*models.Issue, error)
+	UpsertIssue(ctx context.Context, issue *models.Issue) (created bool, err error)
+	DeleteIssue(ctx context.Context, repoFullName string, number int) error
+	ListIssueLabels(ctx context.Context, repoFullName string, issueNumber int) ([]*models.Label, error)
+	AddIssueLabel(ctx context.Context, repoFullName string, issueNumber int, labelName string) error
+
+	GetLabel(ctx context.Context, name string) (*models.Label, error)
+	AddLabel(ctx context.Context, label *models.Label) error
+}
+
 // Provider is a function that creates a new db instance
 type Provider func(config *config.Config) (DB, error)
@@ -0,0 +1,51 @@
+package db
+
+import "errors"
+
+// Kinds of storage errors. Every error returned by a backend for a missing record, a lost race
+// or a storage failure matches one of them with errors.Is, so callers can tell them apart
+// without parsing messages.
+var (
+	// ErrNotFound is matched by errors for records that don't exist
+	ErrNotFound = errors.New("not found")
+
+	// ErrConflict is matched by errors for writes that lose a race with another writer
+	ErrConflict = errors.New("conflict")
+
+	// ErrUnavailable is matched by errors for storage that can't be read or written,
+	// such as a failed write to disk or a database opened by another process
+	ErrUnavailable = errors.New("storage unavailable")
+)
+
+// kindError is an error of one of the storage error kinds
+type kindError struct {
+	kind error
+	msg  string
+}
+
+func (e *kindError) Error() string { return e.msg }
+
+func (e *kindError) Unwrap() error { return e.kind }
+
+// Errors returned by storage backends for writes that lose a race
+var (
+	// ErrVersionConflict is returned by UpdateRepository when the repository was
+	// changed since the caller read it. Re-read the repository and apply the change again.
+	ErrVersionConflict error = &kindError{ErrConflict, "repository was modified concurrently"}
+
+	// ErrStaleWrite is returned by UpdatePullRequest and UpdateIssue when the stored
+	// item was updated on GitHub more recently than the one being written.
+	ErrStaleWrite error = &kindError{ErrConflict, "stored item is newer than the update"}
+)
+
+// Errors returned when opening or writing a database shared between processes
+var (
+	// ErrDatabaseInUse is returned when another process has the database open for writing
+	ErrDatabaseInUse error = &kindError{ErrUnavailable, "database is in use by another process"}
+
+	// ErrReadOnly is returned by writes to a database opened read-only
+	ErrReadOnly error = &kindError{ErrUnavailable, "database is opened read-only"}
+
+	// ErrUnsupportedSchema is returned when opening a database written by a newer release
+	ErrUnsupportedSchema = errors.New("database schema is newer than this release supports")
+)
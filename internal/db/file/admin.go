@@ -0,0 +1,285 @@
+package file
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// fileSize returns the size of the persisted data, or 0 when kept in memory only
+func (db *DB) fileSize() int64 {
+	if db.path == "" {
+		return 0
+	}
+	info, err := os.Stat(db.path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+// repositoryStats returns per repository entity counts, ordered by full name.
+// It must be called with the lock held.
+func (db *DB) repositoryStats() []*models.RepositoryStorageStats {
+	list := make([]*models.RepositoryStorageStats, 0, len(db.repositories))
+	for _, repo := range db.sortedRepositories() {
+		stats := &models.RepositoryStorageStats{
+			FullName:     repo.FullName,
+			PullRequests: len(db.pullRequests[repo.FullName]),
+			Issues:       len(db.issues[repo.FullName]),
+			Snapshots:    len(db.snapshots[repo.FullName]),
+		}
+		for _, pr := range db.pullRequests[repo.FullName] {
+			stats.EstimatedBytes += itemSize(pr.Title, pr.Body)
+			if pr.Tombstoned {
+				stats.TombstonedPullRequests++
+			}
+		}
+		for _, issue := range db.issues[repo.FullName] {
+			stats.EstimatedBytes += itemSize(issue.Title, issue.Body)
+			if issue.Tombstoned {
+				stats.TombstonedIssues++
+			}
+		}
+
+		labels := make(map[string]bool)
+		for _, names := range db.prLabels[repo.FullName] {
+			for _, name := range names {
+				labels[name] = true
+			}
+		}
+		for _, names := range db.issueLabels[repo.FullName] {
+			for _, name := range names {
+				labels[name] = true
+			}
+		}
+		stats.Labels = len(labels)
+
+		list = append(list, stats)
+	}
+	return list
+}
+
+// Compact removes tombstoned pull requests and issues and the data no longer reachable from a
+// tracked repository, pull request, issue, webhook or triage rule, and rewrites the file
+func (db *DB) Compact(ctx context.Context) (*models.CompactionResult, error) {
+	db.Lock()
+	defer db.Unlock()
+
+	result := &models.CompactionResult{BytesBefore: db.fileSize()}
+
+	// Pull requests and issues no longer present upstream
+	for fullName, prs := range db.pullRequests {
+		for number, pr := range prs {
+			if pr.Tombstoned {
+				db.drop(evictionCandidate{repo: fullName, number: number, isPR: true})
+				result.RemovedTombstones++
+			}
+		}
+	}
+	for fullName, issues := range db.issues {
+		for number, issue := range issues {
+			if issue.Tombstoned {
+				db.drop(evictionCandidate{repo: fullName, number: number})
+				result.RemovedTombstones++
+			}
+		}
+	}
+
+	// Data of repositories that are no longer tracked
+	for _, byRepo := range []map[string]map[int][]string{db.prLabels, db.issueLabels} {
+		for fullName, links := range byRepo {
+			if _, ok := db.repositories[fullName]; !ok {
+				result.RemovedEntries += len(links)
+				delete(byRepo, fullName)
+			}
+		}
+	}
+	for fullName, snapshots := range db.snapshots {
+		if _, ok := db.repositories[fullName]; !ok {
+			result.RemovedEntries += len(snapshots)
+			delete(db.snapshots, fullName)
+		}
+	}
+	for fullName, milestones := range db.milestones {
+		if _, ok := db.repositories[fullName]; !ok {
+			result.RemovedEntries += len(milestones)
+			delete(db.milestones, fullName)
+		}
+	}
+	for fullName, releases := range db.releases {
+		if _, ok := db.repositories[fullName]; !ok {
+			result.RemovedEntries += len(releases)
+			delete(db.releases, fullName)
+		}
+	}
+	for fullName, alerts := range db.alerts {
+		if _, ok := db.repositories[fullName]; !ok {
+			result.RemovedEntries += len(alerts)
+			delete(db.alerts, fullName)
+		}
+	}
+	for fullName, commits := range db.commits {
+		if _, ok := db.repositories[fullName]; !ok {
+			result.RemovedEntries += len(commits)
+			delete(db.commits, fullName)
+		}
+	}
+	for fullName, duplicates := range db.duplicates {
+		if _, ok := db.repositories[fullName]; !ok {
+			result.RemovedEntries += len(duplicates)
+			delete(db.duplicates, fullName)
+		}
+	}
+	for fullName, discussions := range db.discussions {
+		if _, ok := db.repositories[fullName]; !ok {
+			result.RemovedEntries += len(discussions)
+			delete(db.discussions, fullName)
+		}
+	}
+	for fullName, projects := range db.projects {
+		if _, ok := db.repositories[fullName]; !ok {
+			result.RemovedEntries += len(projects)
+			delete(db.projects, fullName)
+		}
+	}
+	for fullName, items := range db.projectItems {
+		if _, ok := db.repositories[fullName]; !ok {
+			result.RemovedEntries += len(items)
+			delete(db.projectItems, fullName)
+		}
+	}
+
+	// Cached diffs of pull requests that were removed
+	for fullName, diffs := range db.diffs {
+		kept := diffs[:0]
+		for _, diff := range diffs {
+			if _, ok := db.pullRequests[fullName][diff.Number]; ok {
+				kept = append(kept, diff)
+			} else {
+				result.RemovedEntries++
+			}
+		}
+		if len(kept) == 0 {
+			delete(db.diffs, fullName)
+		} else {
+			db.diffs[fullName] = kept
+		}
+	}
+
+	// Label links of pull requests and issues that were removed
+	for fullName, links := range db.prLabels {
+		for number := range links {
+			if _, ok := db.pullRequests[fullName][number]; !ok {
+				result.RemovedEntries++
+				delete(links, number)
+			}
+		}
+	}
+	for fullName, links := range db.issueLabels {
+		for number := range links {
+			if _, ok := db.issues[fullName][number]; !ok {
+				result.RemovedEntries++
+				delete(links, number)
+			}
+		}
+	}
+
+	// Labels no longer attached to anything
+	used := make(map[string]bool)
+	for _, byRepo := range []map[string]map[int][]string{db.prLabels, db.issueLabels} {
+		for _, links := range byRepo {
+			for _, names := range links {
+				for _, name := range names {
+					used[name] = true
+				}
+			}
+		}
+	}
+	for _, byRepo := range []map[string]map[string]*models.Label{db.labels, db.repoLabels} {
+		for _, labels := range byRepo {
+			for name := range labels {
+				if !used[name] {
+					result.RemovedEntries++
+					delete(labels, name)
+				}
+			}
+		}
+	}
+
+	// Delivery logs of removed webhooks
+	for id, deliveries := range db.webhookDeliveries {
+		if _, ok := db.webhooks[id]; !ok {
+			result.RemovedEntries += len(deliveries)
+			delete(db.webhookDeliveries, id)
+		}
+	}
+
+	// Execution logs of removed triage rules, and of repositories that are no longer tracked
+	for id, executions := range db.triageExecutions {
+		if _, ok := db.triageRules[id]; !ok {
+			result.RemovedEntries += len(executions)
+			delete(db.triageExecutions, id)
+			continue
+		}
+		kept := executions[:0]
+		for _, execution := range executions {
+			if _, ok := db.repositories[execution.RepositoryFullName]; ok {
+				kept = append(kept, execution)
+			} else {
+				result.RemovedEntries++
+			}
+		}
+		db.triageExecutions[id] = kept
+	}
+
+	if err := db.sync(); err != nil {
+		return nil, err
+	}
+	result.BytesAfter = db.fileSize()
+	return result, nil
+}
+
+// ClearRepositoryData removes the pull requests, issues, snapshots, milestones, releases, alerts, commits, duplicates, discussions, projects and diffs of a repository
+// while keeping it tracked, so the next sync fetches everything again
+func (db *DB) ClearRepositoryData(ctx context.Context, fullName string) error {
+	db.Lock()
+	defer db.Unlock()
+
+	repo, ok := db.repositories[fullName]
+	if !ok {
+		return db.ErrRepositoryNotFound(fullName)
+	}
+
+	delete(db.pullRequests, fullName)
+	delete(db.issues, fullName)
+	delete(db.repoPRs, fullName)
+	delete(db.repoIssues, fullName)
+	delete(db.prLabels, fullName)
+	delete(db.issueLabels, fullName)
+	delete(db.snapshots, fullName)
+	delete(db.milestones, fullName)
+	delete(db.releases, fullName)
+	delete(db.alerts, fullName)
+	delete(db.commits, fullName)
+	delete(db.duplicates, fullName)
+	delete(db.discussions, fullName)
+	delete(db.projects, fullName)
+	delete(db.projectItems, fullName)
+	delete(db.diffs, fullName)
+	db.prIndex.removeRepository(fullName)
+	db.issueIndex.removeRepository(fullName)
+
+	// Make the cleared data count as stale. The stored repository is replaced rather than
+	// changed, as snapshots share it.
+	repo = cloneRepository(repo)
+	db.repositories[fullName] = repo
+	repo.LastSyncedAt = time.Time{}
+	repo.PullRequestsSyncedAt = time.Time{}
+	repo.IssuesSyncedAt = time.Time{}
+	repo.DiscussionsSyncedAt = time.Time{}
+
+	return db.sync()
+}
@@ -0,0 +1,107 @@
+package file
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// TestCompactAndClear tests removing orphaned data and clearing the data of a repository
+func TestCompactAndClear(t *testing.T) {
+	ctx := context.Background()
+	db, err := NewDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+
+	repo := &models.Repository{Owner: "pingcap", Name: "tidb", FullName: "pingcap/tidb", LastSyncedAt: time.Now()}
+	if err := db.AddRepository(ctx, repo); err != nil {
+		t.Fatalf("AddRepository() error = %v", err)
+	}
+	for number := 1; number <= 2; number++ {
+		if err := db.AddPullRequest(ctx, &models.PullRequest{RepositoryFullName: "pingcap/tidb", Number: number, State: "open"}); err != nil {
+			t.Fatalf("AddPullRequest() error = %v", err)
+		}
+	}
+	for _, name := range []string{"bug", "stale"} {
+		if err := db.AddLabel(ctx, &models.Label{Name: name}); err != nil {
+			t.Fatalf("AddLabel() error = %v", err)
+		}
+	}
+	if err := db.AddPullRequestLabel(ctx, "pingcap/tidb", 1, "bug"); err != nil {
+		t.Fatalf("AddPullRequestLabel() error = %v", err)
+	}
+	if err := db.AddPullRequestLabel(ctx, "pingcap/tidb", 2, "bug"); err != nil {
+		t.Fatalf("AddPullRequestLabel() error = %v", err)
+	}
+	for number := 1; number <= 2; number++ {
+		diff := &models.PullRequestDiff{RepositoryFullName: "pingcap/tidb", Number: number, Format: models.DiffFormatDiff, Content: "diff --git"}
+		if err := db.SavePullRequestDiff(ctx, diff); err != nil {
+			t.Fatalf("SavePullRequestDiff() error = %v", err)
+		}
+	}
+	if err := db.DeletePullRequest(ctx, "pingcap/tidb", 2); err != nil {
+		t.Fatalf("DeletePullRequest() error = %v", err)
+	}
+	if err := db.AddIssue(ctx, &models.Issue{RepositoryFullName: "pingcap/tidb", Number: 3, State: "open", Tombstoned: true}); err != nil {
+		t.Fatalf("AddIssue() error = %v", err)
+	}
+
+	stats, err := db.Stats(ctx)
+	if err != nil {
+		t.Fatalf("Stats() error = %v", err)
+	}
+	if len(stats.PerRepository) != 1 || stats.PerRepository[0].PullRequests != 1 || stats.PerRepository[0].Labels != 1 {
+		t.Errorf("Stats() per repository = %+v", stats.PerRepository)
+	}
+	if stats.FileBytes == 0 {
+		t.Errorf("Stats() FileBytes = 0")
+	}
+
+	// The link and diff of the deleted pull request and the unused "stale" label are orphaned
+	result, err := db.Compact(ctx)
+	if err != nil {
+		t.Fatalf("Compact() error = %v", err)
+	}
+	if result.RemovedEntries < 3 {
+		t.Errorf("Compact() removed %d entries, want at least 3", result.RemovedEntries)
+	}
+	if result.RemovedTombstones != 1 {
+		t.Errorf("Compact() removed %d tombstones, want 1", result.RemovedTombstones)
+	}
+	if _, err := db.GetIssue(ctx, "pingcap/tidb", 3); err == nil {
+		t.Errorf("GetIssue(3) found a tombstoned issue after compaction")
+	}
+	if _, err := db.GetPullRequestDiff(ctx, "pingcap/tidb", 2, models.DiffFormatDiff); err == nil {
+		t.Errorf("GetPullRequestDiff(2) found the diff of a deleted pull request after compaction")
+	}
+	if _, err := db.GetPullRequestDiff(ctx, "pingcap/tidb", 1, models.DiffFormatDiff); err != nil {
+		t.Errorf("GetPullRequestDiff(1) error = %v", err)
+	}
+	if _, err := db.GetLabel(ctx, "stale"); err == nil {
+		t.Errorf("GetLabel(stale) found an unused label after compaction")
+	}
+	if _, err := db.GetLabel(ctx, "bug"); err != nil {
+		t.Errorf("GetLabel(bug) error = %v", err)
+	}
+
+	if err := db.ClearRepositoryData(ctx, "pingcap/tidb"); err != nil {
+		t.Fatalf("ClearRepositoryData() error = %v", err)
+	}
+	if _, total, _ := db.ListPullRequests(ctx, "pingcap/tidb", 1, 10); total != 0 {
+		t.Errorf("ListPullRequests() total = %d after clearing, want 0", total)
+	}
+	if _, err := db.GetPullRequestDiff(ctx, "pingcap/tidb", 1, models.DiffFormatDiff); err == nil {
+		t.Errorf("GetPullRequestDiff(1) found a diff after clearing")
+	}
+	cleared, err := db.GetRepository(ctx, "pingcap", "tidb")
+	if err != nil {
+		t.Fatalf("GetRepository() error = %v", err)
+	}
+	if !cleared.LastSyncedAt.IsZero() {
+		t.Errorf("LastSyncedAt = %v after clearing, want zero", cleared.LastSyncedAt)
+	}
+}
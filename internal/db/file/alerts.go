@@ -0,0 +1,58 @@
+package file
+
+import (
+	"context"
+	"sort"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// Security alert operations. Alerts are replaced as a whole on each sync and returned as copies.
+
+// ReplaceSecurityAlerts replaces the open security alerts of a repository
+func (db *DB) ReplaceSecurityAlerts(ctx context.Context, repoFullName string, alerts []*models.SecurityAlert) error {
+	db.Lock()
+	defer db.Unlock()
+
+	if _, ok := db.repositories[repoFullName]; !ok {
+		return db.ErrRepositoryNotFound(repoFullName)
+	}
+
+	stored := make([]*models.SecurityAlert, 0, len(alerts))
+	for _, alert := range alerts {
+		clone := *alert
+		clone.RepositoryFullName = repoFullName
+		stored = append(stored, &clone)
+	}
+	db.alerts[repoFullName] = stored
+	return db.sync()
+}
+
+// ListSecurityAlerts lists the open security alerts of a repository, or of every repository
+// when repoFullName is empty, ordered by repository, kind and number
+func (db *DB) ListSecurityAlerts(ctx context.Context, repoFullName string) ([]*models.SecurityAlert, error) {
+	db.RLock()
+	defer db.RUnlock()
+
+	alerts := make([]*models.SecurityAlert, 0)
+	for fullName, list := range db.alerts {
+		if repoFullName != "" && fullName != repoFullName {
+			continue
+		}
+		for _, alert := range list {
+			clone := *alert
+			alerts = append(alerts, &clone)
+		}
+	}
+	sort.Slice(alerts, func(i, j int) bool {
+		a, b := alerts[i], alerts[j]
+		if a.RepositoryFullName != b.RepositoryFullName {
+			return a.RepositoryFullName < b.RepositoryFullName
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Number < b.Number
+	})
+	return alerts, nil
+}
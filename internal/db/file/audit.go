@@ -0,0 +1,65 @@
+package file
+
+import (
+	"context"
+	"strings"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// Audit operations
+
+// AppendAudit appends an entry to the audit log and assigns its sequence ID
+func (db *DB) AppendAudit(ctx context.Context, entry *models.AuditEntry) error {
+	db.Lock()
+	defer db.Unlock()
+
+	db.nextAuditID++
+	entry.ID = db.nextAuditID
+	stored := *entry
+	db.audit = append(db.audit, &stored)
+
+	return db.sync()
+}
+
+// ListAudit lists audit entries matching the filter, newest first
+func (db *DB) ListAudit(ctx context.Context, filter *models.AuditFilter) ([]*models.AuditEntry, int, error) {
+	db.RLock()
+	defer db.RUnlock()
+
+	entries := make([]*models.AuditEntry, 0)
+	for i := len(db.audit) - 1; i >= 0; i-- {
+		entry := db.audit[i]
+		if filter.Actor != "" && !strings.EqualFold(entry.Actor, filter.Actor) {
+			continue
+		}
+		// An action prefix such as repository matches every repository action
+		if filter.Action != "" && entry.Action != filter.Action && !strings.HasPrefix(entry.Action, filter.Action+".") {
+			continue
+		}
+		if filter.Target != "" && !strings.EqualFold(entry.Target, filter.Target) {
+			continue
+		}
+		if !filter.Since.IsZero() && entry.CreatedAt.Before(filter.Since) {
+			continue
+		}
+		if !filter.Until.IsZero() && !entry.CreatedAt.Before(filter.Until) {
+			continue
+		}
+		copied := *entry
+		entries = append(entries, &copied)
+	}
+
+	total := len(entries)
+	offset := (filter.Page - 1) * filter.PerPage
+	if offset >= total {
+		return []*models.AuditEntry{}, total, nil
+	}
+
+	end := offset + filter.PerPage
+	if end > total {
+		end = total
+	}
+
+	return entries[offset:end], total, nil
+}
@@ -0,0 +1,162 @@
+package file
+
+import (
+	"context"
+
+	storage "github.com/siddontang/github-repos-management/internal/db"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// Batch operations. A batch holds the write lock while it runs, so readers wait for it rather
+// than see part of it, and its writes are saved with a single write of the file.
+
+// Batch runs fn, applying its writes together. When fn or saving the file fails, the pull
+// requests, issues and labels are restored to what they were before the batch.
+func (db *DB) Batch(ctx context.Context, fn func(tx storage.Tx) error) error {
+	db.Lock()
+	defer db.Unlock()
+
+	saved := db.saveItems()
+	if err := fn(&batch{db: db}); err != nil {
+		db.restoreItems(saved)
+		return err
+	}
+	if err := db.sync(); err != nil {
+		db.restoreItems(saved)
+		return err
+	}
+	return nil
+}
+
+// itemState is a copy of the pull requests, issues and labels, which are all a batch writes
+type itemState struct {
+	pullRequests  map[string]map[int]*models.PullRequest
+	issues        map[string]map[int]*models.Issue
+	labels        map[string]map[string]*models.Label
+	repoLabels    map[string]map[string]*models.Label
+	repoPRs       map[string][]int
+	repoIssues    map[string][]int
+	prLabels      map[string]map[int][]string
+	issueLabels   map[string]map[int][]string
+	evictedPRs    int64
+	evictedIssues int64
+}
+
+// saveItems copies the pull requests, issues and labels. Stored items are replaced rather than
+// changed in place, so the maps and slices holding them are copied but the items are shared.
+// The caller must hold the lock.
+func (db *DB) saveItems() *itemState {
+	return &itemState{
+		pullRequests:  copyNested(db.pullRequests),
+		issues:        copyNested(db.issues),
+		labels:        copyNested(db.labels),
+		repoLabels:    copyNested(db.repoLabels),
+		repoPRs:       copyNumbers(db.repoPRs),
+		repoIssues:    copyNumbers(db.repoIssues),
+		prLabels:      copyLabelNames(db.prLabels),
+		issueLabels:   copyLabelNames(db.issueLabels),
+		evictedPRs:    db.evictedPRs,
+		evictedIssues: db.evictedIssues,
+	}
+}
+
+// restoreItems puts back the pull requests, issues and labels saved by saveItems, rebuilding
+// the indexes over them; the caller must hold the write lock
+func (db *DB) restoreItems(saved *itemState) {
+	db.pullRequests = saved.pullRequests
+	db.issues = saved.issues
+	db.labels = saved.labels
+	db.repoLabels = saved.repoLabels
+	db.repoPRs = saved.repoPRs
+	db.repoIssues = saved.repoIssues
+	db.prLabels = saved.prLabels
+	db.issueLabels = saved.issueLabels
+	db.evictedPRs = saved.evictedPRs
+	db.evictedIssues = saved.evictedIssues
+	db.rebuildIndexes()
+}
+
+func copyNested[K1, K2 comparable, V any](m map[K1]map[K2]V) map[K1]map[K2]V {
+	copied := make(map[K1]map[K2]V, len(m))
+	for key, inner := range m {
+		innerCopy := make(map[K2]V, len(inner))
+		for k, v := range inner {
+			innerCopy[k] = v
+		}
+		copied[key] = innerCopy
+	}
+	return copied
+}
+
+func copyNumbers(m map[string][]int) map[string][]int {
+	copied := make(map[string][]int, len(m))
+	for key, numbers := range m {
+		copied[key] = append([]int(nil), numbers...)
+	}
+	return copied
+}
+
+func copyLabelNames(m map[string]map[int][]string) map[string]map[int][]string {
+	copied := make(map[string]map[int][]string, len(m))
+	for key, inner := range m {
+		innerCopy := make(map[int][]string, len(inner))
+		for number, names := range inner {
+			innerCopy[number] = append([]string(nil), names...)
+		}
+		copied[key] = innerCopy
+	}
+	return copied
+}
+
+// batch is the Tx of a batch, running operations without taking the lock the batch holds and
+// without saving the file
+type batch struct {
+	db *DB
+}
+
+func (b *batch) GetPullRequest(ctx context.Context, repoFullName string, number int) (*models.PullRequest, error) {
+	return b.db.getPullRequest(repoFullName, number)
+}
+
+func (b *batch) UpsertPullRequest(ctx context.Context, pr *models.PullRequest) (bool, error) {
+	return b.db.upsertPullRequest(pr)
+}
+
+func (b *batch) ListPullRequestLabels(ctx context.Context, repoFullName string, prNumber int) ([]*models.Label, error) {
+	return b.db.listPullRequestLabels(repoFullName, prNumber)
+}
+
+func (b *batch) AddPullRequestLabel(ctx context.Context, repoFullName string, prNumber int, labelName string) error {
+	b.db.addPullRequestLabel(repoFullName, prNumber, labelName)
+	return nil
+}
+
+func (b *batch) GetIssue(ctx context.Context, repoFullName string, number int) (*models.Issue, error) {
+	return b.db.getIssue(repoFullName, number)
+}
+
+func (b *batch) UpsertIssue(ctx context.Context, issue *models.Issue) (bool, error) {
+	return b.db.upsertIssue(issue)
+}
+
+func (b *batch) DeleteIssue(ctx context.Context, repoFullName string, number int) error {
+	return b.db.deleteIssue(repoFullName, number)
+}
+
+func (b *batch) ListIssueLabels(ctx context.Context, repoFullName string, issueNumber int) ([]*models.Label, error) {
+	return b.db.listIssueLabels(repoFullName, issueNumber)
+}
+
+func (b *batch) AddIssueLabel(ctx context.Context, repoFullName string, issueNumber int, labelName string) error {
+	b.db.addIssueLabel(repoFullName, issueNumber, labelName)
+	return nil
+}
+
+func (b *batch) GetLabel(ctx context.Context, name string) (*models.Label, error) {
+	return b.db.getLabel(name)
+}
+
+func (b *batch) AddLabel(ctx context.Context, label *models.Label) error {
+	b.db.addLabel(label)
+	return nil
+}
@@ -0,0 +1,90 @@
+package file
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	storage "github.com/siddontang/github-repos-management/internal/db"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// TestBatch tests that the writes of a batch are saved together and undone together
+func TestBatch(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "data.db")
+	db, err := NewDB(path)
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	now := time.Now()
+	if err := db.AddPullRequest(ctx, &models.PullRequest{RepositoryFullName: "pingcap/tidb", Number: 1, State: "open", UpdatedAt: now}); err != nil {
+		t.Fatalf("AddPullRequest() error = %v", err)
+	}
+
+	err = db.Batch(ctx, func(tx storage.Tx) error {
+		if _, err := tx.UpsertPullRequest(ctx, &models.PullRequest{RepositoryFullName: "pingcap/tidb", Number: 1, State: "closed", UpdatedAt: now}); err != nil {
+			return err
+		}
+		if _, err := tx.UpsertIssue(ctx, &models.Issue{RepositoryFullName: "pingcap/tidb", Number: 2, State: "open", UpdatedAt: now}); err != nil {
+			return err
+		}
+		if err := tx.AddLabel(ctx, &models.Label{Name: "bug"}); err != nil {
+			return err
+		}
+		return tx.AddIssueLabel(ctx, "pingcap/tidb", 2, "bug")
+	})
+	if err != nil {
+		t.Fatalf("Batch() error = %v", err)
+	}
+	db.Close()
+
+	// The writes were saved
+	db, err = NewDB(path)
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	defer db.Close()
+	if pr, _ := db.GetPullRequest(ctx, "pingcap/tidb", 1); pr == nil || pr.State != "closed" {
+		t.Errorf("pull request = %+v, want it closed by the batch", pr)
+	}
+	if labels, _ := db.ListIssueLabels(ctx, "pingcap/tidb", 2); len(labels) != 1 {
+		t.Errorf("issue labels = %v, want bug", labels)
+	}
+
+	// A failing batch leaves nothing behind, in the data or in the indexes
+	failure := errors.New("sync failed")
+	err = db.Batch(ctx, func(tx storage.Tx) error {
+		if _, err := tx.UpsertPullRequest(ctx, &models.PullRequest{RepositoryFullName: "pingcap/tidb", Number: 1, State: "open", UpdatedAt: now.Add(time.Minute)}); err != nil {
+			return err
+		}
+		if _, err := tx.UpsertPullRequest(ctx, &models.PullRequest{RepositoryFullName: "pingcap/tidb", Number: 3, State: "open", UpdatedAt: now}); err != nil {
+			return err
+		}
+		if err := tx.DeleteIssue(ctx, "pingcap/tidb", 2); err != nil {
+			return err
+		}
+		tx.AddPullRequestLabel(ctx, "pingcap/tidb", 1, "bug")
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("Batch() error = %v, want the error of fn", err)
+	}
+	if pr, _ := db.GetPullRequest(ctx, "pingcap/tidb", 1); pr.State != "closed" {
+		t.Errorf("pull request state = %s, want closed", pr.State)
+	}
+	if _, total, _ := db.ListPullRequests(ctx, "pingcap/tidb", 1, 10); total != 1 {
+		t.Errorf("pull requests = %d, want 1", total)
+	}
+	if _, err := db.GetIssue(ctx, "pingcap/tidb", 2); err != nil {
+		t.Errorf("GetIssue() error = %v, want the deleted issue back", err)
+	}
+	if labels, _ := db.ListPullRequestLabels(ctx, "pingcap/tidb", 1); len(labels) != 0 {
+		t.Errorf("pull request labels = %v, want none", labels)
+	}
+	if open, _ := db.FindPullRequests(ctx, &models.ItemQuery{State: "open"}); len(open) != 0 {
+		t.Errorf("open pull requests = %d, want none in the index", len(open))
+	}
+}
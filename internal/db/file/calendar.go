@@ -0,0 +1,99 @@
+package file
+
+import (
+	"context"
+	"sort"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// Milestone and release operations. Both are replaced as a whole on each sync
+// and returned as copies.
+
+// ReplaceMilestones replaces the milestones of a repository
+func (db *DB) ReplaceMilestones(ctx context.Context, repoFullName string, milestones []*models.Milestone) error {
+	db.Lock()
+	defer db.Unlock()
+
+	if _, ok := db.repositories[repoFullName]; !ok {
+		return db.ErrRepositoryNotFound(repoFullName)
+	}
+
+	stored := make([]*models.Milestone, 0, len(milestones))
+	for _, milestone := range milestones {
+		clone := *milestone
+		clone.RepositoryFullName = repoFullName
+		stored = append(stored, &clone)
+	}
+	db.milestones[repoFullName] = stored
+	return db.sync()
+}
+
+// ListMilestones lists the milestones of a repository, or of every repository when
+// repoFullName is empty, ordered by repository and number
+func (db *DB) ListMilestones(ctx context.Context, repoFullName string) ([]*models.Milestone, error) {
+	db.RLock()
+	defer db.RUnlock()
+
+	milestones := make([]*models.Milestone, 0)
+	for fullName, list := range db.milestones {
+		if repoFullName != "" && fullName != repoFullName {
+			continue
+		}
+		for _, milestone := range list {
+			clone := *milestone
+			milestones = append(milestones, &clone)
+		}
+	}
+	sort.Slice(milestones, func(i, j int) bool {
+		if milestones[i].RepositoryFullName != milestones[j].RepositoryFullName {
+			return milestones[i].RepositoryFullName < milestones[j].RepositoryFullName
+		}
+		return milestones[i].Number < milestones[j].Number
+	})
+	return milestones, nil
+}
+
+// ReplaceReleases replaces the releases of a repository
+func (db *DB) ReplaceReleases(ctx context.Context, repoFullName string, releases []*models.Release) error {
+	db.Lock()
+	defer db.Unlock()
+
+	if _, ok := db.repositories[repoFullName]; !ok {
+		return db.ErrRepositoryNotFound(repoFullName)
+	}
+
+	stored := make([]*models.Release, 0, len(releases))
+	for _, release := range releases {
+		clone := *release
+		clone.RepositoryFullName = repoFullName
+		stored = append(stored, &clone)
+	}
+	db.releases[repoFullName] = stored
+	return db.sync()
+}
+
+// ListReleases lists the releases of a repository, or of every repository when
+// repoFullName is empty, ordered by repository and publication date
+func (db *DB) ListReleases(ctx context.Context, repoFullName string) ([]*models.Release, error) {
+	db.RLock()
+	defer db.RUnlock()
+
+	releases := make([]*models.Release, 0)
+	for fullName, list := range db.releases {
+		if repoFullName != "" && fullName != repoFullName {
+			continue
+		}
+		for _, release := range list {
+			clone := *release
+			releases = append(releases, &clone)
+		}
+	}
+	sort.Slice(releases, func(i, j int) bool {
+		if releases[i].RepositoryFullName != releases[j].RepositoryFullName {
+			return releases[i].RepositoryFullName < releases[j].RepositoryFullName
+		}
+		return releases[i].PublishedAt.Before(releases[j].PublishedAt)
+	})
+	return releases, nil
+}
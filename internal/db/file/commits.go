@@ -0,0 +1,56 @@
+package file
+
+import (
+	"context"
+	"sort"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// Commit operations. Commits are replaced as a whole on each sync and returned as copies.
+
+// ReplaceCommits replaces the stored commits of a repository
+func (db *DB) ReplaceCommits(ctx context.Context, repoFullName string, commits []*models.Commit) error {
+	db.Lock()
+	defer db.Unlock()
+
+	if _, ok := db.repositories[repoFullName]; !ok {
+		return db.ErrRepositoryNotFound(repoFullName)
+	}
+
+	stored := make([]*models.Commit, 0, len(commits))
+	for _, commit := range commits {
+		clone := *commit
+		clone.RepositoryFullName = repoFullName
+		stored = append(stored, &clone)
+	}
+	sort.SliceStable(stored, func(i, j int) bool { return stored[i].CommittedAt.After(stored[j].CommittedAt) })
+	db.commits[repoFullName] = stored
+	return db.sync()
+}
+
+// ListCommits lists the stored commits of a repository, or of every repository when
+// repoFullName is empty, newest first
+func (db *DB) ListCommits(ctx context.Context, repoFullName string) ([]*models.Commit, error) {
+	db.RLock()
+	defer db.RUnlock()
+
+	commits := make([]*models.Commit, 0)
+	for fullName, list := range db.commits {
+		if repoFullName != "" && fullName != repoFullName {
+			continue
+		}
+		for _, commit := range list {
+			clone := *commit
+			commits = append(commits, &clone)
+		}
+	}
+	sort.SliceStable(commits, func(i, j int) bool {
+		a, b := commits[i], commits[j]
+		if !a.CommittedAt.Equal(b.CommittedAt) {
+			return a.CommittedAt.After(b.CommittedAt)
+		}
+		return a.RepositoryFullName < b.RepositoryFullName
+	})
+	return commits, nil
+}
@@ -0,0 +1,130 @@
+package file
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	storage "github.com/siddontang/github-repos-management/internal/db"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// TestUpdateRepositoryVersionConflict tests that an update based on an outdated read is rejected
+func TestUpdateRepositoryVersionConflict(t *testing.T) {
+	ctx := context.Background()
+	db, err := NewDB("")
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	if err := db.AddRepository(ctx, &models.Repository{Owner: "pingcap", Name: "tidb", FullName: "pingcap/tidb"}); err != nil {
+		t.Fatalf("AddRepository() error = %v", err)
+	}
+
+	webhook, _ := db.GetRepository(ctx, "pingcap", "tidb")
+	sync, _ := db.GetRepository(ctx, "pingcap", "tidb")
+
+	webhook.Tags = []string{"core"}
+	if err := db.UpdateRepository(ctx, webhook); err != nil {
+		t.Fatalf("UpdateRepository() error = %v", err)
+	}
+	if webhook.Version != 1 {
+		t.Errorf("UpdateRepository() Version = %d, want 1", webhook.Version)
+	}
+
+	sync.LastSyncedAt = time.Now()
+	if err := db.UpdateRepository(ctx, sync); !errors.Is(err, storage.ErrVersionConflict) {
+		t.Fatalf("UpdateRepository() error = %v, want ErrVersionConflict", err)
+	}
+
+	stored, _ := db.GetRepository(ctx, "pingcap", "tidb")
+	if !stored.HasTag("core") || !stored.LastSyncedAt.IsZero() {
+		t.Errorf("stored repository = %+v, want the first update only", stored)
+	}
+
+	// Readers get copies, so changing one doesn't touch the stored repository
+	stored.Tags[0] = "changed"
+	if again, _ := db.GetRepository(ctx, "pingcap", "tidb"); !again.HasTag("core") {
+		t.Errorf("GetRepository() Tags = %v, want [core]", again.Tags)
+	}
+}
+
+// TestUpdateItemStaleWrite tests that pull requests and issues are not overwritten by older data
+func TestUpdateItemStaleWrite(t *testing.T) {
+	ctx := context.Background()
+	db, err := NewDB("")
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+
+	now := time.Now()
+	if err := db.AddPullRequest(ctx, &models.PullRequest{RepositoryFullName: "pingcap/tidb", Number: 1, State: "closed", UpdatedAt: now}); err != nil {
+		t.Fatalf("AddPullRequest() error = %v", err)
+	}
+	err = db.UpdatePullRequest(ctx, &models.PullRequest{RepositoryFullName: "pingcap/tidb", Number: 1, State: "open", UpdatedAt: now.Add(-time.Minute)})
+	if !errors.Is(err, storage.ErrStaleWrite) {
+		t.Fatalf("UpdatePullRequest() error = %v, want ErrStaleWrite", err)
+	}
+	if pr, _ := db.GetPullRequest(ctx, "pingcap/tidb", 1); pr.State != "closed" {
+		t.Errorf("pull request state = %s, want closed", pr.State)
+	}
+	if err := db.UpdatePullRequest(ctx, &models.PullRequest{RepositoryFullName: "pingcap/tidb", Number: 1, State: "merged", UpdatedAt: now}); err != nil {
+		t.Errorf("UpdatePullRequest() with the same updated time error = %v", err)
+	}
+
+	if err := db.AddIssue(ctx, &models.Issue{RepositoryFullName: "pingcap/tidb", Number: 2, State: "closed", UpdatedAt: now}); err != nil {
+		t.Fatalf("AddIssue() error = %v", err)
+	}
+	err = db.UpdateIssue(ctx, &models.Issue{RepositoryFullName: "pingcap/tidb", Number: 2, State: "open", UpdatedAt: now.Add(-time.Minute)})
+	if !errors.Is(err, storage.ErrStaleWrite) {
+		t.Fatalf("UpdateIssue() error = %v, want ErrStaleWrite", err)
+	}
+	if err := db.UpdateIssue(ctx, &models.Issue{RepositoryFullName: "pingcap/tidb", Number: 2, State: "open", UpdatedAt: now.Add(time.Minute)}); err != nil {
+		t.Errorf("UpdateIssue() with a newer updated time error = %v", err)
+	}
+}
+
+// TestUpsert tests that upserts add missing records, replace stored ones and report which they did
+func TestUpsert(t *testing.T) {
+	ctx := context.Background()
+	db, err := NewDB("")
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+
+	repo := &models.Repository{Owner: "pingcap", Name: "tidb", FullName: "pingcap/tidb"}
+	if created, err := db.UpsertRepository(ctx, repo); err != nil || !created {
+		t.Fatalf("UpsertRepository() = %v, %v; want created", created, err)
+	}
+	repo.Description = "TiDB"
+	if created, err := db.UpsertRepository(ctx, repo); err != nil || created || repo.Version != 1 {
+		t.Fatalf("UpsertRepository() again = %v, %v, version %d; want updated to version 1", created, err, repo.Version)
+	}
+	if _, err := db.UpsertRepository(ctx, &models.Repository{Owner: "pingcap", Name: "tidb", FullName: "pingcap/tidb"}); !errors.Is(err, storage.ErrVersionConflict) {
+		t.Errorf("UpsertRepository() of an outdated repository error = %v, want ErrVersionConflict", err)
+	}
+	if stored, _ := db.GetRepository(ctx, "pingcap", "tidb"); stored.Description != "TiDB" {
+		t.Errorf("stored description = %q, want TiDB", stored.Description)
+	}
+
+	now := time.Now()
+	if created, err := db.UpsertPullRequest(ctx, &models.PullRequest{RepositoryFullName: "pingcap/tidb", Number: 1, State: "open", UpdatedAt: now}); err != nil || !created {
+		t.Fatalf("UpsertPullRequest() = %v, %v; want created", created, err)
+	}
+	if created, err := db.UpsertPullRequest(ctx, &models.PullRequest{RepositoryFullName: "pingcap/tidb", Number: 1, State: "merged", UpdatedAt: now}); err != nil || created {
+		t.Fatalf("UpsertPullRequest() again = %v, %v; want updated", created, err)
+	}
+	if _, err := db.UpsertPullRequest(ctx, &models.PullRequest{RepositoryFullName: "pingcap/tidb", Number: 1, State: "open", UpdatedAt: now.Add(-time.Minute)}); !errors.Is(err, storage.ErrStaleWrite) {
+		t.Errorf("UpsertPullRequest() of an older pull request error = %v, want ErrStaleWrite", err)
+	}
+	if prs, total, _ := db.ListPullRequests(ctx, "pingcap/tidb", 1, 10); total != 1 || prs[0].State != "merged" {
+		t.Errorf("pull requests = %d, want the merged one only", total)
+	}
+
+	if created, err := db.UpsertIssue(ctx, &models.Issue{RepositoryFullName: "pingcap/tidb", Number: 2, State: "open", UpdatedAt: now}); err != nil || !created {
+		t.Fatalf("UpsertIssue() = %v, %v; want created", created, err)
+	}
+	if created, err := db.UpsertIssue(ctx, &models.Issue{RepositoryFullName: "pingcap/tidb", Number: 2, State: "closed", UpdatedAt: now.Add(time.Minute)}); err != nil || created {
+		t.Errorf("UpsertIssue() again = %v, %v; want updated", created, err)
+	}
+}
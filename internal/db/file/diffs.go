@@ -0,0 +1,51 @@
+package file
+
+import (
+	"context"
+	"fmt"
+
+	storage "github.com/siddontang/github-repos-management/internal/db"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// Pull request diff operations. Diffs are cached per pull request and format and returned as copies.
+
+// SavePullRequestDiff caches a diff, replacing the one of the same pull request and format
+func (db *DB) SavePullRequestDiff(ctx context.Context, diff *models.PullRequestDiff) error {
+	db.Lock()
+	defer db.Unlock()
+
+	if _, ok := db.repositories[diff.RepositoryFullName]; !ok {
+		return db.ErrRepositoryNotFound(diff.RepositoryFullName)
+	}
+
+	stored := *diff
+	stored.Cached = false
+	diffs := db.diffs[diff.RepositoryFullName]
+	for i, existing := range diffs {
+		if existing.Number == diff.Number && existing.Format == diff.Format {
+			diffs[i] = &stored
+			return db.sync()
+		}
+	}
+	db.diffs[diff.RepositoryFullName] = append(diffs, &stored)
+	return db.sync()
+}
+
+// GetPullRequestDiff gets the cached diff of a pull request in a format
+func (db *DB) GetPullRequestDiff(ctx context.Context, repoFullName string, number int, format string) (*models.PullRequestDiff, error) {
+	db.RLock()
+	defer db.RUnlock()
+
+	for _, diff := range db.diffs[repoFullName] {
+		if diff.Number == number && diff.Format == format {
+			clone := *diff
+			return &clone, nil
+		}
+	}
+	return nil, db.ErrDiffNotFound(repoFullName, number, format)
+}
+
+func (db *DB) ErrDiffNotFound(fullName string, number int, format string) error {
+	return fmt.Errorf("%s of pull request %d in repository %s %w", format, number, fullName, storage.ErrNotFound)
+}
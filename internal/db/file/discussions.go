@@ -0,0 +1,59 @@
+package file
+
+import (
+	"context"
+	"sort"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// Discussion operations. Discussions are replaced as a whole on each sync and returned as copies.
+
+// ReplaceDiscussions replaces the stored discussions of a repository
+func (db *DB) ReplaceDiscussions(ctx context.Context, repoFullName string, discussions []*models.Discussion) error {
+	db.Lock()
+	defer db.Unlock()
+
+	if _, ok := db.repositories[repoFullName]; !ok {
+		return db.ErrRepositoryNotFound(repoFullName)
+	}
+
+	stored := make([]*models.Discussion, 0, len(discussions))
+	for _, discussion := range discussions {
+		clone := *discussion
+		clone.RepositoryFullName = repoFullName
+		stored = append(stored, &clone)
+	}
+	sort.SliceStable(stored, func(i, j int) bool { return stored[i].UpdatedAt.After(stored[j].UpdatedAt) })
+	db.discussions[repoFullName] = stored
+	return db.sync()
+}
+
+// ListDiscussions lists the stored discussions of a repository, or of every repository when
+// repoFullName is empty, most recently updated first
+func (db *DB) ListDiscussions(ctx context.Context, repoFullName string) ([]*models.Discussion, error) {
+	db.RLock()
+	defer db.RUnlock()
+
+	discussions := make([]*models.Discussion, 0)
+	for fullName, list := range db.discussions {
+		if repoFullName != "" && fullName != repoFullName {
+			continue
+		}
+		for _, discussion := range list {
+			clone := *discussion
+			discussions = append(discussions, &clone)
+		}
+	}
+	sort.SliceStable(discussions, func(i, j int) bool {
+		a, b := discussions[i], discussions[j]
+		if !a.UpdatedAt.Equal(b.UpdatedAt) {
+			return a.UpdatedAt.After(b.UpdatedAt)
+		}
+		if a.RepositoryFullName != b.RepositoryFullName {
+			return a.RepositoryFullName < b.RepositoryFullName
+		}
+		return a.Number < b.Number
+	})
+	return discussions, nil
+}
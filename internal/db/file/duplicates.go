@@ -0,0 +1,59 @@
+package file
+
+import (
+	"context"
+	"sort"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// Duplicate issue operations. Candidates are replaced as a whole on each detection and returned as copies.
+
+// ReplaceDuplicates replaces the stored duplicate candidates of a repository
+func (db *DB) ReplaceDuplicates(ctx context.Context, repoFullName string, candidates []*models.DuplicateCandidate) error {
+	db.Lock()
+	defer db.Unlock()
+
+	if _, ok := db.repositories[repoFullName]; !ok {
+		return db.ErrRepositoryNotFound(repoFullName)
+	}
+
+	stored := make([]*models.DuplicateCandidate, 0, len(candidates))
+	for _, candidate := range candidates {
+		clone := *candidate
+		clone.RepositoryFullName = repoFullName
+		stored = append(stored, &clone)
+	}
+	sort.SliceStable(stored, func(i, j int) bool { return stored[i].Score > stored[j].Score })
+	db.duplicates[repoFullName] = stored
+	return db.sync()
+}
+
+// ListDuplicates lists the stored duplicate candidates of a repository, or of every repository when
+// repoFullName is empty, best match first
+func (db *DB) ListDuplicates(ctx context.Context, repoFullName string) ([]*models.DuplicateCandidate, error) {
+	db.RLock()
+	defer db.RUnlock()
+
+	candidates := make([]*models.DuplicateCandidate, 0)
+	for fullName, list := range db.duplicates {
+		if repoFullName != "" && fullName != repoFullName {
+			continue
+		}
+		for _, candidate := range list {
+			clone := *candidate
+			candidates = append(candidates, &clone)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.RepositoryFullName != b.RepositoryFullName {
+			return a.RepositoryFullName < b.RepositoryFullName
+		}
+		return a.Number < b.Number
+	})
+	return candidates, nil
+}
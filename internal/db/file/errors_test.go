@@ -0,0 +1,48 @@
+package file
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	storage "github.com/siddontang/github-repos-management/internal/db"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// TestErrorKinds tests that errors match the storage error kinds, telling missing records
+// from failures
+func TestErrorKinds(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	db, err := NewDB(filepath.Join(dir, "data", "data.db"))
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	defer db.Close()
+
+	for name, err := range map[string]error{
+		"GetRepository":  func() error { _, err := db.GetRepository(ctx, "pingcap", "tidb"); return err }(),
+		"GetPullRequest": func() error { _, err := db.GetPullRequest(ctx, "pingcap/tidb", 1); return err }(),
+		"GetWorkspace":   func() error { _, err := db.GetWorkspace(ctx, "infra"); return err }(),
+		"GetJob":         func() error { _, err := db.GetJob(ctx, 1); return err }(),
+		"DeleteWebhook":  db.DeleteWebhook(ctx, 1),
+	} {
+		if !errors.Is(err, storage.ErrNotFound) || errors.Is(err, storage.ErrUnavailable) {
+			t.Errorf("%s() error = %v, want ErrNotFound", name, err)
+		}
+	}
+	if !errors.Is(storage.ErrVersionConflict, storage.ErrConflict) || !errors.Is(storage.ErrStaleWrite, storage.ErrConflict) {
+		t.Error("lost races should match ErrConflict")
+	}
+
+	// Writes that can't reach the disk are failures, not missing records
+	if err := os.RemoveAll(filepath.Join(dir, "data")); err != nil {
+		t.Fatal(err)
+	}
+	err = db.AddRepository(ctx, &models.Repository{Owner: "pingcap", Name: "tidb", FullName: "pingcap/tidb"})
+	if !errors.Is(err, storage.ErrUnavailable) || errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("AddRepository() without the data directory error = %v, want ErrUnavailable", err)
+	}
+}
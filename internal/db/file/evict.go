@@ -0,0 +1,176 @@
+package file
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// Limits bounds the data kept by the database. Zero values mean unlimited.
+type Limits struct {
+	MaxRepositories       int
+	MaxItemsPerRepository int
+	MaxMemoryBytes        int64
+}
+
+// itemOverhead approximates the fixed memory cost of a pull request or issue
+const itemOverhead = 512
+
+// itemSize estimates the memory used by a pull request or issue
+func itemSize(title, body string) int64 {
+	return int64(itemOverhead + len(title) + len(body))
+}
+
+// evictionCandidate identifies a pull request or issue that may be evicted
+type evictionCandidate struct {
+	repo      string
+	number    int
+	isPR      bool
+	closed    bool
+	updatedAt time.Time
+	size      int64
+}
+
+// SetLimits sets the limits enforced when data is added
+func (db *DB) SetLimits(limits Limits) {
+	db.Lock()
+	defer db.Unlock()
+
+	db.limits = limits
+}
+
+// candidates returns the eviction candidates of a repository, or of all repositories when repo is empty,
+// ordered so that closed items come before open ones and least recently updated items come first
+func (db *DB) candidates(repo string, prs, issues bool) []evictionCandidate {
+	var list []evictionCandidate
+	if prs {
+		for fullName, repoPRs := range db.pullRequests {
+			if repo != "" && fullName != repo {
+				continue
+			}
+			for number, pr := range repoPRs {
+				list = append(list, evictionCandidate{
+					repo:      fullName,
+					number:    number,
+					isPR:      true,
+					closed:    !strings.EqualFold(pr.State, "open"),
+					updatedAt: pr.UpdatedAt,
+					size:      itemSize(pr.Title, pr.Body),
+				})
+			}
+		}
+	}
+	if issues {
+		for fullName, repoIssues := range db.issues {
+			if repo != "" && fullName != repo {
+				continue
+			}
+			for number, issue := range repoIssues {
+				list = append(list, evictionCandidate{
+					repo:      fullName,
+					number:    number,
+					closed:    !strings.EqualFold(issue.State, "open"),
+					updatedAt: issue.UpdatedAt,
+					size:      itemSize(issue.Title, issue.Body),
+				})
+			}
+		}
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].closed != list[j].closed {
+			return list[i].closed
+		}
+		if !list[i].updatedAt.Equal(list[j].updatedAt) {
+			return list[i].updatedAt.Before(list[j].updatedAt)
+		}
+		if list[i].repo != list[j].repo {
+			return list[i].repo < list[j].repo
+		}
+		return list[i].number < list[j].number
+	})
+	return list
+}
+
+// estimateMemory returns the estimated size of all pull requests and issues
+func (db *DB) estimateMemory() int64 {
+	var total int64
+	for _, c := range db.candidates("", true, true) {
+		total += c.size
+	}
+	return total
+}
+
+// enforceLimits evicts pull requests or issues until the limits hold.
+// It must be called with the write lock held.
+func (db *DB) enforceLimits(repo string, isPR bool) {
+	if max := db.limits.MaxItemsPerRepository; max > 0 {
+		count := len(db.issues[repo])
+		if isPR {
+			count = len(db.pullRequests[repo])
+		}
+		for _, c := range db.candidates(repo, isPR, !isPR) {
+			if count <= max {
+				break
+			}
+			db.evict(c)
+			count--
+		}
+	}
+
+	if max := db.limits.MaxMemoryBytes; max > 0 {
+		total := db.estimateMemory()
+		for _, c := range db.candidates("", true, true) {
+			if total <= max {
+				break
+			}
+			db.evict(c)
+			total -= c.size
+		}
+	}
+}
+
+// evict removes a pull request or issue and its labels, counting the eviction
+func (db *DB) evict(c evictionCandidate) {
+	db.drop(c)
+	if c.isPR {
+		db.evictedPRs++
+	} else {
+		db.evictedIssues++
+	}
+}
+
+// drop removes a pull request or issue and its labels
+func (db *DB) drop(c evictionCandidate) {
+	if c.isPR {
+		delete(db.pullRequests[c.repo], c.number)
+		db.repoPRs[c.repo] = removeNumber(db.repoPRs[c.repo], c.number)
+		delete(db.prLabels[c.repo], c.number)
+		db.prIndex.remove(itemKey{repo: c.repo, number: c.number})
+		return
+	}
+
+	delete(db.issues[c.repo], c.number)
+	db.repoIssues[c.repo] = removeNumber(db.repoIssues[c.repo], c.number)
+	delete(db.issueLabels[c.repo], c.number)
+	db.issueIndex.remove(itemKey{repo: c.repo, number: c.number})
+}
+
+// insertNumber inserts number into the sorted numbers, keeping them sorted
+func insertNumber(numbers []int, number int) []int {
+	i := sort.SearchInts(numbers, number)
+	numbers = append(numbers, 0)
+	copy(numbers[i+1:], numbers[i:])
+	numbers[i] = number
+	return numbers
+}
+
+// removeNumber removes the first occurrence of number from numbers
+func removeNumber(numbers []int, number int) []int {
+	for i, n := range numbers {
+		if n == number {
+			return append(numbers[:i], numbers[i+1:]...)
+		}
+	}
+	return numbers
+}
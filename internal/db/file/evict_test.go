@@ -0,0 +1,64 @@
+package file
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// TestEnforceLimits tests that closed and least recently updated items are evicted first
+func TestEnforceLimits(t *testing.T) {
+	ctx := context.Background()
+	db, err := NewDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	db.SetLimits(Limits{MaxRepositories: 1, MaxItemsPerRepository: 2})
+
+	if err := db.AddRepository(ctx, &models.Repository{FullName: "pingcap/tidb"}); err != nil {
+		t.Fatalf("AddRepository() error = %v", err)
+	}
+	if err := db.AddRepository(ctx, &models.Repository{FullName: "pingcap/tikv"}); err == nil {
+		t.Errorf("AddRepository() expected repository limit error")
+	}
+
+	now := time.Now()
+	prs := []*models.PullRequest{
+		{Number: 1, State: "open", UpdatedAt: now.Add(-3 * time.Hour)},
+		{Number: 2, State: "closed", UpdatedAt: now.Add(-time.Hour)},
+		{Number: 3, State: "open", UpdatedAt: now.Add(-2 * time.Hour)},
+		{Number: 4, State: "open", UpdatedAt: now},
+	}
+	for _, pr := range prs {
+		pr.RepositoryFullName = "pingcap/tidb"
+		if err := db.AddPullRequest(ctx, pr); err != nil {
+			t.Fatalf("AddPullRequest() error = %v", err)
+		}
+	}
+
+	got, total, err := db.ListPullRequests(ctx, "pingcap/tidb", 1, 10)
+	if err != nil {
+		t.Fatalf("ListPullRequests() error = %v", err)
+	}
+	if total != 2 {
+		t.Fatalf("ListPullRequests() total = %d, want 2", total)
+	}
+	kept := map[int]bool{}
+	for _, pr := range got {
+		kept[pr.Number] = true
+	}
+	if !kept[3] || !kept[4] {
+		t.Errorf("kept pull requests = %v, want 3 and 4", kept)
+	}
+
+	stats, err := db.Stats(ctx)
+	if err != nil {
+		t.Fatalf("Stats() error = %v", err)
+	}
+	if stats.EvictedPullRequests != 2 {
+		t.Errorf("Stats().EvictedPullRequests = %d, want 2", stats.EvictedPullRequests)
+	}
+}
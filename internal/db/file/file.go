@@ -6,8 +6,13 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
+	storage "github.com/siddontang/github-repos-management/internal/db"
 	"github.com/siddontang/github-repos-management/internal/models"
 )
 
@@ -18,6 +23,15 @@ type DB struct {
 	// File path for persistence
 	path string
 
+	// Lock held on path + ".lock" while the file is open for writing, and whether
+	// the file was opened read-only instead
+	lock     *os.File
+	readOnly bool
+	closed   bool
+
+	// Whether the file is written as indented JSON
+	indent bool
+
 	// In-memory data structures
 	repositories map[string]*models.Repository
 	pullRequests map[string]map[int]*models.PullRequest
@@ -30,10 +44,88 @@ type DB struct {
 	repoLabels  map[string]map[string]*models.Label
 	prLabels    map[string]map[int][]string
 	issueLabels map[string]map[int][]string
+
+	// Webhooks and their delivery logs
+	webhooks          map[int64]*models.Webhook
+	webhookDeliveries map[int64][]*models.WebhookDelivery
+	nextWebhookID     int64
+	nextDeliveryID    int64
+
+	// Label subscriptions
+	subscriptions      map[int64]*models.Subscription
+	nextSubscriptionID int64
+
+	// Triage rules and the items each acted on
+	triageRules      map[int64]*models.TriageRule
+	triageExecutions map[int64][]*models.TriageExecution
+	nextTriageRuleID int64
+
+	// Append-only activity log, oldest first
+	activity       []*models.ActivityEvent
+	nextActivityID int64
+
+	// Per repository metric snapshots, oldest first
+	snapshots map[string][]*models.RepositorySnapshot
+
+	// Per repository milestones and releases, replaced on each sync
+	milestones map[string][]*models.Milestone
+	releases   map[string][]*models.Release
+
+	// Per repository open security alerts, replaced on each sync
+	alerts map[string][]*models.SecurityAlert
+
+	// Per repository default branch commits within the lookback window, newest first
+	commits map[string][]*models.Commit
+
+	// Per repository probable duplicate issues, best match first
+	duplicates map[string][]*models.DuplicateCandidate
+
+	// Per repository discussions, most recently updated first
+	discussions map[string][]*models.Discussion
+
+	// Per repository linked projects and project items, replaced on each sync
+	projects     map[string][]*models.Project
+	projectItems map[string][]*models.ProjectItem
+
+	// Per repository pull request diffs and patches cached for offline viewing
+	diffs map[string][]*models.PullRequestDiff
+
+	// Workspaces by ID
+	workspaces map[string]*models.Workspace
+
+	// Append-only audit log, oldest first
+	audit       []*models.AuditEntry
+	nextAuditID int64
+
+	// Background jobs ordered by ID
+	jobs      []*models.Job
+	nextJobID int64
+
+	// Secondary indexes of pull requests and issues
+	prIndex    *itemIndex
+	issueIndex *itemIndex
+
+	// Limits and eviction counters
+	limits        Limits
+	evictedPRs    int64
+	evictedIssues int64
+
+	// Snapshot of the data taken by the first Snapshot after a write, and the number of writes so
+	// far, telling whether it is still current
+	snapshot   atomic.Pointer[dbSnapshot]
+	generation atomic.Uint64
 }
 
+// maxWebhookDeliveries is the number of delivery logs kept per webhook
+const maxWebhookDeliveries = 100
+
+// maxJobs is the number of jobs kept; the oldest finished jobs are dropped first
+const maxJobs = 1000
+
 // data represents the structure for file persistence
 type data struct {
+	SchemaVersion int `json:"schema_version"`
+
 	Repositories map[string]*models.Repository          `json:"repositories"`
 	PullRequests map[string]map[int]*models.PullRequest `json:"pull_requests"`
 	Issues       map[string]map[int]*models.Issue       `json:"issues"`
@@ -43,12 +135,73 @@ type data struct {
 	RepoLabels   map[string]map[string]*models.Label    `json:"repo_labels"`
 	PRLabels     map[string]map[int][]string            `json:"pr_labels"`
 	IssueLabels  map[string]map[int][]string            `json:"issue_labels"`
+
+	Webhooks          map[int64]*models.Webhook           `json:"webhooks"`
+	WebhookDeliveries map[int64][]*models.WebhookDelivery `json:"webhook_deliveries"`
+	NextWebhookID     int64                               `json:"next_webhook_id"`
+	NextDeliveryID    int64                               `json:"next_delivery_id"`
+
+	Subscriptions      map[int64]*models.Subscription `json:"subscriptions"`
+	NextSubscriptionID int64                          `json:"next_subscription_id"`
+
+	TriageRules      map[int64]*models.TriageRule        `json:"triage_rules"`
+	TriageExecutions map[int64][]*models.TriageExecution `json:"triage_executions"`
+	NextTriageRuleID int64                               `json:"next_triage_rule_id"`
+
+	Activity       []*models.ActivityEvent `json:"activity"`
+	NextActivityID int64                   `json:"next_activity_id"`
+
+	Snapshots map[string][]*models.RepositorySnapshot `json:"snapshots"`
+
+	Milestones map[string][]*models.Milestone `json:"milestones"`
+	Releases   map[string][]*models.Release   `json:"releases"`
+
+	Alerts map[string][]*models.SecurityAlert `json:"alerts"`
+
+	Commits map[string][]*models.Commit `json:"commits"`
+
+	Duplicates map[string][]*models.DuplicateCandidate `json:"duplicates"`
+
+	Discussions map[string][]*models.Discussion `json:"discussions"`
+
+	Projects     map[string][]*models.Project     `json:"projects"`
+	ProjectItems map[string][]*models.ProjectItem `json:"project_items"`
+
+	Diffs map[string][]*models.PullRequestDiff `json:"diffs"`
+
+	Workspaces map[string]*models.Workspace `json:"workspaces"`
+
+	Jobs      []*models.Job `json:"jobs"`
+	NextJobID int64         `json:"next_job_id"`
+
+	Audit       []*models.AuditEntry `json:"audit"`
+	NextAuditID int64                `json:"next_audit_id"`
+
+	Secrets *secrets `json:"secrets,omitempty"`
+}
+
+// Options configures how a file database is opened
+type Options struct {
+	// ReadOnly loads the file without locking it, so it can be read while another process
+	// has it open for writing. Changes are not saved: writes fail with db.ErrReadOnly.
+	ReadOnly bool
+	// Indent writes the file as indented JSON, easier to read by hand but larger
+	Indent bool
 }
 
-// NewDB creates a new file-based database
+// NewDB creates a new file-based database. An empty path keeps the data in memory only.
 func NewDB(path string) (*DB, error) {
+	return NewDBWithOptions(path, Options{})
+}
+
+// NewDBWithOptions creates a new file-based database with the given options. The file is
+// locked while it is open for writing; opening it a second time for writing, from this or
+// another process, fails with db.ErrDatabaseInUse.
+func NewDBWithOptions(path string, opts Options) (*DB, error) {
 	db := &DB{
 		path:         path,
+		readOnly:     opts.ReadOnly && path != "",
+		indent:       opts.Indent,
 		repositories: make(map[string]*models.Repository),
 		pullRequests: make(map[string]map[int]*models.PullRequest),
 		issues:       make(map[string]map[int]*models.Issue),
@@ -58,17 +211,52 @@ func NewDB(path string) (*DB, error) {
 		repoLabels:   make(map[string]map[string]*models.Label),
 		prLabels:     make(map[string]map[int][]string),
 		issueLabels:  make(map[string]map[int][]string),
+
+		webhooks:          make(map[int64]*models.Webhook),
+		webhookDeliveries: make(map[int64][]*models.WebhookDelivery),
+		subscriptions:     make(map[int64]*models.Subscription),
+		triageRules:       make(map[int64]*models.TriageRule),
+		triageExecutions:  make(map[int64][]*models.TriageExecution),
+		snapshots:         make(map[string][]*models.RepositorySnapshot),
+		milestones:        make(map[string][]*models.Milestone),
+		releases:          make(map[string][]*models.Release),
+		alerts:            make(map[string][]*models.SecurityAlert),
+		commits:           make(map[string][]*models.Commit),
+		duplicates:        make(map[string][]*models.DuplicateCandidate),
+		discussions:       make(map[string][]*models.Discussion),
+		projects:          make(map[string][]*models.Project),
+		projectItems:      make(map[string][]*models.ProjectItem),
+		diffs:             make(map[string][]*models.PullRequestDiff),
+		workspaces:        make(map[string]*models.Workspace),
+
+		prIndex:    newItemIndex(),
+		issueIndex: newItemIndex(),
+	}
+
+	// Without a path the data is kept in memory only
+	if path == "" {
+		return db, nil
 	}
 
 	// Create directory if it doesn't exist
-	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
-		return nil, fmt.Errorf("failed to create directory: %v", err)
+	if !db.readOnly {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create directory: %v", err)
+		}
+
+		// Keep other writers out until Close
+		lock, err := lockFile(path + ".lock")
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", err, path)
+		}
+		db.lock = lock
 	}
 
 	// Load existing data if file exists
 	if _, err := os.Stat(path); err == nil {
 		if err := db.load(); err != nil {
-			return nil, fmt.Errorf("failed to load data: %v", err)
+			db.unlock()
+			return nil, fmt.Errorf("failed to load data: %w", err)
 		}
 	}
 
@@ -86,6 +274,21 @@ func (db *DB) load() error {
 	if err := json.Unmarshal(file, &d); err != nil {
 		return err
 	}
+	if err := checkSchemaVersion(d.SchemaVersion); err != nil {
+		return err
+	}
+	migratedFrom := -1
+	if d.SchemaVersion < schemaVersion {
+		migratedFrom = d.SchemaVersion
+		migrated, err := migrate(file, d.SchemaVersion)
+		if err != nil {
+			return err
+		}
+		d = data{}
+		if err := json.Unmarshal(migrated, &d); err != nil {
+			return err
+		}
+	}
 
 	db.repositories = d.Repositories
 	db.pullRequests = d.PullRequests
@@ -97,12 +300,111 @@ func (db *DB) load() error {
 	db.prLabels = d.PRLabels
 	db.issueLabels = d.IssueLabels
 
+	// Files written before webhooks existed have no webhook data
+	db.webhooks = d.Webhooks
+	if db.webhooks == nil {
+		db.webhooks = make(map[int64]*models.Webhook)
+	}
+	db.webhookDeliveries = d.WebhookDeliveries
+	if db.webhookDeliveries == nil {
+		db.webhookDeliveries = make(map[int64][]*models.WebhookDelivery)
+	}
+	db.nextWebhookID = d.NextWebhookID
+	db.nextDeliveryID = d.NextDeliveryID
+	db.subscriptions = d.Subscriptions
+	if db.subscriptions == nil {
+		db.subscriptions = make(map[int64]*models.Subscription)
+	}
+	db.nextSubscriptionID = d.NextSubscriptionID
+	db.triageRules = d.TriageRules
+	if db.triageRules == nil {
+		db.triageRules = make(map[int64]*models.TriageRule)
+	}
+	db.triageExecutions = d.TriageExecutions
+	if db.triageExecutions == nil {
+		db.triageExecutions = make(map[int64][]*models.TriageExecution)
+	}
+	db.nextTriageRuleID = d.NextTriageRuleID
+	db.activity = d.Activity
+	db.nextActivityID = d.NextActivityID
+	db.snapshots = d.Snapshots
+	if db.snapshots == nil {
+		db.snapshots = make(map[string][]*models.RepositorySnapshot)
+	}
+	db.milestones = d.Milestones
+	if db.milestones == nil {
+		db.milestones = make(map[string][]*models.Milestone)
+	}
+	db.releases = d.Releases
+	if db.releases == nil {
+		db.releases = make(map[string][]*models.Release)
+	}
+	db.alerts = d.Alerts
+	if db.alerts == nil {
+		db.alerts = make(map[string][]*models.SecurityAlert)
+	}
+	db.commits = d.Commits
+	if db.commits == nil {
+		db.commits = make(map[string][]*models.Commit)
+	}
+	db.duplicates = d.Duplicates
+	if db.duplicates == nil {
+		db.duplicates = make(map[string][]*models.DuplicateCandidate)
+	}
+	db.discussions = d.Discussions
+	if db.discussions == nil {
+		db.discussions = make(map[string][]*models.Discussion)
+	}
+	db.projects = d.Projects
+	if db.projects == nil {
+		db.projects = make(map[string][]*models.Project)
+	}
+	db.projectItems = d.ProjectItems
+	if db.projectItems == nil {
+		db.projectItems = make(map[string][]*models.ProjectItem)
+	}
+	db.diffs = d.Diffs
+	if db.diffs == nil {
+		db.diffs = make(map[string][]*models.PullRequestDiff)
+	}
+	db.workspaces = d.Workspaces
+	if db.workspaces == nil {
+		db.workspaces = make(map[string]*models.Workspace)
+	}
+	db.jobs = d.Jobs
+	db.nextJobID = d.NextJobID
+	db.audit = d.Audit
+	db.nextAuditID = d.NextAuditID
+	db.loadSecrets(d.Secrets)
+
+	db.rebuildIndexes()
+
+	// Record the upgrade, keeping the file as it was in case the new release is rolled back
+	if migratedFrom >= 0 && !db.readOnly {
+		if err := db.backup(file, migratedFrom); err != nil {
+			return fmt.Errorf("failed to back up the data file before migrating it: %w", err)
+		}
+		return db.sync()
+	}
 	return nil
 }
 
 // sync writes data to file
 func (db *DB) sync() error {
+	// Every write ends here, making the last snapshot outdated
+	db.generation.Add(1)
+	db.snapshot.Store(nil)
+
+	if db.path == "" {
+		return nil
+	}
+	if db.readOnly {
+		return storage.ErrReadOnly
+	}
+
 	d := data{
+		SchemaVersion: schemaVersion,
+
 		Repositories: db.repositories,
 		PullRequests: db.pullRequests,
 		Issues:       db.issues,
@@ -112,14 +414,79 @@ func (db *DB) sync() error {
 		RepoLabels:   db.repoLabels,
 		PRLabels:     db.prLabels,
 		IssueLabels:  db.issueLabels,
+
+		Webhooks:          db.webhooks,
+		WebhookDeliveries: db.webhookDeliveries,
+		NextWebhookID:     db.nextWebhookID,
+		NextDeliveryID:    db.nextDeliveryID,
+
+		Subscriptions:      db.subscriptions,
+		NextSubscriptionID: db.nextSubscriptionID,
+
+		TriageRules:      db.triageRules,
+		TriageExecutions: db.triageExecutions,
+		NextTriageRuleID: db.nextTriageRuleID,
+
+		Activity:       db.activity,
+		NextActivityID: db.nextActivityID,
+
+		Snapshots: db.snapshots,
+
+		Milestones: db.milestones,
+		Releases:   db.releases,
+
+		Alerts: db.alerts,
+
+		Commits: db.commits,
+
+		Duplicates: db.duplicates,
+
+		Discussions: db.discussions,
+
+		Projects:     db.projects,
+		ProjectItems: db.projectItems,
+
+		Diffs: db.diffs,
+
+		Workspaces: db.workspaces,
+
+		Jobs:      db.jobs,
+		NextJobID: db.nextJobID,
+
+		Audit:       db.audit,
+		NextAuditID: db.nextAuditID,
+
+		Secrets: db.saveSecrets(),
 	}
 
-	file, err := json.MarshalIndent(d, "", "  ")
+	var file []byte
+	var err error
+	if db.indent {
+		file, err = json.MarshalIndent(d, "", "  ")
+	} else {
+		file, err = json.Marshal(d)
+	}
 	if err != nil {
 		return err
 	}
 
-	return os.WriteFile(db.path, file, 0644)
+	// Replace the file in one step, so read-only readers never see it half written
+	tmp := db.path + ".tmp"
+	if err := os.WriteFile(tmp, file, 0644); err != nil {
+		return fmt.Errorf("%w: %w", storage.ErrUnavailable, err)
+	}
+	if err := os.Rename(tmp, db.path); err != nil {
+		return fmt.Errorf("%w: %w", storage.ErrUnavailable, err)
+	}
+	return nil
+}
+
+// unlock releases the lock on the file, if held
+func (db *DB) unlock() {
+	if db.lock != nil {
+		db.lock.Close()
+		db.lock = nil
+	}
 }
 
 // Repository operations
@@ -129,7 +496,11 @@ func (db *DB) AddRepository(ctx context.Context, repo *models.Repository) error
 	db.Lock()
 	defer db.Unlock()
 
-	db.repositories[repo.FullName] = repo
+	if _, ok := db.repositories[repo.FullName]; !ok && db.limits.MaxRepositories > 0 && len(db.repositories) >= db.limits.MaxRepositories {
+		return db.ErrRepositoryLimitReached(db.limits.MaxRepositories)
+	}
+
+	db.repositories[repo.FullName] = cloneRepository(repo)
 	return db.sync()
 }
 
@@ -143,22 +514,68 @@ func (db *DB) GetRepository(ctx context.Context, owner, name string) (*models.Re
 	if !ok {
 		return nil, db.ErrRepositoryNotFound(fullName)
 	}
-	return repo, nil
+	return cloneRepository(repo), nil
 }
 
-// UpdateRepository updates a repository in the database
+// UpdateRepository updates a repository in the database. The update is rejected
+// with db.ErrVersionConflict when the stored version differs from repo.Version,
+// meaning another writer changed the repository after it was read. On success
+// repo.Version is set to the new stored version.
 func (db *DB) UpdateRepository(ctx context.Context, repo *models.Repository) error {
 	db.Lock()
 	defer db.Unlock()
 
-	if _, ok := db.repositories[repo.FullName]; !ok {
+	return db.updateRepository(repo)
+}
+
+// UpsertRepository adds a repository or replaces the stored one, reporting whether it was
+// added. Replacing it is rejected with db.ErrVersionConflict like UpdateRepository.
+func (db *DB) UpsertRepository(ctx context.Context, repo *models.Repository) (bool, error) {
+	db.Lock()
+	defer db.Unlock()
+
+	if _, ok := db.repositories[repo.FullName]; ok {
+		return false, db.updateRepository(repo)
+	}
+	if db.limits.MaxRepositories > 0 && len(db.repositories) >= db.limits.MaxRepositories {
+		return false, db.ErrRepositoryLimitReached(db.limits.MaxRepositories)
+	}
+	db.repositories[repo.FullName] = cloneRepository(repo)
+	return true, db.sync()
+}
+
+// updateRepository replaces a stored repository of the same version; the caller must hold the write lock
+func (db *DB) updateRepository(repo *models.Repository) error {
+	stored, ok := db.repositories[repo.FullName]
+	if !ok {
 		return db.ErrRepositoryNotFound(repo.FullName)
 	}
+	if stored.Version != repo.Version {
+		return fmt.Errorf("%w: %s is at version %d, update is based on version %d",
+			storage.ErrVersionConflict, repo.FullName, stored.Version, repo.Version)
+	}
 
-	db.repositories[repo.FullName] = repo
+	updated := cloneRepository(repo)
+	updated.Version++
+	db.repositories[repo.FullName] = updated
+	repo.Version = updated.Version
 	return db.sync()
 }
 
+// cloneRepository copies a repository so callers can't modify the stored one
+func cloneRepository(repo *models.Repository) *models.Repository {
+	clone := *repo
+	clone.Tags = append([]string(nil), repo.Tags...)
+	clone.CodeOwners = append([]models.CodeOwnersRule(nil), repo.CodeOwners...)
+	clone.Labels = append([]models.Label(nil), repo.Labels...)
+	clone.IssueTemplates = append([]models.IssueTemplate(nil), repo.IssueTemplates...)
+	if repo.Settings != nil {
+		settings := *repo.Settings
+		clone.Settings = &settings
+	}
+	return &clone
+}
+
 // DeleteRepository deletes a repository from the database
 func (db *DB) DeleteRepository(ctx context.Context, owner, name string) error {
 	db.Lock()
@@ -178,20 +595,29 @@ func (db *DB) DeleteRepository(ctx context.Context, owner, name string) error {
 	delete(db.repoLabels, fullName)
 	delete(db.prLabels, fullName)
 	delete(db.issueLabels, fullName)
+	delete(db.snapshots, fullName)
+	delete(db.milestones, fullName)
+	delete(db.releases, fullName)
+	delete(db.alerts, fullName)
+	delete(db.commits, fullName)
+	delete(db.duplicates, fullName)
+	delete(db.discussions, fullName)
+	delete(db.projects, fullName)
+	delete(db.projectItems, fullName)
+	delete(db.diffs, fullName)
+	db.removeWorkspaceRepository(fullName)
+	db.prIndex.removeRepository(fullName)
+	db.issueIndex.removeRepository(fullName)
 
 	return db.sync()
 }
 
-// ListRepositories lists repositories from the database
+// ListRepositories lists repositories from the database, ordered by full name
 func (db *DB) ListRepositories(ctx context.Context, page, perPage int) ([]*models.Repository, int, error) {
 	db.RLock()
 	defer db.RUnlock()
 
-	repos := make([]*models.Repository, 0, len(db.repositories))
-	for _, repo := range db.repositories {
-		repos = append(repos, repo)
-	}
-
+	repos := db.sortedRepositories()
 	total := len(repos)
 	offset := (page - 1) * perPage
 	if offset >= total {
@@ -206,6 +632,24 @@ func (db *DB) ListRepositories(ctx context.Context, page, perPage int) ([]*model
 	return repos[offset:end], total, nil
 }
 
+// sortedRepositories returns copies of the repositories ordered by full name
+func (db *DB) sortedRepositories() []*models.Repository {
+	repos := make([]*models.Repository, 0, len(db.repositories))
+	for _, repo := range db.repositories {
+		repos = append(repos, cloneRepository(repo))
+	}
+	sort.Slice(repos, func(i, j int) bool { return repos[i].FullName < repos[j].FullName })
+	return repos
+}
+
+// ListAllRepositories lists every repository in the database, ordered by full name
+func (db *DB) ListAllRepositories(ctx context.Context) ([]*models.Repository, error) {
+	db.RLock()
+	defer db.RUnlock()
+
+	return db.sortedRepositories(), nil
+}
+
 // Pull Request operations
 
 // AddPullRequest adds a pull request to the database
@@ -213,18 +657,27 @@ func (db *DB) AddPullRequest(ctx context.Context, pr *models.PullRequest) error
 	db.Lock()
 	defer db.Unlock()
 
+	db.putPullRequest(pr)
+	return db.sync()
+}
+
+// putPullRequest stores a pull request; the caller must hold the write lock
+func (db *DB) putPullRequest(pr *models.PullRequest) {
 	if _, ok := db.pullRequests[pr.RepositoryFullName]; !ok {
 		db.pullRequests[pr.RepositoryFullName] = make(map[int]*models.PullRequest)
 	}
 
+	_, exists := db.pullRequests[pr.RepositoryFullName][pr.Number]
 	db.pullRequests[pr.RepositoryFullName][pr.Number] = pr
 
 	if _, ok := db.repoPRs[pr.RepositoryFullName]; !ok {
 		db.repoPRs[pr.RepositoryFullName] = make([]int, 0)
 	}
-	db.repoPRs[pr.RepositoryFullName] = append(db.repoPRs[pr.RepositoryFullName], pr.Number)
-
-	return db.sync()
+	if !exists {
+		db.repoPRs[pr.RepositoryFullName] = insertNumber(db.repoPRs[pr.RepositoryFullName], pr.Number)
+	}
+	db.prIndex.put(itemKey{repo: pr.RepositoryFullName, number: pr.Number}, pr.State, pr.UserLogin, pr.UpdatedAt)
+	db.enforceLimits(pr.RepositoryFullName, true)
 }
 
 // GetPullRequest gets a pull request from the database
@@ -232,6 +685,11 @@ func (db *DB) GetPullRequest(ctx context.Context, repoFullName string, number in
 	db.RLock()
 	defer db.RUnlock()
 
+	return db.getPullRequest(repoFullName, number)
+}
+
+// getPullRequest gets a pull request; the caller must hold the lock
+func (db *DB) getPullRequest(repoFullName string, number int) (*models.PullRequest, error) {
 	repoPRs, ok := db.pullRequests[repoFullName]
 	if !ok {
 		return nil, db.ErrPullRequestNotFound(repoFullName, number)
@@ -245,7 +703,7 @@ func (db *DB) GetPullRequest(ctx context.Context, repoFullName string, number in
 	return pr, nil
 }
 
-// ListPullRequests lists pull requests from the database
+// ListPullRequests lists pull requests from the database, ordered by number
 func (db *DB) ListPullRequests(ctx context.Context, repoFullName string, page, perPage int) ([]*models.PullRequest, int, error) {
 	db.RLock()
 	defer db.RUnlock()
@@ -276,10 +734,70 @@ func (db *DB) ListPullRequests(ctx context.Context, repoFullName string, page, p
 	return prs, total, nil
 }
 
-// UpdatePullRequest updates a pull request in the database
+// ListAllPullRequests lists every pull request of a repository, ordered by number
+func (db *DB) ListAllPullRequests(ctx context.Context, repoFullName string) ([]*models.PullRequest, error) {
+	db.RLock()
+	defer db.RUnlock()
+
+	numbers := db.repoPRs[repoFullName]
+	prs := make([]*models.PullRequest, 0, len(numbers))
+	for _, number := range numbers {
+		if pr, ok := db.pullRequests[repoFullName][number]; ok {
+			prs = append(prs, pr)
+		}
+	}
+
+	return prs, nil
+}
+
+// UpdatePullRequest updates a pull request in the database. The update is rejected
+// with db.ErrStaleWrite when the stored pull request was updated on GitHub after pr.UpdatedAt,
+// so a slow sync can't overwrite fresher data.
 func (db *DB) UpdatePullRequest(ctx context.Context, pr *models.PullRequest) error {
-	// Just reuse the add method since it will overwrite
-	return db.AddPullRequest(ctx, pr)
+	db.Lock()
+	defer db.Unlock()
+
+	if _, err := db.checkPullRequest(pr); err != nil {
+		return err
+	}
+
+	db.putPullRequest(pr)
+	return db.sync()
+}
+
+// UpsertPullRequest adds a pull request or replaces the stored one, reporting whether it was
+// added. Replacing it is rejected with db.ErrStaleWrite like UpdatePullRequest.
+func (db *DB) UpsertPullRequest(ctx context.Context, pr *models.PullRequest) (bool, error) {
+	db.Lock()
+	defer db.Unlock()
+
+	created, err := db.upsertPullRequest(pr)
+	if err != nil {
+		return false, err
+	}
+	return created, db.sync()
+}
+
+// upsertPullRequest adds or replaces a pull request, reporting whether it was added; the caller
+// must hold the write lock
+func (db *DB) upsertPullRequest(pr *models.PullRequest) (bool, error) {
+	exists, err := db.checkPullRequest(pr)
+	if err != nil {
+		return false, err
+	}
+	db.putPullRequest(pr)
+	return !exists, nil
+}
+
+// checkPullRequest reports whether a pull request is stored, failing with db.ErrStaleWrite when
+// the stored one is newer than pr; the caller must hold the lock
+func (db *DB) checkPullRequest(pr *models.PullRequest) (bool, error) {
+	stored, ok := db.pullRequests[pr.RepositoryFullName][pr.Number]
+	if ok && stored.UpdatedAt.After(pr.UpdatedAt) {
+		return true, fmt.Errorf("%w: pull request %d in %s was updated at %s",
+			storage.ErrStaleWrite, pr.Number, pr.RepositoryFullName, stored.UpdatedAt.Format(time.RFC3339))
+	}
+	return ok, nil
 }
 
 // DeletePullRequest deletes a pull request from the database
@@ -297,6 +815,7 @@ func (db *DB) DeletePullRequest(ctx context.Context, repoFullName string, number
 	}
 
 	delete(repoPRs, number)
+	db.prIndex.remove(itemKey{repo: repoFullName, number: number})
 
 	// Remove from the list of PRs
 	for i, n := range db.repoPRs[repoFullName] {
@@ -309,6 +828,22 @@ func (db *DB) DeletePullRequest(ctx context.Context, repoFullName string, number
 	return db.sync()
 }
 
+// FindPullRequests returns the pull requests matching a query using the secondary indexes
+func (db *DB) FindPullRequests(ctx context.Context, query *models.ItemQuery) ([]*models.PullRequest, error) {
+	db.RLock()
+	defer db.RUnlock()
+
+	keys := db.prIndex.find(query)
+	prs := make([]*models.PullRequest, 0, len(keys))
+	for _, key := range keys {
+		if pr, ok := db.pullRequests[key.repo][key.number]; ok {
+			prs = append(prs, pr)
+		}
+	}
+
+	return prs, nil
+}
+
 // Issue operations
 
 // AddIssue adds an issue to the database
@@ -316,18 +851,27 @@ func (db *DB) AddIssue(ctx context.Context, issue *models.Issue) error {
 	db.Lock()
 	defer db.Unlock()
 
+	db.putIssue(issue)
+	return db.sync()
+}
+
+// putIssue stores an issue; the caller must hold the write lock
+func (db *DB) putIssue(issue *models.Issue) {
 	if _, ok := db.issues[issue.RepositoryFullName]; !ok {
 		db.issues[issue.RepositoryFullName] = make(map[int]*models.Issue)
 	}
 
+	_, exists := db.issues[issue.RepositoryFullName][issue.Number]
 	db.issues[issue.RepositoryFullName][issue.Number] = issue
 
 	if _, ok := db.repoIssues[issue.RepositoryFullName]; !ok {
 		db.repoIssues[issue.RepositoryFullName] = make([]int, 0)
 	}
-	db.repoIssues[issue.RepositoryFullName] = append(db.repoIssues[issue.RepositoryFullName], issue.Number)
-
-	return db.sync()
+	if !exists {
+		db.repoIssues[issue.RepositoryFullName] = insertNumber(db.repoIssues[issue.RepositoryFullName], issue.Number)
+	}
+	db.issueIndex.put(itemKey{repo: issue.RepositoryFullName, number: issue.Number}, issue.State, issue.UserLogin, issue.UpdatedAt)
+	db.enforceLimits(issue.RepositoryFullName, false)
 }
 
 // GetIssue gets an issue from the database
@@ -335,6 +879,11 @@ func (db *DB) GetIssue(ctx context.Context, repoFullName string, number int) (*m
 	db.RLock()
 	defer db.RUnlock()
 
+	return db.getIssue(repoFullName, number)
+}
+
+// getIssue gets an issue; the caller must hold the lock
+func (db *DB) getIssue(repoFullName string, number int) (*models.Issue, error) {
 	repoIssues, ok := db.issues[repoFullName]
 	if !ok {
 		return nil, db.ErrIssueNotFound(repoFullName, number)
@@ -348,7 +897,7 @@ func (db *DB) GetIssue(ctx context.Context, repoFullName string, number int) (*m
 	return issue, nil
 }
 
-// ListIssues lists issues from the database
+// ListIssues lists issues from the database, ordered by number
 func (db *DB) ListIssues(ctx context.Context, repoFullName string, page, perPage int) ([]*models.Issue, int, error) {
 	db.RLock()
 	defer db.RUnlock()
@@ -379,10 +928,70 @@ func (db *DB) ListIssues(ctx context.Context, repoFullName string, page, perPage
 	return issues, total, nil
 }
 
-// UpdateIssue updates an issue in the database
+// ListAllIssues lists every issue of a repository, ordered by number
+func (db *DB) ListAllIssues(ctx context.Context, repoFullName string) ([]*models.Issue, error) {
+	db.RLock()
+	defer db.RUnlock()
+
+	numbers := db.repoIssues[repoFullName]
+	issues := make([]*models.Issue, 0, len(numbers))
+	for _, number := range numbers {
+		if issue, ok := db.issues[repoFullName][number]; ok {
+			issues = append(issues, issue)
+		}
+	}
+
+	return issues, nil
+}
+
+// UpdateIssue updates an issue in the database. The update is rejected
+// with db.ErrStaleWrite when the stored issue was updated on GitHub after issue.UpdatedAt,
+// so a slow sync can't overwrite fresher data.
 func (db *DB) UpdateIssue(ctx context.Context, issue *models.Issue) error {
-	// Just reuse the add method since it will overwrite
-	return db.AddIssue(ctx, issue)
+	db.Lock()
+	defer db.Unlock()
+
+	if _, err := db.checkIssue(issue); err != nil {
+		return err
+	}
+
+	db.putIssue(issue)
+	return db.sync()
+}
+
+// UpsertIssue adds an issue or replaces the stored one, reporting whether it was added.
+// Replacing it is rejected with db.ErrStaleWrite like UpdateIssue.
+func (db *DB) UpsertIssue(ctx context.Context, issue *models.Issue) (bool, error) {
+	db.Lock()
+	defer db.Unlock()
+
+	created, err := db.upsertIssue(issue)
+	if err != nil {
+		return false, err
+	}
+	return created, db.sync()
+}
+
+// upsertIssue adds or replaces an issue, reporting whether it was added; the caller must hold
+// the write lock
+func (db *DB) upsertIssue(issue *models.Issue) (bool, error) {
+	exists, err := db.checkIssue(issue)
+	if err != nil {
+		return false, err
+	}
+	db.putIssue(issue)
+	return !exists, nil
+}
+
+// checkIssue reports whether an issue is stored, failing with db.ErrStaleWrite when the
+// stored one is newer than issue; the caller must hold the lock
+func (db *DB) checkIssue(issue *models.Issue) (bool, error) {
+	stored, ok := db.issues[issue.RepositoryFullName][issue.Number]
+	if ok && stored.UpdatedAt.After(issue.UpdatedAt) {
+		return true, fmt.Errorf("%w: issue %d in %s was updated at %s",
+			storage.ErrStaleWrite, issue.Number, issue.RepositoryFullName, stored.UpdatedAt.Format(time.RFC3339))
+	}
+	return ok, nil
 }
 
 // DeleteIssue deletes an issue from the database
@@ -390,6 +999,14 @@ func (db *DB) DeleteIssue(ctx context.Context, repoFullName string, number int)
 	db.Lock()
 	defer db.Unlock()
 
+	if err := db.deleteIssue(repoFullName, number); err != nil {
+		return err
+	}
+	return db.sync()
+}
+
+// deleteIssue deletes an issue; the caller must hold the write lock
+func (db *DB) deleteIssue(repoFullName string, number int) error {
 	repoIssues, ok := db.issues[repoFullName]
 	if !ok {
 		return db.ErrIssueNotFound(repoFullName, number)
@@ -400,6 +1017,7 @@ func (db *DB) DeleteIssue(ctx context.Context, repoFullName string, number int)
 	}
 
 	delete(repoIssues, number)
+	db.issueIndex.remove(itemKey{repo: repoFullName, number: number})
 
 	// Remove from the list of issues
 	for i, n := range db.repoIssues[repoFullName] {
@@ -409,7 +1027,23 @@ func (db *DB) DeleteIssue(ctx context.Context, repoFullName string, number int)
 		}
 	}
 
-	return db.sync()
+	return nil
+}
+
+// FindIssues returns the issues matching a query using the secondary indexes
+func (db *DB) FindIssues(ctx context.Context, query *models.ItemQuery) ([]*models.Issue, error) {
+	db.RLock()
+	defer db.RUnlock()
+
+	keys := db.issueIndex.find(query)
+	issues := make([]*models.Issue, 0, len(keys))
+	for _, key := range keys {
+		if issue, ok := db.issues[key.repo][key.number]; ok {
+			issues = append(issues, issue)
+		}
+	}
+
+	return issues, nil
 }
 
 // Label operations
@@ -419,6 +1053,12 @@ func (db *DB) AddLabel(ctx context.Context, label *models.Label) error {
 	db.Lock()
 	defer db.Unlock()
 
+	db.addLabel(label)
+	return db.sync()
+}
+
+// addLabel adds a label; the caller must hold the write lock
+func (db *DB) addLabel(label *models.Label) {
 	// Since the Label struct doesn't have a RepositoryFullName field,
 	// we'll use the label's name as the repository name for now
 	repoName := "global"
@@ -433,8 +1073,6 @@ func (db *DB) AddLabel(ctx context.Context, label *models.Label) error {
 		db.repoLabels[repoName] = make(map[string]*models.Label)
 	}
 	db.repoLabels[repoName][label.Name] = label
-
-	return db.sync()
 }
 
 // GetLabel gets a label from the database
@@ -442,6 +1080,11 @@ func (db *DB) GetLabel(ctx context.Context, name string) (*models.Label, error)
 	db.RLock()
 	defer db.RUnlock()
 
+	return db.getLabel(name)
+}
+
+// getLabel gets a label; the caller must hold the lock
+func (db *DB) getLabel(name string) (*models.Label, error) {
 	// Since the Label struct doesn't have a RepositoryFullName field,
 	// we'll use a global repository name for now
 	repoName := "global"
@@ -477,6 +1120,7 @@ func (db *DB) ListLabels(ctx context.Context, page, perPage int) ([]*models.Labe
 	for _, label := range repoLabels {
 		labels = append(labels, label)
 	}
+	sort.Slice(labels, func(i, j int) bool { return labels[i].Name < labels[j].Name })
 
 	total := len(labels)
 	offset := (page - 1) * perPage
@@ -529,6 +1173,15 @@ func (db *DB) AddPullRequestLabel(ctx context.Context, repoFullName string, prNu
 	db.Lock()
 	defer db.Unlock()
 
+	if !db.addPullRequestLabel(repoFullName, prNumber, labelName) {
+		return nil
+	}
+	return db.sync()
+}
+
+// addPullRequestLabel adds a label to a pull request, reporting whether it didn't have it; the
+// caller must hold the write lock
+func (db *DB) addPullRequestLabel(repoFullName string, prNumber int, labelName string) bool {
 	if _, ok := db.prLabels[repoFullName]; !ok {
 		db.prLabels[repoFullName] = make(map[int][]string)
 	}
@@ -540,12 +1193,13 @@ func (db *DB) AddPullRequestLabel(ctx context.Context, repoFullName string, prNu
 	// Check if the label already exists
 	for _, name := range db.prLabels[repoFullName][prNumber] {
 		if name == labelName {
-			return nil
+			return false
 		}
 	}
 
 	db.prLabels[repoFullName][prNumber] = append(db.prLabels[repoFullName][prNumber], labelName)
-	return db.sync()
+	db.prIndex.setLabels(itemKey{repo: repoFullName, number: prNumber}, db.prLabels[repoFullName][prNumber])
+	return true
 }
 
 // ListPullRequestLabels lists labels for a pull request
@@ -553,6 +1207,11 @@ func (db *DB) ListPullRequestLabels(ctx context.Context, repoFullName string, pr
 	db.RLock()
 	defer db.RUnlock()
 
+	return db.listPullRequestLabels(repoFullName, prNumber)
+}
+
+// listPullRequestLabels lists the labels of a pull request; the caller must hold the lock
+func (db *DB) listPullRequestLabels(repoFullName string, prNumber int) ([]*models.Label, error) {
 	if _, ok := db.prLabels[repoFullName]; !ok {
 		return []*models.Label{}, nil
 	}
@@ -596,6 +1255,7 @@ func (db *DB) RemovePullRequestLabel(ctx context.Context, repoFullName string, p
 			break
 		}
 	}
+	db.prIndex.setLabels(itemKey{repo: repoFullName, number: prNumber}, db.prLabels[repoFullName][prNumber])
 
 	return db.sync()
 }
@@ -607,6 +1267,15 @@ func (db *DB) AddIssueLabel(ctx context.Context, repoFullName string, issueNumbe
 	db.Lock()
 	defer db.Unlock()
 
+	if !db.addIssueLabel(repoFullName, issueNumber, labelName) {
+		return nil
+	}
+	return db.sync()
+}
+
+// addIssueLabel adds a label to an issue, reporting whether it didn't have it; the caller must
+// hold the write lock
+func (db *DB) addIssueLabel(repoFullName string, issueNumber int, labelName string) bool {
 	if _, ok := db.issueLabels[repoFullName]; !ok {
 		db.issueLabels[repoFullName] = make(map[int][]string)
 	}
@@ -618,12 +1287,13 @@ func (db *DB) AddIssueLabel(ctx context.Context, repoFullName string, issueNumbe
 	// Check if the label already exists
 	for _, name := range db.issueLabels[repoFullName][issueNumber] {
 		if name == labelName {
-			return nil
+			return false
 		}
 	}
 
 	db.issueLabels[repoFullName][issueNumber] = append(db.issueLabels[repoFullName][issueNumber], labelName)
-	return db.sync()
+	db.issueIndex.setLabels(itemKey{repo: repoFullName, number: issueNumber}, db.issueLabels[repoFullName][issueNumber])
+	return true
 }
 
 // ListIssueLabels lists labels for an issue
@@ -631,6 +1301,11 @@ func (db *DB) ListIssueLabels(ctx context.Context, repoFullName string, issueNum
 	db.RLock()
 	defer db.RUnlock()
 
+	return db.listIssueLabels(repoFullName, issueNumber)
+}
+
+// listIssueLabels lists the labels of an issue; the caller must hold the lock
+func (db *DB) listIssueLabels(repoFullName string, issueNumber int) ([]*models.Label, error) {
 	if _, ok := db.issueLabels[repoFullName]; !ok {
 		return []*models.Label{}, nil
 	}
@@ -674,21 +1349,276 @@ func (db *DB) RemoveIssueLabel(ctx context.Context, repoFullName string, issueNu
 			break
 		}
 	}
+	db.issueIndex.setLabels(itemKey{repo: repoFullName, number: issueNumber}, db.issueLabels[repoFullName][issueNumber])
+
+	return db.sync()
+}
+
+// Webhook operations
+
+// AddWebhook adds a webhook to the database and assigns its ID
+func (db *DB) AddWebhook(ctx context.Context, hook *models.Webhook) error {
+	db.Lock()
+	defer db.Unlock()
+
+	db.nextWebhookID++
+	hook.ID = db.nextWebhookID
+	db.webhooks[hook.ID] = hook
+
+	return db.sync()
+}
+
+// GetWebhook gets a webhook from the database
+func (db *DB) GetWebhook(ctx context.Context, id int64) (*models.Webhook, error) {
+	db.RLock()
+	defer db.RUnlock()
+
+	hook, ok := db.webhooks[id]
+	if !ok {
+		return nil, db.ErrWebhookNotFound(id)
+	}
+	return hook, nil
+}
+
+// ListWebhooks lists all webhooks ordered by ID
+func (db *DB) ListWebhooks(ctx context.Context) ([]*models.Webhook, error) {
+	db.RLock()
+	defer db.RUnlock()
+
+	hooks := make([]*models.Webhook, 0, len(db.webhooks))
+	for _, hook := range db.webhooks {
+		hooks = append(hooks, hook)
+	}
+	sort.Slice(hooks, func(i, j int) bool {
+		return hooks[i].ID < hooks[j].ID
+	})
+
+	return hooks, nil
+}
+
+// DeleteWebhook deletes a webhook and its delivery logs from the database
+func (db *DB) DeleteWebhook(ctx context.Context, id int64) error {
+	db.Lock()
+	defer db.Unlock()
+
+	if _, ok := db.webhooks[id]; !ok {
+		return db.ErrWebhookNotFound(id)
+	}
+
+	delete(db.webhooks, id)
+	delete(db.webhookDeliveries, id)
 
 	return db.sync()
 }
 
+// AddWebhookDeliveries records webhook deliveries with one write of the file, keeping only the
+// most recent logs of each webhook. Deliveries of webhooks deleted since are dropped.
+func (db *DB) AddWebhookDeliveries(ctx context.Context, deliveries []*models.WebhookDelivery) error {
+	db.Lock()
+	defer db.Unlock()
+
+	for _, delivery := range deliveries {
+		if _, ok := db.webhooks[delivery.WebhookID]; !ok {
+			continue
+		}
+
+		db.nextDeliveryID++
+		delivery.ID = db.nextDeliveryID
+
+		logs := append(db.webhookDeliveries[delivery.WebhookID], delivery)
+		if len(logs) > maxWebhookDeliveries {
+			logs = logs[len(logs)-maxWebhookDeliveries:]
+		}
+		db.webhookDeliveries[delivery.WebhookID] = logs
+	}
+
+	return db.sync()
+}
+
+// ListWebhookDeliveries lists delivery logs of a webhook, newest first
+func (db *DB) ListWebhookDeliveries(ctx context.Context, hookID int64, page, perPage int) ([]*models.WebhookDelivery, int, error) {
+	db.RLock()
+	defer db.RUnlock()
+
+	if _, ok := db.webhooks[hookID]; !ok {
+		return nil, 0, db.ErrWebhookNotFound(hookID)
+	}
+
+	stored := db.webhookDeliveries[hookID]
+	deliveries := make([]*models.WebhookDelivery, 0, len(stored))
+	for i := len(stored) - 1; i >= 0; i-- {
+		deliveries = append(deliveries, stored[i])
+	}
+
+	total := len(deliveries)
+	offset := (page - 1) * perPage
+	if offset >= total {
+		return []*models.WebhookDelivery{}, total, nil
+	}
+
+	end := offset + perPage
+	if end > total {
+		end = total
+	}
+
+	return deliveries[offset:end], total, nil
+}
+
+// Activity operations
+
+// AppendActivity appends an event to the activity log and assigns its sequence ID
+func (db *DB) AppendActivity(ctx context.Context, event *models.ActivityEvent) error {
+	db.Lock()
+	defer db.Unlock()
+
+	db.nextActivityID++
+	event.ID = db.nextActivityID
+	db.activity = append(db.activity, event)
+
+	return db.sync()
+}
+
+// ListActivity lists activity events matching the filter, newest first
+func (db *DB) ListActivity(ctx context.Context, filter *models.ActivityFilter) ([]*models.ActivityEvent, int, error) {
+	db.RLock()
+	defer db.RUnlock()
+
+	events := make([]*models.ActivityEvent, 0)
+	for i := len(db.activity) - 1; i >= 0; i-- {
+		event := db.activity[i]
+		if filter.Repo != "" && !strings.EqualFold(event.Repository, filter.Repo) {
+			continue
+		}
+		if filter.Type != "" && event.Type != filter.Type {
+			continue
+		}
+		if !filter.Since.IsZero() && event.CreatedAt.Before(filter.Since) {
+			continue
+		}
+		if !filter.Until.IsZero() && !event.CreatedAt.Before(filter.Until) {
+			continue
+		}
+		events = append(events, event)
+	}
+
+	total := len(events)
+	offset := (filter.Page - 1) * filter.PerPage
+	if offset >= total {
+		return []*models.ActivityEvent{}, total, nil
+	}
+
+	end := offset + filter.PerPage
+	if end > total {
+		end = total
+	}
+
+	return events[offset:end], total, nil
+}
+
+// ListActivitySince lists the activity events recorded after afterID, oldest first
+func (db *DB) ListActivitySince(ctx context.Context, afterID int64, limit int) ([]*models.ActivityEvent, error) {
+	db.RLock()
+	defer db.RUnlock()
+
+	// Events are appended with increasing IDs
+	start := sort.Search(len(db.activity), func(i int) bool { return db.activity[i].ID > afterID })
+	end := len(db.activity)
+	if limit > 0 && start+limit < end {
+		end = start + limit
+	}
+	events := make([]*models.ActivityEvent, end-start)
+	copy(events, db.activity[start:end])
+	return events, nil
+}
+
+// Snapshot operations
+
+// AddRepositorySnapshot appends a metrics snapshot for a repository
+func (db *DB) AddRepositorySnapshot(ctx context.Context, snapshot *models.RepositorySnapshot) error {
+	db.Lock()
+	defer db.Unlock()
+
+	if _, ok := db.repositories[snapshot.RepositoryFullName]; !ok {
+		return db.ErrRepositoryNotFound(snapshot.RepositoryFullName)
+	}
+
+	db.snapshots[snapshot.RepositoryFullName] = append(db.snapshots[snapshot.RepositoryFullName], snapshot)
+	return db.sync()
+}
+
+// ListRepositorySnapshots lists the snapshots of a repository taken at or after since, oldest first
+func (db *DB) ListRepositorySnapshots(ctx context.Context, repoFullName string, since time.Time) ([]*models.RepositorySnapshot, error) {
+	db.RLock()
+	defer db.RUnlock()
+
+	snapshots := make([]*models.RepositorySnapshot, 0)
+	for _, snapshot := range db.snapshots[repoFullName] {
+		if snapshot.CreatedAt.Before(since) {
+			continue
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	return snapshots, nil
+}
+
+// Stats returns item counts, the estimated memory use and eviction counters
+func (db *DB) Stats(ctx context.Context) (*models.StorageStats, error) {
+	db.RLock()
+	defer db.RUnlock()
+
+	stats := &models.StorageStats{
+		Repositories:        len(db.repositories),
+		EstimatedBytes:      db.estimateMemory(),
+		EvictedPullRequests: db.evictedPRs,
+		EvictedIssues:       db.evictedIssues,
+		FileBytes:           db.fileSize(),
+		SchemaVersion:       schemaVersion,
+		PerRepository:       db.repositoryStats(),
+	}
+	for _, prs := range db.pullRequests {
+		stats.PullRequests += len(prs)
+		for _, pr := range prs {
+			if pr.Tombstoned {
+				stats.TombstonedPullRequests++
+			}
+		}
+	}
+	for _, issues := range db.issues {
+		stats.Issues += len(issues)
+		for _, issue := range issues {
+			if issue.Tombstoned {
+				stats.TombstonedIssues++
+			}
+		}
+	}
+
+	return stats, nil
+}
+
 // Maintenance operations
 
 // Close closes the database
 func (db *DB) Close() error {
+	db.Lock()
+	defer db.Unlock()
+
+	if db.readOnly || db.closed {
+		return nil
+	}
+	db.closed = true
+
 	// Sync any pending changes
+	defer db.unlock()
 	return db.sync()
 }
 
 // Ping checks if the database is available
 func (db *DB) Ping(ctx context.Context) error {
 	// The file DB is always available if we can access the file
+	if db.path == "" {
+		return nil
+	}
 	_, err := os.Stat(db.path)
 	return err
 }
@@ -704,17 +1634,37 @@ func (db *DB) Sync() error {
 // Error helpers
 
 func (db *DB) ErrRepositoryNotFound(fullName string) error {
-	return fmt.Errorf("repository %s not found", fullName)
+	return fmt.Errorf("repository %s %w", fullName, storage.ErrNotFound)
 }
 
 func (db *DB) ErrPullRequestNotFound(fullName string, number int) error {
-	return fmt.Errorf("pull request %d not found in repository %s", number, fullName)
+	return fmt.Errorf("pull request %d %w in repository %s", number, storage.ErrNotFound, fullName)
 }
 
 func (db *DB) ErrIssueNotFound(fullName string, number int) error {
-	return fmt.Errorf("issue %d not found in repository %s", number, fullName)
+	return fmt.Errorf("issue %d %w in repository %s", number, storage.ErrNotFound, fullName)
 }
 
 func (db *DB) ErrLabelNotFound(fullName string, name string) error {
-	return fmt.Errorf("label %s not found in repository %s", name, fullName)
+	return fmt.Errorf("label %s %w in repository %s", name, storage.ErrNotFound, fullName)
+}
+
+func (db *DB) ErrRepositoryLimitReached(max int) error {
+	return fmt.Errorf("repository limit of %d reached", max)
+}
+
+func (db *DB) ErrWebhookNotFound(id int64) error {
+	return fmt.Errorf("webhook %d %w", id, storage.ErrNotFound)
+}
+
+func (db *DB) ErrSubscriptionNotFound(id int64) error {
+	return fmt.Errorf("subscription %d %w", id, storage.ErrNotFound)
+}
+
+func (db *DB) ErrTriageRuleNotFound(id int64) error {
+	return fmt.Errorf("triage rule %d %w", id, storage.ErrNotFound)
+}
+
+func (db *DB) ErrJobNotFound(id int64) error {
+	return fmt.Errorf("job %d %w", id, storage.ErrNotFound)
 }
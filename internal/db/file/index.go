@@ -0,0 +1,244 @@
+package file
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// itemKey identifies a pull request or issue across repositories
+type itemKey struct {
+	repo   string
+	number int
+}
+
+// itemSet is a set of pull requests or issues
+type itemSet map[itemKey]struct{}
+
+// indexEntry holds the indexed attributes of a pull request or issue
+type indexEntry struct {
+	state     string
+	author    string
+	labels    []string
+	updatedAt time.Time
+}
+
+// itemIndex holds the secondary indexes of pull requests or issues.
+// Indexes are rebuilt when the file is loaded and are not persisted.
+type itemIndex struct {
+	entries  map[itemKey]*indexEntry
+	byState  map[string]itemSet
+	byAuthor map[string]itemSet
+	byLabel  map[string]itemSet
+
+	// Keys ordered by updated_at, oldest first
+	byUpdated []itemKey
+}
+
+// newItemIndex creates an empty index
+func newItemIndex() *itemIndex {
+	return &itemIndex{
+		entries:  make(map[itemKey]*indexEntry),
+		byState:  make(map[string]itemSet),
+		byAuthor: make(map[string]itemSet),
+		byLabel:  make(map[string]itemSet),
+	}
+}
+
+// addTo adds key to the set stored under value
+func addTo(sets map[string]itemSet, value string, key itemKey) {
+	value = strings.ToLower(value)
+	if sets[value] == nil {
+		sets[value] = make(itemSet)
+	}
+	sets[value][key] = struct{}{}
+}
+
+// removeFrom removes key from the set stored under value
+func removeFrom(sets map[string]itemSet, value string, key itemKey) {
+	value = strings.ToLower(value)
+	delete(sets[value], key)
+	if len(sets[value]) == 0 {
+		delete(sets, value)
+	}
+}
+
+// updatedPosition returns where an item updated at t belongs in byUpdated
+func (idx *itemIndex) updatedPosition(key itemKey, t time.Time) int {
+	return sort.Search(len(idx.byUpdated), func(i int) bool {
+		other := idx.entries[idx.byUpdated[i]]
+		if !other.updatedAt.Equal(t) {
+			return other.updatedAt.After(t)
+		}
+		if idx.byUpdated[i].repo != key.repo {
+			return idx.byUpdated[i].repo > key.repo
+		}
+		return idx.byUpdated[i].number >= key.number
+	})
+}
+
+// put indexes an item, replacing its previous attributes but keeping its labels
+func (idx *itemIndex) put(key itemKey, state, author string, updatedAt time.Time) {
+	var labels []string
+	if old, ok := idx.entries[key]; ok {
+		labels = old.labels
+		idx.remove(key)
+	}
+
+	entry := &indexEntry{state: state, author: author, labels: labels, updatedAt: updatedAt}
+	pos := idx.updatedPosition(key, updatedAt)
+	idx.entries[key] = entry
+	idx.byUpdated = append(idx.byUpdated, itemKey{})
+	copy(idx.byUpdated[pos+1:], idx.byUpdated[pos:])
+	idx.byUpdated[pos] = key
+
+	addTo(idx.byState, state, key)
+	addTo(idx.byAuthor, author, key)
+	for _, label := range labels {
+		addTo(idx.byLabel, label, key)
+	}
+}
+
+// remove drops an item from the index
+func (idx *itemIndex) remove(key itemKey) {
+	entry, ok := idx.entries[key]
+	if !ok {
+		return
+	}
+
+	pos := idx.updatedPosition(key, entry.updatedAt)
+	if pos < len(idx.byUpdated) && idx.byUpdated[pos] == key {
+		idx.byUpdated = append(idx.byUpdated[:pos], idx.byUpdated[pos+1:]...)
+	}
+	removeFrom(idx.byState, entry.state, key)
+	removeFrom(idx.byAuthor, entry.author, key)
+	for _, label := range entry.labels {
+		removeFrom(idx.byLabel, label, key)
+	}
+	delete(idx.entries, key)
+}
+
+// removeRepository drops every item of a repository from the index
+func (idx *itemIndex) removeRepository(repo string) {
+	for key := range idx.entries {
+		if key.repo == repo {
+			idx.remove(key)
+		}
+	}
+}
+
+// setLabels replaces the labels of an indexed item
+func (idx *itemIndex) setLabels(key itemKey, labels []string) {
+	entry, ok := idx.entries[key]
+	if !ok {
+		return
+	}
+
+	for _, label := range entry.labels {
+		removeFrom(idx.byLabel, label, key)
+	}
+	entry.labels = append([]string(nil), labels...)
+	for _, label := range entry.labels {
+		addTo(idx.byLabel, label, key)
+	}
+}
+
+// find returns the keys matching the query, ordered by repository and number
+func (idx *itemIndex) find(query *models.ItemQuery) []itemKey {
+	keys := idx.match(query)
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].repo != keys[j].repo {
+			return keys[i].repo < keys[j].repo
+		}
+		return keys[i].number < keys[j].number
+	})
+	return keys
+}
+
+// match returns the keys matching the query. Equality filters are answered by
+// intersecting their sets, starting from the smallest; the updated_at filter
+// uses the ordered keys when it is the only one.
+func (idx *itemIndex) match(query *models.ItemQuery) []itemKey {
+	var sets []itemSet
+	if query.State != "" {
+		sets = append(sets, idx.byState[strings.ToLower(query.State)])
+	}
+	if query.Author != "" {
+		sets = append(sets, idx.byAuthor[strings.ToLower(query.Author)])
+	}
+	if query.Label != "" {
+		sets = append(sets, idx.byLabel[strings.ToLower(query.Label)])
+	}
+
+	var repos map[string]bool
+	if query.Repositories != nil {
+		repos = make(map[string]bool, len(query.Repositories))
+		for _, repo := range query.Repositories {
+			repos[repo] = true
+		}
+	}
+
+	matches := func(key itemKey) bool {
+		if repos != nil && !repos[key.repo] {
+			return false
+		}
+		if !query.UpdatedSince.IsZero() && idx.entries[key].updatedAt.Before(query.UpdatedSince) {
+			return false
+		}
+		return true
+	}
+
+	var keys []itemKey
+	if len(sets) == 0 {
+		start := 0
+		if !query.UpdatedSince.IsZero() {
+			start = sort.Search(len(idx.byUpdated), func(i int) bool {
+				return !idx.entries[idx.byUpdated[i]].updatedAt.Before(query.UpdatedSince)
+			})
+		}
+		for _, key := range idx.byUpdated[start:] {
+			if matches(key) {
+				keys = append(keys, key)
+			}
+		}
+		return keys
+	}
+
+	sort.Slice(sets, func(i, j int) bool { return len(sets[i]) < len(sets[j]) })
+	for key := range sets[0] {
+		inAll := true
+		for _, set := range sets[1:] {
+			if _, ok := set[key]; !ok {
+				inAll = false
+				break
+			}
+		}
+		if inAll && matches(key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// rebuildIndexes indexes every stored pull request and issue
+func (db *DB) rebuildIndexes() {
+	db.prIndex = newItemIndex()
+	for repo, prs := range db.pullRequests {
+		for number, pr := range prs {
+			key := itemKey{repo: repo, number: number}
+			db.prIndex.put(key, pr.State, pr.UserLogin, pr.UpdatedAt)
+			db.prIndex.setLabels(key, db.prLabels[repo][number])
+		}
+	}
+
+	db.issueIndex = newItemIndex()
+	for repo, issues := range db.issues {
+		for number, issue := range issues {
+			key := itemKey{repo: repo, number: number}
+			db.issueIndex.put(key, issue.State, issue.UserLogin, issue.UpdatedAt)
+			db.issueIndex.setLabels(key, db.issueLabels[repo][number])
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package file
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// TestFindPullRequests tests that index-backed queries follow writes, label changes and deletes
+func TestFindPullRequests(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "test.db")
+	db, err := NewDB(path)
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+
+	now := time.Now()
+	prs := []*models.PullRequest{
+		{RepositoryFullName: "pingcap/tidb", Number: 1, State: "open", UserLogin: "alice", UpdatedAt: now.Add(-48 * time.Hour)},
+		{RepositoryFullName: "pingcap/tidb", Number: 2, State: "closed", UserLogin: "bob", UpdatedAt: now.Add(-time.Hour)},
+		{RepositoryFullName: "pingcap/tikv", Number: 3, State: "open", UserLogin: "Alice", UpdatedAt: now},
+	}
+	for _, pr := range prs {
+		if err := db.AddPullRequest(ctx, pr); err != nil {
+			t.Fatalf("AddPullRequest() error = %v", err)
+		}
+	}
+	if err := db.AddPullRequestLabel(ctx, "pingcap/tikv", 3, "bug"); err != nil {
+		t.Fatalf("AddPullRequestLabel() error = %v", err)
+	}
+
+	numbers := func(query *models.ItemQuery) map[int]bool {
+		t.Helper()
+		found, err := db.FindPullRequests(ctx, query)
+		if err != nil {
+			t.Fatalf("FindPullRequests() error = %v", err)
+		}
+		got := make(map[int]bool)
+		for _, pr := range found {
+			got[pr.Number] = true
+		}
+		return got
+	}
+
+	tests := []struct {
+		name  string
+		query models.ItemQuery
+		want  []int
+	}{
+		{name: "All", query: models.ItemQuery{}, want: []int{1, 2, 3}},
+		{name: "State", query: models.ItemQuery{State: "OPEN"}, want: []int{1, 3}},
+		{name: "Author", query: models.ItemQuery{Author: "alice", Repositories: []string{"pingcap/tidb"}}, want: []int{1}},
+		{name: "Label", query: models.ItemQuery{Label: "bug"}, want: []int{3}},
+		{name: "Updated since", query: models.ItemQuery{UpdatedSince: now.Add(-2 * time.Hour)}, want: []int{2, 3}},
+		{name: "No repositories", query: models.ItemQuery{Repositories: []string{}}, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := numbers(&tt.query)
+			if len(got) != len(tt.want) {
+				t.Fatalf("FindPullRequests() = %v, want %v", got, tt.want)
+			}
+			for _, n := range tt.want {
+				if !got[n] {
+					t.Errorf("FindPullRequests() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+
+	// Updating a pull request moves it between index entries
+	if err := db.UpdatePullRequest(ctx, &models.PullRequest{RepositoryFullName: "pingcap/tidb", Number: 1, State: "closed", UserLogin: "alice", UpdatedAt: now}); err != nil {
+		t.Fatalf("UpdatePullRequest() error = %v", err)
+	}
+	if got := numbers(&models.ItemQuery{State: "open"}); len(got) != 1 || !got[3] {
+		t.Errorf("FindPullRequests() after update = %v, want [3]", got)
+	}
+
+	// Indexes are rebuilt when the file is loaded; it is still open for writing, so it is read-only
+	reopened, err := NewDBWithOptions(path, Options{ReadOnly: true})
+	if err != nil {
+		t.Fatalf("NewDBWithOptions() error = %v", err)
+	}
+	found, err := reopened.FindPullRequests(ctx, &models.ItemQuery{Label: "bug", State: "open"})
+	if err != nil || len(found) != 1 || found[0].Number != 3 {
+		t.Errorf("FindPullRequests() after reload = %v, %v, want [3]", found, err)
+	}
+
+	if err := db.DeletePullRequest(ctx, "pingcap/tikv", 3); err != nil {
+		t.Fatalf("DeletePullRequest() error = %v", err)
+	}
+	if got := numbers(&models.ItemQuery{Label: "bug"}); len(got) != 0 {
+		t.Errorf("FindPullRequests() after delete = %v, want none", got)
+	}
+}
+
+// TestStableOrdering tests that listings are ordered independently of insertion order
+func TestStableOrdering(t *testing.T) {
+	ctx := context.Background()
+	db, err := NewDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+
+	for _, name := range []string{"pingcap/tikv", "pingcap/pd", "pingcap/tidb"} {
+		if err := db.AddRepository(ctx, &models.Repository{FullName: name}); err != nil {
+			t.Fatalf("AddRepository() error = %v", err)
+		}
+	}
+	for _, number := range []int{5, 2, 9, 1} {
+		if err := db.AddIssue(ctx, &models.Issue{RepositoryFullName: "pingcap/tidb", Number: number, State: "open"}); err != nil {
+			t.Fatalf("AddIssue() error = %v", err)
+		}
+	}
+
+	repos, _, err := db.ListRepositories(ctx, 2, 2)
+	if err != nil {
+		t.Fatalf("ListRepositories() error = %v", err)
+	}
+	if len(repos) != 1 || repos[0].FullName != "pingcap/tikv" {
+		t.Errorf("ListRepositories() page 2 = %v, want [pingcap/tikv]", repos)
+	}
+
+	issues, _, err := db.ListIssues(ctx, "pingcap/tidb", 1, 3)
+	if err != nil {
+		t.Fatalf("ListIssues() error = %v", err)
+	}
+	var got []int
+	for _, issue := range issues {
+		got = append(got, issue.Number)
+	}
+	if len(got) != 3 || got[0] != 1 || got[1] != 2 || got[2] != 5 {
+		t.Errorf("ListIssues() page 1 = %v, want [1 2 5]", got)
+	}
+}
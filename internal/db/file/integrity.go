@@ -0,0 +1,211 @@
+package file
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// CheckIntegrity checks that stored pull requests, issues, label links and diffs point at tracked
+// repositories, existing items and defined labels, and that the numbers listed per repository
+// match the stored items. With repair, orphans are removed, missing labels are defined again,
+// and lists and item keys are fixed. Problems are ordered by repository and number.
+func (db *DB) CheckIntegrity(ctx context.Context, repair bool) (*models.IntegrityReport, error) {
+	if repair {
+		db.Lock()
+		defer db.Unlock()
+	} else {
+		db.RLock()
+		defer db.RUnlock()
+	}
+
+	report := &models.IntegrityReport{Problems: []*models.IntegrityProblem{}}
+	add := func(problem *models.IntegrityProblem) {
+		problem.Repaired = repair
+		if repair {
+			report.Repaired++
+		}
+		report.Problems = append(report.Problems, problem)
+	}
+
+	// Items of repositories that are no longer tracked, and items stored under another key
+	for fullName, prs := range db.pullRequests {
+		if _, ok := db.repositories[fullName]; !ok {
+			add(&models.IntegrityProblem{Kind: models.IntegrityUntrackedRepository, Repository: fullName, ItemType: models.ItemTypePullRequest,
+				Detail: fmt.Sprintf("%d pull requests of a repository that isn't tracked", len(prs))})
+			if repair {
+				delete(db.pullRequests, fullName)
+				delete(db.repoPRs, fullName)
+				delete(db.prLabels, fullName)
+				continue
+			}
+		}
+		for number, pr := range prs {
+			if pr.RepositoryFullName != fullName || pr.Number != number {
+				add(&models.IntegrityProblem{Kind: models.IntegrityKeyMismatch, Repository: fullName, ItemType: models.ItemTypePullRequest, Number: number,
+					Detail: fmt.Sprintf("stored as %s#%d", pr.RepositoryFullName, pr.Number)})
+				if repair {
+					// Snapshots and readers share the stored record, so replace it with a fixed copy
+					fixed := *pr
+					fixed.RepositoryFullName, fixed.Number = fullName, number
+					prs[number] = &fixed
+				}
+			}
+		}
+	}
+	for fullName, issues := range db.issues {
+		if _, ok := db.repositories[fullName]; !ok {
+			add(&models.IntegrityProblem{Kind: models.IntegrityUntrackedRepository, Repository: fullName, ItemType: models.ItemTypeIssue,
+				Detail: fmt.Sprintf("%d issues of a repository that isn't tracked", len(issues))})
+			if repair {
+				delete(db.issues, fullName)
+				delete(db.repoIssues, fullName)
+				delete(db.issueLabels, fullName)
+				continue
+			}
+		}
+		for number, issue := range issues {
+			if issue.RepositoryFullName != fullName || issue.Number != number {
+				add(&models.IntegrityProblem{Kind: models.IntegrityKeyMismatch, Repository: fullName, ItemType: models.ItemTypeIssue, Number: number,
+					Detail: fmt.Sprintf("stored as %s#%d", issue.RepositoryFullName, issue.Number)})
+				if repair {
+					fixed := *issue
+					fixed.RepositoryFullName, fixed.Number = fullName, number
+					issues[number] = &fixed
+				}
+			}
+		}
+	}
+
+	prs := make(map[string]map[int]bool, len(db.pullRequests))
+	for fullName, byNumber := range db.pullRequests {
+		prs[fullName] = make(map[int]bool, len(byNumber))
+		for number := range byNumber {
+			prs[fullName][number] = true
+		}
+	}
+	issues := make(map[string]map[int]bool, len(db.issues))
+	for fullName, byNumber := range db.issues {
+		issues[fullName] = make(map[int]bool, len(byNumber))
+		for number := range byNumber {
+			issues[fullName][number] = true
+		}
+	}
+	db.checkNumbers(models.ItemTypePullRequest, prs, db.repoPRs, repair, add)
+	db.checkNumbers(models.ItemTypeIssue, issues, db.repoIssues, repair, add)
+	db.checkLabelLinks(models.ItemTypePullRequest, prs, db.prLabels, repair, add)
+	db.checkLabelLinks(models.ItemTypeIssue, issues, db.issueLabels, repair, add)
+
+	// Cached diffs of pull requests that don't exist
+	for fullName, diffs := range db.diffs {
+		kept := make([]*models.PullRequestDiff, 0, len(diffs))
+		for _, diff := range diffs {
+			if prs[fullName][diff.Number] {
+				kept = append(kept, diff)
+				continue
+			}
+			add(&models.IntegrityProblem{Kind: models.IntegrityOrphanedDiff, Repository: fullName, ItemType: models.ItemTypePullRequest, Number: diff.Number,
+				Detail: fmt.Sprintf("%s of a pull request that doesn't exist", diff.Format)})
+		}
+		if repair {
+			db.diffs[fullName] = kept
+		}
+	}
+
+	sort.SliceStable(report.Problems, func(i, j int) bool {
+		a, b := report.Problems[i], report.Problems[j]
+		if a.Repository != b.Repository {
+			return a.Repository < b.Repository
+		}
+		if a.ItemType != b.ItemType {
+			return a.ItemType < b.ItemType
+		}
+		if a.Number != b.Number {
+			return a.Number < b.Number
+		}
+		return a.Kind < b.Kind
+	})
+
+	if !repair || report.Repaired == 0 {
+		return report, nil
+	}
+	db.rebuildIndexes()
+	if err := db.sync(); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// checkNumbers checks that the numbers listed per repository are those of the stored items.
+// It must be called with the lock held, the write lock to repair.
+func (db *DB) checkNumbers(itemType string, stored map[string]map[int]bool, lists map[string][]int, repair bool, add func(*models.IntegrityProblem)) {
+	listed := make(map[string]map[int]bool, len(lists))
+	for fullName, numbers := range lists {
+		listed[fullName] = make(map[int]bool, len(numbers))
+		kept := make([]int, 0, len(numbers))
+		for _, number := range numbers {
+			if !stored[fullName][number] || listed[fullName][number] {
+				add(&models.IntegrityProblem{Kind: models.IntegrityDanglingNumber, Repository: fullName, ItemType: itemType, Number: number,
+					Detail: "listed without a stored item, or listed twice"})
+				continue
+			}
+			listed[fullName][number] = true
+			kept = append(kept, number)
+		}
+		if repair {
+			sort.Ints(kept)
+			lists[fullName] = kept
+		}
+	}
+	for fullName, numbers := range stored {
+		for number := range numbers {
+			if !listed[fullName][number] {
+				add(&models.IntegrityProblem{Kind: models.IntegrityUnlistedItem, Repository: fullName, ItemType: itemType, Number: number,
+					Detail: "stored but missing from the numbers of its repository"})
+				if repair {
+					lists[fullName] = insertNumber(lists[fullName], number)
+				}
+			}
+		}
+	}
+}
+
+// checkLabelLinks checks that labels are attached to stored items and defined.
+// It must be called with the lock held, the write lock to repair.
+func (db *DB) checkLabelLinks(itemType string, stored map[string]map[int]bool, links map[string]map[int][]string, repair bool, add func(*models.IntegrityProblem)) {
+	for fullName, byNumber := range links {
+		for number, names := range byNumber {
+			if !stored[fullName][number] {
+				add(&models.IntegrityProblem{Kind: models.IntegrityOrphanedLabelLink, Repository: fullName, ItemType: itemType, Number: number,
+					Detail: fmt.Sprintf("%d labels attached to an item that doesn't exist", len(names))})
+				if repair {
+					delete(byNumber, number)
+				}
+				continue
+			}
+			for _, name := range names {
+				if _, ok := db.labels["global"][name]; ok {
+					continue
+				}
+				add(&models.IntegrityProblem{Kind: models.IntegrityMissingLabel, Repository: fullName, ItemType: itemType, Number: number,
+					Detail: fmt.Sprintf("label %q isn't defined", name)})
+				if repair {
+					db.defineLabel(&models.Label{Name: name})
+				}
+			}
+		}
+	}
+}
+
+// defineLabel adds a label, like AddLabel, without saving.
+// It must be called with the write lock held.
+func (db *DB) defineLabel(label *models.Label) {
+	for _, byRepo := range []map[string]map[string]*models.Label{db.labels, db.repoLabels} {
+		if _, ok := byRepo["global"]; !ok {
+			byRepo["global"] = make(map[string]*models.Label)
+		}
+		byRepo["global"][label.Name] = label
+	}
+}
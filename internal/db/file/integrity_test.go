@@ -0,0 +1,81 @@
+package file
+
+import (
+	"context"
+	"testing"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// TestCheckIntegrity tests finding and repairing orphans and inconsistencies
+func TestCheckIntegrity(t *testing.T) {
+	ctx := context.Background()
+	db, err := NewDB("")
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	if err := db.AddRepository(ctx, &models.Repository{Owner: "org", Name: "api", FullName: "org/api"}); err != nil {
+		t.Fatalf("AddRepository() error = %v", err)
+	}
+	for _, pr := range []*models.PullRequest{
+		{RepositoryFullName: "org/api", Number: 1, State: "OPEN"},
+		{RepositoryFullName: "org/gone", Number: 1, State: "OPEN"},
+	} {
+		if err := db.AddPullRequest(ctx, pr); err != nil {
+			t.Fatalf("AddPullRequest() error = %v", err)
+		}
+	}
+	if err := db.AddIssue(ctx, &models.Issue{RepositoryFullName: "org/api", Number: 2, State: "OPEN"}); err != nil {
+		t.Fatalf("AddIssue() error = %v", err)
+	}
+	// A label that was never defined, and labels of a deleted pull request
+	if err := db.AddPullRequestLabel(ctx, "org/api", 1, "bug"); err != nil {
+		t.Fatalf("AddPullRequestLabel() error = %v", err)
+	}
+	if err := db.AddPullRequestLabel(ctx, "org/api", 9, "bug"); err != nil {
+		t.Fatalf("AddPullRequestLabel() error = %v", err)
+	}
+	db.repoIssues["org/api"] = append(db.repoIssues["org/api"], 5)
+	db.issues["org/api"][2].Number = 3
+
+	report, err := db.CheckIntegrity(ctx, false)
+	if err != nil {
+		t.Fatalf("CheckIntegrity() error = %v", err)
+	}
+	want := []string{
+		models.IntegrityKeyMismatch, models.IntegrityDanglingNumber, models.IntegrityMissingLabel,
+		models.IntegrityOrphanedLabelLink, models.IntegrityUntrackedRepository,
+	}
+	if len(report.Problems) != len(want) || report.Repaired != 0 {
+		t.Fatalf("CheckIntegrity() = %d problems, %d repaired, want %d unrepaired", len(report.Problems), report.Repaired, len(want))
+	}
+	for i, problem := range report.Problems {
+		if problem.Kind != want[i] {
+			t.Errorf("Problems[%d] = %+v, want %s", i, problem, want[i])
+		}
+	}
+
+	// Repairs replace records rather than change them, leaving earlier snapshots as they were
+	snap, err := db.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Snapshot() error = %v", err)
+	}
+	if report, err = db.CheckIntegrity(ctx, true); err != nil || report.Repaired != len(want) {
+		t.Fatalf("CheckIntegrity(repair) = %+v, %v, want %d repaired", report, err, len(want))
+	}
+	if report, _ = db.CheckIntegrity(ctx, false); len(report.Problems) != 0 {
+		t.Errorf("CheckIntegrity() after repair = %+v, want no problems", report.Problems)
+	}
+	if issue, _ := db.GetIssue(ctx, "org/api", 2); issue.Number != 2 {
+		t.Errorf("repaired issue number = %d, want 2", issue.Number)
+	}
+	if issue, _ := snap.GetIssue(ctx, "org/api", 2); issue.Number != 3 {
+		t.Errorf("issue number in a snapshot taken before the repair = %d, want 3 as it was", issue.Number)
+	}
+	if current, _ := db.Snapshot(ctx); current == snap {
+		t.Error("Snapshot() after repair returned the snapshot taken before it")
+	}
+	if _, err := db.GetLabel(ctx, "bug"); err != nil {
+		t.Errorf("GetLabel(bug) error = %v after repair", err)
+	}
+}
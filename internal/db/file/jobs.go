@@ -0,0 +1,115 @@
+package file
+
+import (
+	"context"
+	"sort"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// Job operations. Jobs are stored and returned as copies, so the queue can keep
+// updating its own job while readers look at a consistent state.
+
+// AddJob adds a job to the database and assigns its ID
+func (db *DB) AddJob(ctx context.Context, job *models.Job) error {
+	db.Lock()
+	defer db.Unlock()
+
+	db.nextJobID++
+	job.ID = db.nextJobID
+	stored := *job
+	db.jobs = append(db.jobs, &stored)
+	db.trimJobs()
+
+	return db.sync()
+}
+
+// trimJobs drops the oldest finished jobs beyond maxJobs; the caller must hold the write lock
+func (db *DB) trimJobs() {
+	excess := len(db.jobs) - maxJobs
+	if excess <= 0 {
+		return
+	}
+
+	kept := db.jobs[:0]
+	for _, job := range db.jobs {
+		if excess > 0 && job.Done() {
+			excess--
+			continue
+		}
+		kept = append(kept, job)
+	}
+	db.jobs = kept
+}
+
+// findJob returns the index of a job, or -1; the caller must hold the lock
+func (db *DB) findJob(id int64) int {
+	i := sort.Search(len(db.jobs), func(i int) bool { return db.jobs[i].ID >= id })
+	if i < len(db.jobs) && db.jobs[i].ID == id {
+		return i
+	}
+	return -1
+}
+
+// UpdateJob updates a job in the database
+func (db *DB) UpdateJob(ctx context.Context, job *models.Job) error {
+	db.Lock()
+	defer db.Unlock()
+
+	i := db.findJob(job.ID)
+	if i < 0 {
+		return db.ErrJobNotFound(job.ID)
+	}
+	stored := *job
+	db.jobs[i] = &stored
+
+	return db.sync()
+}
+
+// GetJob gets a job from the database
+func (db *DB) GetJob(ctx context.Context, id int64) (*models.Job, error) {
+	db.RLock()
+	defer db.RUnlock()
+
+	i := db.findJob(id)
+	if i < 0 {
+		return nil, db.ErrJobNotFound(id)
+	}
+	job := *db.jobs[i]
+	return &job, nil
+}
+
+// ListJobs lists jobs matching the filter, newest first
+func (db *DB) ListJobs(ctx context.Context, filter *models.JobFilter) ([]*models.Job, int, error) {
+	db.RLock()
+	defer db.RUnlock()
+
+	jobs := make([]*models.Job, 0)
+	for i := len(db.jobs) - 1; i >= 0; i-- {
+		job := db.jobs[i]
+		if filter.Type != "" && job.Type != filter.Type {
+			continue
+		}
+		if filter.State != "" && job.State != filter.State {
+			continue
+		}
+		if filter.Target != "" && job.Target != filter.Target {
+			continue
+		}
+		copied := *job
+		jobs = append(jobs, &copied)
+	}
+
+	total := len(jobs)
+	offset := (filter.Page - 1) * filter.PerPage
+	if offset >= total {
+		return []*models.Job{}, total, nil
+	}
+
+	end := offset + filter.PerPage
+	if end > total {
+		end = total
+	}
+
+	return jobs[offset:end], total, nil
+}
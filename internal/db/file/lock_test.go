@@ -0,0 +1,57 @@
+//go:build unix
+
+package file
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	storage "github.com/siddontang/github-repos-management/internal/db"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// TestLock tests that a file is opened for writing once, while read-only opens load its data
+func TestLock(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "test.db")
+	db, err := NewDB(path)
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	if err := db.AddRepository(ctx, &models.Repository{Owner: "pingcap", Name: "tidb", FullName: "pingcap/tidb"}); err != nil {
+		t.Fatalf("AddRepository() error = %v", err)
+	}
+
+	if _, err := NewDB(path); !errors.Is(err, storage.ErrDatabaseInUse) {
+		t.Fatalf("second NewDB() error = %v, want ErrDatabaseInUse", err)
+	}
+
+	readOnly, err := NewDBWithOptions(path, Options{ReadOnly: true})
+	if err != nil {
+		t.Fatalf("NewDBWithOptions() error = %v", err)
+	}
+	if _, err := readOnly.GetRepository(ctx, "pingcap", "tidb"); err != nil {
+		t.Errorf("GetRepository() of read-only database error = %v", err)
+	}
+	if err := readOnly.AddRepository(ctx, &models.Repository{Owner: "pingcap", Name: "tikv", FullName: "pingcap/tikv"}); !errors.Is(err, storage.ErrReadOnly) {
+		t.Errorf("AddRepository() of read-only database error = %v, want ErrReadOnly", err)
+	}
+	if err := readOnly.Close(); err != nil {
+		t.Errorf("Close() of read-only database error = %v", err)
+	}
+
+	// Closing releases the lock
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	reopened, err := NewDB(path)
+	if err != nil {
+		t.Fatalf("NewDB() after Close() error = %v", err)
+	}
+	defer reopened.Close()
+	if _, err := reopened.GetRepository(ctx, "pingcap", "tikv"); err == nil {
+		t.Error("write to the read-only database was saved")
+	}
+}
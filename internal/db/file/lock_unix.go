@@ -0,0 +1,30 @@
+//go:build unix
+
+package file
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+
+	storage "github.com/siddontang/github-repos-management/internal/db"
+)
+
+// lockFile takes an exclusive advisory lock on path, failing with db.ErrDatabaseInUse
+// rather than waiting when another process holds it. The lock is released by closing
+// the returned file, or when the process exits.
+func lockFile(path string) (*os.File, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		f.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, storage.ErrDatabaseInUse
+		}
+		return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+	}
+	return f, nil
+}
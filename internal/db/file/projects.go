@@ -0,0 +1,107 @@
+package file
+
+import (
+	"context"
+	"slices"
+	"sort"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// Project operations. Projects and project items are replaced as a whole on each sync and
+// returned as copies.
+
+// ReplaceProjects replaces the linked projects and the project items of a repository
+func (db *DB) ReplaceProjects(ctx context.Context, repoFullName string, projects []*models.Project, items []*models.ProjectItem) error {
+	db.Lock()
+	defer db.Unlock()
+
+	if _, ok := db.repositories[repoFullName]; !ok {
+		return db.ErrRepositoryNotFound(repoFullName)
+	}
+
+	storedProjects := make([]*models.Project, 0, len(projects))
+	for _, project := range projects {
+		clone := *project
+		clone.Columns = slices.Clone(project.Columns)
+		clone.Repositories = nil
+		clone.Items = nil
+		storedProjects = append(storedProjects, &clone)
+	}
+	storedItems := make([]*models.ProjectItem, 0, len(items))
+	for _, item := range items {
+		clone := *item
+		clone.RepositoryFullName = repoFullName
+		storedItems = append(storedItems, &clone)
+	}
+	db.projects[repoFullName] = storedProjects
+	db.projectItems[repoFullName] = storedItems
+	return db.sync()
+}
+
+// ListProjects lists the projects linked to a repository, or to every repository when repoFullName
+// is empty, ordered by repository, owner and number. Each project has Repositories set to the
+// repository it is linked to, so a project linked to several repositories is listed once for each.
+func (db *DB) ListProjects(ctx context.Context, repoFullName string) ([]*models.Project, error) {
+	db.RLock()
+	defer db.RUnlock()
+
+	projects := make([]*models.Project, 0)
+	for fullName, list := range db.projects {
+		if repoFullName != "" && fullName != repoFullName {
+			continue
+		}
+		for _, project := range list {
+			clone := *project
+			clone.Columns = slices.Clone(project.Columns)
+			clone.Repositories = []string{fullName}
+			projects = append(projects, &clone)
+		}
+	}
+	sort.Slice(projects, func(i, j int) bool {
+		a, b := projects[i], projects[j]
+		if a.Repositories[0] != b.Repositories[0] {
+			return a.Repositories[0] < b.Repositories[0]
+		}
+		if a.Owner != b.Owner {
+			return a.Owner < b.Owner
+		}
+		return a.Number < b.Number
+	})
+	return projects, nil
+}
+
+// ListProjectItems lists the project items of a repository, or of every repository when
+// repoFullName is empty, ordered by repository, project, type and number
+func (db *DB) ListProjectItems(ctx context.Context, repoFullName string) ([]*models.ProjectItem, error) {
+	db.RLock()
+	defer db.RUnlock()
+
+	items := make([]*models.ProjectItem, 0)
+	for fullName, list := range db.projectItems {
+		if repoFullName != "" && fullName != repoFullName {
+			continue
+		}
+		for _, item := range list {
+			clone := *item
+			items = append(items, &clone)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		a, b := items[i], items[j]
+		if a.RepositoryFullName != b.RepositoryFullName {
+			return a.RepositoryFullName < b.RepositoryFullName
+		}
+		if a.ProjectOwner != b.ProjectOwner {
+			return a.ProjectOwner < b.ProjectOwner
+		}
+		if a.ProjectNumber != b.ProjectNumber {
+			return a.ProjectNumber < b.ProjectNumber
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		return a.Number < b.Number
+	})
+	return items, nil
+}
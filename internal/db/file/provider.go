@@ -5,10 +5,42 @@ import (
 	"github.com/siddontang/github-repos-management/internal/db"
 )
 
+func init() {
+	db.Register(config.DBTypeFile, NewProvider())
+	db.Register(config.DBTypeMemory, NewMemoryProvider())
+}
+
 // NewProvider creates a new file database provider
 func NewProvider() db.Provider {
 	return func(config *config.Config) (db.DB, error) {
 		// Create a new file database with the path from config
-		return NewDB(config.Database.Path)
+		db, err := NewDBWithOptions(config.Database.Path, Options{ReadOnly: config.Database.ReadOnly, Indent: config.Database.Indent})
+		if err != nil {
+			return nil, err
+		}
+
+		db.SetLimits(Limits{
+			MaxRepositories:       config.Cache.MaxRepositories,
+			MaxItemsPerRepository: config.Cache.MaxItemsPerRepository,
+			MaxMemoryBytes:        config.Cache.MaxMemoryBytes,
+		})
+		return db, nil
+	}
+}
+
+// NewMemoryProvider creates a database provider that keeps data in memory only, without persistence
+func NewMemoryProvider() db.Provider {
+	return func(config *config.Config) (db.DB, error) {
+		db, err := NewDB("")
+		if err != nil {
+			return nil, err
+		}
+
+		db.SetLimits(Limits{
+			MaxRepositories:       config.Cache.MaxRepositories,
+			MaxItemsPerRepository: config.Cache.MaxItemsPerRepository,
+			MaxMemoryBytes:        config.Cache.MaxMemoryBytes,
+		})
+		return db, nil
 	}
 }
@@ -0,0 +1,79 @@
+package file
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// closedAt returns when a pull request or issue was closed, falling back to its last update
+func closedAt(closed, merged *time.Time, updated time.Time) time.Time {
+	if closed != nil {
+		return *closed
+	}
+	if merged != nil {
+		return *merged
+	}
+	return updated
+}
+
+// ExpireClosedItems drops the closed pull requests and issues a retention policy no longer keeps,
+// with their labels. Open items are always kept.
+func (db *DB) ExpireClosedItems(ctx context.Context, policy *models.RetentionPolicy) (*models.RetentionResult, error) {
+	db.Lock()
+	defer db.Unlock()
+
+	result := &models.RetentionResult{}
+	for fullName, prs := range db.pullRequests {
+		var closed []evictionCandidate
+		for number, pr := range prs {
+			if !strings.EqualFold(pr.State, "open") {
+				closed = append(closed, evictionCandidate{repo: fullName, number: number, isPR: true, closed: true, updatedAt: closedAt(pr.ClosedAt, pr.MergedAt, pr.UpdatedAt)})
+			}
+		}
+		result.PullRequests += db.expire(closed, policy)
+	}
+	for fullName, issues := range db.issues {
+		var closed []evictionCandidate
+		for number, issue := range issues {
+			if !strings.EqualFold(issue.State, "open") {
+				closed = append(closed, evictionCandidate{repo: fullName, number: number, closed: true, updatedAt: closedAt(issue.ClosedAt, nil, issue.UpdatedAt)})
+			}
+		}
+		result.Issues += db.expire(closed, policy)
+	}
+
+	if result.PullRequests == 0 && result.Issues == 0 {
+		return result, nil
+	}
+	if err := db.sync(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// expire drops the closed items of a repository that a retention policy no longer keeps and
+// returns how many. The updatedAt of the candidates is when they were closed.
+// It must be called with the write lock held.
+func (db *DB) expire(closed []evictionCandidate, policy *models.RetentionPolicy) int {
+	sort.Slice(closed, func(i, j int) bool {
+		if !closed[i].updatedAt.Equal(closed[j].updatedAt) {
+			return closed[i].updatedAt.After(closed[j].updatedAt)
+		}
+		return closed[i].number > closed[j].number
+	})
+
+	dropped := 0
+	for i, c := range closed {
+		tooOld := !policy.ClosedBefore.IsZero() && c.updatedAt.Before(policy.ClosedBefore)
+		tooMany := policy.MaxClosedPerRepository > 0 && i >= policy.MaxClosedPerRepository
+		if tooOld || tooMany {
+			db.drop(c)
+			dropped++
+		}
+	}
+	return dropped
+}
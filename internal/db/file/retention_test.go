@@ -0,0 +1,68 @@
+package file
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// TestExpireClosedItems tests dropping closed items by age and by count per repository
+func TestExpireClosedItems(t *testing.T) {
+	ctx := context.Background()
+	db, err := NewDB("")
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+
+	now := time.Now()
+	daysAgo := func(days int) *time.Time {
+		t := now.AddDate(0, 0, -days)
+		return &t
+	}
+	for _, pr := range []*models.PullRequest{
+		{RepositoryFullName: "org/api", Number: 1, State: "OPEN", UpdatedAt: *daysAgo(400)},
+		{RepositoryFullName: "org/api", Number: 2, State: "MERGED", MergedAt: daysAgo(300), ClosedAt: daysAgo(300)},
+		{RepositoryFullName: "org/api", Number: 3, State: "CLOSED", ClosedAt: daysAgo(10)},
+	} {
+		if err := db.AddPullRequest(ctx, pr); err != nil {
+			t.Fatalf("AddPullRequest() error = %v", err)
+		}
+	}
+	for number, days := range map[int]int{1: 3, 2: 2, 3: 1} {
+		if err := db.AddIssue(ctx, &models.Issue{RepositoryFullName: "org/api", Number: number, State: "CLOSED", ClosedAt: daysAgo(days)}); err != nil {
+			t.Fatalf("AddIssue() error = %v", err)
+		}
+	}
+	if err := db.AddLabel(ctx, &models.Label{Name: "bug"}); err != nil {
+		t.Fatalf("AddLabel() error = %v", err)
+	}
+	if err := db.AddPullRequestLabel(ctx, "org/api", 2, "bug"); err != nil {
+		t.Fatalf("AddPullRequestLabel() error = %v", err)
+	}
+
+	result, err := db.ExpireClosedItems(ctx, &models.RetentionPolicy{ClosedBefore: now.AddDate(0, 0, -180), MaxClosedPerRepository: 2})
+	if err != nil {
+		t.Fatalf("ExpireClosedItems() error = %v", err)
+	}
+	if result.PullRequests != 1 || result.Issues != 1 {
+		t.Errorf("ExpireClosedItems() = %+v, want 1 pull request and 1 issue", result)
+	}
+
+	// The open pull request is kept however old, and the oldest closed issue is over the count
+	prs, _ := db.ListAllPullRequests(ctx, "org/api")
+	if len(prs) != 2 {
+		t.Errorf("pull requests = %d, want #1 and #3", len(prs))
+	}
+	if _, err := db.GetIssue(ctx, "org/api", 1); err == nil {
+		t.Errorf("GetIssue(1) found an issue over the retention count")
+	}
+	if labels, _ := db.ListPullRequestLabels(ctx, "org/api", 2); len(labels) != 0 {
+		t.Errorf("labels of the expired pull request = %v, want none", labels)
+	}
+
+	if result, _ := db.ExpireClosedItems(ctx, &models.RetentionPolicy{}); result.PullRequests != 0 || result.Issues != 0 {
+		t.Errorf("ExpireClosedItems(empty policy) = %+v, want nothing dropped", result)
+	}
+}
@@ -0,0 +1,134 @@
+package file
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/siddontang/github-repos-management/internal/db"
+)
+
+// schemaVersion is the version of the layout of the data file written by this release. Files
+// without a version predate versioning and are version 0.
+const schemaVersion = 3
+
+// migration upgrades the decoded data file from the previous schema version to version
+type migration struct {
+	version     int
+	description string
+	apply       func(d map[string]interface{}) error
+}
+
+// migrations are applied in order to files with an older schema version. Append new ones with
+// the next version and bump schemaVersion; never change a released migration.
+var migrations = []migration{
+	{version: 1, description: "sort the pull request and issue numbers of each repository", apply: sortRepositoryNumbers},
+	{version: 2, description: "move subscription secrets to the secrets section", apply: moveSubscriptionSecrets},
+	{version: 3, description: "move webhook secrets to the secrets section", apply: moveWebhookSecrets},
+}
+
+// migrate upgrades a data file written with schema version from to the current version, returning
+// the upgraded file
+func migrate(file []byte, from int) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(file))
+	decoder.UseNumber()
+	var d map[string]interface{}
+	if err := decoder.Decode(&d); err != nil {
+		return nil, err
+	}
+
+	for _, m := range migrations {
+		if m.version <= from {
+			continue
+		}
+		if err := m.apply(d); err != nil {
+			return nil, fmt.Errorf("migration %d (%s): %w", m.version, m.description, err)
+		}
+	}
+	d["schema_version"] = schemaVersion
+	return json.Marshal(d)
+}
+
+// checkSchemaVersion fails for files written by a newer release, whose data might be lost by
+// rewriting it
+func checkSchemaVersion(version int) error {
+	if version > schemaVersion {
+		return fmt.Errorf("%w: file has version %d, this release supports up to %d", db.ErrUnsupportedSchema, version, schemaVersion)
+	}
+	return nil
+}
+
+// backup keeps the file as it was before a migration next to it, as path.v<version>.bak
+func (db *DB) backup(file []byte, version int) error {
+	return os.WriteFile(db.path+".v"+strconv.Itoa(version)+".bak", file, 0644)
+}
+
+// sortRepositoryNumbers sorts the pull request and issue numbers of each repository, which files
+// written before version 1 kept in insertion order
+func sortRepositoryNumbers(d map[string]interface{}) error {
+	for _, key := range []string{"repo_prs", "repo_issues"} {
+		byRepo, _ := d[key].(map[string]interface{})
+		for _, value := range byRepo {
+			numbers, _ := value.([]interface{})
+			ints := make([]int64, len(numbers))
+			for i, number := range numbers {
+				n, ok := number.(json.Number)
+				if !ok {
+					return fmt.Errorf("%s holds %v, not a number", key, number)
+				}
+				var err error
+				if ints[i], err = n.Int64(); err != nil {
+					return fmt.Errorf("%s holds %v, not an integer", key, number)
+				}
+			}
+			sort.Slice(ints, func(i, j int) bool { return ints[i] < ints[j] })
+			for i, n := range ints {
+				numbers[i] = n
+			}
+		}
+	}
+	return nil
+}
+
+// moveSubscriptionSecrets moves the secrets of subscriptions, which files written before version 2
+// kept with each subscription, to the secrets section
+func moveSubscriptionSecrets(d map[string]interface{}) error {
+	moveSecrets(d, "subscriptions", "secret")
+	return nil
+}
+
+// moveWebhookSecrets moves the secrets of webhooks, which files written before version 3 kept
+// with each webhook, to the secrets section
+func moveWebhookSecrets(d map[string]interface{}) error {
+	moveSecrets(d, "webhooks", "Secret")
+	return nil
+}
+
+// moveSecrets moves the field of every record of section, by ID, to the same section of the secrets
+func moveSecrets(d map[string]interface{}, section, field string) {
+	records, _ := d[section].(map[string]interface{})
+	moved := make(map[string]interface{})
+	for id, value := range records {
+		record, _ := value.(map[string]interface{})
+		if secret, ok := record[field]; ok {
+			moved[id] = secret
+			delete(record, field)
+		}
+	}
+	if len(moved) > 0 {
+		secretsSection(d)[section] = moved
+	}
+}
+
+// secretsSection returns the secrets section of a decoded data file, adding it when missing
+func secretsSection(d map[string]interface{}) map[string]interface{} {
+	section, ok := d["secrets"].(map[string]interface{})
+	if !ok {
+		section = make(map[string]interface{})
+		d["secrets"] = section
+	}
+	return section
+}
@@ -0,0 +1,85 @@
+package file
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/siddontang/github-repos-management/internal/db"
+)
+
+// TestMigrate tests upgrading a file written before schema versioning
+func TestMigrate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	old := `{
+  "repositories": {"org/api": {"Owner": "org", "Name": "api", "FullName": "org/api"}},
+  "pull_requests": {"org/api": {"1": {"RepositoryFullName": "org/api", "Number": 1}, "7": {"RepositoryFullName": "org/api", "Number": 7}, "3": {"RepositoryFullName": "org/api", "Number": 3}}},
+  "repo_prs": {"org/api": [7, 1, 3]}
+}`
+	if err := os.WriteFile(path, []byte(old), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	d, err := NewDB(path)
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	if want := []int{1, 3, 7}; !reflect.DeepEqual(d.repoPRs["org/api"], want) {
+		t.Errorf("repoPRs = %v, want %v", d.repoPRs["org/api"], want)
+	}
+	if prs, _, _ := d.ListPullRequests(context.Background(), "org/api", 1, 10); len(prs) != 3 {
+		t.Errorf("ListPullRequests() = %d pull requests, want 3", len(prs))
+	}
+	d.Close()
+
+	// The upgraded file records its version, and the original is kept
+	file, _ := os.ReadFile(path)
+	var version struct {
+		SchemaVersion int `json:"schema_version"`
+	}
+	if err := json.Unmarshal(file, &version); err != nil || version.SchemaVersion != schemaVersion {
+		t.Errorf("schema_version = %d, %v, want %d", version.SchemaVersion, err, schemaVersion)
+	}
+	if backup, err := os.ReadFile(path + ".v0.bak"); err != nil || string(backup) != old {
+		t.Errorf("backup = %q, %v, want the original file", backup, err)
+	}
+}
+
+// TestMigrateSecrets tests moving the secrets a version 1 file kept with the models to their section
+func TestMigrateSecrets(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	old := `{"schema_version": 1,
+  "subscriptions": {"1": {"id": 1, "label": "bug", "url": "https://example.com", "secret": "s1gn"}},
+  "webhooks": {"1": {"ID": 1, "URL": "https://example.com/hook", "Secret": "h00k"}}
+}`
+	if err := os.WriteFile(path, []byte(old), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	d, err := NewDB(path)
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	defer d.Close()
+	if subs, _ := d.ListSubscriptions(context.Background()); len(subs) != 1 || subs[0].Secret != "s1gn" {
+		t.Errorf("subscriptions = %+v, want the secret kept", subs)
+	}
+	if hooks, _ := d.ListWebhooks(context.Background()); len(hooks) != 1 || hooks[0].Secret != "h00k" {
+		t.Errorf("webhooks = %+v, want the secret kept", hooks)
+	}
+}
+
+// TestNewerSchema tests that files written by a newer release are not opened
+func TestNewerSchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	if err := os.WriteFile(path, []byte(`{"schema_version": 99}`), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if _, err := NewDB(path); !errors.Is(err, db.ErrUnsupportedSchema) {
+		t.Errorf("NewDB() error = %v, want ErrUnsupportedSchema", err)
+	}
+}
@@ -0,0 +1,66 @@
+package file
+
+// secrets are the fields the models leave out of their JSON so that API responses never show
+// them, kept in a section of their own in the data file
+type secrets struct {
+	// Token hashes by workspace ID and token ID
+	WorkspaceTokens map[string]map[int64]string `json:"workspace_tokens,omitempty"`
+	// Subscription secrets by subscription ID
+	Subscriptions map[int64]string `json:"subscriptions,omitempty"`
+	// Webhook secrets by webhook ID
+	Webhooks map[int64]string `json:"webhooks,omitempty"`
+}
+
+// saveSecrets collects the secrets of the stored models; the caller must hold the lock
+func (db *DB) saveSecrets() *secrets {
+	s := &secrets{
+		WorkspaceTokens: make(map[string]map[int64]string),
+		Subscriptions:   make(map[int64]string),
+		Webhooks:        make(map[int64]string),
+	}
+	for id, workspace := range db.workspaces {
+		for _, token := range workspace.Tokens {
+			if s.WorkspaceTokens[id] == nil {
+				s.WorkspaceTokens[id] = make(map[int64]string)
+			}
+			s.WorkspaceTokens[id][token.ID] = token.Hash
+		}
+	}
+	for id, sub := range db.subscriptions {
+		if sub.Secret != "" {
+			s.Subscriptions[id] = sub.Secret
+		}
+	}
+	for id, hook := range db.webhooks {
+		if hook.Secret != "" {
+			s.Webhooks[id] = hook.Secret
+		}
+	}
+	return s
+}
+
+// loadSecrets sets the secrets of the loaded models
+func (db *DB) loadSecrets(s *secrets) {
+	if s == nil {
+		return
+	}
+	for id, hashes := range s.WorkspaceTokens {
+		workspace := db.workspaces[id]
+		if workspace == nil {
+			continue
+		}
+		for _, token := range workspace.Tokens {
+			token.Hash = hashes[token.ID]
+		}
+	}
+	for id, secret := range s.Subscriptions {
+		if sub := db.subscriptions[id]; sub != nil {
+			sub.Secret = secret
+		}
+	}
+	for id, secret := range s.Webhooks {
+		if hook := db.webhooks[id]; hook != nil {
+			hook.Secret = secret
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package file
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// TestSecrets tests that the secrets left out of the models' JSON survive reopening the file
+func TestSecrets(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "test.db")
+	d, err := NewDB(path)
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	workspace := &models.Workspace{ID: "team", Tokens: []*models.WorkspaceToken{{ID: 1, Name: "ci", Hash: "5e3a"}}}
+	if err := d.SaveWorkspace(ctx, workspace); err != nil {
+		t.Fatalf("SaveWorkspace() error = %v", err)
+	}
+	if err := d.AddSubscription(ctx, &models.Subscription{Label: "bug", URL: "https://example.com", Secret: "s1gn"}); err != nil {
+		t.Fatalf("AddSubscription() error = %v", err)
+	}
+	if err := d.AddWebhook(ctx, &models.Webhook{URL: "https://example.com/hook", Secret: "h00k"}); err != nil {
+		t.Fatalf("AddWebhook() error = %v", err)
+	}
+	d.Close()
+
+	if d, err = NewDB(path); err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	defer d.Close()
+	if got, _ := d.GetWorkspace(ctx, "team"); got == nil || got.Tokens[0].Hash != "5e3a" {
+		t.Errorf("workspace after reopening = %+v, want the token hash kept", got)
+	}
+	if subs, _ := d.ListSubscriptions(ctx); len(subs) != 1 || subs[0].Secret != "s1gn" {
+		t.Errorf("subscriptions after reopening = %+v, want the secret kept", subs)
+	}
+	if hooks, _ := d.ListWebhooks(ctx); len(hooks) != 1 || hooks[0].Secret != "h00k" {
+		t.Errorf("webhooks after reopening = %+v, want the secret kept", hooks)
+	}
+
+	// The secrets are only written to their own section
+	file, _ := os.ReadFile(path)
+	for _, secret := range []string{"5e3a", "s1gn", "h00k"} {
+		if strings.Count(string(file), secret) != 1 {
+			t.Errorf("data file = %s, want %s written once", file, secret)
+		}
+	}
+}
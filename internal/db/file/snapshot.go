@@ -0,0 +1,65 @@
+package file
+
+import (
+	"context"
+
+	storage "github.com/siddontang/github-repos-management/internal/db"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// Snapshot operations. A snapshot is an in-memory database holding copies of the maps of
+// repositories, pull requests, issues and labels, sharing the records themselves: writes replace
+// stored records rather than change them, so the records a snapshot holds never change. Taking one
+// holds the read lock only while the maps are copied; its indexes are built after the lock is
+// released, so writes don't wait for them. It is kept until the next write, so queries in between
+// share it.
+
+// dbSnapshot is a snapshot and the number of writes it is as of
+type dbSnapshot struct {
+	*DB
+	generation uint64
+}
+
+// Snapshot returns the repositories, pull requests, issues and labels as of the last write
+func (db *DB) Snapshot(ctx context.Context) (storage.Reader, error) {
+	if snap := db.snapshot.Load(); snap != nil && snap.generation == db.generation.Load() {
+		return snap.DB, nil
+	}
+
+	snap := db.copySnapshot()
+	snap.rebuildIndexes()
+
+	// A snapshot outdated by a write while its indexes were built is still returned, as it is
+	// consistent as of when it was copied, but not kept
+	if snap.generation == db.generation.Load() {
+		db.snapshot.Store(snap)
+	}
+	return snap.DB, nil
+}
+
+// copySnapshot copies the maps of a snapshot under the read lock, leaving its indexes to build
+func (db *DB) copySnapshot() *dbSnapshot {
+	db.RLock()
+	defer db.RUnlock()
+
+	// Writes wait for the read lock, so the copy is consistent
+	items := db.saveItems()
+	repositories := make(map[string]*models.Repository, len(db.repositories))
+	for fullName, repo := range db.repositories {
+		repositories[fullName] = repo
+	}
+	return &dbSnapshot{
+		DB: &DB{
+			repositories: repositories,
+			pullRequests: items.pullRequests,
+			issues:       items.issues,
+			labels:       items.labels,
+			repoPRs:      items.repoPRs,
+			repoIssues:   items.repoIssues,
+			repoLabels:   items.repoLabels,
+			prLabels:     items.prLabels,
+			issueLabels:  items.issueLabels,
+		},
+		generation: db.generation.Load(),
+	}
+}
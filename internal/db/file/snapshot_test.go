@@ -0,0 +1,150 @@
+package file
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// TestSnapshot tests that a snapshot keeps the data as of when it was taken and is shared until the next write
+func TestSnapshot(t *testing.T) {
+	ctx := context.Background()
+	db, err := NewDB("")
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	now := time.Now()
+	if err := db.AddPullRequest(ctx, &models.PullRequest{RepositoryFullName: "pingcap/tidb", Number: 1, State: "open", UpdatedAt: now}); err != nil {
+		t.Fatalf("AddPullRequest() error = %v", err)
+	}
+
+	snap, err := db.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Snapshot() error = %v", err)
+	}
+	if again, _ := db.Snapshot(ctx); again != snap {
+		t.Error("Snapshot() without a write in between should return the same snapshot")
+	}
+
+	if _, err := db.UpsertPullRequest(ctx, &models.PullRequest{RepositoryFullName: "pingcap/tidb", Number: 1, State: "closed", UpdatedAt: now.Add(time.Minute)}); err != nil {
+		t.Fatalf("UpsertPullRequest() error = %v", err)
+	}
+	if err := db.AddIssue(ctx, &models.Issue{RepositoryFullName: "pingcap/tidb", Number: 2, State: "open", UpdatedAt: now}); err != nil {
+		t.Fatalf("AddIssue() error = %v", err)
+	}
+
+	// The snapshot taken before the writes doesn't see them
+	if pr, _ := snap.GetPullRequest(ctx, "pingcap/tidb", 1); pr == nil || pr.State != "open" {
+		t.Errorf("snapshot pull request = %+v, want it still open", pr)
+	}
+	if open, _ := snap.FindPullRequests(ctx, &models.ItemQuery{Repositories: []string{"pingcap/tidb"}, State: "open"}); len(open) != 1 {
+		t.Errorf("snapshot open pull requests = %d, want 1", len(open))
+	}
+	if issues, _ := snap.ListAllIssues(ctx, "pingcap/tidb"); len(issues) != 0 {
+		t.Errorf("snapshot issues = %d, want none", len(issues))
+	}
+
+	// A new snapshot does
+	latest, err := db.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Snapshot() error = %v", err)
+	}
+	if latest == snap {
+		t.Fatal("Snapshot() after a write should take a new snapshot")
+	}
+	if open, _ := latest.FindPullRequests(ctx, &models.ItemQuery{Repositories: []string{"pingcap/tidb"}, State: "open"}); len(open) != 0 {
+		t.Errorf("latest open pull requests = %d, want none", len(open))
+	}
+	if issues, _ := latest.ListAllIssues(ctx, "pingcap/tidb"); len(issues) != 1 {
+		t.Errorf("latest issues = %d, want 1", len(issues))
+	}
+}
+
+// TestSnapshotIndexesOutsideLock tests that writes don't wait for the indexes of a snapshot being
+// built, and that a snapshot outdated by them is not kept
+func TestSnapshotIndexesOutsideLock(t *testing.T) {
+	ctx := context.Background()
+	db, err := NewDB("")
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	now := time.Now()
+	if err := db.AddPullRequest(ctx, &models.PullRequest{RepositoryFullName: "pingcap/tidb", Number: 1, State: "open", UpdatedAt: now}); err != nil {
+		t.Fatalf("AddPullRequest() error = %v", err)
+	}
+
+	// Copied but not indexed yet, as in a Snapshot still building its indexes
+	snap := db.copySnapshot()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := db.UpsertPullRequest(ctx, &models.PullRequest{RepositoryFullName: "pingcap/tidb", Number: 1, State: "closed", UpdatedAt: now.Add(time.Minute)})
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("UpsertPullRequest() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("UpsertPullRequest() waited for the snapshot being built")
+	}
+
+	snap.rebuildIndexes()
+	if open, _ := snap.FindPullRequests(ctx, &models.ItemQuery{Repositories: []string{"pingcap/tidb"}, State: "open"}); len(open) != 1 {
+		t.Errorf("snapshot open pull requests = %d, want 1", len(open))
+	}
+	if snap.generation == db.generation.Load() {
+		t.Error("snapshot copied before a write should be outdated by it")
+	}
+	latest, err := db.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Snapshot() error = %v", err)
+	}
+	if open, _ := latest.FindPullRequests(ctx, &models.ItemQuery{Repositories: []string{"pingcap/tidb"}, State: "open"}); len(open) != 0 {
+		t.Errorf("latest open pull requests = %d, want none", len(open))
+	}
+}
+
+// BenchmarkWriteDuringSnapshot measures writes while snapshots of many pull requests are taken
+// over and over, which only wait for the maps to be copied
+func BenchmarkWriteDuringSnapshot(b *testing.B) {
+	ctx := context.Background()
+	db, err := NewDB("")
+	if err != nil {
+		b.Fatalf("NewDB() error = %v", err)
+	}
+	now := time.Now()
+	for number := 1; number <= 20000; number++ {
+		if err := db.AddPullRequest(ctx, &models.PullRequest{RepositoryFullName: "pingcap/tidb", Number: number, State: "open", UpdatedAt: now}); err != nil {
+			b.Fatalf("AddPullRequest() error = %v", err)
+		}
+	}
+
+	stop := make(chan struct{})
+	snapshots := make(chan struct{})
+	go func() {
+		defer close(snapshots)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				db.Snapshot(ctx)
+			}
+		}
+	}()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pr := &models.PullRequest{RepositoryFullName: "pingcap/tidb", Number: i%20000 + 1, State: "closed", UpdatedAt: now.Add(time.Duration(i) * time.Second)}
+		if _, err := db.UpsertPullRequest(ctx, pr); err != nil {
+			b.Fatalf("UpsertPullRequest() error = %v", err)
+		}
+	}
+	b.StopTimer()
+	close(stop)
+	<-snapshots
+}
@@ -0,0 +1,53 @@
+package file
+
+import (
+	"context"
+	"sort"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// Subscription operations
+
+// AddSubscription adds a label subscription to the database and assigns its ID
+func (db *DB) AddSubscription(ctx context.Context, sub *models.Subscription) error {
+	db.Lock()
+	defer db.Unlock()
+
+	db.nextSubscriptionID++
+	sub.ID = db.nextSubscriptionID
+	clone := *sub
+	db.subscriptions[sub.ID] = &clone
+
+	return db.sync()
+}
+
+// ListSubscriptions lists copies of all label subscriptions ordered by ID
+func (db *DB) ListSubscriptions(ctx context.Context) ([]*models.Subscription, error) {
+	db.RLock()
+	defer db.RUnlock()
+
+	subs := make([]*models.Subscription, 0, len(db.subscriptions))
+	for _, sub := range db.subscriptions {
+		clone := *sub
+		subs = append(subs, &clone)
+	}
+	sort.Slice(subs, func(i, j int) bool {
+		return subs[i].ID < subs[j].ID
+	})
+
+	return subs, nil
+}
+
+// DeleteSubscription deletes a label subscription from the database
+func (db *DB) DeleteSubscription(ctx context.Context, id int64) error {
+	db.Lock()
+	defer db.Unlock()
+
+	if _, ok := db.subscriptions[id]; !ok {
+		return db.ErrSubscriptionNotFound(id)
+	}
+	delete(db.subscriptions, id)
+
+	return db.sync()
+}
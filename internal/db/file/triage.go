@@ -0,0 +1,87 @@
+package file
+
+import (
+	"context"
+	"sort"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// Triage rule operations
+
+// AddTriageRule adds a triage rule to the database and assigns its ID
+func (db *DB) AddTriageRule(ctx context.Context, rule *models.TriageRule) error {
+	db.Lock()
+	defer db.Unlock()
+
+	db.nextTriageRuleID++
+	rule.ID = db.nextTriageRuleID
+	clone := *rule
+	db.triageRules[rule.ID] = &clone
+
+	return db.sync()
+}
+
+// ListTriageRules lists copies of all triage rules ordered by ID
+func (db *DB) ListTriageRules(ctx context.Context) ([]*models.TriageRule, error) {
+	db.RLock()
+	defer db.RUnlock()
+
+	rules := make([]*models.TriageRule, 0, len(db.triageRules))
+	for _, rule := range db.triageRules {
+		clone := *rule
+		rules = append(rules, &clone)
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].ID < rules[j].ID
+	})
+
+	return rules, nil
+}
+
+// DeleteTriageRule deletes a triage rule and its execution log from the database
+func (db *DB) DeleteTriageRule(ctx context.Context, id int64) error {
+	db.Lock()
+	defer db.Unlock()
+
+	if _, ok := db.triageRules[id]; !ok {
+		return db.ErrTriageRuleNotFound(id)
+	}
+	delete(db.triageRules, id)
+	delete(db.triageExecutions, id)
+
+	return db.sync()
+}
+
+// AddTriageExecution appends to the execution log of a triage rule. The whole log is kept, as it
+// is what stops a rule from acting on an item twice.
+func (db *DB) AddTriageExecution(ctx context.Context, execution *models.TriageExecution) error {
+	db.Lock()
+	defer db.Unlock()
+
+	if _, ok := db.triageRules[execution.RuleID]; !ok {
+		return db.ErrTriageRuleNotFound(execution.RuleID)
+	}
+	clone := *execution
+	db.triageExecutions[execution.RuleID] = append(db.triageExecutions[execution.RuleID], &clone)
+
+	return db.sync()
+}
+
+// ListTriageExecutions lists copies of the execution log of a triage rule, newest first
+func (db *DB) ListTriageExecutions(ctx context.Context, ruleID int64) ([]*models.TriageExecution, error) {
+	db.RLock()
+	defer db.RUnlock()
+
+	if _, ok := db.triageRules[ruleID]; !ok {
+		return nil, db.ErrTriageRuleNotFound(ruleID)
+	}
+	stored := db.triageExecutions[ruleID]
+	executions := make([]*models.TriageExecution, 0, len(stored))
+	for i := len(stored) - 1; i >= 0; i-- {
+		clone := *stored[i]
+		executions = append(executions, &clone)
+	}
+
+	return executions, nil
+}
@@ -0,0 +1,107 @@
+package file
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	storage "github.com/siddontang/github-repos-management/internal/db"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// Workspace operations. Workspaces are stored and returned as copies.
+
+// SaveWorkspace creates or replaces a workspace
+func (db *DB) SaveWorkspace(ctx context.Context, workspace *models.Workspace) error {
+	db.Lock()
+	defer db.Unlock()
+
+	db.workspaces[workspace.ID] = cloneWorkspace(workspace)
+	return db.sync()
+}
+
+// UpdateWorkspace applies mutate to a copy of a stored workspace under the write lock, storing
+// the copy when mutate reports a change, and returns the workspace as stored
+func (db *DB) UpdateWorkspace(ctx context.Context, id string, mutate func(workspace *models.Workspace) bool) (*models.Workspace, error) {
+	db.Lock()
+	defer db.Unlock()
+
+	stored, ok := db.workspaces[id]
+	if !ok {
+		return nil, db.ErrWorkspaceNotFound(id)
+	}
+	workspace := cloneWorkspace(stored)
+	if !mutate(workspace) {
+		return cloneWorkspace(stored), nil
+	}
+	db.workspaces[id] = cloneWorkspace(workspace)
+	return workspace, db.sync()
+}
+
+// GetWorkspace gets a workspace from the database
+func (db *DB) GetWorkspace(ctx context.Context, id string) (*models.Workspace, error) {
+	db.RLock()
+	defer db.RUnlock()
+
+	workspace, ok := db.workspaces[id]
+	if !ok {
+		return nil, db.ErrWorkspaceNotFound(id)
+	}
+	return cloneWorkspace(workspace), nil
+}
+
+// ListWorkspaces lists all workspaces ordered by ID
+func (db *DB) ListWorkspaces(ctx context.Context) ([]*models.Workspace, error) {
+	db.RLock()
+	defer db.RUnlock()
+
+	workspaces := make([]*models.Workspace, 0, len(db.workspaces))
+	for _, workspace := range db.workspaces {
+		workspaces = append(workspaces, cloneWorkspace(workspace))
+	}
+	sort.Slice(workspaces, func(i, j int) bool {
+		return workspaces[i].ID < workspaces[j].ID
+	})
+	return workspaces, nil
+}
+
+// DeleteWorkspace deletes a workspace; its repositories stay tracked
+func (db *DB) DeleteWorkspace(ctx context.Context, id string) error {
+	db.Lock()
+	defer db.Unlock()
+
+	if _, ok := db.workspaces[id]; !ok {
+		return db.ErrWorkspaceNotFound(id)
+	}
+	delete(db.workspaces, id)
+	return db.sync()
+}
+
+// removeWorkspaceRepository drops a repository from every workspace including it
+func (db *DB) removeWorkspaceRepository(fullName string) {
+	for _, workspace := range db.workspaces {
+		kept := workspace.Repositories[:0]
+		for _, name := range workspace.Repositories {
+			if name != fullName {
+				kept = append(kept, name)
+			}
+		}
+		workspace.Repositories = kept
+	}
+}
+
+// cloneWorkspace copies a workspace so callers can't modify the stored one
+func cloneWorkspace(workspace *models.Workspace) *models.Workspace {
+	clone := *workspace
+	clone.Repositories = append([]string(nil), workspace.Repositories...)
+	clone.Tokens = make([]*models.WorkspaceToken, 0, len(workspace.Tokens))
+	for _, token := range workspace.Tokens {
+		copied := *token
+		clone.Tokens = append(clone.Tokens, &copied)
+	}
+	return &clone
+}
+
+func (db *DB) ErrWorkspaceNotFound(id string) error {
+	return fmt.Errorf("workspace %s %w", id, storage.ErrNotFound)
+}
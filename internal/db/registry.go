@@ -0,0 +1,55 @@
+package db
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+)
+
+var (
+	providersMu sync.RWMutex
+	providers   = make(map[string]Provider)
+)
+
+// Register makes a storage backend available under a database type name.
+// Backends call it from an init function; registering a name twice panics.
+func Register(name string, provider Provider) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+
+	if provider == nil {
+		panic("db: Register provider is nil")
+	}
+	if _, dup := providers[name]; dup {
+		panic("db: Register called twice for provider " + name)
+	}
+	providers[name] = provider
+}
+
+// Providers returns the names of the registered storage backends, sorted
+func Providers() []string {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Open creates the database of the configured type
+func Open(cfg *config.Config) (DB, error) {
+	providersMu.RLock()
+	provider, ok := providers[cfg.Database.Type]
+	providersMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unsupported database type %q (available: %s)", cfg.Database.Type, strings.Join(Providers(), ", "))
+	}
+	return provider(cfg)
+}
@@ -0,0 +1,39 @@
+package db_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/db"
+	_ "github.com/siddontang/github-repos-management/internal/db/file"
+)
+
+func TestOpenRegisteredProvider(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Database.Type = config.DBTypeMemory
+
+	store, err := db.Open(cfg)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer store.Close()
+
+	if err := store.Ping(context.Background()); err != nil {
+		t.Errorf("Ping() error = %v", err)
+	}
+}
+
+func TestOpenUnknownProvider(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Database.Type = "redis"
+
+	_, err := db.Open(cfg)
+	if err == nil {
+		t.Fatal("Open() succeeded for an unregistered database type")
+	}
+	if !strings.Contains(err.Error(), "file, memory") {
+		t.Errorf("Open() error = %v, want the available types listed", err)
+	}
+}
@@ -0,0 +1,733 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+	"github.com/siddontang/github-repos-management/internal/tracing"
+)
+
+// tracedDB records a span named db.<Operation> for each operation made within a trace
+type tracedDB struct {
+	DB
+}
+
+// Traced wraps a database so that its operations are traced as children of the current span
+func Traced(db DB) DB {
+	return &tracedDB{DB: db}
+}
+
+// The operations below wrap those of DB; Close, Sync and the error constructors pass through.
+
+func (d *tracedDB) AddRepository(ctx context.Context, repo *models.Repository) error {
+	ctx, span := tracing.Start(ctx, "db.AddRepository")
+	defer span.End()
+	err := d.DB.AddRepository(ctx, repo)
+	tracing.RecordError(span, err)
+	return err
+}
+
+func (d *tracedDB) GetRepository(ctx context.Context, owner, name string) (*models.Repository, error) {
+	ctx, span := tracing.Start(ctx, "db.GetRepository")
+	defer span.End()
+	result, err := d.DB.GetRepository(ctx, owner, name)
+	tracing.RecordError(span, err)
+	return result, err
+}
+
+func (d *tracedDB) ListRepositories(ctx context.Context, page, perPage int) ([]*models.Repository, int, error) {
+	ctx, span := tracing.Start(ctx, "db.ListRepositories")
+	defer span.End()
+	result, total, err := d.DB.ListRepositories(ctx, page, perPage)
+	tracing.RecordError(span, err)
+	return result, total, err
+}
+
+func (d *tracedDB) ListAllRepositories(ctx context.Context) ([]*models.Repository, error) {
+	ctx, span := tracing.Start(ctx, "db.ListAllRepositories")
+	defer span.End()
+	result, err := d.DB.ListAllRepositories(ctx)
+	tracing.RecordError(span, err)
+	return result, err
+}
+
+func (d *tracedDB) UpdateRepository(ctx context.Context, repo *models.Repository) error {
+	ctx, span := tracing.Start(ctx, "db.UpdateRepository")
+	defer span.End()
+	err := d.DB.UpdateRepository(ctx, repo)
+	tracing.RecordError(span, err)
+	return err
+}
+
+func (d *tracedDB) UpsertRepository(ctx context.Context, repo *models.Repository) (bool, error) {
+	ctx, span := tracing.Start(ctx, "db.UpsertRepository")
+	defer span.End()
+	created, err := d.DB.UpsertRepository(ctx, repo)
+	tracing.RecordError(span, err)
+	return created, err
+}
+
+func (d *tracedDB) DeleteRepository(ctx context.Context, owner, name string) error {
+	ctx, span := tracing.Start(ctx, "db.DeleteRepository")
+	defer span.End()
+	err := d.DB.DeleteRepository(ctx, owner, name)
+	tracing.RecordError(span, err)
+	return err
+}
+
+func (d *tracedDB) AddPullRequest(ctx context.Context, pr *models.PullRequest) error {
+	ctx, span := tracing.Start(ctx, "db.AddPullRequest")
+	defer span.End()
+	err := d.DB.AddPullRequest(ctx, pr)
+	tracing.RecordError(span, err)
+	return err
+}
+
+func (d *tracedDB) GetPullRequest(ctx context.Context, repoFullName string, number int) (*models.PullRequest, error) {
+	ctx, span := tracing.Start(ctx, "db.GetPullRequest")
+	defer span.End()
+	result, err := d.DB.GetPullRequest(ctx, repoFullName, number)
+	tracing.RecordError(span, err)
+	return result, err
+}
+
+func (d *tracedDB) ListPullRequests(ctx context.Context, repoFullName string, page, perPage int) ([]*models.PullRequest, int, error) {
+	ctx, span := tracing.Start(ctx, "db.ListPullRequests")
+	defer span.End()
+	result, total, err := d.DB.ListPullRequests(ctx, repoFullName, page, perPage)
+	tracing.RecordError(span, err)
+	return result, total, err
+}
+
+func (d *tracedDB) ListAllPullRequests(ctx context.Context, repoFullName string) ([]*models.PullRequest, error) {
+	ctx, span := tracing.Start(ctx, "db.ListAllPullRequests")
+	defer span.End()
+	result, err := d.DB.ListAllPullRequests(ctx, repoFullName)
+	tracing.RecordError(span, err)
+	return result, err
+}
+
+func (d *tracedDB) UpdatePullRequest(ctx context.Context, pr *models.PullRequest) error {
+	ctx, span := tracing.Start(ctx, "db.UpdatePullRequest")
+	defer span.End()
+	err := d.DB.UpdatePullRequest(ctx, pr)
+	tracing.RecordError(span, err)
+	return err
+}
+
+func (d *tracedDB) UpsertPullRequest(ctx context.Context, pr *models.PullRequest) (bool, error) {
+	ctx, span := tracing.Start(ctx, "db.UpsertPullRequest")
+	defer span.End()
+	created, err := d.DB.UpsertPullRequest(ctx, pr)
+	tracing.RecordError(span, err)
+	return created, err
+}
+
+func (d *tracedDB) DeletePullRequest(ctx context.Context, repoFullName string, number int) error {
+	ctx, span := tracing.Start(ctx, "db.DeletePullRequest")
+	defer span.End()
+	err := d.DB.DeletePullRequest(ctx, repoFullName, number)
+	tracing.RecordError(span, err)
+	return err
+}
+
+func (d *tracedDB) FindPullRequests(ctx context.Context, query *models.ItemQuery) ([]*models.PullRequest, error) {
+	ctx, span := tracing.Start(ctx, "db.FindPullRequests")
+	defer span.End()
+	result, err := d.DB.FindPullRequests(ctx, query)
+	tracing.RecordError(span, err)
+	return result, err
+}
+
+func (d *tracedDB) AddIssue(ctx context.Context, issue *models.Issue) error {
+	ctx, span := tracing.Start(ctx, "db.AddIssue")
+	defer span.End()
+	err := d.DB.AddIssue(ctx, issue)
+	tracing.RecordError(span, err)
+	return err
+}
+
+func (d *tracedDB) GetIssue(ctx context.Context, repoFullName string, number int) (*models.Issue, error) {
+	ctx, span := tracing.Start(ctx, "db.GetIssue")
+	defer span.End()
+	result, err := d.DB.GetIssue(ctx, repoFullName, number)
+	tracing.RecordError(span, err)
+	return result, err
+}
+
+func (d *tracedDB) ListIssues(ctx context.Context, repoFullName string, page, perPage int) ([]*models.Issue, int, error) {
+	ctx, span := tracing.Start(ctx, "db.ListIssues")
+	defer span.End()
+	result, total, err := d.DB.ListIssues(ctx, repoFullName, page, perPage)
+	tracing.RecordError(span, err)
+	return result, total, err
+}
+
+func (d *tracedDB) ListAllIssues(ctx context.Context, repoFullName string) ([]*models.Issue, error) {
+	ctx, span := tracing.Start(ctx, "db.ListAllIssues")
+	defer span.End()
+	result, err := d.DB.ListAllIssues(ctx, repoFullName)
+	tracing.RecordError(span, err)
+	return result, err
+}
+
+func (d *tracedDB) UpdateIssue(ctx context.Context, issue *models.Issue) error {
+	ctx, span := tracing.Start(ctx, "db.UpdateIssue")
+	defer span.End()
+	err := d.DB.UpdateIssue(ctx, issue)
+	tracing.RecordError(span, err)
+	return err
+}
+
+func (d *tracedDB) UpsertIssue(ctx context.Context, issue *models.Issue) (bool, error) {
+	ctx, span := tracing.Start(ctx, "db.UpsertIssue")
+	defer span.End()
+	created, err := d.DB.UpsertIssue(ctx, issue)
+	tracing.RecordError(span, err)
+	return created, err
+}
+
+func (d *tracedDB) Snapshot(ctx context.Context) (Reader, error) {
+	ctx, span := tracing.Start(ctx, "db.Snapshot")
+	defer span.End()
+	result, err := d.DB.Snapshot(ctx)
+	tracing.RecordError(span, err)
+	return result, err
+}
+
+func (d *tracedDB) Batch(ctx context.Context, fn func(tx Tx) error) error {
+	ctx, span := tracing.Start(ctx, "db.Batch")
+	defer span.End()
+	err := d.DB.Batch(ctx, fn)
+	tracing.RecordError(span, err)
+	return err
+}
+
+func (d *tracedDB) DeleteIssue(ctx context.Context, repoFullName string, number int) error {
+	ctx, span := tracing.Start(ctx, "db.DeleteIssue")
+	defer span.End()
+	err := d.DB.DeleteIssue(ctx, repoFullName, number)
+	tracing.RecordError(span, err)
+	return err
+}
+
+func (d *tracedDB) FindIssues(ctx context.Context, query *models.ItemQuery) ([]*models.Issue, error) {
+	ctx, span := tracing.Start(ctx, "db.FindIssues")
+	defer span.End()
+	result, err := d.DB.FindIssues(ctx, query)
+	tracing.RecordError(span, err)
+	return result, err
+}
+
+func (d *tracedDB) AddLabel(ctx context.Context, label *models.Label) error {
+	ctx, span := tracing.Start(ctx, "db.AddLabel")
+	defer span.End()
+	err := d.DB.AddLabel(ctx, label)
+	tracing.RecordError(span, err)
+	return err
+}
+
+func (d *tracedDB) GetLabel(ctx context.Context, name string) (*models.Label, error) {
+	ctx, span := tracing.Start(ctx, "db.GetLabel")
+	defer span.End()
+	result, err := d.DB.GetLabel(ctx, name)
+	tracing.RecordError(span, err)
+	return result, err
+}
+
+func (d *tracedDB) ListLabels(ctx context.Context, page, perPage int) ([]*models.Label, int, error) {
+	ctx, span := tracing.Start(ctx, "db.ListLabels")
+	defer span.End()
+	result, total, err := d.DB.ListLabels(ctx, page, perPage)
+	tracing.RecordError(span, err)
+	return result, total, err
+}
+
+func (d *tracedDB) UpdateLabel(ctx context.Context, label *models.Label) error {
+	ctx, span := tracing.Start(ctx, "db.UpdateLabel")
+	defer span.End()
+	err := d.DB.UpdateLabel(ctx, label)
+	tracing.RecordError(span, err)
+	return err
+}
+
+func (d *tracedDB) DeleteLabel(ctx context.Context, name string) error {
+	ctx, span := tracing.Start(ctx, "db.DeleteLabel")
+	defer span.End()
+	err := d.DB.DeleteLabel(ctx, name)
+	tracing.RecordError(span, err)
+	return err
+}
+
+func (d *tracedDB) AddPullRequestLabel(ctx context.Context, repoFullName string, prNumber int, labelName string) error {
+	ctx, span := tracing.Start(ctx, "db.AddPullRequestLabel")
+	defer span.End()
+	err := d.DB.AddPullRequestLabel(ctx, repoFullName, prNumber, labelName)
+	tracing.RecordError(span, err)
+	return err
+}
+
+func (d *tracedDB) ListPullRequestLabels(ctx context.Context, repoFullName string, prNumber int) ([]*models.Label, error) {
+	ctx, span := tracing.Start(ctx, "db.ListPullRequestLabels")
+	defer span.End()
+	result, err := d.DB.ListPullRequestLabels(ctx, repoFullName, prNumber)
+	tracing.RecordError(span, err)
+	return result, err
+}
+
+func (d *tracedDB) RemovePullRequestLabel(ctx context.Context, repoFullName string, prNumber int, labelName string) error {
+	ctx, span := tracing.Start(ctx, "db.RemovePullRequestLabel")
+	defer span.End()
+	err := d.DB.RemovePullRequestLabel(ctx, repoFullName, prNumber, labelName)
+	tracing.RecordError(span, err)
+	return err
+}
+
+func (d *tracedDB) AddIssueLabel(ctx context.Context, repoFullName string, issueNumber int, labelName string) error {
+	ctx, span := tracing.Start(ctx, "db.AddIssueLabel")
+	defer span.End()
+	err := d.DB.AddIssueLabel(ctx, repoFullName, issueNumber, labelName)
+	tracing.RecordError(span, err)
+	return err
+}
+
+func (d *tracedDB) ListIssueLabels(ctx context.Context, repoFullName string, issueNumber int) ([]*models.Label, error) {
+	ctx, span := tracing.Start(ctx, "db.ListIssueLabels")
+	defer span.End()
+	result, err := d.DB.ListIssueLabels(ctx, repoFullName, issueNumber)
+	tracing.RecordError(span, err)
+	return result, err
+}
+
+func (d *tracedDB) RemoveIssueLabel(ctx context.Context, repoFullName string, issueNumber int, labelName string) error {
+	ctx, span := tracing.Start(ctx, "db.RemoveIssueLabel")
+	defer span.End()
+	err := d.DB.RemoveIssueLabel(ctx, repoFullName, issueNumber, labelName)
+	tracing.RecordError(span, err)
+	return err
+}
+
+func (d *tracedDB) AddWebhook(ctx context.Context, hook *models.Webhook) error {
+	ctx, span := tracing.Start(ctx, "db.AddWebhook")
+	defer span.End()
+	err := d.DB.AddWebhook(ctx, hook)
+	tracing.RecordError(span, err)
+	return err
+}
+
+func (d *tracedDB) GetWebhook(ctx context.Context, id int64) (*models.Webhook, error) {
+	ctx, span := tracing.Start(ctx, "db.GetWebhook")
+	defer span.End()
+	result, err := d.DB.GetWebhook(ctx, id)
+	tracing.RecordError(span, err)
+	return result, err
+}
+
+func (d *tracedDB) ListWebhooks(ctx context.Context) ([]*models.Webhook, error) {
+	ctx, span := tracing.Start(ctx, "db.ListWebhooks")
+	defer span.End()
+	result, err := d.DB.ListWebhooks(ctx)
+	tracing.RecordError(span, err)
+	return result, err
+}
+
+func (d *tracedDB) DeleteWebhook(ctx context.Context, id int64) error {
+	ctx, span := tracing.Start(ctx, "db.DeleteWebhook")
+	defer span.End()
+	err := d.DB.DeleteWebhook(ctx, id)
+	tracing.RecordError(span, err)
+	return err
+}
+
+func (d *tracedDB) AddWebhookDeliveries(ctx context.Context, deliveries []*models.WebhookDelivery) error {
+	ctx, span := tracing.Start(ctx, "db.AddWebhookDeliveries")
+	defer span.End()
+	err := d.DB.AddWebhookDeliveries(ctx, deliveries)
+	tracing.RecordError(span, err)
+	return err
+}
+
+func (d *tracedDB) ListWebhookDeliveries(ctx context.Context, hookID int64, page, perPage int) ([]*models.WebhookDelivery, int, error) {
+	ctx, span := tracing.Start(ctx, "db.ListWebhookDeliveries")
+	defer span.End()
+	result, total, err := d.DB.ListWebhookDeliveries(ctx, hookID, page, perPage)
+	tracing.RecordError(span, err)
+	return result, total, err
+}
+
+func (d *tracedDB) AddSubscription(ctx context.Context, sub *models.Subscription) error {
+	ctx, span := tracing.Start(ctx, "db.AddSubscription")
+	defer span.End()
+	err := d.DB.AddSubscription(ctx, sub)
+	tracing.RecordError(span, err)
+	return err
+}
+
+func (d *tracedDB) ListSubscriptions(ctx context.Context) ([]*models.Subscription, error) {
+	ctx, span := tracing.Start(ctx, "db.ListSubscriptions")
+	defer span.End()
+	result, err := d.DB.ListSubscriptions(ctx)
+	tracing.RecordError(span, err)
+	return result, err
+}
+
+func (d *tracedDB) DeleteSubscription(ctx context.Context, id int64) error {
+	ctx, span := tracing.Start(ctx, "db.DeleteSubscription")
+	defer span.End()
+	err := d.DB.DeleteSubscription(ctx, id)
+	tracing.RecordError(span, err)
+	return err
+}
+
+func (d *tracedDB) AddTriageRule(ctx context.Context, rule *models.TriageRule) error {
+	ctx, span := tracing.Start(ctx, "db.AddTriageRule")
+	defer span.End()
+	err := d.DB.AddTriageRule(ctx, rule)
+	tracing.RecordError(span, err)
+	return err
+}
+
+func (d *tracedDB) ListTriageRules(ctx context.Context) ([]*models.TriageRule, error) {
+	ctx, span := tracing.Start(ctx, "db.ListTriageRules")
+	defer span.End()
+	result, err := d.DB.ListTriageRules(ctx)
+	tracing.RecordError(span, err)
+	return result, err
+}
+
+func (d *tracedDB) DeleteTriageRule(ctx context.Context, id int64) error {
+	ctx, span := tracing.Start(ctx, "db.DeleteTriageRule")
+	defer span.End()
+	err := d.DB.DeleteTriageRule(ctx, id)
+	tracing.RecordError(span, err)
+	return err
+}
+
+func (d *tracedDB) AddTriageExecution(ctx context.Context, execution *models.TriageExecution) error {
+	ctx, span := tracing.Start(ctx, "db.AddTriageExecution")
+	defer span.End()
+	err := d.DB.AddTriageExecution(ctx, execution)
+	tracing.RecordError(span, err)
+	return err
+}
+
+func (d *tracedDB) ListTriageExecutions(ctx context.Context, ruleID int64) ([]*models.TriageExecution, error) {
+	ctx, span := tracing.Start(ctx, "db.ListTriageExecutions")
+	defer span.End()
+	result, err := d.DB.ListTriageExecutions(ctx, ruleID)
+	tracing.RecordError(span, err)
+	return result, err
+}
+
+func (d *tracedDB) SaveWorkspace(ctx context.Context, workspace *models.Workspace) error {
+	ctx, span := tracing.Start(ctx, "db.SaveWorkspace")
+	defer span.End()
+	err := d.DB.SaveWorkspace(ctx, workspace)
+	tracing.RecordError(span, err)
+	return err
+}
+
+func (d *tracedDB) UpdateWorkspace(ctx context.Context, id string, mutate func(workspace *models.Workspace) bool) (*models.Workspace, error) {
+	ctx, span := tracing.Start(ctx, "db.UpdateWorkspace")
+	defer span.End()
+	result, err := d.DB.UpdateWorkspace(ctx, id, mutate)
+	tracing.RecordError(span, err)
+	return result, err
+}
+
+func (d *tracedDB) GetWorkspace(ctx context.Context, id string) (*models.Workspace, error) {
+	ctx, span := tracing.Start(ctx, "db.GetWorkspace")
+	defer span.End()
+	result, err := d.DB.GetWorkspace(ctx, id)
+	tracing.RecordError(span, err)
+	return result, err
+}
+
+func (d *tracedDB) ListWorkspaces(ctx context.Context) ([]*models.Workspace, error) {
+	ctx, span := tracing.Start(ctx, "db.ListWorkspaces")
+	defer span.End()
+	result, err := d.DB.ListWorkspaces(ctx)
+	tracing.RecordError(span, err)
+	return result, err
+}
+
+func (d *tracedDB) DeleteWorkspace(ctx context.Context, id string) error {
+	ctx, span := tracing.Start(ctx, "db.DeleteWorkspace")
+	defer span.End()
+	err := d.DB.DeleteWorkspace(ctx, id)
+	tracing.RecordError(span, err)
+	return err
+}
+
+func (d *tracedDB) AppendActivity(ctx context.Context, event *models.ActivityEvent) error {
+	ctx, span := tracing.Start(ctx, "db.AppendActivity")
+	defer span.End()
+	err := d.DB.AppendActivity(ctx, event)
+	tracing.RecordError(span, err)
+	return err
+}
+
+func (d *tracedDB) ListActivity(ctx context.Context, filter *models.ActivityFilter) ([]*models.ActivityEvent, int, error) {
+	ctx, span := tracing.Start(ctx, "db.ListActivity")
+	defer span.End()
+	result, total, err := d.DB.ListActivity(ctx, filter)
+	tracing.RecordError(span, err)
+	return result, total, err
+}
+
+func (d *tracedDB) ListActivitySince(ctx context.Context, afterID int64, limit int) ([]*models.ActivityEvent, error) {
+	ctx, span := tracing.Start(ctx, "db.ListActivitySince")
+	defer span.End()
+	result, err := d.DB.ListActivitySince(ctx, afterID, limit)
+	tracing.RecordError(span, err)
+	return result, err
+}
+
+func (d *tracedDB) AppendAudit(ctx context.Context, entry *models.AuditEntry) error {
+	ctx, span := tracing.Start(ctx, "db.AppendAudit")
+	defer span.End()
+	err := d.DB.AppendAudit(ctx, entry)
+	tracing.RecordError(span, err)
+	return err
+}
+
+func (d *tracedDB) ListAudit(ctx context.Context, filter *models.AuditFilter) ([]*models.AuditEntry, int, error) {
+	ctx, span := tracing.Start(ctx, "db.ListAudit")
+	defer span.End()
+	result, total, err := d.DB.ListAudit(ctx, filter)
+	tracing.RecordError(span, err)
+	return result, total, err
+}
+
+func (d *tracedDB) AddJob(ctx context.Context, job *models.Job) error {
+	ctx, span := tracing.Start(ctx, "db.AddJob")
+	defer span.End()
+	err := d.DB.AddJob(ctx, job)
+	tracing.RecordError(span, err)
+	return err
+}
+
+func (d *tracedDB) UpdateJob(ctx context.Context, job *models.Job) error {
+	ctx, span := tracing.Start(ctx, "db.UpdateJob")
+	defer span.End()
+	err := d.DB.UpdateJob(ctx, job)
+	tracing.RecordError(span, err)
+	return err
+}
+
+func (d *tracedDB) GetJob(ctx context.Context, id int64) (*models.Job, error) {
+	ctx, span := tracing.Start(ctx, "db.GetJob")
+	defer span.End()
+	result, err := d.DB.GetJob(ctx, id)
+	tracing.RecordError(span, err)
+	return result, err
+}
+
+func (d *tracedDB) ListJobs(ctx context.Context, filter *models.JobFilter) ([]*models.Job, int, error) {
+	ctx, span := tracing.Start(ctx, "db.ListJobs")
+	defer span.End()
+	result, total, err := d.DB.ListJobs(ctx, filter)
+	tracing.RecordError(span, err)
+	return result, total, err
+}
+
+func (d *tracedDB) AddRepositorySnapshot(ctx context.Context, snapshot *models.RepositorySnapshot) error {
+	ctx, span := tracing.Start(ctx, "db.AddRepositorySnapshot")
+	defer span.End()
+	err := d.DB.AddRepositorySnapshot(ctx, snapshot)
+	tracing.RecordError(span, err)
+	return err
+}
+
+func (d *tracedDB) ListRepositorySnapshots(ctx context.Context, repoFullName string, since time.Time) ([]*models.RepositorySnapshot, error) {
+	ctx, span := tracing.Start(ctx, "db.ListRepositorySnapshots")
+	defer span.End()
+	result, err := d.DB.ListRepositorySnapshots(ctx, repoFullName, since)
+	tracing.RecordError(span, err)
+	return result, err
+}
+
+func (d *tracedDB) ReplaceMilestones(ctx context.Context, repoFullName string, milestones []*models.Milestone) error {
+	ctx, span := tracing.Start(ctx, "db.ReplaceMilestones")
+	defer span.End()
+	err := d.DB.ReplaceMilestones(ctx, repoFullName, milestones)
+	tracing.RecordError(span, err)
+	return err
+}
+
+func (d *tracedDB) ListMilestones(ctx context.Context, repoFullName string) ([]*models.Milestone, error) {
+	ctx, span := tracing.Start(ctx, "db.ListMilestones")
+	defer span.End()
+	result, err := d.DB.ListMilestones(ctx, repoFullName)
+	tracing.RecordError(span, err)
+	return result, err
+}
+
+func (d *tracedDB) ReplaceReleases(ctx context.Context, repoFullName string, releases []*models.Release) error {
+	ctx, span := tracing.Start(ctx, "db.ReplaceReleases")
+	defer span.End()
+	err := d.DB.ReplaceReleases(ctx, repoFullName, releases)
+	tracing.RecordError(span, err)
+	return err
+}
+
+func (d *tracedDB) ListReleases(ctx context.Context, repoFullName string) ([]*models.Release, error) {
+	ctx, span := tracing.Start(ctx, "db.ListReleases")
+	defer span.End()
+	result, err := d.DB.ListReleases(ctx, repoFullName)
+	tracing.RecordError(span, err)
+	return result, err
+}
+
+func (d *tracedDB) ReplaceSecurityAlerts(ctx context.Context, repoFullName string, alerts []*models.SecurityAlert) error {
+	ctx, span := tracing.Start(ctx, "db.ReplaceSecurityAlerts")
+	defer span.End()
+	err := d.DB.ReplaceSecurityAlerts(ctx, repoFullName, alerts)
+	tracing.RecordError(span, err)
+	return err
+}
+
+func (d *tracedDB) ListSecurityAlerts(ctx context.Context, repoFullName string) ([]*models.SecurityAlert, error) {
+	ctx, span := tracing.Start(ctx, "db.ListSecurityAlerts")
+	defer span.End()
+	result, err := d.DB.ListSecurityAlerts(ctx, repoFullName)
+	tracing.RecordError(span, err)
+	return result, err
+}
+
+func (d *tracedDB) ReplaceCommits(ctx context.Context, repoFullName string, commits []*models.Commit) error {
+	ctx, span := tracing.Start(ctx, "db.ReplaceCommits")
+	defer span.End()
+	err := d.DB.ReplaceCommits(ctx, repoFullName, commits)
+	tracing.RecordError(span, err)
+	return err
+}
+
+func (d *tracedDB) ListCommits(ctx context.Context, repoFullName string) ([]*models.Commit, error) {
+	ctx, span := tracing.Start(ctx, "db.ListCommits")
+	defer span.End()
+	result, err := d.DB.ListCommits(ctx, repoFullName)
+	tracing.RecordError(span, err)
+	return result, err
+}
+
+func (d *tracedDB) ReplaceDuplicates(ctx context.Context, repoFullName string, candidates []*models.DuplicateCandidate) error {
+	ctx, span := tracing.Start(ctx, "db.ReplaceDuplicates")
+	defer span.End()
+	err := d.DB.ReplaceDuplicates(ctx, repoFullName, candidates)
+	tracing.RecordError(span, err)
+	return err
+}
+
+func (d *tracedDB) ListDuplicates(ctx context.Context, repoFullName string) ([]*models.DuplicateCandidate, error) {
+	ctx, span := tracing.Start(ctx, "db.ListDuplicates")
+	defer span.End()
+	result, err := d.DB.ListDuplicates(ctx, repoFullName)
+	tracing.RecordError(span, err)
+	return result, err
+}
+
+func (d *tracedDB) ReplaceDiscussions(ctx context.Context, repoFullName string, discussions []*models.Discussion) error {
+	ctx, span := tracing.Start(ctx, "db.ReplaceDiscussions")
+	defer span.End()
+	err := d.DB.ReplaceDiscussions(ctx, repoFullName, discussions)
+	tracing.RecordError(span, err)
+	return err
+}
+
+func (d *tracedDB) ListDiscussions(ctx context.Context, repoFullName string) ([]*models.Discussion, error) {
+	ctx, span := tracing.Start(ctx, "db.ListDiscussions")
+	defer span.End()
+	result, err := d.DB.ListDiscussions(ctx, repoFullName)
+	tracing.RecordError(span, err)
+	return result, err
+}
+
+func (d *tracedDB) ReplaceProjects(ctx context.Context, repoFullName string, projects []*models.Project, items []*models.ProjectItem) error {
+	ctx, span := tracing.Start(ctx, "db.ReplaceProjects")
+	defer span.End()
+	err := d.DB.ReplaceProjects(ctx, repoFullName, projects, items)
+	tracing.RecordError(span, err)
+	return err
+}
+
+func (d *tracedDB) ListProjects(ctx context.Context, repoFullName string) ([]*models.Project, error) {
+	ctx, span := tracing.Start(ctx, "db.ListProjects")
+	defer span.End()
+	result, err := d.DB.ListProjects(ctx, repoFullName)
+	tracing.RecordError(span, err)
+	return result, err
+}
+
+func (d *tracedDB) ListProjectItems(ctx context.Context, repoFullName string) ([]*models.ProjectItem, error) {
+	ctx, span := tracing.Start(ctx, "db.ListProjectItems")
+	defer span.End()
+	result, err := d.DB.ListProjectItems(ctx, repoFullName)
+	tracing.RecordError(span, err)
+	return result, err
+}
+
+func (d *tracedDB) SavePullRequestDiff(ctx context.Context, diff *models.PullRequestDiff) error {
+	ctx, span := tracing.Start(ctx, "db.SavePullRequestDiff")
+	defer span.End()
+	err := d.DB.SavePullRequestDiff(ctx, diff)
+	tracing.RecordError(span, err)
+	return err
+}
+
+func (d *tracedDB) GetPullRequestDiff(ctx context.Context, repoFullName string, number int, format string) (*models.PullRequestDiff, error) {
+	ctx, span := tracing.Start(ctx, "db.GetPullRequestDiff")
+	defer span.End()
+	result, err := d.DB.GetPullRequestDiff(ctx, repoFullName, number, format)
+	tracing.RecordError(span, err)
+	return result, err
+}
+
+func (d *tracedDB) Stats(ctx context.Context) (*models.StorageStats, error) {
+	ctx, span := tracing.Start(ctx, "db.Stats")
+	defer span.End()
+	result, err := d.DB.Stats(ctx)
+	tracing.RecordError(span, err)
+	return result, err
+}
+
+func (d *tracedDB) Compact(ctx context.Context) (*models.CompactionResult, error) {
+	ctx, span := tracing.Start(ctx, "db.Compact")
+	defer span.End()
+	result, err := d.DB.Compact(ctx)
+	tracing.RecordError(span, err)
+	return result, err
+}
+
+func (d *tracedDB) ExpireClosedItems(ctx context.Context, policy *models.RetentionPolicy) (*models.RetentionResult, error) {
+	ctx, span := tracing.Start(ctx, "db.ExpireClosedItems")
+	defer span.End()
+	result, err := d.DB.ExpireClosedItems(ctx, policy)
+	tracing.RecordError(span, err)
+	return result, err
+}
+
+func (d *tracedDB) CheckIntegrity(ctx context.Context, repair bool) (*models.IntegrityReport, error) {
+	ctx, span := tracing.Start(ctx, "db.CheckIntegrity")
+	defer span.End()
+	result, err := d.DB.CheckIntegrity(ctx, repair)
+	tracing.RecordError(span, err)
+	return result, err
+}
+
+func (d *tracedDB) ClearRepositoryData(ctx context.Context, fullName string) error {
+	ctx, span := tracing.Start(ctx, "db.ClearRepositoryData")
+	defer span.End()
+	err := d.DB.ClearRepositoryData(ctx, fullName)
+	tracing.RecordError(span, err)
+	return err
+}
+
+func (d *tracedDB) Ping(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "db.Ping")
+	defer span.End()
+	err := d.DB.Ping(ctx)
+	tracing.RecordError(span, err)
+	return err
+}
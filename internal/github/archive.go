@@ -0,0 +1,342 @@
+package github
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidArchive is returned for files that are not a migration archive
+var ErrInvalidArchive = errors.New("not a GitHub migration archive")
+
+// Archive holds the repositories, pull requests and issues of a GitHub migration archive, as
+// exported by the organization migrations API or gh-migration tools. Pull requests and issues are
+// keyed by repository full name.
+type Archive struct {
+	Repositories []*Repository
+	PullRequests map[string][]*PullRequest
+	Issues       map[string][]*Issue
+}
+
+// archiveItem is an issue or pull request of a migration archive, where users, repositories,
+// labels and milestones are referenced by URL
+type archiveItem struct {
+	URL       string   `json:"url"`
+	User      string   `json:"user"`
+	Title     string   `json:"title"`
+	Body      string   `json:"body"`
+	State     string   `json:"state"`
+	Assignee  string   `json:"assignee"`
+	Assignees []string `json:"assignees"`
+	Milestone string   `json:"milestone"`
+	Labels    []string `json:"labels"`
+	Draft     bool     `json:"work_in_progress"`
+	CreatedAt string   `json:"created_at"`
+	UpdatedAt string   `json:"updated_at"`
+	ClosedAt  string   `json:"closed_at"`
+	MergedAt  string   `json:"merged_at"`
+}
+
+// ReadArchive reads a migration archive, a gzipped tarball of JSON files such as
+// repositories_000001.json, labels_000001.json, issues_000001.json and pull_requests_000001.json.
+// Files of other kinds are skipped.
+func ReadArchive(r io.Reader) (*Archive, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+	defer gz.Close()
+
+	var repos []archiveRepository
+	var prItems, issueItems []archiveItem
+	labels := make(map[string]Label)
+	milestones := make(map[string]*Milestone)
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		// Files of the same kind are numbered pages, each holding an array
+		name := path.Base(header.Name)
+		if !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		decode := func(v interface{}) error {
+			if err := json.NewDecoder(tr).Decode(v); err != nil {
+				return fmt.Errorf("failed to parse %s: %w", header.Name, err)
+			}
+			return nil
+		}
+		switch {
+		case strings.HasPrefix(name, "repositories_"):
+			var page []archiveRepository
+			if err := decode(&page); err != nil {
+				return nil, err
+			}
+			repos = append(repos, page...)
+		case strings.HasPrefix(name, "labels_"):
+			var page []archiveLabel
+			if err := decode(&page); err != nil {
+				return nil, err
+			}
+			for _, label := range page {
+				labels[label.URL] = Label{Name: label.Name, Color: label.Color, Description: label.Description}
+			}
+		case strings.HasPrefix(name, "milestones_"):
+			var page []archiveMilestone
+			if err := decode(&page); err != nil {
+				return nil, err
+			}
+			for _, milestone := range page {
+				milestones[milestone.URL] = &Milestone{
+					Number:      milestone.number(),
+					Title:       milestone.Title,
+					Description: milestone.Description,
+					State:       strings.ToUpper(milestone.State),
+					HTMLURL:     milestone.URL,
+					DueOn:       parseOptionalTime(milestone.DueOn),
+				}
+			}
+		case strings.HasPrefix(name, "pull_requests_"):
+			var page []archiveItem
+			if err := decode(&page); err != nil {
+				return nil, err
+			}
+			prItems = append(prItems, page...)
+		case strings.HasPrefix(name, "issues_"):
+			var page []archiveItem
+			if err := decode(&page); err != nil {
+				return nil, err
+			}
+			issueItems = append(issueItems, page...)
+		}
+	}
+
+	archive := &Archive{
+		PullRequests: make(map[string][]*PullRequest),
+		Issues:       make(map[string][]*Issue),
+	}
+	for _, repo := range repos {
+		fullName, _ := archivePath(repo.URL)
+		owner, name, ok := strings.Cut(fullName, "/")
+		if !ok {
+			continue
+		}
+		createdAt, _ := time.Parse(time.RFC3339, repo.CreatedAt)
+		archive.Repositories = append(archive.Repositories, &Repository{
+			Owner:       User{Login: owner, HTMLURL: strings.TrimSuffix(repo.URL, "/"+name)},
+			Name:        name,
+			FullName:    fullName,
+			Description: repo.Description,
+			HTMLURL:     repo.URL,
+			Private:     repo.Private,
+			CreatedAt:   createdAt,
+			UpdatedAt:   createdAt,
+		})
+	}
+	for _, item := range prItems {
+		fullName, number := archivePath(item.URL)
+		if number == 0 {
+			continue
+		}
+		pr := &PullRequest{
+			Number:    number,
+			Title:     item.Title,
+			Body:      item.Body,
+			State:     item.state(),
+			Draft:     item.Draft,
+			HTMLURL:   item.URL,
+			User:      archiveUser(item.User),
+			Assignees: item.assignees(),
+			Labels:    item.labels(labels),
+			Milestone: milestones[item.Milestone],
+			ClosedAt:  parseOptionalTime(item.ClosedAt),
+			MergedAt:  parseOptionalTime(item.MergedAt),
+		}
+		pr.CreatedAt, pr.UpdatedAt = item.times()
+		archive.PullRequests[fullName] = append(archive.PullRequests[fullName], pr)
+	}
+	for _, item := range issueItems {
+		fullName, number := archivePath(item.URL)
+		if number == 0 {
+			continue
+		}
+		issue := &Issue{
+			Number:    number,
+			Title:     item.Title,
+			Body:      item.Body,
+			State:     item.state(),
+			HTMLURL:   item.URL,
+			User:      archiveUser(item.User),
+			Assignees: item.assignees(),
+			Labels:    item.labels(labels),
+			Milestone: milestones[item.Milestone],
+			ClosedAt:  parseOptionalTime(item.ClosedAt),
+		}
+		issue.CreatedAt, issue.UpdatedAt = item.times()
+		archive.Issues[fullName] = append(archive.Issues[fullName], issue)
+	}
+	return archive, nil
+}
+
+// FullNames lists the repositories the archive holds data of, sorted
+func (a *Archive) FullNames() []string {
+	seen := make(map[string]bool)
+	var fullNames []string
+	add := func(fullName string) {
+		if !seen[fullName] {
+			seen[fullName] = true
+			fullNames = append(fullNames, fullName)
+		}
+	}
+	for _, repo := range a.Repositories {
+		add(repo.FullName)
+	}
+	for fullName := range a.PullRequests {
+		add(fullName)
+	}
+	for fullName := range a.Issues {
+		add(fullName)
+	}
+	sort.Strings(fullNames)
+	return fullNames
+}
+
+// Repository returns the archived repository with a full name, or nil
+func (a *Archive) Repository(fullName string) *Repository {
+	for _, repo := range a.Repositories {
+		if strings.EqualFold(repo.FullName, fullName) {
+			return repo
+		}
+	}
+	return nil
+}
+
+// archiveRepository is a repository of a migration archive
+type archiveRepository struct {
+	URL         string `json:"url"`
+	Description string `json:"description"`
+	Private     bool   `json:"private"`
+	CreatedAt   string `json:"created_at"`
+}
+
+// archiveLabel is a label of a migration archive, which items refer to by URL
+type archiveLabel struct {
+	URL         string `json:"url"`
+	Name        string `json:"name"`
+	Color       string `json:"color"`
+	Description string `json:"description"`
+}
+
+// archiveMilestone is a milestone of a migration archive, which items refer to by URL
+type archiveMilestone struct {
+	URL         string `json:"url"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	State       string `json:"state"`
+	DueOn       string `json:"due_on"`
+}
+
+// number returns the milestone number, the last segment of its URL
+func (m archiveMilestone) number() int {
+	n, _ := strconv.Atoi(path.Base(m.URL))
+	return n
+}
+
+// state returns the state of an item as gh reports it: MERGED, CLOSED or OPEN
+func (i archiveItem) state() string {
+	switch {
+	case i.MergedAt != "":
+		return "MERGED"
+	case i.ClosedAt != "":
+		return "CLOSED"
+	case i.State != "":
+		return strings.ToUpper(i.State)
+	}
+	return "OPEN"
+}
+
+// times returns when an item was created and last updated. Archives don't always record updates,
+// in which case the item was last updated when it was closed or merged.
+func (i archiveItem) times() (time.Time, time.Time) {
+	createdAt, _ := time.Parse(time.RFC3339, i.CreatedAt)
+	updatedAt := createdAt
+	for _, s := range []string{i.UpdatedAt, i.ClosedAt, i.MergedAt} {
+		if t := parseOptionalTime(s); t != nil && t.After(updatedAt) {
+			updatedAt = *t
+		}
+	}
+	return createdAt, updatedAt
+}
+
+// assignees returns the users an item is assigned to
+func (i archiveItem) assignees() []User {
+	urls := i.Assignees
+	if len(urls) == 0 && i.Assignee != "" {
+		urls = []string{i.Assignee}
+	}
+	var users []User
+	for _, u := range urls {
+		users = append(users, archiveUser(u))
+	}
+	return users
+}
+
+// labels returns the labels of an item, named after their URL when the archive doesn't define them
+func (i archiveItem) labels(defined map[string]Label) []Label {
+	var labels []Label
+	for _, u := range i.Labels {
+		label, ok := defined[u]
+		if !ok {
+			name, _ := url.PathUnescape(path.Base(u))
+			label = Label{Name: name}
+		}
+		labels = append(labels, label)
+	}
+	return labels
+}
+
+// archiveUser returns the user an archive URL such as https://github.com/octocat refers to
+func archiveUser(u string) User {
+	if u == "" {
+		return User{}
+	}
+	login := path.Base(u)
+	return User{Login: login, HTMLURL: u, IsBot: strings.HasSuffix(login, "[bot]")}
+}
+
+// archivePath returns the repository and the item number of an archive URL such as
+// https://github.com/owner/name/issues/12; the number is 0 for a repository URL
+func archivePath(u string) (string, int) {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return "", 0
+	}
+	segments := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+	if len(segments) < 2 {
+		return "", 0
+	}
+	fullName := segments[0] + "/" + segments[1]
+	if len(segments) < 4 {
+		return fullName, 0
+	}
+	number, _ := strconv.Atoi(segments[3])
+	return fullName, number
+}
@@ -0,0 +1,113 @@
+package github
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeArchive builds a migration archive holding files
+func writeArchive(t *testing.T, files map[string]string) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &buf
+}
+
+func TestReadArchive(t *testing.T) {
+	buf := writeArchive(t, map[string]string{
+		"schema.json":               `{"version":"1.0.1"}`,
+		"repositories_000001.json":  `[{"type":"repository","url":"https://github.com/org/api","description":"The API","private":true,"created_at":"2020-01-01T00:00:00Z"}]`,
+		"labels_000001.json":        `[{"type":"label","url":"https://github.com/org/api/labels/bug","name":"bug","color":"d73a4a"}]`,
+		"milestones_000001.json":    `[{"type":"milestone","url":"https://github.com/org/api/milestones/3","title":"v1.0","state":"open"}]`,
+		"issues_000001.json":        `[{"type":"issue","url":"https://github.com/org/api/issues/1","user":"https://github.com/alice","title":"Crash","body":"It crashes","assignee":"https://github.com/bob","milestone":"https://github.com/org/api/milestones/3","labels":["https://github.com/org/api/labels/bug","https://github.com/org/api/labels/good%20first%20issue"],"created_at":"2021-01-01T00:00:00Z","closed_at":"2021-02-01T00:00:00Z"}]`,
+		"pull_requests_000001.json": `[{"type":"pull_request","url":"https://github.com/org/api/pull/2","user":"https://github.com/dependabot[bot]","title":"Bump","created_at":"2021-03-01T00:00:00Z","merged_at":"2021-03-02T00:00:00Z","closed_at":"2021-03-02T00:00:00Z"},{"type":"pull_request","url":"https://github.com/org/web/pull/5","user":"https://github.com/carol","title":"Draft","work_in_progress":true,"created_at":"2021-04-01T00:00:00Z"}]`,
+	})
+
+	archive, err := ReadArchive(buf)
+	if err != nil {
+		t.Fatalf("ReadArchive() error = %v", err)
+	}
+	if got, want := archive.FullNames(), []string{"org/api", "org/web"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("FullNames() = %v, want %v", got, want)
+	}
+	repo := archive.Repository("org/api")
+	if repo == nil || repo.Owner.Login != "org" || repo.Name != "api" || !repo.Private || repo.Description != "The API" {
+		t.Errorf("Repository(org/api) = %+v", repo)
+	}
+	if archive.Repository("org/web") != nil {
+		t.Error("Repository(org/web) should be nil without repository data")
+	}
+
+	issues := archive.Issues["org/api"]
+	if len(issues) != 1 {
+		t.Fatalf("issues of org/api = %d, want 1", len(issues))
+	}
+	issue := issues[0]
+	if issue.Number != 1 || issue.State != "CLOSED" || issue.User.Login != "alice" || issue.Milestone == nil || issue.Milestone.Title != "v1.0" {
+		t.Errorf("issue = %+v", issue)
+	}
+	if want := time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC); !issue.UpdatedAt.Equal(want) {
+		t.Errorf("issue UpdatedAt = %v, want the close time %v", issue.UpdatedAt, want)
+	}
+	if len(issue.Assignees) != 1 || issue.Assignees[0].Login != "bob" {
+		t.Errorf("issue assignees = %+v, want bob", issue.Assignees)
+	}
+	wantLabels := []Label{{Name: "bug", Color: "d73a4a"}, {Name: "good first issue"}}
+	if !reflect.DeepEqual(issue.Labels, wantLabels) {
+		t.Errorf("issue labels = %+v, want %+v", issue.Labels, wantLabels)
+	}
+
+	pr := archive.PullRequests["org/api"][0]
+	if pr.Number != 2 || pr.State != "MERGED" || !pr.User.Bot() || pr.MergedAt == nil {
+		t.Errorf("pull request = %+v", pr)
+	}
+	draft := archive.PullRequests["org/web"][0]
+	if draft.Number != 5 || draft.State != "OPEN" || !draft.Draft {
+		t.Errorf("draft pull request = %+v", draft)
+	}
+}
+
+func TestReadArchiveInvalid(t *testing.T) {
+	if _, err := ReadArchive(strings.NewReader(`[{"number":1}]`)); !errors.Is(err, ErrInvalidArchive) {
+		t.Errorf("ReadArchive(JSON) error = %v, want ErrInvalidArchive", err)
+	}
+	buf := writeArchive(t, map[string]string{"issues_000001.json": `{"broken"`})
+	if _, err := ReadArchive(buf); err == nil || !strings.Contains(err.Error(), "issues_000001.json") {
+		t.Errorf("ReadArchive(broken issues) error = %v, want it to name the file", err)
+	}
+}
+
+func TestParsePullRequests(t *testing.T) {
+	prs, err := ParsePullRequests([]byte(`[{"number":7,"title":"Fix","state":"OPEN","isDraft":true,"author":{"login":"alice"},` +
+		`"createdAt":"2021-01-01T00:00:00Z","updatedAt":"2021-01-02T00:00:00Z","url":"https://github.com/org/api/pull/7","labels":[{"name":"bug"}]}]`))
+	if err != nil {
+		t.Fatalf("ParsePullRequests() error = %v", err)
+	}
+	if len(prs) != 1 || prs[0].Number != 7 || !prs[0].Draft || prs[0].User.Login != "alice" || len(prs[0].Labels) != 1 {
+		t.Errorf("ParsePullRequests() = %+v", prs)
+	}
+	if _, err := ParseIssues([]byte(`{"number":1}`)); err == nil {
+		t.Error("ParseIssues(object) should fail")
+	}
+}
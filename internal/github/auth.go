@@ -0,0 +1,247 @@
+package github
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Authentication modes
+const (
+	AuthModeToken = "token"  // GH_TOKEN or GITHUB_TOKEN environment variable
+	AuthModeGH    = "gh-cli" // Credentials stored by gh auth login
+)
+
+// RequiredScopes lists the OAuth scopes needed to sync private repositories and organization data
+var RequiredScopes = []string{"repo", "read:org"}
+
+// AuthStatus describes the credentials gh uses
+type AuthStatus struct {
+	Mode        string   `json:"mode"`
+	TokenSource string   `json:"token_source,omitempty"` // Environment variable holding the token
+	User        string   `json:"user,omitempty"`
+	Scopes      []string `json:"scopes,omitempty"`
+	// ScopesKnown is false for fine-grained tokens and GitHub App tokens, which do not report scopes
+	ScopesKnown bool   `json:"scopes_known"`
+	GHVersion   string `json:"gh_version,omitempty"`
+}
+
+// MissingScopes returns the required scopes the credentials lack
+func (s *AuthStatus) MissingScopes() []string {
+	if !s.ScopesKnown {
+		return nil
+	}
+	return missingScopes(s.Scopes)
+}
+
+// AuthCheck is the outcome of one diagnostic performed by Diagnose
+type AuthCheck struct {
+	Name   string `json:"name"`
+	OK     bool   `json:"ok"`
+	Warn   bool   `json:"warn,omitempty"` // Not fatal, but worth fixing
+	Detail string `json:"detail"`
+	Fix    string `json:"fix,omitempty"`
+}
+
+// tokenSource returns the environment variable gh reads its token from, if any.
+// gh prefers GH_TOKEN over GITHUB_TOKEN.
+func tokenSource() string {
+	for _, name := range []string{"GH_TOKEN", "GITHUB_TOKEN"} {
+		if os.Getenv(name) != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// GetAuthStatus reports which credentials gh uses, the authenticated user and the token scopes
+func GetAuthStatus() (*AuthStatus, error) {
+	status := &AuthStatus{Mode: AuthModeGH}
+	if status.TokenSource = tokenSource(); status.TokenSource != "" {
+		status.Mode = AuthModeToken
+	}
+
+	version, err := exec.Command("gh", "--version").Output()
+	if err != nil {
+		return status, fmt.Errorf("gh CLI not found: %w", err)
+	}
+	status.GHVersion = strings.TrimSpace(strings.SplitN(string(version), "\n", 2)[0])
+
+	// The scopes are only reported in the response headers
+	cmd := exec.Command("gh", "api", "--include", "user")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return status, fmt.Errorf("GitHub authentication failed: %w, stderr: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	headers, body := splitResponse(stdout.String())
+	if scopes, ok := headers["x-oauth-scopes"]; ok {
+		status.Scopes = parseScopes(scopes)
+		status.ScopesKnown = true
+	}
+
+	var user struct {
+		Login string `json:"login"`
+	}
+	if err := json.Unmarshal([]byte(body), &user); err != nil {
+		return status, fmt.Errorf("failed to parse user data: %w", err)
+	}
+	status.User = user.Login
+
+	return status, nil
+}
+
+// Diagnose checks the gh installation, credentials, token scopes and rate limit,
+// suggesting a fix for each problem found
+func Diagnose() []*AuthCheck {
+	var checks []*AuthCheck
+
+	status, err := GetAuthStatus()
+	if status.GHVersion == "" {
+		return append(checks, &AuthCheck{
+			Name:   "gh CLI",
+			Detail: err.Error(),
+			Fix:    "Install the GitHub CLI from https://cli.github.com and make sure it is on PATH",
+		})
+	}
+	checks = append(checks, &AuthCheck{Name: "gh CLI", OK: true, Detail: status.GHVersion})
+
+	if err != nil {
+		check := &AuthCheck{Name: "Authentication", Detail: err.Error()}
+		if status.Mode == AuthModeToken {
+			check.Fix = fmt.Sprintf("The token in %s was rejected; set a valid token or unset it to use the gh CLI credentials", status.TokenSource)
+		} else {
+			check.Fix = "Run 'ghrepos auth login', or set GH_TOKEN to a personal access token"
+		}
+		return append(checks, check)
+	}
+
+	mode := "gh CLI credentials"
+	if status.Mode == AuthModeToken {
+		mode = "token from " + status.TokenSource
+	}
+	checks = append(checks, &AuthCheck{Name: "Authentication", OK: true, Detail: fmt.Sprintf("logged in as %s using %s", status.User, mode)})
+
+	if os.Getenv("GH_TOKEN") != "" && os.Getenv("GITHUB_TOKEN") != "" {
+		checks = append(checks, &AuthCheck{
+			Name:   "Token variables",
+			OK:     true,
+			Warn:   true,
+			Detail: "both GH_TOKEN and GITHUB_TOKEN are set; GH_TOKEN takes precedence",
+			Fix:    "Unset the variable you do not mean to use",
+		})
+	}
+
+	switch missing := status.MissingScopes(); {
+	case !status.ScopesKnown:
+		checks = append(checks, &AuthCheck{
+			Name:   "Token scopes",
+			OK:     true,
+			Warn:   true,
+			Detail: "the token does not report scopes (fine-grained or app token)",
+			Fix:    "Make sure it grants read access to contents, pull requests, issues and organization members",
+		})
+	case len(missing) > 0:
+		check := &AuthCheck{Name: "Token scopes", Detail: fmt.Sprintf("missing %s (have: %s)", strings.Join(missing, ", "), strings.Join(status.Scopes, ", "))}
+		if status.Mode == AuthModeToken {
+			check.Fix = fmt.Sprintf("Create a token with the %s scopes and set it in %s", strings.Join(RequiredScopes, ", "), status.TokenSource)
+		} else {
+			check.Fix = fmt.Sprintf("Run 'gh auth refresh -s %s'", strings.Join(missing, ","))
+		}
+		checks = append(checks, check)
+	default:
+		checks = append(checks, &AuthCheck{Name: "Token scopes", OK: true, Detail: strings.Join(status.Scopes, ", ")})
+	}
+
+	rateLimit, err := NewClient().GetRateLimit()
+	switch {
+	case err != nil:
+		checks = append(checks, &AuthCheck{Name: "Rate limit", Detail: err.Error(), Fix: "Check network access to api.github.com"})
+	case rateLimit.Remaining == 0:
+		checks = append(checks, &AuthCheck{
+			Name:   "Rate limit",
+			Detail: fmt.Sprintf("exhausted (limit %d)", rateLimit.Limit),
+			Fix:    fmt.Sprintf("Wait until %s before syncing", rateLimit.ResetTime.Local().Format("15:04:05")),
+		})
+	default:
+		checks = append(checks, &AuthCheck{Name: "Rate limit", OK: true, Detail: fmt.Sprintf("%d of %d remaining", rateLimit.Remaining, rateLimit.Limit)})
+	}
+
+	return checks
+}
+
+// LoginWithToken stores a personal access token in gh's credentials
+func LoginWithToken(token string) error {
+	cmd := exec.Command("gh", "auth", "login", "--with-token")
+	cmd.Stdin = strings.NewReader(token)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("GitHub login failed: %w, stderr: %s", err, stderr.String())
+	}
+
+	return nil
+}
+
+// splitResponse splits the output of gh api --include into lowercased headers and the body
+func splitResponse(output string) (map[string]string, string) {
+	headers := make(map[string]string)
+	reader := bufio.NewReader(strings.NewReader(output))
+
+	// Skip the status line
+	if _, err := reader.ReadString('\n'); err != nil {
+		return headers, ""
+	}
+	for {
+		line, err := reader.ReadString('\n')
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" || err != nil {
+			break
+		}
+		if name, value, ok := strings.Cut(line, ":"); ok {
+			headers[strings.ToLower(strings.TrimSpace(name))] = strings.TrimSpace(value)
+		}
+	}
+
+	var body strings.Builder
+	reader.WriteTo(&body)
+	return headers, body.String()
+}
+
+// parseScopes parses the comma separated X-OAuth-Scopes header
+func parseScopes(header string) []string {
+	var scopes []string
+	for _, scope := range strings.Split(header, ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
+
+// missingScopes returns the required scopes not granted, taking implied scopes into account
+func missingScopes(scopes []string) []string {
+	granted := make(map[string]bool)
+	for _, scope := range scopes {
+		granted[scope] = true
+	}
+	// write:org and admin:org include read:org
+	if granted["write:org"] || granted["admin:org"] {
+		granted["read:org"] = true
+	}
+
+	var missing []string
+	for _, scope := range RequiredScopes {
+		if !granted[scope] {
+			missing = append(missing, scope)
+		}
+	}
+	return missing
+}
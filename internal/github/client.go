@@ -2,8 +2,12 @@ package github
 
 import (
 	"bytes"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
+	"net/url"
+	"os"
 	"os/exec"
 	"strconv"
 	"strings"
@@ -12,7 +16,15 @@ import (
 
 // Client represents a GitHub client that uses the gh CLI
 type Client struct {
-	// Add any client-specific configuration here
+	pool   *processPool
+	tokens *tokenPool // nil when gh uses its own credentials
+}
+
+// ClientOptions configures a client. Zero values use the defaults.
+type ClientOptions struct {
+	MaxConcurrentCalls int      // gh processes running at once
+	Tokens             []string // Tokens to rotate between instead of gh's own credentials
+	TokenMinRemaining  int      // Remaining rate limit at which calls move to another token
 }
 
 // Ensure Client implements ClientInterface
@@ -20,7 +32,54 @@ var _ ClientInterface = (*Client)(nil)
 
 // NewClient creates a new GitHub client
 func NewClient() *Client {
-	return &Client{}
+	return NewClientWithConcurrency(DefaultMaxConcurrentCalls)
+}
+
+// NewClientWithConcurrency creates a new GitHub client running at most maxConcurrent gh processes at once
+func NewClientWithConcurrency(maxConcurrent int) *Client {
+	return NewClientWithOptions(ClientOptions{MaxConcurrentCalls: maxConcurrent})
+}
+
+// NewClientWithOptions creates a new GitHub client with the given options
+func NewClientWithOptions(opts ClientOptions) *Client {
+	return &Client{
+		pool:   newProcessPool(opts.MaxConcurrentCalls),
+		tokens: newTokenPool(opts.Tokens, opts.TokenMinRemaining),
+	}
+}
+
+// PoolStats reports the usage of the client's gh process pool
+func (c *Client) PoolStats() PoolStats {
+	return c.pool.snapshot()
+}
+
+// TokenStats reports the rate limit of each configured token, or nil when none are configured
+func (c *Client) TokenStats() []TokenStatus {
+	if c.tokens == nil {
+		return nil
+	}
+	return c.tokens.stats()
+}
+
+// command builds a gh command, authenticated with the next token when tokens are configured
+func (c *Client) command(args ...string) *exec.Cmd {
+	cmd := exec.Command("gh", args...)
+	if c.tokens != nil {
+		withToken(cmd, c.tokens.next())
+	}
+	return cmd
+}
+
+// run runs a gh command in the process pool. A token rejected for exceeding its
+// rate limit is set aside until it resets.
+func (c *Client) run(cmd *exec.Cmd) error {
+	err := c.pool.run(cmd)
+	if err != nil && c.tokens != nil {
+		if stderr, ok := cmd.Stderr.(*bytes.Buffer); ok && isRateLimited(stderr.String()) {
+			c.tokens.exhaust(commandToken(cmd))
+		}
+	}
+	return err
 }
 
 // CheckAuth checks if the user is authenticated with GitHub
@@ -36,12 +95,13 @@ func CheckAuth() error {
 	return nil
 }
 
-// Login performs GitHub authentication
+// Login performs GitHub authentication, prompting on the terminal
 func Login() error {
-	cmd := exec.Command("gh", "auth", "login")
-	cmd.Stdin = strings.NewReader("\n") // Default options
+	cmd := exec.Command("gh", "auth", "login", "--scopes", strings.Join(RequiredScopes, ","))
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
 	var stderr bytes.Buffer
-	cmd.Stderr = &stderr
+	cmd.Stderr = io.MultiWriter(os.Stderr, &stderr)
 
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("GitHub login failed: %w, stderr: %s", err, stderr.String())
@@ -58,12 +118,12 @@ func (c *Client) GetRepository(owner, name string) (*Repository, error) {
 	fmt.Printf("Executing command: %s\n", cmdStr)
 
 	// Execute the command
-	cmd := exec.Command("gh", args...)
+	cmd := c.command(args...)
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
-	if err := cmd.Run(); err != nil {
+	if err := c.run(cmd); err != nil {
 		fmt.Printf("Command failed: %v\n", err)
 		fmt.Printf("Stderr: %s\n", stderr.String())
 		return nil, fmt.Errorf("failed to get repository: %w, stderr: %s", err, stderr.String())
@@ -126,7 +186,14 @@ func (c *Client) GetRepository(owner, name string) (*Repository, error) {
 // ListPullRequests lists pull requests for a repository
 func (c *Client) ListPullRequests(owner, name string, options *PullRequestOptions) ([]*PullRequest, error) {
 	// Build the command to use gh pr list
-	args := []string{"pr", "list", "--repo", fmt.Sprintf("%s/%s", owner, name), "--json", "number,title,state,author,createdAt,updatedAt,url"}
+	fields := "number,title,body,state,isDraft,author,assignees,milestone,reviewRequests,createdAt,updatedAt,closedAt,mergedAt,url,labels,reviewDecision,additions,deletions,changedFiles"
+	if options != nil && options.IncludeReviews {
+		fields += ",reviews"
+	}
+	if options != nil && options.IncludeFiles {
+		fields += ",files"
+	}
+	args := []string{"pr", "list", "--repo", fmt.Sprintf("%s/%s", owner, name), "--json", fields}
 
 	// Add query parameters
 	if options != nil {
@@ -142,12 +209,12 @@ func (c *Client) ListPullRequests(owner, name string, options *PullRequestOption
 	fmt.Printf("Executing command: %s\n", cmdStr)
 
 	// Execute the command
-	cmd := exec.Command("gh", args...)
+	cmd := c.command(args...)
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
-	if err := cmd.Run(); err != nil {
+	if err := c.run(cmd); err != nil {
 		fmt.Printf("Command failed: %v\n", err)
 		fmt.Printf("Stderr: %s\n", stderr.String())
 		return nil, fmt.Errorf("failed to list pull requests: %w, stderr: %s", err, stderr.String())
@@ -159,22 +226,63 @@ func (c *Client) ListPullRequests(owner, name string, options *PullRequestOption
 		fmt.Printf("Command output: %s\n", stdout.String())
 	}
 
-	// Parse the JSON output
+	prs, err := ParsePullRequests(stdout.Bytes())
+	if err != nil {
+		fmt.Printf("Failed to parse JSON: %v\n", err)
+		fmt.Printf("JSON content (first 200 chars): %s\n", truncate(stdout.String(), 200))
+		return nil, err
+	}
+
+	fmt.Printf("Parsed %d pull requests\n", len(prs))
+	return prs, nil
+}
+
+// ParsePullRequests parses the output of gh pr list --json, as produced by ListPullRequests
+func ParsePullRequests(data []byte) ([]*PullRequest, error) {
 	var ghPRs []struct {
-		Number int    `json:"number"`
-		Title  string `json:"title"`
-		State  string `json:"state"`
-		Author struct {
+		Number  int    `json:"number"`
+		Title   string `json:"title"`
+		State   string `json:"state"`
+		Body    string `json:"body"`
+		IsDraft bool   `json:"isDraft"`
+		Author  struct {
 			Login string `json:"login"`
+			IsBot bool   `json:"is_bot"`
 		} `json:"author"`
-		CreatedAt string `json:"createdAt"`
-		UpdatedAt string `json:"updatedAt"`
-		URL       string `json:"url"`
+		Assignees []struct {
+			Login string `json:"login"`
+		} `json:"assignees"`
+		Milestone *Milestone `json:"milestone"`
+		// Review requests are users or teams, told apart by __typename
+		ReviewRequests []struct {
+			Typename string `json:"__typename"`
+			Login    string `json:"login"`
+			Name     string `json:"name"`
+			Slug     string `json:"slug"`
+		} `json:"reviewRequests"`
+		CreatedAt      string  `json:"createdAt"`
+		UpdatedAt      string  `json:"updatedAt"`
+		ClosedAt       string  `json:"closedAt"`
+		MergedAt       string  `json:"mergedAt"`
+		URL            string  `json:"url"`
+		Labels         []Label `json:"labels"`
+		ReviewDecision string  `json:"reviewDecision"`
+		Reviews        []struct {
+			Author struct {
+				Login string `json:"login"`
+			} `json:"author"`
+			State       string `json:"state"`
+			SubmittedAt string `json:"submittedAt"`
+		} `json:"reviews"`
+		Additions    int `json:"additions"`
+		Deletions    int `json:"deletions"`
+		ChangedFiles int `json:"changedFiles"`
+		Files        []struct {
+			Path string `json:"path"`
+		} `json:"files"`
 	}
 
-	if err := json.Unmarshal(stdout.Bytes(), &ghPRs); err != nil {
-		fmt.Printf("Failed to parse JSON: %v\n", err)
-		fmt.Printf("JSON content (first 200 chars): %s\n", truncate(stdout.String(), 200))
+	if err := json.Unmarshal(data, &ghPRs); err != nil {
 		return nil, fmt.Errorf("failed to parse pull requests data: %w", err)
 	}
 
@@ -195,25 +303,62 @@ func (c *Client) ListPullRequests(owner, name string, options *PullRequestOption
 		}
 
 		pr := &PullRequest{
-			Number:    ghPR.Number,
-			Title:     ghPR.Title,
-			State:     ghPR.State,
-			User:      User{Login: ghPR.Author.Login},
-			CreatedAt: createdAt,
-			UpdatedAt: updatedAt,
-			HTMLURL:   ghPR.URL,
+			Number:         ghPR.Number,
+			Title:          ghPR.Title,
+			Body:           ghPR.Body,
+			State:          ghPR.State,
+			Draft:          ghPR.IsDraft,
+			User:           User{Login: ghPR.Author.Login, IsBot: ghPR.Author.IsBot},
+			CreatedAt:      createdAt,
+			UpdatedAt:      updatedAt,
+			ClosedAt:       parseOptionalTime(ghPR.ClosedAt),
+			MergedAt:       parseOptionalTime(ghPR.MergedAt),
+			HTMLURL:        ghPR.URL,
+			Labels:         ghPR.Labels,
+			Milestone:      ghPR.Milestone,
+			ReviewDecision: ghPR.ReviewDecision,
+			Additions:      ghPR.Additions,
+			Deletions:      ghPR.Deletions,
+			ChangedFiles:   ghPR.ChangedFiles,
+		}
+		for _, assignee := range ghPR.Assignees {
+			pr.Assignees = append(pr.Assignees, User{Login: assignee.Login})
+		}
+		for _, request := range ghPR.ReviewRequests {
+			if request.Typename == "Team" {
+				slug := request.Slug
+				if i := strings.LastIndex(slug, "/"); i >= 0 {
+					slug = slug[i+1:]
+				}
+				pr.RequestedTeams = append(pr.RequestedTeams, Team{Name: request.Name, Slug: slug})
+			} else if request.Login != "" {
+				pr.RequestedReviewers = append(pr.RequestedReviewers, User{Login: request.Login})
+			}
+		}
+		for _, file := range ghPR.Files {
+			pr.Files = append(pr.Files, file.Path)
+		}
+		for _, ghReview := range ghPR.Reviews {
+			submittedAt := parseOptionalTime(ghReview.SubmittedAt)
+			if submittedAt == nil {
+				continue
+			}
+			pr.Reviews = append(pr.Reviews, Review{
+				User:        User{Login: ghReview.Author.Login},
+				State:       ghReview.State,
+				SubmittedAt: *submittedAt,
+			})
 		}
 		prs = append(prs, pr)
 	}
 
-	fmt.Printf("Parsed %d pull requests\n", len(prs))
 	return prs, nil
 }
 
 // ListIssues lists issues for a repository
 func (c *Client) ListIssues(owner, name string, options *IssueOptions) ([]*Issue, error) {
 	// Build the command to use gh issue list
-	args := []string{"issue", "list", "--repo", fmt.Sprintf("%s/%s", owner, name), "--json", "number,title,state,author,createdAt,updatedAt,url"}
+	args := []string{"issue", "list", "--repo", fmt.Sprintf("%s/%s", owner, name), "--json", "number,title,body,state,author,assignees,milestone,createdAt,updatedAt,closedAt,url,labels"}
 
 	// Add query parameters
 	if options != nil {
@@ -229,12 +374,12 @@ func (c *Client) ListIssues(owner, name string, options *IssueOptions) ([]*Issue
 	fmt.Printf("Executing command: %s\n", cmdStr)
 
 	// Execute the command
-	cmd := exec.Command("gh", args...)
+	cmd := c.command(args...)
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
-	if err := cmd.Run(); err != nil {
+	if err := c.run(cmd); err != nil {
 		fmt.Printf("Command failed: %v\n", err)
 		fmt.Printf("Stderr: %s\n", stderr.String())
 		return nil, fmt.Errorf("failed to list issues: %w, stderr: %s", err, stderr.String())
@@ -246,22 +391,40 @@ func (c *Client) ListIssues(owner, name string, options *IssueOptions) ([]*Issue
 		fmt.Printf("Command output: %s\n", stdout.String())
 	}
 
-	// Parse the JSON output
+	issues, err := ParseIssues(stdout.Bytes())
+	if err != nil {
+		fmt.Printf("Failed to parse JSON: %v\n", err)
+		fmt.Printf("JSON content (first 200 chars): %s\n", truncate(stdout.String(), 200))
+		return nil, err
+	}
+
+	fmt.Printf("Parsed %d issues\n", len(issues))
+	return issues, nil
+}
+
+// ParseIssues parses the output of gh issue list --json, as produced by ListIssues
+func ParseIssues(data []byte) ([]*Issue, error) {
 	var ghIssues []struct {
 		Number int    `json:"number"`
 		Title  string `json:"title"`
+		Body   string `json:"body"`
 		State  string `json:"state"`
 		Author struct {
 			Login string `json:"login"`
+			IsBot bool   `json:"is_bot"`
 		} `json:"author"`
-		CreatedAt string `json:"createdAt"`
-		UpdatedAt string `json:"updatedAt"`
-		URL       string `json:"url"`
+		Assignees []struct {
+			Login string `json:"login"`
+		} `json:"assignees"`
+		Milestone *Milestone `json:"milestone"`
+		CreatedAt string     `json:"createdAt"`
+		UpdatedAt string     `json:"updatedAt"`
+		ClosedAt  string     `json:"closedAt"`
+		URL       string     `json:"url"`
+		Labels    []Label    `json:"labels"`
 	}
 
-	if err := json.Unmarshal(stdout.Bytes(), &ghIssues); err != nil {
-		fmt.Printf("Failed to parse JSON: %v\n", err)
-		fmt.Printf("JSON content (first 200 chars): %s\n", truncate(stdout.String(), 200))
+	if err := json.Unmarshal(data, &ghIssues); err != nil {
 		return nil, fmt.Errorf("failed to parse issues data: %w", err)
 	}
 
@@ -284,19 +447,888 @@ func (c *Client) ListIssues(owner, name string, options *IssueOptions) ([]*Issue
 		issue := &Issue{
 			Number:    ghIssue.Number,
 			Title:     ghIssue.Title,
+			Body:      ghIssue.Body,
 			State:     ghIssue.State,
-			User:      User{Login: ghIssue.Author.Login},
+			User:      User{Login: ghIssue.Author.Login, IsBot: ghIssue.Author.IsBot},
 			CreatedAt: createdAt,
 			UpdatedAt: updatedAt,
+			ClosedAt:  parseOptionalTime(ghIssue.ClosedAt),
 			HTMLURL:   ghIssue.URL,
+			Labels:    ghIssue.Labels,
+			Milestone: ghIssue.Milestone,
+		}
+		for _, assignee := range ghIssue.Assignees {
+			issue.Assignees = append(issue.Assignees, User{Login: assignee.Login})
 		}
 		issues = append(issues, issue)
 	}
 
-	fmt.Printf("Parsed %d issues\n", len(issues))
 	return issues, nil
 }
 
+// ListOrganizationRepositories lists the full names of an organization's repositories, skipping archived ones
+func (c *Client) ListOrganizationRepositories(org string, limit int) ([]string, error) {
+	args := []string{"repo", "list", org, "--no-archived", "--limit", fmt.Sprintf("%d", limit), "--json", "nameWithOwner"}
+	cmdStr := fmt.Sprintf("gh %s", strings.Join(args, " "))
+	fmt.Printf("Executing command: %s\n", cmdStr)
+
+	cmd := c.command(args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := c.run(cmd); err != nil {
+		fmt.Printf("Command failed: %v\n", err)
+		fmt.Printf("Stderr: %s\n", stderr.String())
+		return nil, fmt.Errorf("failed to list organization repositories: %w, stderr: %s", err, stderr.String())
+	}
+
+	var ghRepos []struct {
+		NameWithOwner string `json:"nameWithOwner"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &ghRepos); err != nil {
+		return nil, fmt.Errorf("failed to parse organization repositories: %w", err)
+	}
+
+	names := make([]string, 0, len(ghRepos))
+	for _, repo := range ghRepos {
+		names = append(names, repo.NameWithOwner)
+	}
+
+	fmt.Printf("Found %d repositories in %s\n", len(names), org)
+	return names, nil
+}
+
+// Relations of the authenticated user to repositories, listed by ListUserRepositories
+const (
+	RelationOwner       = "owner"
+	RelationStarred     = "starred"
+	RelationContributor = "contributor"
+)
+
+// ListUserRepositories lists up to limit repositories the authenticated user owns, stars or
+// has contributed to (commits, pull requests, issues or reviews in the last year)
+func (c *Client) ListUserRepositories(relation string, limit int) ([]*Repository, error) {
+	switch relation {
+	case RelationOwner:
+		return c.listRepositoryPages("user/repos?affiliation=owner&sort=updated", limit)
+	case RelationStarred:
+		return c.listRepositoryPages("user/starred?sort=created", limit)
+	case RelationContributor:
+		return c.listContributedRepositories(limit)
+	}
+	return nil, fmt.Errorf("unknown repository relation %q", relation)
+}
+
+// listRepositoryPages fetches a paged REST list of repositories until limit repositories or the last page
+func (c *Client) listRepositoryPages(endpoint string, limit int) ([]*Repository, error) {
+	var repos []*Repository
+	for page := 1; len(repos) < limit; page++ {
+		var batch []*Repository
+		if err := c.getJSON(fmt.Sprintf("%s&per_page=100&page=%d", endpoint, page), &batch); err != nil {
+			return nil, fmt.Errorf("failed to list repositories: %w", err)
+		}
+		repos = append(repos, batch...)
+		if len(batch) < 100 {
+			break
+		}
+	}
+	if len(repos) > limit {
+		repos = repos[:limit]
+	}
+	return repos, nil
+}
+
+// listContributedRepositories lists the repositories of others the user contributed to. Only
+// GraphQL reports them, at most 100.
+func (c *Client) listContributedRepositories(limit int) ([]*Repository, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 100
+	}
+	query := fmt.Sprintf(`query { viewer { repositoriesContributedTo(first: %d, includeUserRepositories: false,
+		contributionTypes: [COMMIT, PULL_REQUEST, ISSUE, PULL_REQUEST_REVIEW]) {
+		nodes { nameWithOwner name description url isPrivate isArchived owner { login } } } } }`, limit)
+
+	cmd := c.command("api", "graphql", "-f", "query="+query)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := c.run(cmd); err != nil {
+		return nil, fmt.Errorf("failed to list contributed repositories: %w, stderr: %s", err, stderr.String())
+	}
+
+	var response struct {
+		Data struct {
+			Viewer struct {
+				RepositoriesContributedTo struct {
+					Nodes []struct {
+						NameWithOwner string `json:"nameWithOwner"`
+						Name          string `json:"name"`
+						Description   string `json:"description"`
+						URL           string `json:"url"`
+						IsPrivate     bool   `json:"isPrivate"`
+						IsArchived    bool   `json:"isArchived"`
+						Owner         User   `json:"owner"`
+					} `json:"nodes"`
+				} `json:"repositoriesContributedTo"`
+			} `json:"viewer"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &response); err != nil {
+		return nil, fmt.Errorf("failed to parse contributed repositories: %w", err)
+	}
+
+	nodes := response.Data.Viewer.RepositoriesContributedTo.Nodes
+	repos := make([]*Repository, 0, len(nodes))
+	for _, node := range nodes {
+		repos = append(repos, &Repository{
+			Owner:       node.Owner,
+			Name:        node.Name,
+			FullName:    node.NameWithOwner,
+			Description: node.Description,
+			HTMLURL:     node.URL,
+			Private:     node.IsPrivate,
+			Archived:    node.IsArchived,
+		})
+	}
+	return repos, nil
+}
+
+// ListAuthorAssociations maps the numbers of the most recently updated issues and pull requests
+// to their authors' association with the repository (MEMBER, CONTRIBUTOR, FIRST_TIMER, ...).
+// gh's list commands do not report it, so it is read from the REST issues endpoint.
+func (c *Client) ListAuthorAssociations(owner, name string, limit int) (map[int]string, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 100
+	}
+	endpoint := fmt.Sprintf("repos/%s/%s/issues?state=all&sort=updated&direction=desc&per_page=%d", owner, name, limit)
+	args := []string{"api", endpoint, "--jq", "[.[] | {number, author_association}]"}
+	cmdStr := fmt.Sprintf("gh %s", strings.Join(args, " "))
+	fmt.Printf("Executing command: %s\n", cmdStr)
+
+	cmd := c.command(args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := c.run(cmd); err != nil {
+		fmt.Printf("Command failed: %v\n", err)
+		fmt.Printf("Stderr: %s\n", stderr.String())
+		return nil, fmt.Errorf("failed to list author associations: %w, stderr: %s", err, stderr.String())
+	}
+
+	var entries []struct {
+		Number            int    `json:"number"`
+		AuthorAssociation string `json:"author_association"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse author associations: %w", err)
+	}
+
+	associations := make(map[int]string, len(entries))
+	for _, entry := range entries {
+		associations[entry.Number] = entry.AuthorAssociation
+	}
+	return associations, nil
+}
+
+// ListMilestones lists the open and closed milestones of a repository
+func (c *Client) ListMilestones(owner, name string) ([]*Milestone, error) {
+	var milestones []*Milestone
+	endpoint := fmt.Sprintf("repos/%s/%s/milestones?state=all&per_page=100", owner, name)
+	if err := c.getJSON(endpoint, &milestones); err != nil {
+		return nil, fmt.Errorf("failed to list milestones: %w", err)
+	}
+	return milestones, nil
+}
+
+// ListReleases lists the newest releases of a repository, drafts included
+func (c *Client) ListReleases(owner, name string, limit int) ([]*Release, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 100
+	}
+	var releases []*Release
+	endpoint := fmt.Sprintf("repos/%s/%s/releases?per_page=%d", owner, name, limit)
+	if err := c.getJSON(endpoint, &releases); err != nil {
+		return nil, fmt.Errorf("failed to list releases: %w", err)
+	}
+	return releases, nil
+}
+
+// ListCommits lists the commits of the default branch made since a time, newest first, at most limit
+func (c *Client) ListCommits(owner, name string, since time.Time, limit int) ([]*Commit, error) {
+	var commits []*Commit
+	for page := 1; len(commits) < limit; page++ {
+		var batch []struct {
+			SHA     string `json:"sha"`
+			HTMLURL string `json:"html_url"`
+			Commit  struct {
+				Message string `json:"message"`
+				Author  struct {
+					Name string    `json:"name"`
+					Date time.Time `json:"date"`
+				} `json:"author"`
+			} `json:"commit"`
+			Author *struct {
+				Login string `json:"login"`
+			} `json:"author"`
+		}
+		endpoint := fmt.Sprintf("repos/%s/%s/commits?since=%s&per_page=100&page=%d", owner, name, since.UTC().Format(time.RFC3339), page)
+		if err := c.getJSON(endpoint, &batch); err != nil {
+			return nil, fmt.Errorf("failed to list commits: %w", err)
+		}
+		for _, ghCommit := range batch {
+			commit := &Commit{
+				SHA:         ghCommit.SHA,
+				AuthorName:  ghCommit.Commit.Author.Name,
+				Message:     ghCommit.Commit.Message,
+				HTMLURL:     ghCommit.HTMLURL,
+				CommittedAt: ghCommit.Commit.Author.Date,
+			}
+			if ghCommit.Author != nil {
+				commit.AuthorLogin = ghCommit.Author.Login
+			}
+			commits = append(commits, commit)
+		}
+		if len(batch) < 100 {
+			break
+		}
+	}
+	if len(commits) > limit {
+		commits = commits[:limit]
+	}
+	return commits, nil
+}
+
+// ListDependabotAlerts lists the open Dependabot alerts of a repository, at most 100
+func (c *Client) ListDependabotAlerts(owner, name string) ([]*SecurityAlert, error) {
+	var ghAlerts []struct {
+		Number           int       `json:"number"`
+		HTMLURL          string    `json:"html_url"`
+		CreatedAt        time.Time `json:"created_at"`
+		SecurityAdvisory struct {
+			Summary  string `json:"summary"`
+			Severity string `json:"severity"`
+		} `json:"security_advisory"`
+		Dependency struct {
+			Package struct {
+				Name string `json:"name"`
+			} `json:"package"`
+			ManifestPath string `json:"manifest_path"`
+		} `json:"dependency"`
+	}
+	endpoint := fmt.Sprintf("repos/%s/%s/dependabot/alerts?state=open&per_page=100", owner, name)
+	if err := c.getJSON(endpoint, &ghAlerts); err != nil {
+		return nil, fmt.Errorf("failed to list Dependabot alerts: %w", err)
+	}
+
+	alerts := make([]*SecurityAlert, 0, len(ghAlerts))
+	for _, a := range ghAlerts {
+		alerts = append(alerts, &SecurityAlert{
+			Kind:      AlertKindDependabot,
+			Number:    a.Number,
+			Severity:  strings.ToLower(a.SecurityAdvisory.Severity),
+			Summary:   a.SecurityAdvisory.Summary,
+			Package:   a.Dependency.Package.Name,
+			Path:      a.Dependency.ManifestPath,
+			HTMLURL:   a.HTMLURL,
+			CreatedAt: a.CreatedAt,
+		})
+	}
+	return alerts, nil
+}
+
+// ListCodeScanningAlerts lists the open code scanning alerts of a repository, at most 100.
+// Alerts of rules without a security severity get one from their rule severity.
+func (c *Client) ListCodeScanningAlerts(owner, name string) ([]*SecurityAlert, error) {
+	var ghAlerts []struct {
+		Number    int       `json:"number"`
+		HTMLURL   string    `json:"html_url"`
+		CreatedAt time.Time `json:"created_at"`
+		Rule      struct {
+			ID                    string `json:"id"`
+			Severity              string `json:"severity"`
+			SecuritySeverityLevel string `json:"security_severity_level"`
+			Description           string `json:"description"`
+		} `json:"rule"`
+		MostRecentInstance struct {
+			Location struct {
+				Path string `json:"path"`
+			} `json:"location"`
+		} `json:"most_recent_instance"`
+	}
+	endpoint := fmt.Sprintf("repos/%s/%s/code-scanning/alerts?state=open&per_page=100", owner, name)
+	if err := c.getJSON(endpoint, &ghAlerts); err != nil {
+		return nil, fmt.Errorf("failed to list code scanning alerts: %w", err)
+	}
+
+	alerts := make([]*SecurityAlert, 0, len(ghAlerts))
+	for _, a := range ghAlerts {
+		severity := strings.ToLower(a.Rule.SecuritySeverityLevel)
+		if severity == "" {
+			switch a.Rule.Severity {
+			case "error":
+				severity = "high"
+			case "warning":
+				severity = "medium"
+			default:
+				severity = "low"
+			}
+		}
+		alerts = append(alerts, &SecurityAlert{
+			Kind:      AlertKindCodeScanning,
+			Number:    a.Number,
+			Severity:  severity,
+			Summary:   a.Rule.Description,
+			Rule:      a.Rule.ID,
+			Path:      a.MostRecentInstance.Location.Path,
+			HTMLURL:   a.HTMLURL,
+			CreatedAt: a.CreatedAt,
+		})
+	}
+	return alerts, nil
+}
+
+// GetPullRequestDiff gets the changes of a pull request as a unified diff ("diff") or as a series
+// of patches ("patch"), using the media types of the pull request endpoint
+func (c *Client) GetPullRequestDiff(owner, name string, number int, format string) (string, error) {
+	cmd := c.command("api", "-H", "Accept: application/vnd.github."+format, fmt.Sprintf("repos/%s/%s/pulls/%d", owner, name, number))
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := c.run(cmd); err != nil {
+		return "", fmt.Errorf("failed to get %s of pull request #%d: %w, stderr: %s", format, number, err, stderr.String())
+	}
+	return stdout.String(), nil
+}
+
+// CreatePullRequest opens a pull request in a repository. The state is returned in upper case like
+// the states of ListPullRequests.
+func (c *Client) CreatePullRequest(owner, name string, create *PullRequestCreate) (*PullRequest, error) {
+	base := create.Base
+	if base == "" {
+		var ghRepo struct {
+			DefaultBranch string `json:"default_branch"`
+		}
+		if err := c.getJSON(fmt.Sprintf("repos/%s/%s", owner, name), &ghRepo); err != nil {
+			return nil, fmt.Errorf("failed to get default branch: %w", err)
+		}
+		base = ghRepo.DefaultBranch
+	}
+	fields := map[string]string{"title": create.Title, "head": create.Head, "base": base}
+	if create.Body != "" {
+		fields["body"] = create.Body
+	}
+
+	var pr PullRequest
+	if err := c.sendJSON("POST", fmt.Sprintf("repos/%s/%s/pulls", owner, name), fields, &pr); err != nil {
+		return nil, fmt.Errorf("failed to create pull request: %w", err)
+	}
+	pr.State = strings.ToUpper(pr.State)
+	return &pr, nil
+}
+
+// UpdateIssue sets the state, assignees or milestone of an issue or pull request, returning the
+// issue as updated. GitHub silently leaves out assignees lacking access to the repository.
+func (c *Client) UpdateIssue(owner, name string, number int, update *IssueUpdate) (*Issue, error) {
+	body := make(map[string]interface{})
+	if update.State != "" {
+		body["state"] = update.State
+	}
+	if update.Assignees != nil {
+		body["assignees"] = update.Assignees
+	}
+	if update.Milestone != nil {
+		if *update.Milestone == 0 {
+			body["milestone"] = nil
+		} else {
+			body["milestone"] = *update.Milestone
+		}
+	}
+
+	var issue Issue
+	if err := c.sendBody("PATCH", fmt.Sprintf("repos/%s/%s/issues/%d", owner, name, number), body, &issue); err != nil {
+		return nil, fmt.Errorf("failed to update #%d: %w", number, err)
+	}
+	issue.State = strings.ToUpper(issue.State)
+	return &issue, nil
+}
+
+// AddLabels adds labels to an issue or pull request, keeping its other labels
+func (c *Client) AddLabels(owner, name string, number int, labels []string) error {
+	var added []Label
+	endpoint := fmt.Sprintf("repos/%s/%s/issues/%d/labels", owner, name, number)
+	if err := c.sendBody("POST", endpoint, map[string][]string{"labels": labels}, &added); err != nil {
+		return fmt.Errorf("failed to label #%d: %w", number, err)
+	}
+	return nil
+}
+
+// CreateComment comments on an issue or pull request
+func (c *Client) CreateComment(owner, name string, number int, body string) error {
+	var comment struct {
+		ID int64 `json:"id"`
+	}
+	endpoint := fmt.Sprintf("repos/%s/%s/issues/%d/comments", owner, name, number)
+	if err := c.sendJSON("POST", endpoint, map[string]string{"body": body}, &comment); err != nil {
+		return fmt.Errorf("failed to comment on #%d: %w", number, err)
+	}
+	return nil
+}
+
+// GetRepositorySettings gets the merge settings of a repository and the protection of its
+// default branch. Reading protection rules needs admin access to the repository.
+func (c *Client) GetRepositorySettings(owner, name string) (*RepositorySettings, error) {
+	var ghRepo struct {
+		DefaultBranch       string `json:"default_branch"`
+		AllowMergeCommit    bool   `json:"allow_merge_commit"`
+		AllowSquashMerge    bool   `json:"allow_squash_merge"`
+		AllowRebaseMerge    bool   `json:"allow_rebase_merge"`
+		DeleteBranchOnMerge bool   `json:"delete_branch_on_merge"`
+	}
+	if err := c.getJSON(fmt.Sprintf("repos/%s/%s", owner, name), &ghRepo); err != nil {
+		return nil, fmt.Errorf("failed to get repository settings: %w", err)
+	}
+	settings := &RepositorySettings{
+		DefaultBranch:       ghRepo.DefaultBranch,
+		AllowMergeCommit:    ghRepo.AllowMergeCommit,
+		AllowSquashMerge:    ghRepo.AllowSquashMerge,
+		AllowRebaseMerge:    ghRepo.AllowRebaseMerge,
+		DeleteBranchOnMerge: ghRepo.DeleteBranchOnMerge,
+	}
+
+	var protection struct {
+		RequiredPullRequestReviews *struct {
+			RequiredApprovingReviewCount int `json:"required_approving_review_count"`
+		} `json:"required_pull_request_reviews"`
+		RequiredStatusChecks *struct {
+			Contexts []string `json:"contexts"`
+		} `json:"required_status_checks"`
+		EnforceAdmins struct {
+			Enabled bool `json:"enabled"`
+		} `json:"enforce_admins"`
+		AllowForcePushes struct {
+			Enabled bool `json:"enabled"`
+		} `json:"allow_force_pushes"`
+		RequiredLinearHistory struct {
+			Enabled bool `json:"enabled"`
+		} `json:"required_linear_history"`
+	}
+	endpoint := fmt.Sprintf("repos/%s/%s/branches/%s/protection", owner, name, url.PathEscape(settings.DefaultBranch))
+	if err := c.getJSON(endpoint, &protection); err != nil {
+		if strings.Contains(err.Error(), "Branch not protected") {
+			return settings, nil
+		}
+		return nil, fmt.Errorf("failed to get branch protection: %w", err)
+	}
+	settings.Protected = true
+	if reviews := protection.RequiredPullRequestReviews; reviews != nil {
+		settings.RequiredApprovals = reviews.RequiredApprovingReviewCount
+	}
+	if checks := protection.RequiredStatusChecks; checks != nil {
+		settings.RequiredStatusChecks = checks.Contexts
+		if settings.RequiredStatusChecks == nil {
+			settings.RequiredStatusChecks = []string{}
+		}
+	}
+	settings.EnforceAdmins = protection.EnforceAdmins.Enabled
+	settings.AllowForcePushes = protection.AllowForcePushes.Enabled
+	settings.RequireLinearHistory = protection.RequiredLinearHistory.Enabled
+	return settings, nil
+}
+
+// ListLabels lists the labels of a repository
+func (c *Client) ListLabels(owner, name string) ([]*Label, error) {
+	var labels []*Label
+	for page := 1; ; page++ {
+		var batch []*Label
+		if err := c.getJSON(fmt.Sprintf("repos/%s/%s/labels?per_page=100&page=%d", owner, name, page), &batch); err != nil {
+			return nil, fmt.Errorf("failed to list labels: %w", err)
+		}
+		labels = append(labels, batch...)
+		if len(batch) < 100 {
+			return labels, nil
+		}
+	}
+}
+
+// UpdateLabel renames or recolors a label of a repository
+func (c *Client) UpdateLabel(owner, name, label string, update *LabelUpdate) (*Label, error) {
+	fields := make(map[string]string)
+	if update.NewName != "" {
+		fields["new_name"] = update.NewName
+	}
+	if update.Color != "" {
+		fields["color"] = update.Color
+	}
+
+	var updated Label
+	endpoint := fmt.Sprintf("repos/%s/%s/labels/%s", owner, name, url.PathEscape(label))
+	if err := c.sendJSON("PATCH", endpoint, fields, &updated); err != nil {
+		return nil, fmt.Errorf("failed to update label: %w", err)
+	}
+	return &updated, nil
+}
+
+// ListIssueTemplates lists the issue templates of a repository, with at most 20 labels each
+func (c *Client) ListIssueTemplates(owner, name string) ([]*IssueTemplate, error) {
+	query := `query($owner: String!, $name: String!) { repository(owner: $owner, name: $name) {
+		issueTemplates { name about title filename labels(first: 20) { nodes { name } } } } }`
+
+	var response struct {
+		Data struct {
+			Repository struct {
+				IssueTemplates []struct {
+					Name     string `json:"name"`
+					About    string `json:"about"`
+					Title    string `json:"title"`
+					Filename string `json:"filename"`
+					Labels   *struct {
+						Nodes []struct {
+							Name string `json:"name"`
+						} `json:"nodes"`
+					} `json:"labels"`
+				} `json:"issueTemplates"`
+			} `json:"repository"`
+		} `json:"data"`
+	}
+	if err := c.graphQL(query, map[string]string{"owner": owner, "name": name}, nil, &response); err != nil {
+		return nil, fmt.Errorf("failed to list issue templates: %w", err)
+	}
+
+	templates := make([]*IssueTemplate, 0, len(response.Data.Repository.IssueTemplates))
+	for _, node := range response.Data.Repository.IssueTemplates {
+		template := &IssueTemplate{Name: node.Name, About: node.About, Title: node.Title, Filename: node.Filename}
+		if node.Labels != nil {
+			for _, label := range node.Labels.Nodes {
+				template.Labels = append(template.Labels, label.Name)
+			}
+		}
+		templates = append(templates, template)
+	}
+	return templates, nil
+}
+
+// ListDiscussions lists the most recently updated discussions of a repository, at most limit.
+// Repositories without discussions enabled have none.
+func (c *Client) ListDiscussions(owner, name string, limit int) ([]*Discussion, error) {
+	query := `query($owner: String!, $name: String!, $first: Int!, $after: String) { repository(owner: $owner, name: $name) {
+		discussions(first: $first, after: $after, orderBy: {field: UPDATED_AT, direction: DESC}) {
+		nodes { number title url closed isAnswered upvoteCount createdAt updatedAt author { login }
+		category { name isAnswerable } comments { totalCount } } pageInfo { hasNextPage endCursor } } } }`
+
+	var discussions []*Discussion
+	after := ""
+	for len(discussions) < limit {
+		first := limit - len(discussions)
+		if first > 100 {
+			first = 100
+		}
+		variables := map[string]string{"owner": owner, "name": name}
+		if after != "" {
+			variables["after"] = after
+		}
+		var response struct {
+			Data struct {
+				Repository struct {
+					Discussions struct {
+						Nodes []struct {
+							Number      int       `json:"number"`
+							Title       string    `json:"title"`
+							URL         string    `json:"url"`
+							Closed      bool      `json:"closed"`
+							IsAnswered  bool      `json:"isAnswered"`
+							UpvoteCount int       `json:"upvoteCount"`
+							CreatedAt   time.Time `json:"createdAt"`
+							UpdatedAt   time.Time `json:"updatedAt"`
+							Author      *struct {
+								Login string `json:"login"`
+							} `json:"author"`
+							Category struct {
+								Name         string `json:"name"`
+								IsAnswerable bool   `json:"isAnswerable"`
+							} `json:"category"`
+							Comments struct {
+								TotalCount int `json:"totalCount"`
+							} `json:"comments"`
+						} `json:"nodes"`
+						PageInfo struct {
+							HasNextPage bool   `json:"hasNextPage"`
+							EndCursor   string `json:"endCursor"`
+						} `json:"pageInfo"`
+					} `json:"discussions"`
+				} `json:"repository"`
+			} `json:"data"`
+		}
+		if err := c.graphQL(query, variables, map[string]int{"first": first}, &response); err != nil {
+			return nil, fmt.Errorf("failed to list discussions: %w", err)
+		}
+
+		page := response.Data.Repository.Discussions
+		for _, node := range page.Nodes {
+			discussion := &Discussion{
+				Number:     node.Number,
+				Title:      node.Title,
+				Category:   node.Category.Name,
+				Answerable: node.Category.IsAnswerable,
+				Answered:   node.IsAnswered,
+				Closed:     node.Closed,
+				Comments:   node.Comments.TotalCount,
+				Upvotes:    node.UpvoteCount,
+				URL:        node.URL,
+				CreatedAt:  node.CreatedAt,
+				UpdatedAt:  node.UpdatedAt,
+			}
+			// Discussions of deleted accounts have no author
+			if node.Author != nil {
+				discussion.AuthorLogin = node.Author.Login
+			}
+			discussions = append(discussions, discussion)
+		}
+		if !page.PageInfo.HasNextPage {
+			break
+		}
+		after = page.PageInfo.EndCursor
+	}
+	return discussions, nil
+}
+
+// ListProjects lists the projects linked to a repository, at most 20, with the options of
+// their Status field. Reading projects needs the read:project scope.
+func (c *Client) ListProjects(owner, name string) ([]*Project, error) {
+	query := `query($owner: String!, $name: String!) { repository(owner: $owner, name: $name) {
+		projectsV2(first: 20) { nodes { number title url closed owner { ... on Organization { login } ... on User { login } }
+		field(name: "Status") { ... on ProjectV2SingleSelectField { options { name } } } } } } }`
+
+	var response struct {
+		Data struct {
+			Repository struct {
+				ProjectsV2 struct {
+					Nodes []struct {
+						Number int    `json:"number"`
+						Title  string `json:"title"`
+						URL    string `json:"url"`
+						Closed bool   `json:"closed"`
+						Owner  struct {
+							Login string `json:"login"`
+						} `json:"owner"`
+						Field *struct {
+							Options []struct {
+								Name string `json:"name"`
+							} `json:"options"`
+						} `json:"field"`
+					} `json:"nodes"`
+				} `json:"projectsV2"`
+			} `json:"repository"`
+		} `json:"data"`
+	}
+	if err := c.graphQL(query, map[string]string{"owner": owner, "name": name}, nil, &response); err != nil {
+		return nil, fmt.Errorf("failed to list projects: %w", err)
+	}
+
+	nodes := response.Data.Repository.ProjectsV2.Nodes
+	projects := make([]*Project, 0, len(nodes))
+	for _, node := range nodes {
+		project := &Project{Owner: node.Owner.Login, Number: node.Number, Title: node.Title, URL: node.URL, Closed: node.Closed}
+		if node.Field != nil {
+			for _, option := range node.Field.Options {
+				project.Columns = append(project.Columns, option.Name)
+			}
+		}
+		projects = append(projects, project)
+	}
+	return projects, nil
+}
+
+// ListProjectItems lists the project items of the most recently updated issues and pull requests
+// of a repository, at most limit (up to 100) of each and 10 projects per issue or pull request
+func (c *Client) ListProjectItems(owner, name string, limit int) ([]*ProjectItem, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 100
+	}
+	const content = `nodes { number title url projectItems(first: 10) { nodes {
+		project { number owner { ... on Organization { login } ... on User { login } } }
+		fieldValueByName(name: "Status") { ... on ProjectV2ItemFieldSingleSelectValue { name } } } } }`
+	query := fmt.Sprintf(`query($owner: String!, $name: String!) { repository(owner: $owner, name: $name) {
+		issues(first: %d, orderBy: {field: UPDATED_AT, direction: DESC}) { %s }
+		pullRequests(first: %d, orderBy: {field: UPDATED_AT, direction: DESC}) { %s } } }`, limit, content, limit, content)
+
+	type contentNodes struct {
+		Nodes []struct {
+			Number       int    `json:"number"`
+			Title        string `json:"title"`
+			URL          string `json:"url"`
+			ProjectItems struct {
+				Nodes []struct {
+					Project struct {
+						Number int `json:"number"`
+						Owner  struct {
+							Login string `json:"login"`
+						} `json:"owner"`
+					} `json:"project"`
+					FieldValueByName *struct {
+						Name string `json:"name"`
+					} `json:"fieldValueByName"`
+				} `json:"nodes"`
+			} `json:"projectItems"`
+		} `json:"nodes"`
+	}
+	var response struct {
+		Data struct {
+			Repository struct {
+				Issues       contentNodes `json:"issues"`
+				PullRequests contentNodes `json:"pullRequests"`
+			} `json:"repository"`
+		} `json:"data"`
+	}
+	if err := c.graphQL(query, map[string]string{"owner": owner, "name": name}, nil, &response); err != nil {
+		return nil, fmt.Errorf("failed to list project items: %w", err)
+	}
+
+	var items []*ProjectItem
+	add := func(nodes contentNodes, pullRequest bool) {
+		for _, node := range nodes.Nodes {
+			for _, projectItem := range node.ProjectItems.Nodes {
+				item := &ProjectItem{
+					ProjectOwner:  projectItem.Project.Owner.Login,
+					ProjectNumber: projectItem.Project.Number,
+					PullRequest:   pullRequest,
+					Number:        node.Number,
+					Title:         node.Title,
+					URL:           node.URL,
+				}
+				if projectItem.FieldValueByName != nil {
+					item.Status = projectItem.FieldValueByName.Name
+				}
+				items = append(items, item)
+			}
+		}
+	}
+	add(response.Data.Repository.Issues, false)
+	add(response.Data.Repository.PullRequests, true)
+	return items, nil
+}
+
+// GetCodeOwners gets the CODEOWNERS file of a repository from the first of the locations GitHub
+// reads it from, or "" when the repository has none
+func (c *Client) GetCodeOwners(owner, name string) (string, error) {
+	for _, path := range []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"} {
+		var file struct {
+			Content  string `json:"content"`
+			Encoding string `json:"encoding"`
+		}
+		if err := c.getJSON(fmt.Sprintf("repos/%s/%s/contents/%s", owner, name, path), &file); err != nil {
+			if strings.Contains(err.Error(), "HTTP 404") {
+				continue
+			}
+			return "", fmt.Errorf("failed to get %s: %w", path, err)
+		}
+		if file.Encoding != "base64" {
+			return file.Content, nil
+		}
+		content, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(file.Content, "\n", ""))
+		if err != nil {
+			return "", fmt.Errorf("failed to decode %s: %w", path, err)
+		}
+		return string(content), nil
+	}
+	return "", nil
+}
+
+// getJSON fetches a REST API endpoint with gh api and decodes its response into v
+func (c *Client) getJSON(endpoint string, v interface{}) error {
+	cmd := c.command("api", endpoint)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := c.run(cmd); err != nil {
+		return fmt.Errorf("%w, stderr: %s", err, stderr.String())
+	}
+	if err := json.Unmarshal(stdout.Bytes(), v); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
+
+// sendJSON sends a request with string fields to the REST API and decodes the response
+func (c *Client) sendJSON(method, endpoint string, fields map[string]string, v interface{}) error {
+	args := []string{"api", "--method", method, endpoint}
+	for key, value := range fields {
+		args = append(args, "-f", key+"="+value)
+	}
+	cmd := c.command(args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := c.run(cmd); err != nil {
+		return fmt.Errorf("%w, stderr: %s", err, stderr.String())
+	}
+	if err := json.Unmarshal(stdout.Bytes(), v); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
+
+// sendBody sends a request with a JSON body to the REST API and decodes the response, for bodies
+// sendJSON can't express such as arrays and nulls
+func (c *Client) sendBody(method, endpoint string, body interface{}, v interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	cmd := c.command("api", "--method", method, endpoint, "--input", "-")
+	cmd.Stdin = bytes.NewReader(data)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := c.run(cmd); err != nil {
+		return fmt.Errorf("%w, stderr: %s", err, stderr.String())
+	}
+	if err := json.Unmarshal(stdout.Bytes(), v); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
+
+// graphQL runs a GraphQL query with string and number variables and decodes the response
+func (c *Client) graphQL(query string, variables map[string]string, numbers map[string]int, v interface{}) error {
+	args := []string{"api", "graphql", "-f", "query=" + query}
+	for key, value := range variables {
+		args = append(args, "-f", key+"="+value)
+	}
+	for key, value := range numbers {
+		args = append(args, "-F", fmt.Sprintf("%s=%d", key, value))
+	}
+	cmd := c.command(args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := c.run(cmd); err != nil {
+		return fmt.Errorf("%w, stderr: %s", err, stderr.String())
+	}
+	if err := json.Unmarshal(stdout.Bytes(), v); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
+
+// parseOptionalTime parses an RFC3339 timestamp that gh reports as empty or zero when unset
+func parseOptionalTime(s string) *time.Time {
+	if s == "" {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil || t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
 // Helper function to truncate a string
 func truncate(s string, maxLen int) string {
 	if len(s) <= maxLen {
@@ -308,18 +1340,40 @@ func truncate(s string, maxLen int) string {
 	return s[:maxLen-3] + "..."
 }
 
-// GetRateLimit gets the current GitHub API rate limit
+// GetRateLimit gets the current GitHub API rate limit. With several tokens configured,
+// the limits of all tokens are summed and the earliest reset is reported.
 func (c *Client) GetRateLimit() (*RateLimit, error) {
-	// Build the command
-	args := []string{"api", "rate_limit"}
+	if c.tokens == nil {
+		return c.getRateLimit(exec.Command("gh", "api", "rate_limit"))
+	}
 
-	// Execute the command
-	cmd := exec.Command("gh", args...)
+	total := &RateLimit{}
+	for _, token := range c.tokens.all() {
+		cmd := exec.Command("gh", "api", "rate_limit")
+		withToken(cmd, token)
+		rateLimit, err := c.getRateLimit(cmd)
+		if err != nil {
+			return nil, fmt.Errorf("token %s: %w", maskToken(token), err)
+		}
+		c.tokens.update(token, rateLimit)
+
+		total.Limit += rateLimit.Limit
+		total.Remaining += rateLimit.Remaining
+		if total.Reset == 0 || rateLimit.Reset < total.Reset {
+			total.Reset = rateLimit.Reset
+		}
+	}
+	total.ResetTime = time.Unix(total.Reset, 0)
+	return total, nil
+}
+
+// getRateLimit runs a rate limit query. Checking the rate limit does not count against it.
+func (c *Client) getRateLimit(cmd *exec.Cmd) (*RateLimit, error) {
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
-	if err := cmd.Run(); err != nil {
+	if err := c.pool.run(cmd); err != nil {
 		return nil, fmt.Errorf("failed to get rate limit: %w, stderr: %s", err, stderr.String())
 	}
 
@@ -227,3 +227,94 @@ func TestTruncate(t *testing.T) {
 		})
 	}
 }
+
+// TestParseOptionalTime tests the parseOptionalTime function
+func TestParseOptionalTime(t *testing.T) {
+	if got := parseOptionalTime(""); got != nil {
+		t.Errorf("parseOptionalTime(\"\") = %v, want nil", got)
+	}
+	if got := parseOptionalTime("0001-01-01T00:00:00Z"); got != nil {
+		t.Errorf("parseOptionalTime(zero) = %v, want nil", got)
+	}
+	got := parseOptionalTime("2024-01-02T03:04:05Z")
+	if got == nil || got.Year() != 2024 || got.Day() != 2 {
+		t.Errorf("parseOptionalTime() = %v, want 2024-01-02T03:04:05Z", got)
+	}
+}
+
+// TestIssueIsPullRequest tests telling pull requests apart in issue listings
+func TestIssueIsPullRequest(t *testing.T) {
+	tests := []struct {
+		name  string
+		issue Issue
+		want  bool
+	}{
+		{name: "Issue", issue: Issue{HTMLURL: "https://github.com/pingcap/tidb/issues/1"}, want: false},
+		{name: "Pull request URL", issue: Issue{HTMLURL: "https://github.com/pingcap/tidb/pull/2"}, want: true},
+		{name: "REST pull request field", issue: Issue{PullRequest: &IssuePullRequest{URL: "https://api.github.com/repos/pingcap/tidb/pulls/3"}}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.issue.IsPullRequest(); got != tt.want {
+				t.Errorf("IsPullRequest() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestUserBot tests bot detection from the is_bot flag and the login
+func TestUserBot(t *testing.T) {
+	tests := []struct {
+		user User
+		want bool
+	}{
+		{User{Login: "alice"}, false},
+		{User{Login: "app/dependabot", IsBot: true}, true},
+		{User{Login: "dependabot[bot]"}, true},
+		{User{Login: "app/renovate"}, true},
+	}
+
+	for _, tt := range tests {
+		if got := tt.user.Bot(); got != tt.want {
+			t.Errorf("User{%q}.Bot() = %v, want %v", tt.user.Login, got, tt.want)
+		}
+	}
+}
+
+// TestSplitResponse tests parsing the headers and body printed by gh api --include
+func TestSplitResponse(t *testing.T) {
+	output := "HTTP/2.0 200 OK\r\nContent-Type: application/json\r\nX-Oauth-Scopes: gist, read:org, repo\r\n\r\n{\"login\":\"octocat\"}"
+
+	headers, body := splitResponse(output)
+	if got := headers["x-oauth-scopes"]; got != "gist, read:org, repo" {
+		t.Errorf("x-oauth-scopes = %q", got)
+	}
+	if body != `{"login":"octocat"}` {
+		t.Errorf("body = %q", body)
+	}
+
+	scopes := parseScopes(headers["x-oauth-scopes"])
+	if len(scopes) != 3 || scopes[1] != "read:org" {
+		t.Errorf("parseScopes() = %v", scopes)
+	}
+}
+
+// TestMissingScopes tests detection of missing token scopes
+func TestMissingScopes(t *testing.T) {
+	tests := []struct {
+		scopes []string
+		want   int
+	}{
+		{[]string{"repo", "read:org"}, 0},
+		{[]string{"repo", "admin:org"}, 0},
+		{[]string{"repo"}, 1},
+		{nil, 2},
+	}
+
+	for _, tt := range tests {
+		if got := missingScopes(tt.scopes); len(got) != tt.want {
+			t.Errorf("missingScopes(%v) = %v, want %d missing", tt.scopes, got, tt.want)
+		}
+	}
+}
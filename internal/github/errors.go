@@ -0,0 +1,31 @@
+package github
+
+import "strings"
+
+// gh reports failures as text on stderr, which the client's errors include. These helpers classify
+// those errors for callers that react differently to each kind.
+
+// IsRateLimited reports whether a gh call failed because the rate limit was exhausted
+func IsRateLimited(err error) bool {
+	return err != nil && isRateLimited(err.Error())
+}
+
+// IsAuthFailure reports whether a gh call failed because gh is not logged in or its token was rejected
+func IsAuthFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	message := strings.ToLower(err.Error())
+	return strings.Contains(message, "authentication failed") || strings.Contains(message, "bad credentials") ||
+		strings.Contains(message, "(http 401)") || strings.Contains(message, "gh auth login")
+}
+
+// IsNotFound reports whether a gh call failed because the repository or item does not exist or
+// isn't visible to the token
+func IsNotFound(err error) bool {
+	if err == nil {
+		return false
+	}
+	message := strings.ToLower(err.Error())
+	return strings.Contains(message, "(http 404)") || strings.Contains(message, "could not resolve to")
+}
@@ -0,0 +1,36 @@
+package github
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestErrorClassification(t *testing.T) {
+	tests := []struct {
+		stderr                          string
+		rateLimited, authFailed, absent bool
+	}{
+		{stderr: "gh: API rate limit exceeded for user ID 1. (HTTP 403)", rateLimited: true},
+		{stderr: "gh: You have exceeded a secondary rate limit. (HTTP 403)", rateLimited: true},
+		{stderr: "gh: Bad credentials (HTTP 401)", authFailed: true},
+		{stderr: "To get started with GitHub CLI, please run:  gh auth login", authFailed: true},
+		{stderr: "gh: Not Found (HTTP 404)", absent: true},
+		{stderr: "GraphQL: Could not resolve to a Repository with the name 'org/missing'. (repository)", absent: true},
+		{stderr: "gh: Validation Failed (HTTP 422)"},
+	}
+	for _, tt := range tests {
+		err := errors.New("failed to get repository: exit status 1, stderr: " + tt.stderr)
+		if got := IsRateLimited(err); got != tt.rateLimited {
+			t.Errorf("IsRateLimited(%q) = %t, want %t", tt.stderr, got, tt.rateLimited)
+		}
+		if got := IsAuthFailure(err); got != tt.authFailed {
+			t.Errorf("IsAuthFailure(%q) = %t, want %t", tt.stderr, got, tt.authFailed)
+		}
+		if got := IsNotFound(err); got != tt.absent {
+			t.Errorf("IsNotFound(%q) = %t, want %t", tt.stderr, got, tt.absent)
+		}
+	}
+	if IsRateLimited(nil) || IsAuthFailure(nil) || IsNotFound(nil) {
+		t.Error("nil errors are classified")
+	}
+}
@@ -0,0 +1,669 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Fixture is the GitHub data of a repository served by a FixtureClient. In JSON the repository,
+// pull requests, issues, labels, milestones and releases have the field names of the GitHub REST
+// API and the rest their Go field names, as in:
+//
+//	{
+//	  "repository": {"owner": {"login": "org"}, "name": "api", "full_name": "org/api"},
+//	  "pull_requests": [{"number": 2, "title": "Add retries", "state": "OPEN", "user": {"login": "alice"}}],
+//	  "issues": [{"number": 1, "title": "Requests time out", "state": "OPEN", "labels": [{"name": "bug"}]}],
+//	  "diffs": {"2.diff": "diff --git a/client.go b/client.go\n..."}
+//	}
+type Fixture struct {
+	Repository         *Repository         `json:"repository"`
+	PullRequests       []*PullRequest      `json:"pull_requests,omitempty"` // Newest first, as gh lists them
+	Issues             []*Issue            `json:"issues,omitempty"`        // Newest first, as gh lists them
+	Labels             []*Label            `json:"labels,omitempty"`
+	Milestones         []*Milestone        `json:"milestones,omitempty"`
+	Releases           []*Release          `json:"releases,omitempty"`
+	Commits            []*Commit           `json:"commits,omitempty"`
+	DependabotAlerts   []*SecurityAlert    `json:"dependabot_alerts,omitempty"`
+	CodeScanningAlerts []*SecurityAlert    `json:"code_scanning_alerts,omitempty"`
+	IssueTemplates     []*IssueTemplate    `json:"issue_templates,omitempty"`
+	Discussions        []*Discussion       `json:"discussions,omitempty"`
+	Projects           []*Project          `json:"projects,omitempty"`
+	ProjectItems       []*ProjectItem      `json:"project_items,omitempty"`
+	CodeOwners         string              `json:"code_owners,omitempty"`
+	Settings           *RepositorySettings `json:"settings,omitempty"`
+	// Diffs of pull requests by number and format, as "12.diff" or "12.patch"
+	Diffs map[string]string `json:"diffs,omitempty"`
+	// Comments made with CreateComment, by issue or pull request number
+	Comments map[int][]string `json:"comments,omitempty"`
+}
+
+// ReadFixture reads a fixture from a JSON file
+func ReadFixture(path string) (*Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var fixture Fixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return nil, fmt.Errorf("failed to parse fixture %s: %w", path, err)
+	}
+	if fixture.Repository == nil || fixture.Repository.FullName == "" {
+		return nil, fmt.Errorf("fixture %s has no repository full_name", path)
+	}
+	return &fixture, nil
+}
+
+// WriteFixture writes a fixture to a JSON file, indented so that it can be reviewed and edited
+func WriteFixture(path string, fixture *Fixture) error {
+	data, err := json.MarshalIndent(fixture, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// FixtureClient serves GitHub data from fixtures instead of running gh, so that syncs can be
+// tested without gh or its credentials. Listing follows gh: pull requests and issues are open
+// unless another state is asked for, and limits cut lists short. Writes change the fixtures, so
+// later calls and tests see them. Repositories and items missing from the fixtures fail with
+// errors IsNotFound recognizes.
+type FixtureClient struct {
+	// Viewer is the login of the authenticated user, the author of the pull requests it creates
+	Viewer string
+
+	mu       sync.Mutex
+	fixtures map[string]*Fixture // By repository full name
+	calls    atomic.Int64
+}
+
+// Ensure FixtureClient implements ClientInterface
+var _ ClientInterface = (*FixtureClient)(nil)
+
+// NewFixtureClient creates a client serving the given fixtures
+func NewFixtureClient(fixtures ...*Fixture) *FixtureClient {
+	c := &FixtureClient{Viewer: "octocat", fixtures: make(map[string]*Fixture)}
+	for _, fixture := range fixtures {
+		c.Add(fixture)
+	}
+	return c
+}
+
+// LoadFixtures creates a client serving the fixtures of every .json file in a directory, which
+// must hold at least one
+func LoadFixtures(dir string) (*FixtureClient, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	c := NewFixtureClient()
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		fixture, err := ReadFixture(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		c.Add(fixture)
+	}
+	if len(c.fixtures) == 0 {
+		return nil, fmt.Errorf("no fixtures (.json files) in %s", dir)
+	}
+	return c, nil
+}
+
+// Add serves a fixture, replacing the one of the same repository
+func (c *FixtureClient) Add(fixture *Fixture) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.fixtures[fixture.Repository.FullName] = fixture
+}
+
+// Fixture returns the fixture of a repository as changed by writes, nil when there is none. It
+// must not be changed while the client is in use.
+func (c *FixtureClient) Fixture(owner, name string) *Fixture {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.fixtures[owner+"/"+name]
+}
+
+// fixture returns the fixture of a repository, counting the call; the caller must hold the lock
+func (c *FixtureClient) fixture(owner, name string) (*Fixture, error) {
+	c.calls.Add(1)
+	fixture, ok := c.fixtures[owner+"/"+name]
+	if !ok {
+		return nil, fmt.Errorf("no fixture for repository %s/%s (HTTP 404)", owner, name)
+	}
+	return fixture, nil
+}
+
+// GetRepository returns the repository of the fixture
+func (c *FixtureClient) GetRepository(owner, name string) (*Repository, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fixture, err := c.fixture(owner, name)
+	if err != nil {
+		return nil, err
+	}
+	repo := *fixture.Repository
+	return &repo, nil
+}
+
+// ListOrganizationRepositories lists the full names of the repositories owned by org, sorted
+func (c *FixtureClient) ListOrganizationRepositories(org string, limit int) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls.Add(1)
+
+	var names []string
+	for fullName, fixture := range c.fixtures {
+		if strings.EqualFold(fixture.Repository.Owner.Login, org) {
+			names = append(names, fullName)
+		}
+	}
+	sort.Strings(names)
+	if limit > 0 && len(names) > limit {
+		names = names[:limit]
+	}
+	return names, nil
+}
+
+// ListUserRepositories lists every repository of the fixtures, sorted by full name, whatever the relation
+func (c *FixtureClient) ListUserRepositories(relation string, limit int) ([]*Repository, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls.Add(1)
+
+	var repos []*Repository
+	for _, fixture := range c.fixtures {
+		repos = append(repos, fixture.Repository)
+	}
+	sort.Slice(repos, func(i, j int) bool { return repos[i].FullName < repos[j].FullName })
+	return copyLimited(repos, limit), nil
+}
+
+// ListPullRequests lists the pull requests of the fixture in the asked for state, open by default.
+// Reviews and files are left out unless asked for, as gh does.
+func (c *FixtureClient) ListPullRequests(owner, name string, options *PullRequestOptions) ([]*PullRequest, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fixture, err := c.fixture(owner, name)
+	if err != nil {
+		return nil, err
+	}
+	if options == nil {
+		options = &PullRequestOptions{}
+	}
+
+	prs := []*PullRequest{}
+	for _, pr := range fixture.PullRequests {
+		if options.PerPage > 0 && len(prs) == options.PerPage {
+			break
+		}
+		if !inState(pr.State, options.State) {
+			continue
+		}
+		copied := *pr
+		if !options.IncludeReviews {
+			copied.Reviews = nil
+		}
+		if !options.IncludeFiles {
+			copied.Files = nil
+		}
+		prs = append(prs, &copied)
+	}
+	return prs, nil
+}
+
+// ListIssues lists the issues of the fixture in the asked for state, open by default
+func (c *FixtureClient) ListIssues(owner, name string, options *IssueOptions) ([]*Issue, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fixture, err := c.fixture(owner, name)
+	if err != nil {
+		return nil, err
+	}
+	if options == nil {
+		options = &IssueOptions{}
+	}
+
+	issues := []*Issue{}
+	for _, issue := range fixture.Issues {
+		if options.PerPage > 0 && len(issues) == options.PerPage {
+			break
+		}
+		if issue.IsPullRequest() || !inState(issue.State, options.State) {
+			continue
+		}
+		copied := *issue
+		issues = append(issues, &copied)
+	}
+	return issues, nil
+}
+
+// inState reports whether an item state matches a gh --state value; closed includes merged
+func inState(state, want string) bool {
+	state = strings.ToLower(state)
+	switch strings.ToLower(want) {
+	case "all":
+		return true
+	case "", "open":
+		return state == "open"
+	case "closed":
+		return state == "closed" || state == "merged"
+	default:
+		return state == strings.ToLower(want)
+	}
+}
+
+// ListAuthorAssociations returns the author association of the pull requests and issues of the fixture that have one
+func (c *FixtureClient) ListAuthorAssociations(owner, name string, limit int) (map[int]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fixture, err := c.fixture(owner, name)
+	if err != nil {
+		return nil, err
+	}
+
+	associations := make(map[int]string)
+	for _, pr := range fixture.PullRequests {
+		if pr.AuthorAssociation != "" {
+			associations[pr.Number] = pr.AuthorAssociation
+		}
+	}
+	for _, issue := range fixture.Issues {
+		if issue.AuthorAssociation != "" {
+			associations[issue.Number] = issue.AuthorAssociation
+		}
+	}
+	return associations, nil
+}
+
+// ListMilestones lists the milestones of the fixture
+func (c *FixtureClient) ListMilestones(owner, name string) ([]*Milestone, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fixture, err := c.fixture(owner, name)
+	if err != nil {
+		return nil, err
+	}
+	return copyLimited(fixture.Milestones, 0), nil
+}
+
+// ListReleases lists the releases of the fixture
+func (c *FixtureClient) ListReleases(owner, name string, limit int) ([]*Release, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fixture, err := c.fixture(owner, name)
+	if err != nil {
+		return nil, err
+	}
+	return copyLimited(fixture.Releases, limit), nil
+}
+
+// ListDependabotAlerts lists the Dependabot alerts of the fixture
+func (c *FixtureClient) ListDependabotAlerts(owner, name string) ([]*SecurityAlert, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fixture, err := c.fixture(owner, name)
+	if err != nil {
+		return nil, err
+	}
+	return copyLimited(fixture.DependabotAlerts, 0), nil
+}
+
+// ListCodeScanningAlerts lists the code scanning alerts of the fixture
+func (c *FixtureClient) ListCodeScanningAlerts(owner, name string) ([]*SecurityAlert, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fixture, err := c.fixture(owner, name)
+	if err != nil {
+		return nil, err
+	}
+	return copyLimited(fixture.CodeScanningAlerts, 0), nil
+}
+
+// ListCommits lists the commits of the fixture made since a time, all of them when it is zero
+func (c *FixtureClient) ListCommits(owner, name string, since time.Time, limit int) ([]*Commit, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fixture, err := c.fixture(owner, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []*Commit
+	for _, commit := range fixture.Commits {
+		if since.IsZero() || !commit.CommittedAt.Before(since) {
+			commits = append(commits, commit)
+		}
+	}
+	return copyLimited(commits, limit), nil
+}
+
+// ListLabels lists the labels of the fixture
+func (c *FixtureClient) ListLabels(owner, name string) ([]*Label, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fixture, err := c.fixture(owner, name)
+	if err != nil {
+		return nil, err
+	}
+	return copyLimited(fixture.Labels, 0), nil
+}
+
+// UpdateLabel renames or recolors a label of the fixture, on its pull requests and issues too
+func (c *FixtureClient) UpdateLabel(owner, name, label string, update *LabelUpdate) (*Label, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fixture, err := c.fixture(owner, name)
+	if err != nil {
+		return nil, err
+	}
+
+	apply := func(l *Label) {
+		if update.NewName != "" {
+			l.Name = update.NewName
+		}
+		if update.Color != "" {
+			l.Color = update.Color
+		}
+	}
+	var updated *Label
+	for _, l := range fixture.Labels {
+		if l.Name == label {
+			apply(l)
+			copied := *l
+			updated = &copied
+		}
+	}
+	if updated == nil {
+		return nil, fmt.Errorf("no label %s in fixture %s/%s (HTTP 404)", label, owner, name)
+	}
+	for _, pr := range fixture.PullRequests {
+		for i := range pr.Labels {
+			if pr.Labels[i].Name == label {
+				apply(&pr.Labels[i])
+			}
+		}
+	}
+	for _, issue := range fixture.Issues {
+		for i := range issue.Labels {
+			if issue.Labels[i].Name == label {
+				apply(&issue.Labels[i])
+			}
+		}
+	}
+	return updated, nil
+}
+
+// ListIssueTemplates lists the issue templates of the fixture
+func (c *FixtureClient) ListIssueTemplates(owner, name string) ([]*IssueTemplate, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fixture, err := c.fixture(owner, name)
+	if err != nil {
+		return nil, err
+	}
+	return copyLimited(fixture.IssueTemplates, 0), nil
+}
+
+// ListDiscussions lists the discussions of the fixture
+func (c *FixtureClient) ListDiscussions(owner, name string, limit int) ([]*Discussion, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fixture, err := c.fixture(owner, name)
+	if err != nil {
+		return nil, err
+	}
+	return copyLimited(fixture.Discussions, limit), nil
+}
+
+// ListProjects lists the projects of the fixture
+func (c *FixtureClient) ListProjects(owner, name string) ([]*Project, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fixture, err := c.fixture(owner, name)
+	if err != nil {
+		return nil, err
+	}
+	return copyLimited(fixture.Projects, 0), nil
+}
+
+// ListProjectItems lists the project items of the fixture
+func (c *FixtureClient) ListProjectItems(owner, name string, limit int) ([]*ProjectItem, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fixture, err := c.fixture(owner, name)
+	if err != nil {
+		return nil, err
+	}
+	return copyLimited(fixture.ProjectItems, limit), nil
+}
+
+// GetCodeOwners returns the CODEOWNERS file of the fixture, "" when it has none
+func (c *FixtureClient) GetCodeOwners(owner, name string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fixture, err := c.fixture(owner, name)
+	if err != nil {
+		return "", err
+	}
+	return fixture.CodeOwners, nil
+}
+
+// GetPullRequestDiff returns the diff of the fixture for a pull request and format
+func (c *FixtureClient) GetPullRequestDiff(owner, name string, number int, format string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fixture, err := c.fixture(owner, name)
+	if err != nil {
+		return "", err
+	}
+	diff, ok := fixture.Diffs[strconv.Itoa(number)+"."+format]
+	if !ok {
+		return "", fmt.Errorf("no %s of pull request #%d in fixture %s/%s (HTTP 404)", format, number, owner, name)
+	}
+	return diff, nil
+}
+
+// CreatePullRequest adds an open pull request by the viewer to the fixture, numbered after its
+// pull requests and issues
+func (c *FixtureClient) CreatePullRequest(owner, name string, create *PullRequestCreate) (*PullRequest, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fixture, err := c.fixture(owner, name)
+	if err != nil {
+		return nil, err
+	}
+
+	number := 1
+	for _, pr := range fixture.PullRequests {
+		number = max(number, pr.Number+1)
+	}
+	for _, issue := range fixture.Issues {
+		number = max(number, issue.Number+1)
+	}
+	now := time.Now().UTC()
+	pr := &PullRequest{
+		Number:    number,
+		Title:     create.Title,
+		Body:      create.Body,
+		State:     "OPEN",
+		HTMLURL:   fmt.Sprintf("https://github.com/%s/%s/pull/%d", owner, name, number),
+		User:      User{Login: c.Viewer},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	fixture.PullRequests = append([]*PullRequest{pr}, fixture.PullRequests...)
+	copied := *pr
+	return &copied, nil
+}
+
+// UpdateIssue changes the state, assignees or milestone of an issue or pull request of the fixture
+func (c *FixtureClient) UpdateIssue(owner, name string, number int, update *IssueUpdate) (*Issue, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fixture, err := c.fixture(owner, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var milestone *Milestone
+	if update.Milestone != nil && *update.Milestone != 0 {
+		for _, m := range fixture.Milestones {
+			if m.Number == *update.Milestone {
+				milestone = m
+			}
+		}
+		if milestone == nil {
+			return nil, fmt.Errorf("no milestone %d in fixture %s/%s (HTTP 404)", *update.Milestone, owner, name)
+		}
+	}
+	var assignees []User
+	for _, login := range update.Assignees {
+		assignees = append(assignees, User{Login: login})
+	}
+	now := time.Now().UTC()
+	apply := func(state *string, closedAt **time.Time, itemAssignees *[]User, itemMilestone **Milestone, updatedAt *time.Time) {
+		if update.State != "" {
+			*state = strings.ToUpper(update.State)
+			*closedAt = nil
+			if *state == "CLOSED" {
+				*closedAt = &now
+			}
+		}
+		if update.Assignees != nil {
+			*itemAssignees = assignees
+		}
+		if update.Milestone != nil {
+			*itemMilestone = milestone
+		}
+		*updatedAt = now
+	}
+
+	for _, issue := range fixture.Issues {
+		if issue.Number == number {
+			apply(&issue.State, &issue.ClosedAt, &issue.Assignees, &issue.Milestone, &issue.UpdatedAt)
+			copied := *issue
+			return &copied, nil
+		}
+	}
+	for _, pr := range fixture.PullRequests {
+		if pr.Number == number {
+			apply(&pr.State, &pr.ClosedAt, &pr.Assignees, &pr.Milestone, &pr.UpdatedAt)
+			return &Issue{
+				Number: pr.Number, Title: pr.Title, Body: pr.Body, State: pr.State, URL: pr.URL, HTMLURL: pr.HTMLURL,
+				User: pr.User, CreatedAt: pr.CreatedAt, UpdatedAt: pr.UpdatedAt, ClosedAt: pr.ClosedAt, Labels: pr.Labels,
+				Assignees: pr.Assignees, Milestone: pr.Milestone, PullRequest: &IssuePullRequest{HTMLURL: pr.HTMLURL},
+			}, nil
+		}
+	}
+	return nil, fmt.Errorf("no issue or pull request #%d in fixture %s/%s (HTTP 404)", number, owner, name)
+}
+
+// AddLabels adds labels to an issue or pull request of the fixture, taking their color from the
+// labels of the fixture
+func (c *FixtureClient) AddLabels(owner, name string, number int, labels []string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fixture, err := c.fixture(owner, name)
+	if err != nil {
+		return err
+	}
+
+	add := func(itemLabels *[]Label) {
+		for _, label := range labels {
+			present := false
+			for _, l := range *itemLabels {
+				present = present || l.Name == label
+			}
+			if present {
+				continue
+			}
+			added := Label{Name: label}
+			for _, l := range fixture.Labels {
+				if l.Name == label {
+					added = *l
+				}
+			}
+			*itemLabels = append(*itemLabels, added)
+		}
+	}
+	for _, issue := range fixture.Issues {
+		if issue.Number == number {
+			add(&issue.Labels)
+			return nil
+		}
+	}
+	for _, pr := range fixture.PullRequests {
+		if pr.Number == number {
+			add(&pr.Labels)
+			return nil
+		}
+	}
+	return fmt.Errorf("no issue or pull request #%d in fixture %s/%s (HTTP 404)", number, owner, name)
+}
+
+// CreateComment records a comment on an issue or pull request in the fixture's comments
+func (c *FixtureClient) CreateComment(owner, name string, number int, body string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fixture, err := c.fixture(owner, name)
+	if err != nil {
+		return err
+	}
+	if fixture.Comments == nil {
+		fixture.Comments = make(map[int][]string)
+	}
+	fixture.Comments[number] = append(fixture.Comments[number], body)
+	return nil
+}
+
+// GetRepositorySettings returns the settings of the fixture
+func (c *FixtureClient) GetRepositorySettings(owner, name string) (*RepositorySettings, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fixture, err := c.fixture(owner, name)
+	if err != nil {
+		return nil, err
+	}
+	if fixture.Settings == nil {
+		return nil, fmt.Errorf("no settings in fixture %s/%s (HTTP 404)", owner, name)
+	}
+	settings := *fixture.Settings
+	return &settings, nil
+}
+
+// GetRateLimit reports a rate limit that is never exhausted
+func (c *FixtureClient) GetRateLimit() (*RateLimit, error) {
+	c.calls.Add(1)
+	reset := time.Now().Add(time.Hour).Truncate(time.Second)
+	return &RateLimit{Limit: 5000, Remaining: 5000, Reset: reset.Unix(), ResetTime: reset}, nil
+}
+
+// PoolStats reports the calls made to the client, which runs no gh processes
+func (c *FixtureClient) PoolStats() PoolStats {
+	return PoolStats{Calls: c.calls.Load()}
+}
+
+// copyLimited returns copies of at most limit items, all of them when limit is not positive
+func copyLimited[T any](items []*T, limit int) []*T {
+	if limit > 0 && len(items) > limit {
+		items = items[:limit]
+	}
+	copied := make([]*T, len(items))
+	for i, item := range items {
+		value := *item
+		copied[i] = &value
+	}
+	return copied
+}
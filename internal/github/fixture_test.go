@@ -0,0 +1,75 @@
+package github
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestFixtureClient(t *testing.T) {
+	fixture := &Fixture{
+		Repository: &Repository{Owner: User{Login: "org"}, Name: "api", FullName: "org/api"},
+		PullRequests: []*PullRequest{
+			{Number: 3, State: "OPEN", Reviews: []Review{{State: "APPROVED"}}, Labels: []Label{{Name: "bug"}}},
+			{Number: 2, State: "MERGED"},
+		},
+		Issues: []*Issue{{Number: 1, State: "CLOSED", Labels: []Label{{Name: "bug"}}}},
+		Labels: []*Label{{Name: "bug", Color: "d73a4a"}},
+		Diffs:  map[string]string{"3.diff": "diff --git a/a.go b/a.go\n"},
+	}
+	path := filepath.Join(t.TempDir(), "org-api.json")
+	if err := WriteFixture(path, fixture); err != nil {
+		t.Fatalf("WriteFixture() error = %v", err)
+	}
+	c, err := LoadFixtures(filepath.Dir(path))
+	if err != nil {
+		t.Fatalf("LoadFixtures() error = %v", err)
+	}
+
+	// Listing follows gh: open by default, closed including merged, reviews only when asked for
+	for _, tt := range []struct {
+		state string
+		want  int
+	}{{"", 1}, {"closed", 1}, {"merged", 1}, {"all", 2}} {
+		if prs, _ := c.ListPullRequests("org", "api", &PullRequestOptions{State: tt.state}); len(prs) != tt.want {
+			t.Errorf("ListPullRequests(%q) = %d, want %d", tt.state, len(prs), tt.want)
+		}
+	}
+	prs, _ := c.ListPullRequests("org", "api", &PullRequestOptions{State: "all", PerPage: 1})
+	if len(prs) != 1 || prs[0].Reviews != nil {
+		t.Errorf("ListPullRequests() with a limit = %+v, want the first without reviews", prs)
+	}
+	if issues, _ := c.ListIssues("org", "api", nil); len(issues) != 0 {
+		t.Errorf("ListIssues() = %d, want no open issues", len(issues))
+	}
+	if diff, err := c.GetPullRequestDiff("org", "api", 3, "diff"); err != nil || diff == "" {
+		t.Errorf("GetPullRequestDiff() = %q, %v", diff, err)
+	}
+
+	// Writes change the fixture
+	if _, err := c.UpdateIssue("org", "api", 1, &IssueUpdate{State: "open", Assignees: []string{"alice"}}); err != nil {
+		t.Fatalf("UpdateIssue() error = %v", err)
+	}
+	if issues, _ := c.ListIssues("org", "api", nil); len(issues) != 1 || issues[0].Assignees[0].Login != "alice" {
+		t.Errorf("ListIssues() after reopening = %+v", issues)
+	}
+	if _, err := c.UpdateLabel("org", "api", "bug", &LabelUpdate{NewName: "kind/bug"}); err != nil {
+		t.Fatalf("UpdateLabel() error = %v", err)
+	}
+	if labels := c.Fixture("org", "api").Issues[0].Labels; labels[0].Name != "kind/bug" {
+		t.Errorf("issue labels = %v, want the label renamed", labels)
+	}
+	if err := c.CreateComment("org", "api", 3, "LGTM"); err != nil || len(c.Fixture("org", "api").Comments[3]) != 1 {
+		t.Errorf("CreateComment() error = %v, comments %v", err, c.Fixture("org", "api").Comments)
+	}
+
+	// Missing repositories and items are not found
+	if _, err := c.GetRepository("org", "missing"); !IsNotFound(err) {
+		t.Errorf("GetRepository(org/missing) error = %v, want not found", err)
+	}
+	if err := c.AddLabels("org", "api", 9, []string{"bug"}); !IsNotFound(err) {
+		t.Errorf("AddLabels(#9) error = %v, want not found", err)
+	}
+	if stats := c.PoolStats(); stats.Calls == 0 {
+		t.Error("PoolStats() should count the calls")
+	}
+}
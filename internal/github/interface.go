@@ -1,16 +1,87 @@
 package github
 
+import "time"
+
 // ClientInterface defines the interface for a GitHub client
 type ClientInterface interface {
 	// GetRepository gets information about a repository
 	GetRepository(owner, name string) (*Repository, error)
 
+	// ListOrganizationRepositories lists the full names of an organization's repositories
+	ListOrganizationRepositories(org string, limit int) ([]string, error)
+
+	// ListUserRepositories lists repositories the authenticated user is related to: those they own,
+	// star or contribute to
+	ListUserRepositories(relation string, limit int) ([]*Repository, error)
+
 	// ListPullRequests lists pull requests for a repository
 	ListPullRequests(owner, name string, options *PullRequestOptions) ([]*PullRequest, error)
 
 	// ListIssues lists issues for a repository
 	ListIssues(owner, name string, options *IssueOptions) ([]*Issue, error)
 
+	// ListAuthorAssociations maps recently updated issue and pull request numbers to their author associations
+	ListAuthorAssociations(owner, name string, limit int) (map[int]string, error)
+
+	// ListMilestones lists the open and closed milestones of a repository
+	ListMilestones(owner, name string) ([]*Milestone, error)
+
+	// ListReleases lists the newest releases of a repository, drafts included
+	ListReleases(owner, name string, limit int) ([]*Release, error)
+
+	// ListDependabotAlerts lists the open Dependabot alerts of a repository
+	ListDependabotAlerts(owner, name string) ([]*SecurityAlert, error)
+
+	// ListCodeScanningAlerts lists the open code scanning alerts of a repository
+	ListCodeScanningAlerts(owner, name string) ([]*SecurityAlert, error)
+
+	// ListCommits lists the commits of the default branch made since a time, newest first, at most limit
+	ListCommits(owner, name string, since time.Time, limit int) ([]*Commit, error)
+
+	// ListLabels lists the labels of a repository
+	ListLabels(owner, name string) ([]*Label, error)
+
+	// UpdateLabel renames or recolors a label of a repository
+	UpdateLabel(owner, name, label string, update *LabelUpdate) (*Label, error)
+
+	// ListIssueTemplates lists the issue templates of a repository
+	ListIssueTemplates(owner, name string) ([]*IssueTemplate, error)
+
+	// ListDiscussions lists the most recently updated discussions of a repository, at most limit
+	ListDiscussions(owner, name string, limit int) ([]*Discussion, error)
+
+	// ListProjects lists the projects linked to a repository
+	ListProjects(owner, name string) ([]*Project, error)
+
+	// ListProjectItems lists the project items of the most recently updated issues and pull requests
+	// of a repository, at most limit of each
+	ListProjectItems(owner, name string, limit int) ([]*ProjectItem, error)
+
+	// GetCodeOwners gets the CODEOWNERS file of a repository, or "" when it has none
+	GetCodeOwners(owner, name string) (string, error)
+
+	// GetPullRequestDiff gets the changes of a pull request as a unified diff ("diff") or as a
+	// series of patches in git format-patch form ("patch")
+	GetPullRequestDiff(owner, name string, number int, format string) (string, error)
+
+	// CreatePullRequest opens a pull request in a repository
+	CreatePullRequest(owner, name string, create *PullRequestCreate) (*PullRequest, error)
+
+	// UpdateIssue sets the state, assignees or milestone of an issue or pull request
+	UpdateIssue(owner, name string, number int, update *IssueUpdate) (*Issue, error)
+
+	// AddLabels adds labels to an issue or pull request
+	AddLabels(owner, name string, number int, labels []string) error
+
+	// CreateComment comments on an issue or pull request
+	CreateComment(owner, name string, number int, body string) error
+
+	// GetRepositorySettings gets the merge settings of a repository and the protection of its default branch
+	GetRepositorySettings(owner, name string) (*RepositorySettings, error)
+
 	// GetRateLimit gets the current GitHub API rate limit
 	GetRateLimit() (*RateLimit, error)
+
+	// PoolStats reports the usage of the gh process pool
+	PoolStats() PoolStats
 }
@@ -1,6 +1,9 @@
 package github
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 // Repository represents a GitHub repository
 type Repository struct {
@@ -11,6 +14,7 @@ type Repository struct {
 	URL         string    `json:"url"`
 	HTMLURL     string    `json:"html_url"`
 	Private     bool      `json:"private"`
+	Archived    bool      `json:"archived"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 }
@@ -21,6 +25,7 @@ type PullRequest struct {
 	Title     string     `json:"title"`
 	Body      string     `json:"body"`
 	State     string     `json:"state"`
+	Draft     bool       `json:"draft"`
 	URL       string     `json:"url"`
 	HTMLURL   string     `json:"html_url"`
 	User      User       `json:"user"`
@@ -29,6 +34,28 @@ type PullRequest struct {
 	ClosedAt  *time.Time `json:"closed_at"`
 	MergedAt  *time.Time `json:"merged_at"`
 	Labels    []Label    `json:"labels"`
+	Milestone *Milestone `json:"milestone"`
+	// ReviewDecision is APPROVED, CHANGES_REQUESTED, REVIEW_REQUIRED or empty
+	ReviewDecision string `json:"review_decision"`
+	// AuthorAssociation is only populated by Client.ListAuthorAssociations
+	AuthorAssociation string `json:"author_association"`
+	// Reviews is only populated when requested with PullRequestOptions.IncludeReviews
+	Reviews            []Review `json:"reviews"`
+	Assignees          []User   `json:"assignees"`
+	RequestedReviewers []User   `json:"requested_reviewers"`
+	RequestedTeams     []Team   `json:"requested_teams"`
+	Additions          int      `json:"additions"`
+	Deletions          int      `json:"deletions"`
+	ChangedFiles       int      `json:"changed_files"`
+	// Files lists the paths changed, at most 100; only populated when requested with PullRequestOptions.IncludeFiles
+	Files []string `json:"files"`
+}
+
+// Review represents a GitHub pull request review
+type Review struct {
+	User        User      `json:"user"`
+	State       string    `json:"state"`
+	SubmittedAt time.Time `json:"submitted_at"`
 }
 
 // Issue represents a GitHub issue
@@ -44,6 +71,23 @@ type Issue struct {
 	UpdatedAt time.Time  `json:"updated_at"`
 	ClosedAt  *time.Time `json:"closed_at"`
 	Labels    []Label    `json:"labels"`
+	Assignees []User     `json:"assignees"`
+	Milestone *Milestone `json:"milestone"`
+	// AuthorAssociation is only populated by Client.ListAuthorAssociations
+	AuthorAssociation string `json:"author_association"`
+	// PullRequest is set by the REST API when the issue is a pull request
+	PullRequest *IssuePullRequest `json:"pull_request,omitempty"`
+}
+
+// IssuePullRequest links an issue listing entry to its pull request
+type IssuePullRequest struct {
+	URL     string `json:"url"`
+	HTMLURL string `json:"html_url"`
+}
+
+// IsPullRequest reports whether an issue listing entry is actually a pull request
+func (i *Issue) IsPullRequest() bool {
+	return i.PullRequest != nil || strings.Contains(i.HTMLURL, "/pull/")
 }
 
 // User represents a GitHub user
@@ -52,6 +96,18 @@ type User struct {
 	AvatarURL string `json:"avatar_url"`
 	URL       string `json:"url"`
 	HTMLURL   string `json:"html_url"`
+	IsBot     bool   `json:"is_bot"`
+}
+
+// Bot reports whether the user is a bot, such as dependabot or a GitHub App
+func (u User) Bot() bool {
+	return u.IsBot || strings.HasSuffix(u.Login, "[bot]") || strings.HasPrefix(u.Login, "app/")
+}
+
+// Team represents a GitHub team, such as one requested to review a pull request
+type Team struct {
+	Name string `json:"name"`
+	Slug string `json:"slug"` // Without the organization prefix
 }
 
 // Label represents a GitHub label
@@ -61,6 +117,142 @@ type Label struct {
 	Description string `json:"description"`
 }
 
+// LabelUpdate represents a change to a label; empty fields are left unchanged
+type LabelUpdate struct {
+	NewName string
+	Color   string // Hex color without #
+}
+
+// PullRequestCreate represents a pull request to open
+type PullRequestCreate struct {
+	Title string
+	Body  string
+	Base  string // Branch the changes are pulled into, the default branch when empty
+	Head  string // Branch holding the changes, prefixed with owner: for forks
+}
+
+// IssueUpdate represents a change to the state, assignees or milestone of an issue or pull request;
+// empty and nil fields are left unchanged
+type IssueUpdate struct {
+	State     string   // open or closed
+	Assignees []string // Replaces the assignees; empty removes them all
+	Milestone *int     // Number of the milestone, 0 removes it
+}
+
+// IssueTemplate represents an issue template of a repository
+type IssueTemplate struct {
+	Name     string
+	About    string
+	Title    string // Default title of the issues created with it
+	Filename string
+	Labels   []string // Labels applied to the issues created with it
+}
+
+// Milestone represents a GitHub milestone
+type Milestone struct {
+	Number       int        `json:"number"`
+	Title        string     `json:"title"`
+	Description  string     `json:"description"`
+	State        string     `json:"state"`
+	HTMLURL      string     `json:"html_url"`
+	OpenIssues   int        `json:"open_issues"`
+	ClosedIssues int        `json:"closed_issues"`
+	DueOn        *time.Time `json:"due_on"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// Release represents a GitHub release
+type Release struct {
+	TagName     string     `json:"tag_name"`
+	Name        string     `json:"name"`
+	Draft       bool       `json:"draft"`
+	Prerelease  bool       `json:"prerelease"`
+	HTMLURL     string     `json:"html_url"`
+	PublishedAt *time.Time `json:"published_at"` // Unset for drafts
+}
+
+// Discussion represents a GitHub discussion
+type Discussion struct {
+	Number      int
+	Title       string
+	AuthorLogin string
+	Category    string
+	Answerable  bool // Whether the category takes answers, as Q&A does
+	Answered    bool
+	Closed      bool
+	Comments    int
+	Upvotes     int
+	URL         string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// Project represents a GitHub project (Projects v2) linked to a repository
+type Project struct {
+	Owner   string // Login of the organization or user owning the project
+	Number  int
+	Title   string
+	URL     string
+	Closed  bool
+	Columns []string // Options of the Status field, in board order
+}
+
+// ProjectItem represents an issue or pull request on a project with its Status field
+type ProjectItem struct {
+	ProjectOwner  string
+	ProjectNumber int
+	PullRequest   bool // Whether the content is a pull request rather than an issue
+	Number        int
+	Title         string
+	URL           string
+	Status        string // Empty when the Status field is unset
+}
+
+// Commit represents a commit of a repository
+type Commit struct {
+	SHA         string
+	AuthorLogin string // Empty when the author email doesn't belong to a GitHub account
+	AuthorName  string
+	Message     string
+	HTMLURL     string
+	CommittedAt time.Time // Author date
+}
+
+// Kinds of security alerts
+const (
+	AlertKindDependabot   = "dependabot"
+	AlertKindCodeScanning = "code_scanning"
+)
+
+// SecurityAlert represents an open Dependabot or code scanning alert, normalized to the
+// severities low, medium, high and critical
+type SecurityAlert struct {
+	Kind      string
+	Number    int
+	Severity  string
+	Summary   string
+	Package   string // Vulnerable dependency of Dependabot alerts
+	Rule      string // Rule of code scanning alerts
+	Path      string // Manifest or source file
+	HTMLURL   string
+	CreatedAt time.Time
+}
+
+// RepositorySettings represents the merge settings of a repository and the protection of its default branch
+type RepositorySettings struct {
+	DefaultBranch        string
+	AllowMergeCommit     bool
+	AllowSquashMerge     bool
+	AllowRebaseMerge     bool
+	DeleteBranchOnMerge  bool
+	Protected            bool
+	RequiredApprovals    int
+	RequiredStatusChecks []string
+	EnforceAdmins        bool
+	AllowForcePushes     bool
+	RequireLinearHistory bool
+}
+
 // RateLimit represents GitHub API rate limit information
 type RateLimit struct {
 	Limit     int       `json:"limit"`
@@ -71,11 +263,13 @@ type RateLimit struct {
 
 // PullRequestOptions represents options for listing pull requests
 type PullRequestOptions struct {
-	State     string
-	Sort      string
-	Direction string
-	PerPage   int
-	Page      int
+	State          string
+	Sort           string
+	Direction      string
+	PerPage        int
+	Page           int
+	IncludeReviews bool
+	IncludeFiles   bool
 }
 
 // IssueOptions represents options for listing issues
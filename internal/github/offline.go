@@ -0,0 +1,148 @@
+package github
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrOffline is returned for every GitHub call made in offline mode
+var ErrOffline = errors.New("offline mode: GitHub is not contacted, only the local store is served")
+
+// OfflineClient is the client of offline mode: it never runs gh and fails every call with ErrOffline,
+// so that nothing reaches GitHub and callers fall back to the data they have stored
+type OfflineClient struct{}
+
+// NewOfflineClient creates a client failing every call with ErrOffline
+func NewOfflineClient() *OfflineClient {
+	return &OfflineClient{}
+}
+
+// GetRepository fails with ErrOffline
+func (OfflineClient) GetRepository(owner, name string) (*Repository, error) {
+	return nil, ErrOffline
+}
+
+// ListOrganizationRepositories fails with ErrOffline
+func (OfflineClient) ListOrganizationRepositories(org string, limit int) ([]string, error) {
+	return nil, ErrOffline
+}
+
+// ListUserRepositories fails with ErrOffline
+func (OfflineClient) ListUserRepositories(relation string, limit int) ([]*Repository, error) {
+	return nil, ErrOffline
+}
+
+// ListPullRequests fails with ErrOffline
+func (OfflineClient) ListPullRequests(owner, name string, options *PullRequestOptions) ([]*PullRequest, error) {
+	return nil, ErrOffline
+}
+
+// ListIssues fails with ErrOffline
+func (OfflineClient) ListIssues(owner, name string, options *IssueOptions) ([]*Issue, error) {
+	return nil, ErrOffline
+}
+
+// ListAuthorAssociations fails with ErrOffline
+func (OfflineClient) ListAuthorAssociations(owner, name string, limit int) (map[int]string, error) {
+	return nil, ErrOffline
+}
+
+// ListMilestones fails with ErrOffline
+func (OfflineClient) ListMilestones(owner, name string) ([]*Milestone, error) {
+	return nil, ErrOffline
+}
+
+// ListReleases fails with ErrOffline
+func (OfflineClient) ListReleases(owner, name string, limit int) ([]*Release, error) {
+	return nil, ErrOffline
+}
+
+// ListDependabotAlerts fails with ErrOffline
+func (OfflineClient) ListDependabotAlerts(owner, name string) ([]*SecurityAlert, error) {
+	return nil, ErrOffline
+}
+
+// ListCodeScanningAlerts fails with ErrOffline
+func (OfflineClient) ListCodeScanningAlerts(owner, name string) ([]*SecurityAlert, error) {
+	return nil, ErrOffline
+}
+
+// ListCommits fails with ErrOffline
+func (OfflineClient) ListCommits(owner, name string, since time.Time, limit int) ([]*Commit, error) {
+	return nil, ErrOffline
+}
+
+// ListLabels fails with ErrOffline
+func (OfflineClient) ListLabels(owner, name string) ([]*Label, error) {
+	return nil, ErrOffline
+}
+
+// UpdateLabel fails with ErrOffline
+func (OfflineClient) UpdateLabel(owner, name, label string, update *LabelUpdate) (*Label, error) {
+	return nil, ErrOffline
+}
+
+// ListIssueTemplates fails with ErrOffline
+func (OfflineClient) ListIssueTemplates(owner, name string) ([]*IssueTemplate, error) {
+	return nil, ErrOffline
+}
+
+// ListDiscussions fails with ErrOffline
+func (OfflineClient) ListDiscussions(owner, name string, limit int) ([]*Discussion, error) {
+	return nil, ErrOffline
+}
+
+// ListProjects fails with ErrOffline
+func (OfflineClient) ListProjects(owner, name string) ([]*Project, error) {
+	return nil, ErrOffline
+}
+
+// ListProjectItems fails with ErrOffline
+func (OfflineClient) ListProjectItems(owner, name string, limit int) ([]*ProjectItem, error) {
+	return nil, ErrOffline
+}
+
+// GetCodeOwners fails with ErrOffline
+func (OfflineClient) GetCodeOwners(owner, name string) (string, error) {
+	return "", ErrOffline
+}
+
+// GetPullRequestDiff fails with ErrOffline
+func (OfflineClient) GetPullRequestDiff(owner, name string, number int, format string) (string, error) {
+	return "", ErrOffline
+}
+
+// CreatePullRequest fails with ErrOffline
+func (OfflineClient) CreatePullRequest(owner, name string, create *PullRequestCreate) (*PullRequest, error) {
+	return nil, ErrOffline
+}
+
+// UpdateIssue fails with ErrOffline
+func (OfflineClient) UpdateIssue(owner, name string, number int, update *IssueUpdate) (*Issue, error) {
+	return nil, ErrOffline
+}
+
+// AddLabels fails with ErrOffline
+func (OfflineClient) AddLabels(owner, name string, number int, labels []string) error {
+	return ErrOffline
+}
+
+// CreateComment fails with ErrOffline
+func (OfflineClient) CreateComment(owner, name string, number int, body string) error {
+	return ErrOffline
+}
+
+// GetRepositorySettings fails with ErrOffline
+func (OfflineClient) GetRepositorySettings(owner, name string) (*RepositorySettings, error) {
+	return nil, ErrOffline
+}
+
+// GetRateLimit fails with ErrOffline
+func (OfflineClient) GetRateLimit() (*RateLimit, error) {
+	return nil, ErrOffline
+}
+
+// PoolStats reports an idle pool, as no gh process is ever started
+func (OfflineClient) PoolStats() PoolStats {
+	return PoolStats{}
+}
@@ -0,0 +1,28 @@
+package github
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestOfflineClient(t *testing.T) {
+	var client ClientInterface = NewOfflineClient()
+	calls := map[string]error{}
+	_, calls["GetRepository"] = client.GetRepository("org", "api")
+	_, calls["ListPullRequests"] = client.ListPullRequests("org", "api", &PullRequestOptions{})
+	_, calls["ListIssues"] = client.ListIssues("org", "api", &IssueOptions{})
+	_, calls["ListCommits"] = client.ListCommits("org", "api", time.Now(), 10)
+	_, calls["UpdateLabel"] = client.UpdateLabel("org", "api", "bug", &LabelUpdate{NewName: "kind/bug"})
+	_, calls["UpdateIssue"] = client.UpdateIssue("org", "api", 1, &IssueUpdate{Assignees: []string{"alice"}})
+	calls["CreateComment"] = client.CreateComment("org", "api", 1, "Closing as stale")
+	_, calls["GetRateLimit"] = client.GetRateLimit()
+	for call, err := range calls {
+		if !errors.Is(err, ErrOffline) {
+			t.Errorf("%s() error = %v, want ErrOffline", call, err)
+		}
+	}
+	if stats := client.PoolStats(); stats != (PoolStats{}) {
+		t.Errorf("PoolStats() = %+v, want an idle pool", stats)
+	}
+}
@@ -0,0 +1,80 @@
+package github
+
+import (
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// DefaultMaxConcurrentCalls is the number of gh processes allowed to run at once by default
+const DefaultMaxConcurrentCalls = 8
+
+// PoolStats reports the usage of the gh process pool
+type PoolStats struct {
+	MaxConcurrent int           `json:"max_concurrent"`
+	Running       int           `json:"running"`
+	Waiting       int           `json:"waiting"`
+	PeakRunning   int           `json:"peak_running"`
+	Calls         int64         `json:"calls"`
+	Failures      int64         `json:"failures"`
+	TotalWait     time.Duration `json:"total_wait"`
+}
+
+// processPool bounds the number of gh processes running at once, so a burst of syncs
+// queues up instead of forking hundreds of processes
+type processPool struct {
+	slots chan struct{}
+
+	mu    sync.Mutex
+	stats PoolStats
+}
+
+// newProcessPool creates a pool running at most maxConcurrent processes
+func newProcessPool(maxConcurrent int) *processPool {
+	if maxConcurrent <= 0 {
+		maxConcurrent = DefaultMaxConcurrentCalls
+	}
+	return &processPool{
+		slots: make(chan struct{}, maxConcurrent),
+		stats: PoolStats{MaxConcurrent: maxConcurrent},
+	}
+}
+
+// run runs a command once a slot is free
+func (p *processPool) run(cmd *exec.Cmd) error {
+	start := time.Now()
+	p.mu.Lock()
+	p.stats.Waiting++
+	p.mu.Unlock()
+
+	p.slots <- struct{}{}
+	defer func() { <-p.slots }()
+
+	p.mu.Lock()
+	p.stats.Waiting--
+	p.stats.Running++
+	if p.stats.Running > p.stats.PeakRunning {
+		p.stats.PeakRunning = p.stats.Running
+	}
+	p.stats.TotalWait += time.Since(start)
+	p.mu.Unlock()
+
+	err := cmd.Run()
+
+	p.mu.Lock()
+	p.stats.Running--
+	p.stats.Calls++
+	if err != nil {
+		p.stats.Failures++
+	}
+	p.mu.Unlock()
+
+	return err
+}
+
+// snapshot returns a copy of the pool statistics
+func (p *processPool) snapshot() PoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.stats
+}
@@ -0,0 +1,48 @@
+package github
+
+import (
+	"os/exec"
+	"sync"
+	"testing"
+)
+
+// TestProcessPoolLimit tests that the pool never runs more processes than allowed
+func TestProcessPoolLimit(t *testing.T) {
+	if _, err := exec.LookPath("sleep"); err != nil {
+		t.Skip("sleep not available")
+	}
+
+	pool := newProcessPool(2)
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := pool.run(exec.Command("sleep", "0.05")); err != nil {
+				t.Errorf("run() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	stats := pool.snapshot()
+	if stats.PeakRunning > 2 {
+		t.Errorf("PeakRunning = %d, want at most 2", stats.PeakRunning)
+	}
+	if stats.Calls != 6 || stats.Running != 0 || stats.Waiting != 0 {
+		t.Errorf("stats = %+v, want 6 calls and nothing running or waiting", stats)
+	}
+}
+
+// TestProcessPoolFailures tests that failed processes are counted
+func TestProcessPoolFailures(t *testing.T) {
+	pool := newProcessPool(0)
+	if err := pool.run(exec.Command("/nonexistent/gh")); err == nil {
+		t.Fatal("run() succeeded for a missing binary")
+	}
+
+	stats := pool.snapshot()
+	if stats.MaxConcurrent != DefaultMaxConcurrentCalls || stats.Failures != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
@@ -0,0 +1,313 @@
+package github
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Recorder passes the calls made to it on to a client, recording what the reads return as
+// fixtures per repository, which a FixtureClient replays. Listing pull requests or issues again
+// adds to those already recorded; other lists replace theirs. Writes are passed on and not recorded.
+type Recorder struct {
+	ClientInterface
+
+	mu           sync.Mutex
+	fixtures     map[string]*Fixture
+	associations map[string]map[int]string // Author associations by repository and item number
+}
+
+// NewRecorder creates a recorder of the responses of client
+func NewRecorder(client ClientInterface) *Recorder {
+	return &Recorder{
+		ClientInterface: client,
+		fixtures:        make(map[string]*Fixture),
+		associations:    make(map[string]map[int]string),
+	}
+}
+
+// record runs fn on the fixture of a repository, creating it on first use
+func (r *Recorder) record(owner, name string, fn func(fixture *Fixture)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	fullName := owner + "/" + name
+	fixture, ok := r.fixtures[fullName]
+	if !ok {
+		fixture = &Fixture{Repository: &Repository{Owner: User{Login: owner}, Name: name, FullName: fullName}}
+		r.fixtures[fullName] = fixture
+	}
+	fn(fixture)
+}
+
+// Fixtures returns the recorded fixtures, sorted by repository full name
+func (r *Recorder) Fixtures() []*Fixture {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	fixtures := make([]*Fixture, 0, len(r.fixtures))
+	for fullName, fixture := range r.fixtures {
+		associations := r.associations[fullName]
+		for _, pr := range fixture.PullRequests {
+			if association, ok := associations[pr.Number]; ok {
+				pr.AuthorAssociation = association
+			}
+		}
+		for _, issue := range fixture.Issues {
+			if association, ok := associations[issue.Number]; ok {
+				issue.AuthorAssociation = association
+			}
+		}
+		fixtures = append(fixtures, fixture)
+	}
+	sort.Slice(fixtures, func(i, j int) bool { return fixtures[i].Repository.FullName < fixtures[j].Repository.FullName })
+	return fixtures
+}
+
+// Save writes each recorded fixture to a file of dir named after its repository, such as
+// pingcap_tidb.json, creating dir if needed. It returns the paths written.
+func (r *Recorder) Save(dir string) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, fixture := range r.Fixtures() {
+		path := filepath.Join(dir, strings.ReplaceAll(fixture.Repository.FullName, "/", "_")+".json")
+		if err := WriteFixture(path, fixture); err != nil {
+			return paths, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// GetRepository records the repository
+func (r *Recorder) GetRepository(owner, name string) (*Repository, error) {
+	repo, err := r.ClientInterface.GetRepository(owner, name)
+	if err == nil {
+		r.record(owner, name, func(fixture *Fixture) {
+			copied := *repo
+			fixture.Repository = &copied
+		})
+	}
+	return repo, err
+}
+
+// ListOrganizationRepositories records the repositories listed, without their details unless
+// they are fetched too
+func (r *Recorder) ListOrganizationRepositories(org string, limit int) ([]string, error) {
+	names, err := r.ClientInterface.ListOrganizationRepositories(org, limit)
+	for _, fullName := range names {
+		if owner, name, ok := strings.Cut(fullName, "/"); ok {
+			r.record(owner, name, func(*Fixture) {})
+		}
+	}
+	return names, err
+}
+
+// ListUserRepositories records the repositories listed
+func (r *Recorder) ListUserRepositories(relation string, limit int) ([]*Repository, error) {
+	repos, err := r.ClientInterface.ListUserRepositories(relation, limit)
+	for _, repo := range repos {
+		if owner, name, ok := strings.Cut(repo.FullName, "/"); ok {
+			r.record(owner, name, func(fixture *Fixture) {
+				copied := *repo
+				fixture.Repository = &copied
+			})
+		}
+	}
+	return repos, err
+}
+
+// ListPullRequests records the pull requests listed, replacing those of the same number
+func (r *Recorder) ListPullRequests(owner, name string, options *PullRequestOptions) ([]*PullRequest, error) {
+	prs, err := r.ClientInterface.ListPullRequests(owner, name, options)
+	if err == nil {
+		r.record(owner, name, func(fixture *Fixture) {
+			byNumber := make(map[int]*PullRequest)
+			for _, pr := range fixture.PullRequests {
+				byNumber[pr.Number] = pr
+			}
+			for _, pr := range prs {
+				copied := *pr
+				byNumber[pr.Number] = &copied
+			}
+			fixture.PullRequests = fixture.PullRequests[:0]
+			for _, pr := range byNumber {
+				fixture.PullRequests = append(fixture.PullRequests, pr)
+			}
+			sort.Slice(fixture.PullRequests, func(i, j int) bool { return fixture.PullRequests[i].Number > fixture.PullRequests[j].Number })
+		})
+	}
+	return prs, err
+}
+
+// ListIssues records the issues listed, replacing those of the same number
+func (r *Recorder) ListIssues(owner, name string, options *IssueOptions) ([]*Issue, error) {
+	issues, err := r.ClientInterface.ListIssues(owner, name, options)
+	if err == nil {
+		r.record(owner, name, func(fixture *Fixture) {
+			byNumber := make(map[int]*Issue)
+			for _, issue := range fixture.Issues {
+				byNumber[issue.Number] = issue
+			}
+			for _, issue := range issues {
+				copied := *issue
+				byNumber[issue.Number] = &copied
+			}
+			fixture.Issues = fixture.Issues[:0]
+			for _, issue := range byNumber {
+				fixture.Issues = append(fixture.Issues, issue)
+			}
+			sort.Slice(fixture.Issues, func(i, j int) bool { return fixture.Issues[i].Number > fixture.Issues[j].Number })
+		})
+	}
+	return issues, err
+}
+
+// ListAuthorAssociations records the associations, set on the recorded pull requests and issues
+func (r *Recorder) ListAuthorAssociations(owner, name string, limit int) (map[int]string, error) {
+	associations, err := r.ClientInterface.ListAuthorAssociations(owner, name, limit)
+	if err == nil {
+		r.record(owner, name, func(*Fixture) {
+			recorded := r.associations[owner+"/"+name]
+			if recorded == nil {
+				recorded = make(map[int]string)
+				r.associations[owner+"/"+name] = recorded
+			}
+			for number, association := range associations {
+				recorded[number] = association
+			}
+		})
+	}
+	return associations, err
+}
+
+// ListMilestones records the milestones
+func (r *Recorder) ListMilestones(owner, name string) ([]*Milestone, error) {
+	milestones, err := r.ClientInterface.ListMilestones(owner, name)
+	if err == nil {
+		r.record(owner, name, func(fixture *Fixture) { fixture.Milestones = copyLimited(milestones, 0) })
+	}
+	return milestones, err
+}
+
+// ListReleases records the releases
+func (r *Recorder) ListReleases(owner, name string, limit int) ([]*Release, error) {
+	releases, err := r.ClientInterface.ListReleases(owner, name, limit)
+	if err == nil {
+		r.record(owner, name, func(fixture *Fixture) { fixture.Releases = copyLimited(releases, 0) })
+	}
+	return releases, err
+}
+
+// ListDependabotAlerts records the Dependabot alerts
+func (r *Recorder) ListDependabotAlerts(owner, name string) ([]*SecurityAlert, error) {
+	alerts, err := r.ClientInterface.ListDependabotAlerts(owner, name)
+	if err == nil {
+		r.record(owner, name, func(fixture *Fixture) { fixture.DependabotAlerts = copyLimited(alerts, 0) })
+	}
+	return alerts, err
+}
+
+// ListCodeScanningAlerts records the code scanning alerts
+func (r *Recorder) ListCodeScanningAlerts(owner, name string) ([]*SecurityAlert, error) {
+	alerts, err := r.ClientInterface.ListCodeScanningAlerts(owner, name)
+	if err == nil {
+		r.record(owner, name, func(fixture *Fixture) { fixture.CodeScanningAlerts = copyLimited(alerts, 0) })
+	}
+	return alerts, err
+}
+
+// ListCommits records the commits
+func (r *Recorder) ListCommits(owner, name string, since time.Time, limit int) ([]*Commit, error) {
+	commits, err := r.ClientInterface.ListCommits(owner, name, since, limit)
+	if err == nil {
+		r.record(owner, name, func(fixture *Fixture) { fixture.Commits = copyLimited(commits, 0) })
+	}
+	return commits, err
+}
+
+// ListLabels records the labels
+func (r *Recorder) ListLabels(owner, name string) ([]*Label, error) {
+	labels, err := r.ClientInterface.ListLabels(owner, name)
+	if err == nil {
+		r.record(owner, name, func(fixture *Fixture) { fixture.Labels = copyLimited(labels, 0) })
+	}
+	return labels, err
+}
+
+// ListIssueTemplates records the issue templates
+func (r *Recorder) ListIssueTemplates(owner, name string) ([]*IssueTemplate, error) {
+	templates, err := r.ClientInterface.ListIssueTemplates(owner, name)
+	if err == nil {
+		r.record(owner, name, func(fixture *Fixture) { fixture.IssueTemplates = copyLimited(templates, 0) })
+	}
+	return templates, err
+}
+
+// ListDiscussions records the discussions
+func (r *Recorder) ListDiscussions(owner, name string, limit int) ([]*Discussion, error) {
+	discussions, err := r.ClientInterface.ListDiscussions(owner, name, limit)
+	if err == nil {
+		r.record(owner, name, func(fixture *Fixture) { fixture.Discussions = copyLimited(discussions, 0) })
+	}
+	return discussions, err
+}
+
+// ListProjects records the projects
+func (r *Recorder) ListProjects(owner, name string) ([]*Project, error) {
+	projects, err := r.ClientInterface.ListProjects(owner, name)
+	if err == nil {
+		r.record(owner, name, func(fixture *Fixture) { fixture.Projects = copyLimited(projects, 0) })
+	}
+	return projects, err
+}
+
+// ListProjectItems records the project items
+func (r *Recorder) ListProjectItems(owner, name string, limit int) ([]*ProjectItem, error) {
+	items, err := r.ClientInterface.ListProjectItems(owner, name, limit)
+	if err == nil {
+		r.record(owner, name, func(fixture *Fixture) { fixture.ProjectItems = copyLimited(items, 0) })
+	}
+	return items, err
+}
+
+// GetCodeOwners records the CODEOWNERS file
+func (r *Recorder) GetCodeOwners(owner, name string) (string, error) {
+	codeOwners, err := r.ClientInterface.GetCodeOwners(owner, name)
+	if err == nil {
+		r.record(owner, name, func(fixture *Fixture) { fixture.CodeOwners = codeOwners })
+	}
+	return codeOwners, err
+}
+
+// GetPullRequestDiff records the diff under its pull request number and format
+func (r *Recorder) GetPullRequestDiff(owner, name string, number int, format string) (string, error) {
+	diff, err := r.ClientInterface.GetPullRequestDiff(owner, name, number, format)
+	if err == nil {
+		r.record(owner, name, func(fixture *Fixture) {
+			if fixture.Diffs == nil {
+				fixture.Diffs = make(map[string]string)
+			}
+			fixture.Diffs[strconv.Itoa(number)+"."+format] = diff
+		})
+	}
+	return diff, err
+}
+
+// GetRepositorySettings records the settings
+func (r *Recorder) GetRepositorySettings(owner, name string) (*RepositorySettings, error) {
+	settings, err := r.ClientInterface.GetRepositorySettings(owner, name)
+	if err == nil {
+		r.record(owner, name, func(fixture *Fixture) {
+			copied := *settings
+			fixture.Settings = &copied
+		})
+	}
+	return settings, err
+}
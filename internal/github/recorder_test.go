@@ -0,0 +1,59 @@
+package github
+
+import (
+	"testing"
+)
+
+func TestRecorder(t *testing.T) {
+	source := NewFixtureClient(&Fixture{
+		Repository: &Repository{Owner: User{Login: "org"}, Name: "api", FullName: "org/api", Description: "The API server"},
+		PullRequests: []*PullRequest{
+			{Number: 3, State: "OPEN"},
+			{Number: 2, State: "MERGED"},
+		},
+		Labels: []*Label{{Name: "bug"}},
+		Diffs:  map[string]string{"3.diff": "diff --git a/a.go b/a.go\n"},
+	})
+	r := NewRecorder(source)
+
+	r.GetRepository("org", "api")
+	r.ListPullRequests("org", "api", nil)
+	r.ListPullRequests("org", "api", &PullRequestOptions{State: "merged"})
+	r.ListLabels("org", "api")
+	r.GetPullRequestDiff("org", "api", 3, "diff")
+	if _, err := r.GetRepository("org", "missing"); !IsNotFound(err) {
+		t.Errorf("GetRepository(org/missing) error = %v, want the client's not found", err)
+	}
+
+	dir := t.TempDir()
+	paths, err := r.Save(dir)
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if len(paths) != 1 {
+		t.Fatalf("Save() = %v, want only the repository found", paths)
+	}
+	replay, err := LoadFixtures(dir)
+	if err != nil {
+		t.Fatalf("LoadFixtures() error = %v", err)
+	}
+
+	// Both listings are replayed, newest first
+	if repo, err := replay.GetRepository("org", "api"); err != nil || repo.Description != "The API server" {
+		t.Errorf("replayed repository = %+v, %v", repo, err)
+	}
+	prs, _ := replay.ListPullRequests("org", "api", &PullRequestOptions{State: "all"})
+	if len(prs) != 2 || prs[0].Number != 3 {
+		t.Errorf("replayed pull requests = %+v, want 3 then 2", prs)
+	}
+	if labels, _ := replay.ListLabels("org", "api"); len(labels) != 1 {
+		t.Errorf("replayed labels = %v", labels)
+	}
+	if diff, err := replay.GetPullRequestDiff("org", "api", 3, "diff"); err != nil || diff == "" {
+		t.Errorf("replayed diff = %q, %v", diff, err)
+	}
+
+	if _, err := LoadFixtures(t.TempDir()); err == nil {
+		t.Error("LoadFixtures() of an empty directory should fail")
+	}
+}
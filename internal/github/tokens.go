@@ -0,0 +1,196 @@
+package github
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultTokenMinRemaining is the remaining rate limit at which calls move to another token by default
+const DefaultTokenMinRemaining = 100
+
+// defaultTokenLimit is assumed for a token until its rate limit has been checked
+const defaultTokenLimit = 5000
+
+// TokenStatus reports the rate limit of one configured token
+type TokenStatus struct {
+	Name      string    `json:"name"`      // The last characters of the token
+	Limit     int       `json:"limit"`     // 0 until the rate limit was checked
+	Remaining int       `json:"remaining"` // Estimated from the calls made since the last check
+	ResetTime time.Time `json:"reset_time"`
+	Calls     int64     `json:"calls"`
+	Active    bool      `json:"active"` // Calls currently use this token
+}
+
+// TokenReporter is implemented by clients that rotate between several tokens
+type TokenReporter interface {
+	TokenStats() []TokenStatus
+}
+
+// tokenState tracks the rate limit of a token
+type tokenState struct {
+	token     string
+	limit     int
+	remaining int
+	resetTime time.Time
+	checked   bool // The limit was read from GitHub rather than assumed
+	calls     int64
+}
+
+// tokenPool hands out tokens for gh calls, staying on one token until it nears its
+// rate limit and then moving to the token with the most requests left
+type tokenPool struct {
+	minRemaining int
+
+	mu      sync.Mutex
+	tokens  []*tokenState
+	current int
+}
+
+// newTokenPool creates a pool of tokens, or returns nil when there are none so gh uses its own credentials
+func newTokenPool(tokens []string, minRemaining int) *tokenPool {
+	if len(tokens) == 0 {
+		return nil
+	}
+	if minRemaining <= 0 {
+		minRemaining = DefaultTokenMinRemaining
+	}
+
+	p := &tokenPool{minRemaining: minRemaining}
+	for _, token := range tokens {
+		p.tokens = append(p.tokens, &tokenState{token: token, limit: defaultTokenLimit, remaining: defaultTokenLimit})
+	}
+	return p
+}
+
+// next returns the token the next call should use and counts the call against it
+func (p *tokenPool) next() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	for _, t := range p.tokens {
+		// A reset restores the full limit
+		if !t.resetTime.IsZero() && now.After(t.resetTime) {
+			t.remaining = t.limit
+			t.resetTime = time.Time{}
+		}
+	}
+
+	if p.tokens[p.current].remaining <= p.minRemaining {
+		best := p.current
+		for i, t := range p.tokens {
+			if t.remaining > p.tokens[best].remaining {
+				best = i
+			}
+		}
+		p.current = best
+	}
+
+	t := p.tokens[p.current]
+	if t.remaining > 0 {
+		t.remaining--
+	}
+	t.calls++
+	return t.token
+}
+
+// update records the rate limit GitHub reported for a token
+func (p *tokenPool) update(token string, rateLimit *RateLimit) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if t := p.find(token); t != nil {
+		t.limit = rateLimit.Limit
+		t.remaining = rateLimit.Remaining
+		t.resetTime = rateLimit.ResetTime
+		t.checked = true
+	}
+}
+
+// exhaust marks a token as out of requests after GitHub rejected a call with it
+func (p *tokenPool) exhaust(token string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if t := p.find(token); t != nil {
+		t.remaining = 0
+		if t.resetTime.IsZero() {
+			// The reset time is unknown until the next rate limit check; GitHub resets hourly
+			t.resetTime = time.Now().Add(time.Hour)
+		}
+	}
+}
+
+// find returns the state of a token; the caller holds the lock
+func (p *tokenPool) find(token string) *tokenState {
+	for _, t := range p.tokens {
+		if t.token == token {
+			return t
+		}
+	}
+	return nil
+}
+
+// all returns the configured tokens
+func (p *tokenPool) all() []string {
+	tokens := make([]string, len(p.tokens))
+	for i, t := range p.tokens {
+		tokens[i] = t.token
+	}
+	return tokens
+}
+
+// stats reports the state of every token
+func (p *tokenPool) stats() []TokenStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := make([]TokenStatus, 0, len(p.tokens))
+	for i, t := range p.tokens {
+		status := TokenStatus{
+			Name:      maskToken(t.token),
+			Remaining: t.remaining,
+			ResetTime: t.resetTime,
+			Calls:     t.calls,
+			Active:    i == p.current,
+		}
+		if t.checked {
+			status.Limit = t.limit
+		}
+		stats = append(stats, status)
+	}
+	return stats
+}
+
+// maskToken shortens a token to its last four characters so it can be shown
+func maskToken(token string) string {
+	if len(token) <= 4 {
+		return "…"
+	}
+	return "…" + token[len(token)-4:]
+}
+
+// withToken makes a gh command authenticate with token. GH_TOKEN takes precedence over
+// gh's stored credentials, and the last value of a duplicated variable wins.
+func withToken(cmd *exec.Cmd, token string) {
+	cmd.Env = append(os.Environ(), "GH_TOKEN="+token)
+}
+
+// commandToken returns the token a command was authenticated with by withToken
+func commandToken(cmd *exec.Cmd) string {
+	for i := len(cmd.Env) - 1; i >= 0; i-- {
+		if token, ok := strings.CutPrefix(cmd.Env[i], "GH_TOKEN="); ok {
+			return token
+		}
+	}
+	return ""
+}
+
+// isRateLimited reports whether gh failed because the token ran out of requests
+func isRateLimited(stderr string) bool {
+	stderr = strings.ToLower(stderr)
+	return strings.Contains(stderr, "rate limit exceeded") || strings.Contains(stderr, "secondary rate limit")
+}
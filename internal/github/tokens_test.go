@@ -0,0 +1,60 @@
+package github
+
+import (
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func TestTokenPoolRotation(t *testing.T) {
+	if newTokenPool(nil, 0) != nil {
+		t.Fatal("newTokenPool(nil) should return nil so gh uses its own credentials")
+	}
+
+	pool := newTokenPool([]string{"token-a", "token-b"}, 10)
+	pool.update("token-a", &RateLimit{Limit: 5000, Remaining: 12})
+	pool.update("token-b", &RateLimit{Limit: 5000, Remaining: 3000})
+
+	// The current token is used until it reaches the minimum
+	for i := 0; i < 2; i++ {
+		if got := pool.next(); got != "token-a" {
+			t.Fatalf("call %d used %s, want token-a", i, got)
+		}
+	}
+	if got := pool.next(); got != "token-b" {
+		t.Fatalf("next() = %s, want token-b once token-a is low", got)
+	}
+
+	// An exhausted token is skipped until it resets
+	pool.exhaust("token-b")
+	pool.update("token-a", &RateLimit{Limit: 5000, Remaining: 5000})
+	if got := pool.next(); got != "token-a" {
+		t.Fatalf("next() = %s, want token-a after token-b was exhausted", got)
+	}
+
+	stats := pool.stats()
+	if len(stats) != 2 || !stats[0].Active || stats[1].Remaining != 0 || stats[1].Name != "…en-b" {
+		t.Errorf("stats() = %+v, want token-a active and token-b exhausted", stats)
+	}
+}
+
+func TestTokenPoolReset(t *testing.T) {
+	pool := newTokenPool([]string{"token-a"}, 10)
+	pool.update("token-a", &RateLimit{Limit: 5000, Remaining: 0, ResetTime: time.Now().Add(-time.Minute)})
+
+	pool.next()
+	if stats := pool.stats(); stats[0].Remaining != 4999 {
+		t.Errorf("remaining = %d, want the full limit restored after the reset", stats[0].Remaining)
+	}
+}
+
+func TestCommandToken(t *testing.T) {
+	cmd := exec.Command("gh", "api", "rate_limit")
+	withToken(cmd, "secret")
+	if got := commandToken(cmd); got != "secret" {
+		t.Errorf("commandToken() = %q, want secret", got)
+	}
+	if !isRateLimited("HTTP 403: API rate limit exceeded for user ID 1.") {
+		t.Error("isRateLimited() = false for a rate limit error")
+	}
+}
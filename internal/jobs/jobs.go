@@ -0,0 +1,362 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// Defaults used for zero Options fields
+const (
+	DefaultWorkers     = 4
+	DefaultMaxAttempts = 2
+	DefaultRetryDelay  = 5 * time.Second
+)
+
+// Errors returned by the queue
+var (
+	ErrUnknownType = errors.New("unknown job type")
+	ErrFinished    = errors.New("job already finished")
+)
+
+// interruptedError is recorded on jobs that were left unfinished by a previous process
+const interruptedError = "interrupted before it finished"
+
+// Store persists jobs; the database implements it
+type Store interface {
+	AddJob(ctx context.Context, job *models.Job) error
+	UpdateJob(ctx context.Context, job *models.Job) error
+	GetJob(ctx context.Context, id int64) (*models.Job, error)
+	ListJobs(ctx context.Context, filter *models.JobFilter) ([]*models.Job, int, error)
+}
+
+// Handler runs a job. It should stop early when ctx is canceled.
+type Handler func(ctx context.Context, job *models.Job) error
+
+// Options configures a queue
+type Options struct {
+	Workers     int           // Jobs running at once
+	MaxAttempts int           // Runs of a failing job before it is marked failed
+	RetryDelay  time.Duration // Wait before the first retry; the n-th retry waits n times as long
+	Logger      *log.Logger
+}
+
+// Queue runs background jobs with bounded concurrency and retries, persisting
+// their state in a store so they can be listed, polled and canceled
+type Queue struct {
+	store  Store
+	opts   Options
+	logger *log.Logger
+
+	mu       sync.Mutex
+	handlers map[string]Handler
+	active   map[int64]*run
+	running  int       // Jobs holding a worker
+	waiting  []*waiter // Jobs waiting for a worker, highest priority and then oldest first
+}
+
+// waiter is a job waiting for a free worker
+type waiter struct {
+	job   *models.Job
+	ready chan struct{} // Closed when the job was handed a worker
+}
+
+// run tracks a job processed by this queue
+type run struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// New creates a queue storing its jobs in store
+func New(store Store, opts Options) *Queue {
+	if opts.Workers <= 0 {
+		opts.Workers = DefaultWorkers
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = DefaultMaxAttempts
+	}
+	if opts.RetryDelay <= 0 {
+		opts.RetryDelay = DefaultRetryDelay
+	}
+	logger := opts.Logger
+	if logger == nil {
+		logger = log.Default()
+	}
+
+	return &Queue{
+		store:    store,
+		opts:     opts,
+		logger:   logger,
+		handlers: make(map[string]Handler),
+		active:   make(map[int64]*run),
+	}
+}
+
+// Handle registers the handler of a job type
+func (q *Queue) Handle(jobType string, handler Handler) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.handlers[jobType] = handler
+}
+
+// Enqueue stores a new job and runs it in the background once a worker is free
+func (q *Queue) Enqueue(ctx context.Context, jobType, target string) (*models.Job, error) {
+	return q.EnqueueWithPriority(ctx, jobType, target, 0)
+}
+
+// EnqueueWithPriority is like Enqueue, but jobs with a higher priority get a free worker first
+func (q *Queue) EnqueueWithPriority(ctx context.Context, jobType, target string, priority int) (*models.Job, error) {
+	q.mu.Lock()
+	handler, ok := q.handlers[jobType]
+	q.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownType, jobType)
+	}
+
+	job := &models.Job{
+		Type:        jobType,
+		Target:      target,
+		State:       models.JobStateQueued,
+		MaxAttempts: q.opts.MaxAttempts,
+		Priority:    priority,
+		CreatedAt:   time.Now(),
+	}
+	if err := q.store.AddJob(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to store job: %w", err)
+	}
+
+	runCtx, cancel := context.WithCancel(context.Background())
+	r := &run{cancel: cancel, done: make(chan struct{})}
+	q.mu.Lock()
+	q.active[job.ID] = r
+	q.mu.Unlock()
+
+	queued := *job
+	go q.process(runCtx, job, handler, r)
+	return &queued, nil
+}
+
+// process runs a job until it succeeds, runs out of attempts or is canceled
+func (q *Queue) process(ctx context.Context, job *models.Job, handler Handler, r *run) {
+	defer func() {
+		r.cancel()
+		q.mu.Lock()
+		delete(q.active, job.ID)
+		q.mu.Unlock()
+		close(r.done)
+	}()
+
+	for {
+		if !q.acquire(ctx, job) {
+			q.finish(job, models.JobStateCanceled)
+			return
+		}
+
+		job.State = models.JobStateRunning
+		job.Attempts++
+		job.StartedAt = time.Now()
+		q.save(job)
+
+		err := handler(ctx, job)
+		q.release()
+
+		switch {
+		case err == nil:
+			job.Error = ""
+			q.finish(job, models.JobStateSucceeded)
+			return
+		case ctx.Err() != nil:
+			q.finish(job, models.JobStateCanceled)
+			return
+		}
+
+		job.Error = err.Error()
+		if job.Attempts >= job.MaxAttempts {
+			q.finish(job, models.JobStateFailed)
+			return
+		}
+
+		delay := q.opts.RetryDelay * time.Duration(job.Attempts)
+		q.logger.Printf("Job %d (%s %s) failed, retrying in %s: %v", job.ID, job.Type, job.Target, delay, err)
+		job.State = models.JobStateQueued
+		q.save(job)
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			q.finish(job, models.JobStateCanceled)
+			return
+		}
+	}
+}
+
+// acquire waits until the job holds a worker, in priority order, reporting false when ctx ended first
+func (q *Queue) acquire(ctx context.Context, job *models.Job) bool {
+	q.mu.Lock()
+	if q.running < q.opts.Workers && len(q.waiting) == 0 {
+		q.running++
+		q.mu.Unlock()
+		return true
+	}
+
+	w := &waiter{job: job, ready: make(chan struct{})}
+	// Keep waiters ordered by priority, then by ID so equal jobs run in the order they were enqueued
+	i := sort.Search(len(q.waiting), func(i int) bool {
+		other := q.waiting[i].job
+		return other.Priority < job.Priority || (other.Priority == job.Priority && other.ID > job.ID)
+	})
+	q.waiting = append(q.waiting, nil)
+	copy(q.waiting[i+1:], q.waiting[i:])
+	q.waiting[i] = w
+	q.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return true
+	case <-ctx.Done():
+	}
+
+	q.mu.Lock()
+	for i, other := range q.waiting {
+		if other == w {
+			q.waiting = append(q.waiting[:i], q.waiting[i+1:]...)
+			q.mu.Unlock()
+			return false
+		}
+	}
+	q.mu.Unlock()
+
+	// The worker was handed over while ctx ended, so pass it on
+	q.release()
+	return false
+}
+
+// release frees a worker, handing it to the first waiting job
+func (q *Queue) release() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.waiting) == 0 {
+		q.running--
+		return
+	}
+	w := q.waiting[0]
+	q.waiting = q.waiting[1:]
+	close(w.ready)
+}
+
+// finish records the final state of a job
+func (q *Queue) finish(job *models.Job, state string) {
+	job.State = state
+	job.FinishedAt = time.Now()
+	q.save(job)
+}
+
+// save persists a job, logging failures since the job keeps running regardless
+func (q *Queue) save(job *models.Job) {
+	if err := q.store.UpdateJob(context.Background(), job); err != nil {
+		q.logger.Printf("Error saving job %d: %v", job.ID, err)
+	}
+}
+
+// Get returns the current state of a job
+func (q *Queue) Get(ctx context.Context, id int64) (*models.Job, error) {
+	return q.store.GetJob(ctx, id)
+}
+
+// List lists jobs matching the filter, newest first
+func (q *Queue) List(ctx context.Context, filter *models.JobFilter) ([]*models.Job, int, error) {
+	return q.store.ListJobs(ctx, filter)
+}
+
+// Wait blocks until a job finishes or ctx is done, then returns its state.
+// Jobs run by another process are not waited for.
+func (q *Queue) Wait(ctx context.Context, id int64) (*models.Job, error) {
+	q.mu.Lock()
+	r, ok := q.active[id]
+	q.mu.Unlock()
+
+	if ok {
+		select {
+		case <-r.done:
+		case <-ctx.Done():
+		}
+	}
+	return q.store.GetJob(context.Background(), id)
+}
+
+// Cancel stops a queued or running job and returns its final state. A running
+// handler is asked to stop through its context and waited for.
+func (q *Queue) Cancel(ctx context.Context, id int64) (*models.Job, error) {
+	q.mu.Lock()
+	r, ok := q.active[id]
+	q.mu.Unlock()
+
+	if ok {
+		r.cancel()
+		select {
+		case <-r.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		return q.store.GetJob(ctx, id)
+	}
+
+	job, err := q.store.GetJob(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.Done() {
+		return nil, ErrFinished
+	}
+
+	// The job belongs to a process that is gone, so only its record is updated
+	job.State = models.JobStateCanceled
+	job.FinishedAt = time.Now()
+	if err := q.store.UpdateJob(ctx, job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// Recover marks the jobs a previous process left queued or running as failed,
+// returning how many there were. Call it before enqueuing jobs.
+func (q *Queue) Recover(ctx context.Context) (int, error) {
+	var stale []*models.Job
+	for _, state := range []string{models.JobStateQueued, models.JobStateRunning} {
+		jobs, _, err := q.store.ListJobs(ctx, &models.JobFilter{State: state, Page: 1, PerPage: maxRecoveredJobs})
+		if err != nil {
+			return 0, err
+		}
+		stale = append(stale, jobs...)
+	}
+
+	recovered := 0
+	for _, job := range stale {
+		q.mu.Lock()
+		_, running := q.active[job.ID]
+		q.mu.Unlock()
+		if running {
+			continue
+		}
+
+		job.State = models.JobStateFailed
+		job.Error = interruptedError
+		job.FinishedAt = time.Now()
+		if err := q.store.UpdateJob(ctx, job); err != nil {
+			return recovered, err
+		}
+		recovered++
+	}
+	return recovered, nil
+}
+
+// maxRecoveredJobs bounds how many unfinished jobs of each state Recover looks at
+const maxRecoveredJobs = 1000
@@ -0,0 +1,170 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/db/file"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+func newTestQueue(t *testing.T, workers int) (*Queue, *file.DB) {
+	store, err := file.NewDB("")
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	queue := New(store, Options{
+		Workers:     workers,
+		MaxAttempts: 3,
+		RetryDelay:  time.Millisecond,
+		Logger:      log.New(io.Discard, "", 0),
+	})
+	return queue, store
+}
+
+func TestQueueRetries(t *testing.T) {
+	ctx := context.Background()
+	queue, _ := newTestQueue(t, 1)
+
+	runs := 0
+	queue.Handle("flaky", func(ctx context.Context, job *models.Job) error {
+		runs++
+		if runs < 2 {
+			return errors.New("temporary failure")
+		}
+		return nil
+	})
+	queue.Handle("broken", func(ctx context.Context, job *models.Job) error {
+		return errors.New("permanent failure")
+	})
+
+	job, err := queue.Enqueue(ctx, "flaky", "pingcap/tidb")
+	if err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+	job, _ = queue.Wait(ctx, job.ID)
+	if job.State != models.JobStateSucceeded || job.Attempts != 2 || job.Error != "" {
+		t.Errorf("flaky job = %+v, want succeeded on the second attempt", job)
+	}
+
+	job, _ = queue.Enqueue(ctx, "broken", "pingcap/tidb")
+	job, _ = queue.Wait(ctx, job.ID)
+	if job.State != models.JobStateFailed || job.Attempts != 3 || job.Error != "permanent failure" {
+		t.Errorf("broken job = %+v, want failed after 3 attempts", job)
+	}
+
+	if _, err := queue.Enqueue(ctx, "missing", ""); !errors.Is(err, ErrUnknownType) {
+		t.Errorf("Enqueue() error = %v, want ErrUnknownType", err)
+	}
+}
+
+func TestQueueCancel(t *testing.T) {
+	ctx := context.Background()
+	queue, _ := newTestQueue(t, 1)
+
+	started := make(chan struct{})
+	queue.Handle("block", func(ctx context.Context, job *models.Job) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	queue.Handle("noop", func(ctx context.Context, job *models.Job) error { return nil })
+
+	running, _ := queue.Enqueue(ctx, "block", "")
+	<-started
+	// The only worker is busy, so this job stays queued
+	queued, _ := queue.Enqueue(ctx, "noop", "")
+
+	job, err := queue.Cancel(ctx, queued.ID)
+	if err != nil || job.State != models.JobStateCanceled || job.Attempts != 0 {
+		t.Errorf("Cancel() queued job = %+v, %v, want canceled before running", job, err)
+	}
+	job, err = queue.Cancel(ctx, running.ID)
+	if err != nil || job.State != models.JobStateCanceled || job.Attempts != 1 {
+		t.Errorf("Cancel() running job = %+v, %v, want canceled", job, err)
+	}
+	if _, err := queue.Cancel(ctx, running.ID); !errors.Is(err, ErrFinished) {
+		t.Errorf("Cancel() finished job error = %v, want ErrFinished", err)
+	}
+
+	jobs, total, err := queue.List(ctx, &models.JobFilter{State: models.JobStateCanceled, Page: 1, PerPage: 10})
+	if err != nil || total != 2 || jobs[0].ID != queued.ID {
+		t.Errorf("List() = %d jobs, %v, want both jobs newest first", total, err)
+	}
+}
+
+func TestQueueRecover(t *testing.T) {
+	ctx := context.Background()
+	queue, store := newTestQueue(t, 1)
+
+	// A job left running by a process that exited
+	orphan := &models.Job{Type: "noop", State: models.JobStateRunning, Attempts: 1, CreatedAt: time.Now()}
+	if err := store.AddJob(ctx, orphan); err != nil {
+		t.Fatalf("AddJob() error = %v", err)
+	}
+
+	recovered, err := queue.Recover(ctx)
+	if err != nil || recovered != 1 {
+		t.Fatalf("Recover() = %d, %v, want 1", recovered, err)
+	}
+	job, _ := queue.Get(ctx, orphan.ID)
+	if job.State != models.JobStateFailed || job.Error != interruptedError {
+		t.Errorf("recovered job = %+v, want failed as interrupted", job)
+	}
+}
+
+func TestQueuePriority(t *testing.T) {
+	ctx := context.Background()
+	queue, _ := newTestQueue(t, 1)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	queue.Handle("block", func(ctx context.Context, job *models.Job) error {
+		close(started)
+		<-release
+		return nil
+	})
+	var order []string
+	queue.Handle("record", func(ctx context.Context, job *models.Job) error {
+		order = append(order, job.Target)
+		return nil
+	})
+
+	blocking, _ := queue.Enqueue(ctx, "block", "")
+	<-started
+
+	// Jobs wait for the busy worker and then run by priority, in order within a priority
+	var queued []*models.Job
+	for _, target := range []struct {
+		name     string
+		priority int
+	}{{"low", 0}, {"high", 2}, {"normal-1", 1}, {"normal-2", 1}} {
+		job, err := queue.EnqueueWithPriority(ctx, "record", target.name, target.priority)
+		if err != nil {
+			t.Fatalf("EnqueueWithPriority() error = %v", err)
+		}
+		queued = append(queued, job)
+	}
+	// Give the jobs time to line up behind the busy worker
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+
+	queue.Wait(ctx, blocking.ID)
+	for _, job := range queued {
+		queue.Wait(ctx, job.ID)
+	}
+
+	want := []string{"high", "normal-1", "normal-2", "low"}
+	if len(order) != len(want) {
+		t.Fatalf("ran %v, want %v", order, want)
+	}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("ran %v, want %v", order, want)
+		}
+	}
+}
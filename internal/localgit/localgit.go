@@ -0,0 +1,291 @@
+// Package localgit finds local git clones and the GitHub repositories their remotes point to
+package localgit
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultHost is the GitHub host recognized in remote URLs besides the hosts gh is logged in to
+const DefaultHost = "github.com"
+
+// ErrNoRemote is returned when a clone has no remote pointing to the expected repository
+var ErrNoRemote = errors.New("no remote of the clone points to the repository")
+
+// skippedDirs are directories Scan never descends into, as they hold dependencies rather than clones
+var skippedDirs = map[string]bool{"node_modules": true, "vendor": true}
+
+// Remote is a git remote pointing to a GitHub repository
+type Remote struct {
+	Name       string // Such as origin or upstream
+	URL        string
+	Host       string // Such as github.com
+	Repository string // Full name, owner/name
+}
+
+// Clone is a local git repository with remotes pointing to GitHub
+type Clone struct {
+	Path    string
+	Remotes []Remote
+}
+
+// Hosts returns the GitHub hosts recognized in remote URLs: github.com and the hosts of gh's
+// hosts.yml, such as GitHub Enterprise servers gh is logged in to
+func Hosts() []string {
+	hosts := []string{DefaultHost}
+	data, err := os.ReadFile(filepath.Join(ghConfigDir(), "hosts.yml"))
+	if err != nil {
+		return hosts
+	}
+	var configured map[string]any
+	if err := yaml.Unmarshal(data, &configured); err != nil {
+		return hosts
+	}
+	for host := range configured {
+		if !strings.EqualFold(host, DefaultHost) {
+			hosts = append(hosts, strings.ToLower(host))
+		}
+	}
+	sort.Strings(hosts[1:])
+	return hosts
+}
+
+// ghConfigDir returns the configuration directory of gh, following its own lookup order
+func ghConfigDir() string {
+	if dir := os.Getenv("GH_CONFIG_DIR"); dir != "" {
+		return dir
+	}
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "gh")
+	}
+	if dir := os.Getenv("AppData"); dir != "" {
+		return filepath.Join(dir, "GitHub CLI")
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".config", "gh")
+}
+
+// ParseRemoteURL returns the host and full name of the GitHub repository a remote URL points to,
+// reporting false for URLs of other hosts. HTTPS, SSH, git and scp-like (git@host:owner/name)
+// URLs are understood.
+func ParseRemoteURL(rawURL string, hosts []string) (host, fullName string, ok bool) {
+	var path string
+	if strings.Contains(rawURL, "://") {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return "", "", false
+		}
+		host, path = u.Hostname(), u.Path
+	} else {
+		// scp-like syntax: [user@]host:owner/name
+		address, rest, found := strings.Cut(rawURL, ":")
+		if !found || strings.Contains(address, "/") {
+			return "", "", false
+		}
+		if _, h, found := strings.Cut(address, "@"); found {
+			address = h
+		}
+		host, path = address, rest
+	}
+
+	host = strings.ToLower(host)
+	known := false
+	for _, h := range hosts {
+		if strings.EqualFold(h, host) {
+			known = true
+			break
+		}
+	}
+	if !known {
+		return "", "", false
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	owner, name, found := strings.Cut(path, "/")
+	if !found || owner == "" || name == "" || strings.Contains(name, "/") {
+		return "", "", false
+	}
+	return host, owner + "/" + name, true
+}
+
+// ReadRemotes returns the remotes of the clone at path that point to GitHub, ordered by name
+func ReadRemotes(path string, hosts []string) ([]Remote, error) {
+	configPath, err := gitConfigPath(path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(configPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var remotes []Remote
+	var remote string // Remote of the current section, if any
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			remote = ""
+			section := strings.TrimSpace(strings.Trim(line, "[]"))
+			if name, found := strings.CutPrefix(section, "remote "); found {
+				remote = strings.Trim(strings.TrimSpace(name), `"`)
+			}
+			continue
+		}
+		key, value, found := strings.Cut(line, "=")
+		if remote == "" || !found || !strings.EqualFold(strings.TrimSpace(key), "url") {
+			continue
+		}
+		value = strings.Trim(strings.TrimSpace(value), `"`)
+		if host, fullName, ok := ParseRemoteURL(value, hosts); ok {
+			remotes = append(remotes, Remote{Name: remote, URL: value, Host: host, Repository: fullName})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(remotes, func(i, j int) bool { return remotes[i].Name < remotes[j].Name })
+	return remotes, nil
+}
+
+// gitConfigPath returns the path of the git configuration of the clone at path. Worktrees and
+// submodules have a .git file pointing to their git directory, whose commondir file points to the
+// directory holding the configuration of worktrees.
+func gitConfigPath(path string) (string, error) {
+	gitDir := filepath.Join(path, ".git")
+	info, err := os.Stat(gitDir)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		data, err := os.ReadFile(gitDir)
+		if err != nil {
+			return "", err
+		}
+		dir, found := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:")
+		if !found {
+			return "", errors.New("invalid .git file in " + path)
+		}
+		gitDir = strings.TrimSpace(dir)
+		if !filepath.IsAbs(gitDir) {
+			gitDir = filepath.Join(path, gitDir)
+		}
+	}
+	if common, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		dir := strings.TrimSpace(string(common))
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(gitDir, dir)
+		}
+		gitDir = dir
+	}
+	return filepath.Join(gitDir, "config"), nil
+}
+
+// Scan finds the clones with GitHub remotes under root, down to maxDepth directories below it,
+// ordered by path. It doesn't look inside clones, hidden directories or dependency directories,
+// and skips directories it can't read.
+func Scan(root string, maxDepth int, hosts []string) ([]*Clone, error) {
+	root = filepath.Clean(root)
+	var clones []*Clone
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			return fs.SkipDir
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != root && (strings.HasPrefix(d.Name(), ".") || skippedDirs[d.Name()]) {
+			return fs.SkipDir
+		}
+
+		if _, err := os.Stat(filepath.Join(path, ".git")); err == nil {
+			if remotes, err := ReadRemotes(path, hosts); err == nil && len(remotes) > 0 {
+				clones = append(clones, &Clone{Path: path, Remotes: remotes})
+			}
+			return fs.SkipDir
+		}
+
+		if rel, err := filepath.Rel(root, path); err == nil && rel != "." && strings.Count(rel, string(filepath.Separator))+1 >= maxDepth {
+			return fs.SkipDir
+		}
+		return nil
+	})
+	return clones, err
+}
+
+// FindRemote returns the remote of the clone at path pointing to the repository fullName, preferring
+// origin, or ErrNoRemote when it has none
+func FindRemote(path, fullName string, hosts []string) (*Remote, error) {
+	remotes, err := ReadRemotes(path, hosts)
+	if err != nil {
+		return nil, err
+	}
+	var found *Remote
+	for i := range remotes {
+		if !strings.EqualFold(remotes[i].Repository, fullName) {
+			continue
+		}
+		if found == nil || remotes[i].Name == "origin" {
+			found = &remotes[i]
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("%w %s", ErrNoRemote, fullName)
+	}
+	return found, nil
+}
+
+// CheckoutOptions are the options of CheckoutPullRequest
+type CheckoutOptions struct {
+	Branch string // Local branch, the head branch of the pull request by default
+	Detach bool   // Check out the head commit without a branch
+	Force  bool   // Reset an existing local branch to the pull request's head
+}
+
+// CheckoutPullRequest fetches a pull request of the repository remote points to and checks it out
+// in the clone at path. It runs gh pr checkout, which adds the remotes of forks and sets the
+// upstream of the branch; its output goes to stdout and stderr.
+func CheckoutPullRequest(path string, remote *Remote, number int, opts CheckoutOptions, stdout, stderr io.Writer) error {
+	cmd := exec.Command("gh", checkoutArgs(remote, number, opts)...)
+	cmd.Dir = path
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to check out pull request #%d in %s: %w", number, path, err)
+	}
+	return nil
+}
+
+// checkoutArgs returns the gh arguments checking out a pull request
+func checkoutArgs(remote *Remote, number int, opts CheckoutOptions) []string {
+	repo := remote.Repository
+	if !strings.EqualFold(remote.Host, DefaultHost) {
+		repo = remote.Host + "/" + repo
+	}
+	args := []string{"pr", "checkout", strconv.Itoa(number), "--repo", repo}
+	if opts.Branch != "" {
+		args = append(args, "--branch", opts.Branch)
+	}
+	if opts.Detach {
+		args = append(args, "--detach")
+	}
+	if opts.Force {
+		args = append(args, "--force")
+	}
+	return args
+}
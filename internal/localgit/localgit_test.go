@@ -0,0 +1,152 @@
+package localgit
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseRemoteURL(t *testing.T) {
+	hosts := []string{"github.com", "ghe.example.com"}
+	tests := []struct {
+		url      string
+		host     string
+		fullName string
+		ok       bool
+	}{
+		{url: "https://github.com/pingcap/tidb.git", host: "github.com", fullName: "pingcap/tidb", ok: true},
+		{url: "https://user@github.com/pingcap/tidb/", host: "github.com", fullName: "pingcap/tidb", ok: true},
+		{url: "git@github.com:pingcap/tidb.git", host: "github.com", fullName: "pingcap/tidb", ok: true},
+		{url: "ssh://git@GitHub.com:22/pingcap/tidb", host: "github.com", fullName: "pingcap/tidb", ok: true},
+		{url: "git@ghe.example.com:team/tool.git", host: "ghe.example.com", fullName: "team/tool", ok: true},
+		{url: "https://gitlab.com/pingcap/tidb.git"},
+		{url: "https://github.com/pingcap"},
+		{url: "https://github.com/pingcap/tidb/tree/master"},
+		{url: "/srv/git/tidb.git"},
+	}
+	for _, tt := range tests {
+		host, fullName, ok := ParseRemoteURL(tt.url, hosts)
+		if host != tt.host || fullName != tt.fullName || ok != tt.ok {
+			t.Errorf("ParseRemoteURL(%q) = %q, %q, %t, want %q, %q, %t", tt.url, host, fullName, ok, tt.host, tt.fullName, tt.ok)
+		}
+	}
+}
+
+// writeClone creates a clone with a git configuration under root
+func writeClone(t *testing.T, root, path, config string) {
+	t.Helper()
+	dir := filepath.Join(root, path, ".git")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "config"), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestScan(t *testing.T) {
+	root := t.TempDir()
+	writeClone(t, root, "src/tidb", `[core]
+	bare = false
+[remote "origin"]
+	url = git@github.com:alice/tidb.git
+	fetch = +refs/heads/*:refs/remotes/origin/*
+[remote "upstream"]
+	url = https://github.com/pingcap/tidb.git
+[branch "master"]
+	remote = origin
+`)
+	writeClone(t, root, "src/tidb/vendor-copy/inner", "[remote \"origin\"]\n\turl = https://github.com/nested/clone\n")
+	writeClone(t, root, "src/gitlab", "[remote \"origin\"]\n\turl = https://gitlab.com/alice/tool\n")
+	writeClone(t, root, "node_modules/dep", "[remote \"origin\"]\n\turl = https://github.com/dep/dep\n")
+	writeClone(t, root, "a/b/c/deep", "[remote \"origin\"]\n\turl = https://github.com/too/deep\n")
+
+	// A worktree points to the git directory of its main clone
+	worktree := filepath.Join(root, "src", "tidb-wt")
+	gitDir := filepath.Join(root, "src", "tidb", ".git", "worktrees", "wt")
+	if err := os.MkdirAll(gitDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(gitDir, "commondir"), []byte("../..\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(worktree, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(worktree, ".git"), []byte("gitdir: "+gitDir+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	clones, err := Scan(root, 3, []string{DefaultHost})
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	var paths []string
+	for _, clone := range clones {
+		rel, _ := filepath.Rel(root, clone.Path)
+		paths = append(paths, filepath.ToSlash(rel))
+	}
+	if want := []string{"src/tidb", "src/tidb-wt"}; !reflect.DeepEqual(paths, want) {
+		t.Fatalf("Scan() found %v, want %v", paths, want)
+	}
+	want := []Remote{
+		{Name: "origin", URL: "git@github.com:alice/tidb.git", Host: "github.com", Repository: "alice/tidb"},
+		{Name: "upstream", URL: "https://github.com/pingcap/tidb.git", Host: "github.com", Repository: "pingcap/tidb"},
+	}
+	if !reflect.DeepEqual(clones[0].Remotes, want) {
+		t.Errorf("remotes = %+v, want %+v", clones[0].Remotes, want)
+	}
+	if !reflect.DeepEqual(clones[1].Remotes, want) {
+		t.Errorf("worktree remotes = %+v, want those of its main clone", clones[1].Remotes)
+	}
+}
+
+func TestHosts(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("GH_CONFIG_DIR", dir)
+	if got := Hosts(); !reflect.DeepEqual(got, []string{"github.com"}) {
+		t.Errorf("Hosts() without gh configuration = %v, want github.com", got)
+	}
+	config := "github.com:\n    user: alice\nGHE.example.com:\n    user: alice\n"
+	if err := os.WriteFile(filepath.Join(dir, "hosts.yml"), []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := Hosts(), []string{"github.com", "ghe.example.com"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Hosts() = %v, want %v", got, want)
+	}
+}
+
+func TestFindRemote(t *testing.T) {
+	root := t.TempDir()
+	writeClone(t, root, "tidb", "[remote \"fork\"]\n\turl = https://github.com/pingcap/tidb\n[remote \"origin\"]\n\turl = git@github.com:PingCAP/tidb.git\n")
+	hosts := []string{DefaultHost, "ghe.example.com"}
+
+	remote, err := FindRemote(filepath.Join(root, "tidb"), "pingcap/tidb", hosts)
+	if err != nil {
+		t.Fatalf("FindRemote() error = %v", err)
+	}
+	if remote.Name != "origin" {
+		t.Errorf("FindRemote() = %s, want origin among several matching remotes", remote.Name)
+	}
+	if _, err := FindRemote(filepath.Join(root, "tidb"), "pingcap/tikv", hosts); !errors.Is(err, ErrNoRemote) {
+		t.Errorf("FindRemote(other repository) error = %v, want ErrNoRemote", err)
+	}
+
+	tests := []struct {
+		remote Remote
+		opts   CheckoutOptions
+		want   []string
+	}{
+		{Remote{Host: "github.com", Repository: "pingcap/tidb"}, CheckoutOptions{}, []string{"pr", "checkout", "12", "--repo", "pingcap/tidb"}},
+		{Remote{Host: "ghe.example.com", Repository: "infra/deploy"}, CheckoutOptions{Branch: "review", Force: true},
+			[]string{"pr", "checkout", "12", "--repo", "ghe.example.com/infra/deploy", "--branch", "review", "--force"}},
+		{Remote{Host: "github.com", Repository: "pingcap/tidb"}, CheckoutOptions{Detach: true}, []string{"pr", "checkout", "12", "--repo", "pingcap/tidb", "--detach"}},
+	}
+	for _, tt := range tests {
+		if got := checkoutArgs(&tt.remote, 12, tt.opts); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("checkoutArgs(%+v, %+v) = %v, want %v", tt.remote, tt.opts, got, tt.want)
+		}
+	}
+}
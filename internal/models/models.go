@@ -2,21 +2,200 @@ package models
 
 import (
 	"encoding/json"
+	"fmt"
+	"strings"
 	"time"
 )
 
 // Repository represents a GitHub repository in the database
 type Repository struct {
-	Owner        string    `db:"owner"`
-	Name         string    `db:"name"`
-	FullName     string    `db:"full_name"`
-	Description  string    `db:"description"`
-	URL          string    `db:"url"`
-	HTMLURL      string    `db:"html_url"`
-	IsPrivate    bool      `db:"is_private"`
-	LastSyncedAt time.Time `db:"last_synced_at"`
-	CreatedAt    time.Time `db:"created_at"`
-	UpdatedAt    time.Time `db:"updated_at"`
+	Owner        string               `db:"owner"`
+	Name         string               `db:"name"`
+	FullName     string               `db:"full_name"`
+	Description  string               `db:"description"`
+	URL          string               `db:"url"`
+	HTMLURL      string               `db:"html_url"`
+	IsPrivate    bool                 `db:"is_private"`
+	Tags         []string             `db:"tags"`
+	SyncConfig   RepositorySyncConfig `db:"sync_config"`
+	Paused       bool                 `db:"paused"`
+	TrackedBy    string               `db:"tracked_by"` // TrackedByStarred when tracked because the user starred it, "" when added explicitly
+	LocalPath    string               `db:"local_path"` // Local clone pull requests are checked out in, if any
+	LastSyncedAt time.Time            `db:"last_synced_at"`
+	CreatedAt    time.Time            `db:"created_at"`
+	UpdatedAt    time.Time            `db:"updated_at"`
+
+	// When each kind of data was last fetched, used for cache TTLs
+	MetadataSyncedAt     time.Time `db:"metadata_synced_at"`
+	PullRequestsSyncedAt time.Time `db:"pull_requests_synced_at"`
+	IssuesSyncedAt       time.Time `db:"issues_synced_at"`
+	AlertsSyncedAt       time.Time `db:"alerts_synced_at"`
+	DiscussionsSyncedAt  time.Time `db:"discussions_synced_at"`
+
+	// Merge settings and default branch protection, captured when compliance reporting is enabled
+	Settings *RepositorySettings `db:"settings"`
+
+	// Rules of the CODEOWNERS file, synced when code owners are enabled
+	CodeOwners []CodeOwnersRule `db:"code_owners"`
+
+	// Label set and issue templates, synced when labels are enabled
+	Labels         []Label         `db:"labels"`
+	IssueTemplates []IssueTemplate `db:"issue_templates"`
+
+	// GitHub API requests spent on the repository
+	APIUsage APIUsage `db:"api_usage"`
+
+	// Incremented by the database on every update, used to detect concurrent writes
+	Version int64 `db:"version"`
+}
+
+// TrackedByStarred marks repositories tracked automatically because the authenticated user starred them
+const TrackedByStarred = "starred"
+
+// APIUsage counts the GitHub API requests spent on a repository
+type APIUsage struct {
+	LastSyncRequests int   `db:"last_sync_requests" json:"last_sync_requests"`
+	TotalRequests    int64 `db:"total_requests" json:"total_requests"` // Including failed syncs and cache refreshes
+	Syncs            int64 `db:"syncs" json:"syncs"`                   // Completed syncs
+}
+
+// AverageSyncRequests returns the mean number of requests per completed sync
+func (u APIUsage) AverageSyncRequests() float64 {
+	if u.Syncs == 0 {
+		return 0
+	}
+	return float64(u.TotalRequests) / float64(u.Syncs)
+}
+
+// RepositorySyncConfig holds per-repository overrides of the global sync settings.
+// Zero values and nil pointers fall back to the global defaults.
+type RepositorySyncConfig struct {
+	SyncInterval     time.Duration `db:"sync_interval"`
+	SyncPullRequests *bool         `db:"sync_pull_requests"`
+	SyncIssues       *bool         `db:"sync_issues"`
+	SyncReviews      *bool         `db:"sync_reviews"`
+	SyncDiscussions  *bool         `db:"sync_discussions"` // Off unless enabled, since most repositories use issues
+	ItemLimit        int           `db:"item_limit"`
+	Priority         string        `db:"priority"` // One of the SyncPriority classes, empty means normal
+}
+
+// Sync priority classes. Due repositories of a higher class are synced first.
+const (
+	SyncPriorityHigh   = "high"
+	SyncPriorityNormal = "normal"
+	SyncPriorityLow    = "low"
+)
+
+// ValidSyncPriority reports whether p is a sync priority class or empty
+func ValidSyncPriority(p string) bool {
+	switch p {
+	case "", SyncPriorityHigh, SyncPriorityNormal, SyncPriorityLow:
+		return true
+	}
+	return false
+}
+
+// PriorityClass returns the sync priority class of the repository
+func (c RepositorySyncConfig) PriorityClass() string {
+	if c.Priority == "" {
+		return SyncPriorityNormal
+	}
+	return c.Priority
+}
+
+// ShouldSyncPullRequests reports whether pull requests are synced for the repository
+func (c RepositorySyncConfig) ShouldSyncPullRequests() bool {
+	return c.SyncPullRequests == nil || *c.SyncPullRequests
+}
+
+// ShouldSyncIssues reports whether issues are synced for the repository
+func (c RepositorySyncConfig) ShouldSyncIssues() bool {
+	return c.SyncIssues == nil || *c.SyncIssues
+}
+
+// ShouldSyncReviews reports whether reviews are synced for the repository
+func (c RepositorySyncConfig) ShouldSyncReviews() bool {
+	return c.SyncReviews == nil || *c.SyncReviews
+}
+
+// ShouldSyncDiscussions reports whether discussions are synced for the repository
+func (c RepositorySyncConfig) ShouldSyncDiscussions() bool {
+	return c.SyncDiscussions != nil && *c.SyncDiscussions
+}
+
+// RepositorySyncConfigUpdate represents a partial update of a repository's sync configuration.
+// Only non-nil fields are applied.
+type RepositorySyncConfigUpdate struct {
+	SyncInterval     *time.Duration
+	SyncPullRequests *bool
+	SyncIssues       *bool
+	SyncReviews      *bool
+	SyncDiscussions  *bool
+	ItemLimit        *int
+	Priority         *string
+}
+
+// Apply applies the update to the given sync configuration
+func (u *RepositorySyncConfigUpdate) Apply(c *RepositorySyncConfig) {
+	if u.SyncInterval != nil {
+		c.SyncInterval = *u.SyncInterval
+	}
+	if u.SyncPullRequests != nil {
+		c.SyncPullRequests = u.SyncPullRequests
+	}
+	if u.SyncIssues != nil {
+		c.SyncIssues = u.SyncIssues
+	}
+	if u.SyncReviews != nil {
+		c.SyncReviews = u.SyncReviews
+	}
+	if u.SyncDiscussions != nil {
+		c.SyncDiscussions = u.SyncDiscussions
+	}
+	if u.ItemLimit != nil {
+		c.ItemLimit = *u.ItemLimit
+	}
+	if u.Priority != nil {
+		c.Priority = *u.Priority
+	}
+}
+
+// String lists the settings the update changes, such as "sync_interval=2h0m0s sync_issues=false"
+func (u *RepositorySyncConfigUpdate) String() string {
+	var changes []string
+	if u.SyncInterval != nil {
+		changes = append(changes, fmt.Sprintf("sync_interval=%s", *u.SyncInterval))
+	}
+	for _, setting := range []struct {
+		name  string
+		value *bool
+	}{
+		{"sync_pull_requests", u.SyncPullRequests},
+		{"sync_issues", u.SyncIssues},
+		{"sync_reviews", u.SyncReviews},
+		{"sync_discussions", u.SyncDiscussions},
+	} {
+		if setting.value != nil {
+			changes = append(changes, fmt.Sprintf("%s=%t", setting.name, *setting.value))
+		}
+	}
+	if u.ItemLimit != nil {
+		changes = append(changes, fmt.Sprintf("item_limit=%d", *u.ItemLimit))
+	}
+	if u.Priority != nil {
+		changes = append(changes, fmt.Sprintf("priority=%s", *u.Priority))
+	}
+	return strings.Join(changes, " ")
+}
+
+// HasTag reports whether the repository carries the given tag
+func (r *Repository) HasTag(tag string) bool {
+	for _, t := range r.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
 }
 
 // MarshalJSON customizes JSON marshaling for Repository
@@ -37,21 +216,303 @@ func (r *Repository) MarshalJSON() ([]byte, error) {
 
 // PullRequest represents a GitHub pull request in the database
 type PullRequest struct {
-	RepositoryFullName string     `db:"repository_full_name"`
-	Number             int        `db:"number"`
-	Title              string     `db:"title"`
-	Body               string     `db:"body"`
-	State              string     `db:"state"`
-	URL                string     `db:"url"`
-	HTMLURL            string     `db:"html_url"`
-	UserLogin          string     `db:"user_login"`
-	UserAvatarURL      string     `db:"user_avatar_url"`
-	UserURL            string     `db:"user_url"`
-	UserHTMLURL        string     `db:"user_html_url"`
-	CreatedAt          time.Time  `db:"created_at"`
-	UpdatedAt          time.Time  `db:"updated_at"`
-	ClosedAt           *time.Time `db:"closed_at"`
-	MergedAt           *time.Time `db:"merged_at"`
+	RepositoryFullName string              `db:"repository_full_name"`
+	Number             int                 `db:"number"`
+	Title              string              `db:"title"`
+	Body               string              `db:"body"`
+	State              string              `db:"state"`
+	Draft              bool                `db:"draft"`
+	URL                string              `db:"url"`
+	HTMLURL            string              `db:"html_url"`
+	UserLogin          string              `db:"user_login"`
+	UserAvatarURL      string              `db:"user_avatar_url"`
+	UserURL            string              `db:"user_url"`
+	UserHTMLURL        string              `db:"user_html_url"`
+	UserIsBot          bool                `db:"user_is_bot"`
+	AuthorAssociation  string              `db:"author_association"` // MEMBER, CONTRIBUTOR, FIRST_TIMER, ...
+	Assignees          []string            `db:"assignees"`
+	Milestone          string              `db:"milestone"` // Title of the milestone, if any
+	RequestedReviewers []string            `db:"requested_reviewers"`
+	RequestedTeams     []string            `db:"requested_teams"` // Team slugs
+	Mentions           []string            `db:"mentions"`        // Users and org/team slugs mentioned in the body
+	JiraKeys           []string            `db:"jira_keys"`       // Jira issue keys in the title and body
+	References         []string            `db:"references"`      // Items of other repositories referenced in the title and body, as owner/name#number
+	ReferencedBy       []string            `db:"-"`               // Items of tracked repositories referencing it, filled in when getting it
+	Additions          int                 `db:"additions"`
+	Deletions          int                 `db:"deletions"`
+	ChangedFiles       int                 `db:"changed_files"`
+	Files              []string            `db:"files"` // Paths changed, at most 100, synced when enabled
+	CreatedAt          time.Time           `db:"created_at"`
+	UpdatedAt          time.Time           `db:"updated_at"`
+	ClosedAt           *time.Time          `db:"closed_at"`
+	MergedAt           *time.Time          `db:"merged_at"`
+	ReviewDecision     string              `db:"review_decision"`
+	FirstReviewAt      *time.Time          `db:"first_review_at"`
+	Reviews            []PullRequestReview `db:"reviews"`
+	StateHistory       StateHistory        `db:"state_history"`
+	Tombstoned         bool                `db:"tombstoned"` // No longer present upstream
+	TombstonedAt       *time.Time          `db:"tombstoned_at"`
+}
+
+// PullRequestCreate represents a pull request to open in a tracked repository
+type PullRequestCreate struct {
+	Title string
+	Body  string
+	Base  string // Branch the changes are pulled into, the default branch when empty
+	Head  string // Branch holding the changes, prefixed with owner: for forks
+}
+
+// ItemUpdate represents a change to the assignees or milestone of a pull request or issue
+type ItemUpdate struct {
+	AddAssignees    []string `json:"add_assignees,omitempty"`
+	RemoveAssignees []string `json:"remove_assignees,omitempty"`
+	Milestone       *string  `json:"milestone,omitempty"` // Title of the milestone, empty to remove it; nil leaves it unchanged
+}
+
+// Actions applied by bulk requests
+const (
+	BulkActionClose   = "close"
+	BulkActionLabel   = "label"
+	BulkActionComment = "comment"
+)
+
+// Outcomes of a bulk action on an item
+const (
+	BulkItemPlanned = "planned" // Dry runs only
+	BulkItemDone    = "done"
+	BulkItemSkipped = "skipped"
+	BulkItemFailed  = "failed"
+)
+
+// BulkFilter selects the pull requests and issues a bulk action applies to
+type BulkFilter struct {
+	Type    string    `json:"type,omitempty"`  // ItemTypePullRequest, ItemTypeIssue or empty for both
+	State   string    `json:"state,omitempty"` // open by default, closed or all
+	Repo    string    `json:"repo,omitempty"`
+	RepoTag string    `json:"repo_tag,omitempty"`
+	Author  string    `json:"author,omitempty"`
+	Label   string    `json:"label,omitempty"`
+	Since   time.Time `json:"since,omitempty"` // Updated since
+
+	// AllRepos must be set to act on every tracked repository, without Repo or RepoTag
+	AllRepos bool `json:"all_repos,omitempty"`
+}
+
+// BulkRequest represents an action applied to every pull request and issue matching a filter
+type BulkRequest struct {
+	Action      string     `json:"action"`            // BulkActionClose, BulkActionLabel or BulkActionComment
+	Label       string     `json:"label,omitempty"`   // Label added by BulkActionLabel
+	Comment     string     `json:"comment,omitempty"` // Body of the comment added by BulkActionComment
+	Filter      BulkFilter `json:"filter"`
+	DryRun      bool       `json:"dry_run,omitempty"`     // Only report the items the action would apply to
+	Concurrency int        `json:"concurrency,omitempty"` // Items updated at once; 0 uses the default
+}
+
+// BulkResult represents the outcome of a bulk action on an item
+type BulkResult struct {
+	Type               string `json:"type"`
+	RepositoryFullName string `json:"repository_full_name"`
+	Number             int    `json:"number"`
+	Title              string `json:"title"`
+	Status             string `json:"status"` // BulkItemPlanned, BulkItemDone, BulkItemSkipped or BulkItemFailed
+	Reason             string `json:"reason,omitempty"`
+}
+
+// BulkReport represents the outcome of a bulk request, with the results ordered by repository and number
+type BulkReport struct {
+	Action  string        `json:"action"`
+	DryRun  bool          `json:"dry_run"`
+	Matched int           `json:"matched"`
+	Done    int           `json:"done"`
+	Skipped int           `json:"skipped"`
+	Failed  int           `json:"failed"`
+	Results []*BulkResult `json:"results"`
+}
+
+// TriageRule applies actions to the open pull requests and issues matching its conditions after each
+// sync. Every condition given must hold, and a rule acts on an item once.
+type TriageRule struct {
+	ID         int64           `json:"id" yaml:"-"`
+	Name       string          `json:"name" yaml:"name"`
+	Type       string          `json:"type,omitempty" yaml:"type"`             // ItemTypePullRequest, ItemTypeIssue or empty for both
+	Repository string          `json:"repository,omitempty" yaml:"repository"` // Empty for every tracked repository
+	If         TriageCondition `json:"if" yaml:"if"`
+	Then       TriageActions   `json:"then" yaml:"then"`
+	CreatedAt  time.Time       `json:"created_at" yaml:"-"`
+}
+
+// TriageCondition is what an item must match for a triage rule to act on it
+type TriageCondition struct {
+	TitleMatches string `json:"title_matches,omitempty" yaml:"title_matches"` // Regular expression
+	Author       string `json:"author,omitempty" yaml:"author"`               // Matched ignoring case
+	NoLabelDays  int    `json:"no_label_days,omitempty" yaml:"no_label_days"` // Still unlabeled this many days after it was opened
+}
+
+// TriageActions is what a triage rule does to the items it matches
+type TriageActions struct {
+	AddLabel string `json:"add_label,omitempty" yaml:"add_label"`
+	Comment  string `json:"comment,omitempty" yaml:"comment"` // Go template over the item, such as "Thanks @{{.Author}}"
+	Notify   bool   `json:"notify,omitempty" yaml:"notify"`   // Fire a triage.matched notification
+}
+
+// TriageExecution records a triage rule acting on an item
+type TriageExecution struct {
+	RuleID             int64     `json:"rule_id"`
+	Type               string    `json:"type"` // ItemTypePullRequest or ItemTypeIssue
+	RepositoryFullName string    `json:"repository_full_name"`
+	Number             int       `json:"number"`
+	Title              string    `json:"title"`
+	Actions            []string  `json:"actions"` // Actions applied, such as "label needs-triage"
+	Error              string    `json:"error,omitempty"`
+	ExecutedAt         time.Time `json:"executed_at"`
+}
+
+// Pull request sizes by lines changed, from smallest to largest
+const (
+	SizeXS  = "XS"
+	SizeS   = "S"
+	SizeM   = "M"
+	SizeL   = "L"
+	SizeXL  = "XL"
+	SizeXXL = "XXL"
+)
+
+// sizeLimits are the lines changed below which a pull request has each size; larger ones are XXL
+var sizeLimits = []struct {
+	size  string
+	limit int
+}{{SizeXS, 10}, {SizeS, 30}, {SizeM, 100}, {SizeL, 500}, {SizeXL, 1000}}
+
+// ValidSize reports whether size names a pull request size
+func ValidSize(size string) bool {
+	if size == SizeXXL {
+		return true
+	}
+	for _, s := range sizeLimits {
+		if s.size == size {
+			return true
+		}
+	}
+	return false
+}
+
+// Size returns the size of the pull request by its added and deleted lines
+func (pr *PullRequest) Size() string {
+	lines := pr.Additions + pr.Deletions
+	for _, s := range sizeLimits {
+		if lines < s.limit {
+			return s.size
+		}
+	}
+	return SizeXXL
+}
+
+// Formats of pull request changes
+const (
+	DiffFormatDiff  = "diff"  // Unified diff
+	DiffFormatPatch = "patch" // Series of patches in git format-patch form, one per commit
+)
+
+// PullRequestDiff holds the changes of a pull request in one format, as fetched at FetchedAt
+type PullRequestDiff struct {
+	RepositoryFullName string    `db:"repository_full_name" json:"repository"`
+	Number             int       `db:"number" json:"number"`
+	Format             string    `db:"format" json:"format"`
+	Content            string    `db:"content" json:"content"`
+	FetchedAt          time.Time `db:"fetched_at" json:"fetched_at"`
+	Cached             bool      `db:"-" json:"cached"` // Served from the local store rather than fetched
+}
+
+// Review queue statuses, by how long a pull request has waited for review
+const (
+	ReviewStatusOK      = "ok"
+	ReviewStatusDueSoon = "due_soon"
+	ReviewStatusOverdue = "overdue"
+)
+
+// ReviewQueueItem represents a pull request waiting for review from a reviewer
+type ReviewQueueItem struct {
+	PullRequest  *PullRequest `json:"pull_request"`
+	WaitingSince time.Time    `json:"waiting_since"` // Creation, or the last time it was marked ready for review
+	Status       string       `json:"status"`        // ReviewStatusOK, ReviewStatusDueSoon or ReviewStatusOverdue
+}
+
+// ReviewQueueFilter represents the filter of the review queue
+type ReviewQueueFilter struct {
+	Reviewer string // Login, the configured reviewer when empty
+	Repo     string
+	RepoTag  string
+}
+
+// SLABreach represents an open pull request or issue past the deadline of an SLA policy
+type SLABreach struct {
+	Policy             string    `json:"policy"`
+	RepositoryFullName string    `json:"repository"`
+	Type               string    `json:"type"` // ItemTypePullRequest or ItemTypeIssue
+	Number             int       `json:"number"`
+	Title              string    `json:"title"`
+	HTMLURL            string    `json:"html_url"`
+	Author             string    `json:"author"`
+	Teams              []string  `json:"teams,omitempty"` // Configured teams involved by author, assignee, reviewer or mention
+	Deadline           time.Time `json:"deadline"`
+}
+
+// SLAReport lists the current SLA breaches, most overdue first, with their counts per repository and team
+type SLAReport struct {
+	Breaches     []*SLABreach   `json:"breaches"`
+	ByRepository map[string]int `json:"by_repository"`
+	ByTeam       map[string]int `json:"by_team"`
+}
+
+// SLAFilter represents the filter of the SLA report
+type SLAFilter struct {
+	Repo    string
+	RepoTag string
+	Team    string
+	Policy  string
+}
+
+// Periods pull requests and issues are aggregated by
+const (
+	AggregateWeekly  = "weekly"
+	AggregateMonthly = "monthly"
+)
+
+// Bucket counts the pull requests or issues created and closed in a period
+type Bucket struct {
+	Start   time.Time `json:"start"` // Monday of the week or first day of the month, in UTC
+	Created int       `json:"created"`
+	Closed  int       `json:"closed"`           // Including merged pull requests
+	Merged  int       `json:"merged,omitempty"` // Pull requests only
+}
+
+// StateTransition represents a change of state observed between two syncs.
+// Pull requests also record "draft" to "ready" transitions and back.
+type StateTransition struct {
+	From string    `db:"from" json:"from"`
+	To   string    `db:"to" json:"to"`
+	At   time.Time `db:"at" json:"at"`
+}
+
+// StateHistory lists the state transitions of a pull request or issue, oldest first
+type StateHistory []StateTransition
+
+// Reopened returns how many times the item was reopened after being closed
+func (h StateHistory) Reopened() int {
+	count := 0
+	for _, t := range h {
+		if t.From == "closed" && t.To == "open" {
+			count++
+		}
+	}
+	return count
+}
+
+// PullRequestReview represents a review submitted on a pull request
+type PullRequestReview struct {
+	UserLogin   string    `db:"user_login"`
+	State       string    `db:"state"`
+	SubmittedAt time.Time `db:"submitted_at"`
 }
 
 // MarshalJSON customizes JSON marshaling for PullRequest
@@ -90,20 +551,32 @@ func (pr *PullRequest) MarshalJSON() ([]byte, error) {
 
 // Issue represents a GitHub issue in the database
 type Issue struct {
-	RepositoryFullName string     `db:"repository_full_name"`
-	Number             int        `db:"number"`
-	Title              string     `db:"title"`
-	Body               string     `db:"body"`
-	State              string     `db:"state"`
-	URL                string     `db:"url"`
-	HTMLURL            string     `db:"html_url"`
-	UserLogin          string     `db:"user_login"`
-	UserAvatarURL      string     `db:"user_avatar_url"`
-	UserURL            string     `db:"user_url"`
-	UserHTMLURL        string     `db:"user_html_url"`
-	CreatedAt          time.Time  `db:"created_at"`
-	UpdatedAt          time.Time  `db:"updated_at"`
-	ClosedAt           *time.Time `db:"closed_at"`
+	RepositoryFullName string       `db:"repository_full_name"`
+	Number             int          `db:"number"`
+	Title              string       `db:"title"`
+	Body               string       `db:"body"`
+	State              string       `db:"state"`
+	URL                string       `db:"url"`
+	HTMLURL            string       `db:"html_url"`
+	UserLogin          string       `db:"user_login"`
+	UserAvatarURL      string       `db:"user_avatar_url"`
+	UserURL            string       `db:"user_url"`
+	UserHTMLURL        string       `db:"user_html_url"`
+	UserIsBot          bool         `db:"user_is_bot"`
+	AuthorAssociation  string       `db:"author_association"` // MEMBER, CONTRIBUTOR, FIRST_TIMER, ...
+	Assignees          []string     `db:"assignees"`
+	Milestone          string       `db:"milestone"`  // Title of the milestone, if any
+	Mentions           []string     `db:"mentions"`   // Users and org/team slugs mentioned in the body
+	JiraKeys           []string     `db:"jira_keys"`  // Jira issue keys in the title and body
+	References         []string     `db:"references"` // Items of other repositories referenced in the title and body, as owner/name#number
+	ReferencedBy       []string     `db:"-"`          // Items of tracked repositories referencing it, filled in when getting it
+	Tasks              []IssueTask  `db:"tasks"`      // Issues in the task list of the body, the children of an epic
+	CreatedAt          time.Time    `db:"created_at"`
+	UpdatedAt          time.Time    `db:"updated_at"`
+	ClosedAt           *time.Time   `db:"closed_at"`
+	StateHistory       StateHistory `db:"state_history"`
+	Tombstoned         bool         `db:"tombstoned"` // No longer present upstream
+	TombstonedAt       *time.Time   `db:"tombstoned_at"`
 }
 
 // MarshalJSON customizes JSON marshaling for Issue
@@ -141,6 +614,68 @@ type Label struct {
 	Description string `db:"description"`
 }
 
+// IssueTemplate represents an issue template of a repository
+type IssueTemplate struct {
+	Name     string   `db:"name" json:"name"`
+	About    string   `db:"about" json:"about,omitempty"`
+	Title    string   `db:"title" json:"title,omitempty"` // Default title of the issues created with it
+	Filename string   `db:"filename" json:"filename"`
+	Labels   []string `db:"labels" json:"labels,omitempty"` // Labels applied to the issues created with it
+}
+
+// LabelVariant is a spelling and color of a label shared by some repositories
+type LabelVariant struct {
+	Name         string   `json:"name"`
+	Color        string   `json:"color"`
+	Description  string   `json:"description,omitempty"`
+	Repositories []string `json:"repositories"`
+}
+
+// LabelSummary groups the labels of the tracked repositories whose names only differ by case
+type LabelSummary struct {
+	Name            string          `json:"name"` // Spelling used by the most repositories
+	Repositories    int             `json:"repositories"`
+	Variants        []*LabelVariant `json:"variants"`
+	Inconsistencies []string        `json:"inconsistencies,omitempty"` // Colors or spellings differing, templates using it where it's missing
+}
+
+// LabelFilter represents filter options for the label registry
+type LabelFilter struct {
+	Repo             string
+	RepoTag          string
+	Name             string // Labels with this name, ignoring case
+	InconsistentOnly bool
+}
+
+// LabelRename represents renaming or recoloring a label across the tracked repositories
+type LabelRename struct {
+	Label   string // Current name, matched ignoring case
+	NewName string // Empty keeps the name
+	Color   string // Hex color, with or without #; empty keeps the color
+	Repo    string
+	RepoTag string
+	DryRun  bool // Only plan the changes
+}
+
+// Outcomes of a label change in a repository
+const (
+	LabelChangePlanned = "planned"
+	LabelChangeUpdated = "updated"
+	LabelChangeSkipped = "skipped"
+	LabelChangeFailed  = "failed"
+)
+
+// LabelChange is the change of a label in a repository planned or made by a rename
+type LabelChange struct {
+	Repository string `json:"repository"`
+	Label      string `json:"label,omitempty"` // Spelling in the repository, empty when it doesn't have the label
+	NewName    string `json:"new_name,omitempty"`
+	Color      string `json:"color,omitempty"` // Current color
+	NewColor   string `json:"new_color,omitempty"`
+	Status     string `json:"status"`           // One of the LabelChange outcomes
+	Reason     string `json:"reason,omitempty"` // Why it was skipped or failed
+}
+
 // PullRequestLabel represents a many-to-many relationship between pull requests and labels
 type PullRequestLabel struct {
 	RepositoryFullName string `db:"repository_full_name"`
@@ -155,38 +690,941 @@ type IssueLabel struct {
 	LabelName          string `db:"label_name"`
 }
 
+// RepositoryAddResult represents the outcome of adding one repository in a bulk add
+type RepositoryAddResult struct {
+	FullName   string      `json:"full_name"`
+	Repository *Repository `json:"repository,omitempty"`
+	Existing   bool        `json:"existing"`
+	Job        *Job        `json:"job,omitempty"` // The sync queued for a newly added repository, when not waited for
+	Error      string      `json:"error,omitempty"`
+}
+
+// DiscoveredRepository represents an untracked repository the authenticated GitHub user is
+// related to, suggested for tracking
+type DiscoveredRepository struct {
+	FullName    string   `json:"full_name"`
+	Description string   `json:"description"`
+	URL         string   `json:"url"`
+	Private     bool     `json:"private"`
+	Relations   []string `json:"relations"` // owner, starred, contributor
+}
+
+// RepositoryFilter represents filter options for repositories
+type RepositoryFilter struct {
+	Tag     string
+	Cursor  string
+	Page    int
+	PerPage int
+}
+
+// Workspace groups a set of tracked repositories, such as those of one team, with its own
+// API tokens and sync schedule. Pull requests and issues are stored once however many
+// workspaces include their repository.
+type Workspace struct {
+	ID           string            `db:"id"` // Lowercase slug used in routes and --workspace
+	Name         string            `db:"name"`
+	Repositories []string          `db:"repositories"`  // Full names, sorted
+	SyncInterval time.Duration     `db:"sync_interval"` // Default interval of its repositories, 0 uses the global one
+	Tokens       []*WorkspaceToken `db:"tokens"`
+	CreatedAt    time.Time         `db:"created_at"`
+	UpdatedAt    time.Time         `db:"updated_at"`
+}
+
+// HasRepository reports whether the workspace includes a repository
+func (w *Workspace) HasRepository(fullName string) bool {
+	for _, name := range w.Repositories {
+		if name == fullName {
+			return true
+		}
+	}
+	return false
+}
+
+// WorkspaceUpdate represents a partial update of a workspace. Only non-nil fields are applied.
+type WorkspaceUpdate struct {
+	Name         *string
+	SyncInterval *time.Duration
+}
+
+// WorkspaceToken is an API token restricted to one workspace. Only a hash of the
+// token is stored; the token itself is shown once when it is created.
+type WorkspaceToken struct {
+	ID         int64     `db:"id"`
+	Name       string    `db:"name"`
+	Hash       string    `db:"hash" json:"-"` // Hex SHA-256 of the token
+	CreatedAt  time.Time `db:"created_at"`
+	LastUsedAt time.Time `db:"last_used_at"`
+}
+
+// Webhook represents a user registered endpoint receiving service events
+type Webhook struct {
+	ID        int64     `db:"id"`
+	URL       string    `db:"url"`
+	Secret    string    `db:"secret" json:"-"` // Signs payloads; never returned by the API
+	Events    []string  `db:"events"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// WebhookDelivery represents one attempt to deliver an event to a webhook
+type WebhookDelivery struct {
+	ID          int64         `db:"id"`
+	WebhookID   int64         `db:"webhook_id"`
+	Event       string        `db:"event"`
+	Repository  string        `db:"repository"`
+	StatusCode  int           `db:"status_code"`
+	Attempts    int           `db:"attempts"`
+	Error       string        `db:"error"`
+	Duration    time.Duration `db:"duration"`
+	DeliveredAt time.Time     `db:"delivered_at"`
+}
+
+// Subscription channels
+const (
+	SubscriptionChannelSlack   = "slack"
+	SubscriptionChannelWebhook = "webhook"
+)
+
+// Subscription routes the events of a label being added to pull requests and issues to a channel
+type Subscription struct {
+	ID         int64     `db:"id" json:"id"`
+	Label      string    `db:"label" json:"label"`           // Matched ignoring case
+	Repository string    `db:"repository" json:"repository"` // Empty for every tracked repository
+	Channel    string    `db:"channel" json:"channel"`       // SubscriptionChannelSlack or SubscriptionChannelWebhook
+	URL        string    `db:"url" json:"url"`               // Slack incoming webhook or webhook URL
+	Secret     string    `db:"secret" json:"-"`              // Signs webhook payloads; never returned by the API
+	CreatedAt  time.Time `db:"created_at" json:"created_at"`
+}
+
+// Job types
+const (
+	JobTypeSyncRepository = "sync_repository"
+)
+
+// Job states
+const (
+	JobStateQueued    = "queued"
+	JobStateRunning   = "running"
+	JobStateSucceeded = "succeeded"
+	JobStateFailed    = "failed"
+	JobStateCanceled  = "canceled"
+)
+
+// Job represents a background task such as a repository sync
+type Job struct {
+	ID          int64     `db:"id"`
+	Type        string    `db:"type"`
+	Target      string    `db:"target"` // What the job works on, such as a repository full name
+	State       string    `db:"state"`
+	Attempts    int       `db:"attempts"`
+	MaxAttempts int       `db:"max_attempts"`
+	Priority    int       `db:"priority"` // Queued jobs with a higher priority run first
+	Error       string    `db:"error"`    // Error of the latest failed attempt
+	CreatedAt   time.Time `db:"created_at"`
+	StartedAt   time.Time `db:"started_at"` // Start of the latest attempt
+	FinishedAt  time.Time `db:"finished_at"`
+}
+
+// Done reports whether the job has finished, successfully or not
+func (j *Job) Done() bool {
+	return j.State == JobStateSucceeded || j.State == JobStateFailed || j.State == JobStateCanceled
+}
+
+// JobFilter represents filter options for jobs
+type JobFilter struct {
+	Type    string
+	State   string
+	Target  string
+	Page    int
+	PerPage int
+}
+
+// JiraLink represents the pull requests and issues that reference a Jira issue
+type JiraLink struct {
+	Key   string  `json:"key"`
+	URL   string  `json:"url,omitempty"` // Set when a Jira base URL is configured
+	Items []*Item `json:"items"`
+}
+
+// JiraLinkFilter represents filter options for Jira links
+type JiraLinkFilter struct {
+	Key     string // A single Jira issue key
+	Project string // Keys of a Jira project, such as PROJ
+	Repo    string
+	RepoTag string
+}
+
+// ItemLink is a reference from a pull request or issue to an item of another tracked repository
+type ItemLink struct {
+	From      string `json:"from"` // owner/name#number
+	FromType  string `json:"from_type"`
+	FromTitle string `json:"from_title"`
+	To        string `json:"to"` // owner/name#number
+}
+
+// RepositoryLink counts the references from the items of a tracked repository to another
+type RepositoryLink struct {
+	From       string `json:"from"`
+	To         string `json:"to"`
+	References int    `json:"references"`
+}
+
+// LinkGraph is the graph of references between the items of tracked repositories
+type LinkGraph struct {
+	Repositories []*RepositoryLink `json:"repositories"` // Most references first
+	Items        []*ItemLink       `json:"items"`
+}
+
+// LinkFilter represents filter options for the link graph; a repository or tag keeps the links
+// from or to its repositories
+type LinkFilter struct {
+	Repo    string
+	RepoTag string
+}
+
+// IssueTask is an item of the task list of an issue referencing another issue, such as
+// "- [x] #12" or "- [ ] org/web#3"
+type IssueTask struct {
+	Reference string `db:"reference" json:"reference"` // owner/name#number
+	Checked   bool   `db:"checked" json:"checked"`
+}
+
+// IssueProgress counts the closed children of an epic
+type IssueProgress struct {
+	Closed int `json:"closed"`
+	Total  int `json:"total"`
+}
+
+// IssueTree is an issue with the issues in its task list, recursively
+type IssueTree struct {
+	Reference string         `json:"reference"` // owner/name#number
+	Title     string         `json:"title,omitempty"`
+	State     string         `json:"state,omitempty"`
+	HTMLURL   string         `json:"html_url,omitempty"`
+	Tracked   bool           `json:"tracked"` // Whether the issue is stored; the state of others comes from their task
+	Closed    bool           `json:"closed"`
+	TrackedBy []string       `json:"tracked_by,omitempty"` // Epics listing the root issue in their task list
+	Progress  *IssueProgress `json:"progress,omitempty"`   // Closed children, for issues with a task list
+	Children  []*IssueTree   `json:"children,omitempty"`
+}
+
+// Milestone represents a milestone of a repository
+type Milestone struct {
+	RepositoryFullName string    `db:"repository_full_name"`
+	Number             int       `db:"number"`
+	Title              string    `db:"title"`
+	Description        string    `db:"description"`
+	State              string    `db:"state"`
+	HTMLURL            string    `db:"html_url"`
+	OpenIssues         int       `db:"open_issues"`
+	ClosedIssues       int       `db:"closed_issues"`
+	DueOn              time.Time `db:"due_on"` // Zero when the milestone has no due date
+	UpdatedAt          time.Time `db:"updated_at"`
+}
+
+// Release represents a published release of a repository
+type Release struct {
+	RepositoryFullName string    `db:"repository_full_name"`
+	TagName            string    `db:"tag_name"`
+	Name               string    `db:"name"`
+	Prerelease         bool      `db:"prerelease"`
+	HTMLURL            string    `db:"html_url"`
+	PublishedAt        time.Time `db:"published_at"`
+}
+
+// ReleaseNotes is a changelog draft of the pull requests merged into a repository since a release or date
+type ReleaseNotes struct {
+	Repository   string                 `json:"repository"`
+	Since        string                 `json:"since,omitempty"` // Release tag or date the notes start from
+	SinceTime    time.Time              `json:"since_time"`
+	Sections     []*ReleaseNotesSection `json:"sections"`
+	Contributors []string               `json:"contributors"` // Authors of the pull requests, by login
+	Markdown     string                 `json:"markdown"`
+}
+
+// ReleaseNotesSection lists the merged pull requests of a kind, such as features, oldest first
+type ReleaseNotesSection struct {
+	Title        string                `json:"title"`
+	PullRequests []*ReleaseNotesChange `json:"pull_requests"`
+}
+
+// ReleaseNotesChange is a merged pull request of release notes
+type ReleaseNotesChange struct {
+	Number   int       `json:"number"`
+	Title    string    `json:"title"`
+	Author   string    `json:"author"`
+	HTMLURL  string    `json:"html_url"`
+	Labels   []string  `json:"labels"`
+	MergedAt time.Time `json:"merged_at"`
+}
+
+// Calendar event types
+const (
+	CalendarEventMilestone = "milestone"
+	CalendarEventRelease   = "release"
+)
+
+// CalendarEvent represents a dated event of a repository: a milestone due date or a release
+type CalendarEvent struct {
+	Type               string
+	RepositoryFullName string
+	ID                 string // Unique within the repository and type, such as the milestone number
+	Title              string
+	Description        string
+	HTMLURL            string
+	Date               time.Time
+	UpdatedAt          time.Time
+}
+
+// CalendarFilter represents filter options for calendar events
+type CalendarFilter struct {
+	Repo    string
+	RepoTag string
+	Type    string // CalendarEventMilestone, CalendarEventRelease or empty for both
+	Since   time.Time
+	Until   time.Time
+}
+
+// Security alert kinds and severities, from least to most severe
+const (
+	AlertKindDependabot   = "dependabot"
+	AlertKindCodeScanning = "code_scanning"
+
+	SeverityLow      = "low"
+	SeverityMedium   = "medium"
+	SeverityHigh     = "high"
+	SeverityCritical = "critical"
+)
+
+// severityRanks orders the alert severities
+var severityRanks = map[string]int{SeverityLow: 1, SeverityMedium: 2, SeverityHigh: 3, SeverityCritical: 4}
+
+// SeverityRank returns the rank of an alert severity, higher being more severe, or 0 when unknown
+func SeverityRank(severity string) int {
+	return severityRanks[severity]
+}
+
+// SecurityAlert represents an open Dependabot or code scanning alert of a repository
+type SecurityAlert struct {
+	RepositoryFullName string    `db:"repository_full_name" json:"repository"`
+	Kind               string    `db:"kind" json:"kind"`
+	Number             int       `db:"number" json:"number"`
+	Severity           string    `db:"severity" json:"severity"`
+	Summary            string    `db:"summary" json:"summary"`
+	Package            string    `db:"package" json:"package,omitempty"`
+	Rule               string    `db:"rule" json:"rule,omitempty"`
+	Path               string    `db:"path" json:"path,omitempty"`
+	HTMLURL            string    `db:"html_url" json:"html_url"`
+	CreatedAt          time.Time `db:"created_at" json:"created_at"`
+}
+
+// DuplicateCandidate is an open issue probably duplicating an earlier open issue of its repository
+type DuplicateCandidate struct {
+	RepositoryFullName string    `json:"repository_full_name"`
+	Number             int       `json:"number"`
+	Title              string    `json:"title"`
+	HTMLURL            string    `json:"html_url"`
+	DuplicateOf        int       `json:"duplicate_of"`
+	DuplicateOfTitle   string    `json:"duplicate_of_title"`
+	DuplicateOfHTMLURL string    `json:"duplicate_of_html_url"`
+	Score              float64   `json:"score"` // Similarity from 0 to 1
+	DetectedAt         time.Time `json:"detected_at"`
+}
+
+// SecurityAlertSummary counts the open security alerts of a repository by severity
+type SecurityAlertSummary struct {
+	Repository string    `json:"repository"`
+	Total      int       `json:"total"`
+	Critical   int       `json:"critical"`
+	High       int       `json:"high"`
+	Medium     int       `json:"medium"`
+	Low        int       `json:"low"`
+	SyncedAt   time.Time `json:"synced_at"` // Zero when alerts were never synced
+}
+
+// Add counts an alert
+func (s *SecurityAlertSummary) Add(alert *SecurityAlert) {
+	s.Total++
+	switch alert.Severity {
+	case SeverityCritical:
+		s.Critical++
+	case SeverityHigh:
+		s.High++
+	case SeverityMedium:
+		s.Medium++
+	default:
+		s.Low++
+	}
+}
+
+// SecurityAlertFilter represents filter options for security alerts
+type SecurityAlertFilter struct {
+	Repo     string
+	RepoTag  string
+	Kind     string // AlertKindDependabot, AlertKindCodeScanning or empty for both
+	Severity string // Minimum severity, or empty for all
+}
+
+// CodeOwnersRule represents a line of a CODEOWNERS file: the owners of the paths matching a pattern
+type CodeOwnersRule struct {
+	Pattern string   `db:"pattern" json:"pattern"`
+	Owners  []string `db:"owners" json:"owners"` // User logins, org/team slugs or emails, without @
+}
+
+// Commit represents a commit of the default branch of a repository
+type Commit struct {
+	RepositoryFullName string    `db:"repository_full_name" json:"repository"`
+	SHA                string    `db:"sha" json:"sha"`
+	Author             string    `db:"author" json:"author"`   // GitHub login, or the git author name when the commit isn't linked to an account
+	Subject            string    `db:"subject" json:"subject"` // First line of the message
+	HTMLURL            string    `db:"html_url" json:"html_url"`
+	CommittedAt        time.Time `db:"committed_at" json:"committed_at"`
+}
+
+// CommitFilter represents filter options for commits
+type CommitFilter struct {
+	Author  string
+	Since   time.Time
+	Until   time.Time
+	Page    int
+	PerPage int
+}
+
+// Discussion represents a GitHub discussion of a repository
+type Discussion struct {
+	RepositoryFullName string    `db:"repository_full_name" json:"repository"`
+	Number             int       `db:"number" json:"number"`
+	Title              string    `db:"title" json:"title"`
+	Author             string    `db:"author" json:"author"`
+	Category           string    `db:"category" json:"category"`
+	Answerable         bool      `db:"answerable" json:"answerable"` // Whether the category takes answers, as Q&A does
+	Answered           bool      `db:"answered" json:"answered"`
+	Closed             bool      `db:"closed" json:"closed"`
+	Comments           int       `db:"comments" json:"comments"`
+	Upvotes            int       `db:"upvotes" json:"upvotes"`
+	HTMLURL            string    `db:"html_url" json:"html_url"`
+	CreatedAt          time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt          time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// DiscussionFilter represents filter options for discussions
+type DiscussionFilter struct {
+	State      string // open, closed, or all when empty
+	Author     string
+	Repo       string
+	RepoTag    string
+	Category   string
+	Unanswered bool // Only discussions of answerable categories without an accepted answer
+	Since      time.Time
+	Page       int
+	PerPage    int
+}
+
+// Project represents a GitHub project (Projects v2) linked to tracked repositories
+type Project struct {
+	Owner        string         `db:"owner" json:"owner"` // Login of the organization or user owning the project
+	Number       int            `db:"number" json:"number"`
+	Title        string         `db:"title" json:"title"`
+	URL          string         `db:"url" json:"url"`
+	Closed       bool           `db:"closed" json:"closed"`
+	Columns      []string       `db:"columns" json:"columns"`          // Options of the Status field, in board order
+	Repositories []string       `db:"-" json:"repositories,omitempty"` // Tracked repositories linked to the project
+	Items        map[string]int `db:"-" json:"items,omitempty"`        // Synced items by column, ProjectNoStatus for those without one
+}
+
+// ProjectNoStatus is the column of project items whose Status field is unset
+const ProjectNoStatus = "No Status"
+
+// ProjectItem represents an issue or pull request of a tracked repository on a project
+type ProjectItem struct {
+	RepositoryFullName string `db:"repository_full_name" json:"repository"`
+	ProjectOwner       string `db:"project_owner" json:"project_owner"`
+	ProjectNumber      int    `db:"project_number" json:"project_number"`
+	Type               string `db:"type" json:"type"` // ItemTypePullRequest or ItemTypeIssue
+	Number             int    `db:"number" json:"number"`
+	Title              string `db:"title" json:"title"`
+	HTMLURL            string `db:"html_url" json:"html_url"`
+	Status             string `db:"status" json:"status,omitempty"` // Empty when the Status field is unset
+}
+
+// ProjectFilter represents filter options for projects
+type ProjectFilter struct {
+	Repo          string
+	RepoTag       string
+	IncludeClosed bool
+}
+
+// Merge methods of pull requests
+const (
+	MergeMethodMerge  = "merge"
+	MergeMethodSquash = "squash"
+	MergeMethodRebase = "rebase"
+)
+
+// RepositorySettings represents the merge settings of a repository and the protection of its default branch
+type RepositorySettings struct {
+	DefaultBranch        string    `db:"default_branch" json:"default_branch"`
+	MergeMethods         []string  `db:"merge_methods" json:"merge_methods"` // Allowed merge methods
+	DeleteBranchOnMerge  bool      `db:"delete_branch_on_merge" json:"delete_branch_on_merge"`
+	Protected            bool      `db:"protected" json:"protected"` // Whether the default branch is protected; the rules below need it
+	RequiredApprovals    int       `db:"required_approvals" json:"required_approvals"`
+	RequiredStatusChecks []string  `db:"required_status_checks" json:"required_status_checks"` // Nil when status checks are not required
+	EnforceAdmins        bool      `db:"enforce_admins" json:"enforce_admins"`
+	AllowForcePushes     bool      `db:"allow_force_pushes" json:"allow_force_pushes"`
+	RequireLinearHistory bool      `db:"require_linear_history" json:"require_linear_history"`
+	CapturedAt           time.Time `db:"captured_at" json:"captured_at"`
+}
+
+// ComplianceResult represents how the settings of a repository compare to the compliance policy
+type ComplianceResult struct {
+	Repository string              `json:"repository"`
+	Compliant  bool                `json:"compliant"`
+	Violations []string            `json:"violations"`
+	Settings   *RepositorySettings `json:"settings"` // Nil when the settings were never captured
+}
+
+// ComplianceFilter represents filter options for the compliance report
+type ComplianceFilter struct {
+	Repo           string
+	RepoTag        string
+	ViolationsOnly bool // Leave out compliant repositories
+}
+
+// RepositorySnapshot represents the item counts of a repository at a point in time
+type RepositorySnapshot struct {
+	RepositoryFullName string    `db:"repository_full_name"`
+	OpenPullRequests   int       `db:"open_pull_requests"`
+	OpenIssues         int       `db:"open_issues"`
+	StalePullRequests  int       `db:"stale_pull_requests"`
+	StaleIssues        int       `db:"stale_issues"`
+	CreatedAt          time.Time `db:"created_at"`
+}
+
+// ActivityEvent represents an observed change in the append-only activity log
+type ActivityEvent struct {
+	ID            int64     `db:"id"`
+	Type          string    `db:"type"`
+	Repository    string    `db:"repository"`
+	Number        int       `db:"number"`
+	Title         string    `db:"title"`
+	URL           string    `db:"url"`
+	Author        string    `db:"author"`
+	Label         string    `db:"label"`
+	State         string    `db:"state"`
+	PreviousState string    `db:"previous_state"`
+	Error         string    `db:"error"`
+	CreatedAt     time.Time `db:"created_at"`
+}
+
+// ChangeRecord is a line of the change stream: an activity event with its sequence number, and
+// the item it concerns as stored when the stream is read
+type ChangeRecord struct {
+	Sequence    int64          `json:"sequence"`
+	Type        string         `json:"type"`
+	Repository  string         `json:"repository,omitempty"`
+	Number      int            `json:"number,omitempty"`
+	Time        time.Time      `json:"time"`
+	Event       *ActivityEvent `json:"event"`
+	PullRequest *PullRequest   `json:"pull_request,omitempty"`
+	Issue       *Issue         `json:"issue,omitempty"`
+}
+
+// FleetDiff summarizes what changed in the tracked repositories between two times
+type FleetDiff struct {
+	From                time.Time         `json:"from"`
+	To                  time.Time         `json:"to"`
+	RepositoriesAdded   []string          `json:"repositories_added"`
+	RepositoriesRemoved []string          `json:"repositories_removed"`
+	PullRequestsOpened  int               `json:"pull_requests_opened"`
+	PullRequestsMerged  []*DiffItem       `json:"pull_requests_merged"`
+	IssuesOpened        int               `json:"issues_opened"`
+	IssuesClosed        []*DiffItem       `json:"issues_closed"`
+	LabelsAdded         []*DiffItem       `json:"labels_added"` // From the activity log
+	Repositories        []*RepositoryDiff `json:"repositories"` // From the snapshots
+}
+
+// DiffItem represents a pull request or issue that changed between two times
+type DiffItem struct {
+	Repository string    `json:"repository"`
+	Type       string    `json:"type"` // ItemTypePullRequest or ItemTypeIssue
+	Number     int       `json:"number"`
+	Title      string    `json:"title"`
+	HTMLURL    string    `json:"html_url"`
+	Label      string    `json:"label,omitempty"` // The label added
+	At         time.Time `json:"at"`
+}
+
+// RepositoryDiff compares the open items of a repository at two times, as of the latest
+// snapshots taken before each
+type RepositoryDiff struct {
+	Repository           string `json:"repository"`
+	OpenPullRequestsFrom int    `json:"open_pull_requests_from"`
+	OpenPullRequestsTo   int    `json:"open_pull_requests_to"`
+	OpenIssuesFrom       int    `json:"open_issues_from"`
+	OpenIssuesTo         int    `json:"open_issues_to"`
+}
+
+// DiffFilter represents the window and repositories of a fleet diff
+type DiffFilter struct {
+	From    time.Time
+	To      time.Time
+	Repo    string
+	RepoTag string
+}
+
+// ActivityFilter represents filter options for the activity log
+type ActivityFilter struct {
+	Repo    string
+	Type    string
+	Since   time.Time
+	Until   time.Time
+	Page    int
+	PerPage int
+}
+
+// Audited actions
+const (
+	AuditRepositoryAdd       = "repository.add"
+	AuditRepositoryRemove    = "repository.remove"
+	AuditRepositoryRefresh   = "repository.refresh"
+	AuditRepositoryTag       = "repository.tag"
+	AuditRepositoryUntag     = "repository.untag"
+	AuditRepositoryConfigure = "repository.configure"
+	AuditRepositoryPause     = "repository.pause"
+	AuditRepositoryResume    = "repository.resume"
+	AuditRepositoryClone     = "repository.clone"
+	AuditRepositorySeed      = "repository.seed"
+	AuditRefreshAll          = "refresh.all"
+	AuditRefreshDue          = "refresh.due"
+	AuditWebhookAdd          = "webhook.add"
+	AuditWebhookDelete       = "webhook.delete"
+	AuditSubscriptionAdd     = "subscription.add"
+	AuditSubscriptionDelete  = "subscription.delete"
+	AuditJobCancel           = "job.cancel"
+	AuditAdminClear          = "admin.clear"
+	AuditAdminCompact        = "admin.compact"
+	AuditAdminRepair         = "admin.repair"
+	AuditAdminWarehouse      = "admin.warehouse"
+	AuditSessionLogin        = "session.login"
+	AuditWorkspaceCreate     = "workspace.create"
+	AuditWorkspaceUpdate     = "workspace.update"
+	AuditWorkspaceDelete     = "workspace.delete"
+	AuditWorkspaceAdd        = "workspace.add"
+	AuditWorkspaceRemove     = "workspace.remove"
+	AuditWorkspaceToken      = "workspace.token"
+	AuditWorkspaceRevoke     = "workspace.revoke"
+	AuditLabelUpdate         = "label.update"
+	AuditPullRequestCreate   = "pull_request.create"
+	AuditItemUpdate          = "item.update"
+	AuditItemBulk            = "item.bulk"
+	AuditTriageRuleAdd       = "triage_rule.add"
+	AuditTriageRuleDelete    = "triage_rule.delete"
+)
+
+// AuditEntry records who changed what through a mutating operation
+type AuditEntry struct {
+	ID         int64     `db:"id"`
+	Actor      string    `db:"actor"`
+	Credential string    `db:"credential"` // The end of the API key presented, if any
+	Action     string    `db:"action"`     // One of the Audit constants
+	Target     string    `db:"target"`     // Repository full name, webhook or job ID
+	Detail     string    `db:"detail"`
+	CreatedAt  time.Time `db:"created_at"`
+}
+
+// AuditFilter represents filter options for the audit log
+type AuditFilter struct {
+	Actor   string
+	Action  string
+	Target  string
+	Since   time.Time
+	Until   time.Time
+	Page    int
+	PerPage int
+}
+
+// AnalyticsFilter represents filter options for analytics.
+// Items are selected by their creation time.
+type AnalyticsFilter struct {
+	Repo        string
+	RepoTag     string
+	ExcludeBots bool
+	Association string
+	Since       time.Time
+	Until       time.Time
+}
+
+// LeaderboardFilter represents filter options for the contributor leaderboard
+type LeaderboardFilter struct {
+	Repo        string
+	RepoTag     string
+	ExcludeBots bool
+	Association string
+	Since       time.Time
+	Until       time.Time
+	Cursor      string
+	Page        int
+	PerPage     int
+}
+
+// TopicFilter represents filter options for the topics of open issues
+type TopicFilter struct {
+	Repo        string
+	RepoTag     string
+	ExcludeBots bool
+	Limit       int // Most topics to return, all when zero
+}
+
 // PullRequestFilter represents filter options for pull requests
 type PullRequestFilter struct {
-	State     string
-	Author    string
-	Repo      string
-	Label     string
-	SortBy    string
-	Direction string
-	Since     time.Time
-	GroupBy   string
-	Page      int
-	PerPage   int
+	State             string
+	Author            string
+	Repo              string
+	RepoTag           string
+	Label             string
+	SortBy            string
+	Direction         string
+	Since             time.Time
+	GroupBy           string
+	ExcludeBots       bool
+	Association       string // Comma separated author associations
+	Team              string // Items assigned to, requesting review from or mentioning the team
+	Jira              string // Items referencing the Jira issue key
+	OwnedByMe         bool   // Pull requests touching paths the configured code owners user owns
+	OwnedByTeam       string // Pull requests touching paths the team owns
+	Path              string // Pull requests changing a path matching the pattern, such as docs/**
+	Size              string // Pull requests of a size: XS, S, M, L, XL or XXL
+	Project           string // Items on the project, by owner/number or title
+	ProjectStatus     string // Items in the Status column of a project, such as In Review
+	IncludeTombstoned bool
+	Cursor            string
+	Page              int
+	PerPage           int
 }
 
 // IssueFilter represents filter options for issues
 type IssueFilter struct {
-	State     string
-	Author    string
-	Repo      string
-	Label     string
-	SortBy    string
-	Direction string
-	Since     time.Time
-	GroupBy   string
-	Page      int
-	PerPage   int
+	State             string
+	Author            string
+	Repo              string
+	RepoTag           string
+	Label             string
+	SortBy            string
+	Direction         string
+	Since             time.Time
+	GroupBy           string
+	ExcludeBots       bool
+	Association       string // Comma separated author associations
+	Team              string // Items assigned to, requesting review from or mentioning the team
+	Jira              string // Items referencing the Jira issue key
+	Project           string // Items on the project, by owner/number or title
+	ProjectStatus     string // Items in the Status column of a project, such as In Review
+	IncludeTombstoned bool
+	Cursor            string
+	Page              int
+	PerPage           int
+}
+
+// Item types
+const (
+	ItemTypePullRequest = "pull_request"
+	ItemTypeIssue       = "issue"
+)
+
+// Item represents a pull request or an issue in a unified listing
+type Item struct {
+	Type               string    `json:"type"`
+	RepositoryFullName string    `json:"repository_full_name"`
+	Number             int       `json:"number"`
+	Title              string    `json:"title"`
+	State              string    `json:"state"`
+	UserLogin          string    `json:"user_login"`
+	UserIsBot          bool      `json:"user_is_bot"`
+	AuthorAssociation  string    `json:"author_association"`
+	HTMLURL            string    `json:"html_url"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+// ItemFilter represents filter options for the unified listing of pull requests and issues
+type ItemFilter struct {
+	Type        string // ItemTypePullRequest, ItemTypeIssue or empty for both
+	State       string
+	Author      string
+	Repo        string
+	RepoTag     string
+	Label       string
+	ExcludeBots bool
+	Association string // Comma separated author associations
+	Team        string // Items assigned to, requesting review from or mentioning the team
+	Jira        string // Items referencing the Jira issue key
+	Direction   string
+	Since       time.Time
+	Cached      bool // Only read the stored data, without re-syncing stale repositories
+	Cursor      string
+	Page        int
+	PerPage     int
+}
+
+// ItemQuery represents filters on pull requests or issues that the database answers from its indexes.
+// Empty fields match everything, except that a non-nil empty Repositories matches nothing.
+type ItemQuery struct {
+	Repositories []string
+	State        string
+	Author       string
+	Label        string
+	UpdatedSince time.Time
+}
+
+// StorageStats represents the amount of data held by the database
+type StorageStats struct {
+	Repositories           int   `json:"repositories"`
+	PullRequests           int   `json:"pull_requests"`
+	Issues                 int   `json:"issues"`
+	EstimatedBytes         int64 `json:"estimated_bytes"`
+	EvictedPullRequests    int64 `json:"evicted_pull_requests"`
+	EvictedIssues          int64 `json:"evicted_issues"`
+	TombstonedPullRequests int   `json:"tombstoned_pull_requests"`
+	TombstonedIssues       int   `json:"tombstoned_issues"`
+	FileBytes              int64 `json:"file_bytes"` // Size of the persisted data, 0 when kept in memory only
+	SchemaVersion          int   `json:"schema_version"`
+
+	PerRepository []*RepositoryStorageStats `json:"per_repository,omitempty"`
+}
+
+// RepositoryStorageStats reports what the database holds for one repository
+type RepositoryStorageStats struct {
+	FullName               string `json:"full_name"`
+	PullRequests           int    `json:"pull_requests"`
+	Issues                 int    `json:"issues"`
+	Labels                 int    `json:"labels"` // Distinct labels on its pull requests and issues
+	Snapshots              int    `json:"snapshots"`
+	TombstonedPullRequests int    `json:"tombstoned_pull_requests"`
+	TombstonedIssues       int    `json:"tombstoned_issues"`
+	EstimatedBytes         int64  `json:"estimated_bytes"`
+}
+
+// RepositoryAPIUsage reports the API requests spent on one repository
+type RepositoryAPIUsage struct {
+	FullName string `json:"full_name"`
+	APIUsage
+	Share float64 `json:"share"` // Fraction of the requests spent on all repositories
+}
+
+// CompactionResult reports what compacting the database removed
+type CompactionResult struct {
+	BytesBefore    int64 `json:"bytes_before"`
+	BytesAfter     int64 `json:"bytes_after"`
+	RemovedEntries int   `json:"removed_entries"` // Orphaned labels, label links, snapshots and delivery logs
+
+	// Pull requests and issues no longer present upstream
+	RemovedTombstones int `json:"removed_tombstones"`
+
+	// Closed items dropped by the retention policy
+	ExpiredPullRequests int `json:"expired_pull_requests"`
+	ExpiredIssues       int `json:"expired_issues"`
+}
+
+// WarehousePush reports the rows pushed to the warehouse, per table
+type WarehousePush struct {
+	Kind         string `json:"kind"`
+	Repositories int    `json:"repositories"`
+	PullRequests int    `json:"pull_requests"`
+	Issues       int    `json:"issues"`
+}
+
+// SeedResult reports what seeding a repository from exported data stored
+type SeedResult struct {
+	Repository string `json:"repository"`
+	Tracked    bool   `json:"tracked"` // Whether seeding started tracking the repository
+
+	PullRequests int `json:"pull_requests"`
+	Issues       int `json:"issues"`
+	// Items already stored with the same or a later update, left as they are
+	Skipped int `json:"skipped"`
+}
+
+// Integrity problem kinds
+const (
+	IntegrityUntrackedRepository = "untracked_repository" // Items of a repository that isn't tracked
+	IntegrityKeyMismatch         = "key_mismatch"         // An item stored under another repository or number than its own
+	IntegrityUnlistedItem        = "unlisted_item"        // An item missing from the numbers of its repository
+	IntegrityDanglingNumber      = "dangling_number"      // A number listed for a repository without an item
+	IntegrityOrphanedLabelLink   = "orphaned_label_link"  // Labels attached to an item that doesn't exist
+	IntegrityMissingLabel        = "missing_label"        // A label attached to an item but not defined
+	IntegrityOrphanedDiff        = "orphaned_diff"        // A cached diff of a pull request that doesn't exist
+)
+
+// IntegrityProblem is an inconsistency found in the database
+type IntegrityProblem struct {
+	Kind       string `json:"kind"`
+	Repository string `json:"repository,omitempty"`
+	ItemType   string `json:"item_type,omitempty"` // ItemTypePullRequest or ItemTypeIssue
+	Number     int    `json:"number,omitempty"`
+	Detail     string `json:"detail"`
+	Repaired   bool   `json:"repaired"`
+}
+
+// IntegrityReport lists the inconsistencies found in the database, by kind, repository and number
+type IntegrityReport struct {
+	Problems []*IntegrityProblem `json:"problems"`
+	Repaired int                 `json:"repaired"`
+}
+
+// RetentionPolicy selects the closed pull requests and issues to drop. Zero values keep everything.
+type RetentionPolicy struct {
+	ClosedBefore           time.Time // Drop the items closed before
+	MaxClosedPerRepository int       // Keep the most recently closed pull requests and issues of each repository, each up to this many
+}
+
+// RetentionResult counts the closed items dropped by a retention policy
+type RetentionResult struct {
+	PullRequests int `json:"pull_requests"`
+	Issues       int `json:"issues"`
+}
+
+// RefreshPlan reports what a refresh would fetch, without fetching it
+type RefreshPlan struct {
+	Repositories      []*SyncPlan `json:"repositories"`
+	EstimatedRequests int         `json:"estimated_requests"`
+	EstimatedItems    int         `json:"estimated_items"`
+
+	// Current GitHub rate limit, nil when it could not be fetched
+	RateLimit *RateLimitStatus `json:"rate_limit,omitempty"`
+}
+
+// ExceedsRateLimit reports whether the refresh needs more requests than the rate limit has left
+func (p *RefreshPlan) ExceedsRateLimit() bool {
+	return p.RateLimit != nil && p.EstimatedRequests > p.RateLimit.Remaining
+}
+
+// SyncPlan describes what syncing one repository would fetch
+type SyncPlan struct {
+	FullName          string    `json:"full_name"`
+	Skip              string    `json:"skip,omitempty"` // Why the repository would not be synced, empty when it would
+	PullRequests      bool      `json:"pull_requests"`
+	Issues            bool      `json:"issues"`
+	Reviews           bool      `json:"reviews"`
+	ItemLimit         int       `json:"item_limit"`
+	Priority          string    `json:"priority"`
+	RecentChanges     int       `json:"recent_changes"` // Items updated within the churn window, used to order syncs
+	EstimatedRequests int       `json:"estimated_requests"`
+	EstimatedItems    int       `json:"estimated_items"` // Upper bound, a repository may have fewer items
+	LastSyncedAt      time.Time `json:"last_synced_at"`
+}
+
+// RateLimitStatus is a snapshot of the GitHub API rate limit
+type RateLimitStatus struct {
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	ResetAt   time.Time `json:"reset_at"`
 }
 
 // Pagination represents pagination information
 type Pagination struct {
-	Page       int `json:"page"`
-	PerPage    int `json:"per_page"`
-	Total      int `json:"total"`
-	TotalPages int `json:"total_pages"`
+	Page       int    `json:"page"`
+	PerPage    int    `json:"per_page"`
+	Total      int    `json:"total"`
+	TotalPages int    `json:"total_pages"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
 }
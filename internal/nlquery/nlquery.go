@@ -0,0 +1,81 @@
+// Package nlquery translates natural-language questions about pull requests and issues,
+// such as "open PRs by alice labeled bug in tidb", into item filters with a pluggable
+// language model backend.
+package nlquery
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+)
+
+// Query is the filter a question was translated into. Fields are left empty when the
+// question doesn't constrain them.
+type Query struct {
+	Type    string `json:"type"`     // pull_request or issue
+	State   string `json:"state"`    // open, closed, merged or all
+	Author  string `json:"author"`   // GitHub login
+	Repo    string `json:"repo"`     // owner/name or just the repository name
+	RepoTag string `json:"repo_tag"` // Repository tag
+	Label   string `json:"label"`
+	Team    string `json:"team"`
+	Jira    string `json:"jira"`  // Jira issue key
+	Since   string `json:"since"` // YYYY-MM-DD, for "this week" and the like
+}
+
+// Translator translates questions into queries
+type Translator interface {
+	Translate(ctx context.Context, question string) (*Query, error)
+}
+
+// Provider creates the translator of a backend from its configuration
+type Provider func(cfg *config.QueryConfig) (Translator, error)
+
+var (
+	providersMu sync.RWMutex
+	providers   = make(map[string]Provider)
+)
+
+// Register makes a translation backend available under a name.
+// Backends call it from an init function; registering a name twice panics.
+func Register(name string, provider Provider) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+
+	if provider == nil {
+		panic("nlquery: Register provider is nil")
+	}
+	if _, dup := providers[name]; dup {
+		panic("nlquery: Register called twice for provider " + name)
+	}
+	providers[name] = provider
+}
+
+// Providers returns the names of the registered backends, sorted
+func Providers() []string {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Open creates the translator of the configured backend
+func Open(cfg *config.QueryConfig) (Translator, error) {
+	providersMu.RLock()
+	provider, ok := providers[cfg.Backend]
+	providersMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unsupported query backend %q (available: %s)", cfg.Backend, strings.Join(Providers(), ", "))
+	}
+	return provider(cfg)
+}
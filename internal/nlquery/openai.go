@@ -0,0 +1,136 @@
+package nlquery
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+)
+
+// Defaults of the openai backend
+const (
+	DefaultOpenAIEndpoint = "https://api.openai.com/v1"
+	DefaultOpenAIModel    = "gpt-4o-mini"
+	defaultTimeout        = 30 * time.Second
+)
+
+func init() {
+	Register("openai", NewOpenAI)
+}
+
+// systemPrompt instructs the model to answer with a Query; %s is today's date
+const systemPrompt = `You translate questions about GitHub pull requests and issues into a JSON search filter.
+Answer with a single JSON object with these string fields, leaving out fields the question doesn't mention:
+- type: "pull_request" for pull requests/PRs, "issue" for issues
+- state: "open", "closed", "merged" or "all"
+- author: GitHub login of the author
+- repo: repository as "owner/name", or just "name" when no owner is given
+- repo_tag: repository tag, for questions such as "in repos tagged backend"
+- label: a single label name
+- team: team name, for items involving a team
+- jira: Jira issue key such as PROJ-123
+- since: YYYY-MM-DD date items were updated since, for "this week", "since Monday" and the like
+Today is %s.`
+
+// openAI translates questions with an OpenAI-compatible chat completions API, which
+// hosted and local model servers alike provide
+type openAI struct {
+	endpoint   string
+	model      string
+	apiKey     string
+	httpClient *http.Client
+	now        func() time.Time
+}
+
+// NewOpenAI creates a translator using the chat completions API at cfg.Endpoint
+func NewOpenAI(cfg *config.QueryConfig) (Translator, error) {
+	t := &openAI{
+		endpoint:   strings.TrimSuffix(cfg.Endpoint, "/"),
+		model:      cfg.Model,
+		apiKey:     cfg.APIKey,
+		httpClient: &http.Client{Timeout: cfg.Timeout},
+		now:        time.Now,
+	}
+	if t.endpoint == "" {
+		t.endpoint = DefaultOpenAIEndpoint
+	}
+	if t.model == "" {
+		t.model = DefaultOpenAIModel
+	}
+	if t.httpClient.Timeout <= 0 {
+		t.httpClient.Timeout = defaultTimeout
+	}
+	return t, nil
+}
+
+// chatMessage is a message of a chat completion
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// Translate asks the model for the filter matching question
+func (t *openAI) Translate(ctx context.Context, question string) (*Query, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"model": t.model,
+		"messages": []chatMessage{
+			{Role: "system", Content: fmt.Sprintf(systemPrompt, t.now().Format("2006-01-02 (Monday)"))},
+			{Role: "user", Content: question},
+		},
+		"response_format": map[string]string{"type": "json_object"},
+		"temperature":     0,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.endpoint+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if t.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+t.apiKey)
+	}
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("chat completion failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+
+	var completion struct {
+		Choices []struct {
+			Message chatMessage `json:"message"`
+		} `json:"choices"`
+	}
+	if err := json.Unmarshal(data, &completion); err != nil {
+		return nil, fmt.Errorf("failed to parse chat completion: %w", err)
+	}
+	if len(completion.Choices) == 0 {
+		return nil, fmt.Errorf("chat completion has no choices")
+	}
+
+	var query Query
+	content := strings.TrimSpace(completion.Choices[0].Message.Content)
+	// Some models wrap JSON in a Markdown code block despite the response format
+	content = strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(content, "```json"), "```"), "```")
+	if err := json.Unmarshal([]byte(content), &query); err != nil {
+		return nil, fmt.Errorf("model did not answer with a filter: %w", err)
+	}
+	return &query, nil
+}
@@ -0,0 +1,54 @@
+package nlquery
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+)
+
+func TestOpenAITranslate(t *testing.T) {
+	var request struct {
+		Model    string        `json:"model"`
+		Messages []chatMessage `json:"messages"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" || r.Header.Get("Authorization") != "Bearer key" {
+			http.Error(w, "unexpected request", http.StatusUnauthorized)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"{\"type\":\"pull_request\",\"author\":\"alice\",\"label\":\"bug\",\"repo\":\"tidb\"}"}}]}`))
+	}))
+	defer server.Close()
+
+	translator, err := Open(&config.QueryConfig{Backend: "openai", Endpoint: server.URL + "/v1/", Model: "local", APIKey: "key"})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	query, err := translator.Translate(context.Background(), "PRs by alice labeled bug in tidb")
+	if err != nil {
+		t.Fatalf("Translate() error = %v", err)
+	}
+	want := Query{Type: "pull_request", Author: "alice", Label: "bug", Repo: "tidb"}
+	if *query != want {
+		t.Errorf("Translate() = %+v, want %+v", *query, want)
+	}
+	if request.Model != "local" || len(request.Messages) != 2 || request.Messages[1].Content != "PRs by alice labeled bug in tidb" {
+		t.Errorf("request = %+v, want the question for model local", request)
+	}
+	if !strings.Contains(request.Messages[0].Content, "Today is ") {
+		t.Errorf("system prompt %q does not give the date", request.Messages[0].Content)
+	}
+
+	if _, err := Open(&config.QueryConfig{Backend: "unknown"}); err == nil {
+		t.Error("Open() of an unknown backend succeeded")
+	}
+}
@@ -0,0 +1,191 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+)
+
+// EventType identifies the kind of event a notification is fired for
+type EventType string
+
+// Event types
+const (
+	EventRepositoryAdded         EventType = "repository.added"
+	EventRepositoryRemoved       EventType = "repository.removed"
+	EventPullRequestOpened       EventType = "pull_request.opened"
+	EventPullRequestUpdated      EventType = "pull_request.updated"
+	EventPullRequestStateChanged EventType = "pull_request.state_changed"
+	EventPullRequestApproved     EventType = "pull_request.approved"
+	EventPullRequestLabeled      EventType = "pull_request.labeled"
+	EventIssueOpened             EventType = "issue.opened"
+	EventIssueUpdated            EventType = "issue.updated"
+	EventIssueStateChanged       EventType = "issue.state_changed"
+	EventIssueLabeled            EventType = "issue.labeled"
+	EventSyncCompleted           EventType = "sync.completed"
+	EventSyncFailed              EventType = "sync.failed"
+	EventTriageMatched           EventType = "triage.matched"
+)
+
+// Event represents something observed by the service that may be worth notifying about
+type Event struct {
+	Type       EventType
+	Repository string
+	Number     int
+	Title      string
+	URL        string
+	Author     string
+	Label      string
+	// Rule is the name of the triage rule of triage events
+	Rule string
+	// State and PreviousState are set on state change events
+	State         string
+	PreviousState string
+	Error         string
+	Time          time.Time
+}
+
+// Message returns a human readable description of the event
+func (e *Event) Message() string {
+	switch e.Type {
+	case EventRepositoryAdded:
+		return fmt.Sprintf("Repository %s is now tracked", e.Repository)
+	case EventRepositoryRemoved:
+		return fmt.Sprintf("Repository %s is no longer tracked", e.Repository)
+	case EventPullRequestOpened:
+		return fmt.Sprintf("New pull request %s#%d by %s: %s", e.Repository, e.Number, e.Author, e.Title)
+	case EventPullRequestUpdated:
+		return fmt.Sprintf("Pull request %s#%d updated: %s", e.Repository, e.Number, e.Title)
+	case EventPullRequestStateChanged:
+		return fmt.Sprintf("Pull request %s#%d changed from %s to %s: %s", e.Repository, e.Number, e.PreviousState, e.State, e.Title)
+	case EventPullRequestLabeled:
+		return fmt.Sprintf("Pull request %s#%d labeled %q: %s", e.Repository, e.Number, e.Label, e.Title)
+	case EventPullRequestApproved:
+		return fmt.Sprintf("Pull request %s#%d approved: %s", e.Repository, e.Number, e.Title)
+	case EventIssueOpened:
+		return fmt.Sprintf("New issue %s#%d by %s: %s", e.Repository, e.Number, e.Author, e.Title)
+	case EventIssueUpdated:
+		return fmt.Sprintf("Issue %s#%d updated: %s", e.Repository, e.Number, e.Title)
+	case EventIssueStateChanged:
+		return fmt.Sprintf("Issue %s#%d changed from %s to %s: %s", e.Repository, e.Number, e.PreviousState, e.State, e.Title)
+	case EventIssueLabeled:
+		return fmt.Sprintf("Issue %s#%d labeled %q: %s", e.Repository, e.Number, e.Label, e.Title)
+	case EventSyncCompleted:
+		return fmt.Sprintf("Sync of %s completed", e.Repository)
+	case EventSyncFailed:
+		return fmt.Sprintf("Sync of %s failed: %s", e.Repository, e.Error)
+	case EventTriageMatched:
+		return fmt.Sprintf("Triage rule %q matched %s#%d: %s", e.Rule, e.Repository, e.Number, e.Title)
+	default:
+		return fmt.Sprintf("%s event in %s", e.Type, e.Repository)
+	}
+}
+
+// Notifier delivers events to an external destination
+type Notifier interface {
+	Notify(ctx context.Context, event *Event) error
+}
+
+// Rule decides which events are routed to a notifier.
+// Empty lists match everything.
+type Rule struct {
+	Events       []EventType
+	Repositories []string
+	Labels       []string
+}
+
+// Match reports whether the event satisfies the rule
+func (r *Rule) Match(event *Event) bool {
+	if len(r.Events) > 0 {
+		matched := false
+		for _, t := range r.Events {
+			if t == event.Type {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	if len(r.Repositories) > 0 && !containsFold(r.Repositories, event.Repository) {
+		return false
+	}
+
+	// Label restrictions only apply to events that carry a label
+	if len(r.Labels) > 0 && event.Label != "" && !containsFold(r.Labels, event.Label) {
+		return false
+	}
+
+	return true
+}
+
+// containsFold reports whether list contains s, ignoring case
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// route binds a rule to the notifier that receives matching events
+type route struct {
+	rule     Rule
+	notifier Notifier
+}
+
+// Dispatcher fans events out to the notifiers whose rules match
+type Dispatcher struct {
+	routes []route
+}
+
+// NewDispatcher creates a dispatcher with the notifiers defined in the configuration
+func NewDispatcher(cfg *config.NotificationsConfig) *Dispatcher {
+	d := &Dispatcher{}
+	for _, slack := range cfg.Slack {
+		d.Add(ruleFromConfig(slack.NotificationRoute), NewSlackNotifier(slack.WebhookURL, slack.Channel))
+	}
+	return d
+}
+
+// ruleFromConfig converts a configured route into a rule
+func ruleFromConfig(cfg config.NotificationRoute) Rule {
+	events := make([]EventType, 0, len(cfg.Events))
+	for _, e := range cfg.Events {
+		events = append(events, EventType(e))
+	}
+	return Rule{
+		Events:       events,
+		Repositories: cfg.Repositories,
+		Labels:       cfg.Labels,
+	}
+}
+
+// Add registers a notifier for events matching the rule
+func (d *Dispatcher) Add(rule Rule, notifier Notifier) {
+	d.routes = append(d.routes, route{rule: rule, notifier: notifier})
+}
+
+// Dispatch delivers the event to every matching notifier.
+// Delivery failures are logged and never returned to the caller.
+func (d *Dispatcher) Dispatch(ctx context.Context, event *Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	for _, r := range d.routes {
+		if !r.rule.Match(event) {
+			continue
+		}
+		if err := r.notifier.Notify(ctx, event); err != nil {
+			log.Printf("Error delivering %s notification for %s: %v", event.Type, event.Repository, err)
+		}
+	}
+}
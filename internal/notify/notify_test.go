@@ -0,0 +1,130 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestRuleMatch tests the Rule.Match function
+func TestRuleMatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  Rule
+		event Event
+		want  bool
+	}{
+		{
+			name:  "Empty rule",
+			rule:  Rule{},
+			event: Event{Type: EventSyncFailed, Repository: "pingcap/tidb"},
+			want:  true,
+		},
+		{
+			name:  "Event type mismatch",
+			rule:  Rule{Events: []EventType{EventPullRequestOpened}},
+			event: Event{Type: EventSyncFailed, Repository: "pingcap/tidb"},
+			want:  false,
+		},
+		{
+			name:  "Repository match ignores case",
+			rule:  Rule{Repositories: []string{"PingCAP/TiDB"}},
+			event: Event{Type: EventPullRequestOpened, Repository: "pingcap/tidb"},
+			want:  true,
+		},
+		{
+			name:  "Repository mismatch",
+			rule:  Rule{Repositories: []string{"pingcap/tikv"}},
+			event: Event{Type: EventPullRequestOpened, Repository: "pingcap/tidb"},
+			want:  false,
+		},
+		{
+			name:  "Label mismatch",
+			rule:  Rule{Labels: []string{"security"}},
+			event: Event{Type: EventIssueLabeled, Repository: "pingcap/tidb", Label: "bug"},
+			want:  false,
+		},
+		{
+			name:  "Label rule ignored for unlabeled events",
+			rule:  Rule{Labels: []string{"security"}},
+			event: Event{Type: EventSyncFailed, Repository: "pingcap/tidb"},
+			want:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rule.Match(&tt.event); got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestSlackNotifier tests that the Slack notifier posts the event message
+func TestSlackNotifier(t *testing.T) {
+	var got slackMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	d := &Dispatcher{}
+	d.Add(Rule{Events: []EventType{EventSyncFailed}}, NewSlackNotifier(server.URL, "#alerts"))
+	d.Dispatch(context.Background(), &Event{Type: EventSyncFailed, Repository: "pingcap/tidb", Error: "boom"})
+
+	if got.Channel != "#alerts" {
+		t.Errorf("channel = %v, want %v", got.Channel, "#alerts")
+	}
+	if want := "Sync of pingcap/tidb failed: boom"; got.Text != want {
+		t.Errorf("text = %v, want %v", got.Text, want)
+	}
+}
+
+// TestDeliverWebhook tests signing and retrying of webhook deliveries
+func TestDeliverWebhook(t *testing.T) {
+	retryBackoff = time.Millisecond
+
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get(HeaderSignature) != Sign("secret", body) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if got := r.Header.Get(HeaderEvent); got != string(EventSyncCompleted) {
+			t.Errorf("event header = %v, want %v", got, EventSyncCompleted)
+		}
+		if calls < 2 {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+
+	event := &Event{Type: EventSyncCompleted, Repository: "pingcap/tidb", Time: time.Now()}
+	result := DeliverWebhook(context.Background(), server.Client(), server.URL, "secret", event, 3)
+	if result.Err != nil {
+		t.Fatalf("DeliverWebhook() error = %v", result.Err)
+	}
+	if result.Attempts != 2 {
+		t.Errorf("attempts = %v, want %v", result.Attempts, 2)
+	}
+	if result.StatusCode != http.StatusOK {
+		t.Errorf("status = %v, want %v", result.StatusCode, http.StatusOK)
+	}
+
+	result = DeliverWebhook(context.Background(), server.Client(), server.URL, "other", event, 2)
+	if result.Err == nil {
+		t.Error("DeliverWebhook() with a bad signature should return an error")
+	}
+	if result.StatusCode != http.StatusUnauthorized {
+		t.Errorf("status = %v, want %v", result.StatusCode, http.StatusUnauthorized)
+	}
+}
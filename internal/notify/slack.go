@@ -0,0 +1,67 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// SlackNotifier posts events to a Slack incoming webhook
+type SlackNotifier struct {
+	webhookURL string
+	channel    string
+	httpClient *http.Client
+}
+
+// Ensure SlackNotifier implements Notifier
+var _ Notifier = (*SlackNotifier)(nil)
+
+// NewSlackNotifier creates a new Slack notifier.
+// An empty channel posts to the webhook's default channel.
+func NewSlackNotifier(webhookURL, channel string) *SlackNotifier {
+	return &SlackNotifier{
+		webhookURL: webhookURL,
+		channel:    channel,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// slackMessage is the payload accepted by Slack incoming webhooks
+type slackMessage struct {
+	Channel string `json:"channel,omitempty"`
+	Text    string `json:"text"`
+}
+
+// Notify posts the event to Slack
+func (n *SlackNotifier) Notify(ctx context.Context, event *Event) error {
+	text := event.Message()
+	if event.URL != "" {
+		text = fmt.Sprintf("%s\n<%s>", text, event.URL)
+	}
+
+	payload, err := json.Marshal(&slackMessage{Channel: n.channel, Text: text})
+	if err != nil {
+		return fmt.Errorf("failed to encode slack message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.webhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create slack request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post slack message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("slack webhook returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
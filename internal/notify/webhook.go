@@ -0,0 +1,131 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Webhook request headers
+const (
+	HeaderEvent     = "X-Ghrepos-Event"
+	HeaderSignature = "X-Ghrepos-Signature-256"
+)
+
+// retryBackoff is the delay before the first retry; it doubles on every further attempt
+var retryBackoff = time.Second
+
+// WebhookPayload is the JSON body posted to webhooks
+type WebhookPayload struct {
+	Event         EventType `json:"event"`
+	Repository    string    `json:"repository"`
+	Number        int       `json:"number,omitempty"`
+	Title         string    `json:"title,omitempty"`
+	URL           string    `json:"url,omitempty"`
+	Author        string    `json:"author,omitempty"`
+	Label         string    `json:"label,omitempty"`
+	Rule          string    `json:"rule,omitempty"`
+	State         string    `json:"state,omitempty"`
+	PreviousState string    `json:"previous_state,omitempty"`
+	Error         string    `json:"error,omitempty"`
+	Timestamp     string    `json:"timestamp"`
+}
+
+// NewWebhookPayload builds the webhook payload for an event
+func NewWebhookPayload(event *Event) *WebhookPayload {
+	return &WebhookPayload{
+		Event:         event.Type,
+		Repository:    event.Repository,
+		Number:        event.Number,
+		Title:         event.Title,
+		URL:           event.URL,
+		Author:        event.Author,
+		Label:         event.Label,
+		Rule:          event.Rule,
+		State:         event.State,
+		PreviousState: event.PreviousState,
+		Error:         event.Error,
+		Timestamp:     event.Time.Format(time.RFC3339),
+	}
+}
+
+// Sign returns the HMAC-SHA256 signature of body in the "sha256=<hex>" form
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// DeliveryResult describes the outcome of a webhook delivery
+type DeliveryResult struct {
+	StatusCode int
+	Attempts   int
+	Duration   time.Duration
+	Err        error
+}
+
+// DeliverWebhook posts the event to url, signing the body when secret is set.
+// Failed attempts are retried with exponential backoff up to maxAttempts times.
+func DeliverWebhook(ctx context.Context, client *http.Client, url, secret string, event *Event, maxAttempts int) DeliveryResult {
+	start := time.Now()
+
+	body, err := json.Marshal(NewWebhookPayload(event))
+	if err != nil {
+		return DeliveryResult{Err: fmt.Errorf("failed to encode webhook payload: %w", err)}
+	}
+
+	result := DeliveryResult{}
+	backoff := retryBackoff
+	for result.Attempts < maxAttempts {
+		if result.Attempts > 0 {
+			select {
+			case <-ctx.Done():
+				result.Err = ctx.Err()
+				result.Duration = time.Since(start)
+				return result
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+		result.Attempts++
+
+		result.StatusCode, result.Err = postWebhook(ctx, client, url, secret, event.Type, body)
+		if result.Err == nil {
+			break
+		}
+	}
+
+	result.Duration = time.Since(start)
+	return result
+}
+
+// postWebhook performs a single webhook request
+func postWebhook(ctx context.Context, client *http.Client, url, secret string, eventType EventType, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, string(eventType))
+	if secret != "" {
+		req.Header.Set(HeaderSignature, Sign(secret, body))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to post webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
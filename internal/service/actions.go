@@ -0,0 +1,276 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/github"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// Number of items a bulk action updates at once
+const (
+	defaultBulkConcurrency = 4
+	maxBulkConcurrency     = 16
+)
+
+// bulkTarget is an item a bulk action applies to
+type bulkTarget struct {
+	repo   *models.Repository
+	state  string
+	labels []string
+	result *models.BulkResult
+}
+
+// ApplyBulkAction closes, labels or comments on every pull request and issue matching the filter of
+// a request, open ones unless the filter asks for another state, and reports the outcome of each.
+// Items already closed or labeled are skipped, and a failed item doesn't stop the others. With
+// DryRun, nothing is changed and the remaining items are reported as planned. The filter must name a
+// repository or a repository tag, or set AllRepos.
+func (s *Service) ApplyBulkAction(ctx context.Context, req *models.BulkRequest) (*models.BulkReport, error) {
+	switch req.Action {
+	case models.BulkActionClose:
+	case models.BulkActionLabel:
+		if strings.TrimSpace(req.Label) == "" {
+			return nil, ErrInvalidBulkAction
+		}
+	case models.BulkActionComment:
+		if strings.TrimSpace(req.Comment) == "" {
+			return nil, ErrInvalidBulkAction
+		}
+	default:
+		return nil, ErrInvalidBulkAction
+	}
+	// Acting on every repository has to be asked for, not implied by a filter left empty
+	if req.Filter.Repo == "" && req.Filter.RepoTag == "" && !req.Filter.AllRepos {
+		return nil, ErrInvalidBulkScope
+	}
+	targets, err := s.findBulkTargets(ctx, &req.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []*bulkTarget
+	for _, target := range targets {
+		switch {
+		case req.Action == models.BulkActionClose && !strings.EqualFold(target.state, "open"):
+			target.result.Status, target.result.Reason = models.BulkItemSkipped, "already "+strings.ToLower(target.state)
+		case req.Action == models.BulkActionLabel && containsLabel(target.labels, req.Label):
+			target.result.Status, target.result.Reason = models.BulkItemSkipped, "already labeled"
+		case req.DryRun:
+			target.result.Status = models.BulkItemPlanned
+		default:
+			pending = append(pending, target)
+		}
+	}
+
+	concurrency := req.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultBulkConcurrency
+	}
+	if concurrency > maxBulkConcurrency {
+		concurrency = maxBulkConcurrency
+	}
+	slots := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for _, target := range pending {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(target *bulkTarget) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			if err := s.applyBulkAction(ctx, req, target); err != nil {
+				target.result.Status, target.result.Reason = models.BulkItemFailed, err.Error()
+				return
+			}
+			target.result.Status = models.BulkItemDone
+		}(target)
+	}
+	wg.Wait()
+
+	report := &models.BulkReport{Action: req.Action, DryRun: req.DryRun, Matched: len(targets), Results: make([]*models.BulkResult, 0, len(targets))}
+	for _, target := range targets {
+		report.Results = append(report.Results, target.result)
+		switch target.result.Status {
+		case models.BulkItemDone:
+			report.Done++
+		case models.BulkItemSkipped:
+			report.Skipped++
+		case models.BulkItemFailed:
+			report.Failed++
+		}
+	}
+	if report.Done > 0 {
+		s.audit(ctx, models.AuditItemBulk, req.Action, fmt.Sprintf("%d of %d items, %d failed", report.Done, report.Matched, report.Failed))
+	}
+	return report, nil
+}
+
+// findBulkTargets finds the live pull requests and issues matching a bulk filter, ordered by
+// repository and number
+func (s *Service) findBulkTargets(ctx context.Context, filter *models.BulkFilter) ([]*bulkTarget, error) {
+	if filter.Type != "" && filter.Type != models.ItemTypePullRequest && filter.Type != models.ItemTypeIssue {
+		return nil, ErrInvalidItemType
+	}
+	state := filter.State
+	if state == "" {
+		state = "open"
+	}
+	repos, err := s.selectRepositories(ctx, filter.Repo, filter.RepoTag)
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]*models.Repository, len(repos))
+	for _, repo := range repos {
+		byName[repo.FullName] = repo
+	}
+	query := &models.ItemQuery{
+		Repositories: queryRepositories(ctx, repos, filter.Repo, filter.RepoTag),
+		State:        stateFilter(state),
+		Author:       filter.Author,
+		Label:        filter.Label,
+		UpdatedSince: filter.Since,
+	}
+
+	var targets []*bulkTarget
+	target := func(itemType, fullName string, number int, title, state string, labels []*models.Label) {
+		repo, ok := byName[fullName]
+		if !ok {
+			return
+		}
+		t := &bulkTarget{repo: repo, state: state, result: &models.BulkResult{Type: itemType, RepositoryFullName: fullName, Number: number, Title: title}}
+		for _, label := range labels {
+			t.labels = append(t.labels, label.Name)
+		}
+		targets = append(targets, t)
+	}
+	if filter.Type != models.ItemTypeIssue {
+		prs, err := s.db.FindPullRequests(ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find pull requests: %w", err)
+		}
+		for _, pr := range livePullRequests(prs) {
+			labels, _ := s.db.ListPullRequestLabels(ctx, pr.RepositoryFullName, pr.Number)
+			target(models.ItemTypePullRequest, pr.RepositoryFullName, pr.Number, pr.Title, pr.State, labels)
+		}
+	}
+	if filter.Type != models.ItemTypePullRequest {
+		issues, err := s.db.FindIssues(ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find issues: %w", err)
+		}
+		for _, issue := range liveIssues(issues) {
+			labels, _ := s.db.ListIssueLabels(ctx, issue.RepositoryFullName, issue.Number)
+			target(models.ItemTypeIssue, issue.RepositoryFullName, issue.Number, issue.Title, issue.State, labels)
+		}
+	}
+
+	sort.Slice(targets, func(i, j int) bool {
+		a, b := targets[i].result, targets[j].result
+		if a.RepositoryFullName != b.RepositoryFullName {
+			return a.RepositoryFullName < b.RepositoryFullName
+		}
+		return a.Number < b.Number
+	})
+	return targets, nil
+}
+
+// applyBulkAction applies the action of a bulk request to an item on GitHub, then to the stored item
+// so listings reflect it before the next sync
+func (s *Service) applyBulkAction(ctx context.Context, req *models.BulkRequest, target *bulkTarget) error {
+	owner, name, number := target.repo.Owner, target.repo.Name, target.result.Number
+	pullRequest := target.result.Type == models.ItemTypePullRequest
+
+	switch req.Action {
+	case models.BulkActionClose:
+		ghIssue, err := s.gh(ctx).UpdateIssue(owner, name, number, &github.IssueUpdate{State: "closed"})
+		if err != nil {
+			return err
+		}
+		closedAt := time.Now()
+		if ghIssue.ClosedAt != nil {
+			closedAt = *ghIssue.ClosedAt
+		}
+		if pullRequest {
+			err = s.closeStoredPullRequest(ctx, target.repo.FullName, number, closedAt)
+		} else {
+			err = s.closeStoredIssue(ctx, target.repo.FullName, number, closedAt)
+		}
+		if err != nil {
+			s.logger.Printf("Error storing closed %s#%d: %v", target.repo.FullName, number, err)
+		}
+	case models.BulkActionLabel:
+		label := strings.TrimSpace(req.Label)
+		if err := s.gh(ctx).AddLabels(owner, name, number, []string{label}); err != nil {
+			return err
+		}
+		s.storeItemLabel(ctx, target.result.Type, target.repo.FullName, number, label)
+	case models.BulkActionComment:
+		return s.gh(ctx).CreateComment(owner, name, number, req.Comment)
+	}
+	return nil
+}
+
+// storeItemLabel records a label added on GitHub to a stored pull request or issue
+func (s *Service) storeItemLabel(ctx context.Context, itemType, fullName string, number int, label string) {
+	// Stored labels of items are only listed once the label itself is known
+	if existing, err := s.db.GetLabel(ctx, label); err != nil || existing == nil {
+		if err := s.db.AddLabel(ctx, &models.Label{Name: label}); err != nil {
+			s.logger.Printf("Error storing label %s: %v", label, err)
+		}
+	}
+	var err error
+	if itemType == models.ItemTypePullRequest {
+		err = s.db.AddPullRequestLabel(ctx, fullName, number, label)
+	} else {
+		err = s.db.AddIssueLabel(ctx, fullName, number, label)
+	}
+	if err != nil {
+		s.logger.Printf("Error storing label of %s#%d: %v", fullName, number, err)
+	}
+}
+
+// closeStoredPullRequest marks a stored pull request closed, recording the transition
+func (s *Service) closeStoredPullRequest(ctx context.Context, fullName string, number int, closedAt time.Time) error {
+	stored, err := s.db.GetPullRequest(ctx, fullName, number)
+	if err != nil {
+		return err
+	}
+	pr := *stored
+	pr.State, pr.ClosedAt = "CLOSED", &closedAt
+	if closedAt.After(pr.UpdatedAt) {
+		pr.UpdatedAt = closedAt
+	}
+	pr.StateHistory = pullRequestHistory(stored, &pr)
+	return s.db.UpdatePullRequest(ctx, &pr)
+}
+
+// closeStoredIssue marks a stored issue closed, recording the transition
+func (s *Service) closeStoredIssue(ctx context.Context, fullName string, number int, closedAt time.Time) error {
+	stored, err := s.db.GetIssue(ctx, fullName, number)
+	if err != nil {
+		return err
+	}
+	issue := *stored
+	issue.State, issue.ClosedAt = "CLOSED", &closedAt
+	if closedAt.After(issue.UpdatedAt) {
+		issue.UpdatedAt = closedAt
+	}
+	issue.StateHistory = issueHistory(stored, &issue)
+	return s.db.UpdateIssue(ctx, &issue)
+}
+
+// containsLabel reports whether labels contains label, ignoring case
+func containsLabel(labels []string, label string) bool {
+	label = strings.TrimSpace(label)
+	for _, l := range labels {
+		if strings.EqualFold(l, label) {
+			return true
+		}
+	}
+	return false
+}
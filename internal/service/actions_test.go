@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/db/file"
+	"github.com/siddontang/github-repos-management/internal/github"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// bulkGitHub records the items it changes and fails on #13
+type bulkGitHub struct {
+	github.ClientInterface
+	mu      sync.Mutex
+	changed []int
+}
+
+func (g *bulkGitHub) change(number int) error {
+	if number == 13 {
+		return errors.New("403 resource not accessible")
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.changed = append(g.changed, number)
+	return nil
+}
+
+func (g *bulkGitHub) UpdateIssue(owner, name string, number int, update *github.IssueUpdate) (*github.Issue, error) {
+	if err := g.change(number); err != nil {
+		return nil, err
+	}
+	closedAt := time.Now()
+	return &github.Issue{Number: number, State: strings.ToUpper(update.State), ClosedAt: &closedAt}, nil
+}
+
+func (g *bulkGitHub) AddLabels(owner, name string, number int, labels []string) error {
+	return g.change(number)
+}
+
+func (g *bulkGitHub) CreateComment(owner, name string, number int, body string) error {
+	return g.change(number)
+}
+
+func TestApplyBulkAction(t *testing.T) {
+	ctx := context.Background()
+	db, err := file.NewDB("")
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	if err := db.AddRepository(ctx, &models.Repository{Owner: "org", Name: "api", FullName: "org/api"}); err != nil {
+		t.Fatalf("AddRepository() error = %v", err)
+	}
+	for _, issue := range []*models.Issue{
+		{RepositoryFullName: "org/api", Number: 11, State: "OPEN"},
+		{RepositoryFullName: "org/api", Number: 12, State: "CLOSED"},
+		{RepositoryFullName: "org/api", Number: 13, State: "OPEN"},
+		{RepositoryFullName: "org/api", Number: 14, State: "OPEN"},
+	} {
+		if err := db.AddIssue(ctx, issue); err != nil {
+			t.Fatalf("AddIssue() error = %v", err)
+		}
+		if issue.Number != 14 {
+			db.AddIssueLabel(ctx, "org/api", issue.Number, "stale")
+		}
+	}
+	if err := db.AddLabel(ctx, &models.Label{Name: "stale"}); err != nil {
+		t.Fatalf("AddLabel() error = %v", err)
+	}
+	gh := &bulkGitHub{}
+	s := &Service{db: db, ghClient: gh, config: &config.Config{}, logger: log.New(io.Discard, "", 0)}
+
+	if _, err := s.ApplyBulkAction(ctx, &models.BulkRequest{Action: models.BulkActionClose}); !errors.Is(err, ErrInvalidBulkScope) {
+		t.Errorf("ApplyBulkAction(empty filter) error = %v, want ErrInvalidBulkScope", err)
+	}
+
+	filter := models.BulkFilter{Type: models.ItemTypeIssue, Label: "stale", State: "all", AllRepos: true}
+	report, err := s.ApplyBulkAction(ctx, &models.BulkRequest{Action: models.BulkActionClose, Filter: filter, DryRun: true})
+	if err != nil {
+		t.Fatalf("ApplyBulkAction(dry run) error = %v", err)
+	}
+	if report.Matched != 3 || report.Skipped != 1 || report.Done != 0 || len(gh.changed) != 0 {
+		t.Errorf("dry run = %+v with %v changed, want 3 matched, #12 skipped and nothing changed", report, gh.changed)
+	}
+	if report.Results[0].Status != models.BulkItemPlanned || report.Results[1].Reason != "already closed" {
+		t.Errorf("dry run results = %+v, %+v, want #11 planned and #12 already closed", report.Results[0], report.Results[1])
+	}
+
+	report, err = s.ApplyBulkAction(ctx, &models.BulkRequest{Action: models.BulkActionClose, Filter: filter, Concurrency: 2})
+	if err != nil {
+		t.Fatalf("ApplyBulkAction() error = %v", err)
+	}
+	if report.Done != 1 || report.Failed != 1 || report.Results[2].Status != models.BulkItemFailed {
+		t.Errorf("close = %+v, want #11 closed and #13 failed", report)
+	}
+	if issue, _ := s.GetIssue(ctx, "org", "api", 11); issue.State != "CLOSED" || issue.ClosedAt == nil || len(issue.StateHistory) == 0 {
+		t.Errorf("stored #11 = %s, want CLOSED with its transition", issue.State)
+	}
+
+	// Only open items match by default, and labeled ones are skipped
+	report, err = s.ApplyBulkAction(ctx, &models.BulkRequest{Action: models.BulkActionLabel, Label: "Stale", Filter: models.BulkFilter{Repo: "org/api"}})
+	if err != nil {
+		t.Fatalf("ApplyBulkAction(label) error = %v", err)
+	}
+	if report.Matched != 2 || report.Skipped != 1 || report.Done != 1 || report.Results[1].Number != 14 {
+		t.Errorf("label = %+v, want #13 skipped and #14 labeled", report)
+	}
+
+	if _, err := s.ApplyBulkAction(ctx, &models.BulkRequest{Action: models.BulkActionComment}); !errors.Is(err, ErrInvalidBulkAction) {
+		t.Errorf("ApplyBulkAction(empty comment) error = %v, want ErrInvalidBulkAction", err)
+	}
+}
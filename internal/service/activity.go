@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+	"github.com/siddontang/github-repos-management/internal/notify"
+)
+
+// ListActivity lists recorded activity events, newest first
+func (s *Service) ListActivity(ctx context.Context, filter *models.ActivityFilter) ([]*models.ActivityEvent, *models.Pagination, error) {
+	// Without a repository the activity spans every workspace
+	if filter.Repo != "" {
+		if err := s.checkWorkspace(ctx, filter.Repo); err != nil {
+			return nil, nil, err
+		}
+	} else if workspaceToken(ctx) {
+		return nil, nil, ErrSessionRequired
+	}
+
+	events, total, err := s.db.ListActivity(ctx, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return events, &models.Pagination{
+		Page:       filter.Page,
+		PerPage:    filter.PerPage,
+		Total:      total,
+		TotalPages: (total + filter.PerPage - 1) / filter.PerPage,
+	}, nil
+}
+
+// activityRecorder appends every dispatched event to the activity log
+type activityRecorder struct {
+	service *Service
+}
+
+// Ensure activityRecorder implements notify.Notifier
+var _ notify.Notifier = (*activityRecorder)(nil)
+
+// Notify records the event in the activity log
+func (r *activityRecorder) Notify(ctx context.Context, event *notify.Event) error {
+	return r.service.db.AppendActivity(ctx, &models.ActivityEvent{
+		Type:          string(event.Type),
+		Repository:    event.Repository,
+		Number:        event.Number,
+		Title:         event.Title,
+		URL:           event.URL,
+		Author:        event.Author,
+		Label:         event.Label,
+		State:         event.State,
+		PreviousState: event.PreviousState,
+		Error:         event.Error,
+		CreatedAt:     event.Time,
+	})
+}
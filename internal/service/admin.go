@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"runtime"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// AdminStats reports storage statistics together with the process memory usage
+type AdminStats struct {
+	Storage    *models.StorageStats `json:"storage"`
+	HeapBytes  uint64               `json:"heap_bytes"`
+	SysBytes   uint64               `json:"sys_bytes"`
+	Goroutines int                  `json:"goroutines"`
+}
+
+// localAccessKey is the context key marking operations run in process on the database
+type localAccessKey struct{}
+
+// WithLocalAccess returns a context marking the operations performed with it as run in process by
+// someone able to read the database file, such as the CLI, which may use the admin operations
+// when no admin API key is configured. It must never be set for requests received over the network.
+func WithLocalAccess(ctx context.Context) context.Context {
+	return context.WithValue(ctx, localAccessKey{}, true)
+}
+
+// authorizeAdmin checks the admin API key. Without a configured key the admin operations are
+// refused, unless the context has local access to the database.
+func (s *Service) authorizeAdmin(ctx context.Context, apiKey string) error {
+	want := s.config.Admin.APIKey
+	if want == "" {
+		if local, _ := ctx.Value(localAccessKey{}).(bool); local {
+			return nil
+		}
+		return ErrAdminDisabled
+	}
+	if subtle.ConstantTimeCompare([]byte(apiKey), []byte(want)) != 1 {
+		return ErrAdminUnauthorized
+	}
+	return nil
+}
+
+// GetAdminStats returns entity counts per repository, the data file size and memory usage
+func (s *Service) GetAdminStats(ctx context.Context, apiKey string) (*AdminStats, error) {
+	if err := s.authorizeAdmin(ctx, apiKey); err != nil {
+		return nil, err
+	}
+
+	storage, err := s.db.Stats(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get storage stats: %w", err)
+	}
+
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	return &AdminStats{
+		Storage:    storage,
+		HeapBytes:  mem.HeapAlloc,
+		SysBytes:   mem.Sys,
+		Goroutines: runtime.NumGoroutine(),
+	}, nil
+}
+
+// CompactStorage drops the closed items the retention policy no longer keeps and the tombstoned
+// ones, removes orphaned data from the database and rewrites it, reporting the space reclaimed
+func (s *Service) CompactStorage(ctx context.Context, apiKey string) (*models.CompactionResult, error) {
+	if err := s.authorizeAdmin(ctx, apiKey); err != nil {
+		return nil, err
+	}
+
+	result, err := s.compact(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.audit(withCredential(ctx, apiKey), models.AuditAdminCompact, "", fmt.Sprintf("%d bytes freed", result.BytesBefore-result.BytesAfter))
+	return result, nil
+}
+
+// CheckIntegrity checks the references between the stored repositories, items and labels and, with
+// repair, fixes the problems found
+func (s *Service) CheckIntegrity(ctx context.Context, apiKey string, repair bool) (*models.IntegrityReport, error) {
+	if err := s.authorizeAdmin(ctx, apiKey); err != nil {
+		return nil, err
+	}
+
+	report, err := s.db.CheckIntegrity(ctx, repair)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check database integrity: %w", err)
+	}
+	if report.Repaired > 0 {
+		s.audit(withCredential(ctx, apiKey), models.AuditAdminRepair, "", fmt.Sprintf("%d problems repaired", report.Repaired))
+	}
+	return report, nil
+}
+
+// ClearRepositoryData drops the stored pull requests, issues and snapshots of a repository,
+// keeping it tracked so that the next refresh fetches them again
+func (s *Service) ClearRepositoryData(ctx context.Context, apiKey, owner, name string) error {
+	if err := s.authorizeAdmin(ctx, apiKey); err != nil {
+		return err
+	}
+
+	fullName := fmt.Sprintf("%s/%s", owner, name)
+	if _, err := s.db.GetRepository(ctx, owner, name); err != nil {
+		return notFound(err, ErrRepositoryNotFound)
+	}
+	if err := s.db.ClearRepositoryData(ctx, fullName); err != nil {
+		return fmt.Errorf("failed to clear repository data: %w", err)
+	}
+	s.audit(withCredential(ctx, apiKey), models.AuditAdminClear, fullName, "")
+	return nil
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"testing"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/db/file"
+)
+
+func TestAuthorizeAdmin(t *testing.T) {
+	newService := func(apiKey string) *Service {
+		t.Helper()
+		db, err := file.NewDB("")
+		if err != nil {
+			t.Fatalf("NewDB() error = %v", err)
+		}
+		s, err := NewServiceWithOptions(&config.Config{Admin: config.AdminConfig{APIKey: apiKey}}, Options{DB: db, Logger: log.New(io.Discard, "", 0)})
+		if err != nil {
+			t.Fatalf("NewServiceWithOptions() error = %v", err)
+		}
+		t.Cleanup(func() { s.Close() })
+		return s
+	}
+	ctx := context.Background()
+	local := WithLocalAccess(ctx)
+
+	// Without a key only local access is allowed
+	s := newService("")
+	if _, err := s.GetAdminStats(ctx, ""); !errors.Is(err, ErrAdminDisabled) {
+		t.Errorf("GetAdminStats() without a key error = %v, want ErrAdminDisabled", err)
+	}
+	if _, err := s.GetAdminStats(local, ""); err != nil {
+		t.Errorf("GetAdminStats() with local access error = %v", err)
+	}
+
+	// With a key everyone must present it, local access included
+	s = newService("s3cr3t")
+	for _, c := range []context.Context{ctx, local} {
+		if _, err := s.GetAdminStats(c, "wrong"); !errors.Is(err, ErrAdminUnauthorized) {
+			t.Errorf("GetAdminStats(wrong key) error = %v, want ErrAdminUnauthorized", err)
+		}
+		if _, err := s.GetAdminStats(c, "s3cr3t"); err != nil {
+			t.Errorf("GetAdminStats() error = %v", err)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// AggregatePullRequests counts the pull requests matching the filters of a list by the week or month
+// they were created, closed and merged in, ignoring sorting and pagination
+func (s *Service) AggregatePullRequests(ctx context.Context, filter *models.PullRequestFilter, period string) ([]*models.Bucket, error) {
+	if period != models.AggregateWeekly && period != models.AggregateMonthly {
+		return nil, ErrInvalidAggregate
+	}
+	prs, err := s.findPullRequests(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	buckets := newBuckets(period)
+	for _, pr := range prs {
+		buckets.get(pr.CreatedAt).Created++
+		if pr.ClosedAt != nil {
+			buckets.get(*pr.ClosedAt).Closed++
+		}
+		if pr.MergedAt != nil {
+			buckets.get(*pr.MergedAt).Merged++
+		}
+	}
+	return buckets.list(), nil
+}
+
+// AggregateIssues counts the issues matching the filters of a list by the week or month they were
+// created and closed in, ignoring sorting and pagination
+func (s *Service) AggregateIssues(ctx context.Context, filter *models.IssueFilter, period string) ([]*models.Bucket, error) {
+	if period != models.AggregateWeekly && period != models.AggregateMonthly {
+		return nil, ErrInvalidAggregate
+	}
+	issues, err := s.findIssues(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	buckets := newBuckets(period)
+	for _, issue := range issues {
+		buckets.get(issue.CreatedAt).Created++
+		if issue.ClosedAt != nil {
+			buckets.get(*issue.ClosedAt).Closed++
+		}
+	}
+	return buckets.list(), nil
+}
+
+// buckets collects counts by period start
+type buckets struct {
+	period string
+	byTime map[time.Time]*models.Bucket
+}
+
+func newBuckets(period string) *buckets {
+	return &buckets{period: period, byTime: make(map[time.Time]*models.Bucket)}
+}
+
+// start returns the start of the period of t: Monday of its week or the first day of its month, in UTC
+func (b *buckets) start(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	if b.period == models.AggregateMonthly {
+		return time.Date(y, m, 1, 0, 0, 0, 0, time.UTC)
+	}
+	day := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+}
+
+// next returns the start of the period after the one starting at start
+func (b *buckets) next(start time.Time) time.Time {
+	if b.period == models.AggregateMonthly {
+		return start.AddDate(0, 1, 0)
+	}
+	return start.AddDate(0, 0, 7)
+}
+
+// get returns the bucket of the period of t, creating it when needed
+func (b *buckets) get(t time.Time) *models.Bucket {
+	start := b.start(t)
+	bucket, ok := b.byTime[start]
+	if !ok {
+		bucket = &models.Bucket{Start: start}
+		b.byTime[start] = bucket
+	}
+	return bucket
+}
+
+// list returns the buckets from the first period with items to the last, oldest first, including
+// the empty periods in between so they can be charted as they are
+func (b *buckets) list() []*models.Bucket {
+	starts := make([]time.Time, 0, len(b.byTime))
+	for start := range b.byTime {
+		starts = append(starts, start)
+	}
+	if len(starts) == 0 {
+		return []*models.Bucket{}
+	}
+	sort.Slice(starts, func(i, j int) bool { return starts[i].Before(starts[j]) })
+
+	list := make([]*models.Bucket, 0, len(starts))
+	for start := starts[0]; !start.After(starts[len(starts)-1]); start = b.next(start) {
+		list = append(list, b.get(start))
+	}
+	return list
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/db/file"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+func TestAggregate(t *testing.T) {
+	ctx := context.Background()
+	db, err := file.NewDB("")
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	if err := db.AddRepository(ctx, &models.Repository{Owner: "org", Name: "api", FullName: "org/api"}); err != nil {
+		t.Fatalf("AddRepository() error = %v", err)
+	}
+
+	// Wednesday 2024-01-03, the following Monday and a Thursday two weeks later
+	wednesday := time.Date(2024, 1, 3, 12, 0, 0, 0, time.UTC)
+	monday := time.Date(2024, 1, 8, 9, 0, 0, 0, time.UTC)
+	thursday := time.Date(2024, 1, 25, 9, 0, 0, 0, time.UTC)
+	prs := []*models.PullRequest{
+		{Number: 1, State: "closed", CreatedAt: wednesday, ClosedAt: &monday, MergedAt: &monday},
+		{Number: 2, State: "open", CreatedAt: wednesday},
+		{Number: 3, State: "closed", CreatedAt: monday, ClosedAt: &thursday},
+	}
+	for _, pr := range prs {
+		pr.RepositoryFullName = "org/api"
+		if err := db.AddPullRequest(ctx, pr); err != nil {
+			t.Fatalf("AddPullRequest() error = %v", err)
+		}
+	}
+	s := &Service{db: db, config: &config.Config{}, logger: log.New(io.Discard, "", 0)}
+
+	buckets, err := s.AggregatePullRequests(ctx, &models.PullRequestFilter{}, models.AggregateWeekly)
+	if err != nil {
+		t.Fatalf("AggregatePullRequests(weekly) error = %v", err)
+	}
+	want := []models.Bucket{
+		{Start: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Created: 2},
+		{Start: time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC), Created: 1, Closed: 1, Merged: 1},
+		{Start: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)},
+		{Start: time.Date(2024, 1, 22, 0, 0, 0, 0, time.UTC), Closed: 1},
+	}
+	if len(buckets) != len(want) {
+		t.Fatalf("weekly buckets = %d, want %d", len(buckets), len(want))
+	}
+	for i := range want {
+		if *buckets[i] != want[i] {
+			t.Errorf("bucket %d = %+v, want %+v", i, *buckets[i], want[i])
+		}
+	}
+
+	buckets, err = s.AggregatePullRequests(ctx, &models.PullRequestFilter{State: "closed"}, models.AggregateMonthly)
+	if err != nil {
+		t.Fatalf("AggregatePullRequests(monthly) error = %v", err)
+	}
+	if len(buckets) != 1 || buckets[0].Created != 2 || buckets[0].Closed != 2 {
+		t.Errorf("monthly buckets of closed pull requests = %+v, want one with 2 created and closed", buckets)
+	}
+
+	if _, err := s.AggregateIssues(ctx, &models.IssueFilter{}, "daily"); !errors.Is(err, ErrInvalidAggregate) {
+		t.Errorf("AggregateIssues(daily) error = %v, want ErrInvalidAggregate", err)
+	}
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/siddontang/github-repos-management/internal/github"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// syncAlerts replaces the stored security alerts of a repository with the open alerts on GitHub,
+// reporting whether every kind was fetched. Alerts of a kind that fails to be fetched, such as
+// code scanning on a repository without it, keep their stored values.
+func (s *Service) syncAlerts(ctx context.Context, owner, name string) bool {
+	fullName := owner + "/" + name
+	stored, err := s.db.ListSecurityAlerts(ctx, fullName)
+	if err != nil {
+		s.logger.Printf("Error listing security alerts of %s: %v", fullName, err)
+		return false
+	}
+
+	complete := true
+	var alerts []*models.SecurityAlert
+	for kind, list := range map[string]func(owner, name string) ([]*github.SecurityAlert, error){
+		models.AlertKindDependabot:   s.gh(ctx).ListDependabotAlerts,
+		models.AlertKindCodeScanning: s.gh(ctx).ListCodeScanningAlerts,
+	} {
+		ghAlerts, err := list(owner, name)
+		if err != nil {
+			s.logger.Printf("Error syncing %s alerts of %s: %v", kind, fullName, err)
+			complete = false
+			for _, alert := range stored {
+				if alert.Kind == kind {
+					alerts = append(alerts, alert)
+				}
+			}
+			continue
+		}
+		for _, a := range ghAlerts {
+			alerts = append(alerts, &models.SecurityAlert{
+				Kind:      kind,
+				Number:    a.Number,
+				Severity:  a.Severity,
+				Summary:   a.Summary,
+				Package:   a.Package,
+				Rule:      a.Rule,
+				Path:      a.Path,
+				HTMLURL:   a.HTMLURL,
+				CreatedAt: a.CreatedAt,
+			})
+		}
+	}
+
+	if err := s.db.ReplaceSecurityAlerts(ctx, fullName, alerts); err != nil {
+		s.logger.Printf("Error storing security alerts of %s: %v", fullName, err)
+		return false
+	}
+	return complete
+}
+
+// ListSecurityAlerts lists the open security alerts of the tracked repositories matching the
+// filter, most severe first
+func (s *Service) ListSecurityAlerts(ctx context.Context, filter *models.SecurityAlertFilter) ([]*models.SecurityAlert, error) {
+	if filter.Severity != "" && models.SeverityRank(filter.Severity) == 0 {
+		return nil, ErrInvalidSeverity
+	}
+	if filter.Kind != "" && filter.Kind != models.AlertKindDependabot && filter.Kind != models.AlertKindCodeScanning {
+		return nil, ErrInvalidAlertKind
+	}
+
+	repos, err := s.selectRepositories(ctx, filter.Repo, filter.RepoTag)
+	if err != nil {
+		return nil, err
+	}
+	selected := make(map[string]bool, len(repos))
+	for _, repo := range repos {
+		selected[repo.FullName] = true
+	}
+
+	all, err := s.db.ListSecurityAlerts(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list security alerts: %w", err)
+	}
+	alerts := make([]*models.SecurityAlert, 0)
+	for _, alert := range all {
+		if !selected[alert.RepositoryFullName] {
+			continue
+		}
+		if filter.Kind != "" && alert.Kind != filter.Kind {
+			continue
+		}
+		if models.SeverityRank(alert.Severity) < models.SeverityRank(filter.Severity) {
+			continue
+		}
+		alerts = append(alerts, alert)
+	}
+
+	// Stored alerts are ordered by repository, kind and number
+	sort.SliceStable(alerts, func(i, j int) bool {
+		return models.SeverityRank(alerts[i].Severity) > models.SeverityRank(alerts[j].Severity)
+	})
+	return alerts, nil
+}
+
+// SummarizeSecurityAlerts counts the open security alerts of each tracked repository matching
+// the filter by severity, ordered by repository
+func (s *Service) SummarizeSecurityAlerts(ctx context.Context, filter *models.SecurityAlertFilter) ([]*models.SecurityAlertSummary, error) {
+	alerts, err := s.ListSecurityAlerts(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	repos, err := s.selectRepositories(ctx, filter.Repo, filter.RepoTag)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make([]*models.SecurityAlertSummary, 0, len(repos))
+	byRepo := make(map[string]*models.SecurityAlertSummary, len(repos))
+	for _, repo := range repos {
+		summary := &models.SecurityAlertSummary{Repository: repo.FullName, SyncedAt: repo.AlertsSyncedAt}
+		summaries = append(summaries, summary)
+		byRepo[repo.FullName] = summary
+	}
+	for _, alert := range alerts {
+		byRepo[alert.RepositoryFullName].Add(alert)
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].Repository < summaries[j].Repository })
+	return summaries, nil
+}
+
+// GetRepositoryAlerts returns the severity breakdown and open security alerts of a repository
+func (s *Service) GetRepositoryAlerts(ctx context.Context, owner, name string) (*models.SecurityAlertSummary, []*models.SecurityAlert, error) {
+	repo, err := s.GetRepository(ctx, owner, name)
+	if err != nil {
+		return nil, nil, err
+	}
+	alerts, err := s.ListSecurityAlerts(ctx, &models.SecurityAlertFilter{Repo: repo.FullName})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	summary := &models.SecurityAlertSummary{Repository: repo.FullName, SyncedAt: repo.AlertsSyncedAt}
+	for _, alert := range alerts {
+		summary.Add(alert)
+	}
+	return summary, alerts, nil
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"testing"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/db/file"
+	"github.com/siddontang/github-repos-management/internal/github"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// alertsGitHub serves Dependabot alerts and fails code scanning requests once scanning is off
+type alertsGitHub struct {
+	github.ClientInterface
+	scanning *bool
+}
+
+func (alertsGitHub) ListDependabotAlerts(owner, name string) ([]*github.SecurityAlert, error) {
+	return []*github.SecurityAlert{
+		{Number: 1, Severity: "medium", Package: "lodash"},
+		{Number: 2, Severity: "critical", Package: "log4j"},
+	}, nil
+}
+
+func (g alertsGitHub) ListCodeScanningAlerts(owner, name string) ([]*github.SecurityAlert, error) {
+	if !*g.scanning {
+		return nil, errors.New("code scanning is not enabled")
+	}
+	return []*github.SecurityAlert{{Number: 7, Severity: "high", Rule: "go/sql-injection"}}, nil
+}
+
+func TestSecurityAlerts(t *testing.T) {
+	ctx := context.Background()
+	db, err := file.NewDB("")
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	for _, fullName := range []string{"org/repo", "org/clean"} {
+		if err := db.AddRepository(ctx, &models.Repository{Owner: "org", Name: fullName[4:], FullName: fullName}); err != nil {
+			t.Fatalf("AddRepository() error = %v", err)
+		}
+	}
+	scanning := true
+	s := &Service{db: db, ghClient: alertsGitHub{scanning: &scanning}, config: &config.Config{}, logger: log.New(io.Discard, "", 0)}
+
+	if !s.syncAlerts(ctx, "org", "repo") {
+		t.Fatal("syncAlerts() = false, want every kind fetched")
+	}
+	// Alerts of a kind that can't be fetched are kept
+	scanning = false
+	if s.syncAlerts(ctx, "org", "repo") {
+		t.Error("syncAlerts() = true with code scanning failing")
+	}
+
+	alerts, err := s.ListSecurityAlerts(ctx, &models.SecurityAlertFilter{})
+	if err != nil {
+		t.Fatalf("ListSecurityAlerts() error = %v", err)
+	}
+	if len(alerts) != 3 || alerts[0].Package != "log4j" || alerts[1].Rule != "go/sql-injection" || alerts[2].Package != "lodash" {
+		t.Fatalf("ListSecurityAlerts() = %+v, want the 3 alerts most severe first", alerts)
+	}
+
+	alerts, _ = s.ListSecurityAlerts(ctx, &models.SecurityAlertFilter{Severity: models.SeverityHigh, Kind: models.AlertKindDependabot})
+	if len(alerts) != 1 || alerts[0].Number != 2 {
+		t.Errorf("ListSecurityAlerts(high, dependabot) = %+v, want the critical Dependabot alert", alerts)
+	}
+	if _, err := s.ListSecurityAlerts(ctx, &models.SecurityAlertFilter{Severity: "severe"}); !errors.Is(err, ErrInvalidSeverity) {
+		t.Errorf("ListSecurityAlerts(severe) error = %v, want ErrInvalidSeverity", err)
+	}
+
+	summaries, err := s.SummarizeSecurityAlerts(ctx, &models.SecurityAlertFilter{})
+	if err != nil {
+		t.Fatalf("SummarizeSecurityAlerts() error = %v", err)
+	}
+	if len(summaries) != 2 || summaries[0].Repository != "org/clean" || summaries[0].Total != 0 {
+		t.Fatalf("SummarizeSecurityAlerts() = %+v, want org/clean without alerts first", summaries)
+	}
+	if got := summaries[1]; got.Total != 3 || got.Critical != 1 || got.High != 1 || got.Medium != 1 || got.Low != 0 {
+		t.Errorf("summary of org/repo = %+v, want one critical, high and medium alert", got)
+	}
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+
+	"github.com/siddontang/github-repos-management/internal/analytics"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// GetAnalytics computes lead-time and review-latency metrics for items created within the filter's time range
+func (s *Service) GetAnalytics(ctx context.Context, filter *models.AnalyticsFilter) (*analytics.Report, error) {
+	repos, err := s.selectRepositories(ctx, filter.Repo, filter.RepoTag)
+	if err != nil {
+		return nil, err
+	}
+
+	// Read from a snapshot so the queries over every repository don't hold up syncs
+	snap, err := s.db.Snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var prs []*models.PullRequest
+	var issues []*models.Issue
+	for _, repo := range repos {
+		repoPRs, err := snap.ListAllPullRequests(ctx, repo.FullName)
+		if err != nil {
+			continue
+		}
+		for _, pr := range filterPullRequestAuthors(repoPRs, filter.ExcludeBots, filter.Association) {
+			if analytics.InRange(pr.CreatedAt, filter.Since, filter.Until) {
+				prs = append(prs, pr)
+			}
+		}
+
+		repoIssues, err := snap.ListAllIssues(ctx, repo.FullName)
+		if err != nil {
+			continue
+		}
+		for _, issue := range filterIssueAuthors(repoIssues, filter.ExcludeBots, filter.Association) {
+			if analytics.InRange(issue.CreatedAt, filter.Since, filter.Until) {
+				issues = append(issues, issue)
+			}
+		}
+	}
+
+	var commits []*models.Commit
+	for _, commit := range s.listSelectedCommits(ctx, repos, filter.ExcludeBots, filter.Association) {
+		if analytics.InRange(commit.CommittedAt, filter.Since, filter.Until) {
+			commits = append(commits, commit)
+		}
+	}
+
+	return analytics.Compute(prs, issues, commits, s.hours), nil
+}
+
+// GetLeaderboard summarizes contributions per user within the filter's time range, most active first
+func (s *Service) GetLeaderboard(ctx context.Context, filter *models.LeaderboardFilter) ([]*analytics.Contribution, *models.Pagination, error) {
+	repos, err := s.selectRepositories(ctx, filter.Repo, filter.RepoTag)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	snap, err := s.db.Snapshot(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var prs []*models.PullRequest
+	var issues []*models.Issue
+	for _, repo := range repos {
+		repoPRs, err := snap.ListAllPullRequests(ctx, repo.FullName)
+		if err != nil {
+			continue
+		}
+		prs = append(prs, filterPullRequestAuthors(repoPRs, filter.ExcludeBots, filter.Association)...)
+
+		repoIssues, err := snap.ListAllIssues(ctx, repo.FullName)
+		if err != nil {
+			continue
+		}
+		issues = append(issues, filterIssueAuthors(repoIssues, filter.ExcludeBots, filter.Association)...)
+	}
+
+	commits := s.listSelectedCommits(ctx, repos, filter.ExcludeBots, filter.Association)
+	contributions := analytics.Leaderboard(prs, issues, commits, filter.Since, filter.Until)
+
+	key := func(i int) cursorKey {
+		return cursorKey{User: contributions[i].User, Total: contributions[i].Total()}
+	}
+	start, end, pagination, err := window(len(contributions), key, contributionLess, filter.Page, filter.PerPage, filter.Cursor)
+	if err != nil {
+		return nil, nil, err
+	}
+	return contributions[start:end], pagination, nil
+}
+
+// GetTopics groups the open issues of the selected repositories into themes by label and title
+// keyword, most issues first
+func (s *Service) GetTopics(ctx context.Context, filter *models.TopicFilter) ([]*analytics.Topic, error) {
+	repos, err := s.selectRepositories(ctx, filter.Repo, filter.RepoTag)
+	if err != nil {
+		return nil, err
+	}
+
+	snap, err := s.db.Snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []*analytics.TopicIssue
+	for _, repo := range repos {
+		repoIssues, err := snap.FindIssues(ctx, &models.ItemQuery{Repositories: []string{repo.FullName}, State: "open"})
+		if err != nil {
+			continue
+		}
+		for _, issue := range filterIssueAuthors(liveIssues(repoIssues), filter.ExcludeBots, "") {
+			topicIssue := &analytics.TopicIssue{Repository: issue.RepositoryFullName, Number: issue.Number, Title: issue.Title}
+			labels, _ := snap.ListIssueLabels(ctx, issue.RepositoryFullName, issue.Number)
+			for _, label := range labels {
+				topicIssue.Labels = append(topicIssue.Labels, label.Name)
+			}
+			issues = append(issues, topicIssue)
+		}
+	}
+
+	return analytics.Topics(issues, filter.Limit), nil
+}
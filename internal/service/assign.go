@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/siddontang/github-repos-management/internal/github"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// milestoneTitle returns the title of a milestone, or "" when there is none
+func milestoneTitle(milestone *github.Milestone) string {
+	if milestone == nil {
+		return ""
+	}
+	return milestone.Title
+}
+
+// UpdatePullRequest changes the assignees or milestone of a pull request. The stored pull request is
+// changed first so listings reflect the update at once, and restored when GitHub rejects it.
+func (s *Service) UpdatePullRequest(ctx context.Context, owner, name string, number int, update *models.ItemUpdate) (*models.PullRequest, error) {
+	repo, err := s.db.GetRepository(ctx, owner, name)
+	if err != nil {
+		return nil, notFound(err, ErrRepositoryNotFound)
+	}
+	if err := s.checkWorkspace(ctx, repo.FullName); err != nil {
+		return nil, err
+	}
+	stored, err := s.db.GetPullRequest(ctx, repo.FullName, number)
+	if err != nil {
+		return nil, notFound(err, ErrPullRequestNotFound)
+	}
+	assignees, milestone, change, err := s.planItemUpdate(ctx, repo, stored.Assignees, stored.Milestone, update)
+	if err != nil {
+		return nil, err
+	}
+
+	previous, pr := *stored, *stored
+	pr.Assignees, pr.Milestone = assignees, milestone
+	if err := s.db.UpdatePullRequest(ctx, &pr); err != nil {
+		return nil, fmt.Errorf("failed to store pull request #%d: %w", number, err)
+	}
+	ghIssue, err := s.gh(ctx).UpdateIssue(repo.Owner, repo.Name, number, change)
+	if err != nil {
+		if err := s.db.UpdatePullRequest(ctx, &previous); err != nil {
+			s.logger.Printf("Error restoring pull request %s#%d: %v", repo.FullName, number, err)
+		}
+		return nil, err
+	}
+
+	// Store what GitHub applied, which leaves out assignees lacking access
+	updated := pr
+	updated.Assignees, updated.Milestone = userLogins(ghIssue.Assignees), milestoneTitle(ghIssue.Milestone)
+	if ghIssue.UpdatedAt.After(updated.UpdatedAt) {
+		updated.UpdatedAt = ghIssue.UpdatedAt
+	}
+	if err := s.db.UpdatePullRequest(ctx, &updated); err != nil {
+		s.logger.Printf("Error storing pull request %s#%d: %v", repo.FullName, number, err)
+	}
+	s.audit(ctx, models.AuditItemUpdate, fmt.Sprintf("%s#%d", repo.FullName, number), describeItemUpdate(update))
+	return &updated, nil
+}
+
+// UpdateIssue changes the assignees or milestone of an issue. The stored issue is changed first so
+// listings reflect the update at once, and restored when GitHub rejects it.
+func (s *Service) UpdateIssue(ctx context.Context, owner, name string, number int, update *models.ItemUpdate) (*models.Issue, error) {
+	repo, err := s.db.GetRepository(ctx, owner, name)
+	if err != nil {
+		return nil, notFound(err, ErrRepositoryNotFound)
+	}
+	if err := s.checkWorkspace(ctx, repo.FullName); err != nil {
+		return nil, err
+	}
+	stored, err := s.db.GetIssue(ctx, repo.FullName, number)
+	if err != nil {
+		return nil, notFound(err, ErrIssueNotFound)
+	}
+	assignees, milestone, change, err := s.planItemUpdate(ctx, repo, stored.Assignees, stored.Milestone, update)
+	if err != nil {
+		return nil, err
+	}
+
+	previous, issue := *stored, *stored
+	issue.Assignees, issue.Milestone = assignees, milestone
+	if err := s.db.UpdateIssue(ctx, &issue); err != nil {
+		return nil, fmt.Errorf("failed to store issue #%d: %w", number, err)
+	}
+	ghIssue, err := s.gh(ctx).UpdateIssue(repo.Owner, repo.Name, number, change)
+	if err != nil {
+		if err := s.db.UpdateIssue(ctx, &previous); err != nil {
+			s.logger.Printf("Error restoring issue %s#%d: %v", repo.FullName, number, err)
+		}
+		return nil, err
+	}
+
+	updated := issue
+	updated.Assignees, updated.Milestone = userLogins(ghIssue.Assignees), milestoneTitle(ghIssue.Milestone)
+	if ghIssue.UpdatedAt.After(updated.UpdatedAt) {
+		updated.UpdatedAt = ghIssue.UpdatedAt
+	}
+	if err := s.db.UpdateIssue(ctx, &updated); err != nil {
+		s.logger.Printf("Error storing issue %s#%d: %v", repo.FullName, number, err)
+	}
+	s.audit(ctx, models.AuditItemUpdate, fmt.Sprintf("%s#%d", repo.FullName, number), describeItemUpdate(update))
+	return &updated, nil
+}
+
+// planItemUpdate returns the assignees and milestone an item has after an update, and the change
+// to send to GitHub. Logins may start with @ and match the current assignees ignoring case;
+// milestones are found by title among the synced ones, or fetched when not synced.
+func (s *Service) planItemUpdate(ctx context.Context, repo *models.Repository, assignees []string, milestone string, update *models.ItemUpdate) ([]string, string, *github.IssueUpdate, error) {
+	if len(update.AddAssignees) == 0 && len(update.RemoveAssignees) == 0 && update.Milestone == nil {
+		return nil, "", nil, ErrInvalidItemUpdate
+	}
+	change := &github.IssueUpdate{}
+
+	if len(update.AddAssignees) > 0 || len(update.RemoveAssignees) > 0 {
+		result := make([]string, 0, len(assignees)+len(update.AddAssignees))
+		for _, login := range assignees {
+			if !containsLogin(update.RemoveAssignees, login) {
+				result = append(result, login)
+			}
+		}
+		for _, login := range update.AddAssignees {
+			login = strings.TrimPrefix(strings.TrimSpace(login), "@")
+			if login == "" {
+				return nil, "", nil, ErrInvalidItemUpdate
+			}
+			if !containsLogin(result, login) {
+				result = append(result, login)
+			}
+		}
+		assignees, change.Assignees = result, result
+	}
+
+	if update.Milestone != nil {
+		title := strings.TrimSpace(*update.Milestone)
+		number := 0
+		if title != "" {
+			found, err := s.findMilestone(ctx, repo, title)
+			if err != nil {
+				return nil, "", nil, err
+			}
+			title, number = found.Title, found.Number
+		}
+		milestone, change.Milestone = title, &number
+	}
+	return assignees, milestone, change, nil
+}
+
+// findMilestone finds a milestone of a repository by title, ignoring case
+func (s *Service) findMilestone(ctx context.Context, repo *models.Repository, title string) (*models.Milestone, error) {
+	if milestones, err := s.db.ListMilestones(ctx, repo.FullName); err == nil {
+		for _, m := range milestones {
+			if strings.EqualFold(m.Title, title) {
+				return m, nil
+			}
+		}
+	}
+	ghMilestones, err := s.gh(ctx).ListMilestones(repo.Owner, repo.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list milestones: %w", err)
+	}
+	for _, m := range ghMilestones {
+		if strings.EqualFold(m.Title, title) {
+			return &models.Milestone{RepositoryFullName: repo.FullName, Number: m.Number, Title: m.Title}, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %q in %s", ErrMilestoneNotFound, title, repo.FullName)
+}
+
+// containsLogin reports whether logins contains login, ignoring case and a leading @
+func containsLogin(logins []string, login string) bool {
+	login = strings.TrimPrefix(strings.TrimSpace(login), "@")
+	for _, l := range logins {
+		if strings.EqualFold(strings.TrimPrefix(strings.TrimSpace(l), "@"), login) {
+			return true
+		}
+	}
+	return false
+}
+
+// describeItemUpdate describes an update, such as "+alice -bob, milestone v1.0"
+func describeItemUpdate(update *models.ItemUpdate) string {
+	var parts []string
+	var assignees []string
+	for _, login := range update.AddAssignees {
+		assignees = append(assignees, "+"+strings.TrimPrefix(login, "@"))
+	}
+	for _, login := range update.RemoveAssignees {
+		assignees = append(assignees, "-"+strings.TrimPrefix(login, "@"))
+	}
+	if len(assignees) > 0 {
+		parts = append(parts, strings.Join(assignees, " "))
+	}
+	if update.Milestone != nil {
+		if *update.Milestone == "" {
+			parts = append(parts, "milestone removed")
+		} else {
+			parts = append(parts, "milestone "+*update.Milestone)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/db"
+	"github.com/siddontang/github-repos-management/internal/db/file"
+	"github.com/siddontang/github-repos-management/internal/github"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// assigningGitHub applies updates except to assignees named "ghost", recording the stored pull
+// request seen while the update is sent
+type assigningGitHub struct {
+	github.ClientInterface
+	db     db.DB
+	during []string
+}
+
+func (g *assigningGitHub) ListMilestones(owner, name string) ([]*github.Milestone, error) {
+	return []*github.Milestone{{Number: 3, Title: "v1.0"}}, nil
+}
+
+func (g *assigningGitHub) UpdateIssue(owner, name string, number int, update *github.IssueUpdate) (*github.Issue, error) {
+	if pr, err := g.db.GetPullRequest(context.Background(), owner+"/"+name, number); err == nil {
+		g.during = pr.Assignees
+	}
+	issue := &github.Issue{Number: number, UpdatedAt: time.Now()}
+	for _, login := range update.Assignees {
+		if login == "ghost" {
+			return nil, errors.New("422 validation failed")
+		}
+		issue.Assignees = append(issue.Assignees, github.User{Login: login})
+	}
+	if update.Milestone != nil && *update.Milestone == 3 {
+		issue.Milestone = &github.Milestone{Number: 3, Title: "v1.0"}
+	}
+	return issue, nil
+}
+
+func TestUpdatePullRequest(t *testing.T) {
+	ctx := context.Background()
+	store, err := file.NewDB("")
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	if err := store.AddRepository(ctx, &models.Repository{Owner: "org", Name: "api", FullName: "org/api"}); err != nil {
+		t.Fatalf("AddRepository() error = %v", err)
+	}
+	updatedAt := time.Now().Add(-time.Hour)
+	if err := store.AddPullRequest(ctx, &models.PullRequest{RepositoryFullName: "org/api", Number: 1, Assignees: []string{"Bob"}, UpdatedAt: updatedAt}); err != nil {
+		t.Fatalf("AddPullRequest() error = %v", err)
+	}
+	gh := &assigningGitHub{db: store}
+	s := &Service{db: store, ghClient: gh, config: &config.Config{}, logger: log.New(io.Discard, "", 0)}
+
+	milestone := "V1.0"
+	pr, err := s.UpdatePullRequest(ctx, "org", "api", 1, &models.ItemUpdate{AddAssignees: []string{"@alice"}, RemoveAssignees: []string{"bob"}, Milestone: &milestone})
+	if err != nil {
+		t.Fatalf("UpdatePullRequest() error = %v", err)
+	}
+	if want := []string{"alice"}; !reflect.DeepEqual(gh.during, want) || !reflect.DeepEqual(pr.Assignees, want) {
+		t.Errorf("assignees while sending = %v, after = %v, want %v", gh.during, pr.Assignees, want)
+	}
+	if pr.Milestone != "v1.0" || !pr.UpdatedAt.After(updatedAt) {
+		t.Errorf("milestone = %q updated at %v, want v1.0 updated now", pr.Milestone, pr.UpdatedAt)
+	}
+
+	// A rejected update is rolled back
+	if _, err := s.UpdatePullRequest(ctx, "org", "api", 1, &models.ItemUpdate{AddAssignees: []string{"ghost"}}); err == nil {
+		t.Fatal("UpdatePullRequest(ghost) error = nil, want the GitHub error")
+	}
+	if want := []string{"alice", "ghost"}; !reflect.DeepEqual(gh.during, want) {
+		t.Errorf("assignees while sending = %v, want %v", gh.during, want)
+	}
+	stored, _ := s.GetPullRequest(ctx, "org", "api", 1)
+	if want := []string{"alice"}; !reflect.DeepEqual(stored.Assignees, want) || stored.Milestone != "v1.0" {
+		t.Errorf("after rollback = %v, %q, want %v, v1.0", stored.Assignees, stored.Milestone, want)
+	}
+
+	unknown := "v9"
+	if _, err := s.UpdatePullRequest(ctx, "org", "api", 1, &models.ItemUpdate{Milestone: &unknown}); !errors.Is(err, ErrMilestoneNotFound) {
+		t.Errorf("UpdatePullRequest(unknown milestone) error = %v, want ErrMilestoneNotFound", err)
+	}
+	if _, err := s.UpdatePullRequest(ctx, "org", "api", 1, &models.ItemUpdate{}); !errors.Is(err, ErrInvalidItemUpdate) {
+		t.Errorf("UpdatePullRequest(empty) error = %v, want ErrInvalidItemUpdate", err)
+	}
+	if _, err := s.UpdateIssue(ctx, "org", "api", 1, &models.ItemUpdate{AddAssignees: []string{"alice"}}); !errors.Is(err, ErrIssueNotFound) {
+		t.Errorf("UpdateIssue(unknown) error = %v, want ErrIssueNotFound", err)
+	}
+}
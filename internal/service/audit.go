@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// unknownActor is recorded for operations whose context does not name an actor
+const unknownActor = "unknown"
+
+// actorKey is the context key of the actor performing an operation
+type actorKey struct{}
+
+// credentialKey is the context key of the API key an operation was authorized with
+type credentialKey struct{}
+
+// WithActor returns a context attributing the operations performed with it to actor in the audit log
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// withCredential returns a context recording that the operation presented apiKey
+func withCredential(ctx context.Context, apiKey string) context.Context {
+	if apiKey == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, credentialKey{}, MaskSecret(apiKey))
+}
+
+// MaskSecret shortens a secret to its last four characters so it can be recorded or logged
+func MaskSecret(secret string) string {
+	if len(secret) <= 4 {
+		return "…"
+	}
+	return "…" + secret[len(secret)-4:]
+}
+
+// audit records a successful mutation. Failures are logged, as the mutation already happened.
+func (s *Service) audit(ctx context.Context, action, target, detail string) {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	if actor == "" {
+		actor = unknownActor
+	}
+	credential, _ := ctx.Value(credentialKey{}).(string)
+
+	entry := &models.AuditEntry{
+		Actor:      actor,
+		Credential: credential,
+		Action:     action,
+		Target:     target,
+		Detail:     detail,
+		CreatedAt:  time.Now(),
+	}
+	if err := s.db.AppendAudit(ctx, entry); err != nil {
+		s.logger.Printf("Error recording audit entry %s %s by %s: %v", action, target, actor, err)
+	}
+}
+
+// ListAudit lists audit log entries matching the filter, newest first
+func (s *Service) ListAudit(ctx context.Context, filter *models.AuditFilter) ([]*models.AuditEntry, *models.Pagination, error) {
+	// The audit log spans every workspace
+	if workspaceToken(ctx) {
+		return nil, nil, ErrSessionRequired
+	}
+
+	entries, total, err := s.db.ListAudit(ctx, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return entries, &models.Pagination{
+		Page:       filter.Page,
+		PerPage:    filter.PerPage,
+		Total:      total,
+		TotalPages: (total + filter.PerPage - 1) / filter.PerPage,
+	}, nil
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/siddontang/github-repos-management/internal/db/file"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+func TestAuditLog(t *testing.T) {
+	db, err := file.NewDB("")
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	if err := db.AddRepository(context.Background(), &models.Repository{Owner: "org", Name: "repo", FullName: "org/repo"}); err != nil {
+		t.Fatalf("AddRepository() error = %v", err)
+	}
+	s := &Service{db: db}
+
+	alice := WithActor(context.Background(), "alice")
+	if _, err := s.AddRepositoryTag(alice, "org", "repo", "backend"); err != nil {
+		t.Fatalf("AddRepositoryTag() error = %v", err)
+	}
+	if _, err := s.PauseRepository(context.Background(), "org", "repo"); err != nil {
+		t.Fatalf("PauseRepository() error = %v", err)
+	}
+	if _, err := s.AddRepositoryTag(alice, "org", "missing", "backend"); err == nil {
+		t.Fatal("AddRepositoryTag() of an untracked repository should fail")
+	}
+
+	entries, pagination, err := s.ListAudit(context.Background(), &models.AuditFilter{Page: 1, PerPage: 10})
+	if err != nil {
+		t.Fatalf("ListAudit() error = %v", err)
+	}
+	if pagination.Total != 2 {
+		t.Fatalf("ListAudit() total = %d, want only the 2 successful changes", pagination.Total)
+	}
+	if e := entries[0]; e.Action != models.AuditRepositoryPause || e.Actor != unknownActor {
+		t.Errorf("newest entry = %+v, want a pause by an unknown actor", e)
+	}
+	if e := entries[1]; e.Action != models.AuditRepositoryTag || e.Actor != "alice" || e.Target != "org/repo" || e.Detail != "backend" {
+		t.Errorf("oldest entry = %+v, want alice tagging org/repo", e)
+	}
+
+	entries, _, _ = s.ListAudit(context.Background(), &models.AuditFilter{Actor: "alice", Action: "repository", Page: 1, PerPage: 10})
+	if len(entries) != 1 || entries[0].Action != models.AuditRepositoryTag {
+		t.Errorf("ListAudit(alice, repository) = %+v, want the tag entry", entries)
+	}
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// authorAssociations fetches the author associations of a repository's recently updated items.
+// Failures are logged and yield no associations, keeping the ones already stored.
+func (s *Service) authorAssociations(ctx context.Context, owner, name string, limit int) map[int]string {
+	associations, err := s.gh(ctx).ListAuthorAssociations(owner, name, limit)
+	if err != nil {
+		s.logger.Printf("Error fetching author associations of %s/%s: %v", owner, name, err)
+		return nil
+	}
+	return associations
+}
+
+// authorMatcher reports whether an item author passes the bot and association filters.
+// associations is a comma separated list, matched case-insensitively; empty matches everything.
+func authorMatcher(excludeBots bool, associations string) func(isBot bool, association string) bool {
+	wanted := make(map[string]bool)
+	for _, a := range strings.Split(associations, ",") {
+		if a = strings.TrimSpace(a); a != "" {
+			wanted[strings.ToUpper(a)] = true
+		}
+	}
+
+	return func(isBot bool, association string) bool {
+		if excludeBots && isBot {
+			return false
+		}
+		return len(wanted) == 0 || wanted[strings.ToUpper(association)]
+	}
+}
+
+// filterPullRequestAuthors drops pull requests whose authors do not pass the bot and association filters
+func filterPullRequestAuthors(prs []*models.PullRequest, excludeBots bool, associations string) []*models.PullRequest {
+	if !excludeBots && associations == "" {
+		return prs
+	}
+	match := authorMatcher(excludeBots, associations)
+	filtered := make([]*models.PullRequest, 0, len(prs))
+	for _, pr := range prs {
+		if match(pr.UserIsBot, pr.AuthorAssociation) {
+			filtered = append(filtered, pr)
+		}
+	}
+	return filtered
+}
+
+// filterIssueAuthors drops issues whose authors do not pass the bot and association filters
+func filterIssueAuthors(issues []*models.Issue, excludeBots bool, associations string) []*models.Issue {
+	if !excludeBots && associations == "" {
+		return issues
+	}
+	match := authorMatcher(excludeBots, associations)
+	filtered := make([]*models.Issue, 0, len(issues))
+	for _, issue := range issues {
+		if match(issue.UserIsBot, issue.AuthorAssociation) {
+			filtered = append(filtered, issue)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+func TestFilterPullRequestAuthors(t *testing.T) {
+	prs := []*models.PullRequest{
+		{Number: 1, UserLogin: "alice", AuthorAssociation: "MEMBER"},
+		{Number: 2, UserLogin: "dependabot[bot]", UserIsBot: true, AuthorAssociation: "NONE"},
+		{Number: 3, UserLogin: "bob", AuthorAssociation: "FIRST_TIMER"},
+		{Number: 4, UserLogin: "carol"},
+	}
+
+	tests := []struct {
+		name         string
+		excludeBots  bool
+		associations string
+		want         []int
+	}{
+		{"no filters", false, "", []int{1, 2, 3, 4}},
+		{"exclude bots", true, "", []int{1, 3, 4}},
+		{"single association", false, "member", []int{1}},
+		{"several associations", false, "MEMBER, FIRST_TIMER", []int{1, 3}},
+		{"bots and association", true, "NONE,MEMBER", []int{1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := filterPullRequestAuthors(prs, tt.excludeBots, tt.associations)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d pull requests, want %d", len(got), len(tt.want))
+			}
+			for i, pr := range got {
+				if pr.Number != tt.want[i] {
+					t.Errorf("got #%d at %d, want #%d", pr.Number, i, tt.want[i])
+				}
+			}
+		})
+	}
+}
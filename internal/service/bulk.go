@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// maxOrganizationRepositories bounds how many repositories an organization import adds
+const maxOrganizationRepositories = 1000
+
+// AddRepositories adds several repositories to be tracked, reporting the outcome of each.
+// Newly added repositories are synced after all of them are stored.
+func (s *Service) AddRepositories(ctx context.Context, fullNames []string) []*models.RepositoryAddResult {
+	results, added := s.trackRepositories(ctx, fullNames)
+	s.syncRepositories(ctx, added, nil)
+	return results
+}
+
+// StartAddRepositories adds several repositories like AddRepositories, but returns once their
+// syncs are queued. Each newly added repository comes with its sync job to poll.
+func (s *Service) StartAddRepositories(ctx context.Context, fullNames []string) []*models.RepositoryAddResult {
+	results, _ := s.trackRepositories(ctx, fullNames)
+	for _, result := range results {
+		if result.Repository == nil || result.Existing {
+			continue
+		}
+		job, err := s.jobs.EnqueueWithPriority(ctx, models.JobTypeSyncRepository, result.Repository.FullName, syncPriority(result.Repository))
+		if err != nil {
+			s.logger.Printf("Error queueing sync of repository %s: %v", result.Repository.FullName, err)
+			continue
+		}
+		result.Job = job
+	}
+	return results
+}
+
+// trackRepositories tracks several repositories, returning the outcome of each and the newly added ones
+func (s *Service) trackRepositories(ctx context.Context, fullNames []string) ([]*models.RepositoryAddResult, []*models.Repository) {
+	results := make([]*models.RepositoryAddResult, 0, len(fullNames))
+	var added []*models.Repository
+	for _, fullName := range fullNames {
+		fullName = strings.TrimSpace(fullName)
+		result := &models.RepositoryAddResult{FullName: fullName}
+		results = append(results, result)
+
+		repo, created, err := s.trackRepository(ctx, fullName)
+		if err != nil {
+			result.Error = err.Error()
+			continue
+		}
+		result.Repository = repo
+		result.Existing = !created
+		if created {
+			added = append(added, repo)
+		}
+	}
+	return results, added
+}
+
+// AddOrganizationRepositories adds every non-archived repository of an organization
+func (s *Service) AddOrganizationRepositories(ctx context.Context, org string) ([]*models.RepositoryAddResult, error) {
+	fullNames, err := s.organizationRepositories(ctx, org)
+	if err != nil {
+		return nil, err
+	}
+	return s.AddRepositories(ctx, fullNames), nil
+}
+
+// StartAddOrganizationRepositories adds every non-archived repository of an organization like
+// StartAddRepositories, returning once their syncs are queued
+func (s *Service) StartAddOrganizationRepositories(ctx context.Context, org string) ([]*models.RepositoryAddResult, error) {
+	fullNames, err := s.organizationRepositories(ctx, org)
+	if err != nil {
+		return nil, err
+	}
+	return s.StartAddRepositories(ctx, fullNames), nil
+}
+
+// organizationRepositories lists the full names of the non-archived repositories of an organization
+func (s *Service) organizationRepositories(ctx context.Context, org string) ([]string, error) {
+	org = strings.TrimSpace(org)
+	if org == "" || strings.Contains(org, "/") {
+		return nil, ErrInvalidOrganization
+	}
+
+	fullNames, err := s.gh(ctx).ListOrganizationRepositories(org, maxOrganizationRepositories)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organization repositories: %w", err)
+	}
+	return fullNames, nil
+}
+
+// syncProgressInterval is how often syncRepositories checks the state of its jobs
+const syncProgressInterval = 100 * time.Millisecond
+
+// syncRepositories syncs repositories through the job queue and waits for them, logging failures,
+// and returns the final state of each job. The queue bounds how many run at once, starting them in
+// order within each priority class. progress, if not nil, is called with each job when it is queued
+// and whenever its state or attempt changes.
+func (s *Service) syncRepositories(ctx context.Context, repos []*models.Repository, progress func(*models.Job)) []*models.Job {
+	jobs := make([]*models.Job, 0, len(repos))
+	for _, repo := range repos {
+		job, err := s.jobs.EnqueueWithPriority(ctx, models.JobTypeSyncRepository, repo.FullName, syncPriority(repo))
+		if err != nil {
+			s.logger.Printf("Error queueing sync of repository %s: %v", repo.FullName, err)
+			continue
+		}
+		jobs = append(jobs, job)
+		if progress != nil {
+			progress(job)
+		}
+	}
+
+	ticker := time.NewTicker(syncProgressInterval)
+	defer ticker.Stop()
+	waiting := len(jobs)
+	finished := make([]bool, len(jobs))
+	for waiting > 0 {
+		select {
+		case <-ctx.Done():
+			s.logger.Printf("Error waiting for sync jobs: %v", ctx.Err())
+			return jobs
+		case <-ticker.C:
+		}
+		for i, job := range jobs {
+			if finished[i] {
+				continue
+			}
+			current, err := s.jobs.Get(ctx, job.ID)
+			if err != nil {
+				s.logger.Printf("Error waiting for sync job: %v", err)
+				finished[i] = true
+				waiting--
+				continue
+			}
+			if current.State == job.State && current.Attempts == job.Attempts {
+				continue
+			}
+			jobs[i] = current
+			if progress != nil {
+				progress(current)
+			}
+			if !current.Done() {
+				continue
+			}
+			finished[i] = true
+			waiting--
+			switch current.State {
+			case models.JobStateSucceeded:
+				s.logger.Printf("Successfully synced repository: %s", current.Target)
+			case models.JobStateFailed:
+				s.logger.Printf("Error syncing repository %s: %s", current.Target, current.Error)
+			}
+		}
+	}
+	return jobs
+}
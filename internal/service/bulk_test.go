@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"io"
+	"log"
+	"testing"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/db/file"
+	"github.com/siddontang/github-repos-management/internal/github"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+func TestRefreshAllWithProgress(t *testing.T) {
+	ctx := context.Background()
+	db, err := file.NewDB("")
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	for _, name := range []string{"api", "web", "docs"} {
+		if err := db.AddRepository(ctx, &models.Repository{Owner: "org", Name: name, FullName: "org/" + name}); err != nil {
+			t.Fatalf("AddRepository() error = %v", err)
+		}
+	}
+
+	cfg := &config.Config{Jobs: config.JobsConfig{Workers: 2}}
+	s, err := NewServiceWithOptions(cfg, Options{DB: db, GitHubClient: starredGitHub{starred: new([]*github.Repository)}, Logger: log.New(io.Discard, "", 0)})
+	if err != nil {
+		t.Fatalf("NewServiceWithOptions() error = %v", err)
+	}
+	defer s.Close()
+
+	states := make(map[string][]string)
+	jobs, err := s.RefreshAllWithProgress(ctx, func(job *models.Job) {
+		states[job.Target] = append(states[job.Target], job.State)
+	})
+	if err != nil {
+		t.Fatalf("RefreshAllWithProgress() error = %v", err)
+	}
+	if len(jobs) != 3 {
+		t.Fatalf("RefreshAllWithProgress() = %d jobs, want 3", len(jobs))
+	}
+	for _, job := range jobs {
+		if job.State != models.JobStateSucceeded {
+			t.Errorf("job of %s is %s, want succeeded", job.Target, job.State)
+		}
+		seen := states[job.Target]
+		if len(seen) < 2 || seen[0] != models.JobStateQueued || seen[len(seen)-1] != models.JobStateSucceeded {
+			t.Errorf("progress of %s = %v, want queued first and succeeded last", job.Target, seen)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// stale reports whether data fetched at syncedAt is older than ttl. A zero ttl never expires, and
+// nothing is stale offline, where the cached data is all there is.
+func (s *Service) stale(syncedAt time.Time, ttl time.Duration) bool {
+	return !s.config.GitHub.Offline && ttl > 0 && time.Since(syncedAt) > ttl
+}
+
+// refreshStaleRepository re-fetches the metadata of a repository when it is older than the repository TTL.
+// The cached repository is returned if the re-fetch fails.
+func (s *Service) refreshStaleRepository(ctx context.Context, repo *models.Repository) *models.Repository {
+	if repo.Paused || !s.stale(repo.MetadataSyncedAt, s.config.Cache.RepositoryTTL) {
+		return repo
+	}
+
+	ghRepo, err := s.gh(ctx).GetRepository(repo.Owner, repo.Name)
+	if err != nil {
+		s.logger.Printf("Error refreshing stale repository %s: %v", repo.FullName, err)
+		s.recordAPIUsage(ctx, repo.Owner, repo.Name, 1)
+		return repo
+	}
+
+	updated, err := s.updateRepository(ctx, repo.Owner, repo.Name, func(repo *models.Repository) bool {
+		repo.Description = ghRepo.Description
+		repo.URL = ghRepo.URL
+		repo.HTMLURL = ghRepo.HTMLURL
+		repo.IsPrivate = ghRepo.Private
+		repo.UpdatedAt = ghRepo.UpdatedAt
+		repo.MetadataSyncedAt = time.Now()
+		repo.APIUsage.TotalRequests++
+		return true
+	})
+	if err != nil {
+		s.logger.Printf("Error updating repository %s: %v", repo.FullName, err)
+		return repo
+	}
+	return updated
+}
+
+// refreshStalePullRequests re-syncs the pull requests of repositories older than the pull request TTL
+func (s *Service) refreshStalePullRequests(ctx context.Context, repos []*models.Repository) {
+	for _, repo := range repos {
+		if repo.Paused || !repo.SyncConfig.ShouldSyncPullRequests() || !s.stale(repo.PullRequestsSyncedAt, s.config.Cache.PullRequestTTL) {
+			continue
+		}
+
+		requestsBefore := s.usage.Requests(repo.Owner, repo.Name)
+		if err := s.syncPullRequests(ctx, repo.Owner, repo.Name); err != nil {
+			s.logger.Printf("Error refreshing stale pull requests of %s: %v", repo.FullName, err)
+			s.recordAPIUsage(ctx, repo.Owner, repo.Name, s.usage.Requests(repo.Owner, repo.Name)-requestsBefore)
+			continue
+		}
+		syncedAt := time.Now()
+		requests := s.usage.Requests(repo.Owner, repo.Name) - requestsBefore
+		updated, err := s.updateRepository(ctx, repo.Owner, repo.Name, func(repo *models.Repository) bool {
+			repo.PullRequestsSyncedAt = syncedAt
+			repo.APIUsage.TotalRequests += requests
+			return true
+		})
+		if err != nil {
+			s.logger.Printf("Error updating repository %s: %v", repo.FullName, err)
+			continue
+		}
+		*repo = *updated
+	}
+}
+
+// refreshStaleIssues re-syncs the issues of repositories older than the issue TTL
+func (s *Service) refreshStaleIssues(ctx context.Context, repos []*models.Repository) {
+	for _, repo := range repos {
+		if repo.Paused || !repo.SyncConfig.ShouldSyncIssues() || !s.stale(repo.IssuesSyncedAt, s.config.Cache.IssueTTL) {
+			continue
+		}
+
+		requestsBefore := s.usage.Requests(repo.Owner, repo.Name)
+		if err := s.syncIssues(ctx, repo.Owner, repo.Name); err != nil {
+			s.logger.Printf("Error refreshing stale issues of %s: %v", repo.FullName, err)
+			s.recordAPIUsage(ctx, repo.Owner, repo.Name, s.usage.Requests(repo.Owner, repo.Name)-requestsBefore)
+			continue
+		}
+		syncedAt := time.Now()
+		requests := s.usage.Requests(repo.Owner, repo.Name) - requestsBefore
+		updated, err := s.updateRepository(ctx, repo.Owner, repo.Name, func(repo *models.Repository) bool {
+			repo.IssuesSyncedAt = syncedAt
+			repo.APIUsage.TotalRequests += requests
+			return true
+		})
+		if err != nil {
+			s.logger.Printf("Error updating repository %s: %v", repo.FullName, err)
+			continue
+		}
+		*repo = *updated
+	}
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// calendarRequests is the number of requests syncCalendar makes
+const calendarRequests = 2
+
+// calendarReleaseLimit is the number of newest releases kept per repository
+const calendarReleaseLimit = 30
+
+// syncCalendar replaces the stored milestones and releases of a repository with those on GitHub
+func (s *Service) syncCalendar(ctx context.Context, owner, name string) error {
+	fullName := owner + "/" + name
+
+	ghMilestones, err := s.gh(ctx).ListMilestones(owner, name)
+	if err != nil {
+		return err
+	}
+	milestones := make([]*models.Milestone, 0, len(ghMilestones))
+	for _, m := range ghMilestones {
+		milestone := &models.Milestone{
+			Number:       m.Number,
+			Title:        m.Title,
+			Description:  m.Description,
+			State:        m.State,
+			HTMLURL:      m.HTMLURL,
+			OpenIssues:   m.OpenIssues,
+			ClosedIssues: m.ClosedIssues,
+			UpdatedAt:    m.UpdatedAt,
+		}
+		if m.DueOn != nil {
+			milestone.DueOn = *m.DueOn
+		}
+		milestones = append(milestones, milestone)
+	}
+	if err := s.db.ReplaceMilestones(ctx, fullName, milestones); err != nil {
+		return fmt.Errorf("failed to store milestones: %w", err)
+	}
+
+	ghReleases, err := s.gh(ctx).ListReleases(owner, name, calendarReleaseLimit)
+	if err != nil {
+		return err
+	}
+	releases := make([]*models.Release, 0, len(ghReleases))
+	for _, r := range ghReleases {
+		// Drafts have no date yet
+		if r.Draft || r.PublishedAt == nil {
+			continue
+		}
+		releases = append(releases, &models.Release{
+			TagName:     r.TagName,
+			Name:        r.Name,
+			Prerelease:  r.Prerelease,
+			HTMLURL:     r.HTMLURL,
+			PublishedAt: *r.PublishedAt,
+		})
+	}
+	if err := s.db.ReplaceReleases(ctx, fullName, releases); err != nil {
+		return fmt.Errorf("failed to store releases: %w", err)
+	}
+	return nil
+}
+
+// ListCalendarEvents lists the milestone due dates and releases of the tracked repositories
+// matching the filter, oldest first. Milestones without a due date are left out.
+func (s *Service) ListCalendarEvents(ctx context.Context, filter *models.CalendarFilter) ([]*models.CalendarEvent, error) {
+	if filter.Type != "" && filter.Type != models.CalendarEventMilestone && filter.Type != models.CalendarEventRelease {
+		return nil, ErrInvalidCalendarEventType
+	}
+
+	repos, err := s.selectRepositories(ctx, filter.Repo, filter.RepoTag)
+	if err != nil {
+		return nil, err
+	}
+	selected := make(map[string]bool, len(repos))
+	for _, repo := range repos {
+		selected[repo.FullName] = true
+	}
+	// A single repository is listed directly rather than filtered out of all of them
+	repoFullName := ""
+	if filter.Repo != "" && len(repos) == 1 {
+		repoFullName = repos[0].FullName
+	}
+
+	inRange := func(date time.Time) bool {
+		return (filter.Since.IsZero() || !date.Before(filter.Since)) && (filter.Until.IsZero() || date.Before(filter.Until))
+	}
+
+	var events []*models.CalendarEvent
+	if filter.Type != models.CalendarEventRelease {
+		milestones, err := s.db.ListMilestones(ctx, repoFullName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list milestones: %w", err)
+		}
+		for _, m := range milestones {
+			if !selected[m.RepositoryFullName] || m.DueOn.IsZero() || !inRange(m.DueOn) {
+				continue
+			}
+			description := fmt.Sprintf("%d open, %d closed issues", m.OpenIssues, m.ClosedIssues)
+			if m.Description != "" {
+				description = m.Description + "\n\n" + description
+			}
+			events = append(events, &models.CalendarEvent{
+				Type:               models.CalendarEventMilestone,
+				RepositoryFullName: m.RepositoryFullName,
+				ID:                 strconv.Itoa(m.Number),
+				Title:              fmt.Sprintf("%s milestone %s (%s)", m.RepositoryFullName, m.Title, strings.ToLower(m.State)),
+				Description:        description,
+				HTMLURL:            m.HTMLURL,
+				Date:               m.DueOn,
+				UpdatedAt:          m.UpdatedAt,
+			})
+		}
+	}
+	if filter.Type != models.CalendarEventMilestone {
+		releases, err := s.db.ListReleases(ctx, repoFullName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list releases: %w", err)
+		}
+		for _, r := range releases {
+			if !selected[r.RepositoryFullName] || !inRange(r.PublishedAt) {
+				continue
+			}
+			title := r.Name
+			if title == "" {
+				title = r.TagName
+			}
+			kind := "release"
+			if r.Prerelease {
+				kind = "pre-release"
+			}
+			events = append(events, &models.CalendarEvent{
+				Type:               models.CalendarEventRelease,
+				RepositoryFullName: r.RepositoryFullName,
+				ID:                 r.TagName,
+				Title:              fmt.Sprintf("%s %s %s", r.RepositoryFullName, kind, title),
+				HTMLURL:            r.HTMLURL,
+				Date:               r.PublishedAt,
+				UpdatedAt:          r.PublishedAt,
+			})
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Date.Before(events[j].Date)
+	})
+	return events, nil
+}
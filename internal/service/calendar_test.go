@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/db/file"
+	"github.com/siddontang/github-repos-management/internal/github"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// calendarGitHub serves milestones and releases; other calls are not expected
+type calendarGitHub struct {
+	github.ClientInterface
+}
+
+func (calendarGitHub) ListMilestones(owner, name string) ([]*github.Milestone, error) {
+	due := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	return []*github.Milestone{
+		{Number: 1, Title: "v1.0", State: "open", OpenIssues: 3, ClosedIssues: 5, DueOn: &due},
+		{Number: 2, Title: "Backlog", State: "open"},
+	}, nil
+}
+
+func (calendarGitHub) ListReleases(owner, name string, limit int) ([]*github.Release, error) {
+	published := time.Date(2024, 2, 1, 12, 0, 0, 0, time.UTC)
+	return []*github.Release{
+		{TagName: "v0.9", Name: "", Prerelease: true, PublishedAt: &published},
+		{TagName: "v1.0", Name: "First release", Draft: true},
+	}, nil
+}
+
+func TestCalendar(t *testing.T) {
+	ctx := context.Background()
+	db, err := file.NewDB("")
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	if err := db.AddRepository(ctx, &models.Repository{Owner: "org", Name: "repo", FullName: "org/repo"}); err != nil {
+		t.Fatalf("AddRepository() error = %v", err)
+	}
+	s := &Service{db: db, ghClient: calendarGitHub{}, logger: log.New(io.Discard, "", 0)}
+
+	if err := s.syncCalendar(ctx, "org", "repo"); err != nil {
+		t.Fatalf("syncCalendar() error = %v", err)
+	}
+
+	events, err := s.ListCalendarEvents(ctx, &models.CalendarFilter{})
+	if err != nil {
+		t.Fatalf("ListCalendarEvents() error = %v", err)
+	}
+	// The milestone without a due date and the draft release have no date to show
+	if len(events) != 2 {
+		t.Fatalf("ListCalendarEvents() = %d events, want 2", len(events))
+	}
+	if e := events[0]; e.Type != models.CalendarEventRelease || e.Title != "org/repo pre-release v0.9" {
+		t.Errorf("first event = %+v, want the v0.9 pre-release", e)
+	}
+	if e := events[1]; e.Type != models.CalendarEventMilestone || e.ID != "1" || e.Description != "3 open, 5 closed issues" {
+		t.Errorf("second event = %+v, want milestone 1", e)
+	}
+
+	events, err = s.ListCalendarEvents(ctx, &models.CalendarFilter{Repo: "org/repo", Since: time.Date(2024, 2, 15, 0, 0, 0, 0, time.UTC)})
+	if err != nil || len(events) != 1 || events[0].Type != models.CalendarEventMilestone {
+		t.Errorf("ListCalendarEvents() since February 15 = %v, %v, want only the milestone", events, err)
+	}
+	if _, err := s.ListCalendarEvents(ctx, &models.CalendarFilter{Type: "deadline"}); err != ErrInvalidCalendarEventType {
+		t.Errorf("ListCalendarEvents() of an unknown type error = %v, want ErrInvalidCalendarEventType", err)
+	}
+}
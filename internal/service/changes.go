@@ -0,0 +1,59 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/siddontang/github-repos-management/internal/codeowners"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// syncsPullRequestFiles reports whether the paths changed by pull requests are synced
+func (s *Service) syncsPullRequestFiles() bool {
+	return s.config.GitHub.SyncPullRequestFiles || s.config.CodeOwners.Enabled
+}
+
+// filterPullRequestChanges keeps the pull requests changing a path matching a CODEOWNERS-style
+// pattern and of a size; empty values keep all
+func (s *Service) filterPullRequestChanges(prs []*models.PullRequest, path, size string) ([]*models.PullRequest, error) {
+	if path == "" && size == "" {
+		return prs, nil
+	}
+	if path != "" && !s.syncsPullRequestFiles() {
+		return nil, ErrFilesNotConfigured
+	}
+	var pattern *regexp.Regexp
+	if path != "" {
+		var err error
+		if pattern, err = codeowners.CompilePattern(path); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPathPattern, err)
+		}
+	}
+	size = strings.ToUpper(size)
+	if size != "" && !models.ValidSize(size) {
+		return nil, ErrInvalidSize
+	}
+
+	filtered := make([]*models.PullRequest, 0, len(prs))
+	for _, pr := range prs {
+		if size != "" && pr.Size() != size {
+			continue
+		}
+		if pattern != nil && !changesPath(pr, pattern) {
+			continue
+		}
+		filtered = append(filtered, pr)
+	}
+	return filtered, nil
+}
+
+// changesPath reports whether a pull request changes a path matching a pattern
+func changesPath(pr *models.PullRequest, pattern *regexp.Regexp) bool {
+	for _, file := range pr.Files {
+		if pattern.MatchString(file) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+func TestFilterPullRequestChanges(t *testing.T) {
+	s := &Service{config: &config.Config{GitHub: config.GitHubConfig{SyncPullRequestFiles: true}}}
+	prs := []*models.PullRequest{
+		{Number: 1, Additions: 3, Deletions: 2, Files: []string{"docs/guide/intro.md"}},
+		{Number: 2, Additions: 700, Deletions: 100, Files: []string{"cmd/main.go", "docs/README.md"}},
+		{Number: 3, Additions: 40, Files: []string{"pkg/docs/gen.go"}},
+	}
+
+	numbers := func(path, size string) []int {
+		t.Helper()
+		filtered, err := s.filterPullRequestChanges(prs, path, size)
+		if err != nil {
+			t.Fatalf("filterPullRequestChanges(%q, %q) error = %v", path, size, err)
+		}
+		var got []int
+		for _, pr := range filtered {
+			got = append(got, pr.Number)
+		}
+		return got
+	}
+
+	if got, want := numbers("docs/**", ""), []int{1, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("path docs/** = %v, want %v", got, want)
+	}
+	if got, want := numbers("*.go", ""), []int{2, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("path *.go = %v, want %v", got, want)
+	}
+	if got, want := numbers("", "xl"), []int{2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("size xl = %v, want %v", got, want)
+	}
+	if got, want := numbers("docs/**", "XS"), []int{1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("path docs/** and size XS = %v, want %v", got, want)
+	}
+
+	if _, err := s.filterPullRequestChanges(prs, "", "huge"); !errors.Is(err, ErrInvalidSize) {
+		t.Errorf("size huge error = %v, want ErrInvalidSize", err)
+	}
+	s.config.GitHub.SyncPullRequestFiles = false
+	if _, err := s.filterPullRequestChanges(prs, "docs/**", ""); !errors.Is(err, ErrFilesNotConfigured) {
+		t.Errorf("path without synced files error = %v, want ErrFilesNotConfigured", err)
+	}
+}
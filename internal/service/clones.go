@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/siddontang/github-repos-management/internal/localgit"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// SetLocalClone associates a tracked repository with its local clone at path, or removes the
+// association when path is empty. The clone must have a remote pointing to the repository.
+func (s *Service) SetLocalClone(ctx context.Context, owner, name, path string) (*models.Repository, error) {
+	repo, err := s.db.GetRepository(ctx, owner, name)
+	if err != nil {
+		return nil, notFound(err, ErrRepositoryNotFound)
+	}
+	if err := s.checkWorkspace(ctx, repo.FullName); err != nil {
+		return nil, err
+	}
+	if path != "" {
+		if path, err = filepath.Abs(path); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidClone, err)
+		}
+		if _, err := localgit.FindRemote(path, repo.FullName, localgit.Hosts()); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidClone, err)
+		}
+	}
+
+	updated, err := s.updateRepository(ctx, owner, name, func(repo *models.Repository) bool {
+		if repo.LocalPath == path {
+			return false
+		}
+		repo.LocalPath = path
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	details := path
+	if details == "" {
+		details = "removed"
+	}
+	s.audit(ctx, models.AuditRepositoryClone, updated.FullName, details)
+	return updated, nil
+}
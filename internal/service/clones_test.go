@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/db/file"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+func TestSetLocalClone(t *testing.T) {
+	ctx := context.Background()
+	t.Setenv("GH_CONFIG_DIR", t.TempDir())
+	db, err := file.NewDB("")
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	if err := db.AddRepository(ctx, &models.Repository{Owner: "org", Name: "api", FullName: "org/api"}); err != nil {
+		t.Fatalf("AddRepository() error = %v", err)
+	}
+	s := &Service{db: db, config: &config.Config{}, logger: log.New(io.Discard, "", 0)}
+
+	root := t.TempDir()
+	for name, url := range map[string]string{"api": "git@github.com:org/api.git", "web": "https://github.com/org/web"} {
+		dir := filepath.Join(root, name, ".git")
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "config"), []byte("[remote \"origin\"]\n\turl = "+url+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	repo, err := s.SetLocalClone(ctx, "org", "api", filepath.Join(root, "api"))
+	if err != nil {
+		t.Fatalf("SetLocalClone() error = %v", err)
+	}
+	if repo.LocalPath != filepath.Join(root, "api") {
+		t.Errorf("LocalPath = %q, want the clone", repo.LocalPath)
+	}
+	if _, err := s.SetLocalClone(ctx, "org", "api", filepath.Join(root, "web")); !errors.Is(err, ErrInvalidClone) {
+		t.Errorf("SetLocalClone(clone of another repository) error = %v, want ErrInvalidClone", err)
+	}
+	if _, err := s.SetLocalClone(ctx, "org", "api", filepath.Join(root, "missing")); !errors.Is(err, ErrInvalidClone) {
+		t.Errorf("SetLocalClone(missing directory) error = %v, want ErrInvalidClone", err)
+	}
+	if _, err := s.SetLocalClone(ctx, "org", "web", filepath.Join(root, "web")); !errors.Is(err, ErrRepositoryNotFound) {
+		t.Errorf("SetLocalClone(untracked) error = %v, want ErrRepositoryNotFound", err)
+	}
+
+	if repo, err = s.SetLocalClone(ctx, "org", "api", ""); err != nil {
+		t.Fatalf("SetLocalClone(\"\") error = %v", err)
+	}
+	if repo.LocalPath != "" {
+		t.Errorf("LocalPath = %q after removing the association, want none", repo.LocalPath)
+	}
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/siddontang/github-repos-management/internal/codeowners"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// fetchCodeOwners fetches and parses the CODEOWNERS file of a repository; a repository without
+// one has no rules
+func (s *Service) fetchCodeOwners(ctx context.Context, owner, name string) ([]models.CodeOwnersRule, error) {
+	content, err := s.gh(ctx).GetCodeOwners(owner, name)
+	if err != nil {
+		return nil, err
+	}
+	return codeowners.Parse(content), nil
+}
+
+// filterPullRequestOwners keeps the pull requests changing a path owned by the configured user
+// when ownedByMe is set, and by a team when team is set. Pull requests match through their
+// synced changed files and the CODEOWNERS rules of their repository.
+func (s *Service) filterPullRequestOwners(repos []*models.Repository, prs []*models.PullRequest, ownedByMe bool, team string) ([]*models.PullRequest, error) {
+	if !ownedByMe && team == "" {
+		return prs, nil
+	}
+	if !s.config.CodeOwners.Enabled {
+		return nil, ErrCodeOwnersNotConfigured
+	}
+
+	var matches []func(teams, logins []string) bool
+	if ownedByMe {
+		if s.config.CodeOwners.User == "" {
+			return nil, ErrCodeOwnersUserNotSet
+		}
+		matches = append(matches, s.userMatcher(s.config.CodeOwners.User))
+	}
+	if team != "" {
+		matches = append(matches, s.teamMatcher(team))
+	}
+
+	matchers := make(map[string]*codeowners.Matcher, len(repos))
+	for _, repo := range repos {
+		matchers[repo.FullName] = codeowners.Compile(repo.CodeOwners)
+	}
+
+	filtered := make([]*models.PullRequest, 0, len(prs))
+	for _, pr := range prs {
+		matcher := matchers[pr.RepositoryFullName]
+		if matcher == nil {
+			continue
+		}
+		// Every filter must match, each through any of the changed files
+		owned := true
+		for _, match := range matches {
+			found := false
+			for _, path := range pr.Files {
+				if match(splitOwners(matcher.Owners(path))) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				owned = false
+				break
+			}
+		}
+		if owned {
+			filtered = append(filtered, pr)
+		}
+	}
+	return filtered, nil
+}
+
+// userMatcher reports whether owners include a user, directly or through a configured team
+// listing the user as a member
+func (s *Service) userMatcher(login string) func(teams, logins []string) bool {
+	memberOf := make(map[string]bool)
+	for name, members := range s.config.Teams {
+		for _, member := range members {
+			if strings.EqualFold(member, login) {
+				memberOf[strings.ToLower(name)] = true
+			}
+		}
+	}
+
+	return func(teams, logins []string) bool {
+		for _, l := range logins {
+			if strings.EqualFold(l, login) {
+				return true
+			}
+		}
+		for _, t := range teams {
+			if i := strings.LastIndex(t, "/"); i >= 0 {
+				t = t[i+1:]
+			}
+			if memberOf[strings.ToLower(t)] {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// splitOwners separates the org/team owners from the user owners, leaving out email owners
+func splitOwners(owners []string) (teams, logins []string) {
+	for _, owner := range owners {
+		switch {
+		case strings.Contains(owner, "@"):
+		case strings.Contains(owner, "/"):
+			teams = append(teams, owner)
+		default:
+			logins = append(logins, owner)
+		}
+	}
+	return teams, logins
+}
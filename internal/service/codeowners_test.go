@@ -0,0 +1,62 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/siddontang/github-repos-management/internal/codeowners"
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+func TestFilterPullRequestOwners(t *testing.T) {
+	s := &Service{config: &config.Config{
+		CodeOwners: config.CodeOwnersConfig{Enabled: true, User: "alice"},
+		Teams:      map[string][]string{"docs": {"Alice", "bob"}},
+	}}
+	repos := []*models.Repository{{FullName: "org/repo", CodeOwners: codeowners.Parse("* @org/core\n/docs/ @org/docs\n*.go @alice\n")}}
+	prs := []*models.PullRequest{
+		{RepositoryFullName: "org/repo", Number: 1, Files: []string{"main.go"}},
+		{RepositoryFullName: "org/repo", Number: 2, Files: []string{"README.md", "docs/intro.md"}},
+		{RepositoryFullName: "org/repo", Number: 3, Files: []string{"README.md"}},
+		{RepositoryFullName: "org/other", Number: 4, Files: []string{"main.go"}},
+		{RepositoryFullName: "org/repo", Number: 5},
+	}
+
+	numbers := func(ownedByMe bool, team string) []int {
+		t.Helper()
+		filtered, err := s.filterPullRequestOwners(repos, prs, ownedByMe, team)
+		if err != nil {
+			t.Fatalf("filterPullRequestOwners() error = %v", err)
+		}
+		var got []int
+		for _, pr := range filtered {
+			got = append(got, pr.Number)
+		}
+		return got
+	}
+
+	// alice owns Go files directly and docs through the docs team
+	if got, want := numbers(true, ""), []int{1, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("owned by me = %v, want %v", got, want)
+	}
+	if got, want := numbers(false, "core"), []int{2, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("owned by core = %v, want %v", got, want)
+	}
+	if got, want := numbers(true, "core"), []int{2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("owned by me and core = %v, want %v", got, want)
+	}
+	if got := numbers(false, ""); len(got) != len(prs) {
+		t.Errorf("without ownership filters kept %d of %d", len(got), len(prs))
+	}
+
+	s.config.CodeOwners.User = ""
+	if _, err := s.filterPullRequestOwners(repos, prs, true, ""); !errors.Is(err, ErrCodeOwnersUserNotSet) {
+		t.Errorf("owned by me without a user error = %v, want ErrCodeOwnersUserNotSet", err)
+	}
+	s.config.CodeOwners.Enabled = false
+	if _, err := s.filterPullRequestOwners(repos, prs, false, "core"); !errors.Is(err, ErrCodeOwnersNotConfigured) {
+		t.Errorf("owned by team when disabled error = %v, want ErrCodeOwnersNotConfigured", err)
+	}
+}
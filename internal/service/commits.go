@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// Commit sync limits
+const (
+	defaultCommitLookback = 30 * 24 * time.Hour
+	maxCommitsPerSync     = 1000
+)
+
+// commitLookback returns how far back commits are synced and kept
+func (s *Service) commitLookback() time.Duration {
+	if s.config.GitHub.CommitLookback > 0 {
+		return s.config.GitHub.CommitLookback
+	}
+	return defaultCommitLookback
+}
+
+// syncCommits fetches the default branch commits made since the newest stored commit, or within
+// the lookback window when none is stored, and drops those older than the window
+func (s *Service) syncCommits(ctx context.Context, owner, name string) error {
+	fullName := owner + "/" + name
+	stored, err := s.db.ListCommits(ctx, fullName)
+	if err != nil {
+		return err
+	}
+
+	cutoff := time.Now().Add(-s.commitLookback())
+	since := cutoff
+	if len(stored) > 0 && stored[0].CommittedAt.After(since) {
+		since = stored[0].CommittedAt
+	}
+	ghCommits, err := s.gh(ctx).ListCommits(owner, name, since, maxCommitsPerSync)
+	if err != nil {
+		return err
+	}
+
+	// The newest stored commit is fetched again, since the window includes its time
+	seen := make(map[string]bool, len(stored)+len(ghCommits))
+	commits := make([]*models.Commit, 0, len(stored)+len(ghCommits))
+	for _, c := range ghCommits {
+		if seen[c.SHA] {
+			continue
+		}
+		seen[c.SHA] = true
+		author := c.AuthorLogin
+		if author == "" {
+			author = c.AuthorName
+		}
+		subject, _, _ := strings.Cut(c.Message, "\n")
+		commits = append(commits, &models.Commit{
+			SHA:         c.SHA,
+			Author:      author,
+			Subject:     strings.TrimSpace(subject),
+			HTMLURL:     c.HTMLURL,
+			CommittedAt: c.CommittedAt,
+		})
+	}
+	for _, commit := range stored {
+		if !seen[commit.SHA] && !commit.CommittedAt.Before(cutoff) {
+			seen[commit.SHA] = true
+			commits = append(commits, commit)
+		}
+	}
+	return s.db.ReplaceCommits(ctx, fullName, commits)
+}
+
+// ListCommits lists the synced default branch commits of a repository matching the filter, newest first
+func (s *Service) ListCommits(ctx context.Context, owner, name string, filter *models.CommitFilter) ([]*models.Commit, *models.Pagination, error) {
+	repo, err := s.GetRepository(ctx, owner, name)
+	if err != nil {
+		return nil, nil, err
+	}
+	all, err := s.db.ListCommits(ctx, repo.FullName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list commits: %w", err)
+	}
+
+	commits := make([]*models.Commit, 0, len(all))
+	for _, commit := range all {
+		if filter.Author != "" && !strings.EqualFold(commit.Author, filter.Author) {
+			continue
+		}
+		if !filter.Since.IsZero() && commit.CommittedAt.Before(filter.Since) {
+			continue
+		}
+		if !filter.Until.IsZero() && !commit.CommittedAt.Before(filter.Until) {
+			continue
+		}
+		commits = append(commits, commit)
+	}
+
+	total := len(commits)
+	pagination := &models.Pagination{
+		Page:       filter.Page,
+		PerPage:    filter.PerPage,
+		Total:      total,
+		TotalPages: (total + filter.PerPage - 1) / filter.PerPage,
+	}
+	start := (filter.Page - 1) * filter.PerPage
+	if start >= total {
+		return []*models.Commit{}, pagination, nil
+	}
+	end := start + filter.PerPage
+	if end > total {
+		end = total
+	}
+	return commits[start:end], pagination, nil
+}
+
+// listSelectedCommits lists the synced commits of the given repositories for activity metrics.
+// Bot commits are left out with excludeBots, and every commit with an association filter, since
+// commits carry no author association.
+func (s *Service) listSelectedCommits(ctx context.Context, repos []*models.Repository, excludeBots bool, associations string) []*models.Commit {
+	if !s.config.GitHub.SyncCommits || associations != "" {
+		return nil
+	}
+	var commits []*models.Commit
+	for _, repo := range repos {
+		repoCommits, err := s.db.ListCommits(ctx, repo.FullName)
+		if err != nil {
+			continue
+		}
+		for _, commit := range repoCommits {
+			if excludeBots && strings.HasSuffix(commit.Author, "[bot]") {
+				continue
+			}
+			commits = append(commits, commit)
+		}
+	}
+	return commits
+}
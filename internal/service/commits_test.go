@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/db/file"
+	"github.com/siddontang/github-repos-management/internal/github"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// commitsGitHub serves the commits made since the requested time, newest first
+type commitsGitHub struct {
+	github.ClientInterface
+	commits []*github.Commit
+	since   *time.Time
+}
+
+func (g commitsGitHub) ListCommits(owner, name string, since time.Time, limit int) ([]*github.Commit, error) {
+	*g.since = since
+	var commits []*github.Commit
+	for _, c := range g.commits {
+		if !c.CommittedAt.Before(since) {
+			commits = append(commits, c)
+		}
+	}
+	return commits, nil
+}
+
+func TestSyncCommits(t *testing.T) {
+	ctx := context.Background()
+	db, err := file.NewDB("")
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	if err := db.AddRepository(ctx, &models.Repository{Owner: "org", Name: "repo", FullName: "org/repo"}); err != nil {
+		t.Fatalf("AddRepository() error = %v", err)
+	}
+
+	now := time.Now()
+	gh := commitsGitHub{since: new(time.Time), commits: []*github.Commit{
+		{SHA: "b", AuthorLogin: "Alice", Message: "Fix sync\n\nDetails", CommittedAt: now.Add(-time.Hour)},
+		{SHA: "a", AuthorName: "Bob Local", Message: "Initial commit", CommittedAt: now.Add(-48 * time.Hour)},
+	}}
+	cfg := &config.Config{GitHub: config.GitHubConfig{SyncCommits: true, CommitLookback: 7 * 24 * time.Hour}}
+	s := &Service{db: db, ghClient: gh, config: cfg, logger: log.New(io.Discard, "", 0)}
+
+	if err := s.syncCommits(ctx, "org", "repo"); err != nil {
+		t.Fatalf("syncCommits() error = %v", err)
+	}
+	if want := now.Add(-cfg.GitHub.CommitLookback); gh.since.Sub(want).Abs() > time.Minute {
+		t.Errorf("first sync fetched since %v, want the lookback window", *gh.since)
+	}
+
+	// Later syncs fetch from the newest stored commit and drop those out of the window
+	gh.commits = append([]*github.Commit{{SHA: "c", AuthorLogin: "alice", Message: "Add commits", CommittedAt: now}}, gh.commits...)
+	cfg.GitHub.CommitLookback = 24 * time.Hour
+	s.ghClient = gh
+	if err := s.syncCommits(ctx, "org", "repo"); err != nil {
+		t.Fatalf("syncCommits() error = %v", err)
+	}
+	if !gh.since.Equal(now.Add(-time.Hour)) {
+		t.Errorf("second sync fetched since %v, want the newest stored commit", *gh.since)
+	}
+
+	commits, pagination, err := s.ListCommits(ctx, "org", "repo", &models.CommitFilter{Page: 1, PerPage: 10})
+	if err != nil {
+		t.Fatalf("ListCommits() error = %v", err)
+	}
+	if pagination.Total != 2 || commits[0].SHA != "c" || commits[1].SHA != "b" || commits[1].Subject != "Fix sync" {
+		t.Fatalf("ListCommits() = %+v, want c and b within the window, newest first", commits)
+	}
+
+	commits, _, _ = s.ListCommits(ctx, "org", "repo", &models.CommitFilter{Author: "ALICE", Since: now.Add(-30 * time.Minute), Page: 1, PerPage: 10})
+	if len(commits) != 1 || commits[0].SHA != "c" {
+		t.Errorf("ListCommits(author, since) = %+v, want c", commits)
+	}
+
+	contributions, _, err := s.GetLeaderboard(ctx, &models.LeaderboardFilter{Page: 1, PerPage: 10})
+	if err != nil {
+		t.Fatalf("GetLeaderboard() error = %v", err)
+	}
+	if len(contributions) != 1 || contributions[0].User != "alice" || contributions[0].Commits != 2 {
+		t.Errorf("GetLeaderboard() = %+v, want alice with 2 commits", contributions)
+	}
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// defaultSnapshotInterval is how often repository settings are captured when the configuration doesn't say
+const defaultSnapshotInterval = 24 * time.Hour
+
+// settingsDue reports whether the settings of a repository should be captured by its sync
+func (s *Service) settingsDue(repo *models.Repository) bool {
+	if !s.config.Compliance.Enabled {
+		return false
+	}
+	if repo.Settings == nil {
+		return true
+	}
+	interval := s.config.Compliance.SnapshotInterval
+	if interval <= 0 {
+		interval = defaultSnapshotInterval
+	}
+	return time.Since(repo.Settings.CapturedAt) >= interval
+}
+
+// captureSettings fetches the merge settings and default branch protection of a repository
+func (s *Service) captureSettings(ctx context.Context, owner, name string) (*models.RepositorySettings, error) {
+	ghSettings, err := s.gh(ctx).GetRepositorySettings(owner, name)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := &models.RepositorySettings{
+		DefaultBranch:        ghSettings.DefaultBranch,
+		MergeMethods:         []string{},
+		DeleteBranchOnMerge:  ghSettings.DeleteBranchOnMerge,
+		Protected:            ghSettings.Protected,
+		RequiredApprovals:    ghSettings.RequiredApprovals,
+		RequiredStatusChecks: ghSettings.RequiredStatusChecks,
+		EnforceAdmins:        ghSettings.EnforceAdmins,
+		AllowForcePushes:     ghSettings.AllowForcePushes,
+		RequireLinearHistory: ghSettings.RequireLinearHistory,
+		CapturedAt:           time.Now(),
+	}
+	if ghSettings.AllowMergeCommit {
+		settings.MergeMethods = append(settings.MergeMethods, models.MergeMethodMerge)
+	}
+	if ghSettings.AllowSquashMerge {
+		settings.MergeMethods = append(settings.MergeMethods, models.MergeMethodSquash)
+	}
+	if ghSettings.AllowRebaseMerge {
+		settings.MergeMethods = append(settings.MergeMethods, models.MergeMethodRebase)
+	}
+	return settings, nil
+}
+
+// ComplianceReport checks the captured settings of the tracked repositories matching the filter
+// against the configured policy, ordered by repository. Repositories whose settings were never
+// captured are reported as violating it.
+func (s *Service) ComplianceReport(ctx context.Context, filter *models.ComplianceFilter) ([]*models.ComplianceResult, error) {
+	if !s.config.Compliance.Enabled {
+		return nil, ErrComplianceNotConfigured
+	}
+
+	repos, err := s.selectRepositories(ctx, filter.Repo, filter.RepoTag)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*models.ComplianceResult, 0, len(repos))
+	for _, repo := range repos {
+		violations := checkCompliance(s.config.Compliance.Policy, repo.Settings)
+		if filter.ViolationsOnly && len(violations) == 0 {
+			continue
+		}
+		results = append(results, &models.ComplianceResult{
+			Repository: repo.FullName,
+			Compliant:  len(violations) == 0,
+			Violations: violations,
+			Settings:   repo.Settings,
+		})
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Repository < results[j].Repository })
+	return results, nil
+}
+
+// checkCompliance lists how repository settings deviate from a policy
+func checkCompliance(policy config.CompliancePolicy, settings *models.RepositorySettings) []string {
+	violations := make([]string, 0)
+	if settings == nil {
+		return append(violations, "settings not captured yet")
+	}
+
+	if policy.DefaultBranch != "" && settings.DefaultBranch != policy.DefaultBranch {
+		violations = append(violations, fmt.Sprintf("default branch is %q, expected %q", settings.DefaultBranch, policy.DefaultBranch))
+	}
+	if policy.RequireProtection != nil && settings.Protected != *policy.RequireProtection {
+		violations = append(violations, expectation("default branch protection", settings.Protected))
+	}
+	if policy.MinApprovals > 0 && settings.RequiredApprovals < policy.MinApprovals {
+		violations = append(violations, fmt.Sprintf("requires %d approvals, expected at least %d", settings.RequiredApprovals, policy.MinApprovals))
+	}
+	if policy.RequireStatusChecks != nil && (settings.RequiredStatusChecks != nil) != *policy.RequireStatusChecks {
+		violations = append(violations, expectation("required status checks", settings.RequiredStatusChecks != nil))
+	}
+	if policy.EnforceAdmins != nil && settings.EnforceAdmins != *policy.EnforceAdmins {
+		violations = append(violations, expectation("enforcement for admins", settings.EnforceAdmins))
+	}
+	if policy.AllowForcePushes != nil && settings.AllowForcePushes != *policy.AllowForcePushes {
+		violations = append(violations, expectation("force pushes", settings.AllowForcePushes))
+	}
+	if policy.RequireLinearHistory != nil && settings.RequireLinearHistory != *policy.RequireLinearHistory {
+		violations = append(violations, expectation("required linear history", settings.RequireLinearHistory))
+	}
+	if policy.DeleteBranchOnMerge != nil && settings.DeleteBranchOnMerge != *policy.DeleteBranchOnMerge {
+		violations = append(violations, expectation("branch deletion on merge", settings.DeleteBranchOnMerge))
+	}
+	if len(policy.AllowedMergeMethods) > 0 {
+		var disallowed []string
+		for _, method := range settings.MergeMethods {
+			if !slices.Contains(policy.AllowedMergeMethods, method) {
+				disallowed = append(disallowed, method)
+			}
+		}
+		if len(disallowed) > 0 {
+			violations = append(violations, fmt.Sprintf("merge methods %s are allowed, expected only %s",
+				strings.Join(disallowed, ", "), strings.Join(policy.AllowedMergeMethods, ", ")))
+		}
+	}
+	return violations
+}
+
+// expectation describes a setting that is on when it should be off, or the other way around
+func expectation(setting string, enabled bool) string {
+	if enabled {
+		return setting + " is enabled, expected disabled"
+	}
+	return setting + " is disabled, expected enabled"
+}
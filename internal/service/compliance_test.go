@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/db/file"
+	"github.com/siddontang/github-repos-management/internal/github"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// settingsGitHub serves a protected main branch for org/good and an unprotected master branch otherwise
+type settingsGitHub struct {
+	github.ClientInterface
+}
+
+func (settingsGitHub) GetRepositorySettings(owner, name string) (*github.RepositorySettings, error) {
+	if name == "good" {
+		return &github.RepositorySettings{DefaultBranch: "main", AllowSquashMerge: true, Protected: true, RequiredApprovals: 2, RequiredStatusChecks: []string{"ci"}}, nil
+	}
+	return &github.RepositorySettings{DefaultBranch: "master", AllowMergeCommit: true, AllowSquashMerge: true}, nil
+}
+
+func TestComplianceReport(t *testing.T) {
+	ctx := context.Background()
+	db, err := file.NewDB("")
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	for _, name := range []string{"good", "bad", "new"} {
+		if err := db.AddRepository(ctx, &models.Repository{Owner: "org", Name: name, FullName: "org/" + name}); err != nil {
+			t.Fatalf("AddRepository() error = %v", err)
+		}
+	}
+	yes := true
+	cfg := &config.Config{}
+	s := &Service{db: db, ghClient: settingsGitHub{}, config: cfg, logger: log.New(io.Discard, "", 0)}
+
+	if _, err := s.ComplianceReport(ctx, &models.ComplianceFilter{}); !errors.Is(err, ErrComplianceNotConfigured) {
+		t.Fatalf("ComplianceReport() error = %v, want ErrComplianceNotConfigured", err)
+	}
+
+	cfg.Compliance = config.ComplianceConfig{Enabled: true, Policy: config.CompliancePolicy{
+		DefaultBranch:       "main",
+		RequireProtection:   &yes,
+		MinApprovals:        1,
+		RequireStatusChecks: &yes,
+		AllowedMergeMethods: []string{models.MergeMethodSquash},
+	}}
+	for _, name := range []string{"good", "bad"} {
+		repo, _ := db.GetRepository(ctx, "org", name)
+		if !s.settingsDue(repo) {
+			t.Fatalf("settingsDue(%s) = false, want settings never captured to be due", name)
+		}
+		settings, err := s.captureSettings(context.Background(), "org", name)
+		if err != nil {
+			t.Fatalf("captureSettings() error = %v", err)
+		}
+		repo.Settings = settings
+		if err := db.UpdateRepository(ctx, repo); err != nil {
+			t.Fatalf("UpdateRepository() error = %v", err)
+		}
+		if s.settingsDue(repo) {
+			t.Errorf("settingsDue(%s) = true right after capturing", name)
+		}
+	}
+
+	results, err := s.ComplianceReport(ctx, &models.ComplianceFilter{})
+	if err != nil {
+		t.Fatalf("ComplianceReport() error = %v", err)
+	}
+	if len(results) != 3 || results[0].Repository != "org/bad" || results[1].Repository != "org/good" || results[2].Repository != "org/new" {
+		t.Fatalf("ComplianceReport() = %+v, want the 3 repositories in order", results)
+	}
+	if !results[1].Compliant || len(results[1].Violations) != 0 {
+		t.Errorf("org/good = %+v, want compliant", results[1])
+	}
+	violations := strings.Join(results[0].Violations, "\n")
+	for _, want := range []string{`default branch is "master"`, "protection is disabled", "requires 0 approvals", "status checks is disabled", "merge methods merge are allowed"} {
+		if !strings.Contains(violations, want) {
+			t.Errorf("org/bad violations = %q, want %q", results[0].Violations, want)
+		}
+	}
+	if results[2].Compliant || results[2].Settings != nil {
+		t.Errorf("org/new = %+v, want violating without captured settings", results[2])
+	}
+
+	results, _ = s.ComplianceReport(ctx, &models.ComplianceFilter{ViolationsOnly: true})
+	if len(results) != 2 {
+		t.Errorf("ComplianceReport(violations only) = %d results, want 2", len(results))
+	}
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// cursorKey is the position of an item in a sorted listing
+type cursorKey struct {
+	CreatedAt time.Time `json:"t,omitempty"`
+	Repo      string    `json:"r"`
+	Number    int       `json:"n,omitempty"`
+	User      string    `json:"u,omitempty"`
+	Total     int       `json:"c,omitempty"`
+}
+
+// cursor points before or after an item. It stays valid when items are added or
+// removed, unlike a page number.
+type cursor struct {
+	Prev bool      `json:"p,omitempty"`
+	Key  cursorKey `json:"k"`
+}
+
+// encode returns the opaque form of the cursor
+func (c cursor) encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor parses an opaque cursor
+func decodeCursor(value string) (cursor, error) {
+	var c cursor
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return c, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return c, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// itemLess orders pull requests and issues by creation time, breaking ties by
+// repository and number so that listings are stable
+func itemLess(direction string) func(a, b cursorKey) bool {
+	return func(a, b cursorKey) bool {
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			if direction == "asc" {
+				return a.CreatedAt.Before(b.CreatedAt)
+			}
+			return a.CreatedAt.After(b.CreatedAt)
+		}
+		if a.Repo != b.Repo {
+			return a.Repo < b.Repo
+		}
+		return a.Number < b.Number
+	}
+}
+
+// repositoryLess orders repositories by full name
+func repositoryLess(a, b cursorKey) bool {
+	return a.Repo < b.Repo
+}
+
+// contributionLess orders leaderboard contributions by total, most first, breaking ties by user
+func contributionLess(a, b cursorKey) bool {
+	if a.Total != b.Total {
+		return a.Total > b.Total
+	}
+	return a.User < b.User
+}
+
+// window selects the items of a sorted listing of n items to return. Without a cursor it
+// returns the requested page; with one it returns up to perPage items after or before it.
+// The returned pagination carries the cursors of the neighbouring windows.
+func window(n int, key func(i int) cursorKey, less func(a, b cursorKey) bool, page, perPage int, rawCursor string) (int, int, *models.Pagination, error) {
+	var start, end int
+	if rawCursor == "" {
+		start = (page - 1) * perPage
+		if start > n {
+			start = n
+		}
+		end = start + perPage
+	} else {
+		c, err := decodeCursor(rawCursor)
+		if err != nil {
+			return 0, 0, nil, err
+		}
+		if c.Prev {
+			end = sort.Search(n, func(i int) bool { return !less(key(i), c.Key) })
+			start = end - perPage
+			if start < 0 {
+				start = 0
+			}
+		} else {
+			start = sort.Search(n, func(i int) bool { return less(c.Key, key(i)) })
+			end = start + perPage
+		}
+		page = start/perPage + 1
+	}
+	if end > n {
+		end = n
+	}
+
+	pagination := &models.Pagination{
+		Page:       page,
+		PerPage:    perPage,
+		Total:      n,
+		TotalPages: (n + perPage - 1) / perPage,
+	}
+	if end < n && end > 0 {
+		pagination.NextCursor = cursor{Key: key(end - 1)}.encode()
+	}
+	if start > 0 && start < n {
+		pagination.PrevCursor = cursor{Prev: true, Key: key(start)}.encode()
+	}
+
+	return start, end, pagination, nil
+}
+
+// pullRequestKey returns the cursor key of a pull request
+func pullRequestKey(pr *models.PullRequest) cursorKey {
+	return cursorKey{CreatedAt: pr.CreatedAt, Repo: pr.RepositoryFullName, Number: pr.Number}
+}
+
+// issueKey returns the cursor key of an issue
+func issueKey(issue *models.Issue) cursorKey {
+	return cursorKey{CreatedAt: issue.CreatedAt, Repo: issue.RepositoryFullName, Number: issue.Number}
+}
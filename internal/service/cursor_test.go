@@ -0,0 +1,40 @@
+package service
+
+import (
+	"testing"
+)
+
+// TestWindow tests offset and cursor pagination over a sorted listing
+func TestWindow(t *testing.T) {
+	names := []string{"a/a", "a/b", "a/c", "a/d", "a/e"}
+	key := func(i int) cursorKey { return cursorKey{Repo: names[i]} }
+
+	start, end, p, err := window(len(names), key, repositoryLess, 1, 2, "")
+	if err != nil || start != 0 || end != 2 {
+		t.Fatalf("window() page 1 = %d, %d, %v, want 0, 2", start, end, err)
+	}
+	if p.NextCursor == "" || p.PrevCursor != "" {
+		t.Fatalf("window() page 1 cursors = %q, %q, want only next", p.NextCursor, p.PrevCursor)
+	}
+
+	// An item inserted before the cursor does not shift the next window
+	names = []string{"a/0", "a/a", "a/b", "a/c", "a/d", "a/e"}
+	start, end, p, err = window(len(names), key, repositoryLess, 1, 2, p.NextCursor)
+	if err != nil || names[start] != "a/c" || end-start != 2 {
+		t.Fatalf("window() next = %d, %d, %v, want a/c and a/d", start, end, err)
+	}
+
+	start, end, _, err = window(len(names), key, repositoryLess, 1, 2, p.PrevCursor)
+	if err != nil || names[start] != "a/a" || names[end-1] != "a/b" {
+		t.Fatalf("window() prev = %d, %d, %v, want a/a and a/b", start, end, err)
+	}
+
+	if _, _, _, err := window(len(names), key, repositoryLess, 1, 2, "not a cursor"); err != ErrInvalidCursor {
+		t.Errorf("window() invalid cursor error = %v, want %v", err, ErrInvalidCursor)
+	}
+
+	start, end, _, err = window(len(names), key, repositoryLess, 10, 2, "")
+	if err != nil || start != end {
+		t.Errorf("window() past the end = %d, %d, %v, want empty", start, end, err)
+	}
+}
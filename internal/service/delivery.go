@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/db"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// Webhook and subscription deliveries run in the background so that a slow or unreachable
+// endpoint never holds up a sync. Their delivery logs are written in batches.
+const (
+	deliveryQueueSize     = 1000
+	deliveryWorkers       = 4
+	deliveryFlushInterval = time.Second
+	deliveryMaxBatch      = 100
+)
+
+// deliveryQueue runs deliveries on a pool of workers and records their logs
+type deliveryQueue struct {
+	db     db.DB
+	logger *log.Logger
+
+	// ctx is canceled when closing gives up waiting, aborting the deliveries in progress
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu      sync.RWMutex
+	closed  bool
+	tasks   chan func(ctx context.Context)
+	workers sync.WaitGroup
+
+	logs       chan *models.WebhookDelivery
+	logsClosed bool          // Guarded by mu
+	recorded   chan struct{} // Closed once the last logs are written
+}
+
+// newDeliveryQueue starts the workers and the log writer of a delivery queue
+func newDeliveryQueue(store db.DB, logger *log.Logger) *deliveryQueue {
+	ctx, cancel := context.WithCancel(context.Background())
+	q := &deliveryQueue{
+		db:       store,
+		logger:   logger,
+		ctx:      ctx,
+		cancel:   cancel,
+		tasks:    make(chan func(ctx context.Context), deliveryQueueSize),
+		logs:     make(chan *models.WebhookDelivery, deliveryQueueSize),
+		recorded: make(chan struct{}),
+	}
+	for i := 0; i < deliveryWorkers; i++ {
+		q.workers.Add(1)
+		go q.work()
+	}
+	go q.writeLogs()
+	return q
+}
+
+// enqueue queues a delivery, reporting false when the queue is full or closed
+func (q *deliveryQueue) enqueue(deliver func(ctx context.Context)) bool {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.closed {
+		return false
+	}
+	select {
+	case q.tasks <- deliver:
+		return true
+	default:
+		return false
+	}
+}
+
+// addLog queues a delivery log to be written with the next batch, dropping it once the queue is closed
+func (q *deliveryQueue) addLog(delivery *models.WebhookDelivery) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if !q.logsClosed {
+		q.logs <- delivery
+	}
+}
+
+// work runs queued deliveries until the queue is closed
+func (q *deliveryQueue) work() {
+	defer q.workers.Done()
+	for deliver := range q.tasks {
+		deliver(q.ctx)
+	}
+}
+
+// writeLogs writes the delivery logs every deliveryFlushInterval, or sooner when deliveryMaxBatch
+// are pending, until the logs channel is closed
+func (q *deliveryQueue) writeLogs() {
+	defer close(q.recorded)
+	ticker := time.NewTicker(deliveryFlushInterval)
+	defer ticker.Stop()
+
+	var pending []*models.WebhookDelivery
+	flush := func() {
+		if len(pending) == 0 {
+			return
+		}
+		if err := q.db.AddWebhookDeliveries(context.Background(), pending); err != nil {
+			q.logger.Printf("Error recording %d webhook deliveries: %v", len(pending), err)
+		}
+		pending = nil
+	}
+	for {
+		select {
+		case delivery, ok := <-q.logs:
+			if !ok {
+				flush()
+				return
+			}
+			pending = append(pending, delivery)
+			if len(pending) >= deliveryMaxBatch {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// Close stops accepting deliveries and waits for the queued ones until ctx is done, when those
+// in progress are aborted, then writes the remaining logs
+func (q *deliveryQueue) Close(ctx context.Context) {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return
+	}
+	q.closed = true
+	close(q.tasks)
+	q.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		q.workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		q.cancel()
+		<-done
+	}
+	q.cancel()
+
+	q.mu.Lock()
+	q.logsClosed = true
+	close(q.logs)
+	q.mu.Unlock()
+	<-q.recorded
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/db/file"
+	"github.com/siddontang/github-repos-management/internal/notify"
+)
+
+// TestWebhookDelivery tests that webhooks are delivered in the background and their logs recorded
+func TestWebhookDelivery(t *testing.T) {
+	ctx := context.Background()
+	release := make(chan struct{})
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	db, err := file.NewDB("")
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	s, err := NewServiceWithOptions(&config.Config{}, Options{DB: db, Logger: log.New(io.Discard, "", 0)})
+	if err != nil {
+		t.Fatalf("NewServiceWithOptions() error = %v", err)
+	}
+	defer s.Close()
+	hook, err := s.AddWebhook(ctx, receiver.URL, "s3cr3t", nil)
+	if err != nil {
+		t.Fatalf("AddWebhook() error = %v", err)
+	}
+
+	// The endpoint doesn't answer yet, which must not hold up the event
+	start := time.Now()
+	s.notifier.Dispatch(ctx, &notify.Event{Type: notify.EventSyncCompleted, Repository: "org/api"})
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Dispatch() took %v, want it to return before the delivery", elapsed)
+	}
+
+	close(release)
+	s.deliveries.Close(ctx)
+	deliveries, _, err := s.ListWebhookDeliveries(ctx, hook.ID, 1, 10)
+	if err != nil {
+		t.Fatalf("ListWebhookDeliveries() error = %v", err)
+	}
+	if len(deliveries) != 1 || deliveries[0].StatusCode != http.StatusNoContent || deliveries[0].Error != "" {
+		t.Errorf("deliveries = %+v, want one successful delivery", deliveries)
+	}
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/analytics"
+	"github.com/siddontang/github-repos-management/internal/models"
+	"github.com/siddontang/github-repos-management/internal/notify"
+)
+
+// Diff summarizes what changed in the tracked repositories matching the filter between two times:
+// the repositories added and removed and the labels added, from the activity log; the pull requests
+// opened and merged and the issues opened and closed, from the synced items; and the open item
+// counts at both times, from the snapshots.
+func (s *Service) Diff(ctx context.Context, filter *models.DiffFilter) (*models.FleetDiff, error) {
+	if filter.From.IsZero() || filter.To.IsZero() || !filter.To.After(filter.From) {
+		return nil, ErrInvalidWindow
+	}
+	repos, err := s.selectRepositories(ctx, filter.Repo, filter.RepoTag)
+	if err != nil {
+		return nil, err
+	}
+	selected := make(map[string]bool, len(repos))
+	for _, repo := range repos {
+		selected[repo.FullName] = true
+	}
+	// Removed repositories are only reported when no repositories were selected
+	scoped := filter.Repo != "" || filter.RepoTag != "" || workspaceFrom(ctx) != ""
+
+	diff := &models.FleetDiff{
+		From:                filter.From,
+		To:                  filter.To,
+		RepositoriesAdded:   make([]string, 0),
+		RepositoriesRemoved: make([]string, 0),
+		PullRequestsMerged:  make([]*models.DiffItem, 0),
+		IssuesClosed:        make([]*models.DiffItem, 0),
+		LabelsAdded:         make([]*models.DiffItem, 0),
+		Repositories:        make([]*models.RepositoryDiff, 0),
+	}
+	events, _, err := s.db.ListActivity(ctx, &models.ActivityFilter{Since: filter.From, Until: filter.To, Page: 1, PerPage: math.MaxInt32})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list activity: %w", err)
+	}
+	for i := len(events) - 1; i >= 0; i-- {
+		event := events[i]
+		switch notify.EventType(event.Type) {
+		case notify.EventRepositoryAdded:
+			if !scoped || selected[event.Repository] {
+				diff.RepositoriesAdded = append(diff.RepositoriesAdded, event.Repository)
+			}
+		case notify.EventRepositoryRemoved:
+			if !scoped {
+				diff.RepositoriesRemoved = append(diff.RepositoriesRemoved, event.Repository)
+			}
+		case notify.EventPullRequestLabeled, notify.EventIssueLabeled:
+			if !selected[event.Repository] {
+				continue
+			}
+			itemType := models.ItemTypeIssue
+			if notify.EventType(event.Type) == notify.EventPullRequestLabeled {
+				itemType = models.ItemTypePullRequest
+			}
+			diff.LabelsAdded = append(diff.LabelsAdded, &models.DiffItem{
+				Repository: event.Repository,
+				Type:       itemType,
+				Number:     event.Number,
+				Title:      event.Title,
+				HTMLURL:    event.URL,
+				Label:      event.Label,
+				At:         event.CreatedAt,
+			})
+		}
+	}
+
+	query := &models.ItemQuery{Repositories: queryRepositories(ctx, repos, filter.Repo, filter.RepoTag)}
+	prs, err := s.db.FindPullRequests(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find pull requests: %w", err)
+	}
+	for _, pr := range prs {
+		if analytics.InRange(pr.CreatedAt, filter.From, filter.To) {
+			diff.PullRequestsOpened++
+		}
+		if pr.MergedAt != nil && analytics.InRange(*pr.MergedAt, filter.From, filter.To) {
+			diff.PullRequestsMerged = append(diff.PullRequestsMerged, &models.DiffItem{
+				Repository: pr.RepositoryFullName,
+				Type:       models.ItemTypePullRequest,
+				Number:     pr.Number,
+				Title:      pr.Title,
+				HTMLURL:    pr.HTMLURL,
+				At:         *pr.MergedAt,
+			})
+		}
+	}
+	issues, err := s.db.FindIssues(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find issues: %w", err)
+	}
+	for _, issue := range issues {
+		if analytics.InRange(issue.CreatedAt, filter.From, filter.To) {
+			diff.IssuesOpened++
+		}
+		if issue.ClosedAt != nil && analytics.InRange(*issue.ClosedAt, filter.From, filter.To) {
+			diff.IssuesClosed = append(diff.IssuesClosed, &models.DiffItem{
+				Repository: issue.RepositoryFullName,
+				Type:       models.ItemTypeIssue,
+				Number:     issue.Number,
+				Title:      issue.Title,
+				HTMLURL:    issue.HTMLURL,
+				At:         *issue.ClosedAt,
+			})
+		}
+	}
+	for _, items := range [][]*models.DiffItem{diff.PullRequestsMerged, diff.IssuesClosed} {
+		sort.Slice(items, func(i, j int) bool { return items[i].At.Before(items[j].At) })
+	}
+
+	sort.Slice(repos, func(i, j int) bool { return repos[i].FullName < repos[j].FullName })
+	for _, repo := range repos {
+		snapshots, err := s.db.ListRepositorySnapshots(ctx, repo.FullName, time.Time{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list snapshots of %s: %w", repo.FullName, err)
+		}
+		from, to := latestSnapshot(snapshots, filter.From), latestSnapshot(snapshots, filter.To)
+		if from == nil || to == nil {
+			continue
+		}
+		diff.Repositories = append(diff.Repositories, &models.RepositoryDiff{
+			Repository:           repo.FullName,
+			OpenPullRequestsFrom: from.OpenPullRequests,
+			OpenPullRequestsTo:   to.OpenPullRequests,
+			OpenIssuesFrom:       from.OpenIssues,
+			OpenIssuesTo:         to.OpenIssues,
+		})
+	}
+	return diff, nil
+}
+
+// latestSnapshot returns the latest snapshot taken before t, nil when there is none
+func latestSnapshot(snapshots []*models.RepositorySnapshot, t time.Time) *models.RepositorySnapshot {
+	var latest *models.RepositorySnapshot
+	for _, snapshot := range snapshots {
+		if snapshot.CreatedAt.Before(t) && (latest == nil || snapshot.CreatedAt.After(latest.CreatedAt)) {
+			latest = snapshot
+		}
+	}
+	return latest
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/db/file"
+	"github.com/siddontang/github-repos-management/internal/models"
+	"github.com/siddontang/github-repos-management/internal/notify"
+)
+
+func TestDiff(t *testing.T) {
+	ctx := context.Background()
+	db, err := file.NewDB("")
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	if err := db.AddRepository(ctx, &models.Repository{Owner: "org", Name: "api", FullName: "org/api"}); err != nil {
+		t.Fatalf("AddRepository() error = %v", err)
+	}
+
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	day := func(month time.Month, d int) time.Time { return time.Date(2024, month, d, 0, 0, 0, 0, time.UTC) }
+	at := func(t time.Time) *time.Time { return &t }
+
+	events := []*models.ActivityEvent{
+		{Type: string(notify.EventRepositoryAdded), Repository: "org/api", CreatedAt: day(1, 2)},
+		{Type: string(notify.EventRepositoryRemoved), Repository: "org/old", CreatedAt: day(1, 3)},
+		{Type: string(notify.EventIssueLabeled), Repository: "org/api", Number: 7, Label: "bug", CreatedAt: day(1, 4)},
+		{Type: string(notify.EventIssueLabeled), Repository: "org/api", Number: 8, Label: "bug", CreatedAt: day(2, 4)},
+	}
+	for _, event := range events {
+		if err := db.AppendActivity(ctx, event); err != nil {
+			t.Fatalf("AppendActivity() error = %v", err)
+		}
+	}
+	prs := []*models.PullRequest{
+		{Number: 1, State: "closed", CreatedAt: day(1, 5), ClosedAt: at(day(1, 20)), MergedAt: at(day(1, 20))},
+		{Number: 2, State: "closed", CreatedAt: day(1, 6), ClosedAt: at(day(2, 2)), MergedAt: at(day(2, 2))},
+		{Number: 3, State: "open", CreatedAt: day(1, 10)},
+	}
+	for _, pr := range prs {
+		pr.RepositoryFullName = "org/api"
+		if err := db.AddPullRequest(ctx, pr); err != nil {
+			t.Fatalf("AddPullRequest() error = %v", err)
+		}
+	}
+	if err := db.AddIssue(ctx, &models.Issue{RepositoryFullName: "org/api", Number: 7, State: "closed", CreatedAt: day(1, 4), ClosedAt: at(day(1, 9))}); err != nil {
+		t.Fatalf("AddIssue() error = %v", err)
+	}
+	for _, snapshot := range []*models.RepositorySnapshot{
+		{OpenPullRequests: 4, OpenIssues: 9, CreatedAt: day(12, 31).AddDate(-1, 0, 0)},
+		{OpenPullRequests: 2, OpenIssues: 8, CreatedAt: day(1, 31)},
+		{OpenPullRequests: 1, OpenIssues: 1, CreatedAt: day(2, 15)},
+	} {
+		snapshot.RepositoryFullName = "org/api"
+		if err := db.AddRepositorySnapshot(ctx, snapshot); err != nil {
+			t.Fatalf("AddRepositorySnapshot() error = %v", err)
+		}
+	}
+
+	s := &Service{db: db, config: &config.Config{}, logger: log.New(io.Discard, "", 0)}
+	diff, err := s.Diff(ctx, &models.DiffFilter{From: from, To: to})
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+	if !reflect.DeepEqual(diff.RepositoriesAdded, []string{"org/api"}) || !reflect.DeepEqual(diff.RepositoriesRemoved, []string{"org/old"}) {
+		t.Errorf("repositories added %v and removed %v, want org/api and org/old", diff.RepositoriesAdded, diff.RepositoriesRemoved)
+	}
+	if diff.PullRequestsOpened != 3 || len(diff.PullRequestsMerged) != 1 || diff.PullRequestsMerged[0].Number != 1 {
+		t.Errorf("pull requests opened %d and merged %v, want 3 and #1", diff.PullRequestsOpened, diff.PullRequestsMerged)
+	}
+	if diff.IssuesOpened != 1 || len(diff.IssuesClosed) != 1 || len(diff.LabelsAdded) != 1 || diff.LabelsAdded[0].Number != 7 {
+		t.Errorf("issues opened %d, closed %d and labeled %v, want 1, 1 and #7", diff.IssuesOpened, len(diff.IssuesClosed), diff.LabelsAdded)
+	}
+	want := []*models.RepositoryDiff{{Repository: "org/api", OpenPullRequestsFrom: 4, OpenPullRequestsTo: 2, OpenIssuesFrom: 9, OpenIssuesTo: 8}}
+	if !reflect.DeepEqual(diff.Repositories, want) {
+		t.Errorf("repositories = %+v, want %+v", diff.Repositories[0], want[0])
+	}
+
+	if _, err := s.Diff(ctx, &models.DiffFilter{From: to, To: from}); !errors.Is(err, ErrInvalidWindow) {
+		t.Errorf("Diff(reversed) error = %v, want ErrInvalidWindow", err)
+	}
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// GetPullRequestDiff gets the changes of a pull request of a tracked repository as a unified diff or
+// a series of patches. They are fetched from GitHub and, with cache, stored for offline viewing;
+// when they can't be fetched, such as in offline mode, the stored copy is served instead.
+func (s *Service) GetPullRequestDiff(ctx context.Context, owner, name string, number int, format string, cache bool) (*models.PullRequestDiff, error) {
+	if format == "" {
+		format = models.DiffFormatDiff
+	}
+	if format != models.DiffFormatDiff && format != models.DiffFormatPatch {
+		return nil, ErrInvalidDiffFormat
+	}
+	repo, err := s.db.GetRepository(ctx, owner, name)
+	if err != nil {
+		return nil, notFound(err, ErrRepositoryNotFound)
+	}
+	if err := s.checkWorkspace(ctx, repo.FullName); err != nil {
+		return nil, err
+	}
+
+	content, err := s.gh(ctx).GetPullRequestDiff(repo.Owner, repo.Name, number, format)
+	if err != nil {
+		cached, cacheErr := s.db.GetPullRequestDiff(ctx, repo.FullName, number, format)
+		if cacheErr != nil {
+			return nil, fmt.Errorf("failed to fetch pull request changes, and none are cached: %w", err)
+		}
+		s.logger.Printf("Serving the cached %s of %s#%d: %v", format, repo.FullName, number, err)
+		cached.Cached = true
+		return cached, nil
+	}
+
+	diff := &models.PullRequestDiff{
+		RepositoryFullName: repo.FullName,
+		Number:             number,
+		Format:             format,
+		Content:            content,
+		FetchedAt:          time.Now(),
+	}
+	if cache {
+		if err := s.db.SavePullRequestDiff(ctx, diff); err != nil {
+			return nil, fmt.Errorf("failed to cache pull request changes: %w", err)
+		}
+	}
+	return diff, nil
+}
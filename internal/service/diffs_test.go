@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"testing"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/db/file"
+	"github.com/siddontang/github-repos-management/internal/github"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// diffGitHub serves the diffs of pull requests, or fails with err when set
+type diffGitHub struct {
+	github.ClientInterface
+	content map[string]string // By format
+	err     *error
+}
+
+func (g diffGitHub) GetPullRequestDiff(owner, name string, number int, format string) (string, error) {
+	if *g.err != nil {
+		return "", *g.err
+	}
+	return g.content[format], nil
+}
+
+func TestGetPullRequestDiff(t *testing.T) {
+	ctx := context.Background()
+	db, err := file.NewDB("")
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	if err := db.AddRepository(ctx, &models.Repository{Owner: "org", Name: "api", FullName: "org/api"}); err != nil {
+		t.Fatalf("AddRepository() error = %v", err)
+	}
+	var ghErr error
+	gh := diffGitHub{content: map[string]string{"diff": "diff --git a/main.go b/main.go\n", "patch": "From 1234 Mon Sep 17 00:00:00 2001\n"}, err: &ghErr}
+	s := &Service{db: db, ghClient: gh, config: &config.Config{}, logger: log.New(io.Discard, "", 0)}
+
+	diff, err := s.GetPullRequestDiff(ctx, "org", "api", 7, "", false)
+	if err != nil {
+		t.Fatalf("GetPullRequestDiff() error = %v", err)
+	}
+	if diff.Format != models.DiffFormatDiff || diff.Content != gh.content["diff"] || diff.Cached {
+		t.Errorf("GetPullRequestDiff() = %+v, want the fetched diff", diff)
+	}
+	if _, err := s.GetPullRequestDiff(ctx, "org", "api", 7, "html", false); !errors.Is(err, ErrInvalidDiffFormat) {
+		t.Errorf("GetPullRequestDiff(html) error = %v, want ErrInvalidDiffFormat", err)
+	}
+	if _, err := s.GetPullRequestDiff(ctx, "org", "web", 7, "", false); !errors.Is(err, ErrRepositoryNotFound) {
+		t.Errorf("GetPullRequestDiff(untracked) error = %v, want ErrRepositoryNotFound", err)
+	}
+
+	// Only diffs fetched with cache are served when GitHub can't be reached
+	if _, err := s.GetPullRequestDiff(ctx, "org", "api", 7, models.DiffFormatPatch, true); err != nil {
+		t.Fatalf("GetPullRequestDiff(cache) error = %v", err)
+	}
+	ghErr = github.ErrOffline
+	patch, err := s.GetPullRequestDiff(ctx, "org", "api", 7, models.DiffFormatPatch, false)
+	if err != nil {
+		t.Fatalf("GetPullRequestDiff(cached) error = %v", err)
+	}
+	if !patch.Cached || patch.Content != gh.content["patch"] {
+		t.Errorf("GetPullRequestDiff(cached) = %+v, want the cached patch", patch)
+	}
+	if _, err := s.GetPullRequestDiff(ctx, "org", "api", 7, models.DiffFormatDiff, false); !errors.Is(err, github.ErrOffline) {
+		t.Errorf("GetPullRequestDiff(not cached) error = %v, want ErrOffline", err)
+	}
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/siddontang/github-repos-management/internal/github"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// maxDiscoveredRepositories bounds how many repositories of each relation discovery lists
+const maxDiscoveredRepositories = 1000
+
+// discoveryRelations are the relations discovery looks at by default
+var discoveryRelations = []string{github.RelationOwner, github.RelationStarred, github.RelationContributor}
+
+// DiscoverRepositories lists the non-archived repositories the authenticated GitHub user owns,
+// stars or contributes to that are not tracked yet, or not in the workspace of the context.
+// Relations limits the lookup to some of them; empty looks at all.
+func (s *Service) DiscoverRepositories(ctx context.Context, relations []string) ([]*models.DiscoveredRepository, error) {
+	if len(relations) == 0 {
+		relations = discoveryRelations
+	}
+	for _, relation := range relations {
+		if !slices.Contains(discoveryRelations, relation) {
+			return nil, ErrInvalidRelation
+		}
+	}
+
+	known, err := s.trackedNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[string]*models.DiscoveredRepository)
+	for _, relation := range relations {
+		repos, err := s.gh(ctx).ListUserRepositories(relation, maxDiscoveredRepositories)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s repositories: %w", relation, err)
+		}
+		for _, repo := range repos {
+			key := strings.ToLower(repo.FullName)
+			if repo.Archived || known[key] {
+				continue
+			}
+			discovered, ok := found[key]
+			if !ok {
+				discovered = &models.DiscoveredRepository{
+					FullName:    repo.FullName,
+					Description: repo.Description,
+					URL:         repo.HTMLURL,
+					Private:     repo.Private,
+				}
+				found[key] = discovered
+			}
+			if !slices.Contains(discovered.Relations, relation) {
+				discovered.Relations = append(discovered.Relations, relation)
+			}
+		}
+	}
+
+	discovered := make([]*models.DiscoveredRepository, 0, len(found))
+	for _, repo := range found {
+		discovered = append(discovered, repo)
+	}
+	sort.Slice(discovered, func(i, j int) bool {
+		return strings.ToLower(discovered[i].FullName) < strings.ToLower(discovered[j].FullName)
+	})
+	return discovered, nil
+}
+
+// UntrackedRepositories returns the repositories among fullNames that are not tracked yet, or not
+// in the workspace of the context, in their given order without duplicates, ignoring case
+func (s *Service) UntrackedRepositories(ctx context.Context, fullNames []string) ([]string, error) {
+	known, err := s.trackedNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+	untracked := make([]string, 0, len(fullNames))
+	for _, fullName := range fullNames {
+		key := strings.ToLower(fullName)
+		if !known[key] {
+			known[key] = true
+			untracked = append(untracked, fullName)
+		}
+	}
+	return untracked, nil
+}
+
+// trackedNames returns the lowercase full names of the tracked repositories in the workspace of the context
+func (s *Service) trackedNames(ctx context.Context) (map[string]bool, error) {
+	tracked, err := s.db.ListAllRepositories(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list repositories: %w", err)
+	}
+	if tracked, err = s.scopeRepositories(ctx, tracked); err != nil {
+		return nil, err
+	}
+	known := make(map[string]bool, len(tracked))
+	for _, repo := range tracked {
+		known[strings.ToLower(repo.FullName)] = true
+	}
+	return known, nil
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/siddontang/github-repos-management/internal/db/file"
+	"github.com/siddontang/github-repos-management/internal/github"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// userGitHub serves the repositories of each relation of the authenticated user
+type userGitHub struct {
+	github.ClientInterface
+	repos map[string][]*github.Repository
+}
+
+func (g userGitHub) ListUserRepositories(relation string, limit int) ([]*github.Repository, error) {
+	return g.repos[relation], nil
+}
+
+func TestDiscoverRepositories(t *testing.T) {
+	ctx := context.Background()
+	db, err := file.NewDB("")
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	if err := db.AddRepository(ctx, &models.Repository{Owner: "alice", Name: "tracked", FullName: "alice/tracked"}); err != nil {
+		t.Fatalf("AddRepository() error = %v", err)
+	}
+	gh := userGitHub{repos: map[string][]*github.Repository{
+		github.RelationOwner: {
+			{FullName: "alice/tracked"},
+			{FullName: "alice/tool", Description: "A tool"},
+			{FullName: "alice/old", Archived: true},
+		},
+		github.RelationStarred: {
+			{FullName: "Alice/Tool"},
+			{FullName: "pingcap/tidb"},
+		},
+		github.RelationContributor: {
+			{FullName: "pingcap/tidb"},
+		},
+	}}
+	s := &Service{db: db, ghClient: gh}
+
+	repos, err := s.DiscoverRepositories(ctx, nil)
+	if err != nil {
+		t.Fatalf("DiscoverRepositories() error = %v", err)
+	}
+	if len(repos) != 2 {
+		t.Fatalf("DiscoverRepositories() = %d repositories, want the untracked non-archived 2", len(repos))
+	}
+	if repos[0].FullName != "alice/tool" || repos[0].Description != "A tool" || len(repos[0].Relations) != 2 {
+		t.Errorf("first repository = %+v, want alice/tool owned and starred", repos[0])
+	}
+	if repos[1].FullName != "pingcap/tidb" || len(repos[1].Relations) != 2 || repos[1].Relations[1] != github.RelationContributor {
+		t.Errorf("second repository = %+v, want pingcap/tidb starred and contributed to", repos[1])
+	}
+
+	repos, err = s.DiscoverRepositories(ctx, []string{github.RelationContributor})
+	if err != nil || len(repos) != 1 || repos[0].FullName != "pingcap/tidb" {
+		t.Errorf("DiscoverRepositories(contributor) = %v, %v, want pingcap/tidb", repos, err)
+	}
+	if _, err := s.DiscoverRepositories(ctx, []string{"watched"}); !errors.Is(err, ErrInvalidRelation) {
+		t.Errorf("DiscoverRepositories(watched) error = %v, want ErrInvalidRelation", err)
+	}
+
+	untracked, err := s.UntrackedRepositories(ctx, []string{"Alice/Tracked", "alice/tool", "ALICE/TOOL", "pingcap/tidb"})
+	if err != nil {
+		t.Fatalf("UntrackedRepositories() error = %v", err)
+	}
+	if want := []string{"alice/tool", "pingcap/tidb"}; !slices.Equal(untracked, want) {
+		t.Errorf("UntrackedRepositories() = %v, want %v", untracked, want)
+	}
+}
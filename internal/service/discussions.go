@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// syncDiscussions fetches the most recently updated discussions of a repository, at most limit,
+// and stores them with those fetched before
+func (s *Service) syncDiscussions(ctx context.Context, owner, name string, limit int) error {
+	fullName := owner + "/" + name
+	stored, err := s.db.ListDiscussions(ctx, fullName)
+	if err != nil {
+		return err
+	}
+	ghDiscussions, err := s.gh(ctx).ListDiscussions(owner, name, limit)
+	if err != nil {
+		return err
+	}
+
+	fetched := make(map[int]bool, len(ghDiscussions))
+	discussions := make([]*models.Discussion, 0, len(stored)+len(ghDiscussions))
+	for _, d := range ghDiscussions {
+		fetched[d.Number] = true
+		discussions = append(discussions, &models.Discussion{
+			Number:     d.Number,
+			Title:      d.Title,
+			Author:     d.AuthorLogin,
+			Category:   d.Category,
+			Answerable: d.Answerable,
+			Answered:   d.Answered,
+			Closed:     d.Closed,
+			Comments:   d.Comments,
+			Upvotes:    d.Upvotes,
+			HTMLURL:    d.URL,
+			CreatedAt:  d.CreatedAt,
+			UpdatedAt:  d.UpdatedAt,
+		})
+	}
+	for _, discussion := range stored {
+		if !fetched[discussion.Number] {
+			discussions = append(discussions, discussion)
+		}
+	}
+	return s.db.ReplaceDiscussions(ctx, fullName, discussions)
+}
+
+// ListDiscussions lists the synced discussions of the tracked repositories matching the filter,
+// most recently updated first
+func (s *Service) ListDiscussions(ctx context.Context, filter *models.DiscussionFilter) ([]*models.Discussion, *models.Pagination, error) {
+	repos, err := s.selectRepositories(ctx, filter.Repo, filter.RepoTag)
+	if err != nil {
+		return nil, nil, err
+	}
+	selected := make(map[string]bool, len(repos))
+	for _, repo := range repos {
+		selected[repo.FullName] = true
+	}
+
+	all, err := s.db.ListDiscussions(ctx, "")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list discussions: %w", err)
+	}
+	state := stateFilter(filter.State)
+	discussions := make([]*models.Discussion, 0, len(all))
+	for _, discussion := range all {
+		if !selected[discussion.RepositoryFullName] {
+			continue
+		}
+		if (state == "open" && discussion.Closed) || (state == "closed" && !discussion.Closed) {
+			continue
+		}
+		if filter.Author != "" && !strings.EqualFold(discussion.Author, filter.Author) {
+			continue
+		}
+		if filter.Category != "" && !strings.EqualFold(discussion.Category, filter.Category) {
+			continue
+		}
+		if filter.Unanswered && (!discussion.Answerable || discussion.Answered) {
+			continue
+		}
+		if !filter.Since.IsZero() && discussion.UpdatedAt.Before(filter.Since) {
+			continue
+		}
+		discussions = append(discussions, discussion)
+	}
+
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.PerPage < 1 {
+		filter.PerPage = 30
+	}
+	total := len(discussions)
+	pagination := &models.Pagination{
+		Page:       filter.Page,
+		PerPage:    filter.PerPage,
+		Total:      total,
+		TotalPages: (total + filter.PerPage - 1) / filter.PerPage,
+	}
+	start := (filter.Page - 1) * filter.PerPage
+	if start >= total {
+		return []*models.Discussion{}, pagination, nil
+	}
+	end := start + filter.PerPage
+	if end > total {
+		end = total
+	}
+	return discussions[start:end], pagination, nil
+}
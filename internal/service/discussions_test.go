@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/db/file"
+	"github.com/siddontang/github-repos-management/internal/github"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// discussionsGitHub serves a fixed list of discussions, most recently updated first
+type discussionsGitHub struct {
+	github.ClientInterface
+	discussions []*github.Discussion
+}
+
+func (g *discussionsGitHub) ListDiscussions(owner, name string, limit int) ([]*github.Discussion, error) {
+	if len(g.discussions) > limit {
+		return g.discussions[:limit], nil
+	}
+	return g.discussions, nil
+}
+
+func TestDiscussions(t *testing.T) {
+	ctx := context.Background()
+	db, err := file.NewDB("")
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	if err := db.AddRepository(ctx, &models.Repository{Owner: "org", Name: "repo", FullName: "org/repo"}); err != nil {
+		t.Fatalf("AddRepository() error = %v", err)
+	}
+
+	now := time.Now()
+	gh := &discussionsGitHub{discussions: []*github.Discussion{
+		{Number: 3, Title: "How do I configure tokens?", AuthorLogin: "alice", Category: "Q&A", Answerable: true, UpdatedAt: now},
+		{Number: 2, Title: "Why is sync slow?", AuthorLogin: "bob", Category: "Q&A", Answerable: true, Answered: true, UpdatedAt: now.Add(-time.Hour)},
+		{Number: 1, Title: "Welcome", AuthorLogin: "alice", Category: "Announcements", Closed: true, UpdatedAt: now.Add(-48 * time.Hour)},
+	}}
+	s := &Service{db: db, ghClient: gh, config: &config.Config{}, logger: log.New(io.Discard, "", 0)}
+	if err := s.syncDiscussions(ctx, "org", "repo", 10); err != nil {
+		t.Fatalf("syncDiscussions() error = %v", err)
+	}
+
+	// Later syncs fetching only the latest discussions keep the older ones
+	gh.discussions = []*github.Discussion{
+		{Number: 3, Title: "How do I configure tokens?", AuthorLogin: "alice", Category: "Q&A", Answerable: true, Answered: true, UpdatedAt: now.Add(time.Minute)},
+	}
+	if err := s.syncDiscussions(ctx, "org", "repo", 1); err != nil {
+		t.Fatalf("syncDiscussions() error = %v", err)
+	}
+
+	numbers := func(filter *models.DiscussionFilter) []int {
+		t.Helper()
+		filter.Page, filter.PerPage = 1, 30
+		discussions, _, err := s.ListDiscussions(ctx, filter)
+		if err != nil {
+			t.Fatalf("ListDiscussions() error = %v", err)
+		}
+		var got []int
+		for _, d := range discussions {
+			got = append(got, d.Number)
+		}
+		return got
+	}
+	if got := numbers(&models.DiscussionFilter{}); len(got) != 3 || got[0] != 3 {
+		t.Errorf("all discussions = %v, want 3 first of three", got)
+	}
+	if got := numbers(&models.DiscussionFilter{State: "open", Category: "q&a"}); len(got) != 2 {
+		t.Errorf("open Q&A discussions = %v, want two", got)
+	}
+	if got := numbers(&models.DiscussionFilter{Unanswered: true}); len(got) != 0 {
+		t.Errorf("unanswered discussions = %v, want none once 3 is answered", got)
+	}
+	if got := numbers(&models.DiscussionFilter{State: "closed", Author: "ALICE"}); len(got) != 1 || got[0] != 1 {
+		t.Errorf("closed discussions of alice = %v, want [1]", got)
+	}
+}
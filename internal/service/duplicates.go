@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+	"github.com/siddontang/github-repos-management/internal/similarity"
+)
+
+// defaultDuplicateThreshold is the lowest similarity of duplicate issues when none is configured
+const defaultDuplicateThreshold = 0.5
+
+// detectDuplicates replaces the stored duplicate candidates of a repository with the pairs of its
+// open issues the detector finds alike. The later issue of a pair is the candidate duplicate, and
+// an issue is only flagged as the duplicate of its best match.
+func (s *Service) detectDuplicates(ctx context.Context, fullName string) error {
+	issues, err := s.db.FindIssues(ctx, &models.ItemQuery{Repositories: []string{fullName}, State: "open"})
+	if err != nil {
+		return fmt.Errorf("failed to find issues: %w", err)
+	}
+	issues = liveIssues(issues)
+
+	byNumber := make(map[int]*models.Issue, len(issues))
+	docs := make([]similarity.Document, 0, len(issues))
+	for _, issue := range issues {
+		byNumber[issue.Number] = issue
+		docs = append(docs, similarity.Document{ID: issue.Number, Text: issue.Title + "\n" + issue.Body})
+	}
+
+	threshold := s.config.Duplicates.Threshold
+	if threshold <= 0 {
+		threshold = defaultDuplicateThreshold
+	}
+	now := time.Now()
+	flagged := make(map[int]bool)
+	var candidates []*models.DuplicateCandidate
+	for _, match := range s.duplicates.Matches(docs, threshold) {
+		// Matches come best first, so the first match of an issue is its best
+		if flagged[match.B] {
+			continue
+		}
+		flagged[match.B] = true
+		issue, original := byNumber[match.B], byNumber[match.A]
+		candidates = append(candidates, &models.DuplicateCandidate{
+			Number:             issue.Number,
+			Title:              issue.Title,
+			HTMLURL:            issue.HTMLURL,
+			DuplicateOf:        original.Number,
+			DuplicateOfTitle:   original.Title,
+			DuplicateOfHTMLURL: original.HTMLURL,
+			Score:              match.Score,
+			DetectedAt:         now,
+		})
+	}
+
+	return s.db.ReplaceDuplicates(ctx, fullName, candidates)
+}
+
+// ListDuplicates lists the probable duplicate issues of a repository found by its last sync, best
+// match first
+func (s *Service) ListDuplicates(ctx context.Context, owner, name string) ([]*models.DuplicateCandidate, error) {
+	if s.duplicates == nil {
+		return nil, ErrDuplicatesNotConfigured
+	}
+	repo, err := s.GetRepository(ctx, owner, name)
+	if err != nil {
+		return nil, err
+	}
+	return s.db.ListDuplicates(ctx, repo.FullName)
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"testing"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/db/file"
+	"github.com/siddontang/github-repos-management/internal/models"
+	"github.com/siddontang/github-repos-management/internal/similarity"
+)
+
+func TestDetectDuplicates(t *testing.T) {
+	ctx := context.Background()
+	db, err := file.NewDB("")
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	if err := db.AddRepository(ctx, &models.Repository{Owner: "org", Name: "api", FullName: "org/api"}); err != nil {
+		t.Fatalf("AddRepository() error = %v", err)
+	}
+	for _, issue := range []*models.Issue{
+		{RepositoryFullName: "org/api", Number: 1, Title: "Panic on startup when config is missing", State: "OPEN"},
+		{RepositoryFullName: "org/api", Number: 2, Title: "Add dark mode", Body: "The dashboard is too bright", State: "OPEN"},
+		{RepositoryFullName: "org/api", Number: 3, Title: "panic on startup when the config is missing", State: "OPEN"},
+		{RepositoryFullName: "org/api", Number: 4, Title: "Panic on startup when config is missing!", State: "OPEN"},
+		{RepositoryFullName: "org/api", Number: 5, Title: "Panic on startup when config is missing", State: "CLOSED"},
+	} {
+		if err := db.AddIssue(ctx, issue); err != nil {
+			t.Fatalf("AddIssue() error = %v", err)
+		}
+	}
+
+	s := &Service{db: db, config: &config.Config{}, logger: log.New(io.Discard, "", 0)}
+	if _, err := s.ListDuplicates(ctx, "org", "api"); !errors.Is(err, ErrDuplicatesNotConfigured) {
+		t.Errorf("ListDuplicates(disabled) error = %v, want ErrDuplicatesNotConfigured", err)
+	}
+
+	s.duplicates = similarity.Shingles{}
+	if err := s.detectDuplicates(ctx, "org/api"); err != nil {
+		t.Fatalf("detectDuplicates() error = %v", err)
+	}
+	candidates, err := s.ListDuplicates(ctx, "org", "api")
+	if err != nil {
+		t.Fatalf("ListDuplicates() error = %v", err)
+	}
+
+	// #4 has the same words as #1, and #3 is flagged once, against its best match
+	if len(candidates) != 2 {
+		t.Fatalf("ListDuplicates() = %+v, want #3 and #4", candidates)
+	}
+	if c := candidates[0]; c.Number != 4 || c.DuplicateOf != 1 || c.Score != 1 {
+		t.Errorf("candidates[0] = %+v, want #4 duplicating #1 with a score of 1", c)
+	}
+	if c := candidates[1]; c.Number != 3 || c.DuplicateOf != 1 || c.DuplicateOfTitle != "Panic on startup when config is missing" {
+		t.Errorf("candidates[1] = %+v, want #3 duplicating #1", c)
+	}
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// taskPattern matches the items of a Markdown task list, capturing the check mark and the text
+var taskPattern = regexp.MustCompile(`(?m)^[ \t]*[-*+][ \t]+\[([ xX])\][ \t]+(.*)$`)
+
+// localReferencePattern matches references to issues of the same repository such as #12
+var localReferencePattern = regexp.MustCompile(`(?:^|[^\w/.#-])#([1-9][0-9]*)\b`)
+
+// parseTasks returns the issues referenced by the task list of the body of an issue of a
+// repository, each with whether its task is checked. A task refers to the first issue it mentions,
+// as #12, owner/name#12 or a GitHub URL; tasks without one are left out.
+func parseTasks(fullName, body string) []models.IssueTask {
+	var tasks []models.IssueTask
+	seen := make(map[string]bool)
+	for _, match := range taskPattern.FindAllStringSubmatch(body, -1) {
+		ref := taskReference(fullName, match[2])
+		if ref == "" || seen[strings.ToLower(ref)] {
+			continue
+		}
+		seen[strings.ToLower(ref)] = true
+		tasks = append(tasks, models.IssueTask{Reference: ref, Checked: match[1] != " "})
+	}
+	return tasks
+}
+
+// taskReference returns the first issue referenced by the text of a task, as owner/name#number
+func taskReference(fullName, text string) string {
+	ref, at := "", len(text)
+	if loc := referencePattern.FindStringSubmatchIndex(text); loc != nil {
+		ref, at = text[loc[2]:loc[3]]+"/"+text[loc[4]:loc[5]]+"#"+text[loc[6]:loc[7]], loc[0]
+	}
+	if loc := localReferencePattern.FindStringSubmatchIndex(text); loc != nil && loc[0] < at {
+		ref = fullName + "#" + text[loc[2]:loc[3]]
+	}
+	return ref
+}
+
+// GetIssueTree returns an issue with the issues of its task list, recursively, and the progress of
+// each epic. The state of children that are not stored comes from their task check mark. Children
+// and parents outside the workspace of the context are left as untracked references.
+func (s *Service) GetIssueTree(ctx context.Context, owner, name string, number int) (*models.IssueTree, error) {
+	if err := s.checkRepositoryWorkspace(ctx, owner, name); err != nil {
+		return nil, err
+	}
+	issue, err := s.db.GetIssue(ctx, owner+"/"+name, number)
+	if err != nil {
+		return nil, notFound(err, ErrIssueNotFound)
+	}
+	if issue.Tombstoned {
+		return nil, ErrIssueNotFound
+	}
+	repos, err := s.selectRepositories(ctx, "", "")
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]string, len(repos))
+	for _, repo := range repos {
+		names[strings.ToLower(repo.FullName)] = repo.FullName
+	}
+
+	tree := s.issueTree(ctx, issue, names, make(map[string]bool))
+	if tree.TrackedBy, err = s.trackedBy(ctx, repos, tree.Reference); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+// issueTree builds the tree of an issue. path holds the issues above it, so that an issue listing
+// one of its ancestors doesn't loop.
+func (s *Service) issueTree(ctx context.Context, issue *models.Issue, names map[string]string, path map[string]bool) *models.IssueTree {
+	node := issueTreeNode(issue)
+	if len(issue.Tasks) == 0 {
+		return node
+	}
+	key := strings.ToLower(node.Reference)
+	path[key] = true
+	defer delete(path, key)
+
+	node.Progress = &models.IssueProgress{Total: len(issue.Tasks)}
+	for _, task := range issue.Tasks {
+		child := &models.IssueTree{Reference: task.Reference, Closed: task.Checked}
+		if stored := s.taskIssue(ctx, task.Reference, names); stored != nil {
+			if path[strings.ToLower(task.Reference)] {
+				child = issueTreeNode(stored)
+			} else {
+				child = s.issueTree(ctx, stored, names, path)
+			}
+		}
+		if child.Closed {
+			node.Progress.Closed++
+		}
+		node.Children = append(node.Children, child)
+	}
+	return node
+}
+
+// issueTreeNode returns the tree node of a stored issue, without its children
+func issueTreeNode(issue *models.Issue) *models.IssueTree {
+	return &models.IssueTree{
+		Reference: fmt.Sprintf("%s#%d", issue.RepositoryFullName, issue.Number),
+		Title:     issue.Title,
+		State:     issue.State,
+		HTMLURL:   issue.HTMLURL,
+		Tracked:   true,
+		Closed:    !strings.EqualFold(issue.State, "open"),
+	}
+}
+
+// taskIssue returns the stored issue a task refers to, or nil when its repository isn't tracked or
+// the issue isn't synced
+func (s *Service) taskIssue(ctx context.Context, ref string, names map[string]string) *models.Issue {
+	repo, number := splitReference(ref)
+	fullName, ok := names[strings.ToLower(repo)]
+	if !ok {
+		return nil
+	}
+	n, err := strconv.Atoi(number)
+	if err != nil {
+		return nil
+	}
+	issue, err := s.db.GetIssue(ctx, fullName, n)
+	if err != nil || issue.Tombstoned {
+		return nil
+	}
+	return issue
+}
+
+// trackedBy lists the stored issues of repos listing an issue in their task list
+func (s *Service) trackedBy(ctx context.Context, repos []*models.Repository, ref string) ([]string, error) {
+	issues, err := s.db.FindIssues(ctx, &models.ItemQuery{Repositories: queryRepositories(ctx, repos, "", "")})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find issues: %w", err)
+	}
+	var parents []string
+	for _, issue := range liveIssues(issues) {
+		for _, task := range issue.Tasks {
+			if strings.EqualFold(task.Reference, ref) {
+				parents = append(parents, fmt.Sprintf("%s#%d", issue.RepositoryFullName, issue.Number))
+				break
+			}
+		}
+	}
+	sort.Strings(parents)
+	return parents, nil
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"reflect"
+	"testing"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/db/file"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+func TestParseTasks(t *testing.T) {
+	body := "## Tasks\n" +
+		"- [x] #11 Compaction\n" +
+		"  * [ ] Snapshots, see org/web#5\n" +
+		"- [X] https://github.com/other/lib/issues/7\n" +
+		"- [ ] Write docs\n" +
+		"- [ ] #11 again\n" +
+		"- Not a task #12\n"
+	got := parseTasks("org/api", body)
+	want := []models.IssueTask{
+		{Reference: "org/api#11", Checked: true},
+		{Reference: "org/web#5"},
+		{Reference: "other/lib#7", Checked: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseTasks() = %+v, want %+v", got, want)
+	}
+}
+
+func TestGetIssueTree(t *testing.T) {
+	ctx := context.Background()
+	db, err := file.NewDB("")
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	if err := db.AddRepository(ctx, &models.Repository{Owner: "org", Name: "api", FullName: "org/api"}); err != nil {
+		t.Fatalf("AddRepository() error = %v", err)
+	}
+	for _, issue := range []*models.Issue{
+		{RepositoryFullName: "org/api", Number: 10, Title: "Epic", State: "OPEN", Tasks: []models.IssueTask{
+			{Reference: "org/api#11"}, {Reference: "org/api#12", Checked: true}, {Reference: "other/lib#7", Checked: true},
+		}},
+		{RepositoryFullName: "org/api", Number: 11, Title: "Done", State: "CLOSED"},
+		{RepositoryFullName: "org/api", Number: 14, Title: "Deleted", State: "OPEN", Tombstoned: true},
+		{RepositoryFullName: "org/api", Number: 12, Title: "Sub-epic", State: "OPEN", Tasks: []models.IssueTask{
+			{Reference: "org/api#10"}, {Reference: "org/api#13"},
+		}},
+	} {
+		if err := db.AddIssue(ctx, issue); err != nil {
+			t.Fatalf("AddIssue() error = %v", err)
+		}
+	}
+	s := &Service{db: db, config: &config.Config{}, logger: log.New(io.Discard, "", 0)}
+
+	tree, err := s.GetIssueTree(ctx, "org", "api", 10)
+	if err != nil {
+		t.Fatalf("GetIssueTree() error = %v", err)
+	}
+	// #12 is open although its task is checked, while the state of other/lib#7 comes from its task
+	if tree.Progress == nil || *tree.Progress != (models.IssueProgress{Closed: 2, Total: 3}) {
+		t.Errorf("Progress = %+v, want 2/3 closed", tree.Progress)
+	}
+	if !reflect.DeepEqual(tree.TrackedBy, []string{"org/api#12"}) {
+		t.Errorf("TrackedBy = %v, want org/api#12", tree.TrackedBy)
+	}
+	sub := tree.Children[1]
+	if sub.Reference != "org/api#12" || len(sub.Children) != 2 || sub.Progress.Closed != 0 {
+		t.Fatalf("Children[1] = %+v, want org/api#12 with 2 open children", sub)
+	}
+	// #10 listed by its own child isn't expanded again
+	if loop := sub.Children[0]; loop.Reference != "org/api#10" || loop.Children != nil || !loop.Tracked {
+		t.Errorf("loop = %+v, want org/api#10 without children", loop)
+	}
+	if missing := sub.Children[1]; missing.Tracked || missing.Closed {
+		t.Errorf("missing = %+v, want an open issue that isn't stored", missing)
+	}
+
+	if _, err := s.GetIssueTree(ctx, "org", "api", 99); !errors.Is(err, ErrIssueNotFound) {
+		t.Errorf("GetIssueTree(missing) error = %v, want ErrIssueNotFound", err)
+	}
+	if _, err := s.GetIssueTree(ctx, "org", "api", 14); !errors.Is(err, ErrIssueNotFound) {
+		t.Errorf("GetIssueTree(deleted) error = %v, want ErrIssueNotFound", err)
+	}
+
+	// A workspace sees neither the issues of other repositories nor the parents they hold
+	if err := db.AddRepository(ctx, &models.Repository{Owner: "org", Name: "web", FullName: "org/web"}); err != nil {
+		t.Fatalf("AddRepository() error = %v", err)
+	}
+	if err := db.AddIssue(ctx, &models.Issue{RepositoryFullName: "org/web", Number: 1, Title: "Web epic", State: "open", Tasks: []models.IssueTask{{Reference: "org/api#10"}}}); err != nil {
+		t.Fatalf("AddIssue() error = %v", err)
+	}
+	if _, err := s.CreateWorkspace(ctx, "web", "", 0); err != nil {
+		t.Fatalf("CreateWorkspace() error = %v", err)
+	}
+	web := WithWorkspace(ctx, "web")
+	if err := s.addWorkspaceRepository(web, "org/web"); err != nil {
+		t.Fatalf("addWorkspaceRepository() error = %v", err)
+	}
+	if _, err := s.GetIssueTree(web, "org", "api", 10); !errors.Is(err, ErrRepositoryNotFound) {
+		t.Errorf("GetIssueTree(other workspace) error = %v, want ErrRepositoryNotFound", err)
+	}
+	tree, err = s.GetIssueTree(web, "org", "web", 1)
+	if err != nil {
+		t.Fatalf("GetIssueTree(workspace) error = %v", err)
+	}
+	if child := tree.Children[0]; child.Tracked || child.Title != "" || tree.Closed {
+		t.Errorf("workspace tree = %+v with child %+v, want an open epic with an untracked child", tree, child)
+	}
+	if tree, _ := s.GetIssueTree(ctx, "org", "api", 10); !reflect.DeepEqual(tree.TrackedBy, []string{"org/api#12", "org/web#1"}) {
+		t.Errorf("TrackedBy = %v, want org/api#12 and org/web#1", tree.TrackedBy)
+	}
+}
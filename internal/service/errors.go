@@ -1,10 +1,80 @@
 package service
 
-import "errors"
+import (
+	"errors"
+
+	"github.com/siddontang/github-repos-management/internal/db"
+)
 
 // Error definitions
 var (
-	ErrRepositoryExists      = errors.New("repository already exists")
-	ErrRepositoryNotFound    = errors.New("repository not found")
-	ErrInvalidRepositoryName = errors.New("invalid repository name format")
+	ErrRepositoryExists         = errors.New("repository already exists")
+	ErrRepositoryNotFound       = errors.New("repository not found")
+	ErrPullRequestNotFound      = errors.New("pull request not found")
+	ErrIssueNotFound            = errors.New("issue not found")
+	ErrInvalidRepositoryName    = errors.New("invalid repository name format")
+	ErrInvalidTag               = errors.New("invalid repository tag")
+	ErrInvalidSyncConfig        = errors.New("invalid repository sync configuration")
+	ErrWebhookNotFound          = errors.New("webhook not found")
+	ErrInvalidWebhookURL        = errors.New("invalid webhook URL")
+	ErrSubscriptionNotFound     = errors.New("subscription not found")
+	ErrInvalidSubscription      = errors.New("invalid subscription")
+	ErrInvalidWindow            = errors.New("invalid time window")
+	ErrInvalidCursor            = errors.New("invalid pagination cursor")
+	ErrInvalidOrganization      = errors.New("invalid organization name")
+	ErrInvalidSeverity          = errors.New("invalid severity, expected low, medium, high or critical")
+	ErrInvalidAlertKind         = errors.New("invalid alert kind, expected dependabot or code_scanning")
+	ErrInvalidRelation          = errors.New("invalid repository relation, expected owner, starred or contributor")
+	ErrInvalidItemType          = errors.New("invalid item type")
+	ErrInvalidCalendarEventType = errors.New("invalid calendar event type")
+	ErrAdminUnauthorized        = errors.New("invalid admin API key")
+	ErrAdminDisabled            = errors.New("admin operations are disabled: no admin API key is configured")
+	ErrJobNotFound              = errors.New("job not found")
+	ErrJobFinished              = errors.New("job already finished")
+	ErrSSONotConfigured         = errors.New("single sign-on is not configured")
+	ErrSessionRequired          = errors.New("a login session is required")
+	ErrWorkspaceNotFound        = errors.New("workspace not found")
+	ErrWorkspaceExists          = errors.New("workspace already exists")
+	ErrInvalidWorkspace         = errors.New("invalid workspace ID")
+	ErrWorkspaceTokenNotFound   = errors.New("workspace token not found")
+	ErrInvalidWorkspaceToken    = errors.New("invalid workspace token")
+	ErrQueryNotConfigured       = errors.New("natural-language queries are not configured")
+	ErrComplianceNotConfigured  = errors.New("compliance reporting is not enabled")
+	ErrCodeOwnersNotConfigured  = errors.New("code owners are not enabled")
+	ErrCodeOwnersUserNotSet     = errors.New("code_owners.user is not set")
+	ErrReviewerNotSet           = errors.New("no reviewer given and neither review_queue.user nor code_owners.user is set")
+	ErrSLANotConfigured         = errors.New("no SLA policies are configured")
+	ErrInvalidSLAPolicy         = errors.New("invalid SLA policy")
+	ErrSLAPolicyNotFound        = errors.New("SLA policy not found")
+	ErrFilesNotConfigured       = errors.New("pull request files are not synced")
+	ErrInvalidPathPattern       = errors.New("invalid path pattern")
+	ErrInvalidSize              = errors.New("invalid pull request size, expected XS, S, M, L, XL or XXL")
+	ErrInvalidAggregate         = errors.New("invalid aggregate, expected weekly or monthly")
+	ErrProjectsNotConfigured    = errors.New("projects are not synced")
+	ErrProjectNotFound          = errors.New("project not found")
+	ErrLabelsNotConfigured      = errors.New("labels are not synced")
+	ErrInvalidLabelChange       = errors.New("invalid label change, expected a new name or a hex color such as d73a4a")
+	ErrInvalidQuery             = errors.New("invalid query")
+	ErrInvalidClone             = errors.New("invalid local clone")
+	ErrInvalidDiffFormat        = errors.New("invalid diff format, expected diff or patch")
+	ErrInvalidPullRequest       = errors.New("invalid pull request, expected a title and a head branch")
+	ErrInvalidItemUpdate        = errors.New("invalid update, expected assignees to add or remove or a milestone")
+	ErrMilestoneNotFound        = errors.New("milestone not found")
+	ErrInvalidBulkAction        = errors.New("invalid bulk action, expected close, label with a label or comment with a body")
+	ErrInvalidBulkScope         = errors.New("invalid bulk filter, expected a repo, a repo tag or all repos")
+	ErrTriageRuleNotFound       = errors.New("triage rule not found")
+	ErrInvalidTriageRule        = errors.New("invalid triage rule")
+	ErrDuplicatesNotConfigured  = errors.New("duplicate detection is not enabled")
+	ErrInvalidReleaseNotesSince = errors.New("invalid since, expected a synced release tag or a date")
+	ErrQueryFailed              = errors.New("failed to translate query")
+	ErrWarehouseNotConfigured   = errors.New("no warehouse is configured")
 )
+
+// notFound returns errNotFound for a storage error of a missing record, and other storage
+// errors, which are failures rather than answers, as they are
+func notFound(err, errNotFound error) error {
+	if errors.Is(err, db.ErrNotFound) {
+		return errNotFound
+	}
+	return err
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"testing"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/db"
+	"github.com/siddontang/github-repos-management/internal/db/file"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// unavailableDB fails every repository and workspace read as storage that can't be read
+type unavailableDB struct {
+	db.DB
+}
+
+var errDiskFailure = fmt.Errorf("%w: read /var/lib/ghrepos/data.db: input/output error", db.ErrUnavailable)
+
+func (unavailableDB) GetRepository(ctx context.Context, owner, name string) (*models.Repository, error) {
+	return nil, errDiskFailure
+}
+
+func (unavailableDB) GetWorkspace(ctx context.Context, id string) (*models.Workspace, error) {
+	return nil, errDiskFailure
+}
+
+func TestStorageFailuresAreNotMissingRecords(t *testing.T) {
+	ctx := context.Background()
+	store, err := file.NewDB("")
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	s := &Service{db: store, config: &config.Config{}, logger: log.New(io.Discard, "", 0)}
+
+	if _, err := s.GetRepository(ctx, "org", "api"); !errors.Is(err, ErrRepositoryNotFound) {
+		t.Errorf("GetRepository(untracked) error = %v, want ErrRepositoryNotFound", err)
+	}
+	if _, err := s.GetWorkspace(ctx, "infra"); !errors.Is(err, ErrWorkspaceNotFound) {
+		t.Errorf("GetWorkspace(missing) error = %v, want ErrWorkspaceNotFound", err)
+	}
+
+	s.db = unavailableDB{store}
+	if _, err := s.GetRepository(ctx, "org", "api"); !errors.Is(err, db.ErrUnavailable) || errors.Is(err, ErrRepositoryNotFound) {
+		t.Errorf("GetRepository() on failing storage error = %v, want the storage error", err)
+	}
+	if _, err := s.AddRepository(ctx, "org/api"); !errors.Is(err, db.ErrUnavailable) {
+		t.Errorf("AddRepository() on failing storage error = %v, want the storage error", err)
+	}
+	if _, err := s.CreateWorkspace(ctx, "infra", "Infra", 0); !errors.Is(err, db.ErrUnavailable) || errors.Is(err, ErrWorkspaceExists) {
+		t.Errorf("CreateWorkspace() on failing storage error = %v, want the storage error", err)
+	}
+}
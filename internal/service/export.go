@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// defaultChangeLimit is the number of changes ListChanges returns without a limit
+const defaultChangeLimit = 1000
+
+// ListChanges returns the changes recorded in the activity log after sequence number since, oldest
+// first, and the sequence number to read the next changes from, so that consumers such as
+// warehouses can read the updates incrementally. Changes to pull requests and issues come with
+// the item as stored now, which may be newer than the change. Changes of repositories outside the
+// workspace are left out but still advance the sequence.
+func (s *Service) ListChanges(ctx context.Context, since int64, limit int) ([]*models.ChangeRecord, int64, error) {
+	if limit <= 0 {
+		limit = defaultChangeLimit
+	}
+	events, err := s.db.ListActivitySince(ctx, since, limit)
+	if err != nil {
+		return nil, since, fmt.Errorf("failed to list activity: %w", err)
+	}
+
+	records := make([]*models.ChangeRecord, 0, len(events))
+	next := since
+	for _, event := range events {
+		next = event.ID
+		if event.Repository != "" && s.checkWorkspace(ctx, event.Repository) != nil {
+			continue
+		}
+		record := &models.ChangeRecord{
+			Sequence:   event.ID,
+			Type:       event.Type,
+			Repository: event.Repository,
+			Number:     event.Number,
+			Time:       event.CreatedAt,
+			Event:      event,
+		}
+		if event.Number > 0 {
+			switch {
+			case strings.HasPrefix(event.Type, "pull_request."):
+				record.PullRequest, _ = s.db.GetPullRequest(ctx, event.Repository, event.Number)
+			case strings.HasPrefix(event.Type, "issue."):
+				record.Issue, _ = s.db.GetIssue(ctx, event.Repository, event.Number)
+			}
+		}
+		records = append(records, record)
+	}
+	return records, next, nil
+}
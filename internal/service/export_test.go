@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/db/file"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+func TestListChanges(t *testing.T) {
+	ctx := context.Background()
+	db, err := file.NewDB("")
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	s := &Service{db: db, config: &config.Config{}, logger: log.New(io.Discard, "", 0)}
+
+	now := time.Now()
+	if err := db.AddRepository(ctx, &models.Repository{Owner: "org", Name: "api", FullName: "org/api"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AddPullRequest(ctx, &models.PullRequest{RepositoryFullName: "org/api", Number: 1, Title: "Add API", State: "MERGED", UpdatedAt: now}); err != nil {
+		t.Fatal(err)
+	}
+	for _, event := range []*models.ActivityEvent{
+		{Type: "repository.added", Repository: "org/api", CreatedAt: now},
+		{Type: "pull_request.opened", Repository: "org/api", Number: 1, CreatedAt: now},
+		{Type: "issue.opened", Repository: "org/api", Number: 9, CreatedAt: now},
+		{Type: "pull_request.state_changed", Repository: "org/api", Number: 1, State: "MERGED", PreviousState: "OPEN", CreatedAt: now},
+	} {
+		if err := db.AppendActivity(ctx, event); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	records, next, err := s.ListChanges(ctx, 0, 3)
+	if err != nil {
+		t.Fatalf("ListChanges() error = %v", err)
+	}
+	if len(records) != 3 || next != 3 {
+		t.Fatalf("ListChanges(0, 3) = %d records, next %d; want 3 records, next 3", len(records), next)
+	}
+	for i, record := range records {
+		if record.Sequence != int64(i+1) {
+			t.Errorf("records[%d].Sequence = %d, want %d", i, record.Sequence, i+1)
+		}
+	}
+	if records[1].PullRequest == nil || records[1].PullRequest.Title != "Add API" {
+		t.Errorf("pull request change = %+v, want the stored pull request", records[1])
+	}
+	if records[2].Issue != nil {
+		t.Errorf("change of an issue that isn't stored = %+v, want no issue", records[2].Issue)
+	}
+
+	records, next, err = s.ListChanges(ctx, next, 0)
+	if err != nil {
+		t.Fatalf("ListChanges() error = %v", err)
+	}
+	if len(records) != 1 || next != 4 || records[0].Type != "pull_request.state_changed" || records[0].Event.PreviousState != "OPEN" {
+		t.Errorf("ListChanges(3) = %+v, next %d", records, next)
+	}
+
+	records, next, err = s.ListChanges(ctx, 4, 0)
+	if err != nil || len(records) != 0 || next != 4 {
+		t.Errorf("ListChanges(4) = %+v, next %d, %v; want none with the same sequence", records, next, err)
+	}
+}
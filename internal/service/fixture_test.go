@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"io"
+	"log"
+	"testing"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/db/file"
+	"github.com/siddontang/github-repos-management/internal/github"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// TestSyncFromFixtures tests adding and refreshing a repository served from the fixtures of testdata/github
+func TestSyncFromFixtures(t *testing.T) {
+	ctx := context.Background()
+	gh, err := github.LoadFixtures("testdata/github")
+	if err != nil {
+		t.Fatalf("LoadFixtures() error = %v", err)
+	}
+	db, err := file.NewDB("")
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	s, err := NewServiceWithOptions(&config.Config{}, Options{DB: db, GitHubClient: gh, Logger: log.New(io.Discard, "", 0)})
+	if err != nil {
+		t.Fatalf("NewServiceWithOptions() error = %v", err)
+	}
+	defer s.Close()
+
+	repo, err := s.AddRepository(ctx, "org/api")
+	if err != nil {
+		t.Fatalf("AddRepository() error = %v", err)
+	}
+	if repo.Description != "The API server" {
+		t.Errorf("repository = %+v, want the fixture's", repo)
+	}
+
+	prs, _, err := s.ListPullRequests(ctx, &models.PullRequestFilter{Repo: "org/api", State: "all", Page: 1, PerPage: 10})
+	if err != nil {
+		t.Fatalf("ListPullRequests() error = %v", err)
+	}
+	if len(prs) != 2 {
+		t.Fatalf("pull requests = %d, want both of the fixture", len(prs))
+	}
+	pr, err := s.GetPullRequest(ctx, "org", "api", 3)
+	if err != nil {
+		t.Fatalf("GetPullRequest() error = %v", err)
+	}
+	if pr.UserLogin != "alice" || pr.AuthorAssociation != "MEMBER" || len(pr.RequestedReviewers) != 1 {
+		t.Errorf("pull request 3 = %+v", pr)
+	}
+	if labels, _ := db.ListPullRequestLabels(ctx, "org/api", 3); len(labels) != 1 || labels[0].Name != "bug" {
+		t.Errorf("pull request 3 labels = %v, want bug", labels)
+	}
+	issues, _, err := s.ListIssues(ctx, &models.IssueFilter{Repo: "org/api", State: "open", Page: 1, PerPage: 10})
+	if err != nil {
+		t.Fatalf("ListIssues() error = %v", err)
+	}
+	if len(issues) != 1 || issues[0].Number != 1 {
+		t.Fatalf("open issues = %v, want issue 1", issues)
+	}
+
+	// Changes on GitHub show up after a refresh
+	if _, err := gh.UpdateIssue("org", "api", 1, &github.IssueUpdate{State: "closed"}); err != nil {
+		t.Fatalf("UpdateIssue() error = %v", err)
+	}
+	if err := gh.AddLabels("org", "api", 3, []string{"documentation"}); err != nil {
+		t.Fatalf("AddLabels() error = %v", err)
+	}
+	if _, err := gh.CreatePullRequest("org", "api", &github.PullRequestCreate{Title: "Add a rate limits page", Head: "docs"}); err != nil {
+		t.Fatalf("CreatePullRequest() error = %v", err)
+	}
+	if err := s.RefreshRepository(ctx, "org", "api"); err != nil {
+		t.Fatalf("RefreshRepository() error = %v", err)
+	}
+
+	if issue, _ := s.GetIssue(ctx, "org", "api", 1); issue == nil || issue.State != "CLOSED" {
+		t.Errorf("issue 1 = %+v, want it closed", issue)
+	}
+	if labels, _ := db.ListPullRequestLabels(ctx, "org/api", 3); len(labels) != 2 {
+		t.Errorf("pull request 3 labels = %v, want bug and documentation", labels)
+	}
+	if created, err := s.GetPullRequest(ctx, "org", "api", 5); err != nil || created.UserLogin != gh.Viewer {
+		t.Errorf("GetPullRequest(5) = %+v, %v, want the new pull request", created, err)
+	}
+
+	// Repositories missing from the fixtures are not found on GitHub
+	if _, err := s.AddRepository(ctx, "org/missing"); !github.IsNotFound(err) {
+		t.Errorf("AddRepository(org/missing) error = %v, want not found", err)
+	}
+}
+
+// TestRecordAndReplay tests that a sync recorded with a Recorder replays through github.replay
+func TestRecordAndReplay(t *testing.T) {
+	ctx := context.Background()
+	source, err := github.LoadFixtures("testdata/github")
+	if err != nil {
+		t.Fatalf("LoadFixtures() error = %v", err)
+	}
+	recorder := github.NewRecorder(source)
+	sync := func(cfg *config.Config, gh github.ClientInterface) *Service {
+		t.Helper()
+		db, err := file.NewDB("")
+		if err != nil {
+			t.Fatalf("NewDB() error = %v", err)
+		}
+		s, err := NewServiceWithOptions(cfg, Options{DB: db, GitHubClient: gh, Logger: log.New(io.Discard, "", 0)})
+		if err != nil {
+			t.Fatalf("NewServiceWithOptions() error = %v", err)
+		}
+		t.Cleanup(func() { s.Close() })
+		if _, err := s.AddRepository(ctx, "org/api"); err != nil {
+			t.Fatalf("AddRepository() error = %v", err)
+		}
+		return s
+	}
+	recorded := sync(&config.Config{}, recorder)
+	dir := t.TempDir()
+	if _, err := recorder.Save(dir); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	replayed := sync(&config.Config{GitHub: config.GitHubConfig{Replay: dir}}, nil)
+
+	filter := &models.PullRequestFilter{Repo: "org/api", State: "all", Page: 1, PerPage: 10}
+	want, _, _ := recorded.ListPullRequests(ctx, filter)
+	got, _, err := replayed.ListPullRequests(ctx, filter)
+	if err != nil {
+		t.Fatalf("ListPullRequests() error = %v", err)
+	}
+	if len(got) != len(want) || len(got) == 0 {
+		t.Fatalf("replayed pull requests = %d, want the %d recorded", len(got), len(want))
+	}
+	for i := range got {
+		if got[i].Number != want[i].Number || got[i].State != want[i].State || got[i].AuthorAssociation != want[i].AuthorAssociation {
+			t.Errorf("replayed pull request %+v, want %+v", got[i], want[i])
+		}
+	}
+
+	if _, err := NewServiceWithOptions(&config.Config{GitHub: config.GitHubConfig{Replay: t.TempDir()}}, Options{Logger: log.New(io.Discard, "", 0)}); err == nil {
+		t.Error("NewServiceWithOptions() should fail to replay a directory without fixtures")
+	}
+}
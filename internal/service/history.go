@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// appendTransition records a transition in history when the value changed.
+// Values are compared and stored in lower case.
+func appendTransition(history models.StateHistory, from, to string, at time.Time) models.StateHistory {
+	from, to = strings.ToLower(from), strings.ToLower(to)
+	if from == to {
+		return history
+	}
+	return append(history, models.StateTransition{From: from, To: to, At: at})
+}
+
+// transitionTime estimates when a state change observed during a sync happened
+func transitionTime(updatedAt time.Time, closedAt *time.Time, state string) time.Time {
+	if closedAt != nil && !strings.EqualFold(state, "open") {
+		return *closedAt
+	}
+	return updatedAt
+}
+
+// draftState names the draft status of a pull request for its state history
+func draftState(draft bool) string {
+	if draft {
+		return "draft"
+	}
+	return "ready"
+}
+
+// pullRequestHistory returns the state history of a synced pull request given its stored version
+func pullRequestHistory(existing, pr *models.PullRequest) models.StateHistory {
+	history := append(models.StateHistory(nil), existing.StateHistory...)
+	history = appendTransition(history, existing.State, pr.State, transitionTime(pr.UpdatedAt, pr.ClosedAt, pr.State))
+	return appendTransition(history, draftState(existing.Draft), draftState(pr.Draft), pr.UpdatedAt)
+}
+
+// issueHistory returns the state history of a synced issue given its stored version
+func issueHistory(existing, issue *models.Issue) models.StateHistory {
+	history := append(models.StateHistory(nil), existing.StateHistory...)
+	return appendTransition(history, existing.State, issue.State, transitionTime(issue.UpdatedAt, issue.ClosedAt, issue.State))
+}
+
+// GetPullRequest gets a pull request, including its state history and the items of tracked
+// repositories referencing it
+func (s *Service) GetPullRequest(ctx context.Context, owner, name string, number int) (*models.PullRequest, error) {
+	if err := s.checkRepositoryWorkspace(ctx, owner, name); err != nil {
+		return nil, err
+	}
+	pr, err := s.db.GetPullRequest(ctx, owner+"/"+name, number)
+	if err != nil {
+		return nil, notFound(err, ErrPullRequestNotFound)
+	}
+	clone := *pr
+	clone.ReferencedBy = s.referencedBy(ctx, pr.RepositoryFullName, pr.Number)
+	return &clone, nil
+}
+
+// GetIssue gets an issue, including its state history and the items of tracked repositories
+// referencing it
+func (s *Service) GetIssue(ctx context.Context, owner, name string, number int) (*models.Issue, error) {
+	if err := s.checkRepositoryWorkspace(ctx, owner, name); err != nil {
+		return nil, err
+	}
+	issue, err := s.db.GetIssue(ctx, owner+"/"+name, number)
+	if err != nil {
+		return nil, notFound(err, ErrIssueNotFound)
+	}
+	clone := *issue
+	clone.ReferencedBy = s.referencedBy(ctx, issue.RepositoryFullName, issue.Number)
+	return &clone, nil
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// TestPullRequestHistory tests the transitions recorded between two syncs of a pull request
+func TestPullRequestHistory(t *testing.T) {
+	now := time.Now()
+	closedAt := now.Add(-time.Hour)
+
+	existing := &models.PullRequest{State: "OPEN", Draft: true}
+	pr := &models.PullRequest{State: "CLOSED", UpdatedAt: now, ClosedAt: &closedAt}
+	history := pullRequestHistory(existing, pr)
+	if len(history) != 2 {
+		t.Fatalf("pullRequestHistory() = %v, want 2 transitions", history)
+	}
+	if history[0] != (models.StateTransition{From: "open", To: "closed", At: closedAt}) {
+		t.Errorf("pullRequestHistory()[0] = %v, want open -> closed at close time", history[0])
+	}
+	if history[1] != (models.StateTransition{From: "draft", To: "ready", At: now}) {
+		t.Errorf("pullRequestHistory()[1] = %v, want draft -> ready", history[1])
+	}
+
+	reopened := &models.PullRequest{State: "OPEN", UpdatedAt: now}
+	pr.StateHistory = history
+	history = pullRequestHistory(pr, reopened)
+	if got := history.Reopened(); got != 1 {
+		t.Errorf("Reopened() = %d, want 1", got)
+	}
+
+	reopened.StateHistory = history
+	if got := pullRequestHistory(reopened, reopened); len(got) != 3 {
+		t.Errorf("pullRequestHistory() without changes = %v, want history unchanged", got)
+	}
+}
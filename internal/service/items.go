@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// ListItems lists pull requests and issues together, each tagged with its type
+func (s *Service) ListItems(ctx context.Context, filter *models.ItemFilter) ([]*models.Item, *models.Pagination, error) {
+	if filter.Type != "" && filter.Type != models.ItemTypePullRequest && filter.Type != models.ItemTypeIssue {
+		return nil, nil, ErrInvalidItemType
+	}
+
+	repos, err := s.selectRepositories(ctx, filter.Repo, filter.RepoTag)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	query := &models.ItemQuery{
+		Repositories: queryRepositories(ctx, repos, filter.Repo, filter.RepoTag),
+		State:        stateFilter(filter.State),
+		Author:       filter.Author,
+		Label:        filter.Label,
+		UpdatedSince: filter.Since,
+	}
+
+	var items []*models.Item
+	if filter.Type != models.ItemTypeIssue {
+		if !filter.Cached {
+			s.refreshStalePullRequests(ctx, repos)
+		}
+		snap, err := s.db.Snapshot(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		prs, err := snap.FindPullRequests(ctx, query)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to find pull requests: %w", err)
+		}
+		prs = filterPullRequestAuthors(livePullRequests(prs), filter.ExcludeBots, filter.Association)
+		for _, pr := range filterPullRequestJira(s.filterPullRequestTeam(prs, filter.Team), filter.Jira) {
+			items = append(items, &models.Item{
+				Type:               models.ItemTypePullRequest,
+				RepositoryFullName: pr.RepositoryFullName,
+				Number:             pr.Number,
+				Title:              pr.Title,
+				State:              pr.State,
+				UserLogin:          pr.UserLogin,
+				UserIsBot:          pr.UserIsBot,
+				AuthorAssociation:  pr.AuthorAssociation,
+				HTMLURL:            pr.HTMLURL,
+				CreatedAt:          pr.CreatedAt,
+				UpdatedAt:          pr.UpdatedAt,
+			})
+		}
+	}
+	if filter.Type != models.ItemTypePullRequest {
+		if !filter.Cached {
+			s.refreshStaleIssues(ctx, repos)
+		}
+		snap, err := s.db.Snapshot(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		issues, err := snap.FindIssues(ctx, query)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to find issues: %w", err)
+		}
+		issues = filterIssueAuthors(liveIssues(issues), filter.ExcludeBots, filter.Association)
+		for _, issue := range filterIssueJira(s.filterIssueTeam(issues, filter.Team), filter.Jira) {
+			items = append(items, &models.Item{
+				Type:               models.ItemTypeIssue,
+				RepositoryFullName: issue.RepositoryFullName,
+				Number:             issue.Number,
+				Title:              issue.Title,
+				State:              issue.State,
+				UserLogin:          issue.UserLogin,
+				UserIsBot:          issue.UserIsBot,
+				AuthorAssociation:  issue.AuthorAssociation,
+				HTMLURL:            issue.HTMLURL,
+				CreatedAt:          issue.CreatedAt,
+				UpdatedAt:          issue.UpdatedAt,
+			})
+		}
+	}
+
+	// Pull requests and issues share numbers within a repository, so the usual ordering stays stable
+	itemKey := func(item *models.Item) cursorKey {
+		return cursorKey{CreatedAt: item.CreatedAt, Repo: item.RepositoryFullName, Number: item.Number}
+	}
+	less := itemLess(filter.Direction)
+	sort.Slice(items, func(i, j int) bool {
+		return less(itemKey(items[i]), itemKey(items[j]))
+	})
+
+	key := func(i int) cursorKey { return itemKey(items[i]) }
+	start, end, pagination, err := window(len(items), key, less, filter.Page, filter.PerPage, filter.Cursor)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return items[start:end], pagination, nil
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// jiraKeyPattern matches Jira issue keys such as PROJ-123 that are not part of a longer word
+var jiraKeyPattern = regexp.MustCompile(`(?:^|[^\w-])([A-Z][A-Z0-9_]+-[1-9][0-9]*)\b`)
+
+// jiraIgnoredPrefixes are uppercase prefixes of identifiers that look like Jira keys but aren't
+var jiraIgnoredPrefixes = map[string]bool{
+	"CVE": true, "GHSA": true, "RFC": true, "ISO": true, "UTF": true, "SHA": true, "HTTP": true, "TLS": true,
+}
+
+// parseJiraKeys returns the Jira issue keys in texts, in order of first appearance. Only keys of
+// projects are returned unless projects is empty.
+func parseJiraKeys(projects []string, texts ...string) []string {
+	var keys []string
+	seen := make(map[string]bool)
+	for _, text := range texts {
+		for _, match := range jiraKeyPattern.FindAllStringSubmatch(text, -1) {
+			key := match[1]
+			project := jiraProject(key)
+			if seen[key] || jiraIgnoredPrefixes[project] || !jiraProjectAllowed(projects, project) {
+				continue
+			}
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// jiraProject returns the project of a Jira issue key, such as PROJ for PROJ-123
+func jiraProject(key string) string {
+	project, _, _ := strings.Cut(key, "-")
+	return project
+}
+
+// jiraProjectAllowed reports whether keys of project are detected
+func jiraProjectAllowed(projects []string, project string) bool {
+	if len(projects) == 0 {
+		return true
+	}
+	for _, p := range projects {
+		if strings.EqualFold(p, project) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasJiraKey reports whether keys contain key, ignoring case
+func hasJiraKey(keys []string, key string) bool {
+	for _, k := range keys {
+		if strings.EqualFold(k, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterPullRequestJira keeps the pull requests referencing a Jira key; an empty key keeps all
+func filterPullRequestJira(prs []*models.PullRequest, key string) []*models.PullRequest {
+	if key == "" {
+		return prs
+	}
+	filtered := make([]*models.PullRequest, 0, len(prs))
+	for _, pr := range prs {
+		if hasJiraKey(pr.JiraKeys, key) {
+			filtered = append(filtered, pr)
+		}
+	}
+	return filtered
+}
+
+// filterIssueJira keeps the issues referencing a Jira key; an empty key keeps all
+func filterIssueJira(issues []*models.Issue, key string) []*models.Issue {
+	if key == "" {
+		return issues
+	}
+	filtered := make([]*models.Issue, 0, len(issues))
+	for _, issue := range issues {
+		if hasJiraKey(issue.JiraKeys, key) {
+			filtered = append(filtered, issue)
+		}
+	}
+	return filtered
+}
+
+// jiraURL returns the link to a Jira issue, or empty when no Jira base URL is configured
+func (s *Service) jiraURL(key string) string {
+	if s.config.Jira.BaseURL == "" {
+		return ""
+	}
+	return strings.TrimSuffix(s.config.Jira.BaseURL, "/") + "/browse/" + key
+}
+
+// ListJiraLinks lists the Jira issues referenced by the stored pull requests and issues of the
+// tracked repositories, each with the items referencing it, ordered by key
+func (s *Service) ListJiraLinks(ctx context.Context, filter *models.JiraLinkFilter) ([]*models.JiraLink, error) {
+	repos, err := s.selectRepositories(ctx, filter.Repo, filter.RepoTag)
+	if err != nil {
+		return nil, err
+	}
+	query := &models.ItemQuery{Repositories: queryRepositories(ctx, repos, filter.Repo, filter.RepoTag)}
+
+	links := make(map[string]*models.JiraLink)
+	add := func(keys []string, item *models.Item) {
+		for _, key := range keys {
+			if filter.Key != "" && !strings.EqualFold(key, filter.Key) {
+				continue
+			}
+			if filter.Project != "" && !strings.EqualFold(jiraProject(key), filter.Project) {
+				continue
+			}
+			link, ok := links[key]
+			if !ok {
+				link = &models.JiraLink{Key: key, URL: s.jiraURL(key)}
+				links[key] = link
+			}
+			link.Items = append(link.Items, item)
+		}
+	}
+
+	prs, err := s.db.FindPullRequests(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find pull requests: %w", err)
+	}
+	for _, pr := range livePullRequests(prs) {
+		add(pr.JiraKeys, &models.Item{
+			Type:               models.ItemTypePullRequest,
+			RepositoryFullName: pr.RepositoryFullName,
+			Number:             pr.Number,
+			Title:              pr.Title,
+			State:              pr.State,
+			UserLogin:          pr.UserLogin,
+			UserIsBot:          pr.UserIsBot,
+			AuthorAssociation:  pr.AuthorAssociation,
+			HTMLURL:            pr.HTMLURL,
+			CreatedAt:          pr.CreatedAt,
+			UpdatedAt:          pr.UpdatedAt,
+		})
+	}
+	issues, err := s.db.FindIssues(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find issues: %w", err)
+	}
+	for _, issue := range liveIssues(issues) {
+		add(issue.JiraKeys, &models.Item{
+			Type:               models.ItemTypeIssue,
+			RepositoryFullName: issue.RepositoryFullName,
+			Number:             issue.Number,
+			Title:              issue.Title,
+			State:              issue.State,
+			UserLogin:          issue.UserLogin,
+			UserIsBot:          issue.UserIsBot,
+			AuthorAssociation:  issue.AuthorAssociation,
+			HTMLURL:            issue.HTMLURL,
+			CreatedAt:          issue.CreatedAt,
+			UpdatedAt:          issue.UpdatedAt,
+		})
+	}
+
+	result := make([]*models.JiraLink, 0, len(links))
+	for _, link := range links {
+		sort.Slice(link.Items, func(i, j int) bool {
+			a, b := link.Items[i], link.Items[j]
+			if a.RepositoryFullName != b.RepositoryFullName {
+				return a.RepositoryFullName < b.RepositoryFullName
+			}
+			return a.Number < b.Number
+		})
+		result = append(result, link)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Key < result[j].Key
+	})
+	return result, nil
+}
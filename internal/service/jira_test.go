@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/db/file"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+func TestParseJiraKeys(t *testing.T) {
+	tests := []struct {
+		projects []string
+		texts    []string
+		want     []string
+	}{
+		{nil, []string{"PROJ-123: fix crash", "Also fixes PROJ-7 and (OPS-42); see PROJ-123"}, []string{"PROJ-123", "PROJ-7", "OPS-42"}},
+		{nil, []string{"Bump to UTF-8 and fix CVE-2024-1234 in SHA-256"}, nil},
+		{nil, []string{"not-PROJ-1, proj-2, PROJ-0 or https://x.atlassian.net/browse/WEB-9"}, []string{"WEB-9"}},
+		{[]string{"proj"}, []string{"PROJ-1 and OPS-2"}, []string{"PROJ-1"}},
+	}
+
+	for _, tt := range tests {
+		if got := parseJiraKeys(tt.projects, tt.texts...); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseJiraKeys(%v, %q) = %v, want %v", tt.projects, tt.texts, got, tt.want)
+		}
+	}
+}
+
+func TestListJiraLinks(t *testing.T) {
+	ctx := context.Background()
+	db, err := file.NewDB("")
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	if err := db.AddRepository(ctx, &models.Repository{Owner: "org", Name: "repo", FullName: "org/repo"}); err != nil {
+		t.Fatalf("AddRepository() error = %v", err)
+	}
+	for _, pr := range []*models.PullRequest{
+		{RepositoryFullName: "org/repo", Number: 3, State: "open", JiraKeys: []string{"PROJ-1", "OPS-2"}},
+		{RepositoryFullName: "org/repo", Number: 4, State: "open", JiraKeys: []string{"PROJ-1"}, Tombstoned: true},
+	} {
+		if err := db.AddPullRequest(ctx, pr); err != nil {
+			t.Fatalf("AddPullRequest() error = %v", err)
+		}
+	}
+	if err := db.AddIssue(ctx, &models.Issue{RepositoryFullName: "org/repo", Number: 1, State: "open", JiraKeys: []string{"PROJ-1"}}); err != nil {
+		t.Fatalf("AddIssue() error = %v", err)
+	}
+	s := &Service{db: db, config: &config.Config{Jira: config.JiraConfig{BaseURL: "https://example.atlassian.net/"}}}
+
+	links, err := s.ListJiraLinks(ctx, &models.JiraLinkFilter{})
+	if err != nil {
+		t.Fatalf("ListJiraLinks() error = %v", err)
+	}
+	if len(links) != 2 || links[0].Key != "OPS-2" || links[1].Key != "PROJ-1" {
+		t.Fatalf("ListJiraLinks() = %+v, want OPS-2 and PROJ-1", links)
+	}
+	proj := links[1]
+	if proj.URL != "https://example.atlassian.net/browse/PROJ-1" {
+		t.Errorf("PROJ-1 URL = %s", proj.URL)
+	}
+	// The tombstoned pull request is left out
+	if len(proj.Items) != 2 || proj.Items[0].Number != 1 || proj.Items[1].Number != 3 {
+		t.Errorf("PROJ-1 items = %+v, want issue 1 and pull request 3", proj.Items)
+	}
+
+	links, err = s.ListJiraLinks(ctx, &models.JiraLinkFilter{Project: "ops"})
+	if err != nil || len(links) != 1 || links[0].Key != "OPS-2" {
+		t.Errorf("ListJiraLinks() of project ops = %+v, %v, want OPS-2", links, err)
+	}
+
+	prs := filterPullRequestJira([]*models.PullRequest{{Number: 3, JiraKeys: []string{"OPS-2"}}, {Number: 5}}, "ops-2")
+	if len(prs) != 1 || prs[0].Number != 3 {
+		t.Errorf("filterPullRequestJira() = %v, want pull request 3", prs)
+	}
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/jobs"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// runSyncJob syncs the repository a sync job targets
+func (s *Service) runSyncJob(ctx context.Context, job *models.Job) error {
+	parts := strings.Split(job.Target, "/")
+	if len(parts) != 2 {
+		return ErrInvalidRepositoryName
+	}
+	owner, name := parts[0], parts[1]
+
+	s.logger.Printf("Syncing repository: %s (job %d, attempt %d)", job.Target, job.ID, job.Attempts)
+	return s.syncRepository(ctx, owner, name)
+}
+
+// GetJob returns the current status of a background job
+func (s *Service) GetJob(ctx context.Context, id int64) (*models.Job, error) {
+	job, err := s.jobs.Get(ctx, id)
+	if err != nil {
+		return nil, notFound(err, ErrJobNotFound)
+	}
+	if workspaceToken(ctx) && s.checkWorkspace(ctx, job.Target) != nil {
+		return nil, ErrJobNotFound
+	}
+	return job, nil
+}
+
+// ListJobs lists background jobs matching the filter, newest first
+func (s *Service) ListJobs(ctx context.Context, filter *models.JobFilter) ([]*models.Job, *models.Pagination, error) {
+	// Jobs span every workspace
+	if workspaceToken(ctx) {
+		return nil, nil, ErrSessionRequired
+	}
+
+	jobs, total, err := s.jobs.List(ctx, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pagination := &models.Pagination{
+		Page:       filter.Page,
+		PerPage:    filter.PerPage,
+		Total:      total,
+		TotalPages: (total + filter.PerPage - 1) / filter.PerPage,
+	}
+	return jobs, pagination, nil
+}
+
+// WaitJob waits until a background job finishes, ctx is done or the timeout elapses, then
+// returns its status. A zero timeout waits as long as ctx allows. The returned job is
+// still running when the wait ended first; check Done.
+func (s *Service) WaitJob(ctx context.Context, id int64, timeout time.Duration) (*models.Job, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	job, err := s.jobs.Wait(ctx, id)
+	if err != nil {
+		return nil, notFound(err, ErrJobNotFound)
+	}
+	return job, nil
+}
+
+// CancelJob stops a queued or running background job
+func (s *Service) CancelJob(ctx context.Context, id int64) (*models.Job, error) {
+	if _, err := s.GetJob(ctx, id); err != nil {
+		return nil, err
+	}
+
+	job, err := s.jobs.Cancel(ctx, id)
+	if errors.Is(err, jobs.ErrFinished) {
+		return nil, ErrJobFinished
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.audit(ctx, models.AuditJobCancel, fmt.Sprintf("job %d", id), job.Target)
+	return job, nil
+}
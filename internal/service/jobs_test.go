@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/db/file"
+	"github.com/siddontang/github-repos-management/internal/github"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+func TestStartRefreshReusesQueuedSync(t *testing.T) {
+	ctx := context.Background()
+	db, err := file.NewDB("")
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	if err := db.AddRepository(ctx, &models.Repository{Owner: "org", Name: "api", FullName: "org/api"}); err != nil {
+		t.Fatalf("AddRepository() error = %v", err)
+	}
+	s, err := NewServiceWithOptions(&config.Config{}, Options{DB: db, GitHubClient: starredGitHub{starred: new([]*github.Repository)}, Logger: log.New(io.Discard, "", 0)})
+	if err != nil {
+		t.Fatalf("NewServiceWithOptions() error = %v", err)
+	}
+	defer s.Close()
+
+	// A sync waiting for a worker
+	queued := &models.Job{Type: models.JobTypeSyncRepository, Target: "org/api", State: models.JobStateQueued, CreatedAt: time.Now()}
+	if err := db.AddJob(ctx, queued); err != nil {
+		t.Fatalf("AddJob() error = %v", err)
+	}
+
+	job, err := s.StartRefresh(ctx, "org", "api")
+	if err != nil {
+		t.Fatalf("StartRefresh() error = %v", err)
+	}
+	if job.ID != queued.ID {
+		t.Errorf("StartRefresh() = job %d, want the queued job %d", job.ID, queued.ID)
+	}
+	if _, total, _ := db.ListJobs(ctx, &models.JobFilter{Page: 1, PerPage: 10}); total != 1 {
+		t.Errorf("jobs = %d, want no new job", total)
+	}
+}
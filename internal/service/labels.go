@@ -0,0 +1,262 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/siddontang/github-repos-management/internal/github"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// labelColorPattern matches the hex colors of labels
+var labelColorPattern = regexp.MustCompile(`^[0-9a-f]{6}$`)
+
+// fetchLabels fetches the label set and issue templates of a repository
+func (s *Service) fetchLabels(ctx context.Context, owner, name string) ([]models.Label, []models.IssueTemplate, error) {
+	ghLabels, err := s.gh(ctx).ListLabels(owner, name)
+	if err != nil {
+		return nil, nil, err
+	}
+	ghTemplates, err := s.gh(ctx).ListIssueTemplates(owner, name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	labels := make([]models.Label, 0, len(ghLabels))
+	for _, l := range ghLabels {
+		labels = append(labels, models.Label{Name: l.Name, Color: strings.ToLower(l.Color), Description: l.Description})
+	}
+	templates := make([]models.IssueTemplate, 0, len(ghTemplates))
+	for _, t := range ghTemplates {
+		templates = append(templates, models.IssueTemplate{Name: t.Name, About: t.About, Title: t.Title, Filename: t.Filename, Labels: t.Labels})
+	}
+	return labels, templates, nil
+}
+
+// labelGroup collects the labels of the tracked repositories whose names only differ by case
+type labelGroup struct {
+	variants     map[string]*models.LabelVariant // By exact name and color
+	repositories map[string]bool
+	missing      []string // Issue templates applying the label where it doesn't exist
+}
+
+// LabelRegistry groups the labels of the tracked repositories matching the filter by name, ignoring
+// case, ordered by name. Labels differing in color or spelling across repositories, and labels
+// applied by issue templates of repositories lacking them, are reported as inconsistent.
+func (s *Service) LabelRegistry(ctx context.Context, filter *models.LabelFilter) ([]*models.LabelSummary, error) {
+	if !s.config.GitHub.SyncLabels {
+		return nil, ErrLabelsNotConfigured
+	}
+	repos, err := s.selectRepositories(ctx, filter.Repo, filter.RepoTag)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(repos, func(i, j int) bool { return repos[i].FullName < repos[j].FullName })
+
+	groups := make(map[string]*labelGroup)
+	group := func(name string) *labelGroup {
+		key := strings.ToLower(name)
+		g, ok := groups[key]
+		if !ok {
+			g = &labelGroup{variants: make(map[string]*models.LabelVariant), repositories: make(map[string]bool)}
+			groups[key] = g
+		}
+		return g
+	}
+	for _, repo := range repos {
+		for _, label := range repo.Labels {
+			g := group(label.Name)
+			g.repositories[repo.FullName] = true
+			key := label.Name + "\x00" + label.Color
+			variant, ok := g.variants[key]
+			if !ok {
+				variant = &models.LabelVariant{Name: label.Name, Color: label.Color, Description: label.Description}
+				g.variants[key] = variant
+			}
+			variant.Repositories = append(variant.Repositories, repo.FullName)
+		}
+	}
+	for _, repo := range repos {
+		for _, template := range repo.IssueTemplates {
+			for _, name := range template.Labels {
+				if g := group(name); !g.repositories[repo.FullName] {
+					g.missing = append(g.missing, fmt.Sprintf("missing from %s, used by its issue template %q", repo.FullName, template.Name))
+				}
+			}
+		}
+	}
+
+	summaries := make([]*models.LabelSummary, 0, len(groups))
+	for key, g := range groups {
+		if filter.Name != "" && key != strings.ToLower(filter.Name) {
+			continue
+		}
+		summary := summarizeLabels(key, g)
+		if filter.InconsistentOnly && len(summary.Inconsistencies) == 0 {
+			continue
+		}
+		summaries = append(summaries, summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return strings.ToLower(summaries[i].Name) < strings.ToLower(summaries[j].Name)
+	})
+	return summaries, nil
+}
+
+// summarizeLabels describes a label group, naming it by the spelling most repositories use and
+// the first in byte order on ties
+func summarizeLabels(key string, g *labelGroup) *models.LabelSummary {
+	summary := &models.LabelSummary{Name: key, Repositories: len(g.repositories), Variants: make([]*models.LabelVariant, 0, len(g.variants))}
+	spellings := make(map[string]int)
+	colors := make(map[string][]string)
+	for _, variant := range g.variants {
+		summary.Variants = append(summary.Variants, variant)
+		spellings[variant.Name] += len(variant.Repositories)
+		colors[variant.Color] = append(colors[variant.Color], variant.Repositories...)
+	}
+	sort.Slice(summary.Variants, func(i, j int) bool {
+		a, b := summary.Variants[i], summary.Variants[j]
+		if len(a.Repositories) != len(b.Repositories) {
+			return len(a.Repositories) > len(b.Repositories)
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Color < b.Color
+	})
+
+	best := -1
+	for spelling, n := range spellings {
+		if n > best || (n == best && spelling < summary.Name) {
+			summary.Name, best = spelling, n
+		}
+	}
+	if len(spellings) > 1 {
+		names := make([]string, 0, len(spellings))
+		for spelling := range spellings {
+			names = append(names, spelling)
+		}
+		sort.Strings(names)
+		summary.Inconsistencies = append(summary.Inconsistencies, fmt.Sprintf("%d spellings: %s", len(names), strings.Join(names, ", ")))
+	}
+	if len(colors) > 1 {
+		described := make([]string, 0, len(colors))
+		for color, repos := range colors {
+			sort.Strings(repos)
+			described = append(described, fmt.Sprintf("#%s (%s)", color, strings.Join(repos, ", ")))
+		}
+		sort.Strings(described)
+		summary.Inconsistencies = append(summary.Inconsistencies, fmt.Sprintf("%d colors: %s", len(colors), strings.Join(described, "; ")))
+	}
+	summary.Inconsistencies = append(summary.Inconsistencies, g.missing...)
+	return summary
+}
+
+// RenameLabel renames or recolors a label in the tracked repositories matching the request, or only
+// plans it with DryRun, returning the change of each repository ordered by name. Repositories lacking
+// the label, already matching the request or having another label with the new name are skipped, and
+// a failed update doesn't stop the others.
+func (s *Service) RenameLabel(ctx context.Context, rename *models.LabelRename) ([]*models.LabelChange, error) {
+	if !s.config.GitHub.SyncLabels {
+		return nil, ErrLabelsNotConfigured
+	}
+	color := strings.ToLower(strings.TrimPrefix(rename.Color, "#"))
+	if rename.Label == "" || (rename.NewName == "" && color == "") || (color != "" && !labelColorPattern.MatchString(color)) {
+		return nil, ErrInvalidLabelChange
+	}
+	repos, err := s.selectRepositories(ctx, rename.Repo, rename.RepoTag)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(repos, func(i, j int) bool { return repos[i].FullName < repos[j].FullName })
+
+	changes := make([]*models.LabelChange, 0, len(repos))
+	for _, repo := range repos {
+		change := planLabelChange(repo, rename.Label, rename.NewName, color)
+		changes = append(changes, change)
+		if rename.DryRun || change.Status != models.LabelChangePlanned {
+			continue
+		}
+
+		update := &github.LabelUpdate{NewName: change.NewName, Color: change.NewColor}
+		if _, err := s.gh(ctx).UpdateLabel(repo.Owner, repo.Name, change.Label, update); err != nil {
+			change.Status, change.Reason = models.LabelChangeFailed, err.Error()
+			continue
+		}
+		change.Status = models.LabelChangeUpdated
+		s.audit(ctx, models.AuditLabelUpdate, repo.FullName, describeLabelChange(change))
+
+		// Keep the registry current until the next sync fetches the labels again
+		if _, err := s.updateRepository(ctx, repo.Owner, repo.Name, func(r *models.Repository) bool {
+			for i := range r.Labels {
+				if r.Labels[i].Name == change.Label {
+					if change.NewName != "" {
+						r.Labels[i].Name = change.NewName
+					}
+					if change.NewColor != "" {
+						r.Labels[i].Color = change.NewColor
+					}
+					return true
+				}
+			}
+			return false
+		}); err != nil {
+			s.logger.Printf("Error updating labels of %s: %v", repo.FullName, err)
+		}
+	}
+	return changes, nil
+}
+
+// planLabelChange plans renaming a label of a repository to newName and recoloring it to color;
+// empty values keep the name or color
+func planLabelChange(repo *models.Repository, label, newName, color string) *models.LabelChange {
+	change := &models.LabelChange{Repository: repo.FullName, Status: models.LabelChangeSkipped}
+	var current *models.Label
+	for i := range repo.Labels {
+		if strings.EqualFold(repo.Labels[i].Name, label) {
+			current = &repo.Labels[i]
+			break
+		}
+	}
+	if current == nil {
+		change.Reason = "label not found"
+		return change
+	}
+	change.Label, change.Color = current.Name, current.Color
+
+	if newName != "" && newName != current.Name {
+		for _, other := range repo.Labels {
+			if other.Name != current.Name && strings.EqualFold(other.Name, newName) {
+				change.Reason = fmt.Sprintf("label %q already exists", other.Name)
+				return change
+			}
+		}
+		change.NewName = newName
+	}
+	if color != "" && color != current.Color {
+		change.NewColor = color
+	}
+	if change.NewName == "" && change.NewColor == "" {
+		change.Reason = "already up to date"
+		return change
+	}
+	change.Status = models.LabelChangePlanned
+	return change
+}
+
+// describeLabelChange describes a label change, such as "bug -> kind/bug, #d73a4a -> #b60205"
+func describeLabelChange(change *models.LabelChange) string {
+	var parts []string
+	if change.NewName != "" {
+		parts = append(parts, change.Label+" -> "+change.NewName)
+	} else {
+		parts = append(parts, change.Label)
+	}
+	if change.NewColor != "" {
+		parts = append(parts, "#"+change.Color+" -> #"+change.NewColor)
+	}
+	return strings.Join(parts, ", ")
+}
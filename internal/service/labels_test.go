@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"reflect"
+	"testing"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/db/file"
+	"github.com/siddontang/github-repos-management/internal/github"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+func TestLabelRegistry(t *testing.T) {
+	ctx := context.Background()
+	db, err := file.NewDB("")
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	repos := []*models.Repository{
+		{Owner: "org", Name: "api", FullName: "org/api", Labels: []models.Label{{Name: "bug", Color: "d73a4a"}, {Name: "docs", Color: "0075ca"}}},
+		{Owner: "org", Name: "cli", FullName: "org/cli", Labels: []models.Label{{Name: "bug", Color: "d73a4a"}, {Name: "Docs", Color: "0075ca"}}},
+		{Owner: "org", Name: "web", FullName: "org/web", Labels: []models.Label{{Name: "bug", Color: "ff0000"}},
+			IssueTemplates: []models.IssueTemplate{{Name: "Bug report", Labels: []string{"bug", "triage"}}}},
+	}
+	for _, repo := range repos {
+		if err := db.AddRepository(ctx, repo); err != nil {
+			t.Fatalf("AddRepository() error = %v", err)
+		}
+	}
+	s := &Service{db: db, config: &config.Config{GitHub: config.GitHubConfig{SyncLabels: true}}}
+
+	labels, err := s.LabelRegistry(ctx, &models.LabelFilter{})
+	if err != nil {
+		t.Fatalf("LabelRegistry() error = %v", err)
+	}
+	byName := make(map[string]*models.LabelSummary)
+	var names []string
+	for _, label := range labels {
+		byName[label.Name] = label
+		names = append(names, label.Name)
+	}
+	if want := []string{"bug", "Docs", "triage"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("labels = %v, want %v", names, want)
+	}
+	if want := []string{"2 colors: #d73a4a (org/api, org/cli); #ff0000 (org/web)"}; !reflect.DeepEqual(byName["bug"].Inconsistencies, want) {
+		t.Errorf("bug inconsistencies = %q, want %q", byName["bug"].Inconsistencies, want)
+	}
+	if byName["bug"].Repositories != 3 || len(byName["bug"].Variants) != 2 || byName["bug"].Variants[0].Color != "d73a4a" {
+		t.Errorf("bug = %d repositories and variants %+v, want 3 with the shared color first", byName["bug"].Repositories, byName["bug"].Variants)
+	}
+	if want := []string{"2 spellings: Docs, docs"}; !reflect.DeepEqual(byName["Docs"].Inconsistencies, want) {
+		t.Errorf("docs inconsistencies = %q, want %q", byName["Docs"].Inconsistencies, want)
+	}
+	if want := []string{`missing from org/web, used by its issue template "Bug report"`}; !reflect.DeepEqual(byName["triage"].Inconsistencies, want) {
+		t.Errorf("triage inconsistencies = %q, want %q", byName["triage"].Inconsistencies, want)
+	}
+
+	consistent, err := s.LabelRegistry(ctx, &models.LabelFilter{Repo: "org/api", InconsistentOnly: true})
+	if err != nil {
+		t.Fatalf("LabelRegistry(org/api) error = %v", err)
+	}
+	if len(consistent) != 0 {
+		t.Errorf("inconsistent labels of org/api alone = %d, want 0", len(consistent))
+	}
+
+	s.config.GitHub.SyncLabels = false
+	if _, err := s.LabelRegistry(ctx, &models.LabelFilter{}); !errors.Is(err, ErrLabelsNotConfigured) {
+		t.Errorf("LabelRegistry() without synced labels error = %v, want ErrLabelsNotConfigured", err)
+	}
+}
+
+// labelsGitHub records label updates, failing those of one repository
+type labelsGitHub struct {
+	github.ClientInterface
+	updated []string
+	failing string
+}
+
+func (g *labelsGitHub) UpdateLabel(owner, name, label string, update *github.LabelUpdate) (*github.Label, error) {
+	if owner+"/"+name == g.failing {
+		return nil, errors.New("HTTP 403")
+	}
+	g.updated = append(g.updated, owner+"/"+name+":"+label)
+	return &github.Label{Name: update.NewName, Color: update.Color}, nil
+}
+
+func TestRenameLabel(t *testing.T) {
+	ctx := context.Background()
+	db, err := file.NewDB("")
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	repos := []*models.Repository{
+		{Owner: "org", Name: "api", FullName: "org/api", Labels: []models.Label{{Name: "Bug", Color: "d73a4a"}}},
+		{Owner: "org", Name: "cli", FullName: "org/cli", Labels: []models.Label{{Name: "bug", Color: "d73a4a"}, {Name: "kind/bug", Color: "ff0000"}}},
+		{Owner: "org", Name: "docs", FullName: "org/docs"},
+		{Owner: "org", Name: "web", FullName: "org/web", Labels: []models.Label{{Name: "bug", Color: "ff0000"}}},
+	}
+	for _, repo := range repos {
+		if err := db.AddRepository(ctx, repo); err != nil {
+			t.Fatalf("AddRepository() error = %v", err)
+		}
+	}
+	gh := &labelsGitHub{failing: "org/web"}
+	s := &Service{db: db, ghClient: gh, config: &config.Config{GitHub: config.GitHubConfig{SyncLabels: true}}, logger: log.New(io.Discard, "", 0)}
+
+	statuses := func(changes []*models.LabelChange) []string {
+		var got []string
+		for _, change := range changes {
+			got = append(got, change.Repository+" "+change.Status)
+		}
+		return got
+	}
+
+	rename := &models.LabelRename{Label: "bug", NewName: "kind/bug", Color: "#D73A4A", DryRun: true}
+	plan, err := s.RenameLabel(ctx, rename)
+	if err != nil {
+		t.Fatalf("RenameLabel(dry run) error = %v", err)
+	}
+	want := []string{"org/api planned", "org/cli skipped", "org/docs skipped", "org/web planned"}
+	if got := statuses(plan); !reflect.DeepEqual(got, want) {
+		t.Errorf("plan = %v, want %v", got, want)
+	}
+	if len(gh.updated) != 0 {
+		t.Errorf("dry run updated %v", gh.updated)
+	}
+	if plan[3].NewColor != "d73a4a" || plan[0].NewColor != "" {
+		t.Errorf("planned colors = %q and %q, want only org/web recolored", plan[0].NewColor, plan[3].NewColor)
+	}
+
+	rename.DryRun = false
+	changes, err := s.RenameLabel(ctx, rename)
+	if err != nil {
+		t.Fatalf("RenameLabel() error = %v", err)
+	}
+	want = []string{"org/api updated", "org/cli skipped", "org/docs skipped", "org/web failed"}
+	if got := statuses(changes); !reflect.DeepEqual(got, want) {
+		t.Errorf("changes = %v, want %v", got, want)
+	}
+	if want := []string{"org/api:Bug"}; !reflect.DeepEqual(gh.updated, want) {
+		t.Errorf("updated = %v, want %v", gh.updated, want)
+	}
+	repo, err := db.GetRepository(ctx, "org", "api")
+	if err != nil {
+		t.Fatalf("GetRepository() error = %v", err)
+	}
+	if repo.Labels[0].Name != "kind/bug" {
+		t.Errorf("stored label = %q, want kind/bug", repo.Labels[0].Name)
+	}
+
+	if _, err := s.RenameLabel(ctx, &models.LabelRename{Label: "bug", Color: "red"}); !errors.Is(err, ErrInvalidLabelChange) {
+		t.Errorf("RenameLabel(color red) error = %v, want ErrInvalidLabelChange", err)
+	}
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// referencePattern matches references to items of other repositories, either owner/name#123 or
+// the GitHub URL of an issue or pull request
+var referencePattern = regexp.MustCompile(`(?:^|[^\w/.-])(?:https?://github\.com/)?([A-Za-z0-9](?:[A-Za-z0-9-]*[A-Za-z0-9])?)/([A-Za-z0-9_.-]+?)(?:#|/(?:issues|pull)/)([1-9][0-9]*)\b`)
+
+// parseReferences returns the items of other repositories referenced in texts, as
+// owner/name#number in order of first appearance. References to the repository itself are left out.
+func parseReferences(fullName string, texts ...string) []string {
+	var refs []string
+	seen := make(map[string]bool)
+	for _, text := range texts {
+		for _, match := range referencePattern.FindAllStringSubmatch(text, -1) {
+			repo := match[1] + "/" + match[2]
+			if strings.EqualFold(repo, fullName) {
+				continue
+			}
+			ref := repo + "#" + match[3]
+			if key := strings.ToLower(ref); !seen[key] {
+				seen[key] = true
+				refs = append(refs, ref)
+			}
+		}
+	}
+	return refs
+}
+
+// splitReference splits an owner/name#number reference into the repository and the number
+func splitReference(ref string) (string, string) {
+	repo, number, _ := strings.Cut(ref, "#")
+	return repo, number
+}
+
+// listItemLinks lists the references from the stored pull requests and issues of repos to items
+// of other tracked repositories
+func (s *Service) listItemLinks(ctx context.Context, repos []string) ([]*models.ItemLink, error) {
+	tracked, err := s.selectRepositories(ctx, "", "")
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]string, len(tracked))
+	for _, repo := range tracked {
+		names[strings.ToLower(repo.FullName)] = repo.FullName
+	}
+
+	var links []*models.ItemLink
+	add := func(from, fromType, title string, refs []string) {
+		for _, ref := range refs {
+			repo, number := splitReference(ref)
+			if name, ok := names[strings.ToLower(repo)]; ok {
+				links = append(links, &models.ItemLink{From: from, FromType: fromType, FromTitle: title, To: name + "#" + number})
+			}
+		}
+	}
+	query := &models.ItemQuery{Repositories: repos}
+	prs, err := s.db.FindPullRequests(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find pull requests: %w", err)
+	}
+	for _, pr := range livePullRequests(prs) {
+		add(fmt.Sprintf("%s#%d", pr.RepositoryFullName, pr.Number), models.ItemTypePullRequest, pr.Title, pr.References)
+	}
+	issues, err := s.db.FindIssues(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find issues: %w", err)
+	}
+	for _, issue := range liveIssues(issues) {
+		add(fmt.Sprintf("%s#%d", issue.RepositoryFullName, issue.Number), models.ItemTypeIssue, issue.Title, issue.References)
+	}
+	return links, nil
+}
+
+// referencedBy lists the items of tracked repositories referencing an item, ordered by reference
+func (s *Service) referencedBy(ctx context.Context, fullName string, number int) []string {
+	links, err := s.listItemLinks(ctx, nil)
+	if err != nil {
+		s.logger.Printf("Failed to list the references to %s#%d: %v", fullName, number, err)
+		return nil
+	}
+	target := strings.ToLower(fmt.Sprintf("%s#%d", fullName, number))
+	var refs []string
+	for _, link := range links {
+		if strings.ToLower(link.To) == target {
+			refs = append(refs, link.From)
+		}
+	}
+	sort.Strings(refs)
+	return refs
+}
+
+// LinkGraph returns the graph of references between the items of tracked repositories. With a
+// repository or tag, only the links from or to its repositories are kept.
+func (s *Service) LinkGraph(ctx context.Context, filter *models.LinkFilter) (*models.LinkGraph, error) {
+	repos, err := s.selectRepositories(ctx, filter.Repo, filter.RepoTag)
+	if err != nil {
+		return nil, err
+	}
+	selected := make(map[string]bool, len(repos))
+	for _, repo := range repos {
+		selected[strings.ToLower(repo.FullName)] = true
+	}
+
+	links, err := s.listItemLinks(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	graph := &models.LinkGraph{Repositories: []*models.RepositoryLink{}, Items: []*models.ItemLink{}}
+	edges := make(map[[2]string]*models.RepositoryLink)
+	for _, link := range links {
+		from, _ := splitReference(link.From)
+		to, _ := splitReference(link.To)
+		if !selected[strings.ToLower(from)] && !selected[strings.ToLower(to)] {
+			continue
+		}
+		graph.Items = append(graph.Items, link)
+		edge, ok := edges[[2]string{from, to}]
+		if !ok {
+			edge = &models.RepositoryLink{From: from, To: to}
+			edges[[2]string{from, to}] = edge
+			graph.Repositories = append(graph.Repositories, edge)
+		}
+		edge.References++
+	}
+
+	sort.Slice(graph.Items, func(i, j int) bool {
+		a, b := graph.Items[i], graph.Items[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		return a.To < b.To
+	})
+	sort.Slice(graph.Repositories, func(i, j int) bool {
+		a, b := graph.Repositories[i], graph.Repositories[j]
+		if a.References != b.References {
+			return a.References > b.References
+		}
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		return a.To < b.To
+	})
+	return graph, nil
+}
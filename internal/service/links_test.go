@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"io"
+	"log"
+	"reflect"
+	"testing"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/db/file"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+func TestParseReferences(t *testing.T) {
+	got := parseReferences("org/api", "Fix org/web#12 crash",
+		"See https://github.com/org/cli/issues/3, org/api#4, ORG/WEB#12 and https://github.com/org/web/pull/7. Not a ref: https://example.com/a/b#5")
+	want := []string{"org/web#12", "org/cli#3", "org/web#7"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseReferences() = %v, want %v", got, want)
+	}
+}
+
+func TestLinkGraph(t *testing.T) {
+	ctx := context.Background()
+	db, err := file.NewDB("")
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	for _, name := range []string{"api", "web", "cli"} {
+		if err := db.AddRepository(ctx, &models.Repository{Owner: "org", Name: name, FullName: "org/" + name}); err != nil {
+			t.Fatalf("AddRepository() error = %v", err)
+		}
+	}
+	for _, pr := range []*models.PullRequest{
+		{RepositoryFullName: "org/web", Number: 1, Title: "Use the new API", State: "OPEN", References: []string{"org/api#5", "other/lib#2"}},
+		{RepositoryFullName: "org/cli", Number: 2, Title: "Follow the API", State: "MERGED", References: []string{"ORG/API#5"}},
+	} {
+		if err := db.AddPullRequest(ctx, pr); err != nil {
+			t.Fatalf("AddPullRequest() error = %v", err)
+		}
+	}
+	for _, issue := range []*models.Issue{
+		{RepositoryFullName: "org/api", Number: 5, Title: "New API", State: "OPEN"},
+		{RepositoryFullName: "org/web", Number: 3, Title: "Blocked by the API", State: "OPEN", References: []string{"org/api#6"}},
+		{RepositoryFullName: "org/cli", Number: 4, Title: "Web parity", State: "OPEN", References: []string{"org/web#1"}},
+	} {
+		if err := db.AddIssue(ctx, issue); err != nil {
+			t.Fatalf("AddIssue() error = %v", err)
+		}
+	}
+	s := &Service{db: db, config: &config.Config{}, logger: log.New(io.Discard, "", 0)}
+
+	issue, err := s.GetIssue(ctx, "org", "api", 5)
+	if err != nil {
+		t.Fatalf("GetIssue() error = %v", err)
+	}
+	if want := []string{"org/cli#2", "org/web#1"}; !reflect.DeepEqual(issue.ReferencedBy, want) {
+		t.Errorf("ReferencedBy = %v, want %v", issue.ReferencedBy, want)
+	}
+	if stored, _ := db.GetIssue(ctx, "org/api", 5); stored.ReferencedBy != nil {
+		t.Errorf("stored ReferencedBy = %v, want it left out", stored.ReferencedBy)
+	}
+
+	graph, err := s.LinkGraph(ctx, &models.LinkFilter{Repo: "org/api"})
+	if err != nil {
+		t.Fatalf("LinkGraph() error = %v", err)
+	}
+	// The reference of org/cli#4 to org/web is left out, as is the one to an untracked repository
+	if len(graph.Items) != 3 {
+		t.Errorf("Items = %+v, want the 3 references to org/api", graph.Items)
+	}
+	want := []*models.RepositoryLink{
+		{From: "org/web", To: "org/api", References: 2},
+		{From: "org/cli", To: "org/api", References: 1},
+	}
+	if !reflect.DeepEqual(graph.Repositories, want) {
+		t.Errorf("Repositories = %+v, want %+v", graph.Repositories, want)
+	}
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/db/file"
+	"github.com/siddontang/github-repos-management/internal/github"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+func TestOffline(t *testing.T) {
+	ctx := context.Background()
+	db, err := file.NewDB("")
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	if err := db.AddRepository(ctx, &models.Repository{Owner: "org", Name: "api", FullName: "org/api"}); err != nil {
+		t.Fatalf("AddRepository() error = %v", err)
+	}
+
+	// The given client must never be called offline
+	cfg := &config.Config{
+		GitHub: config.GitHubConfig{Offline: true},
+		Cache:  config.CacheConfig{RepositoryTTL: time.Minute, PullRequestTTL: time.Minute},
+	}
+	s, err := NewServiceWithOptions(cfg, Options{DB: db, GitHubClient: starredGitHub{starred: new([]*github.Repository)}, Logger: log.New(io.Discard, "", 0)})
+	if err != nil {
+		t.Fatalf("NewServiceWithOptions() error = %v", err)
+	}
+	defer s.Close()
+
+	// Stale data is served as cached
+	repo, err := s.GetRepository(ctx, "org", "api")
+	if err != nil {
+		t.Fatalf("GetRepository() error = %v", err)
+	}
+	if !repo.MetadataSyncedAt.IsZero() {
+		t.Errorf("stale repository was re-fetched offline at %v", repo.MetadataSyncedAt)
+	}
+	if _, _, err := s.ListPullRequests(ctx, &models.PullRequestFilter{Page: 1, PerPage: 30}); err != nil {
+		t.Errorf("ListPullRequests() error = %v", err)
+	}
+
+	if _, err := s.StartRefresh(ctx, "org", "api"); !errors.Is(err, github.ErrOffline) {
+		t.Errorf("StartRefresh() error = %v, want ErrOffline", err)
+	}
+	if err := s.RefreshAll(ctx); !errors.Is(err, github.ErrOffline) {
+		t.Errorf("RefreshAll() error = %v, want ErrOffline", err)
+	}
+	if _, err := s.AddRepository(ctx, "org/web"); !errors.Is(err, github.ErrOffline) {
+		t.Errorf("AddRepository(untracked) error = %v, want ErrOffline", err)
+	}
+
+	status, err := s.GetStatus(ctx)
+	if err != nil {
+		t.Fatalf("GetStatus() error = %v", err)
+	}
+	if status["status"] != "offline" {
+		t.Errorf("status = %v, want offline", status["status"])
+	}
+	if usage := s.usage.Requests("org", "api"); usage != 0 {
+		t.Errorf("%d GitHub requests made offline, want none", usage)
+	}
+}
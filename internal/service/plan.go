@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// githubPageSize is the number of items gh fetches per API request
+const githubPageSize = 100
+
+// Reasons a repository is left out of a refresh
+const (
+	skipPaused = "paused"
+	skipNotDue = "not due"
+	skipBudget = "over rate limit budget"
+)
+
+// PlanRefresh reports what refreshing would fetch without contacting GitHub for any repository data.
+// An empty owner plans a refresh of every repository, like RefreshAll, or only of the repositories
+// whose sync interval has elapsed when dueOnly is set, like RefreshDue. Repositories are listed in
+// the order they would be synced.
+func (s *Service) PlanRefresh(ctx context.Context, owner, name string, dueOnly bool) (*models.RefreshPlan, error) {
+	if owner != "" {
+		repo, err := s.db.GetRepository(ctx, owner, name)
+		if err != nil {
+			return nil, notFound(err, ErrRepositoryNotFound)
+		}
+		if err := s.checkWorkspace(ctx, repo.FullName); err != nil {
+			return nil, err
+		}
+		return s.planRefresh(ctx, []*models.Repository{repo}, true, false), nil
+	}
+
+	repos, err := s.db.ListAllRepositories(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list repositories: %w", err)
+	}
+	if repos, err = s.scopeRepositories(ctx, repos); err != nil {
+		return nil, err
+	}
+	return s.planRefresh(ctx, repos, false, dueOnly), nil
+}
+
+// planRefresh plans syncs of repos in priority order. A single repository is refreshed even
+// when it is paused. Due refreshes only spend the remaining rate limit: repositories whose
+// estimated requests don't fit are skipped, leaving the budget to higher priority ones.
+func (s *Service) planRefresh(ctx context.Context, repos []*models.Repository, single, dueOnly bool) *models.RefreshPlan {
+	plan := &models.RefreshPlan{
+		Repositories: make([]*models.SyncPlan, 0, len(repos)),
+		RateLimit:    s.rateLimitStatus(ctx),
+	}
+
+	budget := -1
+	if dueOnly && plan.RateLimit != nil {
+		budget = plan.RateLimit.Remaining
+	}
+
+	intervals := s.workspaceIntervals(ctx)
+	now := time.Now()
+	for _, p := range s.prioritizeRepositories(ctx, repos) {
+		repo := p.repo
+		repoPlan := planSync(repo)
+		repoPlan.Priority = p.class
+		repoPlan.RecentChanges = p.recentChanges
+		switch {
+		case single:
+		case repo.Paused:
+			repoPlan.Skip = skipPaused
+		case dueOnly && now.Sub(repo.LastSyncedAt) < s.syncInterval(repo, intervals):
+			repoPlan.Skip = skipNotDue
+		case budget >= 0 && repoPlan.EstimatedRequests > budget:
+			repoPlan.Skip = skipBudget
+		}
+
+		if repoPlan.Skip == "" {
+			plan.EstimatedRequests += repoPlan.EstimatedRequests
+			plan.EstimatedItems += repoPlan.EstimatedItems
+			if budget >= 0 {
+				budget -= repoPlan.EstimatedRequests
+			}
+		}
+		plan.Repositories = append(plan.Repositories, repoPlan)
+	}
+
+	return plan
+}
+
+// rateLimitStatus returns the current rate limit, or nil when it can't be fetched.
+// Checking the rate limit is free, but plans are still useful without it.
+func (s *Service) rateLimitStatus(ctx context.Context) *models.RateLimitStatus {
+	rateLimit, err := s.gh(ctx).GetRateLimit()
+	if err != nil {
+		s.logger.Printf("Error getting rate limit for refresh plan: %v", err)
+		return nil
+	}
+	return &models.RateLimitStatus{
+		Limit:     rateLimit.Limit,
+		Remaining: rateLimit.Remaining,
+		ResetAt:   time.Unix(rateLimit.Reset, 0),
+	}
+}
+
+// planSync estimates the requests syncRepository makes for a repository: the pages of
+// pull requests and issues, plus one author association lookup for each of them and
+// the milestone and release listings synced with issues, and the pages of discussions of
+// repositories syncing them
+func planSync(repo *models.Repository) *models.SyncPlan {
+	limit := itemLimit(repo)
+	pages := (limit + githubPageSize - 1) / githubPageSize
+
+	plan := &models.SyncPlan{
+		FullName:     repo.FullName,
+		PullRequests: repo.SyncConfig.ShouldSyncPullRequests(),
+		Issues:       repo.SyncConfig.ShouldSyncIssues(),
+		ItemLimit:    limit,
+		LastSyncedAt: repo.LastSyncedAt,
+	}
+	if plan.PullRequests {
+		plan.Reviews = repo.SyncConfig.ShouldSyncReviews()
+		plan.EstimatedRequests += pages + 1
+		plan.EstimatedItems += limit
+	}
+	if plan.Issues {
+		plan.EstimatedRequests += pages + 1 + calendarRequests
+		plan.EstimatedItems += limit
+	}
+	if repo.SyncConfig.ShouldSyncDiscussions() {
+		plan.EstimatedRequests += pages
+		plan.EstimatedItems += limit
+	}
+	return plan
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+func TestPlanSync(t *testing.T) {
+	disabled := false
+
+	tests := []struct {
+		name         string
+		config       models.RepositorySyncConfig
+		wantRequests int
+		wantItems    int
+	}{
+		{"defaults", models.RepositorySyncConfig{}, 6, 200},
+		{"several pages", models.RepositorySyncConfig{ItemLimit: 250}, 10, 500},
+		{"issues only", models.RepositorySyncConfig{SyncPullRequests: &disabled}, 4, 100},
+		{"nothing to sync", models.RepositorySyncConfig{SyncPullRequests: &disabled, SyncIssues: &disabled}, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := planSync(&models.Repository{FullName: "pingcap/tidb", SyncConfig: tt.config})
+			if plan.EstimatedRequests != tt.wantRequests || plan.EstimatedItems != tt.wantItems {
+				t.Errorf("planSync() requests = %d, items = %d, want %d, %d", plan.EstimatedRequests, plan.EstimatedItems, tt.wantRequests, tt.wantItems)
+			}
+		})
+	}
+}
+
+func TestRefreshPlanExceedsRateLimit(t *testing.T) {
+	plan := &models.RefreshPlan{EstimatedRequests: 10}
+	if plan.ExceedsRateLimit() {
+		t.Errorf("ExceedsRateLimit() = true without a known rate limit")
+	}
+	plan.RateLimit = &models.RateLimitStatus{Limit: 5000, Remaining: 5}
+	if !plan.ExceedsRateLimit() {
+		t.Errorf("ExceedsRateLimit() = false with 5 requests remaining")
+	}
+}
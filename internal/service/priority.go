@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// churnWindow is how far back item updates count as recent activity of a repository
+const churnWindow = 7 * 24 * time.Hour
+
+// priorityRanks orders the sync priority classes; higher ranks are synced first
+var priorityRanks = map[string]int{
+	models.SyncPriorityLow:    0,
+	models.SyncPriorityNormal: 1,
+	models.SyncPriorityHigh:   2,
+}
+
+// prioritizedRepository is a repository with the facts its sync order is based on
+type prioritizedRepository struct {
+	repo          *models.Repository
+	class         string
+	recentChanges int
+}
+
+// prioritizeRepositories orders repositories for syncing: higher priority classes first and,
+// within a class, the repositories with the most pull requests and issues updated recently,
+// so the rate limit is spent on the data that goes stale fastest
+func (s *Service) prioritizeRepositories(ctx context.Context, repos []*models.Repository) []*prioritizedRepository {
+	since := time.Now().Add(-churnWindow)
+	prioritized := make([]*prioritizedRepository, 0, len(repos))
+	for _, repo := range repos {
+		prioritized = append(prioritized, &prioritizedRepository{
+			repo:          repo,
+			class:         repo.SyncConfig.PriorityClass(),
+			recentChanges: s.recentChanges(ctx, repo.FullName, since),
+		})
+	}
+
+	sort.SliceStable(prioritized, func(i, j int) bool {
+		a, b := prioritized[i], prioritized[j]
+		if priorityRanks[a.class] != priorityRanks[b.class] {
+			return priorityRanks[a.class] > priorityRanks[b.class]
+		}
+		return a.recentChanges > b.recentChanges
+	})
+	return prioritized
+}
+
+// recentChanges counts the stored pull requests and issues of a repository updated after since
+func (s *Service) recentChanges(ctx context.Context, fullName string, since time.Time) int {
+	changes := 0
+	if prs, err := s.db.ListAllPullRequests(ctx, fullName); err == nil {
+		for _, pr := range prs {
+			if pr.UpdatedAt.After(since) {
+				changes++
+			}
+		}
+	}
+	if issues, err := s.db.ListAllIssues(ctx, fullName); err == nil {
+		for _, issue := range issues {
+			if issue.UpdatedAt.After(since) {
+				changes++
+			}
+		}
+	}
+	return changes
+}
+
+// syncPriority is the job priority of a repository sync
+func syncPriority(repo *models.Repository) int {
+	return priorityRanks[repo.SyncConfig.PriorityClass()]
+}
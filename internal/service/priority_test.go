@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/db/file"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+func TestPrioritizeRepositories(t *testing.T) {
+	ctx := context.Background()
+	db, err := file.NewDB("")
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+
+	now := time.Now()
+	repos := []*models.Repository{
+		{FullName: "org/dormant"},
+		{FullName: "org/busy"},
+		{FullName: "org/pinned", SyncConfig: models.RepositorySyncConfig{Priority: models.SyncPriorityHigh}},
+		{FullName: "org/archive", SyncConfig: models.RepositorySyncConfig{Priority: models.SyncPriorityLow}},
+	}
+	items := []*models.PullRequest{
+		{RepositoryFullName: "org/busy", Number: 1, UpdatedAt: now},
+		{RepositoryFullName: "org/busy", Number: 2, UpdatedAt: now},
+		{RepositoryFullName: "org/dormant", Number: 1, UpdatedAt: now.Add(-30 * 24 * time.Hour)},
+		{RepositoryFullName: "org/archive", Number: 1, UpdatedAt: now},
+	}
+	for _, pr := range items {
+		if err := db.AddPullRequest(ctx, pr); err != nil {
+			t.Fatalf("AddPullRequest() error = %v", err)
+		}
+	}
+
+	s := &Service{db: db}
+	prioritized := s.prioritizeRepositories(ctx, repos)
+
+	want := []string{"org/pinned", "org/busy", "org/dormant", "org/archive"}
+	for i, p := range prioritized {
+		if p.repo.FullName != want[i] {
+			t.Fatalf("position %d = %s, want order %v", i, p.repo.FullName, want)
+		}
+	}
+	if prioritized[1].recentChanges != 2 || prioritized[2].recentChanges != 0 {
+		t.Errorf("recent changes = %d, %d, want 2, 0", prioritized[1].recentChanges, prioritized[2].recentChanges)
+	}
+}
@@ -0,0 +1,264 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// projectItemsPerSync is how many of the most recently updated issues, and as many pull requests,
+// have their project items synced
+const projectItemsPerSync = 100
+
+// syncProjects fetches the projects linked to a repository and the project items of its recently
+// updated issues and pull requests
+func (s *Service) syncProjects(ctx context.Context, owner, name string) error {
+	ghProjects, err := s.gh(ctx).ListProjects(owner, name)
+	if err != nil {
+		return err
+	}
+	ghItems, err := s.gh(ctx).ListProjectItems(owner, name, projectItemsPerSync)
+	if err != nil {
+		return err
+	}
+
+	projects := make([]*models.Project, 0, len(ghProjects))
+	for _, p := range ghProjects {
+		projects = append(projects, &models.Project{
+			Owner:   p.Owner,
+			Number:  p.Number,
+			Title:   p.Title,
+			URL:     p.URL,
+			Closed:  p.Closed,
+			Columns: p.Columns,
+		})
+	}
+	items := make([]*models.ProjectItem, 0, len(ghItems))
+	for _, i := range ghItems {
+		itemType := models.ItemTypeIssue
+		if i.PullRequest {
+			itemType = models.ItemTypePullRequest
+		}
+		items = append(items, &models.ProjectItem{
+			ProjectOwner:  i.ProjectOwner,
+			ProjectNumber: i.ProjectNumber,
+			Type:          itemType,
+			Number:        i.Number,
+			Title:         i.Title,
+			HTMLURL:       i.URL,
+			Status:        i.Status,
+		})
+	}
+	return s.db.ReplaceProjects(ctx, owner+"/"+name, projects, items)
+}
+
+// ListProjects lists the projects linked to the tracked repositories matching the filter, ordered
+// by owner and number, with the repositories they are linked to and their synced items by column
+func (s *Service) ListProjects(ctx context.Context, filter *models.ProjectFilter) ([]*models.Project, error) {
+	if !s.config.GitHub.SyncProjects {
+		return nil, ErrProjectsNotConfigured
+	}
+	repos, err := s.selectRepositories(ctx, filter.Repo, filter.RepoTag)
+	if err != nil {
+		return nil, err
+	}
+
+	projects, items, err := s.listSelectedProjects(ctx, repos)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*models.Project, 0, len(projects))
+	for _, project := range projects {
+		if project.Closed && !filter.IncludeClosed {
+			continue
+		}
+		project.Items = make(map[string]int)
+		for _, item := range items {
+			if item.ProjectOwner == project.Owner && item.ProjectNumber == project.Number {
+				project.Items[projectColumn(item)]++
+			}
+		}
+		result = append(result, project)
+	}
+	return result, nil
+}
+
+// GetProject returns a project linked to the tracked repositories and its synced items of those
+// repositories, only those in a column when status isn't empty
+func (s *Service) GetProject(ctx context.Context, owner string, number int, status string) (*models.Project, []*models.ProjectItem, error) {
+	if !s.config.GitHub.SyncProjects {
+		return nil, nil, ErrProjectsNotConfigured
+	}
+	repos, err := s.selectRepositories(ctx, "", "")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	projects, items, err := s.listSelectedProjects(ctx, repos)
+	if err != nil {
+		return nil, nil, err
+	}
+	var project *models.Project
+	for _, p := range projects {
+		if strings.EqualFold(p.Owner, owner) && p.Number == number {
+			project = p
+			break
+		}
+	}
+	if project == nil {
+		return nil, nil, fmt.Errorf("%w: %s/%d", ErrProjectNotFound, owner, number)
+	}
+
+	project.Items = make(map[string]int)
+	projectItems := make([]*models.ProjectItem, 0)
+	for _, item := range items {
+		if item.ProjectOwner != project.Owner || item.ProjectNumber != project.Number {
+			continue
+		}
+		project.Items[projectColumn(item)]++
+		if status == "" || strings.EqualFold(projectColumn(item), status) {
+			projectItems = append(projectItems, item)
+		}
+	}
+	return project, projectItems, nil
+}
+
+// listSelectedProjects lists the projects linked to some repositories, merging those linked to
+// several of them, and the project items of those repositories
+func (s *Service) listSelectedProjects(ctx context.Context, repos []*models.Repository) ([]*models.Project, []*models.ProjectItem, error) {
+	selected := make(map[string]bool, len(repos))
+	for _, repo := range repos {
+		selected[repo.FullName] = true
+	}
+
+	stored, err := s.db.ListProjects(ctx, "")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list projects: %w", err)
+	}
+	byKey := make(map[string]*models.Project)
+	projects := make([]*models.Project, 0)
+	for _, project := range stored {
+		if !selected[project.Repositories[0]] {
+			continue
+		}
+		key := projectKey(project.Owner, project.Number)
+		if merged, ok := byKey[key]; ok {
+			merged.Repositories = append(merged.Repositories, project.Repositories...)
+			continue
+		}
+		byKey[key] = project
+		projects = append(projects, project)
+	}
+	sort.Slice(projects, func(i, j int) bool {
+		if projects[i].Owner != projects[j].Owner {
+			return projects[i].Owner < projects[j].Owner
+		}
+		return projects[i].Number < projects[j].Number
+	})
+
+	storedItems, err := s.db.ListProjectItems(ctx, "")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list project items: %w", err)
+	}
+	items := make([]*models.ProjectItem, 0, len(storedItems))
+	for _, item := range storedItems {
+		if selected[item.RepositoryFullName] {
+			items = append(items, item)
+		}
+	}
+	return projects, items, nil
+}
+
+// projectKey identifies a project by its owner and number
+func projectKey(owner string, number int) string {
+	return strings.ToLower(owner) + "/" + strconv.Itoa(number)
+}
+
+// projectColumn returns the column of a project item
+func projectColumn(item *models.ProjectItem) string {
+	if item.Status == "" {
+		return models.ProjectNoStatus
+	}
+	return item.Status
+}
+
+// projectItemMatcher returns whether the items of a repository, by type and number, are on a project
+// and in a column. The project is given by owner/number or title; empty values match any.
+func (s *Service) projectItemMatcher(ctx context.Context, project, status string) (func(repoFullName, itemType string, number int) bool, error) {
+	if !s.config.GitHub.SyncProjects {
+		return nil, ErrProjectsNotConfigured
+	}
+
+	var projects map[string]bool
+	if project != "" {
+		stored, err := s.db.ListProjects(ctx, "")
+		if err != nil {
+			return nil, fmt.Errorf("failed to list projects: %w", err)
+		}
+		projects = make(map[string]bool)
+		for _, p := range stored {
+			key := projectKey(p.Owner, p.Number)
+			if strings.EqualFold(project, key) || strings.EqualFold(project, p.Title) {
+				projects[key] = true
+			}
+		}
+	}
+
+	items, err := s.db.ListProjectItems(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list project items: %w", err)
+	}
+	matched := make(map[string]bool)
+	for _, item := range items {
+		if projects != nil && !projects[projectKey(item.ProjectOwner, item.ProjectNumber)] {
+			continue
+		}
+		if status != "" && !strings.EqualFold(projectColumn(item), status) {
+			continue
+		}
+		matched[fmt.Sprintf("%s/%s/%d", item.RepositoryFullName, item.Type, item.Number)] = true
+	}
+	return func(repoFullName, itemType string, number int) bool {
+		return matched[fmt.Sprintf("%s/%s/%d", repoFullName, itemType, number)]
+	}, nil
+}
+
+// filterPullRequestProjects keeps the pull requests on a project and in a column; empty values keep all
+func (s *Service) filterPullRequestProjects(ctx context.Context, prs []*models.PullRequest, project, status string) ([]*models.PullRequest, error) {
+	if project == "" && status == "" {
+		return prs, nil
+	}
+	match, err := s.projectItemMatcher(ctx, project, status)
+	if err != nil {
+		return nil, err
+	}
+	filtered := make([]*models.PullRequest, 0, len(prs))
+	for _, pr := range prs {
+		if match(pr.RepositoryFullName, models.ItemTypePullRequest, pr.Number) {
+			filtered = append(filtered, pr)
+		}
+	}
+	return filtered, nil
+}
+
+// filterIssueProjects keeps the issues on a project and in a column; empty values keep all
+func (s *Service) filterIssueProjects(ctx context.Context, issues []*models.Issue, project, status string) ([]*models.Issue, error) {
+	if project == "" && status == "" {
+		return issues, nil
+	}
+	match, err := s.projectItemMatcher(ctx, project, status)
+	if err != nil {
+		return nil, err
+	}
+	filtered := make([]*models.Issue, 0, len(issues))
+	for _, issue := range issues {
+		if match(issue.RepositoryFullName, models.ItemTypeIssue, issue.Number) {
+			filtered = append(filtered, issue)
+		}
+	}
+	return filtered, nil
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"reflect"
+	"testing"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/db/file"
+	"github.com/siddontang/github-repos-management/internal/github"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// projectsGitHub links the same board to every repository; items are served per repository
+type projectsGitHub struct {
+	github.ClientInterface
+	items map[string][]*github.ProjectItem
+}
+
+func (g projectsGitHub) ListProjects(owner, name string) ([]*github.Project, error) {
+	return []*github.Project{{Owner: "org", Number: 7, Title: "Roadmap", Columns: []string{"Todo", "In Review", "Done"}}}, nil
+}
+
+func (g projectsGitHub) ListProjectItems(owner, name string, limit int) ([]*github.ProjectItem, error) {
+	return g.items[owner+"/"+name], nil
+}
+
+func TestProjects(t *testing.T) {
+	ctx := context.Background()
+	db, err := file.NewDB("")
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	for _, name := range []string{"api", "web"} {
+		if err := db.AddRepository(ctx, &models.Repository{Owner: "org", Name: name, FullName: "org/" + name}); err != nil {
+			t.Fatalf("AddRepository() error = %v", err)
+		}
+	}
+
+	gh := projectsGitHub{items: map[string][]*github.ProjectItem{
+		"org/api": {
+			{ProjectOwner: "org", ProjectNumber: 7, PullRequest: true, Number: 1, Status: "In Review"},
+			{ProjectOwner: "org", ProjectNumber: 7, Number: 2, Status: "Todo"},
+		},
+		"org/web": {
+			{ProjectOwner: "org", ProjectNumber: 7, PullRequest: true, Number: 1},
+			{ProjectOwner: "org", ProjectNumber: 7, Number: 2, Status: "In Review"},
+		},
+	}}
+	cfg := &config.Config{GitHub: config.GitHubConfig{SyncProjects: true}}
+	s := &Service{db: db, ghClient: gh, config: cfg, logger: log.New(io.Discard, "", 0)}
+	for _, name := range []string{"api", "web"} {
+		if err := s.syncProjects(ctx, "org", name); err != nil {
+			t.Fatalf("syncProjects(%s) error = %v", name, err)
+		}
+	}
+
+	projects, err := s.ListProjects(ctx, &models.ProjectFilter{})
+	if err != nil {
+		t.Fatalf("ListProjects() error = %v", err)
+	}
+	if len(projects) != 1 {
+		t.Fatalf("ListProjects() = %d projects, want the shared board once", len(projects))
+	}
+	if want := []string{"org/api", "org/web"}; !reflect.DeepEqual(projects[0].Repositories, want) {
+		t.Errorf("repositories = %v, want %v", projects[0].Repositories, want)
+	}
+	if want := map[string]int{"In Review": 2, "Todo": 1, models.ProjectNoStatus: 1}; !reflect.DeepEqual(projects[0].Items, want) {
+		t.Errorf("items = %v, want %v", projects[0].Items, want)
+	}
+
+	project, items, err := s.GetProject(ctx, "ORG", 7, "in review")
+	if err != nil {
+		t.Fatalf("GetProject() error = %v", err)
+	}
+	if project.Title != "Roadmap" || len(items) != 2 {
+		t.Errorf("GetProject() = %q with %d items, want Roadmap with 2", project.Title, len(items))
+	}
+	if _, _, err := s.GetProject(ctx, "org", 8, ""); !errors.Is(err, ErrProjectNotFound) {
+		t.Errorf("GetProject(unknown) error = %v, want ErrProjectNotFound", err)
+	}
+
+	// Pull requests and issues are filtered by project and column without mixing up their numbers
+	prs := []*models.PullRequest{{RepositoryFullName: "org/api", Number: 1}, {RepositoryFullName: "org/api", Number: 2}, {RepositoryFullName: "org/web", Number: 1}}
+	filtered, err := s.filterPullRequestProjects(ctx, prs, "Roadmap", "In Review")
+	if err != nil {
+		t.Fatalf("filterPullRequestProjects() error = %v", err)
+	}
+	if len(filtered) != 1 || filtered[0].RepositoryFullName != "org/api" || filtered[0].Number != 1 {
+		t.Errorf("pull requests in review = %v, want org/api#1", filtered)
+	}
+	issues := []*models.Issue{{RepositoryFullName: "org/api", Number: 2}, {RepositoryFullName: "org/web", Number: 2}}
+	filteredIssues, err := s.filterIssueProjects(ctx, issues, "org/7", "")
+	if err != nil {
+		t.Fatalf("filterIssueProjects() error = %v", err)
+	}
+	if len(filteredIssues) != 2 {
+		t.Errorf("issues on org/7 = %d, want 2", len(filteredIssues))
+	}
+
+	s.config.GitHub.SyncProjects = false
+	if _, err := s.filterIssueProjects(ctx, issues, "", "Todo"); !errors.Is(err, ErrProjectsNotConfigured) {
+		t.Errorf("filter without synced projects error = %v, want ErrProjectsNotConfigured", err)
+	}
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/siddontang/github-repos-management/internal/github"
+	"github.com/siddontang/github-repos-management/internal/models"
+	"github.com/siddontang/github-repos-management/internal/notify"
+)
+
+// CreatePullRequest opens a pull request in a tracked repository and stores it right away, without
+// waiting for the next sync. The base defaults to the repository's default branch.
+func (s *Service) CreatePullRequest(ctx context.Context, owner, name string, create *models.PullRequestCreate) (*models.PullRequest, error) {
+	title, head := strings.TrimSpace(create.Title), strings.TrimSpace(create.Head)
+	if title == "" || head == "" {
+		return nil, ErrInvalidPullRequest
+	}
+	repo, err := s.db.GetRepository(ctx, owner, name)
+	if err != nil {
+		return nil, notFound(err, ErrRepositoryNotFound)
+	}
+	if err := s.checkWorkspace(ctx, repo.FullName); err != nil {
+		return nil, err
+	}
+
+	base := strings.TrimSpace(create.Base)
+	if base == "" && repo.Settings != nil {
+		base = repo.Settings.DefaultBranch
+	}
+
+	ghPR, err := s.gh(ctx).CreatePullRequest(repo.Owner, repo.Name, &github.PullRequestCreate{Title: title, Body: create.Body, Base: base, Head: head})
+	if err != nil {
+		return nil, err
+	}
+	pr := s.pullRequestModel(repo.FullName, ghPR, "")
+	if err := s.db.AddPullRequest(ctx, pr); err != nil {
+		return nil, fmt.Errorf("failed to store pull request #%d: %w", pr.Number, err)
+	}
+	s.audit(ctx, models.AuditPullRequestCreate, fmt.Sprintf("%s#%d", repo.FullName, pr.Number), head)
+	s.notifyPullRequest(ctx, notify.EventPullRequestOpened, pr)
+	return pr, nil
+}
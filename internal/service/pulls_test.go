@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/db/file"
+	"github.com/siddontang/github-repos-management/internal/github"
+	"github.com/siddontang/github-repos-management/internal/models"
+	"github.com/siddontang/github-repos-management/internal/notify"
+)
+
+// creatingGitHub opens pull requests numbered from 100, remembering the last request
+type creatingGitHub struct {
+	github.ClientInterface
+	created *github.PullRequestCreate
+}
+
+func (g *creatingGitHub) CreatePullRequest(owner, name string, create *github.PullRequestCreate) (*github.PullRequest, error) {
+	g.created = create
+	return &github.PullRequest{
+		Number:    100,
+		Title:     create.Title,
+		Body:      create.Body,
+		State:     "OPEN",
+		HTMLURL:   "https://github.com/" + owner + "/" + name + "/pull/100",
+		User:      github.User{Login: "alice"},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}, nil
+}
+
+func TestCreatePullRequest(t *testing.T) {
+	ctx := context.Background()
+	db, err := file.NewDB("")
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	repo := &models.Repository{Owner: "org", Name: "api", FullName: "org/api", Settings: &models.RepositorySettings{DefaultBranch: "main"}}
+	if err := db.AddRepository(ctx, repo); err != nil {
+		t.Fatalf("AddRepository() error = %v", err)
+	}
+	gh := &creatingGitHub{}
+	logger := log.New(io.Discard, "", 0)
+	s := &Service{db: db, ghClient: gh, config: &config.Config{}, logger: logger, notifier: &notify.Dispatcher{}}
+
+	pr, err := s.CreatePullRequest(ctx, "org", "api", &models.PullRequestCreate{Title: " Add retries ", Body: "Fixes #1", Head: "retries"})
+	if err != nil {
+		t.Fatalf("CreatePullRequest() error = %v", err)
+	}
+	if gh.created.Base != "main" || gh.created.Title != "Add retries" {
+		t.Errorf("created %+v, want the title trimmed and the default branch as base", gh.created)
+	}
+	stored, err := s.GetPullRequest(ctx, "org", "api", pr.Number)
+	if err != nil {
+		t.Fatalf("GetPullRequest() error = %v, want the new pull request stored", err)
+	}
+	if stored.State != "OPEN" || stored.UserLogin != "alice" {
+		t.Errorf("stored pull request = %s by %s, want OPEN by alice", stored.State, stored.UserLogin)
+	}
+
+	if _, err := s.CreatePullRequest(ctx, "org", "api", &models.PullRequestCreate{Title: "No head"}); !errors.Is(err, ErrInvalidPullRequest) {
+		t.Errorf("CreatePullRequest(no head) error = %v, want ErrInvalidPullRequest", err)
+	}
+	if _, err := s.CreatePullRequest(ctx, "org", "web", &models.PullRequestCreate{Title: "Untracked", Head: "x"}); !errors.Is(err, ErrRepositoryNotFound) {
+		t.Errorf("CreatePullRequest(untracked) error = %v, want ErrRepositoryNotFound", err)
+	}
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+	"github.com/siddontang/github-repos-management/internal/tracing"
+)
+
+// QueryItems answers a natural-language question such as "open PRs by alice labeled bug in tidb".
+// The configured backend translates the question into an item filter, which is returned together
+// with the page of matching items.
+func (s *Service) QueryItems(ctx context.Context, question string, page, perPage int) (*models.ItemFilter, []*models.Item, *models.Pagination, error) {
+	if s.translator == nil {
+		return nil, nil, nil, ErrQueryNotConfigured
+	}
+	question = strings.TrimSpace(question)
+	if question == "" {
+		return nil, nil, nil, fmt.Errorf("%w: question is empty", ErrInvalidQuery)
+	}
+
+	translateCtx, span := tracing.Start(ctx, "nlquery.Translate")
+	query, err := s.translator.Translate(translateCtx, question)
+	tracing.RecordError(span, err)
+	span.End()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("%w: %v", ErrQueryFailed, err)
+	}
+
+	filter := &models.ItemFilter{
+		Author:  strings.TrimPrefix(strings.TrimSpace(query.Author), "@"),
+		RepoTag: strings.TrimSpace(query.RepoTag),
+		Label:   strings.TrimSpace(query.Label),
+		Team:    strings.TrimSpace(query.Team),
+		Jira:    strings.ToUpper(strings.TrimSpace(query.Jira)),
+		Page:    page,
+		PerPage: perPage,
+	}
+
+	switch strings.ToLower(strings.TrimSpace(query.Type)) {
+	case "":
+	case models.ItemTypePullRequest, "pr", "pull", "pulls", "pull_requests":
+		filter.Type = models.ItemTypePullRequest
+	case models.ItemTypeIssue, "issues":
+		filter.Type = models.ItemTypeIssue
+	default:
+		return nil, nil, nil, fmt.Errorf("%w: unknown item type %q", ErrInvalidQuery, query.Type)
+	}
+
+	switch state := strings.ToLower(strings.TrimSpace(query.State)); state {
+	case "", "open", "closed", "merged", "all":
+		filter.State = state
+	default:
+		return nil, nil, nil, fmt.Errorf("%w: unknown state %q", ErrInvalidQuery, query.State)
+	}
+
+	if since := strings.TrimSpace(query.Since); since != "" {
+		if filter.Since, err = time.ParseInLocation("2006-01-02", since, time.Local); err != nil {
+			return nil, nil, nil, fmt.Errorf("%w: since must be YYYY-MM-DD, got %q", ErrInvalidQuery, query.Since)
+		}
+	}
+
+	if filter.Repo, err = s.resolveRepositoryName(ctx, strings.TrimSpace(query.Repo)); err != nil {
+		return nil, nil, nil, err
+	}
+
+	items, pagination, err := s.ListItems(ctx, filter)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return filter, items, pagination, nil
+}
+
+// resolveRepositoryName returns the full name of a tracked repository named in a question,
+// which often leaves out the owner ("in tidb" rather than "in pingcap/tidb")
+func (s *Service) resolveRepositoryName(ctx context.Context, name string) (string, error) {
+	if name == "" || strings.Contains(name, "/") {
+		return name, nil
+	}
+
+	repos, err := s.db.ListAllRepositories(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to list repositories: %w", err)
+	}
+
+	var matches []string
+	for _, repo := range repos {
+		if strings.EqualFold(repo.Name, name) {
+			matches = append(matches, repo.FullName)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("%w: %s", ErrRepositoryNotFound, name)
+	case 1:
+		return matches[0], nil
+	}
+	return "", fmt.Errorf("%w: %s matches %s", ErrInvalidQuery, name, strings.Join(matches, ", "))
+}
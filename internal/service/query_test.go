@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/db/file"
+	"github.com/siddontang/github-repos-management/internal/models"
+	"github.com/siddontang/github-repos-management/internal/nlquery"
+)
+
+// fakeTranslator answers every question with the same query
+type fakeTranslator struct {
+	query *nlquery.Query
+	err   error
+}
+
+func (f *fakeTranslator) Translate(ctx context.Context, question string) (*nlquery.Query, error) {
+	return f.query, f.err
+}
+
+func TestQueryItems(t *testing.T) {
+	ctx := context.Background()
+	db, err := file.NewDB("")
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	for _, repo := range []*models.Repository{
+		{Owner: "pingcap", Name: "tidb", FullName: "pingcap/tidb"},
+		{Owner: "pingcap", Name: "tikv", FullName: "pingcap/tikv"},
+		{Owner: "fork", Name: "tikv", FullName: "fork/tikv"},
+	} {
+		if err := db.AddRepository(ctx, repo); err != nil {
+			t.Fatalf("AddRepository() error = %v", err)
+		}
+	}
+	for _, pr := range []*models.PullRequest{
+		{RepositoryFullName: "pingcap/tidb", Number: 1, State: "open", UserLogin: "alice"},
+		{RepositoryFullName: "pingcap/tidb", Number: 2, State: "open", UserLogin: "bob"},
+		{RepositoryFullName: "pingcap/tidb", Number: 3, State: "closed", UserLogin: "alice"},
+	} {
+		if err := db.AddPullRequest(ctx, pr); err != nil {
+			t.Fatalf("AddPullRequest() error = %v", err)
+		}
+	}
+	if err := db.AddIssue(ctx, &models.Issue{RepositoryFullName: "pingcap/tidb", Number: 4, State: "open", UserLogin: "alice"}); err != nil {
+		t.Fatalf("AddIssue() error = %v", err)
+	}
+
+	translator := &fakeTranslator{query: &nlquery.Query{Type: "PR", State: "open", Author: "@alice", Repo: "tidb"}}
+	s := &Service{db: db, config: &config.Config{}, translator: translator}
+
+	filter, items, _, err := s.QueryItems(ctx, "open PRs by alice in tidb", 1, 30)
+	if err != nil {
+		t.Fatalf("QueryItems() error = %v", err)
+	}
+	if filter.Type != models.ItemTypePullRequest || filter.Repo != "pingcap/tidb" || filter.Author != "alice" {
+		t.Errorf("QueryItems() filter = %+v, want open pull requests by alice in pingcap/tidb", filter)
+	}
+	if len(items) != 1 || items[0].Number != 1 {
+		t.Errorf("QueryItems() items = %+v, want pull request 1", items)
+	}
+
+	tests := []struct {
+		query *nlquery.Query
+		err   error
+		want  error
+	}{
+		{query: &nlquery.Query{Repo: "tikv"}, want: ErrInvalidQuery},
+		{query: &nlquery.Query{Repo: "tispark"}, want: ErrRepositoryNotFound},
+		{query: &nlquery.Query{State: "pending"}, want: ErrInvalidQuery},
+		{query: &nlquery.Query{Type: "discussion"}, want: ErrInvalidQuery},
+		{query: &nlquery.Query{Since: "last week"}, want: ErrInvalidQuery},
+		{err: errors.New("rate limited"), want: ErrQueryFailed},
+	}
+	for _, tt := range tests {
+		translator.query, translator.err = tt.query, tt.err
+		if _, _, _, err := s.QueryItems(ctx, "question", 1, 30); !errors.Is(err, tt.want) {
+			t.Errorf("QueryItems() with %+v, %v error = %v, want %v", tt.query, tt.err, err, tt.want)
+		}
+	}
+
+	if _, _, _, err := (&Service{db: db, config: &config.Config{}}).QueryItems(ctx, "question", 1, 30); !errors.Is(err, ErrQueryNotConfigured) {
+		t.Errorf("QueryItems() without translator error = %v, want ErrQueryNotConfigured", err)
+	}
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/github"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// reconcilePullRequests tombstones the stored pull requests of a repository that are missing from a
+// complete upstream listing, because they were deleted or transferred. It returns how many were tombstoned.
+func (s *Service) reconcilePullRequests(ctx context.Context, repoFullName string, upstream []*github.PullRequest) (int, error) {
+	present := make(map[int]bool, len(upstream))
+	for _, pr := range upstream {
+		present[pr.Number] = true
+	}
+
+	stored, err := s.db.ListAllPullRequests(ctx, repoFullName)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list pull requests: %w", err)
+	}
+
+	now := time.Now()
+	count := 0
+	for _, pr := range stored {
+		if present[pr.Number] || pr.Tombstoned {
+			continue
+		}
+
+		tombstoned := *pr
+		tombstoned.Tombstoned = true
+		tombstoned.TombstonedAt = &now
+		if err := s.db.UpdatePullRequest(ctx, &tombstoned); err != nil {
+			return count, fmt.Errorf("failed to tombstone pull request #%d: %w", pr.Number, err)
+		}
+		count++
+	}
+
+	if count > 0 {
+		s.logger.Printf("Tombstoned %d pull requests of %s no longer present upstream", count, repoFullName)
+	}
+	return count, nil
+}
+
+// reconcileIssues tombstones the stored issues of a repository that are missing from a
+// complete upstream listing, because they were deleted or transferred. It returns how many were tombstoned.
+func (s *Service) reconcileIssues(ctx context.Context, repoFullName string, upstream []*github.Issue) (int, error) {
+	present := make(map[int]bool, len(upstream))
+	for _, issue := range upstream {
+		present[issue.Number] = true
+	}
+
+	stored, err := s.db.ListAllIssues(ctx, repoFullName)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list issues: %w", err)
+	}
+
+	now := time.Now()
+	count := 0
+	for _, issue := range stored {
+		if present[issue.Number] || issue.Tombstoned {
+			continue
+		}
+
+		tombstoned := *issue
+		tombstoned.Tombstoned = true
+		tombstoned.TombstonedAt = &now
+		if err := s.db.UpdateIssue(ctx, &tombstoned); err != nil {
+			return count, fmt.Errorf("failed to tombstone issue #%d: %w", issue.Number, err)
+		}
+		count++
+	}
+
+	if count > 0 {
+		s.logger.Printf("Tombstoned %d issues of %s no longer present upstream", count, repoFullName)
+	}
+	return count, nil
+}
+
+// livePullRequests drops tombstoned pull requests
+func livePullRequests(prs []*models.PullRequest) []*models.PullRequest {
+	live := make([]*models.PullRequest, 0, len(prs))
+	for _, pr := range prs {
+		if !pr.Tombstoned {
+			live = append(live, pr)
+		}
+	}
+	return live
+}
+
+// liveIssues drops tombstoned issues
+func liveIssues(issues []*models.Issue) []*models.Issue {
+	live := make([]*models.Issue, 0, len(issues))
+	for _, issue := range issues {
+		if !issue.Tombstoned {
+			live = append(live, issue)
+		}
+	}
+	return live
+}
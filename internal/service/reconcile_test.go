@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/db/file"
+	"github.com/siddontang/github-repos-management/internal/github"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// failingListClient fails to list pull requests and issues while failing is set
+type failingListClient struct {
+	*github.FixtureClient
+	failing bool
+}
+
+func (c *failingListClient) ListPullRequests(owner, name string, options *github.PullRequestOptions) ([]*github.PullRequest, error) {
+	if c.failing {
+		return nil, errors.New("gh: HTTP 502")
+	}
+	return c.FixtureClient.ListPullRequests(owner, name, options)
+}
+
+func (c *failingListClient) ListIssues(owner, name string, options *github.IssueOptions) ([]*github.Issue, error) {
+	if c.failing {
+		return nil, errors.New("gh: HTTP 502")
+	}
+	return c.FixtureClient.ListIssues(owner, name, options)
+}
+
+func TestReconcile(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	fixture := func(prs []int, issues []int) *github.Fixture {
+		f := &github.Fixture{Repository: &github.Repository{Owner: github.User{Login: "org"}, Name: "api", FullName: "org/api"}}
+		for _, number := range prs {
+			f.PullRequests = append(f.PullRequests, &github.PullRequest{Number: number, State: "OPEN", UpdatedAt: now})
+		}
+		for _, number := range issues {
+			f.Issues = append(f.Issues, &github.Issue{Number: number, State: "OPEN", UpdatedAt: now})
+		}
+		return f
+	}
+	gh := &failingListClient{FixtureClient: github.NewFixtureClient(fixture([]int{3, 2, 1}, []int{6, 5, 4}))}
+	db, err := file.NewDB("")
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	s, err := NewServiceWithOptions(&config.Config{}, Options{DB: db, GitHubClient: gh, Logger: log.New(io.Discard, "", 0)})
+	if err != nil {
+		t.Fatalf("NewServiceWithOptions() error = %v", err)
+	}
+	defer s.Close()
+	if _, err := s.AddRepository(ctx, "org/api"); err != nil {
+		t.Fatalf("AddRepository() error = %v", err)
+	}
+
+	sync := func() error {
+		if err := s.syncPullRequests(ctx, "org", "api"); err != nil {
+			return err
+		}
+		return s.syncIssues(ctx, "org", "api")
+	}
+	// check compares the tombstoned pull requests and issues with the expected ones
+	check := func(step string, wantPRs, wantIssues map[int]bool) {
+		t.Helper()
+		prs, err := db.ListAllPullRequests(ctx, "org/api")
+		if err != nil {
+			t.Fatalf("ListAllPullRequests() error = %v", err)
+		}
+		for _, pr := range prs {
+			if pr.Tombstoned != wantPRs[pr.Number] || (pr.TombstonedAt != nil) != pr.Tombstoned {
+				t.Errorf("%s: pull request #%d tombstoned = %v at %v, want %v", step, pr.Number, pr.Tombstoned, pr.TombstonedAt, wantPRs[pr.Number])
+			}
+		}
+		issues, err := db.ListAllIssues(ctx, "org/api")
+		if err != nil {
+			t.Fatalf("ListAllIssues() error = %v", err)
+		}
+		for _, issue := range issues {
+			if issue.Tombstoned != wantIssues[issue.Number] || (issue.TombstonedAt != nil) != issue.Tombstoned {
+				t.Errorf("%s: issue #%d tombstoned = %v at %v, want %v", step, issue.Number, issue.Tombstoned, issue.TombstonedAt, wantIssues[issue.Number])
+			}
+		}
+		if len(prs) != 3 || len(issues) != 3 {
+			t.Errorf("%s: stored %d pull requests and %d issues, want all 3 of each kept", step, len(prs), len(issues))
+		}
+	}
+	check("initial sync", nil, nil)
+
+	// A complete listing tombstones the items missing from it
+	gh.Add(fixture([]int{3, 1}, []int{6, 4}))
+	if err := sync(); err != nil {
+		t.Fatalf("sync of a complete listing error = %v", err)
+	}
+	check("complete listing", map[int]bool{2: true}, map[int]bool{5: true})
+
+	// A listing cut at the item limit tombstones nothing, even of what is beyond the limit
+	limit := 1
+	if _, err := s.UpdateRepositorySyncConfig(ctx, "org", "api", &models.RepositorySyncConfigUpdate{ItemLimit: &limit}); err != nil {
+		t.Fatalf("UpdateRepositorySyncConfig() error = %v", err)
+	}
+	if err := sync(); err != nil {
+		t.Fatalf("sync of a truncated listing error = %v", err)
+	}
+	check("truncated listing", map[int]bool{2: true}, map[int]bool{5: true})
+	limit = 0
+	if _, err := s.UpdateRepositorySyncConfig(ctx, "org", "api", &models.RepositorySyncConfigUpdate{ItemLimit: &limit}); err != nil {
+		t.Fatalf("UpdateRepositorySyncConfig() error = %v", err)
+	}
+
+	// A failed listing tombstones nothing
+	gh.failing = true
+	if err := s.syncPullRequests(ctx, "org", "api"); err == nil {
+		t.Error("syncPullRequests() of a failed listing should fail")
+	}
+	if err := s.syncIssues(ctx, "org", "api"); err == nil {
+		t.Error("syncIssues() of a failed listing should fail")
+	}
+	gh.failing = false
+	check("failed listing", map[int]bool{2: true}, map[int]bool{5: true})
+
+	// Items listed again are no longer tombstoned
+	gh.Add(fixture([]int{3, 2, 1}, []int{6, 5, 4}))
+	if err := sync(); err != nil {
+		t.Fatalf("sync of the reappearing items error = %v", err)
+	}
+	check("reappearing items", nil, nil)
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// otherChangesTitle is the section of merged pull requests matching no configured section
+const otherChangesTitle = "Other changes"
+
+// defaultReleaseNotesSections are used when no sections are configured
+var defaultReleaseNotesSections = []config.ReleaseNotesSection{
+	{Title: "Features", Labels: []string{"feature", "enhancement", "feat"}},
+	{Title: "Bug fixes", Labels: []string{"bug", "bugfix", "fix"}},
+	{Title: "Documentation", Labels: []string{"docs", "documentation"}},
+}
+
+// ReleaseNotes drafts the release notes of the pull requests merged into a repository since a
+// synced release tag or a date (YYYY-MM-DD or RFC3339). An empty since starts from the latest
+// release that is not a prerelease, or covers every merged pull request when there is none.
+func (s *Service) ReleaseNotes(ctx context.Context, owner, name, since string) (*models.ReleaseNotes, error) {
+	repo, err := s.GetRepository(ctx, owner, name)
+	if err != nil {
+		return nil, err
+	}
+	notes := &models.ReleaseNotes{Repository: repo.FullName, Since: since, Sections: []*models.ReleaseNotesSection{}, Contributors: []string{}}
+	if notes.SinceTime, notes.Since, err = s.releaseNotesStart(ctx, repo.FullName, since); err != nil {
+		return nil, err
+	}
+
+	prs, err := s.db.ListAllPullRequests(ctx, repo.FullName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pull requests: %w", err)
+	}
+	sections := s.config.ReleaseNotes.Sections
+	if len(sections) == 0 {
+		sections = defaultReleaseNotesSections
+	}
+	changes := make([][]*models.ReleaseNotesChange, len(sections)+1)
+	contributors := make(map[string]bool)
+	for _, pr := range livePullRequests(prs) {
+		if pr.MergedAt == nil || pr.MergedAt.Before(notes.SinceTime) {
+			continue
+		}
+		labels, _ := s.db.ListPullRequestLabels(ctx, repo.FullName, pr.Number)
+		names := make([]string, 0, len(labels))
+		for _, label := range labels {
+			names = append(names, label.Name)
+		}
+		if releaseNotesLabelMatch(names, s.config.ReleaseNotes.Exclude) {
+			continue
+		}
+
+		section := len(sections)
+		for i, candidate := range sections {
+			if releaseNotesLabelMatch(names, candidate.Labels) {
+				section = i
+				break
+			}
+		}
+		changes[section] = append(changes[section], &models.ReleaseNotesChange{
+			Number:   pr.Number,
+			Title:    pr.Title,
+			Author:   pr.UserLogin,
+			HTMLURL:  pr.HTMLURL,
+			Labels:   names,
+			MergedAt: *pr.MergedAt,
+		})
+		if !pr.UserIsBot && pr.UserLogin != "" {
+			contributors[pr.UserLogin] = true
+		}
+	}
+
+	for i, list := range changes {
+		if len(list) == 0 {
+			continue
+		}
+		sort.Slice(list, func(a, b int) bool { return list[a].MergedAt.Before(list[b].MergedAt) })
+		title := otherChangesTitle
+		if i < len(sections) {
+			title = sections[i].Title
+		}
+		notes.Sections = append(notes.Sections, &models.ReleaseNotesSection{Title: title, PullRequests: list})
+	}
+	for login := range contributors {
+		notes.Contributors = append(notes.Contributors, login)
+	}
+	sort.Slice(notes.Contributors, func(i, j int) bool {
+		return strings.ToLower(notes.Contributors[i]) < strings.ToLower(notes.Contributors[j])
+	})
+	notes.Markdown = releaseNotesMarkdown(notes)
+	return notes, nil
+}
+
+// releaseNotesStart resolves where release notes start: the publication of a synced release tag,
+// or a date. An empty since uses the latest release that is not a prerelease, if any.
+func (s *Service) releaseNotesStart(ctx context.Context, fullName, since string) (time.Time, string, error) {
+	releases, err := s.db.ListReleases(ctx, fullName)
+	if err != nil {
+		return time.Time{}, "", fmt.Errorf("failed to list releases: %w", err)
+	}
+	if since == "" {
+		var latest *models.Release
+		for _, release := range releases {
+			if !release.Prerelease && (latest == nil || release.PublishedAt.After(latest.PublishedAt)) {
+				latest = release
+			}
+		}
+		if latest == nil {
+			return time.Time{}, "", nil
+		}
+		return latest.PublishedAt, latest.TagName, nil
+	}
+
+	for _, release := range releases {
+		if release.TagName == since {
+			return release.PublishedAt, since, nil
+		}
+	}
+	if t, err := time.Parse(time.RFC3339, since); err == nil {
+		return t, since, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", since, time.Local); err == nil {
+		return t, since, nil
+	}
+	return time.Time{}, "", ErrInvalidReleaseNotesSince
+}
+
+// releaseNotesLabelMatch reports whether any label is one of wanted, ignoring case and a scope
+// prefix such as "type/" or "kind:"
+func releaseNotesLabelMatch(labels, wanted []string) bool {
+	for _, label := range labels {
+		label = strings.ToLower(label)
+		unscoped := label
+		if i := strings.LastIndexAny(label, "/:"); i >= 0 {
+			unscoped = strings.TrimSpace(label[i+1:])
+		}
+		for _, w := range wanted {
+			w = strings.ToLower(w)
+			if label == w || unscoped == w {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// releaseNotesMarkdown renders release notes as a Markdown changelog
+func releaseNotesMarkdown(notes *models.ReleaseNotes) string {
+	var b strings.Builder
+	if notes.Since != "" {
+		fmt.Fprintf(&b, "## Changes since %s\n", notes.Since)
+	} else {
+		b.WriteString("## Changes\n")
+	}
+	if len(notes.Sections) == 0 {
+		b.WriteString("\nNo pull requests were merged.\n")
+		return b.String()
+	}
+	for _, section := range notes.Sections {
+		fmt.Fprintf(&b, "\n### %s\n\n", section.Title)
+		for _, change := range section.PullRequests {
+			fmt.Fprintf(&b, "- %s (#%d)", change.Title, change.Number)
+			if change.Author != "" {
+				fmt.Fprintf(&b, " @%s", change.Author)
+			}
+			b.WriteString("\n")
+		}
+	}
+	if len(notes.Contributors) > 0 {
+		b.WriteString("\n### Contributors\n\n")
+		for i, login := range notes.Contributors {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString("@" + login)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
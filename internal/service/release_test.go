@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/db/file"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+func TestReleaseNotes(t *testing.T) {
+	ctx := context.Background()
+	db, err := file.NewDB("")
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	if err := db.AddRepository(ctx, &models.Repository{Owner: "org", Name: "api", FullName: "org/api"}); err != nil {
+		t.Fatalf("AddRepository() error = %v", err)
+	}
+	released := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	if err := db.ReplaceReleases(ctx, "org/api", []*models.Release{
+		{TagName: "v1.0.0", PublishedAt: released},
+		{TagName: "v1.1.0-rc.1", Prerelease: true, PublishedAt: released.AddDate(0, 0, 5)},
+	}); err != nil {
+		t.Fatalf("ReplaceReleases() error = %v", err)
+	}
+	merged := func(days int) *time.Time {
+		t := released.AddDate(0, 0, days)
+		return &t
+	}
+	for _, pr := range []*models.PullRequest{
+		{RepositoryFullName: "org/api", Number: 1, Title: "Old feature", UserLogin: "alice", State: "MERGED", MergedAt: merged(-1)},
+		{RepositoryFullName: "org/api", Number: 2, Title: "Fix crash", UserLogin: "bob", State: "MERGED", MergedAt: merged(3)},
+		{RepositoryFullName: "org/api", Number: 3, Title: "Add export", UserLogin: "alice", State: "MERGED", MergedAt: merged(2)},
+		{RepositoryFullName: "org/api", Number: 4, Title: "Bump deps", UserLogin: "dependabot[bot]", UserIsBot: true, State: "MERGED", MergedAt: merged(4)},
+		{RepositoryFullName: "org/api", Number: 5, Title: "Internal refactor", UserLogin: "carol", State: "MERGED", MergedAt: merged(4)},
+		{RepositoryFullName: "org/api", Number: 6, Title: "Still open", UserLogin: "carol", State: "OPEN"},
+	} {
+		if err := db.AddPullRequest(ctx, pr); err != nil {
+			t.Fatalf("AddPullRequest() error = %v", err)
+		}
+	}
+	for number, label := range map[int]string{1: "feature", 2: "type/bug", 3: "Enhancement", 5: "skip-changelog"} {
+		db.AddLabel(ctx, &models.Label{Name: label})
+		db.AddPullRequestLabel(ctx, "org/api", number, label)
+	}
+
+	cfg := &config.Config{ReleaseNotes: config.ReleaseNotesConfig{Exclude: []string{"skip-changelog"}}}
+	s := &Service{db: db, config: cfg, logger: log.New(io.Discard, "", 0)}
+
+	// The latest release that is not a prerelease is the default start
+	notes, err := s.ReleaseNotes(ctx, "org", "api", "")
+	if err != nil {
+		t.Fatalf("ReleaseNotes() error = %v", err)
+	}
+	if notes.Since != "v1.0.0" || len(notes.Sections) != 3 {
+		t.Fatalf("ReleaseNotes() = %+v, want features, bug fixes and other changes since v1.0.0", notes)
+	}
+	want := map[string][]int{"Features": {3}, "Bug fixes": {2}, "Other changes": {4}}
+	for _, section := range notes.Sections {
+		var numbers []int
+		for _, change := range section.PullRequests {
+			numbers = append(numbers, change.Number)
+		}
+		if len(numbers) != len(want[section.Title]) || numbers[0] != want[section.Title][0] {
+			t.Errorf("section %q = %v, want %v", section.Title, numbers, want[section.Title])
+		}
+	}
+	wantMarkdown := "## Changes since v1.0.0\n\n### Features\n\n- Add export (#3) @alice\n\n### Bug fixes\n\n- Fix crash (#2) @bob\n\n" +
+		"### Other changes\n\n- Bump deps (#4) @dependabot[bot]\n\n### Contributors\n\n@alice, @bob\n"
+	if notes.Markdown != wantMarkdown {
+		t.Errorf("Markdown = %q, want %q", notes.Markdown, wantMarkdown)
+	}
+
+	if notes, err := s.ReleaseNotes(ctx, "org", "api", "2024-02-01"); err != nil || len(notes.Sections[0].PullRequests) != 2 {
+		t.Errorf("ReleaseNotes(date) = %+v, %v, want both features", notes, err)
+	}
+	if _, err := s.ReleaseNotes(ctx, "org", "api", "v9"); !errors.Is(err, ErrInvalidReleaseNotesSince) {
+		t.Errorf("ReleaseNotes(unknown tag) error = %v, want ErrInvalidReleaseNotesSince", err)
+	}
+}
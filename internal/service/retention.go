@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// defaultRetentionInterval is how often the retention policy is applied when no interval is configured
+const defaultRetentionInterval = 24 * time.Hour
+
+// retentionPolicy returns the configured retention policy as of now, or nil when closed items are
+// kept forever
+func (s *Service) retentionPolicy(now time.Time) *models.RetentionPolicy {
+	retention := s.config.Retention
+	if retention.ClosedMaxAge <= 0 && retention.MaxClosedPerRepository <= 0 {
+		return nil
+	}
+	policy := &models.RetentionPolicy{MaxClosedPerRepository: retention.MaxClosedPerRepository}
+	if retention.ClosedMaxAge > 0 {
+		policy.ClosedBefore = now.Add(-retention.ClosedMaxAge)
+	}
+	return policy
+}
+
+// closedTime returns when a pull request or issue was closed, falling back to its last update as
+// the retention policy does
+func closedTime(closed, merged *time.Time, updated time.Time) time.Time {
+	if closed != nil {
+		return *closed
+	}
+	if merged != nil {
+		return *merged
+	}
+	return updated
+}
+
+// retentionCutoff returns when the closed pull requests or issues of a repository, as itemType
+// tells, must have been closed for a sync to store them, so that it doesn't bring back those the
+// retention policy dropped. Past MaxClosedPerRepository stored closed items, it is when the oldest
+// kept one was closed. It is the zero time when closed items are kept forever.
+func (s *Service) retentionCutoff(ctx context.Context, fullName, itemType string) (time.Time, error) {
+	policy := s.retentionPolicy(time.Now())
+	if policy == nil {
+		return time.Time{}, nil
+	}
+	cutoff := policy.ClosedBefore
+	if policy.MaxClosedPerRepository <= 0 {
+		return cutoff, nil
+	}
+
+	var closed []time.Time
+	if itemType == models.ItemTypePullRequest {
+		prs, err := s.db.ListAllPullRequests(ctx, fullName)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("failed to list pull requests: %w", err)
+		}
+		for _, pr := range prs {
+			if !strings.EqualFold(pr.State, "open") {
+				closed = append(closed, closedTime(pr.ClosedAt, pr.MergedAt, pr.UpdatedAt))
+			}
+		}
+	} else {
+		issues, err := s.db.ListAllIssues(ctx, fullName)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("failed to list issues: %w", err)
+		}
+		for _, issue := range issues {
+			if !strings.EqualFold(issue.State, "open") {
+				closed = append(closed, closedTime(issue.ClosedAt, nil, issue.UpdatedAt))
+			}
+		}
+	}
+	if len(closed) < policy.MaxClosedPerRepository {
+		return cutoff, nil
+	}
+	sort.Slice(closed, func(i, j int) bool { return closed[i].After(closed[j]) })
+	if oldest := closed[policy.MaxClosedPerRepository-1]; oldest.After(cutoff) {
+		cutoff = oldest
+	}
+	return cutoff, nil
+}
+
+// compact drops the closed items the retention policy no longer keeps, then removes orphaned data
+// and rewrites the database
+func (s *Service) compact(ctx context.Context) (*models.CompactionResult, error) {
+	expired := &models.RetentionResult{}
+	if policy := s.retentionPolicy(time.Now()); policy != nil {
+		var err error
+		if expired, err = s.db.ExpireClosedItems(ctx, policy); err != nil {
+			return nil, fmt.Errorf("failed to expire closed items: %w", err)
+		}
+	}
+
+	result, err := s.db.Compact(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compact database: %w", err)
+	}
+	result.ExpiredPullRequests = expired.PullRequests
+	result.ExpiredIssues = expired.Issues
+	return result, nil
+}
+
+// RunRetention applies the retention policy and compacts the database every retention interval
+// until ctx is done. It returns at once when closed items are kept forever.
+func (s *Service) RunRetention(ctx context.Context) {
+	if s.retentionPolicy(time.Now()) == nil {
+		return
+	}
+	interval := s.config.Retention.Interval
+	if interval <= 0 {
+		interval = defaultRetentionInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		result, err := s.compact(ctx)
+		if err != nil {
+			s.logger.Printf("Error applying the retention policy: %v", err)
+		} else if result.ExpiredPullRequests > 0 || result.ExpiredIssues > 0 {
+			s.logger.Printf("Retention policy dropped %d closed pull requests and %d closed issues (%d -> %d bytes)",
+				result.ExpiredPullRequests, result.ExpiredIssues, result.BytesBefore, result.BytesAfter)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
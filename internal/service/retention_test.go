@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/db/file"
+	"github.com/siddontang/github-repos-management/internal/github"
+	"github.com/siddontang/github-repos-management/internal/notify"
+)
+
+// TestSyncAfterRetention tests that a sync neither stores again the closed items the retention
+// policy dropped nor announces closed items it stores for the first time
+func TestSyncAfterRetention(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	old, recent := now.AddDate(0, -1, 0), now.Add(-time.Hour)
+	bug := []github.Label{{Name: "bug"}}
+	fixture := &github.Fixture{
+		Repository: &github.Repository{Owner: github.User{Login: "org"}, Name: "api", FullName: "org/api"},
+		PullRequests: []*github.PullRequest{
+			{Number: 1, State: "OPEN", UpdatedAt: now},
+			{Number: 2, State: "CLOSED", Labels: bug, UpdatedAt: old, ClosedAt: &old},
+		},
+		Issues: []*github.Issue{
+			{Number: 3, State: "OPEN", UpdatedAt: now},
+			{Number: 4, State: "CLOSED", Labels: bug, UpdatedAt: old, ClosedAt: &old},
+		},
+	}
+	gh := github.NewFixtureClient(fixture)
+	db, err := file.NewDB("")
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	cfg := &config.Config{}
+	s, err := NewServiceWithOptions(cfg, Options{DB: db, GitHubClient: gh, Logger: log.New(io.Discard, "", 0)})
+	if err != nil {
+		t.Fatalf("NewServiceWithOptions() error = %v", err)
+	}
+	defer s.Close()
+	notifier := &recordingNotifier{}
+	s.notifier.Add(notify.Rule{}, notifier)
+	if _, err := s.AddRepository(ctx, "org/api"); err != nil {
+		t.Fatalf("AddRepository() error = %v", err)
+	}
+	sync := func() {
+		t.Helper()
+		if err := s.syncPullRequests(ctx, "org", "api"); err != nil {
+			t.Fatalf("syncPullRequests() error = %v", err)
+		}
+		if err := s.syncIssues(ctx, "org", "api"); err != nil {
+			t.Fatalf("syncIssues() error = %v", err)
+		}
+	}
+	sync()
+
+	// Closed items are kept until a retention policy is set
+	cfg.Retention = config.RetentionConfig{ClosedMaxAge: 7 * 24 * time.Hour}
+	result, err := s.compact(ctx)
+	if err != nil {
+		t.Fatalf("compact() error = %v", err)
+	}
+	if result.ExpiredPullRequests != 1 || result.ExpiredIssues != 1 {
+		t.Fatalf("compact() = %+v, want #2 and #4 expired", result)
+	}
+
+	// A pull request and an issue closed since the last sync, first seen closed
+	gh.Add(&github.Fixture{
+		Repository: fixture.Repository,
+		PullRequests: append(fixture.PullRequests,
+			&github.PullRequest{Number: 5, State: "CLOSED", Labels: bug, UpdatedAt: recent, ClosedAt: &recent}),
+		Issues: append(fixture.Issues,
+			&github.Issue{Number: 6, State: "CLOSED", Labels: bug, UpdatedAt: recent, ClosedAt: &recent}),
+	})
+	notifier.events = nil
+	sync()
+
+	if _, err := db.GetPullRequest(ctx, "org/api", 2); err == nil {
+		t.Error("expired pull request #2 was stored again")
+	}
+	if _, err := db.GetIssue(ctx, "org/api", 4); err == nil {
+		t.Error("expired issue #4 was stored again")
+	}
+	if _, err := db.GetPullRequest(ctx, "org/api", 5); err != nil {
+		t.Errorf("GetPullRequest(#5) error = %v, want it stored", err)
+	}
+	if _, err := db.GetIssue(ctx, "org/api", 6); err != nil {
+		t.Errorf("GetIssue(#6) error = %v, want it stored", err)
+	}
+	for _, event := range notifier.events {
+		switch event.Type {
+		case notify.EventPullRequestOpened, notify.EventPullRequestLabeled, notify.EventIssueOpened, notify.EventIssueLabeled:
+			t.Errorf("sync sent %s for #%d, want no event for closed items", event.Type, event.Number)
+		}
+	}
+}
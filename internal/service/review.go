@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// Default review queue thresholds
+const (
+	defaultReviewDueSoon = 24 * time.Hour
+	defaultReviewOverdue = 48 * time.Hour
+)
+
+// ReviewQueue lists the open pull requests of the tracked repositories matching the filter that
+// request review from a reviewer, directly or through a configured team listing them, oldest
+// first. Drafts and the reviewer's own pull requests are left out.
+func (s *Service) ReviewQueue(ctx context.Context, filter *models.ReviewQueueFilter) ([]*models.ReviewQueueItem, error) {
+	reviewer := filter.Reviewer
+	if reviewer == "" {
+		reviewer = s.config.ReviewQueue.User
+	}
+	if reviewer == "" {
+		reviewer = s.config.CodeOwners.User
+	}
+	if reviewer == "" {
+		return nil, ErrReviewerNotSet
+	}
+
+	repos, err := s.selectRepositories(ctx, filter.Repo, filter.RepoTag)
+	if err != nil {
+		return nil, err
+	}
+	prs, err := s.db.FindPullRequests(ctx, &models.ItemQuery{
+		Repositories: queryRepositories(ctx, repos, filter.Repo, filter.RepoTag),
+		State:        "open",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find pull requests: %w", err)
+	}
+
+	match := s.userMatcher(reviewer)
+	now := time.Now()
+	items := make([]*models.ReviewQueueItem, 0)
+	for _, pr := range livePullRequests(prs) {
+		if pr.Draft || strings.EqualFold(pr.UserLogin, reviewer) || !match(pr.RequestedTeams, pr.RequestedReviewers) {
+			continue
+		}
+		since := reviewWaitingSince(pr)
+		items = append(items, &models.ReviewQueueItem{
+			PullRequest:  pr,
+			WaitingSince: since,
+			Status:       s.reviewStatus(s.hours.Between(since, now)),
+		})
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if !items[i].WaitingSince.Equal(items[j].WaitingSince) {
+			return items[i].WaitingSince.Before(items[j].WaitingSince)
+		}
+		a, b := items[i].PullRequest, items[j].PullRequest
+		if a.RepositoryFullName != b.RepositoryFullName {
+			return a.RepositoryFullName < b.RepositoryFullName
+		}
+		return a.Number < b.Number
+	})
+	return items, nil
+}
+
+// reviewWaitingSince returns when a pull request started waiting for review: the last time it
+// was marked ready for review, or its creation
+func reviewWaitingSince(pr *models.PullRequest) time.Time {
+	for i := len(pr.StateHistory) - 1; i >= 0; i-- {
+		if t := pr.StateHistory[i]; t.From == "draft" && t.To == "ready" {
+			return t.At
+		}
+	}
+	return pr.CreatedAt
+}
+
+// reviewStatus returns the review queue status of a pull request waiting for some time
+func (s *Service) reviewStatus(waiting time.Duration) string {
+	dueSoon, overdue := s.config.ReviewQueue.DueSoon, s.config.ReviewQueue.Overdue
+	if dueSoon <= 0 {
+		dueSoon = defaultReviewDueSoon
+	}
+	if overdue <= 0 {
+		overdue = defaultReviewOverdue
+	}
+	switch {
+	case waiting >= overdue:
+		return models.ReviewStatusOverdue
+	case waiting >= dueSoon:
+		return models.ReviewStatusDueSoon
+	}
+	return models.ReviewStatusOK
+}
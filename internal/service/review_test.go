@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/db/file"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+func TestReviewQueue(t *testing.T) {
+	ctx := context.Background()
+	db, err := file.NewDB("")
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	if err := db.AddRepository(ctx, &models.Repository{Owner: "org", Name: "api", FullName: "org/api"}); err != nil {
+		t.Fatalf("AddRepository() error = %v", err)
+	}
+
+	now := time.Now()
+	days := func(n int) time.Time { return now.Add(-time.Duration(n) * 24 * time.Hour) }
+	prs := []*models.PullRequest{
+		{Number: 1, State: "open", CreatedAt: days(1).Add(-time.Hour), RequestedReviewers: []string{"alice"}},
+		{Number: 2, State: "open", CreatedAt: days(5), RequestedTeams: []string{"org/backend"}},
+		{Number: 3, State: "open", CreatedAt: days(3), RequestedReviewers: []string{"bob"}},
+		{Number: 4, State: "open", CreatedAt: days(9), Draft: true, RequestedReviewers: []string{"alice"}},
+		{Number: 5, State: "closed", CreatedAt: days(9), RequestedReviewers: []string{"alice"}},
+		// Marked ready for review an hour ago, so it has only just started waiting
+		{Number: 6, State: "open", CreatedAt: days(7), RequestedReviewers: []string{"Alice"},
+			StateHistory: models.StateHistory{{From: "draft", To: "ready", At: now.Add(-time.Hour)}}},
+	}
+	for _, pr := range prs {
+		pr.RepositoryFullName = "org/api"
+		if err := db.AddPullRequest(ctx, pr); err != nil {
+			t.Fatalf("AddPullRequest() error = %v", err)
+		}
+	}
+
+	cfg := &config.Config{Teams: map[string][]string{"backend": {"alice"}}}
+	s := &Service{db: db, config: cfg, logger: log.New(io.Discard, "", 0)}
+	if _, err := s.ReviewQueue(ctx, &models.ReviewQueueFilter{}); !errors.Is(err, ErrReviewerNotSet) {
+		t.Fatalf("ReviewQueue() without reviewer error = %v, want ErrReviewerNotSet", err)
+	}
+
+	cfg.ReviewQueue.User = "alice"
+	items, err := s.ReviewQueue(ctx, &models.ReviewQueueFilter{})
+	if err != nil {
+		t.Fatalf("ReviewQueue() error = %v", err)
+	}
+	want := []struct {
+		number int
+		status string
+	}{{2, models.ReviewStatusOverdue}, {1, models.ReviewStatusDueSoon}, {6, models.ReviewStatusOK}}
+	if len(items) != len(want) {
+		t.Fatalf("ReviewQueue() = %d items, want %d", len(items), len(want))
+	}
+	for i, w := range want {
+		if items[i].PullRequest.Number != w.number || items[i].Status != w.status {
+			t.Errorf("item %d = #%d %s, want #%d %s", i, items[i].PullRequest.Number, items[i].Status, w.number, w.status)
+		}
+	}
+
+	items, err = s.ReviewQueue(ctx, &models.ReviewQueueFilter{Reviewer: "bob"})
+	if err != nil || len(items) != 1 || items[0].PullRequest.Number != 3 {
+		t.Errorf("ReviewQueue(bob) = %v, %v, want #3", items, err)
+	}
+}
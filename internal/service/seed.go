@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/db"
+	"github.com/siddontang/github-repos-management/internal/github"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// SeedRepository stores pull requests and issues exported from GitHub, such as gh pr list --json
+// dumps, without calling GitHub, so that onboarding a large repository doesn't spend API quota. An
+// untracked repository starts being tracked, described by ghRepo when given. Items already stored
+// with the same or a later update are kept as they are.
+func (s *Service) SeedRepository(ctx context.Context, fullName string, ghRepo *github.Repository, prs []*github.PullRequest, issues []*github.Issue) (*models.SeedResult, error) {
+	owner, name, ok := strings.Cut(fullName, "/")
+	if !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+		return nil, ErrInvalidRepositoryName
+	}
+
+	result := &models.SeedResult{Repository: fullName}
+	repo, err := s.db.GetRepository(ctx, owner, name)
+	switch {
+	case err == nil:
+		if err := s.checkWorkspace(ctx, repo.FullName); err != nil {
+			return nil, err
+		}
+	case errors.Is(err, db.ErrNotFound):
+		if repo, result.Tracked, err = s.seedRepository(ctx, owner, name, ghRepo); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, err
+	}
+	result.Repository = repo.FullName
+
+	for _, ghPR := range prs {
+		pr := s.pullRequestModel(repo.FullName, ghPR, "")
+		existing, err := s.db.GetPullRequest(ctx, repo.FullName, ghPR.Number)
+		if err == nil && !existing.UpdatedAt.Before(pr.UpdatedAt) {
+			result.Skipped++
+			continue
+		}
+		if err == nil {
+			if pr.Reviews == nil {
+				pr.Reviews, pr.FirstReviewAt = existing.Reviews, existing.FirstReviewAt
+			}
+			if pr.Files == nil {
+				pr.Files = existing.Files
+			}
+			pr.StateHistory = pullRequestHistory(existing, pr)
+			pr.AuthorAssociation = existing.AuthorAssociation
+		}
+		if _, err := s.db.UpsertPullRequest(ctx, pr); err != nil {
+			return result, fmt.Errorf("failed to store pull request #%d: %w", pr.Number, err)
+		}
+		s.seedLabels(ctx, ghPR.Labels, func(label string) error {
+			return s.db.AddPullRequestLabel(ctx, repo.FullName, pr.Number, label)
+		})
+		result.PullRequests++
+	}
+
+	for _, ghIssue := range issues {
+		if ghIssue.IsPullRequest() {
+			continue
+		}
+		issue := s.issueModel(repo.FullName, ghIssue, "")
+		existing, err := s.db.GetIssue(ctx, repo.FullName, ghIssue.Number)
+		if err == nil && !existing.UpdatedAt.Before(issue.UpdatedAt) {
+			result.Skipped++
+			continue
+		}
+		if err == nil {
+			issue.StateHistory = issueHistory(existing, issue)
+			issue.AuthorAssociation = existing.AuthorAssociation
+		}
+		if _, err := s.db.UpsertIssue(ctx, issue); err != nil {
+			return result, fmt.Errorf("failed to store issue #%d: %w", issue.Number, err)
+		}
+		s.seedLabels(ctx, ghIssue.Labels, func(label string) error {
+			return s.db.AddIssueLabel(ctx, repo.FullName, issue.Number, label)
+		})
+		result.Issues++
+	}
+
+	s.audit(ctx, models.AuditRepositorySeed, repo.FullName,
+		fmt.Sprintf("%d pull requests, %d issues, %d skipped", result.PullRequests, result.Issues, result.Skipped))
+	return result, nil
+}
+
+// SeedArchive seeds every repository of a GitHub migration archive, in order of full name. It stops
+// at the first repository failing, returning the results so far.
+func (s *Service) SeedArchive(ctx context.Context, archive *github.Archive) ([]*models.SeedResult, error) {
+	var results []*models.SeedResult
+	for _, fullName := range archive.FullNames() {
+		result, err := s.SeedRepository(ctx, fullName, archive.Repository(fullName), archive.PullRequests[fullName], archive.Issues[fullName])
+		if result != nil {
+			results = append(results, result)
+		}
+		if err != nil {
+			return results, fmt.Errorf("failed to seed %s: %w", fullName, err)
+		}
+	}
+	return results, nil
+}
+
+// seedRepository starts tracking a repository from exported data rather than from GitHub,
+// reporting whether it was added rather than added by another request meanwhile. It is left
+// unsynced, so that the next refresh fetches what changed since the export.
+func (s *Service) seedRepository(ctx context.Context, owner, name string, ghRepo *github.Repository) (*models.Repository, bool, error) {
+	repo := &models.Repository{
+		Owner:    owner,
+		Name:     name,
+		FullName: owner + "/" + name,
+		HTMLURL:  "https://github.com/" + owner + "/" + name,
+	}
+	if ghRepo != nil {
+		repo.Description = ghRepo.Description
+		repo.IsPrivate = ghRepo.Private
+		repo.CreatedAt = ghRepo.CreatedAt
+		repo.UpdatedAt = ghRepo.UpdatedAt
+		if ghRepo.HTMLURL != "" {
+			repo.HTMLURL = ghRepo.HTMLURL
+		}
+	}
+	if repo.CreatedAt.IsZero() {
+		repo.CreatedAt = time.Now()
+		repo.UpdatedAt = repo.CreatedAt
+	}
+
+	created, err := s.db.UpsertRepository(ctx, repo)
+	if errors.Is(err, db.ErrVersionConflict) {
+		// Added and changed by another request meanwhile, so that one is kept
+		repo, err = s.db.GetRepository(ctx, owner, name)
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to add repository to database: %w", err)
+	}
+	if created {
+		s.audit(ctx, models.AuditRepositoryAdd, repo.FullName, "seeded")
+	}
+	if err := s.addWorkspaceRepository(ctx, repo.FullName); err != nil {
+		return nil, false, err
+	}
+	return repo, created, nil
+}
+
+// seedLabels defines the labels of a seeded item that aren't yet and attaches them with attach
+func (s *Service) seedLabels(ctx context.Context, labels []github.Label, attach func(label string) error) {
+	for _, ghLabel := range labels {
+		if _, err := s.db.GetLabel(ctx, ghLabel.Name); errors.Is(err, db.ErrNotFound) {
+			label := &models.Label{Name: ghLabel.Name, Color: ghLabel.Color, Description: ghLabel.Description}
+			if err := s.db.AddLabel(ctx, label); err != nil {
+				s.logger.Printf("Error adding label %s: %v", ghLabel.Name, err)
+				continue
+			}
+		}
+		if err := attach(ghLabel.Name); err != nil {
+			s.logger.Printf("Error attaching label %s: %v", ghLabel.Name, err)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/db/file"
+	"github.com/siddontang/github-repos-management/internal/github"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+func TestSeedRepository(t *testing.T) {
+	ctx := context.Background()
+	db, err := file.NewDB("")
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	s := &Service{db: db, config: &config.Config{}, logger: log.New(io.Discard, "", 0)}
+
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	prs := []*github.PullRequest{
+		{Number: 1, Title: "Add API", State: "MERGED", User: github.User{Login: "alice"}, CreatedAt: day, UpdatedAt: day,
+			Labels: []github.Label{{Name: "feature", Color: "00ff00"}}},
+	}
+	issues := []*github.Issue{
+		{Number: 2, Title: "Crash", Body: "See #1", State: "OPEN", User: github.User{Login: "bob"}, CreatedAt: day, UpdatedAt: day},
+		{Number: 1, Title: "Add API", HTMLURL: "https://github.com/org/api/pull/1"},
+	}
+
+	if _, err := s.SeedRepository(ctx, "org", nil, prs, issues); !errors.Is(err, ErrInvalidRepositoryName) {
+		t.Errorf("SeedRepository(org) error = %v, want ErrInvalidRepositoryName", err)
+	}
+
+	result, err := s.SeedRepository(ctx, "org/api", &github.Repository{Description: "The API", CreatedAt: day}, prs, issues)
+	if err != nil {
+		t.Fatalf("SeedRepository() error = %v", err)
+	}
+	want := models.SeedResult{Repository: "org/api", Tracked: true, PullRequests: 1, Issues: 1}
+	if *result != want {
+		t.Errorf("SeedRepository() = %+v, want %+v", *result, want)
+	}
+
+	repo, err := db.GetRepository(ctx, "org", "api")
+	if err != nil || repo.Description != "The API" || !repo.LastSyncedAt.IsZero() {
+		t.Errorf("seeded repository = %+v, %v; want it described and unsynced", repo, err)
+	}
+	issue, err := db.GetIssue(ctx, "org/api", 2)
+	if err != nil || issue.UserLogin != "bob" || issue.State != "OPEN" {
+		t.Errorf("seeded issue = %+v, %v", issue, err)
+	}
+	if _, err := db.GetIssue(ctx, "org/api", 1); err == nil {
+		t.Error("a pull request listed as an issue should not be stored as one")
+	}
+	labels, err := db.ListPullRequestLabels(ctx, "org/api", 1)
+	if err != nil || len(labels) != 1 || labels[0].Name != "feature" || labels[0].Color != "00ff00" {
+		t.Errorf("seeded labels = %+v, %v", labels, err)
+	}
+
+	// Seeding again skips what is stored with the same update, and updates newer items
+	later := day.Add(24 * time.Hour)
+	closed := &github.Issue{Number: 2, Title: "Crash", State: "CLOSED", User: github.User{Login: "bob"}, CreatedAt: day, UpdatedAt: later, ClosedAt: &later}
+	result, err = s.SeedRepository(ctx, "org/api", nil, prs, []*github.Issue{closed})
+	if err != nil {
+		t.Fatalf("SeedRepository() again error = %v", err)
+	}
+	want = models.SeedResult{Repository: "org/api", Issues: 1, Skipped: 1}
+	if *result != want {
+		t.Errorf("SeedRepository() again = %+v, want %+v", *result, want)
+	}
+	issue, _ = db.GetIssue(ctx, "org/api", 2)
+	if issue.State != "CLOSED" || len(issue.StateHistory) == 0 {
+		t.Errorf("reseeded issue = %+v, want it closed with its state history", issue)
+	}
+}
+
+func TestSeedArchive(t *testing.T) {
+	ctx := context.Background()
+	db, err := file.NewDB("")
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	s := &Service{db: db, config: &config.Config{}, logger: log.New(io.Discard, "", 0)}
+
+	archive := &github.Archive{
+		Repositories: []*github.Repository{{Name: "web", FullName: "org/web", HTMLURL: "https://ghe.example.com/org/web"}},
+		PullRequests: map[string][]*github.PullRequest{"org/api": {{Number: 3, State: "OPEN"}}},
+		Issues:       map[string][]*github.Issue{"org/web": {{Number: 4, State: "OPEN"}}},
+	}
+	results, err := s.SeedArchive(ctx, archive)
+	if err != nil {
+		t.Fatalf("SeedArchive() error = %v", err)
+	}
+	if len(results) != 2 || results[0].Repository != "org/api" || results[0].PullRequests != 1 || results[1].Issues != 1 {
+		t.Errorf("SeedArchive() = %+v", results)
+	}
+	if repo, err := db.GetRepository(ctx, "org", "web"); err != nil || repo.HTMLURL != "https://ghe.example.com/org/web" {
+		t.Errorf("archived repository = %+v, %v", repo, err)
+	}
+}
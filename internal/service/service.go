@@ -2,8 +2,10 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
 	"sort"
 	"strings"
 	"sync"
@@ -11,90 +13,273 @@ import (
 
 	"github.com/siddontang/github-repos-management/internal/config"
 	"github.com/siddontang/github-repos-management/internal/db"
-	"github.com/siddontang/github-repos-management/internal/db/file"
+	_ "github.com/siddontang/github-repos-management/internal/db/file" // Registers the file and memory backends
 	"github.com/siddontang/github-repos-management/internal/github"
+	"github.com/siddontang/github-repos-management/internal/jobs"
 	"github.com/siddontang/github-repos-management/internal/models"
+	"github.com/siddontang/github-repos-management/internal/nlquery"
+	"github.com/siddontang/github-repos-management/internal/notify"
+	"github.com/siddontang/github-repos-management/internal/similarity"
+	"github.com/siddontang/github-repos-management/internal/tracing"
+	"github.com/siddontang/github-repos-management/internal/warehouse"
+	"github.com/siddontang/github-repos-management/internal/workhours"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Service represents the main service for the GitHub repository management
 type Service struct {
-	config    *config.Config
-	db        db.DB
-	ghClient  github.ClientInterface
-	syncMutex sync.Mutex
+	config     *config.Config
+	db         db.DB
+	ghClient   github.ClientInterface
+	usage      *meteredClient
+	notifier   *notify.Dispatcher
+	deliveries *deliveryQueue // Webhook and subscription deliveries
+	logger     *log.Logger
+	jobs       *jobs.Queue
+	translator nlquery.Translator  // Nil when natural-language queries are not configured
+	hours      *workhours.Calendar // Nil when durations are measured in wall-clock time
+	duplicates similarity.Detector // Nil when duplicate issues are not detected
+	warehouse  warehouse.Sink      // Nil when no warehouse is configured
+	tracer     *tracing.Tracer     // Nil when tracing is not configured
+	syncMutex  sync.Mutex
 
 	syncStatus map[string]string // repository full name -> status
 	startTime  time.Time
 }
 
+// Options overrides the dependencies a service creates from its configuration.
+// Nil fields use the defaults.
+type Options struct {
+	DB              db.DB                  // Defaults to the backend of the configured database type
+	GitHubClient    github.ClientInterface // Defaults to a gh CLI client
+	Logger          *log.Logger            // Defaults to the standard logger
+	QueryTranslator nlquery.Translator     // Defaults to the configured query backend, if any
+	// DuplicateDetector defaults to word shingles of the configured size when duplicates are enabled
+	DuplicateDetector similarity.Detector
+	WarehouseSink     warehouse.Sink  // Defaults to the configured warehouse, if any
+	Tracer            *tracing.Tracer // Defaults to the configured OTLP collector, if any
+}
+
 // NewService creates a new service instance
 func NewService(cfg *config.Config) (*Service, error) {
-	// Create GitHub client
-	ghClient := github.NewClient()
+	return NewServiceWithOptions(cfg, Options{})
+}
 
-	// Create database provider based on configuration
-	var dbProvider db.Provider
-	switch cfg.Database.Type {
-	case config.DBTypeFile:
-		dbProvider = file.NewProvider()
-	case config.DBTypeSQLite:
-		// TODO: Implement SQLite provider
-		return nil, fmt.Errorf("sqlite database not implemented yet")
-	case config.DBTypeMySQL:
-		// TODO: Implement MySQL provider
-		return nil, fmt.Errorf("mysql database not implemented yet")
-	default:
-		return nil, fmt.Errorf("unsupported database type: %s", cfg.Database.Type)
+// NewServiceWithOptions creates a new service instance with the given dependencies
+func NewServiceWithOptions(cfg *config.Config, opts Options) (*Service, error) {
+	// Create GitHub client
+	ghClient := opts.GitHubClient
+	if cfg.GitHub.Offline {
+		// Offline mode never reaches GitHub, whichever client was given
+		ghClient = github.NewOfflineClient()
+	} else if ghClient == nil && cfg.GitHub.Replay != "" {
+		fixtures, err := github.LoadFixtures(cfg.GitHub.Replay)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load replay fixtures: %w", err)
+		}
+		ghClient = fixtures
+	} else if ghClient == nil {
+		ghClient = github.NewClientWithOptions(github.ClientOptions{
+			MaxConcurrentCalls: cfg.GitHub.MaxConcurrentCalls,
+			Tokens:             cfg.GitHub.Tokens,
+			TokenMinRemaining:  cfg.GitHub.TokenMinRemaining,
+		})
 	}
 
-	// Create database instance
-	dbInstance, err := dbProvider(cfg)
+	// Measure SLA deadlines, review waits and lead times in the configured business hours
+	hours, err := workhours.New(&cfg.BusinessHours)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create database: %w", err)
+		return nil, err
+	}
+
+	// Create the database of the configured type from the registered backends
+	dbInstance := opts.DB
+	if dbInstance == nil {
+		var err error
+		if dbInstance, err = db.Open(cfg); err != nil {
+			return nil, fmt.Errorf("failed to create database: %w", err)
+		}
+	}
+
+	logger := opts.Logger
+	if logger == nil {
+		logger = log.Default()
+	}
+
+	// Translate natural-language queries with the configured backend
+	translator := opts.QueryTranslator
+	if translator == nil && cfg.Query.Backend != "" {
+		var err error
+		if translator, err = nlquery.Open(&cfg.Query); err != nil {
+			return nil, fmt.Errorf("failed to create query translator: %w", err)
+		}
+	}
+
+	// Flag probable duplicate issues after each sync
+	duplicates := opts.DuplicateDetector
+	if !cfg.Duplicates.Enabled {
+		duplicates = nil
+	} else if duplicates == nil {
+		duplicates = similarity.Shingles{Size: cfg.Duplicates.ShingleSize}
+	}
+
+	// Push the stored data to the configured warehouse
+	sink := opts.WarehouseSink
+	if sink == nil && cfg.Warehouse.Kind != "" {
+		var err error
+		if sink, err = warehouse.New(cfg.Warehouse); err != nil {
+			return nil, fmt.Errorf("failed to create warehouse sink: %w", err)
+		}
+	}
+
+	// Trace syncs, storage operations and gh calls to the configured collector
+	tracer := opts.Tracer
+	if tracer == nil {
+		var err error
+		if tracer, err = tracing.New(cfg.Tracing, func(err error) { logger.Printf("Error exporting traces: %v", err) }); err != nil {
+			return nil, fmt.Errorf("failed to create tracer: %w", err)
+		}
 	}
+	if tracer != nil {
+		dbInstance = db.Traced(dbInstance)
+	}
+
+	// Count the API requests spent on each repository
+	usage := newMeteredClient(ghClient)
 
-	return &Service{
+	s := &Service{
 		config:     cfg,
 		db:         dbInstance,
-		ghClient:   ghClient,
+		ghClient:   usage,
+		usage:      usage,
+		notifier:   notify.NewDispatcher(&cfg.Notifications),
+		deliveries: newDeliveryQueue(dbInstance, logger),
+		logger:     logger,
+		translator: translator,
+		hours:      hours,
+		duplicates: duplicates,
+		warehouse:  sink,
+		tracer:     tracer,
 		syncStatus: make(map[string]string),
 		startTime:  time.Now(),
-	}, nil
+	}
+
+	// Run syncs and other background work through the persistent job queue
+	s.jobs = jobs.New(dbInstance, jobs.Options{
+		Workers:     cfg.Jobs.Workers,
+		MaxAttempts: cfg.Jobs.MaxAttempts,
+		RetryDelay:  cfg.Jobs.RetryDelay,
+		Logger:      logger,
+	})
+	s.jobs.Handle(models.JobTypeSyncRepository, s.runSyncJob)
+	// Unfinished jobs of a read-only database belong to the process writing it
+	if !cfg.Database.ReadOnly {
+		if recovered, err := s.jobs.Recover(context.Background()); err != nil {
+			logger.Printf("Error recovering unfinished jobs: %v", err)
+		} else if recovered > 0 {
+			logger.Printf("Marked %d unfinished jobs of a previous run as failed", recovered)
+		}
+	}
+
+	// Record every event in the activity log and deliver it to the registered webhooks
+	s.notifier.Add(notify.Rule{}, &activityRecorder{service: s})
+	s.notifier.Add(notify.Rule{}, &webhookNotifier{
+		service:    s,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	})
+	// Deliver labeled pull requests and issues to the label subscriptions
+	s.notifier.Add(notify.Rule{Events: []notify.EventType{notify.EventPullRequestLabeled, notify.EventIssueLabeled}}, &subscriptionNotifier{
+		service:    s,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	})
+
+	return s, nil
 }
 
 // Close closes the service and its resources
 func (s *Service) Close() error {
+	s.StopDeliveries()
+	s.StopTracing()
 	return s.db.Close()
 }
 
+// StopDeliveries waits at most 10 seconds for the queued webhook and subscription deliveries,
+// aborting those still running then, and records their logs; later events are not delivered
+func (s *Service) StopDeliveries() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	s.deliveries.Close(ctx)
+}
+
+// StopTracing exports the spans still queued, waiting at most 10 seconds; later spans are
+// dropped. Failed exports are logged.
+func (s *Service) StopTracing() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_ = s.tracer.Shutdown(ctx)
+}
+
+// Tracer returns the tracer of the service, nil when tracing is not configured
+func (s *Service) Tracer() *tracing.Tracer {
+	return s.tracer
+}
+
 // Repository operations
 
 // AddRepository adds a new repository to be tracked
 func (s *Service) AddRepository(ctx context.Context, fullName string) (*models.Repository, error) {
+	repo, created, err := s.trackRepository(ctx, fullName)
+	if err != nil || !created {
+		return repo, err
+	}
+
+	s.syncRepositories(ctx, []*models.Repository{repo}, nil)
+	return repo, nil
+}
+
+// trackRepository fetches a repository from GitHub and stores it without syncing its items.
+// It reports whether the repository was newly added; an already tracked repository is returned as is.
+func (s *Service) trackRepository(ctx context.Context, fullName string) (*models.Repository, bool, error) {
 	// Parse owner and name
 	parts := strings.Split(fullName, "/")
 	if len(parts) != 2 {
-		return nil, ErrInvalidRepositoryName
+		return nil, false, ErrInvalidRepositoryName
 	}
 	owner, name := parts[0], parts[1]
 
 	// Check if repository already exists
 	existingRepo, err := s.db.GetRepository(ctx, owner, name)
 	if err == nil && existingRepo != nil {
-		log.Printf("Repository %s already exists in database", fullName)
-		return existingRepo, nil
+		s.logger.Printf("Repository %s already exists in database", fullName)
+		if existingRepo.TrackedBy != "" {
+			// Adding a repository explicitly keeps it tracked when it is unstarred
+			if existingRepo, err = s.updateRepository(ctx, owner, name, func(repo *models.Repository) bool {
+				repo.TrackedBy = ""
+				return true
+			}); err != nil {
+				return nil, false, err
+			}
+		}
+		if err := s.addWorkspaceRepository(ctx, existingRepo.FullName); err != nil {
+			return nil, false, err
+		}
+		return existingRepo, false, nil
+	}
+	if err != nil && !errors.Is(err, db.ErrNotFound) {
+		return nil, false, err
 	}
 
-	log.Printf("Adding new repository: %s", fullName)
+	s.logger.Printf("Adding new repository: %s", fullName)
 
 	// Get repository from GitHub
-	ghRepo, err := s.ghClient.GetRepository(owner, name)
+	ghRepo, err := s.gh(ctx).GetRepository(owner, name)
 	if err != nil {
-		log.Printf("Error fetching repository from GitHub: %v", err)
-		return nil, fmt.Errorf("failed to get repository from GitHub: %w", err)
+		s.logger.Printf("Error fetching repository from GitHub: %v", err)
+		return nil, false, fmt.Errorf("failed to get repository from GitHub: %w", err)
 	}
 
-	log.Printf("Successfully fetched repository from GitHub: %s", fullName)
+	s.logger.Printf("Successfully fetched repository from GitHub: %s", fullName)
 
 	// Create repository model
 	repo := &models.Repository{
@@ -108,69 +293,310 @@ func (s *Service) AddRepository(ctx context.Context, fullName string) (*models.R
 		LastSyncedAt: time.Now(), // Set initial sync time
 		CreatedAt:    ghRepo.CreatedAt,
 		UpdatedAt:    ghRepo.UpdatedAt,
-	}
 
-	// Add repository to database
-	if err := s.db.AddRepository(ctx, repo); err != nil {
-		log.Printf("Error adding repository to database: %v", err)
-		return nil, fmt.Errorf("failed to add repository to database: %w", err)
+		MetadataSyncedAt: time.Now(),
+		APIUsage:         models.APIUsage{TotalRequests: 1},
 	}
 
-	log.Printf("Successfully added repository to database: %s", fullName)
+	// Add repository to database. A repository added by another request since the lookup is
+	// refreshed with the fetched metadata, unless it was changed already and is kept as it is.
+	created, err := s.db.UpsertRepository(ctx, repo)
+	if errors.Is(err, db.ErrVersionConflict) {
+		return s.trackRepository(ctx, fullName)
+	}
+	if err != nil {
+		s.logger.Printf("Error adding repository to database: %v", err)
+		return nil, false, fmt.Errorf("failed to add repository to database: %w", err)
+	}
+	if !created {
+		s.logger.Printf("Repository %s was added concurrently", fullName)
+		if err := s.addWorkspaceRepository(ctx, repo.FullName); err != nil {
+			return nil, false, err
+		}
+		return repo, false, nil
+	}
 
-	log.Printf("Syncing repository: %s", fullName)
-	if err := s.syncRepository(context.Background(), owner, name); err != nil {
-		log.Printf("Error syncing repository %s: %v", fullName, err)
-	} else {
-		log.Printf("Successfully synced repository: %s", fullName)
+	s.logger.Printf("Successfully added repository to database: %s", fullName)
+	s.audit(ctx, models.AuditRepositoryAdd, repo.FullName, "")
+	if err := s.addWorkspaceRepository(ctx, repo.FullName); err != nil {
+		return nil, false, err
 	}
+	s.notifier.Dispatch(ctx, &notify.Event{
+		Type:       notify.EventRepositoryAdded,
+		Repository: repo.FullName,
+		URL:        repo.HTMLURL,
+	})
 
-	return repo, nil
+	return repo, true, nil
 }
 
 // GetRepository gets a repository by owner and name
 func (s *Service) GetRepository(ctx context.Context, owner, name string) (*models.Repository, error) {
+	repo, err := s.GetStoredRepository(ctx, owner, name)
+	if err != nil {
+		return nil, err
+	}
+	return s.refreshStaleRepository(ctx, repo), nil
+}
+
+// GetStoredRepository returns a tracked repository as stored, without refreshing it when stale
+func (s *Service) GetStoredRepository(ctx context.Context, owner, name string) (*models.Repository, error) {
 	repo, err := s.db.GetRepository(ctx, owner, name)
 	if err != nil {
-		return nil, ErrRepositoryNotFound
+		return nil, notFound(err, ErrRepositoryNotFound)
+	}
+	if err := s.checkWorkspace(ctx, repo.FullName); err != nil {
+		return nil, err
 	}
 	return repo, nil
 }
 
-// ListRepositories lists all tracked repositories
-func (s *Service) ListRepositories(ctx context.Context, page, perPage int) ([]*models.Repository, int, error) {
-	return s.db.ListRepositories(ctx, page, perPage)
+// ListRepositories lists tracked repositories, optionally restricted to a tag
+func (s *Service) ListRepositories(ctx context.Context, filter *models.RepositoryFilter) ([]*models.Repository, *models.Pagination, error) {
+	repos, err := s.db.ListAllRepositories(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list repositories: %w", err)
+	}
+	if repos, err = s.scopeRepositories(ctx, repos); err != nil {
+		return nil, nil, err
+	}
+
+	if filter.Tag != "" {
+		var filteredRepos []*models.Repository
+		for _, repo := range repos {
+			if repo.HasTag(filter.Tag) {
+				filteredRepos = append(filteredRepos, repo)
+			}
+		}
+		repos = filteredRepos
+	}
+
+	// Repositories are ordered by full name; apply offset or cursor pagination
+	key := func(i int) cursorKey { return cursorKey{Repo: repos[i].FullName} }
+	start, end, pagination, err := window(len(repos), key, repositoryLess, filter.Page, filter.PerPage, filter.Cursor)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return repos[start:end], pagination, nil
+}
+
+// AddRepositoryTag attaches a tag to a tracked repository
+func (s *Service) AddRepositoryTag(ctx context.Context, owner, name, tag string) (*models.Repository, error) {
+	tag = strings.TrimSpace(tag)
+	if tag == "" {
+		return nil, ErrInvalidTag
+	}
+
+	repo, err := s.updateRepository(ctx, owner, name, func(repo *models.Repository) bool {
+		if repo.HasTag(tag) {
+			return false
+		}
+		repo.Tags = append(repo.Tags, tag)
+		sort.Strings(repo.Tags)
+		return true
+	})
+	if err == nil {
+		s.audit(ctx, models.AuditRepositoryTag, repo.FullName, tag)
+	}
+	return repo, err
+}
+
+// RemoveRepositoryTag detaches a tag from a tracked repository
+func (s *Service) RemoveRepositoryTag(ctx context.Context, owner, name, tag string) (*models.Repository, error) {
+	repo, err := s.updateRepository(ctx, owner, name, func(repo *models.Repository) bool {
+		if !repo.HasTag(tag) {
+			return false
+		}
+		tags := make([]string, 0, len(repo.Tags)-1)
+		for _, t := range repo.Tags {
+			if t != tag {
+				tags = append(tags, t)
+			}
+		}
+		repo.Tags = tags
+		return true
+	})
+	if err == nil {
+		s.audit(ctx, models.AuditRepositoryUntag, repo.FullName, tag)
+	}
+	return repo, err
 }
 
 // DeleteRepository removes a repository from tracking
 func (s *Service) DeleteRepository(ctx context.Context, owner, name string) error {
+	// Within a workspace, the repository is only removed from it
+	if workspaceFrom(ctx) != "" {
+		return s.removeWorkspaceRepository(ctx, owner+"/"+name)
+	}
+
 	err := s.db.DeleteRepository(ctx, owner, name)
 	if err != nil {
-		return ErrRepositoryNotFound
+		return notFound(err, ErrRepositoryNotFound)
 	}
+
+	fullName := fmt.Sprintf("%s/%s", owner, name)
+	s.audit(ctx, models.AuditRepositoryRemove, fullName, "")
+	s.notifier.Dispatch(ctx, &notify.Event{
+		Type:       notify.EventRepositoryRemoved,
+		Repository: fullName,
+	})
 	return nil
 }
 
-// RefreshRepository forces a refresh of repository data
+// UpdateRepositorySyncConfig applies a partial update to a repository's sync configuration
+func (s *Service) UpdateRepositorySyncConfig(ctx context.Context, owner, name string, update *models.RepositorySyncConfigUpdate) (*models.Repository, error) {
+	if update.SyncInterval != nil && *update.SyncInterval < 0 {
+		return nil, ErrInvalidSyncConfig
+	}
+	if update.ItemLimit != nil && *update.ItemLimit < 0 {
+		return nil, ErrInvalidSyncConfig
+	}
+	if update.Priority != nil && !models.ValidSyncPriority(*update.Priority) {
+		return nil, ErrInvalidSyncConfig
+	}
+	if err := s.checkRepositoryWorkspace(ctx, owner, name); err != nil {
+		return nil, err
+	}
+
+	repo, err := s.updateRepository(ctx, owner, name, func(repo *models.Repository) bool {
+		update.Apply(&repo.SyncConfig)
+		return true
+	})
+	if err == nil {
+		s.audit(ctx, models.AuditRepositoryConfigure, repo.FullName, update.String())
+	}
+	return repo, err
+}
+
+// PauseRepository excludes a repository from scheduled refreshes without untracking it
+func (s *Service) PauseRepository(ctx context.Context, owner, name string) (*models.Repository, error) {
+	return s.setRepositoryPaused(ctx, owner, name, true)
+}
+
+// ResumeRepository re-includes a paused repository in scheduled refreshes
+func (s *Service) ResumeRepository(ctx context.Context, owner, name string) (*models.Repository, error) {
+	return s.setRepositoryPaused(ctx, owner, name, false)
+}
+
+// setRepositoryPaused updates the paused flag of a repository
+func (s *Service) setRepositoryPaused(ctx context.Context, owner, name string, paused bool) (*models.Repository, error) {
+	if err := s.checkRepositoryWorkspace(ctx, owner, name); err != nil {
+		return nil, err
+	}
+	repo, err := s.updateRepository(ctx, owner, name, func(repo *models.Repository) bool {
+		if repo.Paused == paused {
+			return false
+		}
+		repo.Paused = paused
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	action := models.AuditRepositoryResume
+	if paused {
+		action = models.AuditRepositoryPause
+	}
+	s.audit(ctx, action, repo.FullName, "")
+	return repo, nil
+}
+
+// maxUpdateAttempts bounds how often updateRepository retries after losing a race with another writer
+const maxUpdateAttempts = 5
+
+// updateRepository applies mutate to the stored repository and writes it back. When another
+// writer updated the repository in the meantime the write is rejected with db.ErrVersionConflict,
+// and the change is applied again to the fresh copy. mutate returns false when there is nothing
+// to update.
+func (s *Service) updateRepository(ctx context.Context, owner, name string, mutate func(repo *models.Repository) bool) (*models.Repository, error) {
+	for attempt := 1; ; attempt++ {
+		repo, err := s.db.GetRepository(ctx, owner, name)
+		if err != nil {
+			return nil, notFound(err, ErrRepositoryNotFound)
+		}
+
+		if !mutate(repo) {
+			return repo, nil
+		}
+
+		err = s.db.UpdateRepository(ctx, repo)
+		if err == nil {
+			return repo, nil
+		}
+		if !errors.Is(err, db.ErrVersionConflict) || attempt == maxUpdateAttempts {
+			return nil, fmt.Errorf("failed to update repository: %w", err)
+		}
+	}
+}
+
+// RefreshRepository forces a refresh of repository data and waits until it finishes or ctx is done
 func (s *Service) RefreshRepository(ctx context.Context, owner, name string) error {
+	job, err := s.StartRefresh(ctx, owner, name)
+	if err != nil {
+		return err
+	}
+
+	job, err = s.WaitJob(ctx, job.ID, 0)
+	if err != nil {
+		return err
+	}
+	if !job.Done() {
+		return fmt.Errorf("refresh job %d is still running: %w", job.ID, ctx.Err())
+	}
+	switch job.State {
+	case models.JobStateFailed:
+		return errors.New(job.Error)
+	case models.JobStateCanceled:
+		return fmt.Errorf("refresh job %d was canceled", job.ID)
+	}
+	return nil
+}
+
+// StartRefresh refreshes a repository in the background, returning the job to poll for its completion.
+// Refreshing a repository that has a sync queued already returns that job.
+func (s *Service) StartRefresh(ctx context.Context, owner, name string) (*models.Job, error) {
 	// Check if repository exists
-	_, err := s.db.GetRepository(ctx, owner, name)
+	repo, err := s.db.GetRepository(ctx, owner, name)
 	if err != nil {
-		return ErrRepositoryNotFound
+		return nil, notFound(err, ErrRepositoryNotFound)
+	}
+	if err := s.checkWorkspace(ctx, repo.FullName); err != nil {
+		return nil, err
+	}
+	if s.config.GitHub.Offline {
+		return nil, github.ErrOffline
 	}
 
-	log.Printf("Refreshing repository: %s/%s", owner, name)
-	syncCtx := context.Background()
-	if err := s.syncRepository(syncCtx, owner, name); err != nil {
-		// Log the error but don't return it since we're in a goroutine
-		fmt.Printf("Error refreshing repository %s/%s: %v\n", owner, name, err)
+	// A sync still waiting for a worker fetches everything a new one would, so it is returned instead
+	queued, _, err := s.jobs.List(ctx, &models.JobFilter{
+		Type: models.JobTypeSyncRepository, State: models.JobStateQueued, Target: repo.FullName, Page: 1, PerPage: 1,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(queued) > 0 {
+		return queued[0], nil
 	}
 
-	return nil
+	job, err := s.jobs.EnqueueWithPriority(ctx, models.JobTypeSyncRepository, repo.FullName, syncPriority(repo))
+	if err != nil {
+		return nil, err
+	}
+
+	s.audit(ctx, models.AuditRepositoryRefresh, repo.FullName, fmt.Sprintf("job %d", job.ID))
+	return job, nil
 }
 
 // syncRepository syncs a repository's data from GitHub
-func (s *Service) syncRepository(ctx context.Context, owner, name string) error {
+func (s *Service) syncRepository(ctx context.Context, owner, name string) (err error) {
+	ctx, span := s.tracer.Start(ctx, "service.syncRepository", trace.WithAttributes(attribute.String("github.repository", owner+"/"+name)))
+	defer func() {
+		tracing.RecordError(span, err)
+		span.End()
+	}()
+
 	fullName := fmt.Sprintf("%s/%s", owner, name)
 
 	// Set sync status
@@ -194,33 +620,224 @@ func (s *Service) syncRepository(ctx context.Context, owner, name string) error
 		return fmt.Errorf("repository not found: %w", err)
 	}
 
+	// Requests made by this sync, recorded even when it fails
+	requestsBefore := s.usage.Requests(owner, name)
+	requests := func() int64 { return s.usage.Requests(owner, name) - requestsBefore }
+
+	var pullRequestsSyncedAt, issuesSyncedAt, discussionsSyncedAt time.Time
+
 	// Sync pull requests
-	if err := s.syncPullRequests(ctx, owner, name); err != nil {
-		s.syncMutex.Lock()
-		s.syncStatus[fullName] = fmt.Sprintf("error syncing pull requests: %v", err)
-		s.syncMutex.Unlock()
-		return fmt.Errorf("failed to sync pull requests: %w", err)
+	if repo.SyncConfig.ShouldSyncPullRequests() {
+		if err := s.syncPullRequests(ctx, owner, name); err != nil {
+			s.syncMutex.Lock()
+			s.syncStatus[fullName] = fmt.Sprintf("error syncing pull requests: %v", err)
+			s.syncMutex.Unlock()
+			s.notifySyncFailure(ctx, fullName, err)
+			s.recordAPIUsage(ctx, owner, name, requests())
+			return fmt.Errorf("failed to sync pull requests: %w", err)
+		}
+		pullRequestsSyncedAt = time.Now()
+	}
+
+	// Stop between the phases when the sync job was canceled
+	if err := ctx.Err(); err != nil {
+		s.recordAPIUsage(ctx, owner, name, requests())
+		return err
 	}
 
 	// Sync issues
-	if err := s.syncIssues(ctx, owner, name); err != nil {
-		s.syncMutex.Lock()
-		s.syncStatus[fullName] = fmt.Sprintf("error syncing issues: %v", err)
-		s.syncMutex.Unlock()
-		return fmt.Errorf("failed to sync issues: %w", err)
+	if repo.SyncConfig.ShouldSyncIssues() {
+		if err := s.syncIssues(ctx, owner, name); err != nil {
+			s.syncMutex.Lock()
+			s.syncStatus[fullName] = fmt.Sprintf("error syncing issues: %v", err)
+			s.syncMutex.Unlock()
+			s.notifySyncFailure(ctx, fullName, err)
+			s.recordAPIUsage(ctx, owner, name, requests())
+			return fmt.Errorf("failed to sync issues: %w", err)
+		}
+		issuesSyncedAt = time.Now()
+
+		// Milestones and releases only feed the calendar, so failing to fetch them doesn't fail the sync
+		if err := s.syncCalendar(ctx, owner, name); err != nil {
+			s.logger.Printf("Error syncing milestones and releases of %s: %v", fullName, err)
+		}
+	}
+
+	// Sync discussions, only for repositories opting in
+	if repo.SyncConfig.ShouldSyncDiscussions() {
+		if err := s.syncDiscussions(ctx, owner, name, itemLimit(repo)); err != nil {
+			s.syncMutex.Lock()
+			s.syncStatus[fullName] = fmt.Sprintf("error syncing discussions: %v", err)
+			s.syncMutex.Unlock()
+			s.notifySyncFailure(ctx, fullName, err)
+			s.recordAPIUsage(ctx, owner, name, requests())
+			return fmt.Errorf("failed to sync discussions: %w", err)
+		}
+		discussionsSyncedAt = time.Now()
 	}
 
-	// Update last synced time after successful sync
-	repo.LastSyncedAt = time.Now()
-	if err := s.db.UpdateRepository(ctx, repo); err != nil {
+	// Security alerts neither fail the sync; alerts of a kind that can't be fetched are kept
+	var alertsSyncedAt time.Time
+	if s.config.GitHub.SyncAlerts {
+		if s.syncAlerts(ctx, owner, name) {
+			alertsSyncedAt = time.Now()
+		}
+	}
+
+	// Commits only feed activity metrics, so failing to fetch them doesn't fail the sync either
+	if s.config.GitHub.SyncCommits {
+		if err := s.syncCommits(ctx, owner, name); err != nil {
+			s.logger.Printf("Error syncing commits of %s: %v", fullName, err)
+		}
+	}
+
+	// Projects only feed the project filters and reports, so failing to fetch them doesn't fail the sync
+	if s.config.GitHub.SyncProjects {
+		if err := s.syncProjects(ctx, owner, name); err != nil {
+			s.logger.Printf("Error syncing projects of %s: %v", fullName, err)
+		}
+	}
+
+	// Labels and issue templates only feed the label registry; the previous ones are kept when they can't be fetched
+	var labels []models.Label
+	var templates []models.IssueTemplate
+	labelsFetched := false
+	if s.config.GitHub.SyncLabels {
+		if labels, templates, err = s.fetchLabels(ctx, owner, name); err != nil {
+			s.logger.Printf("Error syncing labels of %s: %v", fullName, err)
+		} else {
+			labelsFetched = true
+		}
+	}
+
+	// CODEOWNERS rules only feed the ownership filters; the previous rules are kept when they can't be fetched
+	var codeOwners []models.CodeOwnersRule
+	codeOwnersFetched := false
+	if s.config.CodeOwners.Enabled {
+		if codeOwners, err = s.fetchCodeOwners(ctx, owner, name); err != nil {
+			s.logger.Printf("Error syncing CODEOWNERS of %s: %v", fullName, err)
+		} else {
+			codeOwnersFetched = true
+		}
+	}
+
+	// Settings feed the compliance report; the previous snapshot is kept when they can't be captured
+	var settings *models.RepositorySettings
+	if s.settingsDue(repo) {
+		if settings, err = s.captureSettings(ctx, owner, name); err != nil {
+			s.logger.Printf("Error capturing settings of %s: %v", fullName, err)
+		}
+	}
+
+	// Update last synced time after successful sync. The repository is re-read so
+	// changes made while the sync was running, such as new tags, are kept.
+	repo, err = s.updateRepository(ctx, owner, name, func(repo *models.Repository) bool {
+		if !pullRequestsSyncedAt.IsZero() {
+			repo.PullRequestsSyncedAt = pullRequestsSyncedAt
+		}
+		if !issuesSyncedAt.IsZero() {
+			repo.IssuesSyncedAt = issuesSyncedAt
+		}
+		if !discussionsSyncedAt.IsZero() {
+			repo.DiscussionsSyncedAt = discussionsSyncedAt
+		}
+		if !alertsSyncedAt.IsZero() {
+			repo.AlertsSyncedAt = alertsSyncedAt
+		}
+		if settings != nil {
+			repo.Settings = settings
+		}
+		if codeOwnersFetched {
+			repo.CodeOwners = codeOwners
+		}
+		if labelsFetched {
+			repo.Labels = labels
+			repo.IssueTemplates = templates
+		}
+		repo.LastSyncedAt = time.Now()
+		used := requests()
+		repo.APIUsage.LastSyncRequests = int(used)
+		repo.APIUsage.TotalRequests += used
+		repo.APIUsage.Syncs++
+		return true
+	})
+	if err != nil {
 		return fmt.Errorf("failed to update last synced time: %w", err)
 	}
 
+	// Record the item counts for trend reporting
+	if err := s.snapshotRepository(ctx, repo); err != nil {
+		s.logger.Printf("Error taking snapshot of repository %s: %v", fullName, err)
+	}
+
+	// Triage rules act on the freshly synced items
+	s.applyTriageRules(ctx, repo)
+
+	// Duplicates only feed the duplicates report, so failing to detect them doesn't fail the sync
+	if s.duplicates != nil {
+		if err := s.detectDuplicates(ctx, fullName); err != nil {
+			s.logger.Printf("Error detecting duplicate issues of %s: %v", fullName, err)
+		}
+	}
+
+	s.notifier.Dispatch(ctx, &notify.Event{
+		Type:       notify.EventSyncCompleted,
+		Repository: fullName,
+	})
+
 	return nil
 }
 
+// pullRequestModel converts a pull request fetched from GitHub to the stored model
+func (s *Service) pullRequestModel(fullName string, ghPR *github.PullRequest, association string) *models.PullRequest {
+	pr := &models.PullRequest{
+		RepositoryFullName: fullName,
+		Number:             ghPR.Number,
+		Title:              ghPR.Title,
+		Body:               ghPR.Body,
+		State:              ghPR.State,
+		Draft:              ghPR.Draft,
+		URL:                ghPR.URL,
+		HTMLURL:            ghPR.HTMLURL,
+		UserLogin:          ghPR.User.Login,
+		UserAvatarURL:      ghPR.User.AvatarURL,
+		UserURL:            ghPR.User.URL,
+		UserHTMLURL:        ghPR.User.HTMLURL,
+		UserIsBot:          ghPR.User.Bot(),
+		AuthorAssociation:  association,
+		Assignees:          userLogins(ghPR.Assignees),
+		Milestone:          milestoneTitle(ghPR.Milestone),
+		RequestedReviewers: userLogins(ghPR.RequestedReviewers),
+		RequestedTeams:     teamSlugs(ghPR.RequestedTeams),
+		Mentions:           parseMentions(ghPR.Body),
+		JiraKeys:           parseJiraKeys(s.config.Jira.Projects, ghPR.Title, ghPR.Body),
+		References:         parseReferences(fullName, ghPR.Title, ghPR.Body),
+		Files:              ghPR.Files,
+		CreatedAt:          ghPR.CreatedAt,
+		UpdatedAt:          ghPR.UpdatedAt,
+		ClosedAt:           ghPR.ClosedAt,
+		MergedAt:           ghPR.MergedAt,
+		ReviewDecision:     ghPR.ReviewDecision,
+		FirstReviewAt:      firstReviewAt(ghPR),
+	}
+	for _, ghReview := range ghPR.Reviews {
+		pr.Reviews = append(pr.Reviews, models.PullRequestReview{
+			UserLogin:   ghReview.User.Login,
+			State:       ghReview.State,
+			SubmittedAt: ghReview.SubmittedAt,
+		})
+	}
+	return pr
+}
+
 // syncPullRequests syncs pull requests for a repository
-func (s *Service) syncPullRequests(ctx context.Context, owner, name string) error {
+func (s *Service) syncPullRequests(ctx context.Context, owner, name string) (err error) {
+	ctx, span := tracing.Start(ctx, "service.syncPullRequests", trace.WithAttributes(attribute.String("github.repository", owner+"/"+name)))
+	defer func() {
+		tracing.RecordError(span, err)
+		span.End()
+	}()
+
 	// Get repository
 	repo, err := s.db.GetRepository(ctx, owner, name)
 	if err != nil {
@@ -229,83 +846,195 @@ func (s *Service) syncPullRequests(ctx context.Context, owner, name string) erro
 
 	// Get pull requests from GitHub
 	options := &github.PullRequestOptions{
-		State:     "all",
-		Sort:      "updated",
-		Direction: "desc",
-		PerPage:   100,
-		Page:      1,
+		State:          "all",
+		Sort:           "updated",
+		Direction:      "desc",
+		PerPage:        itemLimit(repo),
+		Page:           1,
+		IncludeReviews: repo.SyncConfig.ShouldSyncReviews(),
+		IncludeFiles:   s.syncsPullRequestFiles(),
 	}
 
-	prs, err := s.ghClient.ListPullRequests(owner, name, options)
+	prs, err := s.gh(ctx).ListPullRequests(owner, name, options)
 	if err != nil {
 		return fmt.Errorf("failed to list pull requests: %w", err)
 	}
 
-	// Process pull requests
-	for _, ghPR := range prs {
-		// Create pull request model
-		pr := &models.PullRequest{
-			RepositoryFullName: repo.FullName,
-			Number:             ghPR.Number,
-			Title:              ghPR.Title,
-			Body:               ghPR.Body,
-			State:              ghPR.State,
-			URL:                ghPR.URL,
-			HTMLURL:            ghPR.HTMLURL,
-			UserLogin:          ghPR.User.Login,
-			UserAvatarURL:      ghPR.User.AvatarURL,
-			UserURL:            ghPR.User.URL,
-			UserHTMLURL:        ghPR.User.HTMLURL,
-			CreatedAt:          ghPR.CreatedAt,
-			UpdatedAt:          ghPR.UpdatedAt,
-			ClosedAt:           ghPR.ClosedAt,
-			MergedAt:           ghPR.MergedAt,
-		}
-
-		// Check if pull request exists
-		existingPR, err := s.db.GetPullRequest(ctx, repo.FullName, ghPR.Number)
-		if err == nil && existingPR != nil {
-			// Update existing pull request
-			if err := s.db.UpdatePullRequest(ctx, pr); err != nil {
-				continue
+	associations := s.authorAssociations(ctx, owner, name, options.PerPage)
+
+	// Skip change events on the initial sync, when every pull request is new
+	_, known, err := s.db.ListPullRequests(ctx, repo.FullName, 1, 1)
+	notifyChanges := err == nil && known > 0
+
+	cutoff, err := s.retentionCutoff(ctx, repo.FullName, models.ItemTypePullRequest)
+	if err != nil {
+		return err
+	}
+
+	// Process pull requests in one batch, so readers see all of them or none. Notifiers, which may
+	// write to the database, hear of them once they are stored.
+	var events []*notify.Event
+	err = s.db.Batch(ctx, func(tx db.Tx) error {
+		for _, ghPR := range prs {
+			pr := s.pullRequestModel(repo.FullName, ghPR, associations[ghPR.Number])
+
+			// Check if pull request exists
+			existingPR, _ := tx.GetPullRequest(ctx, repo.FullName, ghPR.Number)
+			if existingPR != nil {
+				// Keep the known reviews when reviews were not fetched this time
+				if pr.FirstReviewAt == nil {
+					pr.FirstReviewAt = existingPR.FirstReviewAt
+				}
+				if !options.IncludeReviews {
+					pr.Reviews = existingPR.Reviews
+				}
+				if !options.IncludeFiles {
+					pr.Files = existingPR.Files
+				}
+				pr.StateHistory = pullRequestHistory(existingPR, pr)
+				if pr.AuthorAssociation == "" {
+					pr.AuthorAssociation = existingPR.AuthorAssociation
+				}
 			}
-		} else {
-			// Add new pull request
-			if err := s.db.AddPullRequest(ctx, pr); err != nil {
+
+			// Closed pull requests the retention policy dropped are not stored again
+			closed := !strings.EqualFold(pr.State, "open")
+			if existingPR == nil && closed && closedTime(pr.ClosedAt, pr.MergedAt, pr.UpdatedAt).Before(cutoff) {
 				continue
 			}
-		}
 
-		// Process labels
-		for _, ghLabel := range ghPR.Labels {
-			// Create label model
-			label := &models.Label{
-				Name:        ghLabel.Name,
-				Color:       ghLabel.Color,
-				Description: ghLabel.Description,
+			// Store the pull request
+			created, err := tx.UpsertPullRequest(ctx, pr)
+			if err != nil {
+				continue
+			}
+			// Pull requests first seen closed were neither opened nor labeled since the last sync
+			announce := notifyChanges && !(created && closed)
+			switch {
+			case created:
+				if announce {
+					events = append(events, pullRequestEvent(notify.EventPullRequestOpened, pr))
+				}
+			case existingPR != nil:
+				if notifyChanges && !pr.UpdatedAt.Equal(existingPR.UpdatedAt) {
+					events = append(events, pullRequestEvent(notify.EventPullRequestUpdated, pr))
+				}
+				if notifyChanges && !strings.EqualFold(pr.State, existingPR.State) {
+					events = append(events, &notify.Event{
+						Type:          notify.EventPullRequestStateChanged,
+						Repository:    pr.RepositoryFullName,
+						Number:        pr.Number,
+						Title:         pr.Title,
+						URL:           pr.HTMLURL,
+						Author:        pr.UserLogin,
+						State:         pr.State,
+						PreviousState: existingPR.State,
+					})
+				}
+				if notifyChanges && pr.ReviewDecision == "APPROVED" && existingPR.ReviewDecision != "APPROVED" {
+					events = append(events, pullRequestEvent(notify.EventPullRequestApproved, pr))
+				}
 			}
 
-			// Check if label exists
-			existingLabel, err := s.db.GetLabel(ctx, ghLabel.Name)
-			if err != nil || existingLabel == nil {
-				// Add new label
-				if err := s.db.AddLabel(ctx, label); err != nil {
-					continue
+			// Remember the labels the pull request already had
+			knownLabels := make(map[string]bool)
+			if existingLabels, err := tx.ListPullRequestLabels(ctx, repo.FullName, ghPR.Number); err == nil {
+				for _, label := range existingLabels {
+					knownLabels[label.Name] = true
 				}
 			}
 
-			// Add label to pull request
-			if err := s.db.AddPullRequestLabel(ctx, repo.FullName, ghPR.Number, ghLabel.Name); err != nil {
-				// Ignore errors
+			// Process labels
+			for _, ghLabel := range ghPR.Labels {
+				// Create label model
+				label := &models.Label{
+					Name:        ghLabel.Name,
+					Color:       ghLabel.Color,
+					Description: ghLabel.Description,
+				}
+
+				// Check if label exists
+				existingLabel, err := tx.GetLabel(ctx, ghLabel.Name)
+				if err != nil || existingLabel == nil {
+					// Add new label
+					if err := tx.AddLabel(ctx, label); err != nil {
+						continue
+					}
+				}
+
+				// Add label to pull request
+				if err := tx.AddPullRequestLabel(ctx, repo.FullName, ghPR.Number, ghLabel.Name); err != nil {
+					// Ignore errors
+				}
+
+				if announce && !knownLabels[ghLabel.Name] {
+					events = append(events, &notify.Event{
+						Type:       notify.EventPullRequestLabeled,
+						Repository: pr.RepositoryFullName,
+						Number:     pr.Number,
+						Title:      pr.Title,
+						URL:        pr.HTMLURL,
+						Author:     pr.UserLogin,
+						Label:      ghLabel.Name,
+					})
+				}
 			}
 		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store pull requests: %w", err)
+	}
+	for _, event := range events {
+		s.notifier.Dispatch(ctx, event)
+	}
+
+	// A listing shorter than the limit is complete, so stored pull requests missing from it were removed upstream
+	if len(prs) < options.PerPage {
+		if _, err := s.reconcilePullRequests(ctx, repo.FullName, prs); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
+// issueModel converts an issue fetched from GitHub to the stored model
+func (s *Service) issueModel(fullName string, ghIssue *github.Issue, association string) *models.Issue {
+	return &models.Issue{
+		RepositoryFullName: fullName,
+		Number:             ghIssue.Number,
+		Title:              ghIssue.Title,
+		Body:               ghIssue.Body,
+		State:              ghIssue.State,
+		URL:                ghIssue.URL,
+		HTMLURL:            ghIssue.HTMLURL,
+		UserLogin:          ghIssue.User.Login,
+		UserAvatarURL:      ghIssue.User.AvatarURL,
+		UserURL:            ghIssue.User.URL,
+		UserHTMLURL:        ghIssue.User.HTMLURL,
+		UserIsBot:          ghIssue.User.Bot(),
+		AuthorAssociation:  association,
+		Assignees:          userLogins(ghIssue.Assignees),
+		Milestone:          milestoneTitle(ghIssue.Milestone),
+		Mentions:           parseMentions(ghIssue.Body),
+		JiraKeys:           parseJiraKeys(s.config.Jira.Projects, ghIssue.Title, ghIssue.Body),
+		References:         parseReferences(fullName, ghIssue.Title, ghIssue.Body),
+		Tasks:              parseTasks(fullName, ghIssue.Body),
+		CreatedAt:          ghIssue.CreatedAt,
+		UpdatedAt:          ghIssue.UpdatedAt,
+		ClosedAt:           ghIssue.ClosedAt,
+	}
+}
+
 // syncIssues syncs issues for a repository
-func (s *Service) syncIssues(ctx context.Context, owner, name string) error {
+func (s *Service) syncIssues(ctx context.Context, owner, name string) (err error) {
+	ctx, span := tracing.Start(ctx, "service.syncIssues", trace.WithAttributes(attribute.String("github.repository", owner+"/"+name)))
+	defer func() {
+		tracing.RecordError(span, err)
+		span.End()
+	}()
+
 	// Get repository
 	repo, err := s.db.GetRepository(ctx, owner, name)
 	if err != nil {
@@ -317,317 +1046,515 @@ func (s *Service) syncIssues(ctx context.Context, owner, name string) error {
 		State:     "all",
 		Sort:      "updated",
 		Direction: "desc",
-		PerPage:   100,
+		PerPage:   itemLimit(repo),
 		Page:      1,
 	}
 
-	issues, err := s.ghClient.ListIssues(owner, name, options)
+	issues, err := s.gh(ctx).ListIssues(owner, name, options)
 	if err != nil {
 		return fmt.Errorf("failed to list issues: %w", err)
 	}
 
-	// Process issues
-	for _, ghIssue := range issues {
-		// Create issue model
-		issue := &models.Issue{
-			RepositoryFullName: repo.FullName,
-			Number:             ghIssue.Number,
-			Title:              ghIssue.Title,
-			Body:               ghIssue.Body,
-			State:              ghIssue.State,
-			URL:                ghIssue.URL,
-			HTMLURL:            ghIssue.HTMLURL,
-			UserLogin:          ghIssue.User.Login,
-			UserAvatarURL:      ghIssue.User.AvatarURL,
-			UserURL:            ghIssue.User.URL,
-			UserHTMLURL:        ghIssue.User.HTMLURL,
-			CreatedAt:          ghIssue.CreatedAt,
-			UpdatedAt:          ghIssue.UpdatedAt,
-			ClosedAt:           ghIssue.ClosedAt,
-		}
-
-		// Check if issue exists
-		existingIssue, err := s.db.GetIssue(ctx, repo.FullName, ghIssue.Number)
-		if err == nil && existingIssue != nil {
-			// Update existing issue
-			if err := s.db.UpdateIssue(ctx, issue); err != nil {
+	associations := s.authorAssociations(ctx, owner, name, options.PerPage)
+
+	// Skip change events on the initial sync, when every issue is new
+	_, known, err := s.db.ListIssues(ctx, repo.FullName, 1, 1)
+	notifyChanges := err == nil && known > 0
+
+	cutoff, err := s.retentionCutoff(ctx, repo.FullName, models.ItemTypeIssue)
+	if err != nil {
+		return err
+	}
+
+	// Process issues in one batch, so readers see all of them or none. Notifiers, which may
+	// write to the database, hear of them once they are stored.
+	var events []*notify.Event
+	err = s.db.Batch(ctx, func(tx db.Tx) error {
+		for _, ghIssue := range issues {
+			// Issue listings may include pull requests, which are synced separately
+			if ghIssue.IsPullRequest() {
+				if _, err := tx.GetIssue(ctx, repo.FullName, ghIssue.Number); err == nil {
+					if err := tx.DeleteIssue(ctx, repo.FullName, ghIssue.Number); err != nil {
+						s.logger.Printf("Error removing pull request #%d of %s from issues: %v", ghIssue.Number, repo.FullName, err)
+					}
+				}
 				continue
 			}
-		} else {
-			// Add new issue
-			if err := s.db.AddIssue(ctx, issue); err != nil {
+
+			issue := s.issueModel(repo.FullName, ghIssue, associations[ghIssue.Number])
+
+			// Check if issue exists
+			existingIssue, _ := tx.GetIssue(ctx, repo.FullName, ghIssue.Number)
+			if existingIssue != nil {
+				issue.StateHistory = issueHistory(existingIssue, issue)
+				if issue.AuthorAssociation == "" {
+					issue.AuthorAssociation = existingIssue.AuthorAssociation
+				}
+			}
+
+			// Closed issues the retention policy dropped are not stored again
+			closed := !strings.EqualFold(issue.State, "open")
+			if existingIssue == nil && closed && closedTime(issue.ClosedAt, nil, issue.UpdatedAt).Before(cutoff) {
 				continue
 			}
-		}
 
-		// Process labels
-		for _, ghLabel := range ghIssue.Labels {
-			// Create label model
-			label := &models.Label{
-				Name:        ghLabel.Name,
-				Color:       ghLabel.Color,
-				Description: ghLabel.Description,
+			// Store the issue
+			created, err := tx.UpsertIssue(ctx, issue)
+			if err != nil {
+				continue
+			}
+			// Issues first seen closed were neither opened nor labeled since the last sync
+			announce := notifyChanges && !(created && closed)
+			switch {
+			case created:
+				if announce {
+					events = append(events, issueEvent(notify.EventIssueOpened, issue))
+				}
+			case existingIssue != nil:
+				if notifyChanges && !issue.UpdatedAt.Equal(existingIssue.UpdatedAt) {
+					events = append(events, issueEvent(notify.EventIssueUpdated, issue))
+				}
+				if notifyChanges && !strings.EqualFold(issue.State, existingIssue.State) {
+					events = append(events, &notify.Event{
+						Type:          notify.EventIssueStateChanged,
+						Repository:    issue.RepositoryFullName,
+						Number:        issue.Number,
+						Title:         issue.Title,
+						URL:           issue.HTMLURL,
+						Author:        issue.UserLogin,
+						State:         issue.State,
+						PreviousState: existingIssue.State,
+					})
+				}
 			}
 
-			// Check if label exists
-			existingLabel, err := s.db.GetLabel(ctx, ghLabel.Name)
-			if err != nil || existingLabel == nil {
-				// Add new label
-				if err := s.db.AddLabel(ctx, label); err != nil {
-					continue
+			// Remember the labels the issue already had
+			knownLabels := make(map[string]bool)
+			if existingLabels, err := tx.ListIssueLabels(ctx, repo.FullName, ghIssue.Number); err == nil {
+				for _, label := range existingLabels {
+					knownLabels[label.Name] = true
 				}
 			}
 
-			// Add label to issue
-			if err := s.db.AddIssueLabel(ctx, repo.FullName, ghIssue.Number, ghLabel.Name); err != nil {
-				// Ignore errors
+			// Process labels
+			for _, ghLabel := range ghIssue.Labels {
+				// Create label model
+				label := &models.Label{
+					Name:        ghLabel.Name,
+					Color:       ghLabel.Color,
+					Description: ghLabel.Description,
+				}
+
+				// Check if label exists
+				existingLabel, err := tx.GetLabel(ctx, ghLabel.Name)
+				if err != nil || existingLabel == nil {
+					// Add new label
+					if err := tx.AddLabel(ctx, label); err != nil {
+						continue
+					}
+				}
+
+				// Add label to issue
+				if err := tx.AddIssueLabel(ctx, repo.FullName, ghIssue.Number, ghLabel.Name); err != nil {
+					// Ignore errors
+				}
+
+				if announce && !knownLabels[ghLabel.Name] {
+					events = append(events, &notify.Event{
+						Type:       notify.EventIssueLabeled,
+						Repository: issue.RepositoryFullName,
+						Number:     issue.Number,
+						Title:      issue.Title,
+						URL:        issue.HTMLURL,
+						Author:     issue.UserLogin,
+						Label:      ghLabel.Name,
+					})
+				}
 			}
 		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store issues: %w", err)
+	}
+	for _, event := range events {
+		s.notifier.Dispatch(ctx, event)
 	}
 
-	return nil
-}
-
-// Pull request operations
+	// A listing shorter than the limit is complete, so stored issues missing from it were removed upstream
+	if len(issues) < options.PerPage {
+		if _, err := s.reconcileIssues(ctx, repo.FullName, issues); err != nil {
+			return err
+		}
+	}
 
-// ListPullRequests lists pull requests for a repository or across all repositories
-func (s *Service) ListPullRequests(ctx context.Context, filter *models.PullRequestFilter) ([]*models.PullRequest, *models.Pagination, error) {
-	return s.listAllPullRequests(ctx, filter)
+	return nil
 }
 
-// listAllPullRequests lists pull requests across all repositories or for a specific repository
-func (s *Service) listAllPullRequests(ctx context.Context, filter *models.PullRequestFilter) ([]*models.PullRequest, *models.Pagination, error) {
-	// Get repositories to process
+// selectRepositories returns the repositories a list query should cover.
+// An empty repo selects every tracked repository; a non-empty tag further
+// restricts the selection to repositories carrying that tag.
+func (s *Service) selectRepositories(ctx context.Context, fullName, tag string) ([]*models.Repository, error) {
 	var repos []*models.Repository
-	var err error
 
 	// If a specific repository is requested
-	if filter.Repo != "" {
+	if fullName != "" {
 		// Parse repository owner and name
-		parts := strings.Split(filter.Repo, "/")
+		parts := strings.Split(fullName, "/")
 		if len(parts) != 2 {
-			return nil, nil, ErrInvalidRepositoryName
+			return nil, ErrInvalidRepositoryName
 		}
 		owner, name := parts[0], parts[1]
 
 		// Get the specific repository
 		repo, err := s.db.GetRepository(ctx, owner, name)
 		if err != nil {
-			return nil, nil, ErrRepositoryNotFound
+			return nil, notFound(err, ErrRepositoryNotFound)
 		}
 		repos = []*models.Repository{repo}
 	} else {
 		// Get all repositories
-		repos, _, err = s.db.ListRepositories(ctx, 1, 1000) // Assuming we won't have more than 1000 repos
+		var err error
+		repos, err = s.db.ListAllRepositories(ctx)
 		if err != nil {
-			return nil, nil, fmt.Errorf("failed to list repositories: %w", err)
+			return nil, fmt.Errorf("failed to list repositories: %w", err)
 		}
 	}
 
-	// Collect all pull requests
-	var allPRs []*models.PullRequest
+	// Repositories outside the workspace of the context don't exist for it
+	repos, err := s.scopeRepositories(ctx, repos)
+	if err != nil {
+		return nil, err
+	}
+	if fullName != "" && len(repos) == 0 {
+		return nil, ErrRepositoryNotFound
+	}
+
+	if tag == "" {
+		return repos, nil
+	}
+
+	tagged := make([]*models.Repository, 0, len(repos))
 	for _, repo := range repos {
-		prs, _, err := s.db.ListPullRequests(ctx, repo.FullName, 1, 1000) // Get all PRs, we'll paginate later
-		if err != nil {
-			// Log error but continue
-			continue
+		if repo.HasTag(tag) {
+			tagged = append(tagged, repo)
 		}
-		allPRs = append(allPRs, prs...)
 	}
+	return tagged, nil
+}
 
-	// Apply filters
-	var filteredPRs []*models.PullRequest
-	for _, pr := range allPRs {
-		// Filter by state (case-insensitive comparison)
-		if filter.State != "" && !strings.EqualFold(pr.State, filter.State) {
-			continue
-		}
+// stateFilter returns the state to filter on, where "all" matches every state
+func stateFilter(state string) string {
+	if strings.EqualFold(state, "all") {
+		return ""
+	}
+	return state
+}
 
-		// Filter by author
-		if filter.Author != "" && !strings.EqualFold(pr.UserLogin, filter.Author) {
-			continue
-		}
+// queryRepositories returns the repository names an item query is restricted to.
+// It returns nil when every repository is selected, so the database need not filter by repository.
+func queryRepositories(ctx context.Context, repos []*models.Repository, fullName, tag string) []string {
+	if fullName == "" && tag == "" && workspaceFrom(ctx) == "" {
+		return nil
+	}
+
+	names := make([]string, 0, len(repos))
+	for _, repo := range repos {
+		names = append(names, repo.FullName)
+	}
+	return names
+}
 
-		// Filter by label (would need to fetch labels for each PR)
-		// This is simplified - in a real implementation, you'd need to check labels
+// defaultItemLimit is the number of items fetched per sync when a repository has no override
+const defaultItemLimit = 100
 
-		// Add to filtered list
-		filteredPRs = append(filteredPRs, pr)
+// itemLimit returns the number of items to fetch per sync for a repository
+func itemLimit(repo *models.Repository) int {
+	if repo.SyncConfig.ItemLimit > 0 {
+		return repo.SyncConfig.ItemLimit
 	}
+	return defaultItemLimit
+}
 
-	// Sort the PRs (simplified - in a real implementation, you'd need more complex sorting)
-	// For now, just sort by creation date
-	sort.Slice(filteredPRs, func(i, j int) bool {
-		if filter.Direction == "asc" {
-			return filteredPRs[i].CreatedAt.Before(filteredPRs[j].CreatedAt)
-		}
-		return filteredPRs[i].CreatedAt.After(filteredPRs[j].CreatedAt)
-	})
+// syncInterval returns how often a repository should be synced: its own interval, otherwise
+// the shortest interval of the workspaces including it, otherwise the global one
+func (s *Service) syncInterval(repo *models.Repository, workspaceIntervals map[string]time.Duration) time.Duration {
+	if repo.SyncConfig.SyncInterval > 0 {
+		return repo.SyncConfig.SyncInterval
+	}
+	if interval, ok := workspaceIntervals[repo.FullName]; ok {
+		return interval
+	}
+	return s.config.GitHub.RefreshInterval
+}
 
-	// Apply pagination
-	total := len(filteredPRs)
-	start := (filter.Page - 1) * filter.PerPage
-	if start >= total {
-		return []*models.PullRequest{}, &models.Pagination{
-			Page:       filter.Page,
-			PerPage:    filter.PerPage,
-			Total:      total,
-			TotalPages: (total + filter.PerPage - 1) / filter.PerPage,
-		}, nil
+// firstReviewAt returns when a pull request was first reviewed by someone other than its author
+func firstReviewAt(pr *github.PullRequest) *time.Time {
+	var first *time.Time
+	for i := range pr.Reviews {
+		review := &pr.Reviews[i]
+		if strings.EqualFold(review.User.Login, pr.User.Login) {
+			continue
+		}
+		if first == nil || review.SubmittedAt.Before(*first) {
+			first = &review.SubmittedAt
+		}
 	}
+	return first
+}
+
+// notifyPullRequest dispatches a pull request event
+func (s *Service) notifyPullRequest(ctx context.Context, eventType notify.EventType, pr *models.PullRequest) {
+	s.notifier.Dispatch(ctx, pullRequestEvent(eventType, pr))
+}
 
-	end := start + filter.PerPage
-	if end > total {
-		end = total
+// pullRequestEvent returns a pull request event
+func pullRequestEvent(eventType notify.EventType, pr *models.PullRequest) *notify.Event {
+	return &notify.Event{
+		Type:       eventType,
+		Repository: pr.RepositoryFullName,
+		Number:     pr.Number,
+		Title:      pr.Title,
+		URL:        pr.HTMLURL,
+		Author:     pr.UserLogin,
 	}
+}
+
+// notifyIssue dispatches an issue event
+func (s *Service) notifyIssue(ctx context.Context, eventType notify.EventType, issue *models.Issue) {
+	s.notifier.Dispatch(ctx, issueEvent(eventType, issue))
+}
 
-	// Create pagination
-	pagination := &models.Pagination{
-		Page:       filter.Page,
-		PerPage:    filter.PerPage,
-		Total:      total,
-		TotalPages: (total + filter.PerPage - 1) / filter.PerPage,
+// issueEvent returns an issue event
+func issueEvent(eventType notify.EventType, issue *models.Issue) *notify.Event {
+	return &notify.Event{
+		Type:       eventType,
+		Repository: issue.RepositoryFullName,
+		Number:     issue.Number,
+		Title:      issue.Title,
+		URL:        issue.HTMLURL,
+		Author:     issue.UserLogin,
 	}
+}
 
-	return filteredPRs[start:end], pagination, nil
+// notifySyncFailure dispatches a sync failure event
+func (s *Service) notifySyncFailure(ctx context.Context, fullName string, err error) {
+	s.notifier.Dispatch(ctx, &notify.Event{
+		Type:       notify.EventSyncFailed,
+		Repository: fullName,
+		Error:      err.Error(),
+	})
 }
 
-// Issue operations
+// Pull request operations
 
-// ListIssues lists issues for a repository or across all repositories
-func (s *Service) ListIssues(ctx context.Context, filter *models.IssueFilter) ([]*models.Issue, *models.Pagination, error) {
-	return s.listAllIssues(ctx, filter)
+// ListPullRequests lists pull requests for a repository or across all repositories
+func (s *Service) ListPullRequests(ctx context.Context, filter *models.PullRequestFilter) ([]*models.PullRequest, *models.Pagination, error) {
+	ctx, span := s.tracer.Start(ctx, "service.ListPullRequests")
+	defer span.End()
+
+	prs, pagination, err := s.listAllPullRequests(ctx, filter)
+	tracing.RecordError(span, err)
+	return prs, pagination, err
 }
 
-// listAllIssues lists issues across all repositories or for a specific repository
-func (s *Service) listAllIssues(ctx context.Context, filter *models.IssueFilter) ([]*models.Issue, *models.Pagination, error) {
-	// Get repositories to process
-	var repos []*models.Repository
-	var err error
+// listAllPullRequests lists pull requests across all repositories or for a specific repository
+func (s *Service) listAllPullRequests(ctx context.Context, filter *models.PullRequestFilter) ([]*models.PullRequest, *models.Pagination, error) {
+	filteredPRs, err := s.findPullRequests(ctx, filter)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	// If a specific repository is requested
-	if filter.Repo != "" {
-		// Parse repository owner and name
-		parts := strings.Split(filter.Repo, "/")
-		if len(parts) != 2 {
-			return nil, nil, ErrInvalidRepositoryName
-		}
-		owner, name := parts[0], parts[1]
+	// Sort by creation date, breaking ties by repository and number so pages are stable
+	less := itemLess(filter.Direction)
+	sort.Slice(filteredPRs, func(i, j int) bool {
+		return less(pullRequestKey(filteredPRs[i]), pullRequestKey(filteredPRs[j]))
+	})
 
-		// Get the specific repository
-		repo, err := s.db.GetRepository(ctx, owner, name)
-		if err != nil {
-			return nil, nil, ErrRepositoryNotFound
-		}
-		repos = []*models.Repository{repo}
-	} else {
-		// Get all repositories
-		repos, _, err = s.db.ListRepositories(ctx, 1, 1000) // Assuming we won't have more than 1000 repos
-		if err != nil {
-			return nil, nil, fmt.Errorf("failed to list repositories: %w", err)
-		}
+	// Apply offset or cursor pagination
+	key := func(i int) cursorKey { return pullRequestKey(filteredPRs[i]) }
+	start, end, pagination, err := window(len(filteredPRs), key, less, filter.Page, filter.PerPage, filter.Cursor)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	// Collect all issues
-	var allIssues []*models.Issue
-	for _, repo := range repos {
-		issues, _, err := s.db.ListIssues(ctx, repo.FullName, 1, 1000) // Get all issues, we'll paginate later
-		if err != nil {
-			// Log error but continue
-			continue
-		}
-		allIssues = append(allIssues, issues...)
+	return filteredPRs[start:end], pagination, nil
+}
+
+// findPullRequests returns the pull requests matching the filters of a list, in no particular order
+func (s *Service) findPullRequests(ctx context.Context, filter *models.PullRequestFilter) ([]*models.PullRequest, error) {
+	// Get repositories to process
+	repos, err := s.selectRepositories(ctx, filter.Repo, filter.RepoTag)
+	if err != nil {
+		return nil, err
 	}
+	s.refreshStalePullRequests(ctx, repos)
+
+	// Let the database answer the filters from its indexes
+	filteredPRs, err := s.db.FindPullRequests(ctx, &models.ItemQuery{
+		Repositories: queryRepositories(ctx, repos, filter.Repo, filter.RepoTag),
+		State:        stateFilter(filter.State),
+		Author:       filter.Author,
+		Label:        filter.Label,
+		UpdatedSince: filter.Since,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find pull requests: %w", err)
+	}
+	if !filter.IncludeTombstoned {
+		filteredPRs = livePullRequests(filteredPRs)
+	}
+	filteredPRs = filterPullRequestAuthors(filteredPRs, filter.ExcludeBots, filter.Association)
+	filteredPRs = s.filterPullRequestTeam(filteredPRs, filter.Team)
+	filteredPRs = filterPullRequestJira(filteredPRs, filter.Jira)
+	if filteredPRs, err = s.filterPullRequestOwners(repos, filteredPRs, filter.OwnedByMe, filter.OwnedByTeam); err != nil {
+		return nil, err
+	}
+	if filteredPRs, err = s.filterPullRequestChanges(filteredPRs, filter.Path, filter.Size); err != nil {
+		return nil, err
+	}
+	if filteredPRs, err = s.filterPullRequestProjects(ctx, filteredPRs, filter.Project, filter.ProjectStatus); err != nil {
+		return nil, err
+	}
+	return filteredPRs, nil
+}
 
-	// Apply filters
-	var filteredIssues []*models.Issue
-	for _, issue := range allIssues {
-		// Filter by state (case-insensitive comparison)
-		if filter.State != "" && !strings.EqualFold(issue.State, filter.State) {
-			continue
-		}
+// Issue operations
 
-		// Filter by author
-		if filter.Author != "" && !strings.EqualFold(issue.UserLogin, filter.Author) {
-			continue
-		}
+// ListIssues lists issues for a repository or across all repositories
+func (s *Service) ListIssues(ctx context.Context, filter *models.IssueFilter) ([]*models.Issue, *models.Pagination, error) {
+	ctx, span := s.tracer.Start(ctx, "service.ListIssues")
+	defer span.End()
 
-		// Filter by label (would need to fetch labels for each issue)
-		// This is simplified - in a real implementation, you'd need to check labels
+	issues, pagination, err := s.listAllIssues(ctx, filter)
+	tracing.RecordError(span, err)
+	return issues, pagination, err
+}
 
-		// Add to filtered list
-		filteredIssues = append(filteredIssues, issue)
+// listAllIssues lists issues across all repositories or for a specific repository
+func (s *Service) listAllIssues(ctx context.Context, filter *models.IssueFilter) ([]*models.Issue, *models.Pagination, error) {
+	filteredIssues, err := s.findIssues(ctx, filter)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	// Sort the issues (simplified - in a real implementation, you'd need more complex sorting)
-	// For now, just sort by creation date
+	// Sort by creation date, breaking ties by repository and number so pages are stable
+	less := itemLess(filter.Direction)
 	sort.Slice(filteredIssues, func(i, j int) bool {
-		if filter.Direction == "asc" {
-			return filteredIssues[i].CreatedAt.Before(filteredIssues[j].CreatedAt)
-		}
-		return filteredIssues[i].CreatedAt.After(filteredIssues[j].CreatedAt)
+		return less(issueKey(filteredIssues[i]), issueKey(filteredIssues[j]))
 	})
 
-	// Apply pagination
-	total := len(filteredIssues)
-	start := (filter.Page - 1) * filter.PerPage
-	if start >= total {
-		return []*models.Issue{}, &models.Pagination{
-			Page:       filter.Page,
-			PerPage:    filter.PerPage,
-			Total:      total,
-			TotalPages: (total + filter.PerPage - 1) / filter.PerPage,
-		}, nil
+	// Apply offset or cursor pagination
+	key := func(i int) cursorKey { return issueKey(filteredIssues[i]) }
+	start, end, pagination, err := window(len(filteredIssues), key, less, filter.Page, filter.PerPage, filter.Cursor)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	end := start + filter.PerPage
-	if end > total {
-		end = total
-	}
+	return filteredIssues[start:end], pagination, nil
+}
 
-	// Create pagination
-	pagination := &models.Pagination{
-		Page:       filter.Page,
-		PerPage:    filter.PerPage,
-		Total:      total,
-		TotalPages: (total + filter.PerPage - 1) / filter.PerPage,
+// findIssues returns the issues matching the filters of a list, in no particular order
+func (s *Service) findIssues(ctx context.Context, filter *models.IssueFilter) ([]*models.Issue, error) {
+	// Get repositories to process
+	repos, err := s.selectRepositories(ctx, filter.Repo, filter.RepoTag)
+	if err != nil {
+		return nil, err
 	}
-
-	return filteredIssues[start:end], pagination, nil
+	s.refreshStaleIssues(ctx, repos)
+
+	// Let the database answer the filters from its indexes
+	filteredIssues, err := s.db.FindIssues(ctx, &models.ItemQuery{
+		Repositories: queryRepositories(ctx, repos, filter.Repo, filter.RepoTag),
+		State:        stateFilter(filter.State),
+		Author:       filter.Author,
+		Label:        filter.Label,
+		UpdatedSince: filter.Since,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find issues: %w", err)
+	}
+	if !filter.IncludeTombstoned {
+		filteredIssues = liveIssues(filteredIssues)
+	}
+	filteredIssues = filterIssueAuthors(filteredIssues, filter.ExcludeBots, filter.Association)
+	filteredIssues = s.filterIssueTeam(filteredIssues, filter.Team)
+	filteredIssues = filterIssueJira(filteredIssues, filter.Jira)
+	if filteredIssues, err = s.filterIssueProjects(ctx, filteredIssues, filter.Project, filter.ProjectStatus); err != nil {
+		return nil, err
+	}
+	return filteredIssues, nil
 }
 
 // Service operations
 
-// RefreshAll forces a refresh of all repository data
+// RefreshAll forces a refresh of all repository data, highest priority first
 func (s *Service) RefreshAll(ctx context.Context) error {
-	// Get all repositories
-	repos, _, err := s.db.ListRepositories(ctx, 1, 1000) // Assuming we won't have more than 1000 repos
-	if err != nil {
-		return fmt.Errorf("failed to list repositories: %w", err)
+	_, err := s.refreshPlanned(ctx, false, nil)
+	return err
+}
+
+// RefreshAllWithProgress is RefreshAll calling progress with the sync job of each repository when
+// it is queued and whenever its state changes. It returns the final state of the jobs; failed
+// syncs don't fail the refresh.
+func (s *Service) RefreshAllWithProgress(ctx context.Context, progress func(*models.Job)) ([]*models.Job, error) {
+	return s.refreshPlanned(ctx, false, progress)
+}
+
+// RefreshDue refreshes the repositories whose sync interval has elapsed
+// since they were last synced and returns how many were refreshed.
+// Higher priority repositories are synced first and get the rate limit budget.
+// With github.track_starred, the starred repositories are reconciled first.
+func (s *Service) RefreshDue(ctx context.Context) (int, error) {
+	if s.config.GitHub.TrackStarred && workspaceFrom(ctx) == "" && !s.config.GitHub.Offline {
+		s.reconcileStarred(ctx)
 	}
+	jobs, err := s.refreshPlanned(ctx, true, nil)
+	return len(jobs), err
+}
 
-	// Refresh each repository
-	wg := sync.WaitGroup{}
+// refreshPlanned syncs the repositories a refresh plan selects, in plan order, returning their jobs
+func (s *Service) refreshPlanned(ctx context.Context, dueOnly bool, progress func(*models.Job)) ([]*models.Job, error) {
+	if s.config.GitHub.Offline {
+		return nil, github.ErrOffline
+	}
+	repos, err := s.db.ListAllRepositories(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list repositories: %w", err)
+	}
+	if repos, err = s.scopeRepositories(ctx, repos); err != nil {
+		return nil, err
+	}
+	byName := make(map[string]*models.Repository, len(repos))
 	for _, repo := range repos {
-		wg.Add(1)
-		go func(owner, name string) {
-			defer wg.Done()
-			syncCtx := context.Background()
-			log.Printf("Refreshing repository: %s/%s", owner, name)
-			if err := s.syncRepository(syncCtx, owner, name); err != nil {
-				// Log the error but don't return it since we're in a goroutine
-				fmt.Printf("Error refreshing repository %s/%s: %v\n", owner, name, err)
-			}
-		}(repo.Owner, repo.Name)
+		byName[repo.FullName] = repo
 	}
-	wg.Wait()
-	return nil
+
+	plan := s.planRefresh(ctx, repos, false, dueOnly)
+	selected := make([]*models.Repository, 0, len(plan.Repositories))
+	for _, repoPlan := range plan.Repositories {
+		switch repoPlan.Skip {
+		case "":
+			selected = append(selected, byName[repoPlan.FullName])
+		case skipNotDue:
+		default:
+			s.logger.Printf("Skipping repository %s: %s", repoPlan.FullName, repoPlan.Skip)
+		}
+	}
+
+	action := models.AuditRefreshAll
+	if dueOnly {
+		action = models.AuditRefreshDue
+	}
+	s.audit(ctx, action, "", fmt.Sprintf("%d repositories", len(selected)))
+
+	return s.syncRepositories(ctx, selected, progress), nil
 }
 
 // GetStatus returns the current status of the service
 func (s *Service) GetStatus(ctx context.Context) (map[string]interface{}, error) {
 	// Get all repositories
-	repos, total, err := s.db.ListRepositories(ctx, 1, 1000)
+	repos, err := s.db.ListAllRepositories(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list repositories: %w", err)
 	}
@@ -643,18 +1570,49 @@ func (s *Service) GetStatus(ctx context.Context) (map[string]interface{}, error)
 	}
 	s.syncMutex.Unlock()
 
-	// Get rate limit
-	rateLimit, err := s.ghClient.GetRateLimit()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get rate limit: %w", err)
+	// Get rate limit, unknown offline
+	var rateLimit *github.RateLimit
+	if !s.config.GitHub.Offline {
+		if rateLimit, err = s.gh(ctx).GetRateLimit(); err != nil {
+			return nil, fmt.Errorf("failed to get rate limit: %w", err)
+		}
 	}
 
-	// Find last sync time
+	// Find last sync time and count paused repositories
 	var lastSync time.Time
+	var apiRequests int64
+	paused := 0
 	for _, repo := range repos {
+		apiRequests += repo.APIUsage.TotalRequests
 		if repo.LastSyncedAt.After(lastSync) {
 			lastSync = repo.LastSyncedAt
 		}
+		if repo.Paused {
+			paused++
+		}
+	}
+
+	// Get storage stats
+	storage, err := s.db.Stats(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get storage stats: %w", err)
+	}
+
+	pool := s.ghClient.PoolStats()
+
+	// Rate limits of the rotated tokens, refreshed by the rate limit check above
+	var tokens []map[string]interface{}
+	if reporter, ok := s.ghClient.(github.TokenReporter); ok {
+		for _, token := range reporter.TokenStats() {
+			tokens = append(tokens, map[string]interface{}{
+				"name":      token.Name,
+				"limit":     token.Limit,
+				"remaining": token.Remaining,
+				"reset_at":  token.ResetTime,
+				"calls":     token.Calls,
+				"active":    token.Active,
+			})
+		}
 	}
 
 	// Build status
@@ -663,16 +1621,43 @@ func (s *Service) GetStatus(ctx context.Context) (map[string]interface{}, error)
 		"version": "1.0.0",
 		"uptime":  int(time.Since(s.startTime).Seconds()),
 		"repositories": map[string]interface{}{
-			"total":   total,
+			"total":   len(repos),
 			"syncing": syncing,
+			"paused":  paused,
 			"error":   errors,
 		},
 		"last_sync": lastSync,
-		"github_rate_limit": map[string]interface{}{
+		"api_usage": map[string]interface{}{
+			"total_requests": apiRequests,
+		},
+		"github_tokens": tokens,
+		"github_pool": map[string]interface{}{
+			"max_concurrent": pool.MaxConcurrent,
+			"running":        pool.Running,
+			"waiting":        pool.Waiting,
+			"peak_running":   pool.PeakRunning,
+			"calls":          pool.Calls,
+			"failures":       pool.Failures,
+			"total_wait":     pool.TotalWait.String(),
+		},
+		"storage": map[string]interface{}{
+			"pull_requests":            storage.PullRequests,
+			"issues":                   storage.Issues,
+			"estimated_bytes":          storage.EstimatedBytes,
+			"evicted_pull_requests":    storage.EvictedPullRequests,
+			"evicted_issues":           storage.EvictedIssues,
+			"tombstoned_pull_requests": storage.TombstonedPullRequests,
+			"tombstoned_issues":        storage.TombstonedIssues,
+		},
+	}
+	if rateLimit != nil {
+		status["github_rate_limit"] = map[string]interface{}{
 			"limit":     rateLimit.Limit,
 			"remaining": rateLimit.Remaining,
 			"reset_at":  time.Unix(rateLimit.Reset, 0),
-		},
+		}
+	} else {
+		status["status"] = "offline"
 	}
 
 	return status, nil
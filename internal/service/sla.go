@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// SLAReport lists the open pull requests and issues of the tracked repositories matching the filter
+// that are past the deadline of a configured SLA policy, most overdue first, counted per repository
+// and per configured team
+func (s *Service) SLAReport(ctx context.Context, filter *models.SLAFilter) (*models.SLAReport, error) {
+	policies, err := s.slaPolicies(filter.Policy)
+	if err != nil {
+		return nil, err
+	}
+	repos, err := s.selectRepositories(ctx, filter.Repo, filter.RepoTag)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	report := &models.SLAReport{Breaches: make([]*models.SLABreach, 0), ByRepository: make(map[string]int), ByTeam: make(map[string]int)}
+	for _, policy := range policies {
+		breaches, err := s.slaBreaches(ctx, repos, policy, now)
+		if err != nil {
+			return nil, err
+		}
+		for _, breach := range breaches {
+			if filter.Team != "" && !containsFold(breach.Teams, filter.Team) {
+				continue
+			}
+			report.Breaches = append(report.Breaches, breach)
+			report.ByRepository[breach.RepositoryFullName]++
+			for _, team := range breach.Teams {
+				report.ByTeam[team]++
+			}
+		}
+	}
+	sort.SliceStable(report.Breaches, func(i, j int) bool {
+		return report.Breaches[i].Deadline.Before(report.Breaches[j].Deadline)
+	})
+	return report, nil
+}
+
+// slaPolicies returns the configured SLA policies, only the one named when name isn't empty
+func (s *Service) slaPolicies(name string) ([]config.SLAPolicy, error) {
+	if len(s.config.SLA.Policies) == 0 {
+		return nil, ErrSLANotConfigured
+	}
+	policies := make([]config.SLAPolicy, 0, len(s.config.SLA.Policies))
+	for _, policy := range s.config.SLA.Policies {
+		if err := validateSLAPolicy(policy); err != nil {
+			return nil, err
+		}
+		if name == "" || strings.EqualFold(policy.Name, name) {
+			policies = append(policies, policy)
+		}
+	}
+	if len(policies) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrSLAPolicyNotFound, name)
+	}
+	return policies, nil
+}
+
+// validateSLAPolicy checks that a policy names its items, a target they support and a time limit
+func validateSLAPolicy(policy config.SLAPolicy) error {
+	switch {
+	case policy.Name == "":
+		return fmt.Errorf("%w: a name is required", ErrInvalidSLAPolicy)
+	case policy.Type != models.ItemTypePullRequest && policy.Type != models.ItemTypeIssue:
+		return fmt.Errorf("%w: %s: type must be pull_request or issue", ErrInvalidSLAPolicy, policy.Name)
+	case policy.Target != config.SLATargetClose && (policy.Target != config.SLATargetFirstReview || policy.Type != models.ItemTypePullRequest):
+		return fmt.Errorf("%w: %s: target must be close, or first_review for pull requests", ErrInvalidSLAPolicy, policy.Name)
+	case policy.Within <= 0:
+		return fmt.Errorf("%w: %s: within must be positive", ErrInvalidSLAPolicy, policy.Name)
+	}
+	return nil
+}
+
+// slaBreaches returns the open items of some repositories past the deadline of a policy at a time
+func (s *Service) slaBreaches(ctx context.Context, repos []*models.Repository, policy config.SLAPolicy, now time.Time) ([]*models.SLABreach, error) {
+	names := make([]string, 0, len(repos))
+	for _, repo := range repos {
+		if len(policy.Repositories) == 0 || containsFold(policy.Repositories, repo.FullName) {
+			names = append(names, repo.FullName)
+		}
+	}
+	if len(names) == 0 {
+		return nil, nil
+	}
+	query := &models.ItemQuery{Repositories: names, State: "open", Label: policy.Label}
+
+	var breaches []*models.SLABreach
+	if policy.Type == models.ItemTypePullRequest {
+		prs, err := s.db.FindPullRequests(ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find pull requests: %w", err)
+		}
+		for _, pr := range livePullRequests(prs) {
+			start := pr.CreatedAt
+			if policy.Target == config.SLATargetFirstReview {
+				if pr.Draft || pr.FirstReviewAt != nil {
+					continue
+				}
+				start = reviewWaitingSince(pr)
+			}
+			if deadline := s.hours.Add(start, policy.Within); now.After(deadline) {
+				teams, logins := pullRequestTeams(pr)
+				breaches = append(breaches, &models.SLABreach{
+					Policy:             policy.Name,
+					RepositoryFullName: pr.RepositoryFullName,
+					Type:               models.ItemTypePullRequest,
+					Number:             pr.Number,
+					Title:              pr.Title,
+					HTMLURL:            pr.HTMLURL,
+					Author:             pr.UserLogin,
+					Teams:              s.configuredTeams(teams, append(logins, pr.UserLogin)),
+					Deadline:           deadline,
+				})
+			}
+		}
+		return breaches, nil
+	}
+
+	issues, err := s.db.FindIssues(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find issues: %w", err)
+	}
+	for _, issue := range liveIssues(issues) {
+		if deadline := s.hours.Add(issue.CreatedAt, policy.Within); now.After(deadline) {
+			teams, logins := issueTeams(issue)
+			breaches = append(breaches, &models.SLABreach{
+				Policy:             policy.Name,
+				RepositoryFullName: issue.RepositoryFullName,
+				Type:               models.ItemTypeIssue,
+				Number:             issue.Number,
+				Title:              issue.Title,
+				HTMLURL:            issue.HTMLURL,
+				Author:             issue.UserLogin,
+				Teams:              s.configuredTeams(teams, append(logins, issue.UserLogin)),
+				Deadline:           deadline,
+			})
+		}
+	}
+	return breaches, nil
+}
+
+// configuredTeams returns the names of the configured teams involved by teams and logins, sorted
+func (s *Service) configuredTeams(teams, logins []string) []string {
+	var involved []string
+	for name := range s.config.Teams {
+		if s.teamMatcher(name)(teams, logins) {
+			involved = append(involved, name)
+		}
+	}
+	sort.Strings(involved)
+	return involved
+}
+
+// containsFold reports whether list contains s, ignoring case
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
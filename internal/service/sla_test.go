@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/db/file"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+func TestSLAReport(t *testing.T) {
+	ctx := context.Background()
+	db, err := file.NewDB("")
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	for _, name := range []string{"api", "web"} {
+		if err := db.AddRepository(ctx, &models.Repository{Owner: "org", Name: name, FullName: "org/" + name}); err != nil {
+			t.Fatalf("AddRepository() error = %v", err)
+		}
+	}
+
+	now := time.Now()
+	days := func(n int) time.Time { return now.Add(-time.Duration(n) * 24 * time.Hour) }
+	reviewed := days(1)
+	prs := []*models.PullRequest{
+		{RepositoryFullName: "org/api", Number: 1, State: "open", CreatedAt: days(3), UserLogin: "alice"},
+		{RepositoryFullName: "org/api", Number: 2, State: "open", CreatedAt: days(3), FirstReviewAt: &reviewed},
+		{RepositoryFullName: "org/web", Number: 1, State: "open", CreatedAt: days(1)},
+		{RepositoryFullName: "org/web", Number: 2, State: "open", CreatedAt: days(5), Draft: true},
+	}
+	for _, pr := range prs {
+		if err := db.AddPullRequest(ctx, pr); err != nil {
+			t.Fatalf("AddPullRequest() error = %v", err)
+		}
+	}
+	issues := []*models.Issue{
+		{RepositoryFullName: "org/web", Number: 3, State: "open", CreatedAt: days(10), Assignees: []string{"bob"}},
+		{RepositoryFullName: "org/web", Number: 4, State: "open", CreatedAt: days(10)},
+	}
+	for _, issue := range issues {
+		if err := db.AddIssue(ctx, issue); err != nil {
+			t.Fatalf("AddIssue() error = %v", err)
+		}
+	}
+	if err := db.AddIssueLabel(ctx, "org/web", 3, "P0"); err != nil {
+		t.Fatalf("AddIssueLabel() error = %v", err)
+	}
+
+	cfg := &config.Config{Teams: map[string][]string{"backend": {"alice"}, "frontend": {"bob"}}}
+	s := &Service{db: db, config: cfg, logger: log.New(io.Discard, "", 0)}
+	if _, err := s.SLAReport(ctx, &models.SLAFilter{}); !errors.Is(err, ErrSLANotConfigured) {
+		t.Fatalf("SLAReport() without policies error = %v, want ErrSLANotConfigured", err)
+	}
+
+	cfg.SLA.Policies = []config.SLAPolicy{
+		{Name: "first-review", Type: models.ItemTypePullRequest, Target: config.SLATargetFirstReview, Within: 48 * time.Hour},
+		{Name: "p0", Type: models.ItemTypeIssue, Target: config.SLATargetClose, Within: 7 * 24 * time.Hour, Label: "P0"},
+	}
+	report, err := s.SLAReport(ctx, &models.SLAFilter{})
+	if err != nil {
+		t.Fatalf("SLAReport() error = %v", err)
+	}
+	var got []string
+	for _, breach := range report.Breaches {
+		got = append(got, breach.Policy+" "+breach.RepositoryFullName)
+	}
+	if want := []string{"p0 org/web", "first-review org/api"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("breaches = %v, want %v", got, want)
+	}
+	if want := map[string]int{"backend": 1, "frontend": 1}; !reflect.DeepEqual(report.ByTeam, want) {
+		t.Errorf("by team = %v, want %v", report.ByTeam, want)
+	}
+
+	report, err = s.SLAReport(ctx, &models.SLAFilter{Team: "frontend"})
+	if err != nil || len(report.Breaches) != 1 || report.Breaches[0].Number != 3 {
+		t.Errorf("SLAReport(frontend) = %v, %v, want org/web#3", report, err)
+	}
+	if _, err := s.SLAReport(ctx, &models.SLAFilter{Policy: "merge"}); !errors.Is(err, ErrSLAPolicyNotFound) {
+		t.Errorf("SLAReport(unknown policy) error = %v, want ErrSLAPolicyNotFound", err)
+	}
+
+	cfg.SLA.Policies = append(cfg.SLA.Policies, config.SLAPolicy{Name: "triage", Type: models.ItemTypeIssue, Target: config.SLATargetFirstReview, Within: time.Hour})
+	if _, err := s.SLAReport(ctx, &models.SLAFilter{}); !errors.Is(err, ErrInvalidSLAPolicy) {
+		t.Errorf("SLAReport(first_review of issues) error = %v, want ErrInvalidSLAPolicy", err)
+	}
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+	"github.com/siddontang/github-repos-management/internal/sso"
+)
+
+// ssoProvider creates the configured identity provider
+func (s *Service) ssoProvider(ctx context.Context) (*sso.Provider, error) {
+	cfg := s.config.SSO
+	if cfg.Provider == "" || cfg.SessionSecret == "" {
+		return nil, ErrSSONotConfigured
+	}
+	return sso.NewProvider(ctx, sso.Options{
+		Provider:     cfg.Provider,
+		Issuer:       cfg.Issuer,
+		ClientID:     cfg.ClientID,
+		ClientSecret: cfg.ClientSecret,
+		Scopes:       cfg.Scopes,
+
+		AllowedUsers:   cfg.AllowedUsers,
+		AllowedOrgs:    cfg.AllowedOrgs,
+		AllowedDomains: cfg.AllowedDomains,
+	})
+}
+
+// StartLogin begins a device login with the configured identity provider,
+// returning the code the user enters at the provider
+func (s *Service) StartLogin(ctx context.Context) (*sso.DeviceCode, error) {
+	provider, err := s.ssoProvider(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return provider.StartLogin(ctx)
+}
+
+// CompleteLogin waits until the user entered the code, then issues a session token for them
+func (s *Service) CompleteLogin(ctx context.Context, code *sso.DeviceCode) (string, *sso.Session, error) {
+	provider, err := s.ssoProvider(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+	identity, err := provider.CompleteLogin(ctx, code)
+	if err != nil {
+		return "", nil, err
+	}
+
+	token, session, err := sso.IssueSession([]byte(s.config.SSO.SessionSecret), identity, s.config.SSO.SessionTTL)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to issue session: %w", err)
+	}
+	s.audit(WithActor(ctx, identity.User()), models.AuditSessionLogin, "", identity.Provider)
+	return token, session, nil
+}
+
+// Authenticate verifies a session token and returns a context attributing changes to its user.
+// Without a token the context is returned unchanged, unless sessions are required. Workspace
+// tokens restrict the context to their workspace and come without a session.
+func (s *Service) Authenticate(ctx context.Context, token string) (context.Context, *sso.Session, error) {
+	if token == "" {
+		if s.config.SSO.Required {
+			return nil, nil, ErrSessionRequired
+		}
+		return ctx, nil, nil
+	}
+	if strings.HasPrefix(token, workspaceTokenPrefix) {
+		ctx, err := s.authenticateWorkspaceToken(ctx, token)
+		return ctx, nil, err
+	}
+	if s.config.SSO.SessionSecret == "" {
+		return nil, nil, ErrSSONotConfigured
+	}
+
+	session, err := sso.VerifySession([]byte(s.config.SSO.SessionSecret), token)
+	if err != nil {
+		return nil, nil, err
+	}
+	return WithActor(ctx, session.User()), session, nil
+}
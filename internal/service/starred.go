@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/siddontang/github-repos-management/internal/github"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// maxStarredRepositories bounds how many starred repositories are tracked automatically
+const maxStarredRepositories = 1000
+
+// reconcileStarred tracks the non-archived repositories the authenticated user stars and untracks
+// those it tracked earlier that are no longer starred. Repositories added explicitly are never
+// untracked. Failures are logged, leaving the tracked repositories as they are.
+func (s *Service) reconcileStarred(ctx context.Context) {
+	starred, err := s.gh(ctx).ListUserRepositories(github.RelationStarred, maxStarredRepositories)
+	if err != nil {
+		s.logger.Printf("Error listing starred repositories: %v", err)
+		return
+	}
+	tracked, err := s.db.ListAllRepositories(ctx)
+	if err != nil {
+		s.logger.Printf("Error listing repositories: %v", err)
+		return
+	}
+
+	known := make(map[string]bool, len(tracked))
+	for _, repo := range tracked {
+		known[strings.ToLower(repo.FullName)] = true
+	}
+	wanted := make(map[string]bool, len(starred))
+	var added []*models.Repository
+	for _, ghRepo := range starred {
+		if ghRepo.Archived {
+			continue
+		}
+		key := strings.ToLower(ghRepo.FullName)
+		wanted[key] = true
+		if known[key] {
+			continue
+		}
+
+		repo, created, err := s.trackRepository(ctx, ghRepo.FullName)
+		if err != nil {
+			s.logger.Printf("Error tracking starred repository %s: %v", ghRepo.FullName, err)
+			continue
+		}
+		if !created {
+			continue
+		}
+		repo, err = s.updateRepository(ctx, repo.Owner, repo.Name, func(repo *models.Repository) bool {
+			repo.TrackedBy = models.TrackedByStarred
+			return true
+		})
+		if err != nil {
+			s.logger.Printf("Error marking starred repository %s: %v", ghRepo.FullName, err)
+			continue
+		}
+		added = append(added, repo)
+	}
+
+	// A truncated list can't tell which repositories were unstarred
+	removed := 0
+	if len(starred) < maxStarredRepositories {
+		for _, repo := range tracked {
+			if repo.TrackedBy != models.TrackedByStarred || wanted[strings.ToLower(repo.FullName)] {
+				continue
+			}
+			if err := s.DeleteRepository(ctx, repo.Owner, repo.Name); err != nil {
+				s.logger.Printf("Error untracking unstarred repository %s: %v", repo.FullName, err)
+				continue
+			}
+			removed++
+		}
+	}
+
+	if len(added) > 0 || removed > 0 {
+		s.logger.Printf("Reconciled starred repositories: %d tracked, %d untracked", len(added), removed)
+	}
+	s.syncRepositories(ctx, added, nil)
+}
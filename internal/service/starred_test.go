@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"io"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/db/file"
+	"github.com/siddontang/github-repos-management/internal/github"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// starredGitHub serves empty repositories and the repositories the user stars
+type starredGitHub struct {
+	starred *[]*github.Repository
+}
+
+func (g starredGitHub) GetRepository(owner, name string) (*github.Repository, error) {
+	return &github.Repository{Owner: github.User{Login: owner}, Name: name, FullName: owner + "/" + name}, nil
+}
+
+func (g starredGitHub) ListOrganizationRepositories(org string, limit int) ([]string, error) {
+	return nil, nil
+}
+
+func (g starredGitHub) ListUserRepositories(relation string, limit int) ([]*github.Repository, error) {
+	if relation != github.RelationStarred {
+		return nil, nil
+	}
+	return *g.starred, nil
+}
+
+func (g starredGitHub) ListPullRequests(owner, name string, options *github.PullRequestOptions) ([]*github.PullRequest, error) {
+	return nil, nil
+}
+
+func (g starredGitHub) ListIssues(owner, name string, options *github.IssueOptions) ([]*github.Issue, error) {
+	return nil, nil
+}
+
+func (g starredGitHub) ListAuthorAssociations(owner, name string, limit int) (map[int]string, error) {
+	return nil, nil
+}
+
+func (g starredGitHub) ListMilestones(owner, name string) ([]*github.Milestone, error) {
+	return nil, nil
+}
+
+func (g starredGitHub) ListReleases(owner, name string, limit int) ([]*github.Release, error) {
+	return nil, nil
+}
+
+func (g starredGitHub) ListDependabotAlerts(owner, name string) ([]*github.SecurityAlert, error) {
+	return nil, nil
+}
+
+func (g starredGitHub) ListCodeScanningAlerts(owner, name string) ([]*github.SecurityAlert, error) {
+	return nil, nil
+}
+
+func (g starredGitHub) ListCommits(owner, name string, since time.Time, limit int) ([]*github.Commit, error) {
+	return nil, nil
+}
+
+func (g starredGitHub) ListLabels(owner, name string) ([]*github.Label, error) {
+	return nil, nil
+}
+
+func (g starredGitHub) UpdateLabel(owner, name, label string, update *github.LabelUpdate) (*github.Label, error) {
+	return nil, nil
+}
+
+func (g starredGitHub) ListIssueTemplates(owner, name string) ([]*github.IssueTemplate, error) {
+	return nil, nil
+}
+
+func (g starredGitHub) ListDiscussions(owner, name string, limit int) ([]*github.Discussion, error) {
+	return nil, nil
+}
+
+func (g starredGitHub) ListProjects(owner, name string) ([]*github.Project, error) {
+	return nil, nil
+}
+
+func (g starredGitHub) ListProjectItems(owner, name string, limit int) ([]*github.ProjectItem, error) {
+	return nil, nil
+}
+
+func (g starredGitHub) GetCodeOwners(owner, name string) (string, error) {
+	return "", nil
+}
+
+func (g starredGitHub) GetPullRequestDiff(owner, name string, number int, format string) (string, error) {
+	return "", nil
+}
+
+func (g starredGitHub) CreatePullRequest(owner, name string, create *github.PullRequestCreate) (*github.PullRequest, error) {
+	return nil, nil
+}
+
+func (g starredGitHub) UpdateIssue(owner, name string, number int, update *github.IssueUpdate) (*github.Issue, error) {
+	return nil, nil
+}
+
+func (g starredGitHub) AddLabels(owner, name string, number int, labels []string) error {
+	return nil
+}
+
+func (g starredGitHub) CreateComment(owner, name string, number int, body string) error {
+	return nil
+}
+
+func (g starredGitHub) GetRepositorySettings(owner, name string) (*github.RepositorySettings, error) {
+	return &github.RepositorySettings{DefaultBranch: "main"}, nil
+}
+
+func (g starredGitHub) GetRateLimit() (*github.RateLimit, error) {
+	return &github.RateLimit{Limit: 5000, Remaining: 5000}, nil
+}
+
+func (g starredGitHub) PoolStats() github.PoolStats {
+	return github.PoolStats{}
+}
+
+func TestTrackStarred(t *testing.T) {
+	ctx := context.Background()
+	db, err := file.NewDB("")
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	if err := db.AddRepository(ctx, &models.Repository{Owner: "me", Name: "manual", FullName: "me/manual"}); err != nil {
+		t.Fatalf("AddRepository() error = %v", err)
+	}
+
+	starred := []*github.Repository{
+		{FullName: "me/manual"},
+		{FullName: "pingcap/tidb"},
+		{FullName: "tikv/tikv"},
+		{FullName: "old/archived", Archived: true},
+	}
+	cfg := &config.Config{GitHub: config.GitHubConfig{TrackStarred: true}}
+	s, err := NewServiceWithOptions(cfg, Options{DB: db, GitHubClient: starredGitHub{starred: &starred}, Logger: log.New(io.Discard, "", 0)})
+	if err != nil {
+		t.Fatalf("NewServiceWithOptions() error = %v", err)
+	}
+	defer s.Close()
+
+	trackedNames := func() string {
+		repos, err := db.ListAllRepositories(ctx)
+		if err != nil {
+			t.Fatalf("ListAllRepositories() error = %v", err)
+		}
+		names := make([]string, 0, len(repos))
+		for _, repo := range repos {
+			names = append(names, repo.FullName+":"+repo.TrackedBy)
+		}
+		return strings.Join(names, ",")
+	}
+
+	if _, err := s.RefreshDue(ctx); err != nil {
+		t.Fatalf("RefreshDue() error = %v", err)
+	}
+	if got, want := trackedNames(), "me/manual:,pingcap/tidb:starred,tikv/tikv:starred"; got != want {
+		t.Errorf("tracked after starring = %s, want %s", got, want)
+	}
+
+	// Adding a starred repository explicitly keeps it when unstarred
+	if _, err := s.AddRepository(ctx, "tikv/tikv"); err != nil {
+		t.Fatalf("AddRepository() error = %v", err)
+	}
+	starred = nil
+	if _, err := s.RefreshDue(ctx); err != nil {
+		t.Fatalf("RefreshDue() error = %v", err)
+	}
+	if got, want := trackedNames(), "me/manual:,tikv/tikv:"; got != want {
+		t.Errorf("tracked after unstarring = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+	"github.com/siddontang/github-repos-management/internal/notify"
+)
+
+// AddSubscription subscribes a channel to a label being added to pull requests and issues of a
+// tracked repository, or of every tracked repository when sub.Repository is empty
+func (s *Service) AddSubscription(ctx context.Context, sub *models.Subscription) (*models.Subscription, error) {
+	// Subscriptions receive the label events of every workspace
+	if workspaceToken(ctx) {
+		return nil, ErrSessionRequired
+	}
+	sub.Label = strings.TrimSpace(sub.Label)
+	if sub.Label == "" {
+		return nil, fmt.Errorf("%w: label is required", ErrInvalidSubscription)
+	}
+	if sub.Channel == "" {
+		sub.Channel = models.SubscriptionChannelWebhook
+	}
+	if sub.Channel != models.SubscriptionChannelSlack && sub.Channel != models.SubscriptionChannelWebhook {
+		return nil, fmt.Errorf("%w: channel must be slack or webhook", ErrInvalidSubscription)
+	}
+	u, err := url.Parse(sub.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("%w: invalid URL", ErrInvalidSubscription)
+	}
+	if sub.Repository != "" {
+		repos, err := s.selectRepositories(ctx, sub.Repository, "")
+		if err != nil {
+			return nil, err
+		}
+		sub.Repository = repos[0].FullName
+	}
+
+	sub.CreatedAt = time.Now()
+	if err := s.db.AddSubscription(ctx, sub); err != nil {
+		return nil, fmt.Errorf("failed to add subscription: %w", err)
+	}
+
+	s.audit(ctx, models.AuditSubscriptionAdd, fmt.Sprintf("subscription %d", sub.ID), describeSubscription(sub))
+	return sub, nil
+}
+
+// ListSubscriptions lists the label subscriptions
+func (s *Service) ListSubscriptions(ctx context.Context) ([]*models.Subscription, error) {
+	if workspaceToken(ctx) {
+		return nil, ErrSessionRequired
+	}
+	return s.db.ListSubscriptions(ctx)
+}
+
+// DeleteSubscription removes a label subscription
+func (s *Service) DeleteSubscription(ctx context.Context, id int64) error {
+	if workspaceToken(ctx) {
+		return ErrSessionRequired
+	}
+	if err := s.db.DeleteSubscription(ctx, id); err != nil {
+		return notFound(err, ErrSubscriptionNotFound)
+	}
+	s.audit(ctx, models.AuditSubscriptionDelete, fmt.Sprintf("subscription %d", id), "")
+	return nil
+}
+
+// describeSubscription describes what a subscription matches and where it delivers, such as
+// "security in any repository to slack (hooks.slack.com)"
+func describeSubscription(sub *models.Subscription) string {
+	repo := sub.Repository
+	if repo == "" {
+		repo = "any repository"
+	}
+	host := sub.URL
+	if u, err := url.Parse(sub.URL); err == nil {
+		host = u.Host
+	}
+	return fmt.Sprintf("%s in %s to %s (%s)", sub.Label, repo, sub.Channel, host)
+}
+
+// subscriptionNotifier delivers labeled events to the channels of the matching label subscriptions
+type subscriptionNotifier struct {
+	service    *Service
+	httpClient *http.Client
+}
+
+// Ensure subscriptionNotifier implements notify.Notifier
+var _ notify.Notifier = (*subscriptionNotifier)(nil)
+
+// Notify queues the delivery of a labeled event to every subscription matching its label and repository
+func (n *subscriptionNotifier) Notify(ctx context.Context, event *notify.Event) error {
+	subs, err := n.service.db.ListSubscriptions(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list subscriptions: %w", err)
+	}
+
+	for _, sub := range subs {
+		if !subscriptionMatches(sub, event) {
+			continue
+		}
+
+		queued := n.service.deliveries.enqueue(func(ctx context.Context) {
+			var err error
+			switch sub.Channel {
+			case models.SubscriptionChannelSlack:
+				err = notify.NewSlackNotifier(sub.URL, "").Notify(ctx, event)
+			default:
+				err = notify.DeliverWebhook(ctx, n.httpClient, sub.URL, sub.Secret, event, webhookMaxAttempts).Err
+			}
+			if err != nil {
+				n.service.logger.Printf("Error delivering %s to subscription %d: %v", event.Type, sub.ID, err)
+			}
+		})
+		if !queued {
+			n.service.logger.Printf("Error delivering %s to subscription %d: delivery queue full", event.Type, sub.ID)
+		}
+	}
+
+	return nil
+}
+
+// subscriptionMatches reports whether a subscription wants a labeled event
+func subscriptionMatches(sub *models.Subscription, event *notify.Event) bool {
+	if !strings.EqualFold(sub.Label, event.Label) {
+		return false
+	}
+	return sub.Repository == "" || strings.EqualFold(sub.Repository, event.Repository)
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/db/file"
+	"github.com/siddontang/github-repos-management/internal/models"
+	"github.com/siddontang/github-repos-management/internal/notify"
+)
+
+func TestSubscriptions(t *testing.T) {
+	ctx := context.Background()
+	db, err := file.NewDB("")
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	if err := db.AddRepository(ctx, &models.Repository{Owner: "org", Name: "api", FullName: "org/api"}); err != nil {
+		t.Fatalf("AddRepository() error = %v", err)
+	}
+	logger := log.New(io.Discard, "", 0)
+	s := &Service{db: db, config: &config.Config{}, logger: logger, deliveries: newDeliveryQueue(db, logger)}
+
+	var mu sync.Mutex
+	received := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		received[r.URL.Path]++
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	if _, err := s.AddSubscription(ctx, &models.Subscription{Label: "security", URL: server.URL + "/any"}); err != nil {
+		t.Fatalf("AddSubscription(any) error = %v", err)
+	}
+	sub, err := s.AddSubscription(ctx, &models.Subscription{Label: "bug", Repository: "org/api", Channel: models.SubscriptionChannelSlack, URL: server.URL + "/api"})
+	if err != nil {
+		t.Fatalf("AddSubscription(org/api) error = %v", err)
+	}
+	if _, err := s.AddSubscription(ctx, &models.Subscription{Label: "bug", URL: "ftp://example.com"}); !errors.Is(err, ErrInvalidSubscription) {
+		t.Errorf("AddSubscription(ftp) error = %v, want ErrInvalidSubscription", err)
+	}
+	if _, err := s.AddSubscription(ctx, &models.Subscription{Label: "bug", Repository: "org/web", URL: server.URL}); !errors.Is(err, ErrRepositoryNotFound) {
+		t.Errorf("AddSubscription(untracked) error = %v, want ErrRepositoryNotFound", err)
+	}
+
+	n := &subscriptionNotifier{service: s, httpClient: server.Client()}
+	events := []*notify.Event{
+		{Type: notify.EventIssueLabeled, Repository: "org/web", Number: 1, Label: "Security"},
+		{Type: notify.EventPullRequestLabeled, Repository: "org/api", Number: 2, Label: "bug"},
+		{Type: notify.EventIssueLabeled, Repository: "org/web", Number: 3, Label: "bug"},
+	}
+	for _, event := range events {
+		if err := n.Notify(ctx, event); err != nil {
+			t.Fatalf("Notify() error = %v", err)
+		}
+	}
+	// Deliveries run in the background until the queue is closed
+	s.deliveries.Close(ctx)
+	if want := map[string]int{"/any": 1, "/api": 1}; len(received) != len(want) || received["/any"] != 1 || received["/api"] != 1 {
+		t.Errorf("deliveries = %v, want %v", received, want)
+	}
+
+	if err := s.DeleteSubscription(ctx, sub.ID); err != nil {
+		t.Fatalf("DeleteSubscription() error = %v", err)
+	}
+	if err := s.DeleteSubscription(ctx, sub.ID); !errors.Is(err, ErrSubscriptionNotFound) {
+		t.Errorf("DeleteSubscription(deleted) error = %v, want ErrSubscriptionNotFound", err)
+	}
+	subs, err := s.ListSubscriptions(ctx)
+	if err != nil || len(subs) != 1 {
+		t.Errorf("ListSubscriptions() = %d subscriptions, %v, want 1", len(subs), err)
+	}
+}
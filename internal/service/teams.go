@@ -0,0 +1,135 @@
+package service
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/siddontang/github-repos-management/internal/github"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// mentionPattern matches @user and @org/team mentions that are not part of an email address
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@/.])@([A-Za-z0-9](?:[A-Za-z0-9-]*[A-Za-z0-9])?(?:/[A-Za-z0-9_.-]+)?)`)
+
+// parseMentions returns the users and teams mentioned in a body, in order of first mention
+func parseMentions(body string) []string {
+	var mentions []string
+	seen := make(map[string]bool)
+	for _, match := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		key := strings.ToLower(match[1])
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		mentions = append(mentions, match[1])
+	}
+	return mentions
+}
+
+// userLogins returns the logins of GitHub users
+func userLogins(users []github.User) []string {
+	var logins []string
+	for _, user := range users {
+		logins = append(logins, user.Login)
+	}
+	return logins
+}
+
+// teamSlugs returns the slugs of GitHub teams
+func teamSlugs(teams []github.Team) []string {
+	var slugs []string
+	for _, team := range teams {
+		slugs = append(slugs, team.Slug)
+	}
+	return slugs
+}
+
+// teamMatcher reports whether an item involves a team: the team itself is requested for review
+// or mentioned as @org/team, or one of its configured members is involved by login.
+func (s *Service) teamMatcher(team string) func(teams, logins []string) bool {
+	team = strings.ToLower(team)
+	members := make(map[string]bool)
+	for name, logins := range s.config.Teams {
+		if strings.ToLower(name) != team {
+			continue
+		}
+		for _, login := range logins {
+			members[strings.ToLower(login)] = true
+		}
+	}
+
+	return func(teams, logins []string) bool {
+		for _, t := range teams {
+			if i := strings.LastIndex(t, "/"); i >= 0 {
+				t = t[i+1:]
+			}
+			if strings.ToLower(t) == team {
+				return true
+			}
+		}
+		for _, login := range logins {
+			if members[strings.ToLower(login)] {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// splitMentions separates team mentions (org/team) from user mentions
+func splitMentions(mentions []string) (teams, users []string) {
+	for _, mention := range mentions {
+		if strings.Contains(mention, "/") {
+			teams = append(teams, mention)
+		} else {
+			users = append(users, mention)
+		}
+	}
+	return teams, users
+}
+
+// pullRequestTeams returns the teams and user logins a pull request is assigned to, requests review from or mentions
+func pullRequestTeams(pr *models.PullRequest) (teams, logins []string) {
+	teams, logins = splitMentions(pr.Mentions)
+	teams = append(teams, pr.RequestedTeams...)
+	logins = append(logins, pr.RequestedReviewers...)
+	logins = append(logins, pr.Assignees...)
+	return teams, logins
+}
+
+// issueTeams returns the teams and user logins an issue is assigned to or mentions
+func issueTeams(issue *models.Issue) (teams, logins []string) {
+	teams, logins = splitMentions(issue.Mentions)
+	logins = append(logins, issue.Assignees...)
+	return teams, logins
+}
+
+// filterPullRequestTeam keeps the pull requests involving a team; an empty team keeps all
+func (s *Service) filterPullRequestTeam(prs []*models.PullRequest, team string) []*models.PullRequest {
+	if team == "" {
+		return prs
+	}
+	match := s.teamMatcher(team)
+	filtered := make([]*models.PullRequest, 0, len(prs))
+	for _, pr := range prs {
+		if match(pullRequestTeams(pr)) {
+			filtered = append(filtered, pr)
+		}
+	}
+	return filtered
+}
+
+// filterIssueTeam keeps the issues involving a team; an empty team keeps all
+func (s *Service) filterIssueTeam(issues []*models.Issue, team string) []*models.Issue {
+	if team == "" {
+		return issues
+	}
+	match := s.teamMatcher(team)
+	filtered := make([]*models.Issue, 0, len(issues))
+	for _, issue := range issues {
+		if match(issueTeams(issue)) {
+			filtered = append(filtered, issue)
+		}
+	}
+	return filtered
+}
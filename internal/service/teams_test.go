@@ -0,0 +1,55 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+func TestParseMentions(t *testing.T) {
+	tests := []struct {
+		body string
+		want []string
+	}{
+		{"", nil},
+		{"cc @alice and @bob", []string{"alice", "bob"}},
+		{"@alice first, then @Alice again", []string{"alice"}},
+		{"ping @pingcap/database please", []string{"pingcap/database"}},
+		{"mail me at bob@example.com", nil},
+		{"(@carol-d) see above", []string{"carol-d"}},
+	}
+
+	for _, tt := range tests {
+		if got := parseMentions(tt.body); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseMentions(%q) = %v, want %v", tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestFilterPullRequestTeam(t *testing.T) {
+	s := &Service{config: &config.Config{
+		Teams: map[string][]string{"database": {"alice", "Bob"}},
+	}}
+	prs := []*models.PullRequest{
+		{Number: 1, RequestedTeams: []string{"database"}},
+		{Number: 2, RequestedReviewers: []string{"bob"}},
+		{Number: 3, Assignees: []string{"alice"}},
+		{Number: 4, Mentions: []string{"pingcap/database"}},
+		{Number: 5, Mentions: []string{"carol"}, UserLogin: "alice"},
+		{Number: 6},
+	}
+
+	var got []int
+	for _, pr := range s.filterPullRequestTeam(prs, "Database") {
+		got = append(got, pr.Number)
+	}
+	if want := []int{1, 2, 3, 4}; !reflect.DeepEqual(got, want) {
+		t.Errorf("filterPullRequestTeam() = %v, want %v", got, want)
+	}
+
+	if all := s.filterPullRequestTeam(prs, ""); len(all) != len(prs) {
+		t.Errorf("filterPullRequestTeam() with no team kept %d of %d", len(all), len(prs))
+	}
+}
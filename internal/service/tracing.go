@@ -0,0 +1,263 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/github"
+	"github.com/siddontang/github-repos-management/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracedClient records a span named github.<Call> for each gh call made within the trace of ctx
+type tracedClient struct {
+	github.ClientInterface
+	ctx context.Context
+}
+
+// gh returns the GitHub client, tracing its calls as children of the current span of ctx
+func (s *Service) gh(ctx context.Context) github.ClientInterface {
+	if !tracing.Recording(ctx) {
+		return s.ghClient
+	}
+	return &tracedClient{ClientInterface: s.ghClient, ctx: ctx}
+}
+
+// start starts the span of a call, about the repository owner/name when owner is set
+func (c *tracedClient) start(call, owner, name string) trace.Span {
+	opts := []trace.SpanStartOption{trace.WithSpanKind(trace.SpanKindClient)}
+	if owner != "" {
+		opts = append(opts, trace.WithAttributes(attribute.String("github.repository", owner+"/"+name)))
+	}
+	_, span := tracing.Start(c.ctx, call, opts...)
+	return span
+}
+
+// GetRepository gets information about a repository
+func (c *tracedClient) GetRepository(owner, name string) (*github.Repository, error) {
+	span := c.start("github.GetRepository", owner, name)
+	defer span.End()
+	result, err := c.ClientInterface.GetRepository(owner, name)
+	tracing.RecordError(span, err)
+	return result, err
+}
+
+// ListOrganizationRepositories lists the full names of an organization's repositories
+func (c *tracedClient) ListOrganizationRepositories(org string, limit int) ([]string, error) {
+	span := c.start("github.ListOrganizationRepositories", "", "")
+	defer span.End()
+	result, err := c.ClientInterface.ListOrganizationRepositories(org, limit)
+	tracing.RecordError(span, err)
+	return result, err
+}
+
+// ListUserRepositories lists repositories the authenticated user is related to: those they own,
+// star or contribute to
+func (c *tracedClient) ListUserRepositories(relation string, limit int) ([]*github.Repository, error) {
+	span := c.start("github.ListUserRepositories", "", "")
+	defer span.End()
+	result, err := c.ClientInterface.ListUserRepositories(relation, limit)
+	tracing.RecordError(span, err)
+	return result, err
+}
+
+// ListPullRequests lists pull requests for a repository
+func (c *tracedClient) ListPullRequests(owner, name string, options *github.PullRequestOptions) ([]*github.PullRequest, error) {
+	span := c.start("github.ListPullRequests", owner, name)
+	defer span.End()
+	result, err := c.ClientInterface.ListPullRequests(owner, name, options)
+	tracing.RecordError(span, err)
+	return result, err
+}
+
+// ListIssues lists issues for a repository
+func (c *tracedClient) ListIssues(owner, name string, options *github.IssueOptions) ([]*github.Issue, error) {
+	span := c.start("github.ListIssues", owner, name)
+	defer span.End()
+	result, err := c.ClientInterface.ListIssues(owner, name, options)
+	tracing.RecordError(span, err)
+	return result, err
+}
+
+// ListAuthorAssociations maps recently updated issue and pull request numbers to their author associations
+func (c *tracedClient) ListAuthorAssociations(owner, name string, limit int) (map[int]string, error) {
+	span := c.start("github.ListAuthorAssociations", owner, name)
+	defer span.End()
+	result, err := c.ClientInterface.ListAuthorAssociations(owner, name, limit)
+	tracing.RecordError(span, err)
+	return result, err
+}
+
+// ListMilestones lists the open and closed milestones of a repository
+func (c *tracedClient) ListMilestones(owner, name string) ([]*github.Milestone, error) {
+	span := c.start("github.ListMilestones", owner, name)
+	defer span.End()
+	result, err := c.ClientInterface.ListMilestones(owner, name)
+	tracing.RecordError(span, err)
+	return result, err
+}
+
+// ListReleases lists the newest releases of a repository, drafts included
+func (c *tracedClient) ListReleases(owner, name string, limit int) ([]*github.Release, error) {
+	span := c.start("github.ListReleases", owner, name)
+	defer span.End()
+	result, err := c.ClientInterface.ListReleases(owner, name, limit)
+	tracing.RecordError(span, err)
+	return result, err
+}
+
+// ListDependabotAlerts lists the open Dependabot alerts of a repository
+func (c *tracedClient) ListDependabotAlerts(owner, name string) ([]*github.SecurityAlert, error) {
+	span := c.start("github.ListDependabotAlerts", owner, name)
+	defer span.End()
+	result, err := c.ClientInterface.ListDependabotAlerts(owner, name)
+	tracing.RecordError(span, err)
+	return result, err
+}
+
+// ListCodeScanningAlerts lists the open code scanning alerts of a repository
+func (c *tracedClient) ListCodeScanningAlerts(owner, name string) ([]*github.SecurityAlert, error) {
+	span := c.start("github.ListCodeScanningAlerts", owner, name)
+	defer span.End()
+	result, err := c.ClientInterface.ListCodeScanningAlerts(owner, name)
+	tracing.RecordError(span, err)
+	return result, err
+}
+
+// ListCommits lists the commits of the default branch made since a time, newest first, at most limit
+func (c *tracedClient) ListCommits(owner, name string, since time.Time, limit int) ([]*github.Commit, error) {
+	span := c.start("github.ListCommits", owner, name)
+	defer span.End()
+	result, err := c.ClientInterface.ListCommits(owner, name, since, limit)
+	tracing.RecordError(span, err)
+	return result, err
+}
+
+// ListLabels lists the labels of a repository
+func (c *tracedClient) ListLabels(owner, name string) ([]*github.Label, error) {
+	span := c.start("github.ListLabels", owner, name)
+	defer span.End()
+	result, err := c.ClientInterface.ListLabels(owner, name)
+	tracing.RecordError(span, err)
+	return result, err
+}
+
+// UpdateLabel renames or recolors a label of a repository
+func (c *tracedClient) UpdateLabel(owner, name, label string, update *github.LabelUpdate) (*github.Label, error) {
+	span := c.start("github.UpdateLabel", owner, name)
+	defer span.End()
+	result, err := c.ClientInterface.UpdateLabel(owner, name, label, update)
+	tracing.RecordError(span, err)
+	return result, err
+}
+
+// ListIssueTemplates lists the issue templates of a repository
+func (c *tracedClient) ListIssueTemplates(owner, name string) ([]*github.IssueTemplate, error) {
+	span := c.start("github.ListIssueTemplates", owner, name)
+	defer span.End()
+	result, err := c.ClientInterface.ListIssueTemplates(owner, name)
+	tracing.RecordError(span, err)
+	return result, err
+}
+
+// ListDiscussions lists the most recently updated discussions of a repository, at most limit
+func (c *tracedClient) ListDiscussions(owner, name string, limit int) ([]*github.Discussion, error) {
+	span := c.start("github.ListDiscussions", owner, name)
+	defer span.End()
+	result, err := c.ClientInterface.ListDiscussions(owner, name, limit)
+	tracing.RecordError(span, err)
+	return result, err
+}
+
+// ListProjects lists the projects linked to a repository
+func (c *tracedClient) ListProjects(owner, name string) ([]*github.Project, error) {
+	span := c.start("github.ListProjects", owner, name)
+	defer span.End()
+	result, err := c.ClientInterface.ListProjects(owner, name)
+	tracing.RecordError(span, err)
+	return result, err
+}
+
+// ListProjectItems lists the project items of the most recently updated issues and pull requests
+// of a repository, at most limit of each
+func (c *tracedClient) ListProjectItems(owner, name string, limit int) ([]*github.ProjectItem, error) {
+	span := c.start("github.ListProjectItems", owner, name)
+	defer span.End()
+	result, err := c.ClientInterface.ListProjectItems(owner, name, limit)
+	tracing.RecordError(span, err)
+	return result, err
+}
+
+// GetCodeOwners gets the CODEOWNERS file of a repository, or "" when it has none
+func (c *tracedClient) GetCodeOwners(owner, name string) (string, error) {
+	span := c.start("github.GetCodeOwners", owner, name)
+	defer span.End()
+	result, err := c.ClientInterface.GetCodeOwners(owner, name)
+	tracing.RecordError(span, err)
+	return result, err
+}
+
+// GetPullRequestDiff gets the changes of a pull request as a unified diff ("diff") or as a
+// series of patches in git format-patch form ("patch")
+func (c *tracedClient) GetPullRequestDiff(owner, name string, number int, format string) (string, error) {
+	span := c.start("github.GetPullRequestDiff", owner, name)
+	defer span.End()
+	result, err := c.ClientInterface.GetPullRequestDiff(owner, name, number, format)
+	tracing.RecordError(span, err)
+	return result, err
+}
+
+// CreatePullRequest opens a pull request in a repository
+func (c *tracedClient) CreatePullRequest(owner, name string, create *github.PullRequestCreate) (*github.PullRequest, error) {
+	span := c.start("github.CreatePullRequest", owner, name)
+	defer span.End()
+	result, err := c.ClientInterface.CreatePullRequest(owner, name, create)
+	tracing.RecordError(span, err)
+	return result, err
+}
+
+// UpdateIssue sets the state, assignees or milestone of an issue or pull request
+func (c *tracedClient) UpdateIssue(owner, name string, number int, update *github.IssueUpdate) (*github.Issue, error) {
+	span := c.start("github.UpdateIssue", owner, name)
+	defer span.End()
+	result, err := c.ClientInterface.UpdateIssue(owner, name, number, update)
+	tracing.RecordError(span, err)
+	return result, err
+}
+
+// AddLabels adds labels to an issue or pull request
+func (c *tracedClient) AddLabels(owner, name string, number int, labels []string) error {
+	span := c.start("github.AddLabels", owner, name)
+	defer span.End()
+	err := c.ClientInterface.AddLabels(owner, name, number, labels)
+	tracing.RecordError(span, err)
+	return err
+}
+
+// CreateComment comments on an issue or pull request
+func (c *tracedClient) CreateComment(owner, name string, number int, body string) error {
+	span := c.start("github.CreateComment", owner, name)
+	defer span.End()
+	err := c.ClientInterface.CreateComment(owner, name, number, body)
+	tracing.RecordError(span, err)
+	return err
+}
+
+// GetRepositorySettings gets the merge settings of a repository and the protection of its default branch
+func (c *tracedClient) GetRepositorySettings(owner, name string) (*github.RepositorySettings, error) {
+	span := c.start("github.GetRepositorySettings", owner, name)
+	defer span.End()
+	result, err := c.ClientInterface.GetRepositorySettings(owner, name)
+	tracing.RecordError(span, err)
+	return result, err
+}
+
+// GetRateLimit gets the current GitHub API rate limit
+func (c *tracedClient) GetRateLimit() (*github.RateLimit, error) {
+	span := c.start("github.GetRateLimit", "", "")
+	defer span.End()
+	result, err := c.ClientInterface.GetRateLimit()
+	tracing.RecordError(span, err)
+	return result, err
+}
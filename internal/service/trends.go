@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// staleAfter is how long an open item can go without updates before it counts as stale
+const staleAfter = 30 * 24 * time.Hour
+
+// snapshotRepository records the current item counts of a repository
+func (s *Service) snapshotRepository(ctx context.Context, repo *models.Repository) error {
+	snapshot, err := s.countItems(ctx, repo)
+	if err != nil {
+		return err
+	}
+	return s.db.AddRepositorySnapshot(ctx, snapshot)
+}
+
+// GetRepositoryCounts returns the open and stale item counts of a repository from the
+// stored data, without fetching from GitHub
+func (s *Service) GetRepositoryCounts(ctx context.Context, owner, name string) (*models.RepositorySnapshot, error) {
+	repo, err := s.GetStoredRepository(ctx, owner, name)
+	if err != nil {
+		return nil, err
+	}
+	return s.countItems(ctx, repo)
+}
+
+// countItems counts the open and stale items of a repository
+func (s *Service) countItems(ctx context.Context, repo *models.Repository) (*models.RepositorySnapshot, error) {
+	now := time.Now()
+	snapshot := &models.RepositorySnapshot{
+		RepositoryFullName: repo.FullName,
+		CreatedAt:          now,
+	}
+
+	prs, err := s.db.ListAllPullRequests(ctx, repo.FullName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pull requests: %w", err)
+	}
+	for _, pr := range prs {
+		if pr.Tombstoned || !strings.EqualFold(pr.State, "open") {
+			continue
+		}
+		snapshot.OpenPullRequests++
+		if now.Sub(pr.UpdatedAt) > staleAfter {
+			snapshot.StalePullRequests++
+		}
+	}
+
+	issues, err := s.db.ListAllIssues(ctx, repo.FullName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list issues: %w", err)
+	}
+	for _, issue := range issues {
+		if issue.Tombstoned || !strings.EqualFold(issue.State, "open") {
+			continue
+		}
+		snapshot.OpenIssues++
+		if now.Sub(issue.UpdatedAt) > staleAfter {
+			snapshot.StaleIssues++
+		}
+	}
+
+	return snapshot, nil
+}
+
+// GetRepositoryTrends returns the snapshots of a repository taken within the window, oldest first
+func (s *Service) GetRepositoryTrends(ctx context.Context, owner, name string, window time.Duration) ([]*models.RepositorySnapshot, error) {
+	if window <= 0 {
+		return nil, ErrInvalidWindow
+	}
+
+	repo, err := s.db.GetRepository(ctx, owner, name)
+	if err != nil {
+		return nil, notFound(err, ErrRepositoryNotFound)
+	}
+	if err := s.checkWorkspace(ctx, repo.FullName); err != nil {
+		return nil, err
+	}
+
+	return s.db.ListRepositorySnapshots(ctx, repo.FullName, time.Now().Add(-window))
+}
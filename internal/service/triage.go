@@ -0,0 +1,287 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+	"github.com/siddontang/github-repos-management/internal/notify"
+	"gopkg.in/yaml.v3"
+)
+
+// triageRuleFile is the layout of a YAML file of triage rules
+type triageRuleFile struct {
+	Rules []*models.TriageRule `yaml:"rules"`
+}
+
+// triageItem is an open pull request or issue triage rules are evaluated against. Its exported
+// fields are what comment templates can refer to, such as {{.Author}}.
+type triageItem struct {
+	Type       string
+	Repository string
+	Number     int
+	Title      string
+	Author     string
+	URL        string
+	Labels     []string
+	CreatedAt  time.Time
+}
+
+// ParseTriageRules parses a YAML list of triage rules under a top-level rules key, rejecting
+// unknown fields so that misspelled conditions don't silently match everything
+func ParseTriageRules(data []byte) ([]*models.TriageRule, error) {
+	var file triageRuleFile
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTriageRule, err)
+	}
+	if len(file.Rules) == 0 {
+		return nil, fmt.Errorf("%w: no rules found", ErrInvalidTriageRule)
+	}
+	return file.Rules, nil
+}
+
+// AddTriageRule adds a triage rule, applied to the repositories it covers from their next sync
+func (s *Service) AddTriageRule(ctx context.Context, rule *models.TriageRule) (*models.TriageRule, error) {
+	// Rules act on GitHub with the server's credentials, across every workspace
+	if workspaceToken(ctx) {
+		return nil, ErrSessionRequired
+	}
+	rule.Name = strings.TrimSpace(rule.Name)
+	if rule.Name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidTriageRule)
+	}
+	if rule.Type != "" && rule.Type != models.ItemTypePullRequest && rule.Type != models.ItemTypeIssue {
+		return nil, fmt.Errorf("%w: type must be pull_request or issue", ErrInvalidTriageRule)
+	}
+	cond := &rule.If
+	if cond.TitleMatches == "" && cond.Author == "" && cond.NoLabelDays == 0 {
+		return nil, fmt.Errorf("%w: at least one condition is required", ErrInvalidTriageRule)
+	}
+	if _, err := regexp.Compile(cond.TitleMatches); err != nil {
+		return nil, fmt.Errorf("%w: title_matches: %v", ErrInvalidTriageRule, err)
+	}
+	if cond.NoLabelDays < 0 {
+		return nil, fmt.Errorf("%w: no_label_days must be positive", ErrInvalidTriageRule)
+	}
+	cond.Author = strings.TrimPrefix(strings.TrimSpace(cond.Author), "@")
+	actions := &rule.Then
+	actions.AddLabel = strings.TrimSpace(actions.AddLabel)
+	if actions.AddLabel == "" && strings.TrimSpace(actions.Comment) == "" && !actions.Notify {
+		return nil, fmt.Errorf("%w: at least one action is required", ErrInvalidTriageRule)
+	}
+	if _, err := commentTemplate(actions.Comment); err != nil {
+		return nil, fmt.Errorf("%w: comment: %v", ErrInvalidTriageRule, err)
+	}
+	if rule.Repository != "" {
+		repos, err := s.selectRepositories(ctx, rule.Repository, "")
+		if err != nil {
+			return nil, err
+		}
+		rule.Repository = repos[0].FullName
+	}
+
+	rule.CreatedAt = time.Now()
+	if err := s.db.AddTriageRule(ctx, rule); err != nil {
+		return nil, fmt.Errorf("failed to add triage rule: %w", err)
+	}
+
+	s.audit(ctx, models.AuditTriageRuleAdd, fmt.Sprintf("triage rule %d", rule.ID), rule.Name)
+	return rule, nil
+}
+
+// ListTriageRules lists the triage rules
+func (s *Service) ListTriageRules(ctx context.Context) ([]*models.TriageRule, error) {
+	if workspaceToken(ctx) {
+		return nil, ErrSessionRequired
+	}
+	return s.db.ListTriageRules(ctx)
+}
+
+// DeleteTriageRule removes a triage rule and its execution log
+func (s *Service) DeleteTriageRule(ctx context.Context, id int64) error {
+	if workspaceToken(ctx) {
+		return ErrSessionRequired
+	}
+	if err := s.db.DeleteTriageRule(ctx, id); err != nil {
+		return notFound(err, ErrTriageRuleNotFound)
+	}
+	s.audit(ctx, models.AuditTriageRuleDelete, fmt.Sprintf("triage rule %d", id), "")
+	return nil
+}
+
+// ListTriageExecutions lists the items a triage rule acted on, newest first
+func (s *Service) ListTriageExecutions(ctx context.Context, id int64) ([]*models.TriageExecution, error) {
+	if workspaceToken(ctx) {
+		return nil, ErrSessionRequired
+	}
+	executions, err := s.db.ListTriageExecutions(ctx, id)
+	if err != nil {
+		return nil, notFound(err, ErrTriageRuleNotFound)
+	}
+	return executions, nil
+}
+
+// applyTriageRules runs the triage rules covering a repository against its open pull requests and
+// issues, once per item and rule. Failures are recorded in the execution log rather than retried,
+// so a half-applied rule never comments twice.
+func (s *Service) applyTriageRules(ctx context.Context, repo *models.Repository) {
+	rules, err := s.db.ListTriageRules(ctx)
+	if err != nil {
+		s.logger.Printf("Error listing triage rules: %v", err)
+		return
+	}
+	var items []*triageItem
+	for _, rule := range rules {
+		if rule.Repository != "" && !strings.EqualFold(rule.Repository, repo.FullName) {
+			continue
+		}
+		if items == nil {
+			if items, err = s.triageItems(ctx, repo.FullName); err != nil {
+				s.logger.Printf("Error listing items of %s to triage: %v", repo.FullName, err)
+				return
+			}
+		}
+		executions, err := s.db.ListTriageExecutions(ctx, rule.ID)
+		if err != nil {
+			continue
+		}
+		done := make(map[string]bool, len(executions))
+		for _, execution := range executions {
+			done[fmt.Sprintf("%s %s#%d", execution.Type, execution.RepositoryFullName, execution.Number)] = true
+		}
+
+		titlePattern, _ := regexp.Compile(rule.If.TitleMatches)
+		for _, item := range items {
+			if done[fmt.Sprintf("%s %s#%d", item.Type, item.Repository, item.Number)] || !triageMatches(rule, titlePattern, item) {
+				continue
+			}
+			execution := s.runTriageRule(ctx, repo, rule, item)
+			if err := s.db.AddTriageExecution(ctx, execution); err != nil {
+				s.logger.Printf("Error recording triage rule %d on %s#%d: %v", rule.ID, item.Repository, item.Number, err)
+			}
+		}
+	}
+}
+
+// triageItems lists the live open pull requests and issues of a repository, ordered by number
+func (s *Service) triageItems(ctx context.Context, fullName string) ([]*triageItem, error) {
+	query := &models.ItemQuery{Repositories: []string{fullName}, State: "open"}
+	prs, err := s.db.FindPullRequests(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find pull requests: %w", err)
+	}
+	issues, err := s.db.FindIssues(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find issues: %w", err)
+	}
+
+	items := make([]*triageItem, 0, len(prs)+len(issues))
+	labelNames := func(labels []*models.Label) []string {
+		names := make([]string, 0, len(labels))
+		for _, label := range labels {
+			names = append(names, label.Name)
+		}
+		return names
+	}
+	for _, pr := range livePullRequests(prs) {
+		labels, _ := s.db.ListPullRequestLabels(ctx, pr.RepositoryFullName, pr.Number)
+		items = append(items, &triageItem{Type: models.ItemTypePullRequest, Repository: pr.RepositoryFullName, Number: pr.Number,
+			Title: pr.Title, Author: pr.UserLogin, URL: pr.HTMLURL, Labels: labelNames(labels), CreatedAt: pr.CreatedAt})
+	}
+	for _, issue := range liveIssues(issues) {
+		labels, _ := s.db.ListIssueLabels(ctx, issue.RepositoryFullName, issue.Number)
+		items = append(items, &triageItem{Type: models.ItemTypeIssue, Repository: issue.RepositoryFullName, Number: issue.Number,
+			Title: issue.Title, Author: issue.UserLogin, URL: issue.HTMLURL, Labels: labelNames(labels), CreatedAt: issue.CreatedAt})
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Number < items[j].Number
+	})
+	return items, nil
+}
+
+// triageMatches reports whether an item meets every condition of a triage rule
+func triageMatches(rule *models.TriageRule, titlePattern *regexp.Regexp, item *triageItem) bool {
+	if rule.Type != "" && rule.Type != item.Type {
+		return false
+	}
+	if rule.If.TitleMatches != "" && (titlePattern == nil || !titlePattern.MatchString(item.Title)) {
+		return false
+	}
+	if rule.If.Author != "" && !strings.EqualFold(rule.If.Author, item.Author) {
+		return false
+	}
+	if days := rule.If.NoLabelDays; days > 0 {
+		if len(item.Labels) > 0 || time.Since(item.CreatedAt) < time.Duration(days)*24*time.Hour {
+			return false
+		}
+	}
+	return true
+}
+
+// runTriageRule applies the actions of a triage rule to an item, stopping at the first failure,
+// and returns the execution to record
+func (s *Service) runTriageRule(ctx context.Context, repo *models.Repository, rule *models.TriageRule, item *triageItem) *models.TriageExecution {
+	execution := &models.TriageExecution{RuleID: rule.ID, Type: item.Type, RepositoryFullName: item.Repository, Number: item.Number,
+		Title: item.Title, Actions: []string{}, ExecutedAt: time.Now()}
+	fail := func(err error) *models.TriageExecution {
+		execution.Error = err.Error()
+		s.logger.Printf("Error applying triage rule %q to %s#%d: %v", rule.Name, item.Repository, item.Number, err)
+		return execution
+	}
+
+	if label := rule.Then.AddLabel; label != "" && !containsLabel(item.Labels, label) {
+		if err := s.gh(ctx).AddLabels(repo.Owner, repo.Name, item.Number, []string{label}); err != nil {
+			return fail(err)
+		}
+		s.storeItemLabel(ctx, item.Type, item.Repository, item.Number, label)
+		execution.Actions = append(execution.Actions, "label "+label)
+	}
+	if strings.TrimSpace(rule.Then.Comment) != "" {
+		tmpl, err := commentTemplate(rule.Then.Comment)
+		if err != nil {
+			return fail(err)
+		}
+		var body strings.Builder
+		if err := tmpl.Execute(&body, item); err != nil {
+			return fail(err)
+		}
+		if err := s.gh(ctx).CreateComment(repo.Owner, repo.Name, item.Number, body.String()); err != nil {
+			return fail(err)
+		}
+		execution.Actions = append(execution.Actions, "comment")
+	}
+	if rule.Then.Notify {
+		s.notifier.Dispatch(ctx, &notify.Event{
+			Type:       notify.EventTriageMatched,
+			Repository: item.Repository,
+			Number:     item.Number,
+			Title:      item.Title,
+			URL:        item.URL,
+			Author:     item.Author,
+			Rule:       rule.Name,
+		})
+		execution.Actions = append(execution.Actions, "notify")
+	}
+	return execution
+}
+
+// commentTemplate parses the comment of a triage rule, checking it only refers to fields of items
+func commentTemplate(comment string) (*template.Template, error) {
+	tmpl, err := template.New("comment").Parse(comment)
+	if err != nil {
+		return nil, err
+	}
+	if err := tmpl.Execute(io.Discard, &triageItem{}); err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/db/file"
+	"github.com/siddontang/github-repos-management/internal/github"
+	"github.com/siddontang/github-repos-management/internal/models"
+	"github.com/siddontang/github-repos-management/internal/notify"
+)
+
+// triageGitHub records the labels and comments it is asked to add
+type triageGitHub struct {
+	github.ClientInterface
+	labeled  []int
+	comments []string
+}
+
+func (g *triageGitHub) AddLabels(owner, name string, number int, labels []string) error {
+	g.labeled = append(g.labeled, number)
+	return nil
+}
+
+func (g *triageGitHub) CreateComment(owner, name string, number int, body string) error {
+	g.comments = append(g.comments, body)
+	return nil
+}
+
+// recordingNotifier records the events it is notified of
+type recordingNotifier struct {
+	mu     sync.Mutex
+	events []*notify.Event
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, event *notify.Event) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.events = append(n.events, event)
+	return nil
+}
+
+func TestParseTriageRules(t *testing.T) {
+	rules, err := ParseTriageRules([]byte(`
+rules:
+  - name: crashes
+    type: issue
+    if:
+      title_matches: "(?i)panic"
+    then:
+      add_label: crash
+      notify: true
+`))
+	if err != nil {
+		t.Fatalf("ParseTriageRules() error = %v", err)
+	}
+	if len(rules) != 1 || rules[0].If.TitleMatches != "(?i)panic" || rules[0].Then.AddLabel != "crash" || !rules[0].Then.Notify {
+		t.Errorf("ParseTriageRules() = %+v", rules[0])
+	}
+
+	if _, err := ParseTriageRules([]byte("rules:\n  - name: typo\n    if:\n      title_match: x\n")); !errors.Is(err, ErrInvalidTriageRule) {
+		t.Errorf("ParseTriageRules(unknown field) error = %v, want ErrInvalidTriageRule", err)
+	}
+}
+
+func TestApplyTriageRules(t *testing.T) {
+	ctx := context.Background()
+	db, err := file.NewDB("")
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	repo := &models.Repository{Owner: "org", Name: "api", FullName: "org/api"}
+	if err := db.AddRepository(ctx, repo); err != nil {
+		t.Fatalf("AddRepository() error = %v", err)
+	}
+	old := time.Now().AddDate(0, 0, -5)
+	for _, issue := range []*models.Issue{
+		{RepositoryFullName: "org/api", Number: 1, Title: "Panic on start", UserLogin: "alice", State: "OPEN", CreatedAt: time.Now()},
+		{RepositoryFullName: "org/api", Number: 2, Title: "Docs typo", UserLogin: "bob", State: "OPEN", CreatedAt: old},
+		{RepositoryFullName: "org/api", Number: 3, Title: "Old panic", UserLogin: "bob", State: "CLOSED", CreatedAt: old},
+		{RepositoryFullName: "org/api", Number: 4, Title: "Labeled", UserLogin: "bob", State: "OPEN", CreatedAt: old},
+	} {
+		if err := db.AddIssue(ctx, issue); err != nil {
+			t.Fatalf("AddIssue() error = %v", err)
+		}
+	}
+	db.AddLabel(ctx, &models.Label{Name: "docs"})
+	db.AddIssueLabel(ctx, "org/api", 4, "docs")
+
+	gh := &triageGitHub{}
+	notifier := &recordingNotifier{}
+	dispatcher := &notify.Dispatcher{}
+	dispatcher.Add(notify.Rule{}, notifier)
+	s := &Service{db: db, ghClient: gh, notifier: dispatcher, config: &config.Config{}, logger: log.New(io.Discard, "", 0)}
+
+	crashes, err := s.AddTriageRule(ctx, &models.TriageRule{Name: "crashes", Repository: "org/api",
+		If: models.TriageCondition{TitleMatches: "(?i)panic"}, Then: models.TriageActions{AddLabel: "crash", Notify: true}})
+	if err != nil {
+		t.Fatalf("AddTriageRule() error = %v", err)
+	}
+	stale, err := s.AddTriageRule(ctx, &models.TriageRule{Name: "untriaged", Type: models.ItemTypeIssue,
+		If: models.TriageCondition{NoLabelDays: 3}, Then: models.TriageActions{Comment: "@{{.Author}}, can you add details to #{{.Number}}?"}})
+	if err != nil {
+		t.Fatalf("AddTriageRule() error = %v", err)
+	}
+
+	s.applyTriageRules(ctx, repo)
+	s.applyTriageRules(ctx, repo)
+	if len(gh.labeled) != 1 || gh.labeled[0] != 1 {
+		t.Errorf("labeled = %v, want only #1, once", gh.labeled)
+	}
+	if len(gh.comments) != 1 || gh.comments[0] != "@bob, can you add details to #2?" {
+		t.Errorf("comments = %q, want one on #2", gh.comments)
+	}
+	if len(notifier.events) != 1 || notifier.events[0].Type != notify.EventTriageMatched || notifier.events[0].Rule != "crashes" {
+		t.Errorf("events = %+v, want one triage.matched for crashes", notifier.events)
+	}
+	if labels, _ := db.ListIssueLabels(ctx, "org/api", 1); len(labels) != 1 || labels[0].Name != "crash" {
+		t.Errorf("stored labels of #1 = %v, want crash", labels)
+	}
+
+	executions, err := s.ListTriageExecutions(ctx, crashes.ID)
+	if err != nil {
+		t.Fatalf("ListTriageExecutions() error = %v", err)
+	}
+	if len(executions) != 1 || executions[0].Number != 1 || len(executions[0].Actions) != 2 {
+		t.Errorf("executions = %+v, want #1 labeled and notified", executions)
+	}
+
+	if err := s.DeleteTriageRule(ctx, stale.ID); err != nil {
+		t.Fatalf("DeleteTriageRule() error = %v", err)
+	}
+	if _, err := s.ListTriageExecutions(ctx, stale.ID); !errors.Is(err, ErrTriageRuleNotFound) {
+		t.Errorf("ListTriageExecutions(deleted) error = %v, want ErrTriageRuleNotFound", err)
+	}
+	if _, err := s.AddTriageRule(ctx, &models.TriageRule{Name: "bad", If: models.TriageCondition{Author: "bob"},
+		Then: models.TriageActions{Comment: "{{.Reporter}}"}}); !errors.Is(err, ErrInvalidTriageRule) {
+		t.Errorf("AddTriageRule(unknown field) error = %v, want ErrInvalidTriageRule", err)
+	}
+}
@@ -0,0 +1,232 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/github"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// meteredClient counts the GitHub API requests made for each repository.
+// gh lists items in pages of githubPageSize, one request per page.
+type meteredClient struct {
+	github.ClientInterface
+
+	mu       sync.Mutex
+	requests map[string]int64 // repository full name -> requests
+}
+
+func newMeteredClient(client github.ClientInterface) *meteredClient {
+	return &meteredClient{ClientInterface: client, requests: make(map[string]int64)}
+}
+
+// add records requests made for a repository
+func (c *meteredClient) add(owner, name string, requests int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.requests[owner+"/"+name] += int64(requests)
+}
+
+// Requests returns the number of requests made for a repository so far
+func (c *meteredClient) Requests(owner, name string) int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.requests[owner+"/"+name]
+}
+
+// listRequests returns the requests gh made to list n items
+func listRequests(n int) int {
+	if n <= githubPageSize {
+		return 1
+	}
+	return (n + githubPageSize - 1) / githubPageSize
+}
+
+// GetRepository gets information about a repository
+func (c *meteredClient) GetRepository(owner, name string) (*github.Repository, error) {
+	c.add(owner, name, 1)
+	return c.ClientInterface.GetRepository(owner, name)
+}
+
+// ListPullRequests lists pull requests for a repository
+func (c *meteredClient) ListPullRequests(owner, name string, options *github.PullRequestOptions) ([]*github.PullRequest, error) {
+	prs, err := c.ClientInterface.ListPullRequests(owner, name, options)
+	c.add(owner, name, listRequests(len(prs)))
+	return prs, err
+}
+
+// ListIssues lists issues for a repository
+func (c *meteredClient) ListIssues(owner, name string, options *github.IssueOptions) ([]*github.Issue, error) {
+	issues, err := c.ClientInterface.ListIssues(owner, name, options)
+	c.add(owner, name, listRequests(len(issues)))
+	return issues, err
+}
+
+// ListAuthorAssociations maps recently updated issue and pull request numbers to their author associations
+func (c *meteredClient) ListAuthorAssociations(owner, name string, limit int) (map[int]string, error) {
+	c.add(owner, name, 1)
+	return c.ClientInterface.ListAuthorAssociations(owner, name, limit)
+}
+
+// ListMilestones lists the open and closed milestones of a repository
+func (c *meteredClient) ListMilestones(owner, name string) ([]*github.Milestone, error) {
+	c.add(owner, name, 1)
+	return c.ClientInterface.ListMilestones(owner, name)
+}
+
+// ListReleases lists the newest releases of a repository
+func (c *meteredClient) ListReleases(owner, name string, limit int) ([]*github.Release, error) {
+	c.add(owner, name, 1)
+	return c.ClientInterface.ListReleases(owner, name, limit)
+}
+
+// ListDependabotAlerts lists the open Dependabot alerts of a repository
+func (c *meteredClient) ListDependabotAlerts(owner, name string) ([]*github.SecurityAlert, error) {
+	c.add(owner, name, 1)
+	return c.ClientInterface.ListDependabotAlerts(owner, name)
+}
+
+// ListCodeScanningAlerts lists the open code scanning alerts of a repository
+func (c *meteredClient) ListCodeScanningAlerts(owner, name string) ([]*github.SecurityAlert, error) {
+	c.add(owner, name, 1)
+	return c.ClientInterface.ListCodeScanningAlerts(owner, name)
+}
+
+// ListCommits lists the commits of the default branch made since a time
+func (c *meteredClient) ListCommits(owner, name string, since time.Time, limit int) ([]*github.Commit, error) {
+	commits, err := c.ClientInterface.ListCommits(owner, name, since, limit)
+	c.add(owner, name, listRequests(len(commits)))
+	return commits, err
+}
+
+// ListLabels lists the labels of a repository
+func (c *meteredClient) ListLabels(owner, name string) ([]*github.Label, error) {
+	result, err := c.ClientInterface.ListLabels(owner, name)
+	c.add(owner, name, listRequests(len(result)))
+	return result, err
+}
+
+// UpdateLabel renames or recolors a label of a repository
+func (c *meteredClient) UpdateLabel(owner, name, label string, update *github.LabelUpdate) (*github.Label, error) {
+	c.add(owner, name, 1)
+	return c.ClientInterface.UpdateLabel(owner, name, label, update)
+}
+
+// ListIssueTemplates lists the issue templates of a repository
+func (c *meteredClient) ListIssueTemplates(owner, name string) ([]*github.IssueTemplate, error) {
+	c.add(owner, name, 1)
+	return c.ClientInterface.ListIssueTemplates(owner, name)
+}
+
+// ListDiscussions lists the most recently updated discussions of a repository
+func (c *meteredClient) ListDiscussions(owner, name string, limit int) ([]*github.Discussion, error) {
+	result, err := c.ClientInterface.ListDiscussions(owner, name, limit)
+	c.add(owner, name, listRequests(len(result)))
+	return result, err
+}
+
+// ListProjects lists the projects linked to a repository
+func (c *meteredClient) ListProjects(owner, name string) ([]*github.Project, error) {
+	c.add(owner, name, 1)
+	return c.ClientInterface.ListProjects(owner, name)
+}
+
+// ListProjectItems lists the project items of the recently updated issues and pull requests of a repository
+func (c *meteredClient) ListProjectItems(owner, name string, limit int) ([]*github.ProjectItem, error) {
+	c.add(owner, name, 1)
+	return c.ClientInterface.ListProjectItems(owner, name, limit)
+}
+
+// GetCodeOwners gets the CODEOWNERS file of a repository
+func (c *meteredClient) GetCodeOwners(owner, name string) (string, error) {
+	c.add(owner, name, 1)
+	return c.ClientInterface.GetCodeOwners(owner, name)
+}
+
+// GetPullRequestDiff gets the diff or patch of a pull request
+func (c *meteredClient) GetPullRequestDiff(owner, name string, number int, format string) (string, error) {
+	c.add(owner, name, 1)
+	return c.ClientInterface.GetPullRequestDiff(owner, name, number, format)
+}
+
+// CreatePullRequest opens a pull request in a repository
+func (c *meteredClient) CreatePullRequest(owner, name string, create *github.PullRequestCreate) (*github.PullRequest, error) {
+	c.add(owner, name, 1)
+	return c.ClientInterface.CreatePullRequest(owner, name, create)
+}
+
+// UpdateIssue sets the state, assignees or milestone of an issue or pull request
+func (c *meteredClient) UpdateIssue(owner, name string, number int, update *github.IssueUpdate) (*github.Issue, error) {
+	c.add(owner, name, 1)
+	return c.ClientInterface.UpdateIssue(owner, name, number, update)
+}
+
+// AddLabels adds labels to an issue or pull request
+func (c *meteredClient) AddLabels(owner, name string, number int, labels []string) error {
+	c.add(owner, name, 1)
+	return c.ClientInterface.AddLabels(owner, name, number, labels)
+}
+
+// CreateComment comments on an issue or pull request
+func (c *meteredClient) CreateComment(owner, name string, number int, body string) error {
+	c.add(owner, name, 1)
+	return c.ClientInterface.CreateComment(owner, name, number, body)
+}
+
+// GetRepositorySettings gets the merge settings and default branch protection of a repository
+func (c *meteredClient) GetRepositorySettings(owner, name string) (*github.RepositorySettings, error) {
+	c.add(owner, name, 2)
+	return c.ClientInterface.GetRepositorySettings(owner, name)
+}
+
+// TokenStats reports the rate limit of each token when the wrapped client rotates between tokens
+func (c *meteredClient) TokenStats() []github.TokenStatus {
+	if reporter, ok := c.ClientInterface.(github.TokenReporter); ok {
+		return reporter.TokenStats()
+	}
+	return nil
+}
+
+// recordAPIUsage adds requests spent outside a full sync, such as cache refreshes and failed syncs,
+// to the usage of a repository
+func (s *Service) recordAPIUsage(ctx context.Context, owner, name string, requests int64) {
+	if requests == 0 {
+		return
+	}
+	_, err := s.updateRepository(ctx, owner, name, func(repo *models.Repository) bool {
+		repo.APIUsage.TotalRequests += requests
+		return true
+	})
+	if err != nil {
+		s.logger.Printf("Error recording API usage of %s/%s: %v", owner, name, err)
+	}
+}
+
+// GetAPIUsage reports the GitHub API requests spent on each repository, most expensive first
+func (s *Service) GetAPIUsage(ctx context.Context) ([]*models.RepositoryAPIUsage, error) {
+	repos, err := s.db.ListAllRepositories(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var total int64
+	for _, repo := range repos {
+		total += repo.APIUsage.TotalRequests
+	}
+
+	usage := make([]*models.RepositoryAPIUsage, 0, len(repos))
+	for _, repo := range repos {
+		entry := &models.RepositoryAPIUsage{FullName: repo.FullName, APIUsage: repo.APIUsage}
+		if total > 0 {
+			entry.Share = float64(repo.APIUsage.TotalRequests) / float64(total)
+		}
+		usage = append(usage, entry)
+	}
+	sort.SliceStable(usage, func(i, j int) bool { return usage[i].TotalRequests > usage[j].TotalRequests })
+	return usage, nil
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/siddontang/github-repos-management/internal/db/file"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+func TestListRequests(t *testing.T) {
+	for _, tt := range []struct{ items, want int }{{0, 1}, {1, 1}, {100, 1}, {101, 2}, {250, 3}} {
+		if got := listRequests(tt.items); got != tt.want {
+			t.Errorf("listRequests(%d) = %d, want %d", tt.items, got, tt.want)
+		}
+	}
+}
+
+func TestGetAPIUsage(t *testing.T) {
+	ctx := context.Background()
+	db, err := file.NewDB("")
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	for _, repo := range []*models.Repository{
+		{Owner: "org", Name: "small", FullName: "org/small", APIUsage: models.APIUsage{TotalRequests: 10, Syncs: 5}},
+		{Owner: "org", Name: "large", FullName: "org/large", APIUsage: models.APIUsage{LastSyncRequests: 8, TotalRequests: 30, Syncs: 4}},
+	} {
+		if err := db.AddRepository(ctx, repo); err != nil {
+			t.Fatalf("AddRepository() error = %v", err)
+		}
+	}
+
+	s := &Service{db: db}
+	usage, err := s.GetAPIUsage(ctx)
+	if err != nil {
+		t.Fatalf("GetAPIUsage() error = %v", err)
+	}
+	if len(usage) != 2 || usage[0].FullName != "org/large" {
+		t.Fatalf("GetAPIUsage() = %+v, want org/large first", usage)
+	}
+	if usage[0].Share != 0.75 || usage[0].AverageSyncRequests() != 7.5 || usage[1].Share != 0.25 {
+		t.Errorf("org/large share = %v, average = %v; org/small share = %v, want 0.75, 7.5, 0.25",
+			usage[0].Share, usage[0].AverageSyncRequests(), usage[1].Share)
+	}
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+	"github.com/siddontang/github-repos-management/internal/warehouse"
+)
+
+// defaultWarehouseInterval is how often the warehouse is pushed to when no interval is configured
+const defaultWarehouseInterval = 6 * time.Hour
+
+// PushWarehouse replaces the repositories, pull requests and issues tables of the configured
+// warehouse with the stored data of every tracked repository
+func (s *Service) PushWarehouse(ctx context.Context, apiKey string) (*models.WarehousePush, error) {
+	if err := s.authorizeAdmin(ctx, apiKey); err != nil {
+		return nil, err
+	}
+
+	push, err := s.pushWarehouse(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.audit(withCredential(ctx, apiKey), models.AuditAdminWarehouse, "",
+		fmt.Sprintf("%d repositories, %d pull requests, %d issues", push.Repositories, push.PullRequests, push.Issues))
+	return push, nil
+}
+
+// pushWarehouse pushes the stored data to the warehouse. Tombstoned items are left out.
+func (s *Service) pushWarehouse(ctx context.Context) (*models.WarehousePush, error) {
+	if s.warehouse == nil {
+		return nil, ErrWarehouseNotConfigured
+	}
+
+	repos, err := s.db.ListAllRepositories(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list repositories: %w", err)
+	}
+	var prs []*models.PullRequest
+	var issues []*models.Issue
+	for _, repo := range repos {
+		repoPRs, err := s.db.ListAllPullRequests(ctx, repo.FullName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list pull requests of %s: %w", repo.FullName, err)
+		}
+		prs = append(prs, livePullRequests(repoPRs)...)
+		repoIssues, err := s.db.ListAllIssues(ctx, repo.FullName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list issues of %s: %w", repo.FullName, err)
+		}
+		issues = append(issues, liveIssues(repoIssues)...)
+	}
+
+	prefix := s.config.Warehouse.TablePrefix
+	tables := []*warehouse.Table{
+		warehouse.RepositoryTable(prefix+"repositories", repos),
+		warehouse.PullRequestTable(prefix+"pull_requests", prs, func(fullName string, number int) []string {
+			labels, _ := s.db.ListPullRequestLabels(ctx, fullName, number)
+			return labelNames(labels)
+		}),
+		warehouse.IssueTable(prefix+"issues", issues, func(fullName string, number int) []string {
+			labels, _ := s.db.ListIssueLabels(ctx, fullName, number)
+			return labelNames(labels)
+		}),
+	}
+	for _, table := range tables {
+		if err := s.warehouse.Replace(ctx, table); err != nil {
+			return nil, err
+		}
+	}
+	return &models.WarehousePush{
+		Kind:         s.config.Warehouse.Kind,
+		Repositories: len(repos),
+		PullRequests: len(prs),
+		Issues:       len(issues),
+	}, nil
+}
+
+// RunWarehouse pushes the stored data to the warehouse every warehouse interval until ctx is
+// done. It returns at once when no warehouse is configured.
+func (s *Service) RunWarehouse(ctx context.Context) {
+	if s.warehouse == nil {
+		return
+	}
+	interval := s.config.Warehouse.Interval
+	if interval <= 0 {
+		interval = defaultWarehouseInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if push, err := s.pushWarehouse(ctx); err != nil {
+			s.logger.Printf("Error pushing to the warehouse: %v", err)
+		} else {
+			s.logger.Printf("Pushed %d repositories, %d pull requests and %d issues to %s",
+				push.Repositories, push.PullRequests, push.Issues, push.Kind)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// labelNames returns the names of labels
+func labelNames(labels []*models.Label) []string {
+	names := make([]string, 0, len(labels))
+	for _, label := range labels {
+		names = append(names, label.Name)
+	}
+	return names
+}
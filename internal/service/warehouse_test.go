@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"testing"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/db/file"
+	"github.com/siddontang/github-repos-management/internal/models"
+	"github.com/siddontang/github-repos-management/internal/warehouse"
+)
+
+// recordingSink keeps the tables it is given
+type recordingSink struct {
+	tables map[string]*warehouse.Table
+}
+
+func (s *recordingSink) Replace(ctx context.Context, table *warehouse.Table) error {
+	s.tables[table.Name] = table
+	return nil
+}
+
+func TestPushWarehouse(t *testing.T) {
+	ctx := context.Background()
+	db, err := file.NewDB("")
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	cfg := &config.Config{
+		Admin:     config.AdminConfig{APIKey: "s3cr3t"},
+		Warehouse: config.WarehouseConfig{Kind: config.WarehouseClickHouse, TablePrefix: "gh_"},
+	}
+	s := &Service{db: db, config: cfg, logger: log.New(io.Discard, "", 0)}
+	if _, err := s.PushWarehouse(ctx, "s3cr3t"); !errors.Is(err, ErrWarehouseNotConfigured) {
+		t.Errorf("PushWarehouse() without a sink error = %v, want ErrWarehouseNotConfigured", err)
+	}
+
+	sink := &recordingSink{tables: make(map[string]*warehouse.Table)}
+	s.warehouse = sink
+	if err := db.AddRepository(ctx, &models.Repository{Owner: "org", Name: "api", FullName: "org/api"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, pr := range []*models.PullRequest{
+		{RepositoryFullName: "org/api", Number: 1, State: "OPEN"},
+		{RepositoryFullName: "org/api", Number: 2, State: "OPEN", Tombstoned: true},
+	} {
+		if err := db.AddPullRequest(ctx, pr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.AddLabel(ctx, &models.Label{Name: "bug"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AddPullRequestLabel(ctx, "org/api", 1, "bug"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.PushWarehouse(ctx, "wrong"); !errors.Is(err, ErrAdminUnauthorized) {
+		t.Errorf("PushWarehouse(wrong key) error = %v, want ErrAdminUnauthorized", err)
+	}
+	push, err := s.PushWarehouse(ctx, "s3cr3t")
+	if err != nil {
+		t.Fatalf("PushWarehouse() error = %v", err)
+	}
+	want := models.WarehousePush{Kind: "clickhouse", Repositories: 1, PullRequests: 1}
+	if *push != want {
+		t.Errorf("PushWarehouse() = %+v, want %+v", *push, want)
+	}
+	if len(sink.tables) != 3 || sink.tables["gh_repositories"] == nil || sink.tables["gh_issues"] == nil {
+		t.Fatalf("tables = %v", sink.tables)
+	}
+	prs := sink.tables["gh_pull_requests"]
+	for i, column := range prs.Columns {
+		if column.Name == "labels" {
+			if labels := prs.Rows[0][i].([]string); len(labels) != 1 || labels[0] != "bug" {
+				t.Errorf("labels = %v, want bug", labels)
+			}
+		}
+	}
+}
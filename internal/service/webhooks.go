@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/models"
+	"github.com/siddontang/github-repos-management/internal/notify"
+)
+
+// webhookMaxAttempts is the number of times a webhook delivery is attempted
+const webhookMaxAttempts = 3
+
+// AddWebhook registers a webhook receiving the given events (all events when empty)
+func (s *Service) AddWebhook(ctx context.Context, rawURL, secret string, events []string) (*models.Webhook, error) {
+	// Webhooks receive the events of every workspace
+	if workspaceToken(ctx) {
+		return nil, ErrSessionRequired
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, ErrInvalidWebhookURL
+	}
+
+	hook := &models.Webhook{
+		URL:       rawURL,
+		Secret:    secret,
+		Events:    events,
+		CreatedAt: time.Now(),
+	}
+	if err := s.db.AddWebhook(ctx, hook); err != nil {
+		return nil, fmt.Errorf("failed to add webhook: %w", err)
+	}
+
+	s.audit(ctx, models.AuditWebhookAdd, fmt.Sprintf("webhook %d", hook.ID), u.Host)
+	return hook, nil
+}
+
+// ListWebhooks lists registered webhooks
+func (s *Service) ListWebhooks(ctx context.Context) ([]*models.Webhook, error) {
+	if workspaceToken(ctx) {
+		return nil, ErrSessionRequired
+	}
+	return s.db.ListWebhooks(ctx)
+}
+
+// DeleteWebhook removes a webhook and its delivery logs
+func (s *Service) DeleteWebhook(ctx context.Context, id int64) error {
+	if workspaceToken(ctx) {
+		return ErrSessionRequired
+	}
+	if err := s.db.DeleteWebhook(ctx, id); err != nil {
+		return notFound(err, ErrWebhookNotFound)
+	}
+	s.audit(ctx, models.AuditWebhookDelete, fmt.Sprintf("webhook %d", id), "")
+	return nil
+}
+
+// ListWebhookDeliveries lists the delivery logs of a webhook, newest first
+func (s *Service) ListWebhookDeliveries(ctx context.Context, id int64, page, perPage int) ([]*models.WebhookDelivery, *models.Pagination, error) {
+	if workspaceToken(ctx) {
+		return nil, nil, ErrSessionRequired
+	}
+	deliveries, total, err := s.db.ListWebhookDeliveries(ctx, id, page, perPage)
+	if err != nil {
+		return nil, nil, notFound(err, ErrWebhookNotFound)
+	}
+
+	return deliveries, &models.Pagination{
+		Page:       page,
+		PerPage:    perPage,
+		Total:      total,
+		TotalPages: (total + perPage - 1) / perPage,
+	}, nil
+}
+
+// webhookNotifier delivers events to the webhooks stored in the database through the delivery queue
+type webhookNotifier struct {
+	service    *Service
+	httpClient *http.Client
+}
+
+// Ensure webhookNotifier implements notify.Notifier
+var _ notify.Notifier = (*webhookNotifier)(nil)
+
+// Notify queues the delivery of the event to every webhook subscribed to it, whose outcome is
+// recorded in its delivery log. An event the queue has no room for is logged as not delivered.
+func (n *webhookNotifier) Notify(ctx context.Context, event *notify.Event) error {
+	hooks, err := n.service.db.ListWebhooks(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list webhooks: %w", err)
+	}
+
+	queue := n.service.deliveries
+	for _, hook := range hooks {
+		if !webhookWants(hook, event.Type) {
+			continue
+		}
+
+		delivery := &models.WebhookDelivery{
+			WebhookID:  hook.ID,
+			Event:      string(event.Type),
+			Repository: event.Repository,
+		}
+		queued := queue.enqueue(func(ctx context.Context) {
+			result := notify.DeliverWebhook(ctx, n.httpClient, hook.URL, hook.Secret, event, webhookMaxAttempts)
+			delivery.StatusCode = result.StatusCode
+			delivery.Attempts = result.Attempts
+			delivery.Duration = result.Duration
+			delivery.DeliveredAt = time.Now()
+			if result.Err != nil {
+				delivery.Error = result.Err.Error()
+				n.service.logger.Printf("Error delivering %s to webhook %d: %v", event.Type, hook.ID, result.Err)
+			}
+			queue.addLog(delivery)
+		})
+		if !queued {
+			delivery.Error = "delivery queue full"
+			delivery.DeliveredAt = time.Now()
+			n.service.logger.Printf("Error delivering %s to webhook %d: delivery queue full", event.Type, hook.ID)
+			queue.addLog(delivery)
+		}
+	}
+
+	return nil
+}
+
+// webhookWants reports whether a webhook is subscribed to an event type
+func webhookWants(hook *models.Webhook, eventType notify.EventType) bool {
+	if len(hook.Events) == 0 {
+		return true
+	}
+	for _, e := range hook.Events {
+		if notify.EventType(e) == eventType {
+			return true
+		}
+	}
+	return false
+}
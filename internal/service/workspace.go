@@ -0,0 +1,415 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/db"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// workspaceTokenPrefix starts every workspace API token, telling them apart from session tokens
+const workspaceTokenPrefix = "ghw_"
+
+// tokenUseResolution is how often the last use of a workspace token is recorded, so that
+// authenticating a request doesn't write to the database every time
+const tokenUseResolution = time.Hour
+
+// validWorkspaceID matches workspace IDs, which appear in URLs and on the command line
+var validWorkspaceID = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+
+// workspaceKey is the context key of the workspace an operation is restricted to
+type workspaceKey struct{}
+
+// workspaceScope is the workspace an operation is restricted to, and whether the restriction
+// comes from a workspace token rather than a choice of the caller
+type workspaceScope struct {
+	id    string
+	token bool
+}
+
+// WithWorkspace returns a context restricting the operations performed with it to the
+// repositories of a workspace. Repositories added with it join the workspace, and removing
+// a repository only takes it out of the workspace.
+func WithWorkspace(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, workspaceKey{}, workspaceScope{id: id})
+}
+
+// workspaceFrom returns the workspace of a context, or "" when it is not restricted to one
+func workspaceFrom(ctx context.Context) string {
+	scope, _ := ctx.Value(workspaceKey{}).(workspaceScope)
+	return scope.id
+}
+
+// workspaceToken reports whether a context was authenticated with a workspace token,
+// which only grants access to the repositories of its workspace
+func workspaceToken(ctx context.Context) bool {
+	scope, _ := ctx.Value(workspaceKey{}).(workspaceScope)
+	return scope.token
+}
+
+// CreateWorkspace creates an empty workspace. The name defaults to the ID; a zero sync
+// interval leaves its repositories on the global schedule.
+func (s *Service) CreateWorkspace(ctx context.Context, id, name string, syncInterval time.Duration) (*models.Workspace, error) {
+	if workspaceToken(ctx) {
+		return nil, ErrSessionRequired
+	}
+	if !validWorkspaceID.MatchString(id) {
+		return nil, ErrInvalidWorkspace
+	}
+	if syncInterval < 0 {
+		return nil, ErrInvalidSyncConfig
+	}
+	switch _, err := s.db.GetWorkspace(ctx, id); {
+	case err == nil:
+		return nil, ErrWorkspaceExists
+	case !errors.Is(err, db.ErrNotFound):
+		return nil, err
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = id
+	}
+	now := time.Now()
+	workspace := &models.Workspace{
+		ID:           id,
+		Name:         name,
+		SyncInterval: syncInterval,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if err := s.db.SaveWorkspace(ctx, workspace); err != nil {
+		return nil, fmt.Errorf("failed to save workspace: %w", err)
+	}
+
+	s.audit(ctx, models.AuditWorkspaceCreate, "workspace "+id, name)
+	return workspace, nil
+}
+
+// GetWorkspace gets a workspace
+func (s *Service) GetWorkspace(ctx context.Context, id string) (*models.Workspace, error) {
+	if workspaceToken(ctx) && workspaceFrom(ctx) != id {
+		return nil, ErrWorkspaceNotFound
+	}
+	workspace, err := s.db.GetWorkspace(ctx, id)
+	if err != nil {
+		return nil, notFound(err, ErrWorkspaceNotFound)
+	}
+	return workspace, nil
+}
+
+// ListWorkspaces lists workspaces ordered by ID; a workspace token only sees its own
+func (s *Service) ListWorkspaces(ctx context.Context) ([]*models.Workspace, error) {
+	if workspaceToken(ctx) {
+		workspace, err := s.GetWorkspace(ctx, workspaceFrom(ctx))
+		if err != nil {
+			return nil, err
+		}
+		return []*models.Workspace{workspace}, nil
+	}
+	return s.db.ListWorkspaces(ctx)
+}
+
+// UpdateWorkspace applies a partial update to a workspace
+func (s *Service) UpdateWorkspace(ctx context.Context, id string, update *models.WorkspaceUpdate) (*models.Workspace, error) {
+	if workspaceToken(ctx) {
+		return nil, ErrSessionRequired
+	}
+	if update.SyncInterval != nil && *update.SyncInterval < 0 {
+		return nil, ErrInvalidSyncConfig
+	}
+
+	var changes []string
+	workspace, err := s.db.UpdateWorkspace(ctx, id, func(workspace *models.Workspace) bool {
+		if update.Name != nil && strings.TrimSpace(*update.Name) != "" {
+			workspace.Name = strings.TrimSpace(*update.Name)
+			changes = append(changes, "name="+workspace.Name)
+		}
+		if update.SyncInterval != nil {
+			workspace.SyncInterval = *update.SyncInterval
+			changes = append(changes, fmt.Sprintf("sync_interval=%s", workspace.SyncInterval))
+		}
+		if len(changes) == 0 {
+			return false
+		}
+		workspace.UpdatedAt = time.Now()
+		return true
+	})
+	if err != nil {
+		return nil, notFound(err, ErrWorkspaceNotFound)
+	}
+	if len(changes) == 0 {
+		return workspace, nil
+	}
+	s.audit(ctx, models.AuditWorkspaceUpdate, "workspace "+id, strings.Join(changes, ", "))
+	return workspace, nil
+}
+
+// DeleteWorkspace deletes a workspace and its tokens; its repositories stay tracked
+func (s *Service) DeleteWorkspace(ctx context.Context, id string) error {
+	if workspaceToken(ctx) {
+		return ErrSessionRequired
+	}
+	if err := s.db.DeleteWorkspace(ctx, id); err != nil {
+		return notFound(err, ErrWorkspaceNotFound)
+	}
+	s.audit(ctx, models.AuditWorkspaceDelete, "workspace "+id, "")
+	return nil
+}
+
+// CreateWorkspaceToken issues an API token restricted to a workspace. The token is
+// returned only here; the workspace keeps a hash of it.
+func (s *Service) CreateWorkspaceToken(ctx context.Context, id, name string) (string, *models.WorkspaceToken, error) {
+	if workspaceToken(ctx) {
+		return "", nil, ErrSessionRequired
+	}
+	secret := make([]byte, 20)
+	if _, err := rand.Read(secret); err != nil {
+		return "", nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+	raw := workspaceTokenPrefix + hex.EncodeToString(secret)
+
+	token := &models.WorkspaceToken{
+		Name:      strings.TrimSpace(name),
+		Hash:      hashToken(raw),
+		CreatedAt: time.Now(),
+	}
+	if _, err := s.db.UpdateWorkspace(ctx, id, func(workspace *models.Workspace) bool {
+		token.ID = 1
+		for _, existing := range workspace.Tokens {
+			if existing.ID >= token.ID {
+				token.ID = existing.ID + 1
+			}
+		}
+		if strings.TrimSpace(name) == "" {
+			token.Name = fmt.Sprintf("token-%d", token.ID)
+		}
+		copied := *token
+		workspace.Tokens = append(workspace.Tokens, &copied)
+		return true
+	}); err != nil {
+		return "", nil, notFound(err, ErrWorkspaceNotFound)
+	}
+
+	s.audit(ctx, models.AuditWorkspaceToken, "workspace "+id, fmt.Sprintf("token %d (%s)", token.ID, token.Name))
+	return raw, token, nil
+}
+
+// RevokeWorkspaceToken deletes an API token of a workspace
+func (s *Service) RevokeWorkspaceToken(ctx context.Context, id string, tokenID int64) error {
+	if workspaceToken(ctx) {
+		return ErrSessionRequired
+	}
+	revoked := false
+	if _, err := s.db.UpdateWorkspace(ctx, id, func(workspace *models.Workspace) bool {
+		kept := make([]*models.WorkspaceToken, 0, len(workspace.Tokens))
+		for _, token := range workspace.Tokens {
+			if token.ID != tokenID {
+				kept = append(kept, token)
+			}
+		}
+		revoked = len(kept) < len(workspace.Tokens)
+		workspace.Tokens = kept
+		return revoked
+	}); err != nil {
+		return notFound(err, ErrWorkspaceNotFound)
+	}
+	if !revoked {
+		return ErrWorkspaceTokenNotFound
+	}
+
+	s.audit(ctx, models.AuditWorkspaceRevoke, "workspace "+id, fmt.Sprintf("token %d", tokenID))
+	return nil
+}
+
+// authenticateWorkspaceToken restricts a context to the workspace of an API token. A token
+// presented on the routes of another workspace is rejected as if that workspace didn't exist.
+func (s *Service) authenticateWorkspaceToken(ctx context.Context, raw string) (context.Context, error) {
+	workspaces, err := s.db.ListWorkspaces(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workspaces: %w", err)
+	}
+
+	hash := hashToken(raw)
+	for _, workspace := range workspaces {
+		for _, token := range workspace.Tokens {
+			if subtle.ConstantTimeCompare([]byte(token.Hash), []byte(hash)) != 1 {
+				continue
+			}
+			if current := workspaceFrom(ctx); current != "" && current != workspace.ID {
+				return nil, ErrWorkspaceNotFound
+			}
+
+			if time.Since(token.LastUsedAt) > tokenUseResolution {
+				s.recordTokenUse(ctx, workspace.ID, token.ID)
+			}
+			ctx = WithActor(ctx, fmt.Sprintf("%s (workspace %s token)", token.Name, workspace.ID))
+			return context.WithValue(ctx, workspaceKey{}, workspaceScope{id: workspace.ID, token: true}), nil
+		}
+	}
+	return nil, ErrInvalidWorkspaceToken
+}
+
+// recordTokenUse stamps the last use of a workspace token, unless it was revoked since
+func (s *Service) recordTokenUse(ctx context.Context, id string, tokenID int64) {
+	if _, err := s.db.UpdateWorkspace(ctx, id, func(workspace *models.Workspace) bool {
+		for _, token := range workspace.Tokens {
+			if token.ID == tokenID && time.Since(token.LastUsedAt) > tokenUseResolution {
+				token.LastUsedAt = time.Now()
+				return true
+			}
+		}
+		return false
+	}); err != nil {
+		s.logger.Printf("Error recording use of workspace %s token %d: %v", id, tokenID, err)
+	}
+}
+
+// hashToken returns the hex SHA-256 of a workspace token
+func hashToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+// scopeRepositories keeps the repositories of the workspace of a context
+func (s *Service) scopeRepositories(ctx context.Context, repos []*models.Repository) ([]*models.Repository, error) {
+	id := workspaceFrom(ctx)
+	if id == "" {
+		return repos, nil
+	}
+	workspace, err := s.db.GetWorkspace(ctx, id)
+	if err != nil {
+		return nil, notFound(err, ErrWorkspaceNotFound)
+	}
+
+	scoped := make([]*models.Repository, 0, len(workspace.Repositories))
+	for _, repo := range repos {
+		if workspace.HasRepository(repo.FullName) {
+			scoped = append(scoped, repo)
+		}
+	}
+	return scoped, nil
+}
+
+// checkWorkspace returns ErrRepositoryNotFound for a repository outside the workspace of a context
+func (s *Service) checkWorkspace(ctx context.Context, fullName string) error {
+	id := workspaceFrom(ctx)
+	if id == "" {
+		return nil
+	}
+	workspace, err := s.db.GetWorkspace(ctx, id)
+	if err != nil {
+		return notFound(err, ErrWorkspaceNotFound)
+	}
+	if !workspace.HasRepository(fullName) {
+		return ErrRepositoryNotFound
+	}
+	return nil
+}
+
+// checkRepositoryWorkspace returns ErrRepositoryNotFound for a repository that isn't tracked or
+// is outside the workspace of a context
+func (s *Service) checkRepositoryWorkspace(ctx context.Context, owner, name string) error {
+	repo, err := s.db.GetRepository(ctx, owner, name)
+	if err != nil {
+		return notFound(err, ErrRepositoryNotFound)
+	}
+	return s.checkWorkspace(ctx, repo.FullName)
+}
+
+// addWorkspaceRepository adds a tracked repository to the workspace of a context, if any. A
+// workspace token can't add a repository already in another workspace.
+func (s *Service) addWorkspaceRepository(ctx context.Context, fullName string) error {
+	id := workspaceFrom(ctx)
+	if id == "" {
+		return nil
+	}
+	if workspaceToken(ctx) {
+		// A token only reaches the data of other workspaces through a login session
+		workspaces, err := s.db.ListWorkspaces(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list workspaces: %w", err)
+		}
+		for _, other := range workspaces {
+			if other.ID != id && other.HasRepository(fullName) {
+				return ErrRepositoryNotFound
+			}
+		}
+	}
+
+	added := false
+	if _, err := s.db.UpdateWorkspace(ctx, id, func(workspace *models.Workspace) bool {
+		if added = !workspace.HasRepository(fullName); added {
+			workspace.Repositories = append(workspace.Repositories, fullName)
+			sort.Strings(workspace.Repositories)
+			workspace.UpdatedAt = time.Now()
+		}
+		return added
+	}); err != nil {
+		return notFound(err, ErrWorkspaceNotFound)
+	}
+	if !added {
+		return nil
+	}
+	s.audit(ctx, models.AuditWorkspaceAdd, "workspace "+id, fullName)
+	return nil
+}
+
+// removeWorkspaceRepository takes a repository out of the workspace of a context; it stays tracked
+func (s *Service) removeWorkspaceRepository(ctx context.Context, fullName string) error {
+	id := workspaceFrom(ctx)
+	removed := false
+	if _, err := s.db.UpdateWorkspace(ctx, id, func(workspace *models.Workspace) bool {
+		kept := make([]string, 0, len(workspace.Repositories))
+		for _, name := range workspace.Repositories {
+			if name != fullName {
+				kept = append(kept, name)
+			}
+		}
+		if removed = len(kept) < len(workspace.Repositories); removed {
+			workspace.Repositories = kept
+			workspace.UpdatedAt = time.Now()
+		}
+		return removed
+	}); err != nil {
+		return notFound(err, ErrWorkspaceNotFound)
+	}
+	if !removed {
+		return ErrRepositoryNotFound
+	}
+	s.audit(ctx, models.AuditWorkspaceRemove, "workspace "+id, fullName)
+	return nil
+}
+
+// workspaceIntervals returns the sync interval of each repository on the schedule of a
+// workspace: the shortest interval of the workspaces including it that set one
+func (s *Service) workspaceIntervals(ctx context.Context) map[string]time.Duration {
+	workspaces, err := s.db.ListWorkspaces(ctx)
+	if err != nil {
+		s.logger.Printf("Error listing workspaces for sync intervals: %v", err)
+		return nil
+	}
+
+	intervals := make(map[string]time.Duration)
+	for _, workspace := range workspaces {
+		if workspace.SyncInterval <= 0 {
+			continue
+		}
+		for _, fullName := range workspace.Repositories {
+			if current, ok := intervals[fullName]; !ok || workspace.SyncInterval < current {
+				intervals[fullName] = workspace.SyncInterval
+			}
+		}
+	}
+	return intervals
+}
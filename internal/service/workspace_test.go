@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/db/file"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+func TestWorkspaces(t *testing.T) {
+	ctx := context.Background()
+	db, err := file.NewDB("")
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	for _, repo := range []*models.Repository{
+		{Owner: "pingcap", Name: "tidb", FullName: "pingcap/tidb"},
+		{Owner: "pingcap", Name: "tikv", FullName: "pingcap/tikv"},
+		{Owner: "tikv", Name: "pd", FullName: "tikv/pd"},
+	} {
+		if err := db.AddRepository(ctx, repo); err != nil {
+			t.Fatalf("AddRepository() error = %v", err)
+		}
+	}
+	s := &Service{db: db, config: &config.Config{GitHub: config.GitHubConfig{RefreshInterval: time.Hour}}}
+
+	if _, err := s.CreateWorkspace(ctx, "Not Valid", "", 0); !errors.Is(err, ErrInvalidWorkspace) {
+		t.Errorf("CreateWorkspace(Not Valid) error = %v, want ErrInvalidWorkspace", err)
+	}
+	if _, err := s.CreateWorkspace(ctx, "sql", "SQL layer", 10*time.Minute); err != nil {
+		t.Fatalf("CreateWorkspace() error = %v", err)
+	}
+	if _, err := s.CreateWorkspace(ctx, "sql", "", 0); !errors.Is(err, ErrWorkspaceExists) {
+		t.Errorf("CreateWorkspace() twice error = %v, want ErrWorkspaceExists", err)
+	}
+
+	sql := WithWorkspace(ctx, "sql")
+	for _, fullName := range []string{"pingcap/tikv", "pingcap/tidb"} {
+		if err := s.addWorkspaceRepository(sql, fullName); err != nil {
+			t.Fatalf("addWorkspaceRepository(%s) error = %v", fullName, err)
+		}
+	}
+
+	repos, pagination, err := s.ListRepositories(sql, &models.RepositoryFilter{Page: 1, PerPage: 10})
+	if err != nil {
+		t.Fatalf("ListRepositories() error = %v", err)
+	}
+	if pagination.Total != 2 {
+		t.Errorf("ListRepositories() in workspace total = %d, want 2", pagination.Total)
+	}
+	for _, repo := range repos {
+		if repo.FullName == "tikv/pd" {
+			t.Errorf("ListRepositories() in workspace returned %s", repo.FullName)
+		}
+	}
+	if _, err := s.GetRepository(sql, "tikv", "pd"); !errors.Is(err, ErrRepositoryNotFound) {
+		t.Errorf("GetRepository() outside the workspace error = %v, want ErrRepositoryNotFound", err)
+	}
+
+	// A workspace token can't pull in the repositories of another workspace
+	if _, err := s.CreateWorkspace(ctx, "storage", "", 0); err != nil {
+		t.Fatalf("CreateWorkspace() error = %v", err)
+	}
+	storageToken := context.WithValue(ctx, workspaceKey{}, workspaceScope{id: "storage", token: true})
+	if err := s.addWorkspaceRepository(storageToken, "pingcap/tidb"); !errors.Is(err, ErrRepositoryNotFound) {
+		t.Errorf("addWorkspaceRepository() of another workspace's repository with a token error = %v, want ErrRepositoryNotFound", err)
+	}
+	if err := s.addWorkspaceRepository(storageToken, "tikv/pd"); err != nil {
+		t.Errorf("addWorkspaceRepository() of a repository in no workspace with a token error = %v", err)
+	}
+	if err := s.addWorkspaceRepository(WithWorkspace(ctx, "storage"), "pingcap/tidb"); err != nil {
+		t.Errorf("addWorkspaceRepository() of another workspace's repository with a session error = %v", err)
+	}
+
+	if _, _, err := s.ListRepositories(WithWorkspace(ctx, "missing"), &models.RepositoryFilter{Page: 1, PerPage: 10}); !errors.Is(err, ErrWorkspaceNotFound) {
+		t.Errorf("ListRepositories() in a missing workspace error = %v, want ErrWorkspaceNotFound", err)
+	}
+
+	intervals := s.workspaceIntervals(ctx)
+	tidb, _ := db.GetRepository(ctx, "pingcap", "tidb")
+	pd, _ := db.GetRepository(ctx, "tikv", "pd")
+	if got := s.syncInterval(tidb, intervals); got != 10*time.Minute {
+		t.Errorf("syncInterval(pingcap/tidb) = %s, want the workspace interval", got)
+	}
+	if got := s.syncInterval(pd, intervals); got != time.Hour {
+		t.Errorf("syncInterval(tikv/pd) = %s, want the global interval", got)
+	}
+
+	// Removing a repository in a workspace keeps it tracked
+	if err := s.DeleteRepository(sql, "pingcap", "tikv"); err != nil {
+		t.Fatalf("DeleteRepository() in workspace error = %v", err)
+	}
+	if _, err := s.GetRepository(ctx, "pingcap", "tikv"); err != nil {
+		t.Errorf("GetRepository() after removing it from the workspace error = %v", err)
+	}
+	if _, err := s.GetRepository(sql, "pingcap", "tikv"); !errors.Is(err, ErrRepositoryNotFound) {
+		t.Errorf("GetRepository() in workspace after removing it error = %v, want ErrRepositoryNotFound", err)
+	}
+}
+
+func TestWorkspaceTokens(t *testing.T) {
+	ctx := context.Background()
+	db, err := file.NewDB("")
+	if err != nil {
+		t.Fatalf("NewDB() error = %v", err)
+	}
+	s := &Service{db: db, config: &config.Config{}, logger: log.New(io.Discard, "", 0)}
+	for _, id := range []string{"sql", "storage"} {
+		if _, err := s.CreateWorkspace(ctx, id, "", 0); err != nil {
+			t.Fatalf("CreateWorkspace(%s) error = %v", id, err)
+		}
+	}
+
+	raw, token, err := s.CreateWorkspaceToken(ctx, "sql", "ci")
+	if err != nil {
+		t.Fatalf("CreateWorkspaceToken() error = %v", err)
+	}
+	if token.Hash == raw || token.Hash == "" {
+		t.Errorf("CreateWorkspaceToken() stored hash = %q, want a hash of the token", token.Hash)
+	}
+
+	authenticated, session, err := s.Authenticate(ctx, raw)
+	if err != nil || session != nil {
+		t.Fatalf("Authenticate() = %v, %v, want a context without a session", session, err)
+	}
+	if workspaceFrom(authenticated) != "sql" || !workspaceToken(authenticated) {
+		t.Errorf("Authenticate() context workspace = %q, want the token's workspace", workspaceFrom(authenticated))
+	}
+	workspaces, err := s.ListWorkspaces(authenticated)
+	if err != nil || len(workspaces) != 1 || workspaces[0].ID != "sql" {
+		t.Errorf("ListWorkspaces() with a token = %v, %v, want only its workspace", workspaces, err)
+	}
+	if _, err := s.CreateWorkspace(authenticated, "other", "", 0); !errors.Is(err, ErrSessionRequired) {
+		t.Errorf("CreateWorkspace() with a token error = %v, want ErrSessionRequired", err)
+	}
+	if _, _, err := s.ListAudit(authenticated, &models.AuditFilter{Page: 1, PerPage: 10}); !errors.Is(err, ErrSessionRequired) {
+		t.Errorf("ListAudit() with a token error = %v, want ErrSessionRequired", err)
+	}
+
+	if _, _, err := s.Authenticate(WithWorkspace(ctx, "storage"), raw); !errors.Is(err, ErrWorkspaceNotFound) {
+		t.Errorf("Authenticate() on another workspace error = %v, want ErrWorkspaceNotFound", err)
+	}
+	if _, _, err := s.Authenticate(ctx, raw+"0"); !errors.Is(err, ErrInvalidWorkspaceToken) {
+		t.Errorf("Authenticate() with an unknown token error = %v, want ErrInvalidWorkspaceToken", err)
+	}
+
+	if err := s.RevokeWorkspaceToken(ctx, "sql", token.ID); err != nil {
+		t.Fatalf("RevokeWorkspaceToken() error = %v", err)
+	}
+	if _, _, err := s.Authenticate(ctx, raw); !errors.Is(err, ErrInvalidWorkspaceToken) {
+		t.Errorf("Authenticate() with a revoked token error = %v, want ErrInvalidWorkspaceToken", err)
+	}
+	if err := s.RevokeWorkspaceToken(ctx, "sql", token.ID); !errors.Is(err, ErrWorkspaceTokenNotFound) {
+		t.Errorf("RevokeWorkspaceToken() twice error = %v, want ErrWorkspaceTokenNotFound", err)
+	}
+
+	// Recording the use of a token authenticated before it was revoked, or before its workspace
+	// was deleted, doesn't bring either back
+	s.recordTokenUse(ctx, "sql", token.ID)
+	if _, _, err := s.Authenticate(ctx, raw); !errors.Is(err, ErrInvalidWorkspaceToken) {
+		t.Errorf("Authenticate() after recording the use of a revoked token error = %v, want ErrInvalidWorkspaceToken", err)
+	}
+	if err := s.DeleteWorkspace(ctx, "sql"); err != nil {
+		t.Fatalf("DeleteWorkspace() error = %v", err)
+	}
+	s.recordTokenUse(ctx, "sql", token.ID)
+	if _, err := s.GetWorkspace(ctx, "sql"); !errors.Is(err, ErrWorkspaceNotFound) {
+		t.Errorf("GetWorkspace() after recording a token use in a deleted workspace error = %v, want ErrWorkspaceNotFound", err)
+	}
+	if _, err := s.UpdateWorkspace(ctx, "sql", &models.WorkspaceUpdate{Name: &raw}); !errors.Is(err, ErrWorkspaceNotFound) {
+		t.Errorf("UpdateWorkspace() of a deleted workspace error = %v, want ErrWorkspaceNotFound", err)
+	}
+}
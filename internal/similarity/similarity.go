@@ -0,0 +1,114 @@
+// Package similarity finds pairs of documents that are probably about the same thing, such as
+// duplicate issues. Detectors are behind an interface so that word shingling can be swapped for
+// an embedding model.
+package similarity
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Document is a text to compare, identified by the caller
+type Document struct {
+	ID   int
+	Text string
+}
+
+// Match is a pair of documents scoring at least the threshold, with A the lower ID
+type Match struct {
+	A, B  int
+	Score float64 // From 0, nothing in common, to 1, the same
+}
+
+// Detector finds the pairs of documents that are alike
+type Detector interface {
+	// Matches returns the pairs of documents scoring at least threshold, best first
+	Matches(docs []Document, threshold float64) []Match
+}
+
+// DefaultShingleSize is the number of consecutive words in a shingle when none is given
+const DefaultShingleSize = 2
+
+// Shingles scores documents by the Jaccard similarity of their sets of word shingles, the runs of
+// Size consecutive words. Texts shorter than Size words are a single shingle.
+type Shingles struct {
+	Size int
+}
+
+// Matches returns the pairs of documents whose shingle sets overlap by at least threshold, best first
+func (s Shingles) Matches(docs []Document, threshold float64) []Match {
+	size := s.Size
+	if size <= 0 {
+		size = DefaultShingleSize
+	}
+	sets := make([]map[string]bool, len(docs))
+	for i, doc := range docs {
+		sets[i] = shingles(Words(doc.Text), size)
+	}
+
+	var matches []Match
+	for i := range docs {
+		for j := i + 1; j < len(docs); j++ {
+			score := jaccard(sets[i], sets[j])
+			if score == 0 || score < threshold {
+				continue
+			}
+			a, b := docs[i].ID, docs[j].ID
+			if a > b {
+				a, b = b, a
+			}
+			matches = append(matches, Match{A: a, B: b, Score: score})
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		if matches[i].A != matches[j].A {
+			return matches[i].A < matches[j].A
+		}
+		return matches[i].B < matches[j].B
+	})
+	return matches
+}
+
+// Words splits a text into lower case words of letters and digits
+func Words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// shingles returns the set of runs of size consecutive words
+func shingles(words []string, size int) map[string]bool {
+	set := make(map[string]bool)
+	if len(words) == 0 {
+		return set
+	}
+	if len(words) < size {
+		set[strings.Join(words, " ")] = true
+		return set
+	}
+	for i := 0; i+size <= len(words); i++ {
+		set[strings.Join(words[i:i+size], " ")] = true
+	}
+	return set
+}
+
+// jaccard returns the size of the intersection of two sets over the size of their union
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	common := 0
+	for shingle := range a {
+		if b[shingle] {
+			common++
+		}
+	}
+	return float64(common) / float64(len(a)+len(b)-common)
+}
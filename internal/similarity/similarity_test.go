@@ -0,0 +1,35 @@
+package similarity
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWords(t *testing.T) {
+	got := Words("Panic: nil pointer in `ParseConfig()` (v7.1)")
+	want := []string{"panic", "nil", "pointer", "in", "parseconfig", "v7", "1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Words() = %q, want %q", got, want)
+	}
+}
+
+func TestShinglesMatches(t *testing.T) {
+	docs := []Document{
+		{ID: 3, Text: "Panic on startup when config is missing"},
+		{ID: 1, Text: "panic on startup when the config is missing"},
+		{ID: 2, Text: "Add dark mode to the dashboard"},
+		{ID: 4, Text: ""},
+	}
+
+	matches := Shingles{}.Matches(docs, 0.4)
+	if len(matches) != 1 {
+		t.Fatalf("Matches() = %+v, want one pair", matches)
+	}
+	if m := matches[0]; m.A != 1 || m.B != 3 || m.Score < 0.4 || m.Score >= 1 {
+		t.Errorf("Matches() = %+v, want #1 and #3 scoring between 0.4 and 1", m)
+	}
+
+	if matches := (Shingles{Size: 1}).Matches(docs[:2], 0); len(matches) != 1 || matches[0].Score != 7.0/8 {
+		t.Errorf("Matches(single words) = %+v, want a score of 7/8", matches)
+	}
+}
@@ -0,0 +1,417 @@
+// Package sso logs users in with an OpenID Connect provider (Google, Okta, ...) or
+// GitHub OAuth through the device authorization flow, and issues signed session
+// tokens naming the user, so changes can be attributed without sharing static keys.
+package sso
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Providers
+const (
+	ProviderOIDC   = "oidc"
+	ProviderGitHub = "github"
+)
+
+// GitHub OAuth endpoints; GitHub Enterprise hosts can be set with Options.GitHubURL
+const (
+	defaultGitHubURL    = "https://github.com"
+	defaultGitHubAPIURL = "https://api.github.com"
+)
+
+// Errors returned by the device flow
+var (
+	ErrUnknownProvider = errors.New("unknown identity provider")
+	ErrAccessDenied    = errors.New("login was denied")
+	ErrCodeExpired     = errors.New("login code expired before it was entered")
+	ErrNotAllowed      = errors.New("user is not allowed to log in")
+)
+
+// Options configures a provider
+type Options struct {
+	Provider     string // oidc or github
+	Issuer       string // OIDC issuer URL, such as https://accounts.google.com
+	ClientID     string
+	ClientSecret string   // Required by some providers, such as Google, even for the device flow
+	Scopes       []string // Defaults to openid, email and profile for OIDC and read:user, user:email for GitHub
+
+	// Logins are restricted to users matching any of these when one is set
+	AllowedUsers   []string // GitHub logins, or emails of OIDC users
+	AllowedOrgs    []string // GitHub organizations the user is a member of; read:org is requested
+	AllowedDomains []string // Domains of the verified emails of OIDC users
+
+	GitHubURL    string // Defaults to https://github.com
+	GitHubAPIURL string // Defaults to https://api.github.com
+
+	HTTPClient *http.Client
+}
+
+// Identity is a user authenticated by the provider
+type Identity struct {
+	Provider string `json:"provider"`
+	Subject  string `json:"sub"` // Stable ID of the user at the provider
+	Email    string `json:"email,omitempty"`
+	Name     string `json:"name,omitempty"`
+}
+
+// User returns the name changes are attributed to: the email when known, otherwise the name or subject
+func (i *Identity) User() string {
+	switch {
+	case i.Email != "":
+		return i.Email
+	case i.Name != "":
+		return i.Name
+	}
+	return i.Subject
+}
+
+// DeviceCode is handed out when a login starts. The user opens VerificationURI and enters UserCode.
+type DeviceCode struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete,omitempty"` // Includes the code, when supported
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+// Provider runs device logins against an identity provider
+type Provider struct {
+	opts       Options
+	httpClient *http.Client
+
+	deviceEndpoint string
+	tokenEndpoint  string
+	issuer         string // Expected issuer of OIDC ID tokens
+}
+
+// NewProvider creates a provider, reading the endpoints of an OIDC issuer from its discovery document
+func NewProvider(ctx context.Context, opts Options) (*Provider, error) {
+	p := &Provider{opts: opts, httpClient: opts.HTTPClient}
+	if p.httpClient == nil {
+		p.httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	switch opts.Provider {
+	case ProviderOIDC:
+		if err := p.discover(ctx); err != nil {
+			return nil, err
+		}
+		if len(p.opts.Scopes) == 0 {
+			p.opts.Scopes = []string{"openid", "email", "profile"}
+		}
+	case ProviderGitHub:
+		if p.opts.GitHubURL == "" {
+			p.opts.GitHubURL = defaultGitHubURL
+		}
+		if p.opts.GitHubAPIURL == "" {
+			p.opts.GitHubAPIURL = defaultGitHubAPIURL
+		}
+		p.deviceEndpoint = strings.TrimSuffix(p.opts.GitHubURL, "/") + "/login/device/code"
+		p.tokenEndpoint = strings.TrimSuffix(p.opts.GitHubURL, "/") + "/login/oauth/access_token"
+		if len(p.opts.Scopes) == 0 {
+			p.opts.Scopes = []string{"read:user", "user:email"}
+		}
+		if len(p.opts.AllowedOrgs) > 0 && !contains(p.opts.Scopes, "read:org") {
+			p.opts.Scopes = append(p.opts.Scopes[:len(p.opts.Scopes):len(p.opts.Scopes)], "read:org")
+		}
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownProvider, opts.Provider)
+	}
+	return p, nil
+}
+
+// discover reads the endpoints from the issuer's OpenID configuration
+func (p *Provider) discover(ctx context.Context) error {
+	var doc struct {
+		Issuer                      string `json:"issuer"`
+		DeviceAuthorizationEndpoint string `json:"device_authorization_endpoint"`
+		TokenEndpoint               string `json:"token_endpoint"`
+	}
+	endpoint := strings.TrimSuffix(p.opts.Issuer, "/") + "/.well-known/openid-configuration"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("invalid issuer: %w", err)
+	}
+	if err := p.do(req, &doc); err != nil {
+		return fmt.Errorf("failed to read OpenID configuration: %w", err)
+	}
+	if doc.DeviceAuthorizationEndpoint == "" {
+		return fmt.Errorf("issuer %s does not support the device authorization flow", p.opts.Issuer)
+	}
+
+	p.issuer = doc.Issuer
+	p.deviceEndpoint = doc.DeviceAuthorizationEndpoint
+	p.tokenEndpoint = doc.TokenEndpoint
+	return nil
+}
+
+// StartLogin requests a device code for the user to enter
+func (p *Provider) StartLogin(ctx context.Context) (*DeviceCode, error) {
+	form := url.Values{"client_id": {p.opts.ClientID}, "scope": {strings.Join(p.opts.Scopes, " ")}}
+	if p.opts.ClientSecret != "" {
+		form.Set("client_secret", p.opts.ClientSecret)
+	}
+
+	var code DeviceCode
+	if err := p.post(ctx, p.deviceEndpoint, form, &code); err != nil {
+		return nil, fmt.Errorf("failed to start login: %w", err)
+	}
+	if code.Interval <= 0 {
+		code.Interval = 5
+	}
+	return &code, nil
+}
+
+// tokenResponse is the reply of the token endpoint, carrying either tokens or an error
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	IDToken     string `json:"id_token"`
+	Error       string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+// CompleteLogin polls the provider until the user entered the code, then returns who logged in
+func (p *Provider) CompleteLogin(ctx context.Context, code *DeviceCode) (*Identity, error) {
+	interval := time.Duration(code.Interval) * time.Second
+	deadline := time.Now().Add(time.Duration(code.ExpiresIn) * time.Second)
+
+	form := url.Values{
+		"client_id":   {p.opts.ClientID},
+		"device_code": {code.DeviceCode},
+		"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
+	}
+	if p.opts.ClientSecret != "" {
+		form.Set("client_secret", p.opts.ClientSecret)
+	}
+
+	for {
+		if code.ExpiresIn > 0 && time.Now().After(deadline) {
+			return nil, ErrCodeExpired
+		}
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+
+		var token tokenResponse
+		if err := p.post(ctx, p.tokenEndpoint, form, &token); err != nil && token.Error == "" {
+			return nil, fmt.Errorf("failed to complete login: %w", err)
+		}
+
+		switch token.Error {
+		case "":
+			identity, err := p.identify(ctx, &token)
+			if err != nil {
+				return nil, err
+			}
+			if err := p.authorize(ctx, identity, token.AccessToken); err != nil {
+				return nil, err
+			}
+			return identity, nil
+		case "authorization_pending":
+		case "slow_down":
+			interval += 5 * time.Second
+		case "access_denied":
+			return nil, ErrAccessDenied
+		case "expired_token":
+			return nil, ErrCodeExpired
+		default:
+			return nil, fmt.Errorf("login failed: %s %s", token.Error, token.Description)
+		}
+	}
+}
+
+// identify returns the user the tokens were issued to
+func (p *Provider) identify(ctx context.Context, token *tokenResponse) (*Identity, error) {
+	if p.opts.Provider == ProviderGitHub {
+		return p.githubUser(ctx, token.AccessToken)
+	}
+	if token.IDToken == "" {
+		return nil, errors.New("provider returned no ID token; is the openid scope granted?")
+	}
+	return p.parseIDToken(token.IDToken)
+}
+
+// parseIDToken reads the claims of an ID token. The token came straight from the token
+// endpoint over TLS, so its signature need not be checked (OpenID Connect Core 3.1.3.7),
+// but the issuer, audience and expiry still are.
+func (p *Provider) parseIDToken(idToken string) (*Identity, error) {
+	parts := strings.Split(idToken, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed ID token")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("malformed ID token: %w", err)
+	}
+
+	var claims struct {
+		Issuer   string          `json:"iss"`
+		Subject  string          `json:"sub"`
+		Audience json.RawMessage `json:"aud"` // A string or a list of strings
+		Expiry   int64           `json:"exp"`
+		Email    string          `json:"email"`
+		Verified *bool           `json:"email_verified"`
+		Name     string          `json:"name"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("malformed ID token claims: %w", err)
+	}
+
+	if claims.Issuer != p.issuer {
+		return nil, fmt.Errorf("ID token issued by %s, want %s", claims.Issuer, p.issuer)
+	}
+	var audiences []string
+	if err := json.Unmarshal(claims.Audience, &audiences); err != nil {
+		var audience string
+		if err := json.Unmarshal(claims.Audience, &audience); err != nil {
+			return nil, errors.New("ID token has no audience")
+		}
+		audiences = []string{audience}
+	}
+	if !contains(audiences, p.opts.ClientID) {
+		return nil, errors.New("ID token was issued to another client")
+	}
+	if time.Now().After(time.Unix(claims.Expiry, 0)) {
+		return nil, errors.New("ID token expired")
+	}
+
+	// Emails the provider says are unverified can't be told apart from made up ones
+	email := claims.Email
+	if claims.Verified != nil && !*claims.Verified {
+		email = ""
+	}
+	return &Identity{Provider: ProviderOIDC, Subject: claims.Subject, Email: email, Name: claims.Name}, nil
+}
+
+// githubUser looks up the GitHub user an access token belongs to
+func (p *Provider) githubUser(ctx context.Context, accessToken string) (*Identity, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(p.opts.GitHubAPIURL, "/")+"/user", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	var user struct {
+		ID    int64  `json:"id"`
+		Login string `json:"login"`
+		Email string `json:"email"`
+	}
+	if err := p.do(req, &user); err != nil {
+		return nil, fmt.Errorf("failed to get GitHub user: %w", err)
+	}
+	return &Identity{Provider: ProviderGitHub, Subject: fmt.Sprint(user.ID), Email: user.Email, Name: user.Login}, nil
+}
+
+// authorize checks that a user may log in when logins are restricted: GitHub users by login or
+// organization, OIDC users by email or its domain
+func (p *Provider) authorize(ctx context.Context, identity *Identity, accessToken string) error {
+	opts := p.opts
+	if len(opts.AllowedUsers) == 0 && len(opts.AllowedOrgs) == 0 && len(opts.AllowedDomains) == 0 {
+		return nil
+	}
+
+	if identity.Provider == ProviderGitHub {
+		if containsFold(opts.AllowedUsers, identity.Name) {
+			return nil
+		}
+		if len(opts.AllowedOrgs) > 0 {
+			orgs, err := p.githubOrgs(ctx, accessToken)
+			if err != nil {
+				return err
+			}
+			for _, org := range orgs {
+				if containsFold(opts.AllowedOrgs, org) {
+					return nil
+				}
+			}
+		}
+	} else if identity.Email != "" {
+		_, domain, _ := strings.Cut(identity.Email, "@")
+		if containsFold(opts.AllowedUsers, identity.Email) || containsFold(opts.AllowedDomains, domain) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrNotAllowed, identity.User())
+}
+
+// githubOrgs lists the logins of the GitHub organizations the user of an access token is a member of
+func (p *Provider) githubOrgs(ctx context.Context, accessToken string) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(p.opts.GitHubAPIURL, "/")+"/user/orgs?per_page=100", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	var orgs []struct {
+		Login string `json:"login"`
+	}
+	if err := p.do(req, &orgs); err != nil {
+		return nil, fmt.Errorf("failed to list GitHub organizations: %w", err)
+	}
+	logins := make([]string, 0, len(orgs))
+	for _, org := range orgs {
+		logins = append(logins, org.Login)
+	}
+	return logins, nil
+}
+
+// post sends a form and decodes the JSON reply, which may describe an OAuth error
+func (p *Provider) post(ctx context.Context, endpoint string, form url.Values, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return p.do(req, out)
+}
+
+// do sends a request and decodes its JSON reply. Error replies are decoded too, since
+// OAuth reports pending logins as 400 responses with an error code.
+func (p *Provider) do(req *http.Request, out interface{}) error {
+	req.Header.Set("Accept", "application/json")
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	decodeErr := json.Unmarshal(body, out)
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s returned %s", req.URL.Host, resp.Status)
+	}
+	return decodeErr
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
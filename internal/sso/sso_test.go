@@ -0,0 +1,164 @@
+package sso
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOIDCDeviceLogin(t *testing.T) {
+	var issuer string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                        issuer,
+			"device_authorization_endpoint": issuer + "/device",
+			"token_endpoint":                issuer + "/token",
+		})
+	})
+	mux.HandleFunc("/device", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(DeviceCode{DeviceCode: "device-1", UserCode: "ABCD-EFGH", VerificationURI: issuer + "/activate", ExpiresIn: 60, Interval: 1})
+	})
+	polls := 0
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Form.Get("device_code") != "device-1" {
+			t.Errorf("token request form = %v", r.Form)
+		}
+		if polls++; polls == 1 {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "authorization_pending"})
+			return
+		}
+		claims, _ := json.Marshal(map[string]interface{}{
+			"iss": issuer, "sub": "42", "aud": "ghrepos", "exp": time.Now().Add(time.Hour).Unix(), "email": "alice@example.com",
+		})
+		json.NewEncoder(w).Encode(map[string]string{"id_token": "e30." + base64.RawURLEncoding.EncodeToString(claims) + ".sig"})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	issuer = server.URL
+
+	ctx := context.Background()
+	provider, err := NewProvider(ctx, Options{Provider: ProviderOIDC, Issuer: issuer, ClientID: "ghrepos"})
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+	code, err := provider.StartLogin(ctx)
+	if err != nil || code.UserCode != "ABCD-EFGH" {
+		t.Fatalf("StartLogin() = %+v, %v", code, err)
+	}
+	identity, err := provider.CompleteLogin(ctx, code)
+	if err != nil {
+		t.Fatalf("CompleteLogin() error = %v", err)
+	}
+	if identity.User() != "alice@example.com" || identity.Subject != "42" || polls != 2 {
+		t.Errorf("identity = %+v after %d polls, want alice after 2 polls", identity, polls)
+	}
+
+	// Logins restricted to domains reject users of other domains
+	provider.opts.AllowedDomains = []string{"example.org"}
+	polls = 1
+	if _, err := provider.CompleteLogin(ctx, code); !errors.Is(err, ErrNotAllowed) {
+		t.Errorf("CompleteLogin() outside the allowed domains error = %v, want ErrNotAllowed", err)
+	}
+	provider.opts.AllowedDomains = []string{"Example.com"}
+	polls = 1
+	if _, err := provider.CompleteLogin(ctx, code); err != nil {
+		t.Errorf("CompleteLogin() in an allowed domain error = %v", err)
+	}
+	provider.opts.AllowedDomains = nil
+
+	// ID tokens for another client are rejected
+	provider.opts.ClientID = "other"
+	polls = 1
+	if _, err := provider.CompleteLogin(ctx, code); err == nil {
+		t.Error("CompleteLogin() accepted an ID token issued to another client")
+	}
+}
+
+func TestGitHubAllowedLogins(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login/device/code", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if scope := r.Form.Get("scope"); !strings.Contains(scope, "read:org") {
+			t.Errorf("device code scope = %q, want read:org", scope)
+		}
+		json.NewEncoder(w).Encode(DeviceCode{DeviceCode: "device-1", UserCode: "ABCD-EFGH", ExpiresIn: 60})
+	})
+	mux.HandleFunc("/login/oauth/access_token", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"access_token": "gho_alice"})
+	})
+	mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": 1, "login": "alice"})
+	})
+	mux.HandleFunc("/user/orgs", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer gho_alice" {
+			t.Errorf("organizations listed with %q", r.Header.Get("Authorization"))
+		}
+		json.NewEncoder(w).Encode([]map[string]string{{"login": "pingcap"}})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	ctx := context.Background()
+	tests := []struct {
+		name  string
+		users []string
+		orgs  []string
+		allow bool
+	}{
+		{"unrestricted", nil, nil, true},
+		{"allowed user", []string{"Alice"}, nil, true},
+		{"other user", []string{"bob"}, nil, false},
+		{"member of an allowed organization", nil, []string{"tikv", "PingCAP"}, true},
+		{"member of no allowed organization", []string{"bob"}, []string{"tikv"}, false},
+	}
+	for _, tt := range tests {
+		provider, err := NewProvider(ctx, Options{Provider: ProviderGitHub, ClientID: "ghrepos", GitHubURL: server.URL, GitHubAPIURL: server.URL,
+			AllowedUsers: tt.users, AllowedOrgs: tt.orgs})
+		if err != nil {
+			t.Fatalf("NewProvider() error = %v", err)
+		}
+		if len(tt.orgs) > 0 {
+			if _, err := provider.StartLogin(ctx); err != nil {
+				t.Fatalf("StartLogin() error = %v", err)
+			}
+		}
+		identity, err := provider.CompleteLogin(ctx, &DeviceCode{DeviceCode: "device-1", ExpiresIn: 60})
+		switch {
+		case tt.allow && (err != nil || identity.Name != "alice"):
+			t.Errorf("%s: CompleteLogin() = %+v, %v, want alice", tt.name, identity, err)
+		case !tt.allow && !errors.Is(err, ErrNotAllowed):
+			t.Errorf("%s: CompleteLogin() error = %v, want ErrNotAllowed", tt.name, err)
+		}
+	}
+}
+
+func TestSession(t *testing.T) {
+	secret := []byte("secret")
+	token, _, err := IssueSession(secret, &Identity{Provider: ProviderGitHub, Subject: "1", Name: "octocat"}, time.Hour)
+	if err != nil {
+		t.Fatalf("IssueSession() error = %v", err)
+	}
+
+	session, err := VerifySession(secret, token)
+	if err != nil || session.User() != "octocat" {
+		t.Fatalf("VerifySession() = %+v, %v, want octocat", session, err)
+	}
+	if _, err := VerifySession([]byte("other"), token); !errors.Is(err, ErrInvalidSession) {
+		t.Errorf("VerifySession() with another secret error = %v, want ErrInvalidSession", err)
+	}
+
+	expired, _, _ := IssueSession(secret, &Identity{Subject: "1"}, time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	if _, err := VerifySession(secret, expired); !errors.Is(err, ErrSessionExpired) {
+		t.Errorf("VerifySession() of an expired session error = %v, want ErrSessionExpired", err)
+	}
+}
@@ -0,0 +1,135 @@
+// Package tracing sets up the OpenTelemetry SDK to record spans of HTTP requests, syncs, storage
+// operations and gh calls, and export them in batches to an OTLP/HTTP collector, so that slow
+// syncs and queries can be followed end to end.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DefaultServiceName is the service.name of the exported spans when none is configured
+const DefaultServiceName = "ghrepos"
+
+// scopeName is the instrumentation scope of the recorded spans
+const scopeName = "github.com/siddontang/github-repos-management"
+
+// propagator carries the caller's span in W3C traceparent and tracestate headers, and baggage
+var propagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+
+// Tracer starts spans and exports them. A nil tracer starts spans like Start.
+type Tracer struct {
+	provider *sdktrace.TracerProvider
+	tracer   trace.Tracer
+}
+
+// New creates a tracer exporting to the configured collector, or returns nil when tracing is not
+// configured. It is registered as the global tracer provider and propagator, so third-party
+// instrumentation records into the same traces. errors reports failed exports.
+func New(cfg config.TracingConfig, errors func(error)) (*Tracer, error) {
+	if cfg.Endpoint == "" {
+		return nil, nil
+	}
+	endpoint, err := url.Parse(cfg.Endpoint)
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid tracing endpoint %q, expected a URL such as http://localhost:4318", cfg.Endpoint)
+	}
+	exporter, err := otlptracehttp.New(context.Background(),
+		otlptracehttp.WithEndpointURL(strings.TrimSuffix(cfg.Endpoint, "/")+"/v1/traces"),
+		otlptracehttp.WithHeaders(cfg.Headers))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+
+	t := newTracer(exporter, cfg)
+	otel.SetTracerProvider(t.provider)
+	otel.SetTextMapPropagator(propagator)
+	if errors != nil {
+		otel.SetErrorHandler(otel.ErrorHandlerFunc(errors))
+	}
+	return t, nil
+}
+
+// newTracer creates a tracer exporting to exporter in the background until it is shut down
+func newTracer(exporter sdktrace.SpanExporter, cfg config.TracingConfig) *Tracer {
+	serviceName := cfg.ServiceName
+	if serviceName == "" {
+		serviceName = DefaultServiceName
+	}
+	sampleRatio := cfg.SampleRatio
+	if sampleRatio <= 0 || sampleRatio > 1 {
+		sampleRatio = 1
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(serviceName))),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio))))
+	return &Tracer{provider: provider, tracer: provider.Tracer(scopeName)}
+}
+
+// Start starts a span: a child of the current span of ctx, of the caller's span extracted into
+// ctx, or the root of a new trace, sampled at the configured ratio
+func (t *Tracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	if t == nil {
+		return Start(ctx, name, opts...)
+	}
+	return t.tracer.Start(ctx, name, opts...)
+}
+
+// Shutdown exports the spans still queued and stops the export. Spans ended afterwards are
+// dropped.
+func (t *Tracer) Shutdown(ctx context.Context) error {
+	if t == nil {
+		return nil
+	}
+	return t.provider.Shutdown(ctx)
+}
+
+// Start starts a child of the current span of ctx. Outside a recorded trace nothing is traced and
+// the span returned ignores every call, so storage operations and gh calls are only recorded
+// within a trace.
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	parent := trace.SpanFromContext(ctx)
+	if !parent.IsRecording() {
+		return ctx, parent
+	}
+	return parent.TracerProvider().Tracer(scopeName).Start(ctx, name, opts...)
+}
+
+// Recording reports whether ctx is within a recorded trace
+func Recording(ctx context.Context) bool {
+	return trace.SpanFromContext(ctx).IsRecording()
+}
+
+// RecordError marks a span as failed with err. A nil err is ignored.
+func RecordError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// Extract returns ctx with the caller's span of the traceparent header, which spans started by a
+// tracer then continue. A missing or malformed header leaves ctx as is.
+func Extract(ctx context.Context, header http.Header) context.Context {
+	return propagator.Extract(ctx, propagation.HeaderCarrier(header))
+}
+
+// Inject sets the traceparent header to the current span of ctx, if any
+func Inject(ctx context.Context, header http.Header) {
+	propagator.Inject(ctx, propagation.HeaderCarrier(header))
+}
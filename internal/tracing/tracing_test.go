@@ -0,0 +1,130 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracer(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tracer := newTracer(exporter, config.TracingConfig{})
+
+	ctx, root := tracer.Start(context.Background(), "sync", trace.WithAttributes(attribute.String("github.repository", "org/api")))
+	_, child := Start(ctx, "db.GetRepository")
+	RecordError(child, errors.New("not found"))
+	child.End()
+	RecordError(root, nil)
+	root.SetAttributes(attribute.Int("items", 3))
+	root.End()
+
+	if err := tracer.provider.ForceFlush(context.Background()); err != nil {
+		t.Fatalf("ForceFlush() error = %v", err)
+	}
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("exported %d spans, want 2", len(spans))
+	}
+	getSpan, syncSpan := spans[0], spans[1]
+	if getSpan.Name != "db.GetRepository" || getSpan.Parent.SpanID() != syncSpan.SpanContext.SpanID() || syncSpan.Parent.IsValid() {
+		t.Errorf("spans = %s, %s; want db.GetRepository a child of sync", getSpan.Name, syncSpan.Name)
+	}
+	if getSpan.Status.Code != codes.Error || getSpan.Status.Description != "not found" || syncSpan.Status.Code != codes.Unset {
+		t.Errorf("statuses = %+v, %+v", getSpan.Status, syncSpan.Status)
+	}
+	if len(syncSpan.Attributes) != 2 || syncSpan.Attributes[1].Value.AsInt64() != 3 {
+		t.Errorf("attributes = %v", syncSpan.Attributes)
+	}
+	if service, _ := syncSpan.Resource.Set().Value("service.name"); service.AsString() != DefaultServiceName {
+		t.Errorf("service.name = %q, want %q", service.AsString(), DefaultServiceName)
+	}
+	if err := tracer.Shutdown(context.Background()); err != nil {
+		t.Errorf("Shutdown() error = %v", err)
+	}
+}
+
+func TestNew(t *testing.T) {
+	if tracer, err := New(config.TracingConfig{}, nil); tracer != nil || err != nil {
+		t.Errorf("New() without an endpoint = %v, %v; want nil", tracer, err)
+	}
+	if _, err := New(config.TracingConfig{Endpoint: "localhost"}, nil); err == nil {
+		t.Error("New() with an endpoint without scheme should fail")
+	}
+
+	// Spans are posted to the collector's /v1/traces with the configured headers
+	var mu sync.Mutex
+	var path, apiKey string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		path, apiKey = r.URL.Path, r.Header.Get("X-Api-Key")
+	}))
+	defer server.Close()
+	tracer, err := New(config.TracingConfig{Endpoint: server.URL + "/", Headers: map[string]string{"X-Api-Key": "s3cr3t"}}, nil)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	_, span := tracer.Start(context.Background(), "sync")
+	span.End()
+	if err := tracer.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if path != "/v1/traces" || apiKey != "s3cr3t" {
+		t.Errorf("collector got a request to %q with X-Api-Key %q", path, apiKey)
+	}
+}
+
+func TestStartWithoutTrace(t *testing.T) {
+	// Outside a trace, and with a nil tracer, spans are not recorded and ignore every call
+	var tracer *Tracer
+	ctx, span := tracer.Start(context.Background(), "sync")
+	if span.IsRecording() || Recording(ctx) {
+		t.Fatal("Start() outside a trace should not record a span")
+	}
+	span.SetAttributes(attribute.String("k", "v"))
+	RecordError(span, errors.New("failed"))
+	span.End()
+	if err := tracer.Shutdown(context.Background()); err != nil {
+		t.Errorf("Shutdown() error = %v", err)
+	}
+}
+
+func TestPropagation(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tracer := newTracer(exporter, config.TracingConfig{SampleRatio: 0.5})
+	defer tracer.Shutdown(context.Background())
+
+	header := http.Header{}
+	header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx, span := tracer.Start(Extract(context.Background(), header), "GET /api/v1/repositories")
+	if got := span.SpanContext().TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("trace ID = %s, want the caller's", got)
+	}
+	out := http.Header{}
+	Inject(ctx, out)
+	if want := "00-4bf92f3577b34da6a3ce929d0e0e4736-" + span.SpanContext().SpanID().String() + "-01"; out.Get("traceparent") != want {
+		t.Errorf("traceparent = %q, want %q", out.Get("traceparent"), want)
+	}
+	span.End()
+	tracer.provider.ForceFlush(context.Background())
+	if spans := exporter.GetSpans(); len(spans) != 1 || spans[0].Parent.SpanID().String() != "00f067aa0ba902b7" {
+		t.Errorf("spans = %v, want a child of the caller's span", spans)
+	}
+
+	// The caller's sampling decision is kept
+	header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	if ctx, span := tracer.Start(Extract(context.Background(), header), "unsampled"); span.IsRecording() || Recording(ctx) {
+		t.Error("Start() continuing an unsampled trace should not record a span")
+	}
+}
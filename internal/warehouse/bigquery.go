@@ -0,0 +1,96 @@
+package warehouse
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+)
+
+// bigQueryTypes maps column types to BigQuery types
+var bigQueryTypes = map[string]string{
+	TypeString:    "STRING",
+	TypeInteger:   "INT64",
+	TypeBoolean:   "BOOL",
+	TypeTimestamp: "TIMESTAMP",
+	TypeStrings:   "STRING",
+}
+
+// BigQuerySink loads tables into a BigQuery dataset with bq load, replacing them
+type BigQuerySink struct {
+	dataset string
+	command string
+}
+
+// Ensure BigQuerySink implements Sink
+var _ Sink = (*BigQuerySink)(nil)
+
+// NewBigQuerySink creates a sink loading into a BigQuery dataset
+func NewBigQuerySink(cfg config.BigQueryConfig) *BigQuerySink {
+	command := cfg.Command
+	if command == "" {
+		command = "bq"
+	}
+	return &BigQuerySink{dataset: cfg.Dataset, command: command}
+}
+
+// bigQueryField is a field of a BigQuery JSON schema
+type bigQueryField struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	Mode string `json:"mode"`
+}
+
+// Replace writes the rows as newline-delimited JSON and the schema to temporary files and loads
+// them with bq load --replace
+func (s *BigQuerySink) Replace(ctx context.Context, table *Table) error {
+	dir, err := os.MkdirTemp("", "ghrepos-bq-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	fields := make([]bigQueryField, 0, len(table.Columns))
+	for _, column := range table.Columns {
+		mode := "NULLABLE"
+		if column.Type == TypeStrings {
+			mode = "REPEATED"
+		}
+		fields = append(fields, bigQueryField{Name: column.Name, Type: bigQueryTypes[column.Type], Mode: mode})
+	}
+	schema, err := json.Marshal(fields)
+	if err != nil {
+		return fmt.Errorf("failed to encode schema: %w", err)
+	}
+	schemaPath := filepath.Join(dir, "schema.json")
+	if err := os.WriteFile(schemaPath, schema, 0600); err != nil {
+		return fmt.Errorf("failed to write schema: %w", err)
+	}
+
+	var data bytes.Buffer
+	encoder := json.NewEncoder(&data)
+	for _, row := range table.Rows {
+		if err := encoder.Encode(table.record(row)); err != nil {
+			return fmt.Errorf("failed to encode %s rows: %w", table.Name, err)
+		}
+	}
+	dataPath := filepath.Join(dir, "data.json")
+	if err := os.WriteFile(dataPath, data.Bytes(), 0600); err != nil {
+		return fmt.Errorf("failed to write %s rows: %w", table.Name, err)
+	}
+
+	cmd := exec.CommandContext(ctx, s.command, "load", "--replace", "--source_format=NEWLINE_DELIMITED_JSON",
+		s.dataset+"."+table.Name, dataPath, schemaPath)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("bq load of %s failed: %w, stderr: %s", table.Name, err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
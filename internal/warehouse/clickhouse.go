@@ -0,0 +1,121 @@
+package warehouse
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+)
+
+// clickHouseTypes maps column types to ClickHouse types
+var clickHouseTypes = map[string]string{
+	TypeString:    "String",
+	TypeInteger:   "Int64",
+	TypeBoolean:   "Bool",
+	TypeTimestamp: "Nullable(DateTime64(0, 'UTC'))",
+	TypeStrings:   "Array(String)",
+}
+
+// ClickHouseSink loads tables into a ClickHouse database over its HTTP interface
+type ClickHouseSink struct {
+	url        string
+	database   string
+	user       string
+	password   string
+	httpClient *http.Client
+}
+
+// Ensure ClickHouseSink implements Sink
+var _ Sink = (*ClickHouseSink)(nil)
+
+// NewClickHouseSink creates a sink loading into a ClickHouse database
+func NewClickHouseSink(cfg config.ClickHouseConfig) *ClickHouseSink {
+	database := cfg.Database
+	if database == "" {
+		database = "default"
+	}
+	return &ClickHouseSink{
+		url:        strings.TrimSuffix(cfg.URL, "/"),
+		database:   database,
+		user:       cfg.User,
+		password:   cfg.Password,
+		httpClient: &http.Client{Timeout: 5 * time.Minute},
+	}
+}
+
+// Replace loads the rows into a staging table, then swaps it with the table, so that readers
+// never see it partly loaded
+func (s *ClickHouseSink) Replace(ctx context.Context, table *Table) error {
+	name := quoteIdentifier(s.database) + "." + quoteIdentifier(table.Name)
+	staging := quoteIdentifier(s.database) + "." + quoteIdentifier(table.Name+"_staging")
+
+	columns := make([]string, 0, len(table.Columns))
+	for _, column := range table.Columns {
+		columns = append(columns, quoteIdentifier(column.Name)+" "+clickHouseTypes[column.Type])
+	}
+	schema := "(" + strings.Join(columns, ", ") + ") ENGINE = MergeTree ORDER BY tuple()"
+
+	var data bytes.Buffer
+	encoder := json.NewEncoder(&data)
+	for _, row := range table.Rows {
+		if err := encoder.Encode(table.record(row)); err != nil {
+			return fmt.Errorf("failed to encode %s rows: %w", table.Name, err)
+		}
+	}
+
+	for _, statement := range []struct {
+		query string
+		body  io.Reader
+	}{
+		{query: "CREATE TABLE IF NOT EXISTS " + name + " " + schema},
+		{query: "DROP TABLE IF EXISTS " + staging},
+		{query: "CREATE TABLE " + staging + " " + schema},
+		{query: "INSERT INTO " + staging + " FORMAT JSONEachRow", body: &data},
+		{query: "EXCHANGE TABLES " + staging + " AND " + name},
+		{query: "DROP TABLE " + staging},
+	} {
+		if err := s.exec(ctx, statement.query, statement.body); err != nil {
+			return fmt.Errorf("failed to load %s: %w", table.Name, err)
+		}
+	}
+	return nil
+}
+
+// exec runs a statement, sending body after it as its data
+func (s *ClickHouseSink) exec(ctx context.Context, query string, body io.Reader) error {
+	params := url.Values{"query": {query}, "date_time_input_format": {"best_effort"}}
+	if body == nil {
+		body = http.NoBody
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url+"/?"+params.Encode(), body)
+	if err != nil {
+		return fmt.Errorf("failed to create clickhouse request: %w", err)
+	}
+	if s.user != "" {
+		req.Header.Set("X-ClickHouse-User", s.user)
+		req.Header.Set("X-ClickHouse-Key", s.password)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("clickhouse request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("clickhouse returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
+
+// quoteIdentifier quotes a database, table or column name
+func quoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "\\`") + "`"
+}
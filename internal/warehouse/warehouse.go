@@ -0,0 +1,159 @@
+// Package warehouse pushes the stored repositories, pull requests and issues to a data warehouse,
+// BigQuery or ClickHouse, as tables replaced on each push.
+package warehouse
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+// ErrUnknownKind is returned for warehouse kinds other than bigquery and clickhouse
+var ErrUnknownKind = errors.New("unknown warehouse kind, expected bigquery or clickhouse")
+
+// Column types, mapped to the types of each warehouse
+const (
+	TypeString    = "string"
+	TypeInteger   = "integer"
+	TypeBoolean   = "boolean"
+	TypeTimestamp = "timestamp" // Nullable
+	TypeStrings   = "strings"   // A repeated string
+)
+
+// Column is a column of a table
+type Column struct {
+	Name string
+	Type string
+}
+
+// Table is a table to push: its schema and rows, each holding a value per column in order
+type Table struct {
+	Name    string
+	Columns []Column
+	Rows    [][]interface{}
+}
+
+// record returns a row as a JSON object keyed by column name, with timestamps in RFC3339
+func (t *Table) record(row []interface{}) map[string]interface{} {
+	record := make(map[string]interface{}, len(t.Columns))
+	for i, column := range t.Columns {
+		value := row[i]
+		switch v := value.(type) {
+		case time.Time:
+			if v.IsZero() {
+				value = nil
+			} else {
+				value = v.UTC().Format(time.RFC3339)
+			}
+		case *time.Time:
+			if v == nil {
+				value = nil
+			} else {
+				value = v.UTC().Format(time.RFC3339)
+			}
+		case []string:
+			if v == nil {
+				value = []string{}
+			}
+		}
+		record[column.Name] = value
+	}
+	return record
+}
+
+// Sink receives tables, replacing their previous contents
+type Sink interface {
+	Replace(ctx context.Context, table *Table) error
+}
+
+// New returns the sink of the configured warehouse
+func New(cfg config.WarehouseConfig) (Sink, error) {
+	switch cfg.Kind {
+	case config.WarehouseBigQuery:
+		if cfg.BigQuery.Dataset == "" {
+			return nil, errors.New("warehouse.bigquery.dataset is required")
+		}
+		return NewBigQuerySink(cfg.BigQuery), nil
+	case config.WarehouseClickHouse:
+		if cfg.ClickHouse.URL == "" {
+			return nil, errors.New("warehouse.clickhouse.url is required")
+		}
+		return NewClickHouseSink(cfg.ClickHouse), nil
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownKind, cfg.Kind)
+}
+
+// Labels returns the names of the labels of an item
+type Labels func(fullName string, number int) []string
+
+// RepositoryTable maps repositories to the repositories table
+func RepositoryTable(name string, repos []*models.Repository) *Table {
+	table := &Table{
+		Name: name,
+		Columns: []Column{
+			{"full_name", TypeString}, {"owner", TypeString}, {"name", TypeString}, {"description", TypeString},
+			{"html_url", TypeString}, {"private", TypeBoolean}, {"paused", TypeBoolean}, {"tags", TypeStrings},
+			{"created_at", TypeTimestamp}, {"updated_at", TypeTimestamp}, {"last_synced_at", TypeTimestamp},
+		},
+	}
+	for _, repo := range repos {
+		table.Rows = append(table.Rows, []interface{}{
+			repo.FullName, repo.Owner, repo.Name, repo.Description,
+			repo.HTMLURL, repo.IsPrivate, repo.Paused, repo.Tags,
+			repo.CreatedAt, repo.UpdatedAt, repo.LastSyncedAt,
+		})
+	}
+	return table
+}
+
+// PullRequestTable maps pull requests to the pull requests table
+func PullRequestTable(name string, prs []*models.PullRequest, labels Labels) *Table {
+	table := &Table{
+		Name: name,
+		Columns: []Column{
+			{"repository", TypeString}, {"number", TypeInteger}, {"title", TypeString}, {"state", TypeString},
+			{"draft", TypeBoolean}, {"author", TypeString}, {"author_is_bot", TypeBoolean}, {"author_association", TypeString},
+			{"assignees", TypeStrings}, {"requested_reviewers", TypeStrings}, {"requested_teams", TypeStrings}, {"labels", TypeStrings},
+			{"milestone", TypeString}, {"review_decision", TypeString}, {"additions", TypeInteger}, {"deletions", TypeInteger},
+			{"changed_files", TypeInteger}, {"html_url", TypeString}, {"created_at", TypeTimestamp}, {"updated_at", TypeTimestamp},
+			{"closed_at", TypeTimestamp}, {"merged_at", TypeTimestamp}, {"first_review_at", TypeTimestamp},
+		},
+	}
+	for _, pr := range prs {
+		table.Rows = append(table.Rows, []interface{}{
+			pr.RepositoryFullName, pr.Number, pr.Title, pr.State,
+			pr.Draft, pr.UserLogin, pr.UserIsBot, pr.AuthorAssociation,
+			pr.Assignees, pr.RequestedReviewers, pr.RequestedTeams, labels(pr.RepositoryFullName, pr.Number),
+			pr.Milestone, pr.ReviewDecision, pr.Additions, pr.Deletions,
+			pr.ChangedFiles, pr.HTMLURL, pr.CreatedAt, pr.UpdatedAt,
+			pr.ClosedAt, pr.MergedAt, pr.FirstReviewAt,
+		})
+	}
+	return table
+}
+
+// IssueTable maps issues to the issues table
+func IssueTable(name string, issues []*models.Issue, labels Labels) *Table {
+	table := &Table{
+		Name: name,
+		Columns: []Column{
+			{"repository", TypeString}, {"number", TypeInteger}, {"title", TypeString}, {"state", TypeString},
+			{"author", TypeString}, {"author_is_bot", TypeBoolean}, {"author_association", TypeString}, {"assignees", TypeStrings},
+			{"labels", TypeStrings}, {"milestone", TypeString}, {"html_url", TypeString}, {"created_at", TypeTimestamp},
+			{"updated_at", TypeTimestamp}, {"closed_at", TypeTimestamp},
+		},
+	}
+	for _, issue := range issues {
+		table.Rows = append(table.Rows, []interface{}{
+			issue.RepositoryFullName, issue.Number, issue.Title, issue.State,
+			issue.UserLogin, issue.UserIsBot, issue.AuthorAssociation, issue.Assignees,
+			labels(issue.RepositoryFullName, issue.Number), issue.Milestone, issue.HTMLURL, issue.CreatedAt,
+			issue.UpdatedAt, issue.ClosedAt,
+		})
+	}
+	return table
+}
@@ -0,0 +1,129 @@
+package warehouse
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/models"
+)
+
+func TestNew(t *testing.T) {
+	if _, err := New(config.WarehouseConfig{Kind: "redshift"}); !errors.Is(err, ErrUnknownKind) {
+		t.Errorf("New(redshift) error = %v, want ErrUnknownKind", err)
+	}
+	if _, err := New(config.WarehouseConfig{Kind: config.WarehouseBigQuery}); err == nil {
+		t.Error("New(bigquery) without a dataset should fail")
+	}
+	if sink, err := New(config.WarehouseConfig{Kind: config.WarehouseClickHouse, ClickHouse: config.ClickHouseConfig{URL: "http://localhost:8123"}}); err != nil {
+		t.Errorf("New(clickhouse) error = %v", err)
+	} else if _, ok := sink.(*ClickHouseSink); !ok {
+		t.Errorf("New(clickhouse) = %T", sink)
+	}
+}
+
+func TestPullRequestTable(t *testing.T) {
+	merged := time.Date(2024, 5, 2, 12, 0, 0, 0, time.FixedZone("CEST", 2*3600))
+	table := PullRequestTable("pull_requests", []*models.PullRequest{
+		{RepositoryFullName: "org/api", Number: 7, Title: "Fix", State: "MERGED", UserLogin: "alice", MergedAt: &merged},
+	}, func(fullName string, number int) []string { return []string{fullName + "#bug"} })
+
+	if len(table.Rows) != 1 || len(table.Rows[0]) != len(table.Columns) {
+		t.Fatalf("table = %d rows of %d values, want 1 of %d", len(table.Rows), len(table.Rows[0]), len(table.Columns))
+	}
+	record := table.record(table.Rows[0])
+	if record["number"] != 7 || record["author"] != "alice" || record["merged_at"] != "2024-05-02T10:00:00Z" {
+		t.Errorf("record = %v", record)
+	}
+	if record["created_at"] != nil || record["closed_at"] != nil {
+		t.Errorf("unset timestamps = %v, %v; want null", record["created_at"], record["closed_at"])
+	}
+	if assignees, ok := record["assignees"].([]string); !ok || assignees == nil {
+		t.Errorf("assignees = %#v, want an empty array", record["assignees"])
+	}
+	if labels := record["labels"].([]string); len(labels) != 1 || labels[0] != "org/api#bug" {
+		t.Errorf("labels = %v", labels)
+	}
+}
+
+func TestClickHouseSink(t *testing.T) {
+	var mu sync.Mutex
+	var queries []string
+	var inserted string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.Header.Get("X-ClickHouse-User") != "ghrepos" || r.Header.Get("X-ClickHouse-Key") != "s3cr3t" {
+			http.Error(w, "authentication failed", http.StatusForbidden)
+			return
+		}
+		query := r.URL.Query().Get("query")
+		queries = append(queries, query)
+		if strings.HasPrefix(query, "INSERT") {
+			body, _ := io.ReadAll(r.Body)
+			inserted = string(body)
+		}
+	}))
+	defer server.Close()
+
+	sink := NewClickHouseSink(config.ClickHouseConfig{URL: server.URL, Database: "gh", User: "ghrepos", Password: "s3cr3t"})
+	table := RepositoryTable("repositories", []*models.Repository{{FullName: "org/api", Owner: "org", Name: "api", Tags: []string{"core"}}})
+	if err := sink.Replace(context.Background(), table); err != nil {
+		t.Fatalf("Replace() error = %v", err)
+	}
+
+	if len(queries) != 6 || !strings.HasPrefix(queries[0], "CREATE TABLE IF NOT EXISTS `gh`.`repositories` (`full_name` String") ||
+		queries[4] != "EXCHANGE TABLES `gh`.`repositories_staging` AND `gh`.`repositories`" {
+		t.Errorf("queries = %q", queries)
+	}
+	var row map[string]interface{}
+	if err := json.Unmarshal([]byte(inserted), &row); err != nil || row["full_name"] != "org/api" {
+		t.Errorf("inserted = %s, %v", inserted, err)
+	}
+
+	sink = NewClickHouseSink(config.ClickHouseConfig{URL: server.URL})
+	if err := sink.Replace(context.Background(), table); err == nil || !strings.Contains(err.Error(), "status 403") {
+		t.Errorf("Replace() without credentials error = %v, want the status", err)
+	}
+}
+
+func TestBigQuerySink(t *testing.T) {
+	// A fake bq saving its arguments and the files it was given
+	dir := t.TempDir()
+	script := filepath.Join(dir, "bq")
+	out := filepath.Join(dir, "out")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\necho \"$@\" > "+out+"\ncat \"$5\" \"$6\" >> "+out+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	sink := NewBigQuerySink(config.BigQueryConfig{Dataset: "proj:gh", Command: script})
+	table := IssueTable("ghrepos_issues", []*models.Issue{{RepositoryFullName: "org/api", Number: 3, State: "OPEN"}},
+		func(string, int) []string { return nil })
+	if err := sink.Replace(context.Background(), table); err != nil {
+		t.Fatalf("Replace() error = %v", err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(data)
+	if !strings.HasPrefix(got, "load --replace --source_format=NEWLINE_DELIMITED_JSON proj:gh.ghrepos_issues ") ||
+		!strings.Contains(got, `"repository":"org/api"`) || !strings.Contains(got, `{"name":"labels","type":"STRING","mode":"REPEATED"}`) {
+		t.Errorf("bq got %s", got)
+	}
+
+	sink = NewBigQuerySink(config.BigQueryConfig{Dataset: "proj:gh", Command: filepath.Join(dir, "missing")})
+	if err := sink.Replace(context.Background(), table); err == nil {
+		t.Error("Replace() with a missing bq should fail")
+	}
+}
@@ -0,0 +1,173 @@
+// Package workhours measures time in working hours: the work hours of working days in a time
+// zone, skipping weekends and holidays. A nil calendar measures wall-clock time.
+package workhours
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+)
+
+// ErrInvalidCalendar is returned for malformed calendar configurations
+var ErrInvalidCalendar = errors.New("invalid business hours")
+
+// dateLayout is the layout of holidays
+const dateLayout = "2006-01-02"
+
+// Defaults of enabled calendars
+var (
+	defaultWorkHours = "09:00-17:00"
+	defaultWeekends  = []string{"saturday", "sunday"}
+)
+
+// Calendar measures durations in working hours
+type Calendar struct {
+	loc      *time.Location
+	start    time.Duration // Start of the work hours, from midnight
+	end      time.Duration // End of the work hours, from midnight
+	weekends map[time.Weekday]bool
+	holidays map[string]bool // By date, YYYY-MM-DD
+}
+
+// New creates the calendar of a configuration, or nil when it isn't enabled
+func New(cfg *config.BusinessHoursConfig) (*Calendar, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	c := &Calendar{loc: time.UTC, weekends: make(map[time.Weekday]bool), holidays: make(map[string]bool)}
+	if cfg.Timezone != "" {
+		loc, err := time.LoadLocation(cfg.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unknown timezone %q", ErrInvalidCalendar, cfg.Timezone)
+		}
+		c.loc = loc
+	}
+
+	workHours := cfg.WorkHours
+	if workHours == "" {
+		workHours = defaultWorkHours
+	}
+	from, to, ok := strings.Cut(workHours, "-")
+	start, startErr := parseClock(from)
+	end, endErr := parseClock(to)
+	if !ok || startErr != nil || endErr != nil || end <= start {
+		return nil, fmt.Errorf("%w: work hours must be HH:MM-HH:MM, got %q", ErrInvalidCalendar, workHours)
+	}
+	c.start, c.end = start, end
+
+	weekends := cfg.Weekends
+	if weekends == nil {
+		weekends = defaultWeekends
+	}
+	for _, name := range weekends {
+		day, ok := parseWeekday(name)
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown weekday %q", ErrInvalidCalendar, name)
+		}
+		c.weekends[day] = true
+	}
+	if len(c.weekends) == 7 {
+		return nil, fmt.Errorf("%w: every day is a weekend", ErrInvalidCalendar)
+	}
+
+	for _, date := range cfg.Holidays {
+		if _, err := time.Parse(dateLayout, date); err != nil {
+			return nil, fmt.Errorf("%w: holidays must be YYYY-MM-DD, got %q", ErrInvalidCalendar, date)
+		}
+		c.holidays[date] = true
+	}
+	return c, nil
+}
+
+// parseClock parses a time of day, HH:MM, into the duration since midnight
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// parseWeekday parses the English name of a weekday, ignoring case
+func parseWeekday(name string) (time.Weekday, bool) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.EqualFold(day.String(), strings.TrimSpace(name)) {
+			return day, true
+		}
+	}
+	return 0, false
+}
+
+// workHours returns the work hours of the day starting at midnight, ok false on days off
+func (c *Calendar) workHours(midnight time.Time) (start, end time.Time, ok bool) {
+	if c.weekends[midnight.Weekday()] || c.holidays[midnight.Format(dateLayout)] {
+		return time.Time{}, time.Time{}, false
+	}
+	y, m, d := midnight.Date()
+	start = time.Date(y, m, d, 0, 0, 0, 0, c.loc).Add(c.start)
+	end = time.Date(y, m, d, 0, 0, 0, 0, c.loc).Add(c.end)
+	return start, end, true
+}
+
+// midnight returns the start of the day of t in the calendar's time zone
+func (c *Calendar) midnight(t time.Time) time.Time {
+	y, m, d := t.In(c.loc).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, c.loc)
+}
+
+// Between returns the working time between two times, 0 when to isn't after from
+func (c *Calendar) Between(from, to time.Time) time.Duration {
+	if c == nil {
+		if to.Before(from) {
+			return 0
+		}
+		return to.Sub(from)
+	}
+
+	var total time.Duration
+	for day := c.midnight(from); day.Before(to); day = day.AddDate(0, 0, 1) {
+		start, end, ok := c.workHours(day)
+		if !ok {
+			continue
+		}
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(to) {
+			end = to
+		}
+		if end.After(start) {
+			total += end.Sub(start)
+		}
+	}
+	return total
+}
+
+// Add returns the time once d of working time has passed since t
+func (c *Calendar) Add(t time.Time, d time.Duration) time.Time {
+	if c == nil {
+		return t.Add(d)
+	}
+	if d <= 0 {
+		return t
+	}
+
+	for day := c.midnight(t); ; day = day.AddDate(0, 0, 1) {
+		start, end, ok := c.workHours(day)
+		if !ok || !end.After(t) {
+			continue
+		}
+		if start.Before(t) {
+			start = t
+		}
+		available := end.Sub(start)
+		if d <= available {
+			return start.Add(d)
+		}
+		d -= available
+	}
+}
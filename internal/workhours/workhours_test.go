@@ -0,0 +1,65 @@
+package workhours
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+)
+
+func TestCalendar(t *testing.T) {
+	c, err := New(&config.BusinessHoursConfig{Enabled: true, Timezone: "Asia/Shanghai", Holidays: []string{"2024-01-03"}})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	loc, _ := time.LoadLocation("Asia/Shanghai")
+	at := func(day, hour, minute int) time.Time { return time.Date(2024, 1, day, hour, minute, 0, 0, loc) }
+
+	// Monday 2024-01-01 to Friday 2024-01-05, with Wednesday off
+	tests := []struct {
+		name     string
+		from, to time.Time
+		want     time.Duration
+	}{
+		{"within a day", at(1, 10, 0), at(1, 12, 30), 150 * time.Minute},
+		{"before work hours", at(1, 6, 0), at(1, 10, 0), time.Hour},
+		{"overnight", at(1, 16, 0), at(2, 10, 0), 2 * time.Hour},
+		{"over a holiday", at(2, 16, 0), at(4, 10, 0), 2 * time.Hour},
+		{"over a weekend", at(5, 16, 0), at(8, 10, 0), 2 * time.Hour},
+		{"backwards", at(2, 10, 0), at(1, 10, 0), 0},
+		{"in another zone", at(1, 9, 0), at(1, 9, 0).UTC().Add(time.Hour), time.Hour},
+	}
+	for _, tt := range tests {
+		if got := c.Between(tt.from, tt.to); got != tt.want {
+			t.Errorf("%s: Between() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	// Two working days from Tuesday afternoon skip the holiday and end on Friday afternoon
+	if got, want := c.Add(at(2, 15, 0), 16*time.Hour), at(5, 15, 0); !got.Equal(want) {
+		t.Errorf("Add() = %v, want %v", got, want)
+	}
+	if got, want := c.Add(at(6, 12, 0), time.Hour), at(8, 10, 0); !got.Equal(want) {
+		t.Errorf("Add() from a weekend = %v, want %v", got, want)
+	}
+
+	// Without a calendar, durations are wall-clock time
+	var wall *Calendar
+	if got := wall.Between(at(1, 16, 0), at(2, 10, 0)); got != 18*time.Hour {
+		t.Errorf("nil Between() = %v, want 18h", got)
+	}
+	if c, err := New(&config.BusinessHoursConfig{}); c != nil || err != nil {
+		t.Errorf("New(disabled) = %v, %v, want nil", c, err)
+	}
+	for _, cfg := range []config.BusinessHoursConfig{
+		{Enabled: true, WorkHours: "17:00-09:00"},
+		{Enabled: true, Weekends: []string{"funday"}},
+		{Enabled: true, Holidays: []string{"01/01/2024"}},
+		{Enabled: true, Timezone: "Mars/Olympus"},
+	} {
+		if _, err := New(&cfg); !errors.Is(err, ErrInvalidCalendar) {
+			t.Errorf("New(%+v) error = %v, want ErrInvalidCalendar", cfg, err)
+		}
+	}
+}
@@ -0,0 +1,276 @@
+// Package ghrepos embeds GitHub repository tracking in other Go programs.
+//
+// A Tracker syncs repositories, pull requests and issues from GitHub into a storage backend
+// and answers queries over them, without running the CLI:
+//
+//	tracker, err := ghrepos.New()
+//	if err != nil {
+//		return err
+//	}
+//	defer tracker.Close()
+//
+//	if _, err := tracker.AddRepository(ctx, "pingcap/tidb"); err != nil {
+//		return err
+//	}
+//	prs, _, err := tracker.ListPullRequests(ctx, &ghrepos.PullRequestFilter{State: "open", Page: 1, PerPage: 20})
+package ghrepos
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/db"
+	_ "github.com/siddontang/github-repos-management/internal/db/file" // Registers the file and memory backends
+	"github.com/siddontang/github-repos-management/internal/github"
+	"github.com/siddontang/github-repos-management/internal/service"
+)
+
+// Option configures a Tracker
+type Option func(*options)
+
+type options struct {
+	config   *Config
+	storage  Storage
+	ghClient GitHubClient
+	logger   *log.Logger
+}
+
+// WithConfig sets the configuration. The storage backend is created from its database
+// section unless WithStorage is given.
+func WithConfig(cfg *Config) Option {
+	return func(o *options) { o.config = cfg }
+}
+
+// WithStorage sets the storage backend
+func WithStorage(storage Storage) Option {
+	return func(o *options) { o.storage = storage }
+}
+
+// WithGitHubClient sets the client used to fetch data from GitHub
+func WithGitHubClient(client GitHubClient) Option {
+	return func(o *options) { o.ghClient = client }
+}
+
+// WithLogger sets the logger for sync progress and errors
+func WithLogger(logger *log.Logger) Option {
+	return func(o *options) { o.logger = logger }
+}
+
+// DefaultConfig returns the default configuration, which keeps data in memory only
+func DefaultConfig() *Config {
+	cfg := config.DefaultConfig()
+	cfg.Database.Type = config.DBTypeMemory
+	return cfg
+}
+
+// LoadConfig loads a configuration file in the format used by the CLI
+func LoadConfig(path string) (*Config, error) {
+	return config.Load(path)
+}
+
+// OpenStorage creates the storage backend of the configured database type
+func OpenStorage(cfg *Config) (Storage, error) {
+	return db.Open(cfg)
+}
+
+// NewGitHubFixtureClient creates a GitHubClient serving the given fixtures, so that code using a
+// Tracker can be tested without gh
+func NewGitHubFixtureClient(fixtures ...*GitHubFixture) *GitHubFixtureClient {
+	return github.NewFixtureClient(fixtures...)
+}
+
+// LoadGitHubFixtures creates a GitHubClient serving the fixtures of every .json file in a directory
+func LoadGitHubFixtures(dir string) (*GitHubFixtureClient, error) {
+	return github.LoadFixtures(dir)
+}
+
+// Tracker tracks GitHub repositories and their pull requests and issues
+type Tracker struct {
+	service *service.Service
+}
+
+// New creates a tracker. Without options it keeps data in memory and fetches it with the gh CLI.
+func New(opts ...Option) (*Tracker, error) {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.config == nil {
+		o.config = DefaultConfig()
+	}
+
+	svc, err := service.NewServiceWithOptions(o.config, service.Options{
+		DB:           o.storage,
+		GitHubClient: o.ghClient,
+		Logger:       o.logger,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Tracker{service: svc}, nil
+}
+
+// Close flushes and closes the storage backend
+func (t *Tracker) Close() error {
+	return t.service.Close()
+}
+
+// AddRepository starts tracking a repository ("owner/name") and syncs it
+func (t *Tracker) AddRepository(ctx context.Context, fullName string) (*Repository, error) {
+	return t.service.AddRepository(ctx, fullName)
+}
+
+// AddRepositories starts tracking several repositories, reporting the outcome for each
+func (t *Tracker) AddRepositories(ctx context.Context, fullNames []string) []*RepositoryResult {
+	return t.service.AddRepositories(ctx, fullNames)
+}
+
+// GetRepository returns a tracked repository
+func (t *Tracker) GetRepository(ctx context.Context, owner, name string) (*Repository, error) {
+	return t.service.GetRepository(ctx, owner, name)
+}
+
+// ListRepositories lists tracked repositories
+func (t *Tracker) ListRepositories(ctx context.Context, filter *RepositoryFilter) ([]*Repository, *Pagination, error) {
+	return t.service.ListRepositories(ctx, filter)
+}
+
+// RemoveRepository stops tracking a repository and deletes its data
+func (t *Tracker) RemoveRepository(ctx context.Context, owner, name string) error {
+	return t.service.DeleteRepository(ctx, owner, name)
+}
+
+// RefreshRepository syncs a repository from GitHub, returning once the sync finished or ctx is done
+func (t *Tracker) RefreshRepository(ctx context.Context, owner, name string) error {
+	return t.service.RefreshRepository(ctx, owner, name)
+}
+
+// StartRefresh syncs a repository in the background, returning the job to poll with Job or WaitJob
+func (t *Tracker) StartRefresh(ctx context.Context, owner, name string) (*Job, error) {
+	return t.service.StartRefresh(ctx, owner, name)
+}
+
+// Job returns the current status of a background job
+func (t *Tracker) Job(ctx context.Context, id int64) (*Job, error) {
+	return t.service.GetJob(ctx, id)
+}
+
+// WaitJob waits until a background job finishes, ctx is done or the timeout elapses (0 waits as long as ctx allows)
+func (t *Tracker) WaitJob(ctx context.Context, id int64, timeout time.Duration) (*Job, error) {
+	return t.service.WaitJob(ctx, id, timeout)
+}
+
+// ListJobs lists background jobs, newest first
+func (t *Tracker) ListJobs(ctx context.Context, filter *JobFilter) ([]*Job, *Pagination, error) {
+	return t.service.ListJobs(ctx, filter)
+}
+
+// CancelJob stops a queued or running background job
+func (t *Tracker) CancelJob(ctx context.Context, id int64) (*Job, error) {
+	return t.service.CancelJob(ctx, id)
+}
+
+// APIUsage reports the GitHub API requests spent on each repository, most expensive first
+func (t *Tracker) APIUsage(ctx context.Context) ([]*APIUsage, error) {
+	return t.service.GetAPIUsage(ctx)
+}
+
+// RefreshAll syncs every tracked repository that is not paused
+func (t *Tracker) RefreshAll(ctx context.Context) error {
+	return t.service.RefreshAll(ctx)
+}
+
+// RefreshDue syncs the repositories whose sync interval has elapsed, returning how many were synced
+func (t *Tracker) RefreshDue(ctx context.Context) (int, error) {
+	return t.service.RefreshDue(ctx)
+}
+
+// ListPullRequests lists pull requests of tracked repositories
+func (t *Tracker) ListPullRequests(ctx context.Context, filter *PullRequestFilter) ([]*PullRequest, *Pagination, error) {
+	return t.service.ListPullRequests(ctx, filter)
+}
+
+// GetPullRequest returns a pull request with its state history
+func (t *Tracker) GetPullRequest(ctx context.Context, owner, name string, number int) (*PullRequest, error) {
+	return t.service.GetPullRequest(ctx, owner, name, number)
+}
+
+// ListIssues lists issues of tracked repositories
+func (t *Tracker) ListIssues(ctx context.Context, filter *IssueFilter) ([]*Issue, *Pagination, error) {
+	return t.service.ListIssues(ctx, filter)
+}
+
+// GetIssue returns an issue with its state history
+func (t *Tracker) GetIssue(ctx context.Context, owner, name string, number int) (*Issue, error) {
+	return t.service.GetIssue(ctx, owner, name, number)
+}
+
+// ListItems lists pull requests and issues together, each tagged with its type
+func (t *Tracker) ListItems(ctx context.Context, filter *ItemFilter) ([]*Item, *Pagination, error) {
+	return t.service.ListItems(ctx, filter)
+}
+
+// ListAudit lists the recorded mutating operations, newest first
+func (t *Tracker) ListAudit(ctx context.Context, filter *AuditFilter) ([]*AuditEntry, *Pagination, error) {
+	return t.service.ListAudit(ctx, filter)
+}
+
+// ListCalendarEvents lists the milestone due dates and releases of the tracked repositories, oldest first
+func (t *Tracker) ListCalendarEvents(ctx context.Context, filter *CalendarFilter) ([]*CalendarEvent, error) {
+	return t.service.ListCalendarEvents(ctx, filter)
+}
+
+// ListJiraLinks lists the Jira issues referenced by the stored pull requests and issues
+func (t *Tracker) ListJiraLinks(ctx context.Context, filter *JiraLinkFilter) ([]*JiraLink, error) {
+	return t.service.ListJiraLinks(ctx, filter)
+}
+
+// QueryItems answers a natural-language question about pull requests and issues with the
+// configured query backend, returning the filter it was translated into and the matching items
+func (t *Tracker) QueryItems(ctx context.Context, question string, page, perPage int) (*ItemFilter, []*Item, *Pagination, error) {
+	return t.service.QueryItems(ctx, question, page, perPage)
+}
+
+// WithActor returns a context attributing the changes made with it to actor in the audit log
+func WithActor(ctx context.Context, actor string) context.Context {
+	return service.WithActor(ctx, actor)
+}
+
+// WithWorkspace returns a context restricting the calls made with it to the repositories of a
+// workspace; repositories added with it join the workspace
+func WithWorkspace(ctx context.Context, id string) context.Context {
+	return service.WithWorkspace(ctx, id)
+}
+
+// CreateWorkspace creates an empty workspace; a zero sync interval keeps its repositories on
+// the global schedule
+func (t *Tracker) CreateWorkspace(ctx context.Context, id, name string, syncInterval time.Duration) (*Workspace, error) {
+	return t.service.CreateWorkspace(ctx, id, name, syncInterval)
+}
+
+// ListWorkspaces lists workspaces ordered by ID
+func (t *Tracker) ListWorkspaces(ctx context.Context) ([]*Workspace, error) {
+	return t.service.ListWorkspaces(ctx)
+}
+
+// UpdateWorkspace renames a workspace or changes its sync interval
+func (t *Tracker) UpdateWorkspace(ctx context.Context, id string, update *WorkspaceUpdate) (*Workspace, error) {
+	return t.service.UpdateWorkspace(ctx, id, update)
+}
+
+// DeleteWorkspace deletes a workspace; its repositories stay tracked
+func (t *Tracker) DeleteWorkspace(ctx context.Context, id string) error {
+	return t.service.DeleteWorkspace(ctx, id)
+}
+
+// ListActivity lists observed changes, newest first
+func (t *Tracker) ListActivity(ctx context.Context, filter *ActivityFilter) ([]*ActivityEvent, *Pagination, error) {
+	return t.service.ListActivity(ctx, filter)
+}
+
+// Status reports repository counts, the GitHub rate limit and storage statistics
+func (t *Tracker) Status(ctx context.Context) (map[string]interface{}, error) {
+	return t.service.GetStatus(ctx)
+}
@@ -0,0 +1,177 @@
+package ghrepos_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/siddontang/github-repos-management/pkg/ghrepos"
+)
+
+// fakeGitHub serves a fixed repository with one pull request and one issue
+type fakeGitHub struct{}
+
+func (fakeGitHub) GetRepository(owner, name string) (*ghrepos.GitHubRepository, error) {
+	return &ghrepos.GitHubRepository{
+		Owner:    ghrepos.GitHubUser{Login: owner},
+		Name:     name,
+		FullName: owner + "/" + name,
+	}, nil
+}
+
+func (fakeGitHub) ListOrganizationRepositories(org string, limit int) ([]string, error) {
+	return nil, nil
+}
+
+func (fakeGitHub) ListUserRepositories(relation string, limit int) ([]*ghrepos.GitHubRepository, error) {
+	return nil, nil
+}
+
+func (fakeGitHub) ListPullRequests(owner, name string, options *ghrepos.PullRequestOptions) ([]*ghrepos.GitHubPullRequest, error) {
+	now := time.Now()
+	return []*ghrepos.GitHubPullRequest{
+		{Number: 2, Title: "Fix it", State: "OPEN", User: ghrepos.GitHubUser{Login: "alice"}, CreatedAt: now, UpdatedAt: now},
+	}, nil
+}
+
+func (fakeGitHub) ListIssues(owner, name string, options *ghrepos.IssueOptions) ([]*ghrepos.GitHubIssue, error) {
+	now := time.Now()
+	return []*ghrepos.GitHubIssue{
+		{Number: 1, Title: "It is broken", State: "OPEN", User: ghrepos.GitHubUser{Login: "bob"}, CreatedAt: now, UpdatedAt: now},
+	}, nil
+}
+
+func (fakeGitHub) ListAuthorAssociations(owner, name string, limit int) (map[int]string, error) {
+	return map[int]string{1: "CONTRIBUTOR", 2: "MEMBER"}, nil
+}
+
+func (fakeGitHub) ListMilestones(owner, name string) ([]*ghrepos.GitHubMilestone, error) {
+	return nil, nil
+}
+
+func (fakeGitHub) ListReleases(owner, name string, limit int) ([]*ghrepos.GitHubRelease, error) {
+	return nil, nil
+}
+
+func (fakeGitHub) ListDependabotAlerts(owner, name string) ([]*ghrepos.GitHubSecurityAlert, error) {
+	return nil, nil
+}
+
+func (fakeGitHub) ListCodeScanningAlerts(owner, name string) ([]*ghrepos.GitHubSecurityAlert, error) {
+	return nil, nil
+}
+
+func (fakeGitHub) ListCommits(owner, name string, since time.Time, limit int) ([]*ghrepos.GitHubCommit, error) {
+	return nil, nil
+}
+
+func (fakeGitHub) ListLabels(owner, name string) ([]*ghrepos.GitHubLabel, error) {
+	return nil, nil
+}
+
+func (fakeGitHub) UpdateLabel(owner, name, label string, update *ghrepos.GitHubLabelUpdate) (*ghrepos.GitHubLabel, error) {
+	return nil, nil
+}
+
+func (fakeGitHub) ListIssueTemplates(owner, name string) ([]*ghrepos.GitHubIssueTemplate, error) {
+	return nil, nil
+}
+
+func (fakeGitHub) ListDiscussions(owner, name string, limit int) ([]*ghrepos.GitHubDiscussion, error) {
+	return nil, nil
+}
+
+func (fakeGitHub) ListProjects(owner, name string) ([]*ghrepos.GitHubProject, error) {
+	return nil, nil
+}
+
+func (fakeGitHub) ListProjectItems(owner, name string, limit int) ([]*ghrepos.GitHubProjectItem, error) {
+	return nil, nil
+}
+
+func (fakeGitHub) GetCodeOwners(owner, name string) (string, error) {
+	return "", nil
+}
+
+func (fakeGitHub) GetPullRequestDiff(owner, name string, number int, format string) (string, error) {
+	return "", nil
+}
+
+func (fakeGitHub) CreatePullRequest(owner, name string, create *ghrepos.GitHubPullRequestCreate) (*ghrepos.GitHubPullRequest, error) {
+	return nil, nil
+}
+
+func (fakeGitHub) UpdateIssue(owner, name string, number int, update *ghrepos.GitHubIssueUpdate) (*ghrepos.GitHubIssue, error) {
+	return nil, nil
+}
+
+func (fakeGitHub) AddLabels(owner, name string, number int, labels []string) error {
+	return nil
+}
+
+func (fakeGitHub) CreateComment(owner, name string, number int, body string) error {
+	return nil
+}
+
+func (fakeGitHub) GetRepositorySettings(owner, name string) (*ghrepos.GitHubRepositorySettings, error) {
+	return &ghrepos.GitHubRepositorySettings{DefaultBranch: "main"}, nil
+}
+
+func (fakeGitHub) GetRateLimit() (*ghrepos.RateLimit, error) {
+	return &ghrepos.RateLimit{Limit: 5000, Remaining: 5000}, nil
+}
+
+func (fakeGitHub) PoolStats() ghrepos.PoolStats {
+	return ghrepos.PoolStats{}
+}
+
+func TestTracker(t *testing.T) {
+	ctx := context.Background()
+	tracker, err := ghrepos.New(
+		ghrepos.WithGitHubClient(fakeGitHub{}),
+		ghrepos.WithLogger(log.New(io.Discard, "", 0)),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer tracker.Close()
+
+	if _, err := tracker.AddRepository(ctx, "owner/repo"); err != nil {
+		t.Fatalf("AddRepository() error = %v", err)
+	}
+
+	items, pagination, err := tracker.ListItems(ctx, &ghrepos.ItemFilter{Page: 1, PerPage: 10})
+	if err != nil {
+		t.Fatalf("ListItems() error = %v", err)
+	}
+	if pagination.Total != 2 || len(items) != 2 {
+		t.Fatalf("ListItems() returned %d of %d items, want 2", len(items), pagination.Total)
+	}
+
+	pr, err := tracker.GetPullRequest(ctx, "owner", "repo", 2)
+	if err != nil {
+		t.Fatalf("GetPullRequest() error = %v", err)
+	}
+	if pr.AuthorAssociation != "MEMBER" {
+		t.Errorf("AuthorAssociation = %q, want MEMBER", pr.AuthorAssociation)
+	}
+
+	if _, err := tracker.GetIssue(ctx, "owner", "repo", 99); !errors.Is(err, ghrepos.ErrIssueNotFound) {
+		t.Errorf("GetIssue() error = %v, want ErrIssueNotFound", err)
+	}
+
+	job, err := tracker.StartRefresh(ctx, "owner", "repo")
+	if err != nil {
+		t.Fatalf("StartRefresh() error = %v", err)
+	}
+	job, err = tracker.WaitJob(ctx, job.ID, time.Minute)
+	if err != nil || job.State != ghrepos.JobStateSucceeded {
+		t.Fatalf("WaitJob() = %+v, %v, want a succeeded job", job, err)
+	}
+	if _, err := tracker.Job(ctx, job.ID+1); !errors.Is(err, ghrepos.ErrJobNotFound) {
+		t.Errorf("Job() error = %v, want ErrJobNotFound", err)
+	}
+}
@@ -0,0 +1,141 @@
+package ghrepos
+
+import (
+	"github.com/siddontang/github-repos-management/internal/config"
+	"github.com/siddontang/github-repos-management/internal/db"
+	"github.com/siddontang/github-repos-management/internal/github"
+	"github.com/siddontang/github-repos-management/internal/models"
+	"github.com/siddontang/github-repos-management/internal/service"
+)
+
+// Configuration
+type (
+	Config         = config.Config
+	DatabaseConfig = config.DatabaseConfig
+	CacheConfig    = config.CacheConfig
+	GitHubConfig   = config.GitHubConfig
+	JobsConfig     = config.JobsConfig
+)
+
+// Storage is a storage backend, such as one opened with OpenStorage or a custom implementation
+type Storage = db.DB
+
+// StorageTx is the view of a Storage given to the function run by Storage.Batch
+type StorageTx = db.Tx
+
+// GitHubClient fetches data from GitHub; the default implementation runs the gh CLI
+type GitHubClient = github.ClientInterface
+
+// GitHubFixtureClient is a GitHubClient serving fixtures instead of running gh, for tests
+type GitHubFixtureClient = github.FixtureClient
+
+// Types used by GitHubClient implementations
+type (
+	GitHubRepository         = github.Repository
+	GitHubPullRequest        = github.PullRequest
+	GitHubIssue              = github.Issue
+	GitHubUser               = github.User
+	GitHubTeam               = github.Team
+	GitHubLabel              = github.Label
+	GitHubReview             = github.Review
+	GitHubMilestone          = github.Milestone
+	GitHubRelease            = github.Release
+	GitHubSecurityAlert      = github.SecurityAlert
+	GitHubRepositorySettings = github.RepositorySettings
+	GitHubCommit             = github.Commit
+	GitHubLabelUpdate        = github.LabelUpdate
+	GitHubPullRequestCreate  = github.PullRequestCreate
+	GitHubIssueUpdate        = github.IssueUpdate
+	GitHubIssueTemplate      = github.IssueTemplate
+	GitHubDiscussion         = github.Discussion
+	GitHubProject            = github.Project
+	GitHubProjectItem        = github.ProjectItem
+	GitHubFixture            = github.Fixture
+	PullRequestOptions       = github.PullRequestOptions
+	IssueOptions             = github.IssueOptions
+	RateLimit                = github.RateLimit
+	PoolStats                = github.PoolStats
+)
+
+// Tracked data
+type (
+	Repository       = models.Repository
+	PullRequest      = models.PullRequest
+	Issue            = models.Issue
+	Item             = models.Item
+	Label            = models.Label
+	ActivityEvent    = models.ActivityEvent
+	StateTransition  = models.StateTransition
+	Pagination       = models.Pagination
+	RepositoryResult = models.RepositoryAddResult
+	Job              = models.Job
+	APIUsage         = models.RepositoryAPIUsage
+	AuditEntry       = models.AuditEntry
+	CalendarEvent    = models.CalendarEvent
+	JiraLink         = models.JiraLink
+	Workspace        = models.Workspace
+	WorkspaceToken   = models.WorkspaceToken
+	WorkspaceUpdate  = models.WorkspaceUpdate
+)
+
+// Filters
+type (
+	RepositoryFilter  = models.RepositoryFilter
+	PullRequestFilter = models.PullRequestFilter
+	IssueFilter       = models.IssueFilter
+	ItemFilter        = models.ItemFilter
+	ActivityFilter    = models.ActivityFilter
+	JobFilter         = models.JobFilter
+	AuditFilter       = models.AuditFilter
+	CalendarFilter    = models.CalendarFilter
+	JiraLinkFilter    = models.JiraLinkFilter
+)
+
+// Job states
+const (
+	JobStateQueued    = models.JobStateQueued
+	JobStateRunning   = models.JobStateRunning
+	JobStateSucceeded = models.JobStateSucceeded
+	JobStateFailed    = models.JobStateFailed
+	JobStateCanceled  = models.JobStateCanceled
+)
+
+// Item types reported by ListItems
+const (
+	ItemTypePullRequest = models.ItemTypePullRequest
+	ItemTypeIssue       = models.ItemTypeIssue
+)
+
+// Errors returned by the tracker, to be checked with errors.Is
+var (
+	ErrRepositoryExists      = service.ErrRepositoryExists
+	ErrRepositoryNotFound    = service.ErrRepositoryNotFound
+	ErrPullRequestNotFound   = service.ErrPullRequestNotFound
+	ErrIssueNotFound         = service.ErrIssueNotFound
+	ErrInvalidRepositoryName = service.ErrInvalidRepositoryName
+	ErrInvalidCursor         = service.ErrInvalidCursor
+	ErrInvalidItemType       = service.ErrInvalidItemType
+	ErrJobNotFound           = service.ErrJobNotFound
+	ErrJobFinished           = service.ErrJobFinished
+	ErrWorkspaceNotFound     = service.ErrWorkspaceNotFound
+	ErrWorkspaceExists       = service.ErrWorkspaceExists
+	ErrInvalidWorkspace      = service.ErrInvalidWorkspace
+)
+
+// Kinds of errors that Storage implementations return, matched with errors.Is.
+// Errors for missing records must match ErrNotFound, or they are reported as
+// failures rather than as a missing repository, item, job or workspace.
+var (
+	ErrNotFound    = db.ErrNotFound
+	ErrConflict    = db.ErrConflict
+	ErrUnavailable = db.ErrUnavailable
+)
+
+// Errors that Storage implementations return for writes that lose a race.
+// UpdateRepository must reject a repository whose Version is not the stored
+// version, and UpdatePullRequest and UpdateIssue must reject items older than
+// the stored ones.
+var (
+	ErrVersionConflict = db.ErrVersionConflict
+	ErrStaleWrite      = db.ErrStaleWrite
+)